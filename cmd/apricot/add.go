@@ -0,0 +1,60 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/hoanghai1803/apricot/internal/api/handlers"
+)
+
+// add adds the posts at the given URLs to the reading list, summarizing new
+// ones if an AI provider is configured, as the web app's "Add URL" does.
+func add(args []string) error {
+	var o options
+	fs := newFlagSet("add", "[flags] <url>...", &o)
+	source := fs.String("source", "", "source name to file the posts under (default: the site's name)")
+	fs.Parse(args) //nolint:errcheck // ExitOnError
+	if fs.NArg() == 0 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	cfg, store, err := o.open()
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+	aiProvider, err := newAIProvider(cfg, store)
+	if err != nil {
+		return fmt.Errorf("creating AI provider: %w", err)
+	}
+	fetcher := newFetcher(cfg)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	failed := 0
+	for _, rawURL := range fs.Args() {
+		// Each post gets the time the web app allows for adding one.
+		urlCtx, cancel := context.WithTimeout(ctx, time.Duration(cfg.Server.DiscoveryTimeoutSeconds)*time.Second)
+		blogID, err := handlers.AddURL(urlCtx, store, fetcher, aiProvider, cfg, rawURL, *source)
+		cancel()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %v\n", rawURL, err)
+			failed++
+			continue
+		}
+		title := rawURL
+		if blog, err := store.GetBlogByID(ctx, blogID); err == nil {
+			title = blog.Title
+		}
+		fmt.Printf("Added %s\n", title)
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d posts not added", failed, fs.NArg())
+	}
+	return nil
+}
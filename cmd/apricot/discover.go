@@ -0,0 +1,98 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	"github.com/hoanghai1803/apricot/internal/api/handlers"
+)
+
+// discover runs discovery with the saved preferences and prints the ranked
+// posts. The session is recorded, so the web app shows it as the latest.
+func discover(args []string) error {
+	var o options
+	fs := newFlagSet("discover", "[flags]", &o)
+	asJSON := fs.Bool("json", false, "print the results as JSON")
+	fs.Parse(args) //nolint:errcheck // ExitOnError
+	if fs.NArg() > 0 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	cfg, store, err := o.open()
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+	aiProvider, err := newAIProvider(cfg, store)
+	if err != nil {
+		return fmt.Errorf("creating AI provider: %w", err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(cfg.Server.DiscoveryTimeoutSeconds)*time.Second)
+	defer cancel()
+
+	fmt.Fprintln(os.Stderr, "Fetching feeds and ranking posts...")
+	resp, err := handlers.RunDiscovery(ctx, store, aiProvider, newFetcher(cfg), cfg)
+	if err != nil {
+		return err
+	}
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(resp)
+	}
+	printDiscovery(os.Stdout, resp)
+	return nil
+}
+
+// printDiscovery writes the results of a discovery run to w, best first.
+func printDiscovery(w io.Writer, resp handlers.DiscoverResponse) {
+	if len(resp.Results) == 0 {
+		fmt.Fprintln(w, "No new posts matched your interests.")
+	}
+	for i, r := range resp.Results {
+		title := r.Title
+		if r.RewrittenTitle != "" {
+			title = r.RewrittenTitle
+		}
+		details := []string{r.Source}
+		if r.ReadingTimeMinutes != nil {
+			details = append(details, fmt.Sprintf("%d min", *r.ReadingTimeMinutes))
+		}
+		if r.Difficulty != "" {
+			details = append(details, r.Difficulty)
+		}
+
+		fmt.Fprintf(w, "%2d. %s\n", i+1, title)
+		fmt.Fprintf(w, "    %s\n", strings.Join(details, " · "))
+		fmt.Fprintf(w, "    %s\n", r.URL)
+		if r.Summary != "" {
+			fmt.Fprintf(w, "    %s\n", r.Summary)
+		}
+		if r.Reason != "" {
+			fmt.Fprintf(w, "    Why: %s\n", r.Reason)
+		}
+		fmt.Fprintln(w)
+	}
+	for _, f := range resp.FailedFeeds {
+		fmt.Fprintf(w, "Could not fetch %s: %s\n", f.Source, f.Error)
+	}
+	if resp.Partial {
+		fmt.Fprintln(w, "Discovery ran out of time; these are the results it had so far.")
+	}
+	if resp.Fallback {
+		fmt.Fprintln(w, "The AI could not rank posts, so they are ranked by your topics and recency.")
+	}
+	if len(resp.Results) > 0 {
+		fmt.Fprintln(w, `Save one with "apricot add <url>".`)
+	}
+}
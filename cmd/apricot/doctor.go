@@ -0,0 +1,262 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/hoanghai1803/apricot/internal/ai"
+	"github.com/hoanghai1803/apricot/internal/config"
+	"github.com/hoanghai1803/apricot/internal/feeds"
+	"github.com/hoanghai1803/apricot/internal/netguard"
+	"github.com/hoanghai1803/apricot/internal/storage"
+)
+
+// Severities of a doctor finding.
+const (
+	findingOK   = "ok"
+	findingWarn = "warn"
+	findingFail = "fail"
+)
+
+// finding is the outcome of one of doctor's checks.
+type finding struct {
+	Check    string `json:"check"`
+	Severity string `json:"severity"`
+	Message  string `json:"message"`
+	Hint     string `json:"hint,omitempty"` // what to do about a warning or failure
+}
+
+// report records a finding.
+type report func(check, severity, message, hint string)
+
+// doctor checks the configuration, the database, the feeds of the active
+// sources, and the AI credentials, and prints what it finds with a hint for
+// each problem. It changes nothing: the config file and database are not
+// created if missing, and pending migrations are reported, not applied. It
+// fails if any check does.
+func doctor(args []string) error {
+	var o options
+	fs := newFlagSet("doctor", "[flags]", &o)
+	offline := fs.Bool("offline", false, "skip the checks that need the network: feeds and AI credentials")
+	asJSON := fs.Bool("json", false, "print the findings as JSON")
+	fs.Parse(args) //nolint:errcheck // ExitOnError
+	if fs.NArg() > 0 {
+		fs.Usage()
+		os.Exit(2)
+	}
+	logLevel.Set(slog.LevelError) // failed feeds are findings, not log noise
+
+	ctx := context.Background()
+	findings := runChecks(ctx, &o, *offline)
+
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(findings); err != nil {
+			return err
+		}
+	} else {
+		printFindings(os.Stdout, findings)
+	}
+
+	failed := 0
+	for _, f := range findings {
+		if f.Severity == findingFail {
+			failed++
+		}
+	}
+	switch failed {
+	case 0:
+		return nil
+	case 1:
+		return errors.New("1 check failed")
+	default:
+		return fmt.Errorf("%d checks failed", failed)
+	}
+}
+
+// runChecks runs doctor's checks in order. Checks that need the config or
+// the database are skipped if those could not be loaded.
+func runChecks(ctx context.Context, o *options, offline bool) []finding {
+	var findings []finding
+	add := func(check, severity, message, hint string) {
+		findings = append(findings, finding{Check: check, Severity: severity, Message: message, Hint: hint})
+	}
+
+	cfg := checkConfig(o, add)
+	if cfg == nil {
+		return findings
+	}
+	store := checkDatabase(ctx, cfg, o.dataDir, add)
+	if store != nil {
+		defer store.Close()
+		checkMigrations(ctx, store, add)
+	}
+	if offline {
+		return findings
+	}
+	if store != nil {
+		checkFeeds(ctx, cfg, store, add)
+	}
+	checkAI(ctx, cfg, add)
+	return findings
+}
+
+// checkConfig loads the config file without creating it, returning nil if
+// it is missing or invalid.
+func checkConfig(o *options, add report) *config.Config {
+	if _, err := os.Stat(o.configPath); errors.Is(err, os.ErrNotExist) {
+		cfg, err := config.Default()
+		if err != nil {
+			add("config", findingFail, fmt.Sprintf("the defaults with APRICOT_* variables applied: %v", err),
+				"fix the environment variable named above")
+			return nil
+		}
+		add("config", findingWarn, fmt.Sprintf("%s does not exist; checking the defaults with APRICOT_* variables applied", o.configPath),
+			"pass -config with the path the server uses, unless it runs on environment variables alone")
+		return cfg
+	}
+	cfg, err := config.Load(o.configPath)
+	if err != nil {
+		add("config", findingFail, fmt.Sprintf("%s: %v", o.configPath, err),
+			"fix the setting named above; the Configuration section of the README lists every setting and its default")
+		return nil
+	}
+	add("config", findingOK, fmt.Sprintf("%s is valid", o.configPath), "")
+	return cfg
+}
+
+// checkDatabase opens the database without migrating it and checks its
+// integrity. It returns nil if the database cannot be opened.
+func checkDatabase(ctx context.Context, cfg *config.Config, dataDir string, add report) storage.Store {
+	name := "the Postgres database"
+	if cfg.Storage.Driver != "postgres" {
+		name = filepath.Join(dataDir, "app.db")
+		if _, err := os.Stat(name); errors.Is(err, os.ErrNotExist) {
+			add("database", findingFail, fmt.Sprintf("%s does not exist", name),
+				"pass -data-dir with the directory the server uses, or run apricot serve once to create the database")
+			return nil
+		}
+	}
+	store, err := openStore(cfg, dataDir, false)
+	if err != nil {
+		hint := "check that the file is readable and not on a full disk"
+		if cfg.Storage.Driver == "postgres" {
+			hint = "check storage.postgres_dsn and that the Postgres server is running"
+		}
+		add("database", findingFail, fmt.Sprintf("opening %s: %v", name, err), hint)
+		return nil
+	}
+
+	problems, err := store.IntegrityCheck(ctx)
+	switch {
+	case err != nil:
+		add("database", findingFail, err.Error(), "")
+	case len(problems) > 0:
+		if len(problems) > 5 {
+			problems = append(problems[:5], fmt.Sprintf("and %d more", len(problems)-5))
+		}
+		add("database", findingFail,
+			fmt.Sprintf("%s failed its integrity check: %s", name, strings.Join(problems, "; ")),
+			"stop the server and restore the latest backup from storage.backup_dir, or export what is readable and import it into a new database")
+	default:
+		add("database", findingOK, fmt.Sprintf("%s passed its integrity check", name), "")
+	}
+	return store
+}
+
+// checkMigrations reports migrations the database has yet to apply.
+func checkMigrations(ctx context.Context, store storage.Store, add report) {
+	status, err := store.MigrationStatus(ctx)
+	if err != nil {
+		add("migrations", findingFail, err.Error(), "")
+		return
+	}
+	if status.Pending > 0 {
+		add("migrations", findingWarn,
+			fmt.Sprintf("schema version %d, with %d of this build's migrations not applied", status.Version, status.Pending),
+			"apricot serve applies them when it starts; take a backup first")
+		return
+	}
+	add("migrations", findingOK, fmt.Sprintf("schema version %d is up to date", status.Version), "")
+}
+
+// checkFeeds fetches the feed of each active source once, as discovery
+// would.
+func checkFeeds(ctx context.Context, cfg *config.Config, store storage.Store, add report) {
+	sources, err := store.GetActiveSources(ctx)
+	if err != nil {
+		add("feeds", findingFail, fmt.Sprintf("loading sources: %v", err), "")
+		return
+	}
+	if len(sources) == 0 {
+		add("feeds", findingWarn, "no sources are active", "turn some on in Preferences, or discovery has nothing to rank")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(cfg.Server.DiscoveryTimeoutSeconds)*time.Second)
+	defer cancel()
+	result, err := newFetcher(cfg).FetchAll(ctx, sources, feeds.FetchOptions{Mode: "recent_posts", MaxArticles: 1})
+	if err != nil {
+		add("feeds", findingFail, err.Error(), "")
+		return
+	}
+	for _, f := range result.Failed {
+		hint := "check the source's feed URL in Preferences, or turn the source off"
+		if strings.Contains(f.Error, netguard.ErrBlocked.Error()) {
+			hint = "add the feed's network to feeds.allow_networks if it is meant to be reachable"
+		}
+		add("feeds", findingFail, fmt.Sprintf("%s: %s", f.Source, f.Error), hint)
+	}
+	if ok := len(sources) - len(result.Failed); ok > 0 {
+		add("feeds", findingOK, fmt.Sprintf("%d of %d active sources are reachable", ok, len(sources)), "")
+	}
+}
+
+// checkAI sends the configured AI provider a minimal request to verify the
+// API key and model.
+func checkAI(ctx context.Context, cfg *config.Config, add report) {
+	if cfg.AI.APIKey == "" && cfg.AI.Provider != "mock" {
+		add("ai", findingWarn, fmt.Sprintf("no API key is configured for %s", cfg.AI.Provider),
+			"set ai.api_key or the AI_API_KEY environment variable; discovery and summaries need it")
+		return
+	}
+	provider, err := ai.NewProvider(ai.ProviderConfig{
+		Provider: cfg.AI.Provider,
+		APIKey:   cfg.AI.APIKey,
+		Model:    cfg.AI.Model,
+	})
+	if err != nil {
+		add("ai", findingFail, err.Error(), "check ai.provider and ai.model")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(cfg.Server.RequestTimeoutSeconds)*time.Second)
+	defer cancel()
+	if err := provider.Ping(ctx); err != nil {
+		add("ai", findingFail, fmt.Sprintf("test request to %s failed: %v", cfg.AI.Provider, err),
+			"check that the API key is current and ai.model names a model it can use")
+		return
+	}
+	add("ai", findingOK, fmt.Sprintf("%s answered a test request with model %s", cfg.AI.Provider, cfg.AI.Model), "")
+}
+
+// printFindings writes findings to w, one per line, with the hint for each
+// problem beneath it.
+func printFindings(w io.Writer, findings []finding) {
+	marks := map[string]string{findingOK: "ok  ", findingWarn: "WARN", findingFail: "FAIL"}
+	for _, f := range findings {
+		fmt.Fprintf(w, "%s  %-10s  %s\n", marks[f.Severity], f.Check, f.Message)
+		if f.Hint != "" {
+			fmt.Fprintf(w, "%18s%s\n", "", f.Hint)
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/hoanghai1803/apricot/internal/archive"
+	"github.com/hoanghai1803/apricot/internal/models"
+	"github.com/hoanghai1803/apricot/internal/storage"
+)
+
+// exportArchive writes an export archive, as GET /api/export does, to a
+// file or stdout. A file is written under a temporary name and renamed when
+// complete, so a failed export never leaves a truncated archive behind.
+func exportArchive(args []string) error {
+	var o options
+	fs := newFlagSet("export", "[flags]", &o)
+	output := fs.String("o", "", "write the archive to this file instead of stdout")
+	fs.Parse(args) //nolint:errcheck // ExitOnError
+	if fs.NArg() > 0 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	_, store, err := o.open()
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	if *output == "" {
+		return writeArchive(context.Background(), os.Stdout, store)
+	}
+
+	f, err := os.CreateTemp(filepath.Dir(*output), "."+filepath.Base(*output)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name()) // fails harmlessly once renamed
+	if err := writeArchive(context.Background(), f, store); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), *output)
+}
+
+// writeArchive writes all of store's data to w as an export archive.
+func writeArchive(ctx context.Context, w io.Writer, store storage.Store) error {
+	version, err := store.SchemaVersion(ctx)
+	if err != nil {
+		return fmt.Errorf("getting schema version: %w", err)
+	}
+	err = store.ExportAll(ctx, func(data *models.ArchiveStream) error {
+		return archive.WriteStream(w, data, version, time.Now())
+	})
+	if err != nil {
+		return fmt.Errorf("writing archive: %w", err)
+	}
+	return nil
+}
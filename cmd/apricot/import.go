@@ -0,0 +1,95 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+
+	"github.com/hoanghai1803/apricot/internal/archive"
+	"github.com/hoanghai1803/apricot/internal/models"
+	"github.com/hoanghai1803/apricot/internal/storage"
+)
+
+// importArchive imports an export archive, or another app's export, from a
+// file or stdin, as POST /api/import does, and prints what it did.
+func importArchive(args []string) error {
+	var o options
+	fs := newFlagSet("import", "[flags] [file]", &o)
+	format := fs.String("format", "", "format of the file: instapaper or omnivore (default: detected)")
+	onConflict := fs.String("on-conflict", storage.ConflictSkip, "what to do with records that already exist: skip or overwrite")
+	dryRun := fs.Bool("dry-run", false, "report what would change without writing anything")
+	asJSON := fs.Bool("json", false, "print the result as JSON")
+	fs.Parse(args) //nolint:errcheck // ExitOnError
+	if fs.NArg() > 1 {
+		fs.Usage()
+		os.Exit(2)
+	}
+	switch *onConflict {
+	case storage.ConflictSkip, storage.ConflictOverwrite:
+	default:
+		return fmt.Errorf("on-conflict must be skip or overwrite")
+	}
+
+	var in io.Reader = os.Stdin
+	if name := fs.Arg(0); name != "" && name != "-" {
+		f, err := os.Open(name)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		in = f
+	}
+
+	_, store, err := o.open()
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	ctx := context.Background()
+	version, err := store.SchemaVersion(ctx)
+	if err != nil {
+		return fmt.Errorf("getting schema version: %w", err)
+	}
+	data, _, err := archive.ReadAny(in, *format, version)
+	if err != nil {
+		return err
+	}
+	result, err := store.ImportArchive(ctx, data, storage.ImportOptions{OnConflict: *onConflict, DryRun: *dryRun})
+	if err != nil {
+		return fmt.Errorf("importing: %w", err)
+	}
+
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(result)
+	}
+	printImport(os.Stdout, result)
+	return nil
+}
+
+// printImport writes the counts of an import's result to w as a table.
+func printImport(w io.Writer, r *models.ArchiveImportResult) {
+	if r.DryRun {
+		fmt.Fprintln(w, "Dry run: nothing was written.")
+	}
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "\tADDED\tMERGED\tOVERWRITTEN\tSKIPPED")
+	row := func(name string, added, merged, overwritten, skipped int) {
+		m := "-"
+		if merged >= 0 {
+			m = fmt.Sprint(merged)
+		}
+		fmt.Fprintf(tw, "%s\t%d\t%s\t%d\t%d\n", name, added, m, overwritten, skipped)
+	}
+	row("sources", r.SourcesAdded, -1, r.SourcesOverwritten, r.SourcesSkipped)
+	row("posts", r.BlogsAdded, -1, r.BlogsOverwritten, r.BlogsSkipped)
+	row("reading list", r.ItemsAdded, r.ItemsMerged, r.ItemsOverwritten, r.ItemsSkipped)
+	row("tags", r.TagsAdded, -1, r.TagsOverwritten, r.TagsSkipped)
+	row("preferences", r.PreferencesAdded, -1, r.PreferencesOverwritten, r.PreferencesSkipped)
+	tw.Flush()
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"text/tabwriter"
+
+	"github.com/hoanghai1803/apricot/internal/models"
+	"github.com/hoanghai1803/apricot/internal/storage"
+)
+
+// statuses are the reading list statuses list can filter by.
+var statuses = []string{"unread", "reading", "read", "archived"}
+
+// list prints the reading list, in the order the web app shows it.
+// Snoozed items are left out.
+func list(args []string) error {
+	var o options
+	fs := newFlagSet("list", "[flags]", &o)
+	status := fs.String("status", "", "only list items with this status: unread, reading, read, or archived")
+	difficulty := fs.String("difficulty", "", "only list posts at this level: intro, intermediate, or deep-dive")
+	limit := fs.Int("limit", 0, "list at most this many items (0 for all)")
+	asJSON := fs.Bool("json", false, "print the items as JSON")
+	fs.Parse(args) //nolint:errcheck // ExitOnError
+	if fs.NArg() > 0 {
+		fs.Usage()
+		os.Exit(2)
+	}
+	if *status != "" && !slices.Contains(statuses, *status) {
+		return fmt.Errorf("status must be one of unread, reading, read, archived")
+	}
+	if *difficulty != "" && !models.IsValidDifficulty(*difficulty) {
+		return fmt.Errorf("difficulty must be one of intro, intermediate, deep-dive")
+	}
+	if *limit < 0 {
+		return fmt.Errorf("limit must not be negative")
+	}
+
+	_, store, err := o.open()
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	items, err := store.GetReadingListFiltered(context.Background(), storage.ReadingListFilter{
+		Status:         *status,
+		Difficulty:     *difficulty,
+		Limit:          *limit,
+		Snoozed:        storage.SnoozeHide,
+		WithoutContent: true,
+	})
+	if err != nil {
+		return fmt.Errorf("loading reading list: %w", err)
+	}
+	if *asJSON {
+		if items == nil {
+			items = []models.ReadingListItem{}
+		}
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(items)
+	}
+	printReadingList(os.Stdout, items)
+	return nil
+}
+
+// printReadingList writes items to w as a table.
+func printReadingList(w io.Writer, items []models.ReadingListItem) {
+	if len(items) == 0 {
+		fmt.Fprintln(w, "No items.")
+		return
+	}
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "ID\tSTATUS\tSOURCE\tTITLE")
+	for _, item := range items {
+		if item.Blog == nil {
+			continue
+		}
+		fmt.Fprintf(tw, "%d\t%s\t%s\t%s\n", item.ID, item.Status, item.Blog.Source, item.Blog.Title)
+	}
+	tw.Flush()
+}
@@ -0,0 +1,282 @@
+// Command apricot is a personal tech blog reader: it serves the web app,
+// and its other commands use the same database and configuration from a
+// terminal, such as over SSH.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/hoanghai1803/apricot/internal/ai"
+	"github.com/hoanghai1803/apricot/internal/config"
+	"github.com/hoanghai1803/apricot/internal/feeds"
+	"github.com/hoanghai1803/apricot/internal/logctx"
+	"github.com/hoanghai1803/apricot/internal/logfile"
+	"github.com/hoanghai1803/apricot/internal/netguard"
+	"github.com/hoanghai1803/apricot/internal/storage"
+)
+
+// command is one of apricot's subcommands.
+type command struct {
+	name    string
+	args    string // the arguments after the name, for the usage message
+	summary string
+	run     func(args []string) error
+}
+
+var commands = []command{
+	{"serve", "[flags]", "run the web server (the default)", serve},
+	{"discover", "[flags]", "run discovery and print the ranked posts", discover},
+	{"add", "[flags] <url>...", "add posts to the reading list", add},
+	{"summarize", "[flags] <url>", "summarize a post and print the summary", summarize},
+	{"list", "[flags]", "print the reading list", list},
+	{"tui", "[flags]", "browse discovery results and the reading list in the terminal", runTUI},
+	{"export", "[flags]", "write an export archive, for backups", exportArchive},
+	{"import", "[flags] [file]", "import an export archive or another app's export", importArchive},
+	{"prune", "[flags]", "delete unsaved posts past the retention period", prune},
+	{"vacuum", "[flags]", "rebuild the search index and reclaim free space", vacuum},
+	{"doctor", "[flags]", "check the config, database, feeds, and AI credentials", doctor},
+}
+
+// logLevel is the level of the log records written to stderr. Commands
+// other than serve raise it to warnings, so their output stays readable;
+// serve sets it from the config's [logging] level.
+var logLevel = new(slog.LevelVar)
+
+func main() {
+	// Log records made with a request's or job's context carry its ID.
+	slog.SetDefault(slog.New(logctx.NewHandler(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: logLevel}))))
+
+	// Without a command, or with only flags, apricot serves, as it did
+	// before it had commands.
+	name, args := "serve", os.Args[1:]
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		name, args = args[0], args[1:]
+	}
+	switch name {
+	case "help", "-h", "-help", "--help":
+		usage(os.Stdout)
+		return
+	}
+	for _, c := range commands {
+		if c.name != name {
+			continue
+		}
+		if err := c.run(args); err != nil {
+			fmt.Fprintf(os.Stderr, "apricot %s: %v\n", name, err)
+			os.Exit(1)
+		}
+		return
+	}
+	fmt.Fprintf(os.Stderr, "apricot: unknown command %q\n\n", name)
+	usage(os.Stderr)
+	os.Exit(2)
+}
+
+// usage writes the list of commands to w.
+func usage(w io.Writer) {
+	fmt.Fprintln(w, "usage: apricot <command> [flags] [arguments]")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "commands:")
+	for _, c := range commands {
+		fmt.Fprintf(w, "  %-10s %s\n", c.name, c.summary)
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, `Run "apricot <command> -h" for a command's flags.`)
+}
+
+// options are the flags every command takes.
+type options struct {
+	configPath string
+	dataDir    string
+}
+
+// newFlagSet returns the flag set of the named command, with the flags
+// in o registered on it. args describes the command's arguments.
+func newFlagSet(name, args string, o *options) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ExitOnError)
+	fs.StringVar(&o.configPath, "config", envOr("APRICOT_CONFIG", "config.toml"), "path to config file (or set APRICOT_CONFIG)")
+	fs.StringVar(&o.dataDir, "data-dir", envOr("APRICOT_DATA_DIR", "./data"), "path to data directory (or set APRICOT_DATA_DIR)")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "usage: apricot %s %s\n\nflags:\n", name, args)
+		fs.PrintDefaults()
+	}
+	return fs
+}
+
+// envOr returns the value of the environment variable name, or def if it
+// is empty.
+func envOr(name, def string) string {
+	if v := os.Getenv(name); v != "" {
+		return v
+	}
+	return def
+}
+
+// loadConfig loads the configuration, creating a default one if it is
+// missing, ensures the data directory exists, and sets up logging as the
+// config says, though never below minLevel.
+func (o *options) loadConfig(minLevel slog.Level) (*config.Config, error) {
+	cfg, err := config.Load(o.configPath)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(o.dataDir, 0o755); err != nil {
+		return nil, fmt.Errorf("creating data directory: %w", err)
+	}
+	if err := setupLogging(cfg.Logging, o.dataDir, minLevel); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// setupLogging replaces the default logger with one in the format of cfg,
+// at its level or minLevel, whichever is higher, writing to stderr and to
+// cfg.File if set. The file stays open until the process exits.
+func setupLogging(cfg config.LoggingConfig, dataDir string, minLevel slog.Level) error {
+	var level slog.Level
+	level.UnmarshalText([]byte(cfg.Level)) //nolint:errcheck // validated by config.Load
+	logLevel.Set(max(level, minLevel))
+
+	var w io.Writer = os.Stderr
+	if path := cfg.File; path != "" {
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(dataDir, path)
+		}
+		f, err := logfile.Open(path, int64(cfg.MaxMB)<<20, cfg.Keep)
+		if err != nil {
+			return err
+		}
+		w = io.MultiWriter(os.Stderr, f)
+	}
+
+	opts := &slog.HandlerOptions{Level: logLevel}
+	var h slog.Handler = slog.NewTextHandler(w, opts)
+	if cfg.Format == "json" {
+		h = slog.NewJSONHandler(w, opts)
+	}
+	slog.SetDefault(slog.New(logctx.NewHandler(h)))
+	return nil
+}
+
+// open loads the configuration and opens the database with its schema up
+// to date and the default sources seeded, as serve does. Commands other
+// than serve use it, and only log warnings.
+func (o *options) open() (*config.Config, storage.Store, error) {
+	logLevel.Set(slog.LevelWarn)
+	cfg, err := o.loadConfig(slog.LevelWarn)
+	if err != nil {
+		return nil, nil, fmt.Errorf("loading config: %w", err)
+	}
+	store, err := openStore(cfg, o.dataDir, true)
+	if err != nil {
+		return nil, nil, fmt.Errorf("opening database: %w", err)
+	}
+	if err := store.SeedDefaults(context.Background()); err != nil {
+		store.Close()
+		return nil, nil, fmt.Errorf("seeding defaults: %w", err)
+	}
+	return cfg, store, nil
+}
+
+// newAIProvider returns the configured AI provider, or nil if none is: that
+// needs an API key, except for the mock provider, which runs offline.
+// Responses are cached in store, so unchanged prompts are not billed again.
+func newAIProvider(cfg *config.Config, store storage.Store) (ai.AIProvider, error) {
+	if cfg.AI.APIKey == "" && cfg.AI.Provider != "mock" {
+		return nil, nil
+	}
+	pcfg := ai.ProviderConfig{
+		Provider:  cfg.AI.Provider,
+		APIKey:    cfg.AI.APIKey,
+		Model:     cfg.AI.Model,
+		Rank:      ai.ModelParams(cfg.AI.Rank),
+		Summarize: ai.ModelParams(cfg.AI.Summarize),
+	}
+	provider, err := ai.NewProvider(pcfg)
+	if err != nil {
+		return nil, err
+	}
+	return ai.NewCachingProvider(provider, store, pcfg), nil
+}
+
+// newFetcher returns a feed fetcher kept off local and private networks,
+// apart from those cfg allows.
+func newFetcher(cfg *config.Config) *feeds.Fetcher {
+	guard, _ := netguard.New(cfg.Feeds.AllowNetworks) // already validated by config.Load
+	return feeds.NewFetcher(guard)
+}
+
+// openStore opens the database selected by cfg.Storage.Driver, brings its
+// schema up to date if migrate is set, and returns a store on it. The SQLite
+// database lives in dataDir.
+func openStore(cfg *config.Config, dataDir string, migrate bool) (storage.Store, error) {
+	if cfg.Storage.Driver == "postgres" {
+		db, err := storage.OpenPostgres(cfg.Storage.PostgresDSN, storage.PoolOptions{
+			MaxOpenConns:    cfg.Storage.MaxOpenConns,
+			MaxIdleConns:    cfg.Storage.MaxIdleConns,
+			ConnMaxLifetime: time.Duration(cfg.Storage.ConnMaxLifetimeMinutes) * time.Minute,
+		})
+		if err != nil {
+			return nil, err
+		}
+		if migrate {
+			if err := storage.RunPostgresMigrations(db); err != nil {
+				db.Close()
+				return nil, fmt.Errorf("running migrations: %w", err)
+			}
+		}
+		store := storage.NewPostgresStore(db)
+		if err := useSecretKey(store, cfg.Storage.SecretKey); err != nil {
+			db.Close()
+			return nil, err
+		}
+		return store, nil
+	}
+
+	// SQLite runs in WAL mode with a single write connection and a pool of
+	// read-only connections, so reads don't queue behind writes.
+	path := filepath.Join(dataDir, "app.db")
+	db, err := storage.OpenDatabase(path)
+	if err != nil {
+		return nil, err
+	}
+	if migrate {
+		if err := storage.RunMigrations(db); err != nil {
+			db.Close()
+			return nil, fmt.Errorf("running migrations: %w", err)
+		}
+	}
+	store := storage.NewSQLiteStore(db)
+	rdb, err := storage.OpenReadPool(path)
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	store.UseReadPool(rdb)
+	if err := useSecretKey(store, cfg.Storage.SecretKey); err != nil {
+		store.Close()
+		return nil, err
+	}
+	return store, nil
+}
+
+// useSecretKey sets the key that encrypts stored credentials, if one is
+// configured. Without one, integrations that need to store a credential
+// report that APRICOT_SECRET_KEY must be set.
+func useSecretKey(store interface{ UseSecretKey(string) error }, secret string) error {
+	if secret == "" {
+		return nil
+	}
+	if err := store.UseSecretKey(secret); err != nil {
+		return fmt.Errorf("configuring secret key: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/hoanghai1803/apricot/internal/models"
+)
+
+// prune deletes the posts nobody kept and the cached AI responses that are
+// older than the retention period, as serve does once a day when
+// storage.retention_days is set, and vacuums the database if it deleted
+// any.
+func prune(args []string) error {
+	var o options
+	fs := newFlagSet("prune", "[flags]", &o)
+	days := fs.Int("days", 0, "delete unsaved posts older than this many days (default: storage.retention_days)")
+	dryRun := fs.Bool("dry-run", false, "list the posts that would be deleted without deleting them")
+	fs.Parse(args) //nolint:errcheck // ExitOnError
+	if fs.NArg() > 0 {
+		fs.Usage()
+		os.Exit(2)
+	}
+	if *days < 0 {
+		return fmt.Errorf("days must not be negative")
+	}
+
+	cfg, store, err := o.open()
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+	if *days == 0 {
+		*days = cfg.Storage.RetentionDays
+	}
+	if *days == 0 {
+		return fmt.Errorf("retention is off: set storage.retention_days or pass -days")
+	}
+
+	ctx := context.Background()
+	cutoff := time.Now().AddDate(0, 0, -*days)
+	if *dryRun {
+		blogs, err := store.PrunableBlogs(ctx, cutoff)
+		if err != nil {
+			return err
+		}
+		printPrunable(os.Stdout, blogs, *days)
+		return nil
+	}
+
+	n, err := store.PruneBlogs(ctx, cutoff)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Deleted %d posts older than %d days.\n", n, *days)
+	cached, err := store.PruneAIResponses(ctx, cutoff)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Deleted %d cached AI responses older than %d days.\n", cached, *days)
+	if n > 0 || cached > 0 {
+		if err := store.Vacuum(ctx); err != nil {
+			return err
+		}
+		fmt.Println("Vacuumed the database.")
+	}
+	return nil
+}
+
+// printPrunable writes the posts prune would delete to w as a table.
+func printPrunable(w io.Writer, blogs []models.Blog, days int) {
+	if len(blogs) == 0 {
+		fmt.Fprintf(w, "No posts older than %d days would be deleted.\n", days)
+		return
+	}
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "ID\tDATE\tSOURCE\tTITLE")
+	for _, b := range blogs {
+		date := b.FetchedAt
+		if b.PublishedAt != nil {
+			date = *b.PublishedAt
+		}
+		fmt.Fprintf(tw, "%d\t%s\t%s\t%s\n", b.ID, date.Format("2006-01-02"), b.Source, b.Title)
+	}
+	tw.Flush()
+	fmt.Fprintf(w, "\n%d posts older than %d days would be deleted.\n", len(blogs), days)
+}
@@ -0,0 +1,548 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/hoanghai1803/apricot/internal/api"
+	"github.com/hoanghai1803/apricot/internal/api/handlers"
+	"github.com/hoanghai1803/apricot/internal/backup"
+	"github.com/hoanghai1803/apricot/internal/bookmarks"
+	"github.com/hoanghai1803/apricot/internal/config"
+	"github.com/hoanghai1803/apricot/internal/cron"
+	"github.com/hoanghai1803/apricot/internal/email"
+	"github.com/hoanghai1803/apricot/internal/jobs"
+	"github.com/hoanghai1803/apricot/internal/logfile"
+	"github.com/hoanghai1803/apricot/internal/notify"
+	"github.com/hoanghai1803/apricot/internal/notion"
+	"github.com/hoanghai1803/apricot/internal/pagecache"
+	"github.com/hoanghai1803/apricot/internal/readwise"
+	"github.com/hoanghai1803/apricot/internal/storage"
+	"golang.org/x/crypto/acme/autocert"
+)
+
+// Server timeouts, so a slow or idle client cannot hold a connection open
+// indefinitely. readTimeout covers the whole request, so it is long enough
+// for the largest upload, an archive to POST /api/import, over a slow link.
+const (
+	readHeaderTimeout = 10 * time.Second
+	readTimeout       = 5 * time.Minute
+	idleTimeout       = 2 * time.Minute
+)
+
+// serve runs the web server until SIGINT or SIGTERM. It is the command
+// apricot runs when given none.
+func serve(args []string) error {
+	var o options
+	fs := newFlagSet("serve", "[flags]", &o)
+	rollbackTo := fs.Int("rollback-to", -1, "revert schema migrations newer than this version, then exit")
+	headless := fs.Bool("headless", false, "serve only the API, without the web UI (overrides [server] headless)")
+	noBrowser := fs.Bool("no-browser", false, "do not open the web UI in a browser (overrides [server] auto_open_browser)")
+	fs.Parse(args) //nolint:errcheck // ExitOnError
+
+	// Load configuration (auto-creates default if missing), ensure the
+	// data directory exists, and log as [logging] says.
+	cfg, err := o.loadConfig(slog.LevelDebug)
+	if err != nil {
+		slog.Error("failed to load config", "error", err)
+		os.Exit(1)
+	}
+	if *headless {
+		cfg.Server.Headless = true
+	}
+	if *noBrowser {
+		open := false
+		cfg.Server.AutoOpenBrowser = &open
+	}
+
+	// Roll the schema back instead of serving, without applying migrations
+	// first: the point is usually to retry a newer one after fixing it.
+	if *rollbackTo >= 0 {
+		store, err := openStore(cfg, o.dataDir, false)
+		if err != nil {
+			slog.Error("failed to open database", "error", err)
+			os.Exit(1)
+		}
+		err = store.RollbackTo(context.Background(), *rollbackTo)
+		store.Close()
+		if err != nil {
+			slog.Error("failed to roll back migrations", "error", err)
+			os.Exit(1)
+		}
+		slog.Info("rolled back migrations", "version", *rollbackTo)
+		return nil
+	}
+
+	// Open the configured database and apply schema migrations.
+	store, err := openStore(cfg, o.dataDir, true)
+	if err != nil {
+		slog.Error("failed to open database", "error", err)
+		os.Exit(1)
+	}
+	defer store.Close()
+
+	// Seed default blog sources.
+	if err := store.SeedDefaults(context.Background()); err != nil {
+		slog.Error("failed to seed defaults", "error", err)
+		os.Exit(1)
+	}
+
+	// Background work runs until shutdown, which waits for it to stop
+	// before the database is closed.
+	bgCtx, stopBackground := context.WithCancel(context.Background())
+	var background sync.WaitGroup
+
+	// Move the text of old, unsaved posts into compressed cold storage.
+	if months := cfg.Storage.ColdStorageMonths; months > 0 {
+		n, err := store.ArchiveColdContent(context.Background(), time.Now().AddDate(0, -months, 0))
+		if err != nil {
+			slog.Warn("failed to move old content to cold storage", "error", err)
+		} else if n > 0 {
+			slog.Info("moved old content to cold storage", "blogs", n)
+		}
+	}
+
+	// Back up the database on a schedule, keeping the newest few copies.
+	backupDir := cfg.Storage.BackupDir
+	if backupDir == "" {
+		backupDir = filepath.Join(o.dataDir, "backups")
+	}
+	var backups *backup.Manager
+	if sqliteStore, ok := store.(*storage.SQLiteStore); ok {
+		backups = backup.NewManager(sqliteStore, backupDir, cfg.Storage.BackupKeep)
+		if hours := cfg.Storage.BackupIntervalHours; hours > 0 {
+			background.Go(func() { backups.Schedule(bgCtx, time.Duration(hours)*time.Hour) })
+		}
+	} else if cfg.Storage.BackupIntervalHours > 0 {
+		slog.Info("scheduled backups are only available with sqlite; back up postgres with pg_dump")
+	}
+
+	// Keep pages the reader's proxy fetches, so reopening an article is
+	// instant.
+	var proxyCache *pagecache.Cache
+	if mb := cfg.Storage.ProxyCacheMB; mb > 0 {
+		proxyCache, err = pagecache.Open(filepath.Join(o.dataDir, "proxy-cache"), int64(mb)<<20)
+		if err != nil {
+			slog.Error("failed to open proxy cache", "error", err)
+			os.Exit(1)
+		}
+	}
+
+	// Move snoozed items back to unread once their snooze ends.
+	background.Go(func() { wakeSnoozedItems(bgCtx, store, time.Minute) })
+
+	// Delete old posts nobody kept, once a day.
+	if days := cfg.Storage.RetentionDays; days > 0 {
+		background.Go(func() { pruneOldBlogs(bgCtx, store, days, 24*time.Hour) })
+	}
+
+	// Create AI provider (nil if no API key -- handlers check for this). The
+	// mock provider runs offline and needs no key.
+	aiProvider, err := newAIProvider(cfg, store)
+	if err != nil {
+		slog.Error("failed to create AI provider", "error", err)
+		os.Exit(1)
+	}
+	if aiProvider != nil {
+		slog.Info("AI provider configured", "provider", cfg.AI.Provider, "model", cfg.AI.Model)
+	} else {
+		slog.Warn("no AI provider API key configured, AI features will be disabled")
+	}
+
+	// Create feed fetcher, kept off local and private networks.
+	fetcher := newFetcher(cfg)
+
+	// Run long operations such as discovery and backups in the background,
+	// from a queue kept in the database.
+	runner := jobs.NewManager(store, 2)
+
+	// Send events to the configured webhooks and chats, and take commands
+	// from chats that reply.
+	notifier := notify.New(runner, notifyTargets(cfg)...)
+	background.Go(func() { notifier.Listen(bgCtx, handlers.SaveFromChat(store, notifier)) })
+
+	// Email the discovery digest, if a mail server is configured.
+	var mailer *email.Mailer
+	if cfg.Email.Enabled() {
+		mailer = &email.Mailer{
+			Host:     cfg.Email.SMTPHost,
+			Port:     cfg.Email.SMTPPort,
+			Username: cfg.Email.Username,
+			Password: cfg.Email.Password,
+			From:     cfg.Email.From,
+		}
+	}
+
+	// Sync notes to Readwise, if an access token is configured.
+	var rw *readwise.Client
+	if cfg.Readwise.Enabled() {
+		rw = &readwise.Client{Token: cfg.Readwise.Token}
+	}
+
+	// Mirror the reading list to Raindrop.io or Pinboard, if configured.
+	var bm bookmarks.Service
+	if cfg.Bookmarks.Enabled() {
+		bm, err = bookmarks.New(cfg.Bookmarks.Service, cfg.Bookmarks.Token, cfg.Bookmarks.Collection)
+		if err != nil {
+			slog.Error("failed to set up bookmark sync", "error", err)
+			os.Exit(1)
+		}
+	}
+
+	// Export the reading list to a Notion database, if configured.
+	var nc *notion.Client
+	if cfg.Notion.Enabled() {
+		nc = &notion.Client{Token: cfg.Notion.Token}
+	}
+
+	// Build router with all API routes and static file serving.
+	router := api.NewRouter(store, aiProvider, fetcher, backups, proxyCache, runner, notifier, mailer, rw, bm, nc, cfg)
+	background.Go(func() { runner.Run(bgCtx) })
+
+	// Run discovery on the schedule in the settings, so results are
+	// waiting.
+	if spec := handlers.LoadSettings(bgCtx, store, cfg).DiscoverSchedule; spec != "" && aiProvider == nil {
+		slog.Warn("discover_schedule is set but no AI provider is configured; scheduled runs will fail")
+	}
+	background.Go(func() { discoverOnSchedule(bgCtx, store, runner, cfg) })
+	if spec := cfg.Feeds.SuggestSchedule; spec != "" {
+		sched, _ := cron.Parse(spec) // already validated by config.Load
+		background.Go(func() { enqueueOnSchedule(bgCtx, runner, sched, "source_suggestions") })
+	}
+	if spec := cfg.Email.DigestSchedule; mailer != nil && spec != "" {
+		sched, _ := cron.Parse(spec) // already validated by config.Load
+		background.Go(func() { enqueueOnSchedule(bgCtx, runner, sched, "digest") })
+	}
+	if spec := cfg.Kindle.CompilationSchedule; mailer != nil && spec != "" {
+		sched, _ := cron.Parse(spec) // already validated by config.Load
+		background.Go(func() { enqueueOnSchedule(bgCtx, runner, sched, "kindle") })
+	}
+	if spec := cfg.Readwise.SyncSchedule; rw != nil && spec != "" {
+		sched, _ := cron.Parse(spec) // already validated by config.Load
+		background.Go(func() { enqueueOnSchedule(bgCtx, runner, sched, "readwise") })
+	}
+	if spec := cfg.Bookmarks.SyncSchedule; bm != nil && spec != "" {
+		sched, _ := cron.Parse(spec) // already validated by config.Load
+		background.Go(func() { enqueueOnSchedule(bgCtx, runner, sched, "bookmarks") })
+	}
+	if spec := cfg.Vault.ExportSchedule; spec != "" {
+		sched, _ := cron.Parse(spec) // already validated by config.Load
+		background.Go(func() { enqueueOnSchedule(bgCtx, runner, sched, "vault") })
+	}
+	if spec := cfg.Notion.SyncSchedule; nc != nil && spec != "" {
+		sched, _ := cron.Parse(spec) // already validated by config.Load
+		background.Go(func() { enqueueOnSchedule(bgCtx, runner, sched, "notion") })
+	}
+
+	// Bind to localhost unless configured otherwise; config.Load requires
+	// an auth token for any other address.
+	port := strconv.Itoa(cfg.Server.Port)
+	addr := net.JoinHostPort(cfg.Server.Listen, port)
+	if !config.IsLoopback(cfg.Server.Listen) {
+		slog.Info("accepting connections from other machines; requests need the auth token", "listen", cfg.Server.Listen)
+	}
+	scheme, browseHost := "http", "localhost"
+	if cfg.Server.TLSEnabled() {
+		scheme = "https"
+	}
+	if cfg.Server.ACMEHost != "" {
+		browseHost = cfg.Server.ACMEHost // the certificate only covers that name
+	}
+
+	// Auto-open browser after a short delay to let the server start, signed
+	// in with a one-time login code if a token is required, so the token
+	// itself never shows up in the browser's history or the process list. Headless servers have no UI to open.
+	if cfg.Server.Headless {
+		slog.Info("headless: serving the API without the web UI")
+	} else if cfg.Server.OpenBrowser() {
+		browseURL := scheme + "://" + net.JoinHostPort(browseHost, port) + "/"
+		if cfg.Server.AuthToken != "" {
+			browseURL += "?login=" + url.QueryEscape(api.LoginCode(cfg.Server.AuthToken, time.Now().Add(time.Minute)))
+		}
+		go func() {
+			time.Sleep(500 * time.Millisecond)
+			openBrowser(browseURL)
+		}()
+	}
+
+	// Serve until SIGINT or SIGTERM. Requests run under reqCtx, which is
+	// cancelled only if they outlive the shutdown timeout.
+	sigCtx, stopSignals := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stopSignals()
+	reqCtx, cancelRequests := context.WithCancel(context.Background())
+	defer cancelRequests()
+	var handler http.Handler = router
+	if path := cfg.Server.AccessLog; path != "" {
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(o.dataDir, path)
+		}
+		accessLog, err := logfile.Open(path, int64(cfg.Server.AccessLogMaxMB)<<20, cfg.Server.AccessLogKeep)
+		if err != nil {
+			slog.Error("failed to open access log", "error", err)
+			os.Exit(1)
+		}
+		defer accessLog.Close()
+		handler = api.AccessLog(slog.New(slog.NewJSONHandler(accessLog, nil)))(router)
+	}
+	srv := &http.Server{
+		Addr:              addr,
+		Handler:           handler,
+		ReadHeaderTimeout: readHeaderTimeout,
+		ReadTimeout:       readTimeout,
+		IdleTimeout:       idleTimeout,
+		BaseContext:       func(net.Listener) context.Context { return reqCtx },
+	}
+
+	// Speak HTTPS with the configured certificate or one from Let's
+	// Encrypt, cached in the data directory, or else plain HTTP.
+	serveErr := make(chan error, 1)
+	switch {
+	case cfg.Server.ACMEHost != "":
+		certs := &autocert.Manager{
+			Prompt:     autocert.AcceptTOS,
+			HostPolicy: autocert.HostWhitelist(cfg.Server.ACMEHost),
+			Cache:      autocert.DirCache(filepath.Join(o.dataDir, "certs")),
+			Email:      cfg.Server.ACMEEmail,
+		}
+		srv.TLSConfig = certs.TLSConfig()
+		go func() { serveErr <- srv.ListenAndServeTLS("", "") }()
+	case cfg.Server.TLSCert != "":
+		go func() { serveErr <- srv.ListenAndServeTLS(cfg.Server.TLSCert, cfg.Server.TLSKey) }()
+	default:
+		go func() { serveErr <- srv.ListenAndServe() }()
+	}
+	slog.Info("starting server", "addr", scheme+"://"+addr)
+
+	select {
+	case err := <-serveErr:
+		slog.Error("server failed", "error", err)
+		stopBackground()
+		background.Wait()
+		store.Close()
+		os.Exit(1)
+	case <-sigCtx.Done():
+	}
+	stopSignals() // a second Ctrl-C kills the process at once
+
+	// Stop accepting connections and let in-flight requests finish, then
+	// cancel the stragglers. Background work stops next, and the deferred
+	// Close checkpoints the database, so an interrupted write never leaves
+	// a hot WAL behind.
+	timeout := time.Duration(cfg.Server.ShutdownTimeoutSeconds) * time.Second
+	slog.Info("shutting down", "timeout", timeout)
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		slog.Warn("in-flight requests did not finish in time; cancelling them", "error", err)
+		cancelRequests()
+		srv.Close()
+	}
+	stopBackground()
+	background.Wait()
+	slog.Info("server stopped")
+	return nil
+}
+
+// notifyTargets returns the notification targets configured in cfg.
+func notifyTargets(cfg *config.Config) []notify.Target {
+	var targets []notify.Target
+	for _, hook := range cfg.Webhooks {
+		targets = append(targets, notify.Webhook{URL: hook.URL, Events: hook.Events, Secret: hook.Secret})
+	}
+	for _, slack := range cfg.Slack {
+		targets = append(targets, notify.Slack{WebhookURL: slack.WebhookURL, Top: slack.Top})
+	}
+	for _, discord := range cfg.Discord {
+		targets = append(targets, notify.Discord{WebhookURL: discord.WebhookURL, Top: discord.Top})
+	}
+	for _, tg := range cfg.Telegram {
+		targets = append(targets, notify.Telegram{Token: tg.BotToken, ChatID: tg.ChatID, Top: tg.Top})
+	}
+	appURL := ""
+	if cfg.Server.PublicURL != "" {
+		appURL = strings.TrimRight(cfg.Server.PublicURL, "/") + "/"
+	}
+	for _, n := range cfg.Ntfy {
+		targets = append(targets, notify.Ntfy{
+			Server:     n.Server,
+			Topic:      n.Topic,
+			Token:      n.Token,
+			MustRead:   n.MustRead,
+			ManualRuns: n.ManualRuns,
+			AppURL:     appURL,
+		})
+	}
+	return targets
+}
+
+// pruneOldBlogs deletes blogs older than days that PruneBlogs considers
+// unkept, and cached AI responses older than days, now and then every
+// interval, until ctx is done. The database is vacuumed after each run that
+// deleted something.
+func pruneOldBlogs(ctx context.Context, store storage.Store, days int, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		cutoff := time.Now().AddDate(0, 0, -days)
+		n, err := store.PruneBlogs(ctx, cutoff)
+		if err != nil {
+			slog.Warn("failed to prune old blogs", "error", err)
+		} else if n > 0 {
+			slog.Info("pruned old blogs", "blogs", n)
+		}
+		cached, err := store.PruneAIResponses(ctx, cutoff)
+		if err != nil {
+			slog.Warn("failed to prune cached AI responses", "error", err)
+		} else if cached > 0 {
+			slog.Info("pruned cached AI responses", "responses", cached)
+		}
+		if n > 0 || cached > 0 {
+			if err := store.Vacuum(ctx); err != nil {
+				slog.Warn("failed to vacuum database", "error", err)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// discoverOnSchedule queues a discovery run each time the discover_schedule
+// setting fires, until ctx is done. The setting is read again at least once
+// a minute, so a change takes effect without a restart. If the schedule
+// fired since the latest session, while the server was not running, a run
+// is queued at once to make up for it.
+func discoverOnSchedule(ctx context.Context, store storage.Store, runner *jobs.Manager, cfg *config.Config) {
+	queue := func(reason string) {
+		job, err := handlers.QueueDiscovery(ctx, store, runner)
+		if err != nil {
+			slog.Warn("failed to queue scheduled discovery", "error", err)
+			return
+		}
+		slog.Info("queued scheduled discovery", "job", job.ID, "reason", reason)
+	}
+	// schedule returns the current schedule; the zero Schedule, which
+	// never fires, if discovery is not scheduled.
+	schedule := func() cron.Schedule {
+		spec := handlers.LoadSettings(ctx, store, cfg).DiscoverSchedule
+		if spec == "" {
+			return cron.Schedule{}
+		}
+		sched, _ := cron.Parse(spec) // validated when set
+		return sched
+	}
+
+	last := time.Now()
+	if latest, err := store.GetLatestSession(ctx); err == nil {
+		if missed := schedule().Next(latest.CreatedAt.Local()); !missed.IsZero() && missed.Before(last) {
+			queue("missed while stopped")
+		}
+	}
+
+	for {
+		wait := time.Minute
+		if next := schedule().Next(last); !next.IsZero() {
+			wait = min(wait, time.Until(next))
+		}
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+		now := time.Now()
+		if next := schedule().Next(last); !next.IsZero() && !next.After(now) {
+			queue("scheduled")
+		}
+		last = now
+	}
+}
+
+// enqueueOnSchedule queues a job of kind, with an empty payload, each time
+// sched fires, until ctx is done. Runs missed while the server was stopped
+// are not made up; the next run of each scheduled job catches up on
+// everything since the last one that succeeded.
+func enqueueOnSchedule(ctx context.Context, runner *jobs.Manager, sched cron.Schedule, kind string) {
+	onSchedule(ctx, sched, func() {
+		job, err := runner.Enqueue(ctx, kind, struct{}{})
+		if err != nil {
+			slog.Warn("failed to queue scheduled job", "kind", kind, "error", err)
+			return
+		}
+		slog.Info("queued scheduled job", "kind", kind, "job", job.ID)
+	})
+}
+
+// onSchedule calls fn each time sched fires, until ctx is done.
+func onSchedule(ctx context.Context, sched cron.Schedule, fn func()) {
+	for {
+		next := sched.Next(time.Now())
+		if next.IsZero() {
+			slog.Warn("schedule never fires")
+			return
+		}
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+		fn()
+	}
+}
+
+// wakeSnoozedItems wakes due snoozed reading list items now and then every
+// interval, until ctx is done.
+func wakeSnoozedItems(ctx context.Context, store storage.ReadingListStore, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		n, err := store.WakeSnoozedItems(ctx, time.Now())
+		if err != nil {
+			slog.Warn("failed to wake snoozed items", "error", err)
+		} else if n > 0 {
+			slog.Info("woke snoozed items", "items", n)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// openBrowser opens the given URL in the user's default browser.
+// It is a fire-and-forget operation; errors are silently ignored.
+func openBrowser(url string) {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.Command("open", url)
+	case "linux":
+		cmd = exec.Command("xdg-open", url)
+	case "windows":
+		cmd = exec.Command("cmd", "/c", "start", url)
+	}
+	if cmd != nil {
+		_ = cmd.Start()
+	}
+}
@@ -0,0 +1,169 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	"github.com/hoanghai1803/apricot/internal/ai"
+	"github.com/hoanghai1803/apricot/internal/api/handlers"
+	"github.com/hoanghai1803/apricot/internal/feeds"
+	"github.com/hoanghai1803/apricot/internal/storage"
+)
+
+// summary is what summarize prints with -json.
+type summary struct {
+	BlogID     int64  `json:"blog_id,omitempty"` // set if Apricot has the post
+	URL        string `json:"url"`
+	Title      string `json:"title"`
+	Source     string `json:"source"`
+	Summary    string `json:"summary"`
+	Difficulty string `json:"difficulty,omitempty"`
+	Category   string `json:"category,omitempty"`
+}
+
+// summarize fetches the post at a URL, summarizes it with the configured AI
+// provider, and prints the summary alone, for use in pipelines. A post
+// Apricot has already summarized is not fetched again. Unless -save is
+// given, nothing is stored but the AI response cache; a post already on the
+// reading list is not added twice.
+func summarize(args []string) error {
+	var o options
+	fs := newFlagSet("summarize", "[flags] <url>", &o)
+	save := fs.Bool("save", false, "also add the post to the reading list, as add does")
+	source := fs.String("source", "", "with -save, source name to file the post under (default: the site's name)")
+	asJSON := fs.Bool("json", false, "print the post's title, source, and classification with the summary, as JSON")
+	fs.Parse(args) //nolint:errcheck // ExitOnError
+	if fs.NArg() != 1 {
+		fs.Usage()
+		os.Exit(2)
+	}
+	rawURL := strings.TrimSpace(fs.Arg(0))
+	if u, err := url.ParseRequestURI(rawURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return fmt.Errorf("%q is not an HTTP or HTTPS URL", rawURL)
+	}
+
+	cfg, store, err := o.open()
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+	aiProvider, err := newAIProvider(cfg, store)
+	if err != nil {
+		return fmt.Errorf("creating AI provider: %w", err)
+	}
+	if aiProvider == nil {
+		return errors.New("no AI provider configured: set ai.api_key in config.toml")
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(cfg.Server.DiscoveryTimeoutSeconds)*time.Second)
+	defer cancel()
+
+	var (
+		s      *summary
+		saved  bool
+		blogID int64
+	)
+	existing, err := store.GetBlogByURL(ctx, rawURL)
+	switch {
+	case err == nil:
+		blogID = existing.ID
+		if _, err := store.GetReadingListIDByBlogID(ctx, blogID); err == nil {
+			saved = true
+		}
+		if s, err = storedSummary(ctx, store, blogID); err != nil {
+			return err
+		}
+	case !errors.Is(err, storage.ErrNotFound):
+		return fmt.Errorf("looking up post: %w", err)
+	}
+
+	if *save && !saved {
+		if blogID, err = handlers.AddURL(ctx, store, newFetcher(cfg), aiProvider, cfg, rawURL, *source); err != nil {
+			return err
+		}
+		if s, err = storedSummary(ctx, store, blogID); err != nil {
+			return err
+		}
+		if s == nil {
+			return errors.New("added the post, but could not summarize it")
+		}
+	}
+	if s == nil {
+		if s, err = fetchSummary(ctx, newFetcher(cfg), aiProvider, rawURL); err != nil {
+			return err
+		}
+	}
+
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(s)
+	}
+	fmt.Println(s.Summary)
+	return nil
+}
+
+// storedSummary returns the stored post blogID with its summary, or nil if
+// it has none or the post has changed since it was summarized.
+func storedSummary(ctx context.Context, store storage.Store, blogID int64) (*summary, error) {
+	blog, err := store.GetBlogByID(ctx, blogID)
+	if err != nil {
+		return nil, fmt.Errorf("loading post: %w", err)
+	}
+	cached, err := store.GetSummaryByBlogID(ctx, blogID)
+	if errors.Is(err, storage.ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("loading summary: %w", err)
+	}
+	if cached.Stale || cached.Summary == "" {
+		return nil, nil
+	}
+	return &summary{
+		BlogID:     blog.ID,
+		URL:        blog.URL,
+		Title:      blog.Title,
+		Source:     blog.Source,
+		Summary:    cached.Summary,
+		Difficulty: cached.Difficulty,
+		Category:   cached.Category,
+	}, nil
+}
+
+// fetchSummary fetches and extracts the post at rawURL and summarizes it.
+func fetchSummary(ctx context.Context, fetcher *feeds.Fetcher, aiProvider ai.AIProvider, rawURL string) (*summary, error) {
+	meta, err := fetcher.ExtractArticleMetadata(ctx, rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("fetching post: %w", err)
+	}
+	s := &summary{URL: rawURL, Title: meta.Title, Source: meta.SiteName}
+	if s.Title == "" {
+		s.Title = rawURL
+	}
+	if s.Source == "" {
+		u, _ := url.Parse(rawURL) // validated by summarize
+		s.Source = u.Hostname()
+	}
+
+	result, err := aiProvider.Summarize(ctx, ai.BlogEntry{
+		Title:       s.Title,
+		Source:      s.Source,
+		Description: meta.Excerpt,
+		FullContent: meta.TextContent,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("summarizing post: %w", err)
+	}
+	s.Summary, s.Difficulty, s.Category = result.Text, result.Difficulty, result.Category
+	return s, nil
+}
@@ -0,0 +1,35 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"os/signal"
+
+	"github.com/hoanghai1803/apricot/internal/tui"
+)
+
+// runTUI shows the terminal UI for browsing the latest discovery results
+// and the reading list.
+func runTUI(args []string) error {
+	var o options
+	fs := newFlagSet("tui", "[flags]", &o)
+	fs.Parse(args) //nolint:errcheck // ExitOnError
+	if fs.NArg() > 0 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	_, store, err := o.open()
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	// Log records would draw over the screen; the UI shows errors itself.
+	slog.SetDefault(slog.New(slog.DiscardHandler))
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	return tui.Run(ctx, store)
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// vacuum runs database maintenance, as POST /api/admin/maintenance does: it
+// rebuilds the search index, reclaims free space, and refreshes the query
+// planner's statistics.
+func vacuum(args []string) error {
+	var o options
+	fs := newFlagSet("vacuum", "[flags]", &o)
+	dryRun := fs.Bool("dry-run", false, "report the database size and the space a vacuum would reclaim without running it")
+	fs.Parse(args) //nolint:errcheck // ExitOnError
+	if fs.NArg() > 0 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	_, store, err := o.open()
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+	ctx := context.Background()
+
+	if *dryRun {
+		stats, err := store.DBStats(ctx)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Database size:   %s\n", formatBytes(stats.SizeBytes))
+		if stats.Driver == "sqlite" {
+			fmt.Printf("Reclaimable:     %s\n", formatBytes(stats.FreeBytes))
+			fmt.Printf("Write-ahead log: %s\n", formatBytes(stats.WALSizeBytes))
+		}
+		last := "never"
+		if stats.LastMaintenanceAt != nil {
+			last = stats.LastMaintenanceAt.Local().Format("2006-01-02 15:04")
+		}
+		fmt.Printf("Last maintained: %s\n", last)
+		return nil
+	}
+
+	result, err := store.RunMaintenance(ctx)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Ran %s in %dms.\n", strings.Join(result.Steps, ", "), result.DurationMs)
+	fmt.Printf("Database size: %s before, %s after.\n",
+		formatBytes(result.SizeBeforeBytes), formatBytes(result.SizeAfterBytes))
+	return nil
+}
+
+// formatBytes formats n bytes with a binary unit, such as "1.5 MiB".
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
@@ -9,18 +9,29 @@ import (
 	"log/slog"
 	"net/http"
 	"time"
+
+	"github.com/hoanghai1803/apricot/internal/outbound"
 )
 
 // Compile-time interface check.
 var _ AIProvider = (*AnthropicProvider)(nil)
 
-const anthropicAPIURL = "https://api.anthropic.com/v1/messages"
+const (
+	anthropicAPIURL       = "https://api.anthropic.com/v1/messages"
+	anthropicModelsAPIURL = "https://api.anthropic.com/v1/models"
+
+	// anthropicMaxTokens is the response limit of requests that do not
+	// set one. The Messages API requires a limit.
+	anthropicMaxTokens = 1024
+)
 
 // AnthropicProvider implements AIProvider using the Anthropic Messages API.
 type AnthropicProvider struct {
 	apiKey string
 	model  string
 	client *http.Client
+
+	rank, summarize ModelParams
 }
 
 // NewAnthropicProvider creates an AnthropicProvider with a 60-second timeout
@@ -30,17 +41,20 @@ func NewAnthropicProvider(apiKey, model string) *AnthropicProvider {
 		apiKey: apiKey,
 		model:  model,
 		client: &http.Client{
-			Timeout: 60 * time.Second,
+			Timeout:   60 * time.Second,
+			Transport: &outbound.Transport{Purpose: outbound.PurposeAI},
 		},
 	}
 }
 
 // anthropicRequest is the request body for the Anthropic Messages API.
 type anthropicRequest struct {
-	Model     string             `json:"model"`
-	MaxTokens int                `json:"max_tokens"`
-	System    string             `json:"system"`
-	Messages  []anthropicMessage `json:"messages"`
+	Model       string             `json:"model"`
+	MaxTokens   int                `json:"max_tokens"`
+	Temperature *float64           `json:"temperature,omitempty"`
+	TopP        *float64           `json:"top_p,omitempty"`
+	System      string             `json:"system"`
+	Messages    []anthropicMessage `json:"messages"`
 }
 
 // anthropicMessage is a single message in the Anthropic request.
@@ -64,7 +78,7 @@ type anthropicResponse struct {
 func (p *AnthropicProvider) FilterAndRank(ctx context.Context, preferences string, blogs []BlogEntry, maxResults int, serendipity bool) ([]RankedBlog, error) {
 	systemPrompt, userPrompt := FilterAndRankPrompt(preferences, blogs, maxResults, serendipity)
 
-	text, err := p.callAPI(ctx, systemPrompt, userPrompt)
+	text, err := p.callAPI(ctx, p.rank, systemPrompt, userPrompt)
 	if err != nil {
 		return nil, fmt.Errorf("anthropic filter-and-rank: %w", err)
 	}
@@ -79,9 +93,26 @@ func (p *AnthropicProvider) FilterAndRank(ctx context.Context, preferences strin
 	return ranked, nil
 }
 
-// Summarize generates a concise summary of the given blog post using the
-// Anthropic Messages API.
-func (p *AnthropicProvider) Summarize(ctx context.Context, blog BlogEntry) (string, error) {
+// BuildLearningPath selects and orders blogs into a learning path on topic
+// using the Anthropic Messages API.
+func (p *AnthropicProvider) BuildLearningPath(ctx context.Context, topic string, blogs []BlogEntry, maxItems int) ([]RankedBlog, error) {
+	systemPrompt, userPrompt := LearningPathPrompt(topic, blogs, maxItems)
+
+	text, err := p.callAPI(ctx, p.rank, systemPrompt, userPrompt)
+	if err != nil {
+		return nil, fmt.Errorf("anthropic learning path: %w", err)
+	}
+
+	var ranked []RankedBlog
+	if err := json.Unmarshal([]byte(extractJSON(text)), &ranked); err != nil {
+		return nil, fmt.Errorf("anthropic learning path: parsing response JSON: %w", err)
+	}
+	return ranked, nil
+}
+
+// Summarize generates a concise summary, difficulty level, and category for
+// the given blog post using the Anthropic Messages API.
+func (p *AnthropicProvider) Summarize(ctx context.Context, blog BlogEntry) (Summary, error) {
 	content := blog.FullContent
 	if content == "" {
 		content = blog.Description
@@ -89,25 +120,162 @@ func (p *AnthropicProvider) Summarize(ctx context.Context, blog BlogEntry) (stri
 
 	systemPrompt, userPrompt := SummarizePrompt(blog.Title, blog.Source, content)
 
-	text, err := p.callAPI(ctx, systemPrompt, userPrompt)
+	text, err := p.callAPI(ctx, p.summarize, systemPrompt, userPrompt)
+	if err != nil {
+		return Summary{}, fmt.Errorf("anthropic summarize: %w", err)
+	}
+
+	return parseSummary(text), nil
+}
+
+// ClassifyDifficulty estimates the difficulty level of the given blog post
+// using the Anthropic Messages API.
+func (p *AnthropicProvider) ClassifyDifficulty(ctx context.Context, blog BlogEntry) (string, error) {
+	content := blog.FullContent
+	if content == "" {
+		content = blog.Description
+	}
+
+	systemPrompt, userPrompt := ClassifyDifficultyPrompt(blog.Title, blog.Source, content)
+
+	text, err := p.callAPI(ctx, ModelParams{}, systemPrompt, userPrompt)
 	if err != nil {
-		return "", fmt.Errorf("anthropic summarize: %w", err)
+		return "", fmt.Errorf("anthropic classify difficulty: %w", err)
+	}
+
+	difficulty, err := parseDifficulty(text)
+	if err != nil {
+		return "", fmt.Errorf("anthropic classify difficulty: %w", err)
+	}
+	return difficulty, nil
+}
+
+// RewriteTitle rewrites the given blog post's title into a factual one
+// using the Anthropic Messages API.
+func (p *AnthropicProvider) RewriteTitle(ctx context.Context, blog BlogEntry) (string, error) {
+	systemPrompt, userPrompt := RewriteTitlePrompt(blog.Title, blog.Source, blog.Description)
+
+	text, err := p.callAPI(ctx, ModelParams{}, systemPrompt, userPrompt)
+	if err != nil {
+		return "", fmt.Errorf("anthropic rewrite title: %w", err)
+	}
+	return parseRewrittenTitle(text, blog.Title), nil
+}
+
+// SuggestPrerequisites lists the concepts to understand before reading the
+// given blog post using the Anthropic Messages API.
+func (p *AnthropicProvider) SuggestPrerequisites(ctx context.Context, blog BlogEntry) ([]Prerequisite, error) {
+	content := blog.FullContent
+	if content == "" {
+		content = blog.Description
+	}
+
+	systemPrompt, userPrompt := PrerequisitesPrompt(blog.Title, blog.Source, content)
+
+	text, err := p.callAPI(ctx, ModelParams{}, systemPrompt, userPrompt)
+	if err != nil {
+		return nil, fmt.Errorf("anthropic prerequisites: %w", err)
+	}
+
+	prereqs, err := parsePrerequisites(text)
+	if err != nil {
+		return nil, fmt.Errorf("anthropic prerequisites: %w", err)
+	}
+	return prereqs, nil
+}
+
+// SynthesizeAnswer answers a research question from the given sources using
+// the Anthropic Messages API.
+func (p *AnthropicProvider) SynthesizeAnswer(ctx context.Context, question string, blogs []BlogEntry) (string, error) {
+	systemPrompt, userPrompt := ResearchPrompt(question, blogs)
+
+	text, err := p.callAPI(ctx, ModelParams{}, systemPrompt, userPrompt)
+	if err != nil {
+		return "", fmt.Errorf("anthropic research: %w", err)
 	}
 
 	return text, nil
 }
 
+// NarrateYear writes a narrative of the year's reading themes using the
+// Anthropic Messages API.
+func (p *AnthropicProvider) NarrateYear(ctx context.Context, year int, blogs []BlogEntry) (string, error) {
+	systemPrompt, userPrompt := YearInReviewPrompt(year, blogs)
+
+	text, err := p.callAPI(ctx, ModelParams{}, systemPrompt, userPrompt)
+	if err != nil {
+		return "", fmt.Errorf("anthropic year in review: %w", err)
+	}
+
+	return text, nil
+}
+
+// ListModels returns the models available to the API key using the
+// Anthropic Models API.
+func (p *AnthropicProvider) ListModels(ctx context.Context) ([]ModelInfo, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, anthropicModelsAPIURL+"?limit=1000", nil)
+	if err != nil {
+		return nil, fmt.Errorf("anthropic list models: creating request: %w", err)
+	}
+	req.Header.Set("x-api-key", p.apiKey)
+	req.Header.Set("anthropic-version", "2023-06-01")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("anthropic list models: sending request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var apiResp struct {
+		Data []struct {
+			ID          string `json:"id"`
+			DisplayName string `json:"display_name"`
+		} `json:"data"`
+		Error *struct {
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&apiResp); err != nil {
+		return nil, fmt.Errorf("anthropic list models: parsing response (status %d): %w", resp.StatusCode, err)
+	}
+	if apiResp.Error != nil {
+		return nil, fmt.Errorf("anthropic list models: API error (status %d): %s", resp.StatusCode, apiResp.Error.Message)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("anthropic list models: unexpected status code: %d", resp.StatusCode)
+	}
+
+	models := make([]ModelInfo, 0, len(apiResp.Data))
+	for _, m := range apiResp.Data {
+		models = append(models, ModelInfo{ID: m.ID, DisplayName: m.DisplayName})
+	}
+	return models, nil
+}
+
+// Ping sends a minimal message to verify the API key and model name.
+func (p *AnthropicProvider) Ping(ctx context.Context) error {
+	if _, err := p.callAPI(ctx, ModelParams{}, pingSystemPrompt, pingUserPrompt); err != nil {
+		return fmt.Errorf("anthropic ping: %w", err)
+	}
+	return nil
+}
+
 // callAPI makes an HTTP request to the Anthropic Messages API and returns
 // the text content from the first content block.
-func (p *AnthropicProvider) callAPI(ctx context.Context, systemPrompt, userPrompt string) (string, error) {
+func (p *AnthropicProvider) callAPI(ctx context.Context, params ModelParams, systemPrompt, userPrompt string) (string, error) {
 	reqBody := anthropicRequest{
-		Model:     p.model,
-		MaxTokens: 1024,
-		System:    systemPrompt,
+		Model:       p.model,
+		MaxTokens:   params.MaxTokens,
+		Temperature: params.Temperature,
+		TopP:        params.TopP,
+		System:      systemPrompt,
 		Messages: []anthropicMessage{
 			{Role: "user", Content: userPrompt},
 		},
 	}
+	if reqBody.MaxTokens == 0 {
+		reqBody.MaxTokens = anthropicMaxTokens
+	}
 
 	body, err := json.Marshal(reqBody)
 	if err != nil {
@@ -123,7 +291,7 @@ func (p *AnthropicProvider) callAPI(ctx context.Context, systemPrompt, userPromp
 	req.Header.Set("anthropic-version", "2023-06-01")
 	req.Header.Set("content-type", "application/json")
 
-	slog.Debug("calling Anthropic API", "model", p.model)
+	slog.DebugContext(ctx, "calling Anthropic API", "model", p.model)
 
 	resp, err := p.client.Do(req)
 	if err != nil {
@@ -0,0 +1,266 @@
+package ai
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+)
+
+// Compile-time interface check.
+var _ AIProvider = (*CachingProvider)(nil)
+
+// ResponseCache persists raw AI responses under an opaque cache key.
+// storage.Store satisfies this interface.
+type ResponseCache interface {
+	GetAIResponse(ctx context.Context, key string) (string, error)
+	PutAIResponse(ctx context.Context, key, operation, model, response string) error
+}
+
+// CachingProvider wraps an AIProvider and memoizes its responses. Entries are
+// keyed on a hash of the prompt content, PromptVersion, model, and model
+// parameters, so re-running discovery over unchanged candidates costs no
+// tokens, while any change to an article's content, the prompt templates,
+// the model, or its [ai.rank] and [ai.summarize] settings produces a fresh
+// call.
+type CachingProvider struct {
+	next            AIProvider
+	cache           ResponseCache
+	model           string
+	rank, summarize ModelParams
+}
+
+// NewCachingProvider returns a CachingProvider that delegates cache misses to
+// next, which was created from cfg. The model name is part of every cache
+// key. cfg.Rank and cfg.Summarize are part of the keys of the requests they
+// apply to.
+func NewCachingProvider(next AIProvider, cache ResponseCache, cfg ProviderConfig) *CachingProvider {
+	return &CachingProvider{
+		next:      next,
+		cache:     cache,
+		model:     cfg.Model,
+		rank:      cfg.Rank,
+		summarize: cfg.Summarize,
+	}
+}
+
+// FilterAndRank returns a cached ranking for an identical candidate set and
+// preferences, or delegates to the wrapped provider and caches its result.
+func (p *CachingProvider) FilterAndRank(ctx context.Context, preferences string, blogs []BlogEntry, maxResults int, serendipity bool) ([]RankedBlog, error) {
+	systemPrompt, userPrompt := FilterAndRankPrompt(preferences, blogs, maxResults, serendipity)
+	key := p.cacheKey("filter_and_rank", p.rank, systemPrompt, userPrompt)
+
+	if cached, err := p.cache.GetAIResponse(ctx, key); err == nil {
+		var ranked []RankedBlog
+		if err := json.Unmarshal([]byte(cached), &ranked); err == nil {
+			slog.DebugContext(ctx, "ai cache hit", "operation", "filter_and_rank")
+			return ranked, nil
+		}
+	}
+
+	ranked, err := p.next.FilterAndRank(ctx, preferences, blogs, maxResults, serendipity)
+	if err != nil {
+		return nil, err
+	}
+
+	if data, err := json.Marshal(ranked); err == nil {
+		p.store(ctx, key, "filter_and_rank", string(data))
+	}
+	return ranked, nil
+}
+
+// BuildLearningPath returns a cached path for an identical topic and
+// candidate set, or delegates to the wrapped provider and caches its result.
+func (p *CachingProvider) BuildLearningPath(ctx context.Context, topic string, blogs []BlogEntry, maxItems int) ([]RankedBlog, error) {
+	systemPrompt, userPrompt := LearningPathPrompt(topic, blogs, maxItems)
+	key := p.cacheKey("learning_path", p.rank, systemPrompt, userPrompt)
+
+	if cached, err := p.cache.GetAIResponse(ctx, key); err == nil {
+		var ranked []RankedBlog
+		if err := json.Unmarshal([]byte(cached), &ranked); err == nil {
+			slog.DebugContext(ctx, "ai cache hit", "operation", "learning_path")
+			return ranked, nil
+		}
+	}
+
+	ranked, err := p.next.BuildLearningPath(ctx, topic, blogs, maxItems)
+	if err != nil {
+		return nil, err
+	}
+
+	if data, err := json.Marshal(ranked); err == nil {
+		p.store(ctx, key, "learning_path", string(data))
+	}
+	return ranked, nil
+}
+
+// Summarize returns a cached summary for identical article content, or
+// delegates to the wrapped provider and caches its result.
+func (p *CachingProvider) Summarize(ctx context.Context, blog BlogEntry) (Summary, error) {
+	content := blog.FullContent
+	if content == "" {
+		content = blog.Description
+	}
+	systemPrompt, userPrompt := SummarizePrompt(blog.Title, blog.Source, content)
+	key := p.cacheKey("summarize", p.summarize, systemPrompt, userPrompt)
+
+	if cached, err := p.cache.GetAIResponse(ctx, key); err == nil {
+		var summary Summary
+		if err := json.Unmarshal([]byte(cached), &summary); err == nil {
+			slog.DebugContext(ctx, "ai cache hit", "operation", "summarize")
+			return summary, nil
+		}
+	}
+
+	summary, err := p.next.Summarize(ctx, blog)
+	if err != nil {
+		return Summary{}, err
+	}
+
+	if data, err := json.Marshal(summary); err == nil {
+		p.store(ctx, key, "summarize", string(data))
+	}
+	return summary, nil
+}
+
+// ClassifyDifficulty returns a cached difficulty for identical article
+// content, or delegates to the wrapped provider and caches its result.
+func (p *CachingProvider) ClassifyDifficulty(ctx context.Context, blog BlogEntry) (string, error) {
+	content := blog.FullContent
+	if content == "" {
+		content = blog.Description
+	}
+	systemPrompt, userPrompt := ClassifyDifficultyPrompt(blog.Title, blog.Source, content)
+
+	return p.cachedText(ctx, "classify_difficulty", systemPrompt, userPrompt, func() (string, error) {
+		return p.next.ClassifyDifficulty(ctx, blog)
+	})
+}
+
+// RewriteTitle returns a cached title for an identical title and
+// description, or delegates to the wrapped provider and caches its result.
+func (p *CachingProvider) RewriteTitle(ctx context.Context, blog BlogEntry) (string, error) {
+	systemPrompt, userPrompt := RewriteTitlePrompt(blog.Title, blog.Source, blog.Description)
+
+	return p.cachedText(ctx, "rewrite_title", systemPrompt, userPrompt, func() (string, error) {
+		return p.next.RewriteTitle(ctx, blog)
+	})
+}
+
+// SuggestPrerequisites returns cached prerequisites for identical article
+// content, or delegates to the wrapped provider and caches its result.
+func (p *CachingProvider) SuggestPrerequisites(ctx context.Context, blog BlogEntry) ([]Prerequisite, error) {
+	content := blog.FullContent
+	if content == "" {
+		content = blog.Description
+	}
+	systemPrompt, userPrompt := PrerequisitesPrompt(blog.Title, blog.Source, content)
+	key := p.cacheKey("prerequisites", ModelParams{}, systemPrompt, userPrompt)
+
+	if cached, err := p.cache.GetAIResponse(ctx, key); err == nil {
+		var prereqs []Prerequisite
+		if err := json.Unmarshal([]byte(cached), &prereqs); err == nil {
+			slog.DebugContext(ctx, "ai cache hit", "operation", "prerequisites")
+			return prereqs, nil
+		}
+	}
+
+	prereqs, err := p.next.SuggestPrerequisites(ctx, blog)
+	if err != nil {
+		return nil, err
+	}
+
+	if data, err := json.Marshal(prereqs); err == nil {
+		p.store(ctx, key, "prerequisites", string(data))
+	}
+	return prereqs, nil
+}
+
+// SynthesizeAnswer returns a cached answer for an identical question and
+// source set, or delegates to the wrapped provider and caches its result.
+func (p *CachingProvider) SynthesizeAnswer(ctx context.Context, question string, blogs []BlogEntry) (string, error) {
+	systemPrompt, userPrompt := ResearchPrompt(question, blogs)
+
+	return p.cachedText(ctx, "research", systemPrompt, userPrompt, func() (string, error) {
+		return p.next.SynthesizeAnswer(ctx, question, blogs)
+	})
+}
+
+// NarrateYear returns a cached narrative for an identical year and reading
+// history, or delegates to the wrapped provider and caches its result.
+func (p *CachingProvider) NarrateYear(ctx context.Context, year int, blogs []BlogEntry) (string, error) {
+	systemPrompt, userPrompt := YearInReviewPrompt(year, blogs)
+
+	return p.cachedText(ctx, "year_in_review", systemPrompt, userPrompt, func() (string, error) {
+		return p.next.NarrateYear(ctx, year, blogs)
+	})
+}
+
+// ListModels delegates to the wrapped provider; model listings are never
+// cached.
+func (p *CachingProvider) ListModels(ctx context.Context) ([]ModelInfo, error) {
+	return p.next.ListModels(ctx)
+}
+
+// Ping delegates to the wrapped provider; credential checks are never cached.
+func (p *CachingProvider) Ping(ctx context.Context) error {
+	return p.next.Ping(ctx)
+}
+
+// cachedText returns the cached plain-text response for the given prompts,
+// or calls fn on a miss and caches its result.
+func (p *CachingProvider) cachedText(ctx context.Context, operation, systemPrompt, userPrompt string, fn func() (string, error)) (string, error) {
+	key := p.cacheKey(operation, ModelParams{}, systemPrompt, userPrompt)
+
+	if cached, err := p.cache.GetAIResponse(ctx, key); err == nil {
+		slog.DebugContext(ctx, "ai cache hit", "operation", operation)
+		return cached, nil
+	}
+
+	text, err := fn()
+	if err != nil {
+		return "", err
+	}
+
+	p.store(ctx, key, operation, text)
+	return text, nil
+}
+
+// store writes a response to the cache. Failures are logged and otherwise
+// ignored: a cache write error must never fail the AI call itself.
+func (p *CachingProvider) store(ctx context.Context, key, operation, response string) {
+	if err := p.cache.PutAIResponse(ctx, key, operation, p.model, response); err != nil {
+		slog.WarnContext(ctx, "failed to cache ai response", "operation", operation, "error", err)
+	}
+}
+
+// cacheKey derives the cache key from the operation, PromptVersion, model,
+// the model parameters of the request, and a SHA-256 hash of the rendered
+// prompts. Default parameters add nothing to the key, so responses cached
+// before they could be set stay valid.
+func (p *CachingProvider) cacheKey(operation string, params ModelParams, systemPrompt, userPrompt string) string {
+	content := sha256.Sum256([]byte(systemPrompt + "\x00" + userPrompt))
+	key := operation + "\x00" + strconv.Itoa(PromptVersion) + "\x00" + p.model + "\x00" + fmt.Sprintf("%x", content)
+	if params != (ModelParams{}) {
+		key += "\x00" + params.cacheKey()
+	}
+	return fmt.Sprintf("%x", sha256.Sum256([]byte(key)))
+}
+
+// cacheKey renders the parameters set in mp for CachingProvider.cacheKey.
+func (mp ModelParams) cacheKey() string {
+	var b strings.Builder
+	if mp.Temperature != nil {
+		fmt.Fprintf(&b, "temperature=%g;", *mp.Temperature)
+	}
+	if mp.MaxTokens != 0 {
+		fmt.Fprintf(&b, "max_tokens=%d;", mp.MaxTokens)
+	}
+	if mp.TopP != nil {
+		fmt.Fprintf(&b, "top_p=%g;", *mp.TopP)
+	}
+	return b.String()
+}
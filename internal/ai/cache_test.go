@@ -0,0 +1,191 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+// countingProvider is a fake AIProvider that counts calls.
+type countingProvider struct {
+	rankCalls      int
+	summarizeCalls int
+	prereqCalls    int
+}
+
+func (p *countingProvider) FilterAndRank(_ context.Context, _ string, blogs []BlogEntry, _ int, _ bool) ([]RankedBlog, error) {
+	p.rankCalls++
+	ranked := make([]RankedBlog, 0, len(blogs))
+	for _, b := range blogs {
+		ranked = append(ranked, RankedBlog{ID: b.ID, Reason: "matches"})
+	}
+	return ranked, nil
+}
+
+func (p *countingProvider) BuildLearningPath(ctx context.Context, _ string, blogs []BlogEntry, maxItems int) ([]RankedBlog, error) {
+	return p.FilterAndRank(ctx, "", blogs, maxItems, false)
+}
+
+func (p *countingProvider) Summarize(_ context.Context, blog BlogEntry) (Summary, error) {
+	p.summarizeCalls++
+	return Summary{Text: "summary of " + blog.Title, Difficulty: "intro", Category: "databases"}, nil
+}
+
+func (p *countingProvider) ClassifyDifficulty(context.Context, BlogEntry) (string, error) {
+	return "intro", nil
+}
+
+func (p *countingProvider) RewriteTitle(_ context.Context, blog BlogEntry) (string, error) {
+	return blog.Title, nil
+}
+
+func (p *countingProvider) SuggestPrerequisites(context.Context, BlogEntry) ([]Prerequisite, error) {
+	p.prereqCalls++
+	return []Prerequisite{{Concept: "hashing", Keywords: "hash"}}, nil
+}
+
+func (p *countingProvider) SynthesizeAnswer(context.Context, string, []BlogEntry) (string, error) {
+	return "answer [1]", nil
+}
+
+func (p *countingProvider) NarrateYear(_ context.Context, year int, _ []BlogEntry) (string, error) {
+	return "narrative", nil
+}
+
+func (p *countingProvider) ListModels(context.Context) ([]ModelInfo, error) {
+	return []ModelInfo{{ID: "model-a"}}, nil
+}
+
+func (p *countingProvider) Ping(context.Context) error {
+	return nil
+}
+
+// mapCache is an in-memory ResponseCache.
+type mapCache map[string]string
+
+func (c mapCache) GetAIResponse(_ context.Context, key string) (string, error) {
+	v, ok := c[key]
+	if !ok {
+		return "", errors.New("not found")
+	}
+	return v, nil
+}
+
+func (c mapCache) PutAIResponse(_ context.Context, key, _, _, response string) error {
+	c[key] = response
+	return nil
+}
+
+func TestCachingProvider_Summarize(t *testing.T) {
+	ctx := context.Background()
+	next := &countingProvider{}
+	p := NewCachingProvider(next, mapCache{}, ProviderConfig{Model: "model-a"})
+
+	blog := BlogEntry{ID: 1, Title: "Post", FullContent: "original content"}
+
+	for range 2 {
+		got, err := p.Summarize(ctx, blog)
+		if err != nil {
+			t.Fatalf("Summarize() error: %v", err)
+		}
+		want := Summary{Text: "summary of Post", Difficulty: "intro", Category: "databases"}
+		if got != want {
+			t.Errorf("Summarize() = %+v, want %+v", got, want)
+		}
+	}
+	if next.summarizeCalls != 1 {
+		t.Errorf("provider called %d times for unchanged content, want 1", next.summarizeCalls)
+	}
+
+	blog.FullContent = "updated content"
+	if _, err := p.Summarize(ctx, blog); err != nil {
+		t.Fatalf("Summarize() error: %v", err)
+	}
+	if next.summarizeCalls != 2 {
+		t.Errorf("provider called %d times after content change, want 2", next.summarizeCalls)
+	}
+}
+
+func TestCachingProvider_FilterAndRank(t *testing.T) {
+	ctx := context.Background()
+	next := &countingProvider{}
+	cache := mapCache{}
+	p := NewCachingProvider(next, cache, ProviderConfig{Model: "model-a"})
+
+	blogs := []BlogEntry{{ID: 1, Title: "A"}, {ID: 2, Title: "B"}}
+
+	for range 2 {
+		ranked, err := p.FilterAndRank(ctx, "go", blogs, 10, false)
+		if err != nil {
+			t.Fatalf("FilterAndRank() error: %v", err)
+		}
+		if len(ranked) != 2 || ranked[0].ID != 1 {
+			t.Errorf("FilterAndRank() = %+v, want IDs [1 2]", ranked)
+		}
+	}
+	if next.rankCalls != 1 {
+		t.Errorf("provider called %d times for unchanged candidates, want 1", next.rankCalls)
+	}
+
+	// A different model must not reuse entries from another model.
+	other := NewCachingProvider(next, cache, ProviderConfig{Model: "model-b"})
+	if _, err := other.FilterAndRank(ctx, "go", blogs, 10, false); err != nil {
+		t.Fatalf("FilterAndRank() error: %v", err)
+	}
+	if next.rankCalls != 2 {
+		t.Errorf("provider called %d times after model change, want 2", next.rankCalls)
+	}
+}
+
+func TestCachingProvider_ModelParams(t *testing.T) {
+	ctx := context.Background()
+	next := &countingProvider{}
+	cache := mapCache{}
+	blog := BlogEntry{ID: 1, Title: "Post", FullContent: "content"}
+
+	p := NewCachingProvider(next, cache, ProviderConfig{Model: "model-a"})
+	if _, err := p.Summarize(ctx, blog); err != nil {
+		t.Fatalf("Summarize() error: %v", err)
+	}
+
+	// Raising max_tokens must not reuse summaries cut off at the old limit.
+	longer := NewCachingProvider(next, cache, ProviderConfig{Model: "model-a", Summarize: ModelParams{MaxTokens: 4096}})
+	for range 2 {
+		if _, err := longer.Summarize(ctx, blog); err != nil {
+			t.Fatalf("Summarize() error: %v", err)
+		}
+	}
+	if next.summarizeCalls != 2 {
+		t.Errorf("provider called %d times after max_tokens change, want 2", next.summarizeCalls)
+	}
+
+	// Ranking parameters leave summaries alone.
+	ranked := NewCachingProvider(next, cache, ProviderConfig{Model: "model-a", Rank: ModelParams{MaxTokens: 4096}})
+	if _, err := ranked.Summarize(ctx, blog); err != nil {
+		t.Fatalf("Summarize() error: %v", err)
+	}
+	if next.summarizeCalls != 2 {
+		t.Errorf("provider called %d times after a rank parameter change, want 2", next.summarizeCalls)
+	}
+}
+
+func TestCachingProvider_SuggestPrerequisites(t *testing.T) {
+	ctx := context.Background()
+	next := &countingProvider{}
+	p := NewCachingProvider(next, mapCache{}, ProviderConfig{Model: "model-a"})
+
+	blog := BlogEntry{ID: 1, Title: "Post", FullContent: "content"}
+
+	for range 2 {
+		prereqs, err := p.SuggestPrerequisites(ctx, blog)
+		if err != nil {
+			t.Fatalf("SuggestPrerequisites() error: %v", err)
+		}
+		if len(prereqs) != 1 || prereqs[0].Concept != "hashing" {
+			t.Errorf("SuggestPrerequisites() = %+v, want [hashing]", prereqs)
+		}
+	}
+	if next.prereqCalls != 1 {
+		t.Errorf("provider called %d times for unchanged content, want 1", next.prereqCalls)
+	}
+}
@@ -0,0 +1,91 @@
+package ai
+
+import "strings"
+
+// Rough sizing constants for discovery cost estimates. Token counts are
+// approximated from character length since neither provider exposes a local
+// tokenizer.
+const (
+	charsPerToken = 4
+
+	// summarizeInputTokens approximates one summarize call's input, since
+	// full article content is only extracted after ranking.
+	summarizeInputTokens = 2500
+
+	rankOutputTokensPerResult = 40
+	summarizeOutputTokens     = 150
+	classifyOutputTokens      = 5
+)
+
+// ModelPrice is the list price of a model in USD per million tokens.
+type ModelPrice struct {
+	InputPerMTok  float64
+	OutputPerMTok float64
+}
+
+// modelPrices lists known model prices keyed by model ID prefix, so that
+// dated snapshots (e.g. "gpt-4o-2024-08-06") match their base model.
+// Longer prefixes are checked first by lookupPrice.
+var modelPrices = map[string]ModelPrice{
+	"claude-haiku-4-5":  {InputPerMTok: 1, OutputPerMTok: 5},
+	"claude-sonnet-4-5": {InputPerMTok: 3, OutputPerMTok: 15},
+	"claude-opus-4-1":   {InputPerMTok: 15, OutputPerMTok: 75},
+	"gpt-4o-mini":       {InputPerMTok: 0.15, OutputPerMTok: 0.60},
+	"gpt-4o":            {InputPerMTok: 2.50, OutputPerMTok: 10},
+}
+
+// lookupPrice returns the price for model using the longest matching prefix
+// in modelPrices.
+func lookupPrice(model string) (ModelPrice, bool) {
+	var (
+		best    ModelPrice
+		bestLen int
+	)
+	for prefix, price := range modelPrices {
+		if strings.HasPrefix(model, prefix) && len(prefix) > bestLen {
+			best, bestLen = price, len(prefix)
+		}
+	}
+	return best, bestLen > 0
+}
+
+// EstimateTokens approximates the number of tokens in s.
+func EstimateTokens(s string) int {
+	return (len(s) + charsPerToken - 1) / charsPerToken
+}
+
+// Estimate is an approximate token count and cost for a set of AI calls.
+type Estimate struct {
+	Model        string  `json:"model"`
+	InputTokens  int     `json:"input_tokens"`
+	OutputTokens int     `json:"output_tokens"`
+	CostUSD      float64 `json:"cost_usd"`
+	PriceKnown   bool    `json:"price_known"`
+}
+
+// EstimateDiscovery approximates the AI usage of a discovery run over blogs:
+// one filter-and-rank call followed by a summarize and difficulty call for
+// each of up to maxResults selected posts. CostUSD is zero and PriceKnown is
+// false when the model's price is not known.
+func EstimateDiscovery(model, preferences string, blogs []BlogEntry, maxResults int, serendipity bool) Estimate {
+	sys, user := FilterAndRankPrompt(preferences, blogs, maxResults, serendipity)
+	input := EstimateTokens(sys) + EstimateTokens(user)
+
+	selected := min(maxResults, len(blogs))
+	classifySys, _ := ClassifyDifficultyPrompt("", "", "")
+	classifyInput := EstimateTokens(classifySys) + classifyMaxWords*4/3
+	input += selected * (summarizeInputTokens + classifyInput)
+
+	output := selected * (rankOutputTokensPerResult + summarizeOutputTokens + classifyOutputTokens)
+
+	est := Estimate{
+		Model:        model,
+		InputTokens:  input,
+		OutputTokens: output,
+	}
+	if price, ok := lookupPrice(model); ok {
+		est.PriceKnown = true
+		est.CostUSD = (float64(input)*price.InputPerMTok + float64(output)*price.OutputPerMTok) / 1e6
+	}
+	return est
+}
@@ -0,0 +1,76 @@
+package ai
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestEstimateTokens(t *testing.T) {
+	tests := []struct {
+		in   string
+		want int
+	}{
+		{"", 0},
+		{"abc", 1},
+		{"abcd", 1},
+		{"abcde", 2},
+		{strings.Repeat("x", 400), 100},
+	}
+	for _, tt := range tests {
+		if got := EstimateTokens(tt.in); got != tt.want {
+			t.Errorf("EstimateTokens(%d chars) = %d, want %d", len(tt.in), got, tt.want)
+		}
+	}
+}
+
+func TestLookupPrice_LongestPrefix(t *testing.T) {
+	mini, ok := lookupPrice("gpt-4o-mini-2024-07-18")
+	if !ok {
+		t.Fatal("expected gpt-4o-mini snapshot to be priced")
+	}
+	if mini != modelPrices["gpt-4o-mini"] {
+		t.Errorf("got %+v, want gpt-4o-mini price", mini)
+	}
+
+	if _, ok := lookupPrice("some-unknown-model"); ok {
+		t.Error("expected unknown model to have no price")
+	}
+}
+
+func TestEstimateDiscovery(t *testing.T) {
+	blogs := []BlogEntry{
+		{ID: 1, Title: "Post One", Source: "A", Description: strings.Repeat("word ", 100)},
+		{ID: 2, Title: "Post Two", Source: "B", Description: "short"},
+	}
+
+	t.Run("known model has cost", func(t *testing.T) {
+		est := EstimateDiscovery("claude-haiku-4-5", "go, databases", blogs, 10, false)
+
+		if est.InputTokens <= 0 || est.OutputTokens <= 0 {
+			t.Fatalf("expected positive token counts, got %+v", est)
+		}
+		if !est.PriceKnown || est.CostUSD <= 0 {
+			t.Errorf("expected a known positive cost, got %+v", est)
+		}
+	})
+
+	t.Run("more results cost more", func(t *testing.T) {
+		one := EstimateDiscovery("claude-haiku-4-5", "go", blogs, 1, false)
+		two := EstimateDiscovery("claude-haiku-4-5", "go", blogs, 2, false)
+
+		if two.InputTokens <= one.InputTokens {
+			t.Errorf("input tokens for 2 results (%d) should exceed 1 result (%d)", two.InputTokens, one.InputTokens)
+		}
+	})
+
+	t.Run("unknown model has no cost", func(t *testing.T) {
+		est := EstimateDiscovery("local-model", "go", blogs, 10, false)
+
+		if est.PriceKnown || est.CostUSD != 0 {
+			t.Errorf("expected unknown price, got %+v", est)
+		}
+		if est.Model != "local-model" {
+			t.Errorf("Model = %q, want %q", est.Model, "local-model")
+		}
+	})
+}
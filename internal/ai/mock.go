@@ -0,0 +1,112 @@
+package ai
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Compile-time interface check.
+var _ AIProvider = (*MockProvider)(nil)
+
+// MockModel is the model name reported by MockProvider.
+const MockModel = "mock"
+
+// MockProvider is a deterministic AIProvider that makes no network calls. It
+// ranks posts by recency and returns canned text, so the full pipeline can
+// be exercised offline, in CI, and by contributors without an API key.
+type MockProvider struct{}
+
+// NewMockProvider creates a new mock provider.
+func NewMockProvider() *MockProvider {
+	return &MockProvider{}
+}
+
+// FilterAndRank returns up to maxResults blogs, newest first. Ties and posts
+// without a publish date fall back to descending ID. Serendipity mode only
+// changes the reason given.
+func (p *MockProvider) FilterAndRank(_ context.Context, _ string, blogs []BlogEntry, maxResults int, serendipity bool) ([]RankedBlog, error) {
+	sorted := slices.Clone(blogs)
+	slices.SortStableFunc(sorted, func(a, b BlogEntry) int {
+		if c := cmp.Compare(b.PublishedAt, a.PublishedAt); c != 0 {
+			return c
+		}
+		return cmp.Compare(b.ID, a.ID)
+	})
+
+	reason := "Recent post from %s."
+	if serendipity {
+		reason = "Recent post from %s, outside your usual interests."
+	}
+
+	ranked := make([]RankedBlog, 0, min(maxResults, len(sorted)))
+	for _, b := range sorted[:min(maxResults, len(sorted))] {
+		ranked = append(ranked, RankedBlog{ID: b.ID, Reason: fmt.Sprintf(reason, b.Source)})
+	}
+	return ranked, nil
+}
+
+// BuildLearningPath returns up to maxItems blogs in the order given.
+func (p *MockProvider) BuildLearningPath(_ context.Context, topic string, blogs []BlogEntry, maxItems int) ([]RankedBlog, error) {
+	ranked := make([]RankedBlog, 0, min(maxItems, len(blogs)))
+	for i, b := range blogs[:min(maxItems, len(blogs))] {
+		ranked = append(ranked, RankedBlog{ID: b.ID, Reason: fmt.Sprintf("Step %d on %s.", i+1, topic)})
+	}
+	return ranked, nil
+}
+
+// Summarize returns a canned summary naming the post, classified as an
+// intermediate post in the "other" category.
+func (p *MockProvider) Summarize(_ context.Context, blog BlogEntry) (Summary, error) {
+	return Summary{
+		Text:       fmt.Sprintf("Mock summary of %q from %s.", blog.Title, blog.Source),
+		Difficulty: "intermediate",
+		Category:   "other",
+	}, nil
+}
+
+// ClassifyDifficulty always returns "intermediate".
+func (p *MockProvider) ClassifyDifficulty(context.Context, BlogEntry) (string, error) {
+	return "intermediate", nil
+}
+
+// RewriteTitle returns the title unchanged.
+func (p *MockProvider) RewriteTitle(_ context.Context, blog BlogEntry) (string, error) {
+	return blog.Title, nil
+}
+
+// SuggestPrerequisites returns a single prerequisite whose keywords are the
+// post's title, so that related saved posts are linked.
+func (p *MockProvider) SuggestPrerequisites(_ context.Context, blog BlogEntry) ([]Prerequisite, error) {
+	return []Prerequisite{{
+		Concept:  "Background on " + blog.Title,
+		Reason:   "Mock prerequisite.",
+		Keywords: blog.Title,
+	}}, nil
+}
+
+// SynthesizeAnswer returns a canned answer that cites every source.
+func (p *MockProvider) SynthesizeAnswer(_ context.Context, question string, blogs []BlogEntry) (string, error) {
+	refs := make([]string, len(blogs))
+	for i := range blogs {
+		refs[i] = fmt.Sprintf("[%d]", i+1)
+	}
+	return fmt.Sprintf("Mock answer to %q based on %s.", question, strings.Join(refs, " ")), nil
+}
+
+// NarrateYear returns a canned narrative.
+func (p *MockProvider) NarrateYear(_ context.Context, year int, blogs []BlogEntry) (string, error) {
+	return fmt.Sprintf("In %d you read %d posts.", year, len(blogs)), nil
+}
+
+// ListModels returns the single mock model.
+func (p *MockProvider) ListModels(context.Context) ([]ModelInfo, error) {
+	return []ModelInfo{{ID: MockModel, DisplayName: "Mock (offline)"}}, nil
+}
+
+// Ping always succeeds.
+func (p *MockProvider) Ping(context.Context) error {
+	return nil
+}
@@ -0,0 +1,67 @@
+package ai
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestMockProvider_FilterAndRankByRecency(t *testing.T) {
+	blogs := []BlogEntry{
+		{ID: 1, Source: "A", PublishedAt: "2024-01-01"},
+		{ID: 2, Source: "B", PublishedAt: "2024-03-01"},
+		{ID: 3, Source: "C"},
+		{ID: 4, Source: "D", PublishedAt: "2024-03-01"},
+	}
+
+	ranked, err := NewMockProvider().FilterAndRank(context.Background(), "", blogs, 3, false)
+	if err != nil {
+		t.Fatalf("FilterAndRank() error: %v", err)
+	}
+
+	var ids []int64
+	for _, rb := range ranked {
+		ids = append(ids, rb.ID)
+	}
+	want := []int64{4, 2, 1}
+	if len(ids) != len(want) {
+		t.Fatalf("got IDs %v, want %v", ids, want)
+	}
+	for i := range want {
+		if ids[i] != want[i] {
+			t.Fatalf("got IDs %v, want %v", ids, want)
+		}
+	}
+	if blogs[0].ID != 1 {
+		t.Error("FilterAndRank reordered its input")
+	}
+}
+
+func TestMockProvider_CannedResponses(t *testing.T) {
+	ctx := context.Background()
+	p := NewMockProvider()
+	blog := BlogEntry{ID: 1, Title: "Raft", Source: "A"}
+
+	summary, err := p.Summarize(ctx, blog)
+	if err != nil {
+		t.Fatalf("Summarize() error: %v", err)
+	}
+	if !strings.Contains(summary.Text, "Raft") || summary.Difficulty != "intermediate" || summary.Category != "other" {
+		t.Errorf("got summary %+v", summary)
+	}
+
+	answer, err := p.SynthesizeAnswer(ctx, "why?", []BlogEntry{blog, blog})
+	if err != nil {
+		t.Fatalf("SynthesizeAnswer() error: %v", err)
+	}
+	if !strings.Contains(answer, "[1] [2]") {
+		t.Errorf("answer %q does not cite both sources", answer)
+	}
+
+	if title, _ := p.RewriteTitle(ctx, blog); title != "Raft" {
+		t.Errorf("RewriteTitle() = %q, want unchanged", title)
+	}
+	if err := p.Ping(ctx); err != nil {
+		t.Errorf("Ping() error: %v", err)
+	}
+}
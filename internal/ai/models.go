@@ -2,9 +2,22 @@ package ai
 
 // ProviderConfig holds the configuration needed to create an AI provider.
 type ProviderConfig struct {
-	Provider string // "anthropic" | "openai"
+	Provider string // "anthropic" | "openai" | "mock"
 	APIKey   string
 	Model    string
+
+	// Rank applies to FilterAndRank and BuildLearningPath, and Summarize
+	// to Summarize.
+	Rank      ModelParams
+	Summarize ModelParams
+}
+
+// ModelParams are the sampling parameters of one kind of request. Nil and
+// zero values leave the provider's default in place.
+type ModelParams struct {
+	Temperature *float64
+	MaxTokens   int
+	TopP        *float64
 }
 
 // BlogEntry is a simplified blog representation for AI prompts.
@@ -22,3 +35,26 @@ type RankedBlog struct {
 	ID     int64  `json:"id"`
 	Reason string `json:"reason"`
 }
+
+// Summary is the result of the summarize operation: the summary text plus
+// the post's difficulty level and primary category. Difficulty and Category
+// are empty when the model did not provide a recognized value.
+type Summary struct {
+	Text       string `json:"summary"`
+	Difficulty string `json:"difficulty,omitempty"`
+	Category   string `json:"category,omitempty"`
+}
+
+// Prerequisite is a concept a reader should understand before tackling an
+// article, as suggested by the prerequisites operation.
+type Prerequisite struct {
+	Concept  string `json:"concept"`
+	Reason   string `json:"reason"`
+	Keywords string `json:"keywords"`
+}
+
+// ModelInfo describes a model offered by the configured provider.
+type ModelInfo struct {
+	ID          string `json:"id"`
+	DisplayName string `json:"display_name,omitempty"`
+}
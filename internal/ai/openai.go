@@ -8,19 +8,27 @@ import (
 	"io"
 	"log/slog"
 	"net/http"
+	"sort"
 	"time"
+
+	"github.com/hoanghai1803/apricot/internal/outbound"
 )
 
 // Compile-time interface check.
 var _ AIProvider = (*OpenAIProvider)(nil)
 
-const openaiAPIURL = "https://api.openai.com/v1/chat/completions"
+const (
+	openaiAPIURL       = "https://api.openai.com/v1/chat/completions"
+	openaiModelsAPIURL = "https://api.openai.com/v1/models"
+)
 
 // OpenAIProvider implements AIProvider using the OpenAI Chat Completions API.
 type OpenAIProvider struct {
 	apiKey string
 	model  string
 	client *http.Client
+
+	rank, summarize ModelParams
 }
 
 // NewOpenAIProvider creates an OpenAIProvider with a 60-second timeout
@@ -30,15 +38,19 @@ func NewOpenAIProvider(apiKey, model string) *OpenAIProvider {
 		apiKey: apiKey,
 		model:  model,
 		client: &http.Client{
-			Timeout: 60 * time.Second,
+			Timeout:   60 * time.Second,
+			Transport: &outbound.Transport{Purpose: outbound.PurposeAI},
 		},
 	}
 }
 
 // openaiRequest is the request body for the OpenAI Chat Completions API.
 type openaiRequest struct {
-	Model    string           `json:"model"`
-	Messages []openaiMessage  `json:"messages"`
+	Model               string          `json:"model"`
+	Messages            []openaiMessage `json:"messages"`
+	MaxCompletionTokens int             `json:"max_completion_tokens,omitempty"`
+	Temperature         *float64        `json:"temperature,omitempty"`
+	TopP                *float64        `json:"top_p,omitempty"`
 }
 
 // openaiMessage is a single message in the OpenAI request.
@@ -64,7 +76,7 @@ type openaiResponse struct {
 func (p *OpenAIProvider) FilterAndRank(ctx context.Context, preferences string, blogs []BlogEntry, maxResults int, serendipity bool) ([]RankedBlog, error) {
 	systemPrompt, userPrompt := FilterAndRankPrompt(preferences, blogs, maxResults, serendipity)
 
-	text, err := p.callAPI(ctx, systemPrompt, userPrompt)
+	text, err := p.callAPI(ctx, p.rank, systemPrompt, userPrompt)
 	if err != nil {
 		return nil, fmt.Errorf("openai filter-and-rank: %w", err)
 	}
@@ -79,9 +91,26 @@ func (p *OpenAIProvider) FilterAndRank(ctx context.Context, preferences string,
 	return ranked, nil
 }
 
-// Summarize generates a concise summary of the given blog post using the
-// OpenAI Chat Completions API.
-func (p *OpenAIProvider) Summarize(ctx context.Context, blog BlogEntry) (string, error) {
+// BuildLearningPath selects and orders blogs into a learning path on topic
+// using the OpenAI Chat Completions API.
+func (p *OpenAIProvider) BuildLearningPath(ctx context.Context, topic string, blogs []BlogEntry, maxItems int) ([]RankedBlog, error) {
+	systemPrompt, userPrompt := LearningPathPrompt(topic, blogs, maxItems)
+
+	text, err := p.callAPI(ctx, p.rank, systemPrompt, userPrompt)
+	if err != nil {
+		return nil, fmt.Errorf("openai learning path: %w", err)
+	}
+
+	var ranked []RankedBlog
+	if err := json.Unmarshal([]byte(extractJSON(text)), &ranked); err != nil {
+		return nil, fmt.Errorf("openai learning path: parsing response JSON: %w", err)
+	}
+	return ranked, nil
+}
+
+// Summarize generates a concise summary, difficulty level, and category for
+// the given blog post using the OpenAI Chat Completions API.
+func (p *OpenAIProvider) Summarize(ctx context.Context, blog BlogEntry) (Summary, error) {
 	content := blog.FullContent
 	if content == "" {
 		content = blog.Description
@@ -89,23 +118,157 @@ func (p *OpenAIProvider) Summarize(ctx context.Context, blog BlogEntry) (string,
 
 	systemPrompt, userPrompt := SummarizePrompt(blog.Title, blog.Source, content)
 
-	text, err := p.callAPI(ctx, systemPrompt, userPrompt)
+	text, err := p.callAPI(ctx, p.summarize, systemPrompt, userPrompt)
+	if err != nil {
+		return Summary{}, fmt.Errorf("openai summarize: %w", err)
+	}
+
+	return parseSummary(text), nil
+}
+
+// ClassifyDifficulty estimates the difficulty level of the given blog post
+// using the OpenAI Chat Completions API.
+func (p *OpenAIProvider) ClassifyDifficulty(ctx context.Context, blog BlogEntry) (string, error) {
+	content := blog.FullContent
+	if content == "" {
+		content = blog.Description
+	}
+
+	systemPrompt, userPrompt := ClassifyDifficultyPrompt(blog.Title, blog.Source, content)
+
+	text, err := p.callAPI(ctx, ModelParams{}, systemPrompt, userPrompt)
+	if err != nil {
+		return "", fmt.Errorf("openai classify difficulty: %w", err)
+	}
+
+	difficulty, err := parseDifficulty(text)
+	if err != nil {
+		return "", fmt.Errorf("openai classify difficulty: %w", err)
+	}
+	return difficulty, nil
+}
+
+// RewriteTitle rewrites the given blog post's title into a factual one
+// using the OpenAI Chat Completions API.
+func (p *OpenAIProvider) RewriteTitle(ctx context.Context, blog BlogEntry) (string, error) {
+	systemPrompt, userPrompt := RewriteTitlePrompt(blog.Title, blog.Source, blog.Description)
+
+	text, err := p.callAPI(ctx, ModelParams{}, systemPrompt, userPrompt)
+	if err != nil {
+		return "", fmt.Errorf("openai rewrite title: %w", err)
+	}
+	return parseRewrittenTitle(text, blog.Title), nil
+}
+
+// SuggestPrerequisites lists the concepts to understand before reading the
+// given blog post using the OpenAI Chat Completions API.
+func (p *OpenAIProvider) SuggestPrerequisites(ctx context.Context, blog BlogEntry) ([]Prerequisite, error) {
+	content := blog.FullContent
+	if content == "" {
+		content = blog.Description
+	}
+
+	systemPrompt, userPrompt := PrerequisitesPrompt(blog.Title, blog.Source, content)
+
+	text, err := p.callAPI(ctx, ModelParams{}, systemPrompt, userPrompt)
+	if err != nil {
+		return nil, fmt.Errorf("openai prerequisites: %w", err)
+	}
+
+	prereqs, err := parsePrerequisites(text)
+	if err != nil {
+		return nil, fmt.Errorf("openai prerequisites: %w", err)
+	}
+	return prereqs, nil
+}
+
+// SynthesizeAnswer answers a research question from the given sources using
+// the OpenAI Chat Completions API.
+func (p *OpenAIProvider) SynthesizeAnswer(ctx context.Context, question string, blogs []BlogEntry) (string, error) {
+	systemPrompt, userPrompt := ResearchPrompt(question, blogs)
+
+	text, err := p.callAPI(ctx, ModelParams{}, systemPrompt, userPrompt)
 	if err != nil {
-		return "", fmt.Errorf("openai summarize: %w", err)
+		return "", fmt.Errorf("openai research: %w", err)
 	}
 
 	return text, nil
 }
 
+// NarrateYear writes a narrative of the year's reading themes using the
+// OpenAI Chat Completions API.
+func (p *OpenAIProvider) NarrateYear(ctx context.Context, year int, blogs []BlogEntry) (string, error) {
+	systemPrompt, userPrompt := YearInReviewPrompt(year, blogs)
+
+	text, err := p.callAPI(ctx, ModelParams{}, systemPrompt, userPrompt)
+	if err != nil {
+		return "", fmt.Errorf("openai year in review: %w", err)
+	}
+
+	return text, nil
+}
+
+// ListModels returns the models available to the API key using the OpenAI
+// Models API.
+func (p *OpenAIProvider) ListModels(ctx context.Context) ([]ModelInfo, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, openaiModelsAPIURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("openai list models: creating request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+p.apiKey)
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("openai list models: sending request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var apiResp struct {
+		Data []struct {
+			ID string `json:"id"`
+		} `json:"data"`
+		Error *struct {
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&apiResp); err != nil {
+		return nil, fmt.Errorf("openai list models: parsing response (status %d): %w", resp.StatusCode, err)
+	}
+	if apiResp.Error != nil {
+		return nil, fmt.Errorf("openai list models: API error (status %d): %s", resp.StatusCode, apiResp.Error.Message)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("openai list models: unexpected status code: %d", resp.StatusCode)
+	}
+
+	models := make([]ModelInfo, 0, len(apiResp.Data))
+	for _, m := range apiResp.Data {
+		models = append(models, ModelInfo{ID: m.ID})
+	}
+	sort.Slice(models, func(i, j int) bool { return models[i].ID < models[j].ID })
+	return models, nil
+}
+
+// Ping sends a minimal chat completion to verify the API key and model name.
+func (p *OpenAIProvider) Ping(ctx context.Context) error {
+	if _, err := p.callAPI(ctx, ModelParams{}, pingSystemPrompt, pingUserPrompt); err != nil {
+		return fmt.Errorf("openai ping: %w", err)
+	}
+	return nil
+}
+
 // callAPI makes an HTTP request to the OpenAI Chat Completions API and
 // returns the text content from the first choice.
-func (p *OpenAIProvider) callAPI(ctx context.Context, systemPrompt, userPrompt string) (string, error) {
+func (p *OpenAIProvider) callAPI(ctx context.Context, params ModelParams, systemPrompt, userPrompt string) (string, error) {
 	reqBody := openaiRequest{
 		Model: p.model,
 		Messages: []openaiMessage{
 			{Role: "system", Content: systemPrompt},
 			{Role: "user", Content: userPrompt},
 		},
+		MaxCompletionTokens: params.MaxTokens,
+		Temperature:         params.Temperature,
+		TopP:                params.TopP,
 	}
 
 	body, err := json.Marshal(reqBody)
@@ -121,7 +284,7 @@ func (p *OpenAIProvider) callAPI(ctx context.Context, systemPrompt, userPrompt s
 	req.Header.Set("Authorization", "Bearer "+p.apiKey)
 	req.Header.Set("Content-Type", "application/json")
 
-	slog.Debug("calling OpenAI API", "model", p.model)
+	slog.DebugContext(ctx, "calling OpenAI API", "model", p.model)
 
 	resp, err := p.client.Do(req)
 	if err != nil {
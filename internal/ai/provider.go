@@ -12,17 +12,55 @@ type AIProvider interface {
 	// When serendipity is true, it deliberately picks posts outside the user's interests.
 	FilterAndRank(ctx context.Context, preferences string, blogs []BlogEntry, maxResults int, serendipity bool) ([]RankedBlog, error)
 
-	// Summarize generates a concise summary of the given blog post.
-	Summarize(ctx context.Context, blog BlogEntry) (string, error)
+	// BuildLearningPath selects up to maxItems of the given blogs that teach
+	// the topic and orders them from foundational to advanced.
+	BuildLearningPath(ctx context.Context, topic string, blogs []BlogEntry, maxItems int) ([]RankedBlog, error)
+
+	// Summarize generates a concise summary of the given blog post, along
+	// with its difficulty level and primary category.
+	Summarize(ctx context.Context, blog BlogEntry) (Summary, error)
+
+	// ClassifyDifficulty estimates the difficulty of the given blog post as
+	// one of models.Difficulties.
+	ClassifyDifficulty(ctx context.Context, blog BlogEntry) (string, error)
+
+	// RewriteTitle rewrites a sensational or marketing-style title into a
+	// factual one. Titles that are already factual are returned unchanged.
+	RewriteTitle(ctx context.Context, blog BlogEntry) (string, error)
+
+	// SuggestPrerequisites lists the concepts a reader should understand
+	// before reading the given blog post, most foundational first.
+	SuggestPrerequisites(ctx context.Context, blog BlogEntry) ([]Prerequisite, error)
+
+	// SynthesizeAnswer answers a research question from the given sources,
+	// citing them as [n] by their 1-based position in blogs.
+	SynthesizeAnswer(ctx context.Context, question string, blogs []BlogEntry) (string, error)
+
+	// NarrateYear writes a short narrative of the themes in the posts the
+	// user read during the given year.
+	NarrateYear(ctx context.Context, year int, blogs []BlogEntry) (string, error)
+
+	// ListModels returns the models available to the configured API key.
+	ListModels(ctx context.Context) ([]ModelInfo, error)
+
+	// Ping performs the cheapest possible completion call to verify that the
+	// API key and model name are valid.
+	Ping(ctx context.Context) error
 }
 
 // NewProvider creates the appropriate provider based on config.
 func NewProvider(cfg ProviderConfig) (AIProvider, error) {
 	switch cfg.Provider {
 	case "anthropic":
-		return NewAnthropicProvider(cfg.APIKey, cfg.Model), nil
+		p := NewAnthropicProvider(cfg.APIKey, cfg.Model)
+		p.rank, p.summarize = cfg.Rank, cfg.Summarize
+		return p, nil
 	case "openai":
-		return NewOpenAIProvider(cfg.APIKey, cfg.Model), nil
+		p := NewOpenAIProvider(cfg.APIKey, cfg.Model)
+		p.rank, p.summarize = cfg.Rank, cfg.Summarize
+		return p, nil
+	case "mock":
+		return NewMockProvider(), nil
 	default:
 		return nil, fmt.Errorf("unsupported AI provider: %s", cfg.Provider)
 	}
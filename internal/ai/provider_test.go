@@ -1,6 +1,11 @@
 package ai
 
 import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
 	"testing"
 )
 
@@ -31,6 +36,14 @@ func TestNewProvider(t *testing.T) {
 			wantErr:  false,
 			wantType: "*ai.OpenAIProvider",
 		},
+		{
+			name: "mock provider needs no key",
+			cfg: ProviderConfig{
+				Provider: "mock",
+			},
+			wantErr:  false,
+			wantType: "*ai.MockProvider",
+		},
 		{
 			name: "unsupported provider",
 			cfg: ProviderConfig{
@@ -82,6 +95,80 @@ func TestNewProvider(t *testing.T) {
 				if _, ok := provider.(*OpenAIProvider); !ok {
 					t.Errorf("expected *OpenAIProvider, got %T", provider)
 				}
+			case "*ai.MockProvider":
+				if _, ok := provider.(*MockProvider); !ok {
+					t.Errorf("expected *MockProvider, got %T", provider)
+				}
+			}
+		})
+	}
+}
+
+// roundTripFunc lets a function serve a provider's HTTP requests.
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(r *http.Request) (*http.Response, error) { return f(r) }
+
+func TestModelParams(t *testing.T) {
+	temperature, topP := 0.2, 0.9
+	cfg := ProviderConfig{
+		APIKey:    "test-key",
+		Model:     "test-model",
+		Rank:      ModelParams{MaxTokens: 4096, Temperature: &temperature},
+		Summarize: ModelParams{MaxTokens: 512, TopP: &topP},
+	}
+
+	tests := []struct {
+		provider         string
+		reply            string // a response with the text "[]"
+		maxTokens        string // the request field of the response limit
+		defaultMaxTokens any    // its value when no limit is configured
+	}{
+		{"anthropic", `{"content": [{"text": "[]"}]}`, "max_tokens", 1024.0},
+		{"openai", `{"choices": [{"message": {"content": "[]"}}]}`, "max_completion_tokens", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.provider, func(t *testing.T) {
+			var body map[string]any
+			client := &http.Client{Transport: roundTripFunc(func(r *http.Request) (*http.Response, error) {
+				body = nil
+				if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+					t.Errorf("decoding request: %v", err)
+				}
+				return &http.Response{
+					StatusCode: http.StatusOK,
+					Body:       io.NopCloser(strings.NewReader(tt.reply)),
+					Header:     make(http.Header),
+				}, nil
+			})}
+			cfg.Provider = tt.provider
+			provider, err := NewProvider(cfg)
+			if err != nil {
+				t.Fatalf("NewProvider: %v", err)
+			}
+			switch p := provider.(type) {
+			case *AnthropicProvider:
+				p.client = client
+			case *OpenAIProvider:
+				p.client = client
+			}
+			ctx := context.Background()
+
+			if _, err := provider.FilterAndRank(ctx, "go", nil, 5, false); err != nil {
+				t.Fatalf("FilterAndRank: %v", err)
+			}
+			if body[tt.maxTokens] != 4096.0 || body["temperature"] != 0.2 || body["top_p"] != nil {
+				t.Errorf("rank request = %v, want max tokens 4096 and temperature 0.2", body)
+			}
+
+			provider.Summarize(ctx, BlogEntry{Title: "Post"}) //nolint:errcheck // only the request matters
+			if body[tt.maxTokens] != 512.0 || body["temperature"] != nil || body["top_p"] != 0.9 {
+				t.Errorf("summarize request = %v, want max tokens 512 and top_p 0.9", body)
+			}
+
+			provider.RewriteTitle(ctx, BlogEntry{Title: "Post"}) //nolint:errcheck // only the request matters
+			if body[tt.maxTokens] != tt.defaultMaxTokens || body["temperature"] != nil || body["top_p"] != nil {
+				t.Errorf("rewrite title request = %v, want the provider's defaults", body)
 			}
 		})
 	}
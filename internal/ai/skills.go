@@ -1,15 +1,52 @@
 package ai
 
 import (
+	"cmp"
+	"encoding/json"
 	"fmt"
+	"slices"
 	"strings"
+
+	"github.com/hoanghai1803/apricot/internal/models"
 )
 
-const filterAndRankSystemPromptTmpl = `You are a tech blog curator. Given the user's interests and a list of recent blog posts, select exactly %d posts that best match the user's interests. Return ONLY valid JSON: an array of objects with "id" (the post ID) and "reason" (one sentence explaining why this post matches). Rank by relevance, most relevant first. If there are fewer than %d posts, select all of them.`
+// PromptVersion identifies the current revision of the prompt templates below.
+// Bump it whenever a template changes so that cached responses produced by an
+// older prompt are not reused.
+const PromptVersion = 3
+
+const filterAndRankSystemPromptTmpl = `You are a tech blog curator. Given the user's interests and a list of recent blog posts, select exactly %d posts that best match the user's interests. Interests may carry an importance from 1 (nice to have) to 5 (must have): prefer posts matching higher-importance interests, and do not let nice-to-have interests crowd out must-have ones. Return ONLY valid JSON: an array of objects with "id" (the post ID) and "reason" (one sentence explaining why this post matches). Rank by relevance, most relevant first. If there are fewer than %d posts, select all of them.`
 
 const serendipitySystemPromptTmpl = `You are a tech blog curator focused on broadening horizons. Given the user's stated interests and a list of recent blog posts, select exactly %d posts that are OUTSIDE the user's usual interests but are still high-quality, surprising, and educational. Deliberately avoid posts that directly match the user's interests. Instead, pick posts from different domains, unexpected topics, or novel approaches that a curious engineer would find fascinating. Return ONLY valid JSON: an array of objects with "id" (the post ID) and "reason" (one sentence explaining why this post is a surprising but worthwhile read). Rank by how interesting and unexpected the post would be. If there are fewer than %d posts, select all of them.`
 
-const summarizeSystemPrompt = `You are a technical writer. Summarize the following blog post in exactly 4-5 sentences. Focus on: the problem being solved, the approach taken, key technical decisions, and the outcome or results. Write for a senior engineer audience. Be specific about technologies and numbers mentioned in the post. Do NOT include any prefix like "# Summary" or "Summary:" — start directly with the first sentence.`
+var summarizeSystemPrompt = `You are a technical writer. Summarize the following blog post in exactly 4-5 sentences. Focus on: the problem being solved, the approach taken, key technical decisions, and the outcome or results. Write for a senior engineer audience. Be specific about technologies and numbers mentioned in the post. Do NOT include any prefix like "# Summary" or "Summary:" — start directly with the first sentence.
+
+Also classify the post. "difficulty" is exactly one of: "intro" (approachable overview), "intermediate" (assumes working knowledge of the area), or "deep-dive" (dense material on internals, performance, or research-level detail). "category" is the single best fit from: ` + strings.Join(models.Categories, ", ") + `.
+
+Return ONLY valid JSON: an object with "summary", "difficulty", and "category".`
+
+const classifyDifficultySystemPrompt = `You are a technical editor. Classify the difficulty of the following blog post for a software engineer audience as exactly one of: "intro" (approachable overview, little prior knowledge needed), "intermediate" (assumes working knowledge of the area), or "deep-dive" (dense material on internals, performance, or research-level detail). Respond with ONLY the label, nothing else.`
+
+const learningPathSystemPromptTmpl = `You are a curriculum designer for software engineers. Given a topic and a list of blog posts, select up to %d posts that together teach the topic well, and order them as a learning path: foundational material first, then progressively more advanced or specialized posts. Skip posts that are off-topic or redundant. Return ONLY valid JSON: an array of objects with "id" (the post ID) and "reason" (one sentence on what this post contributes at this point in the path), in path order.`
+
+const rewriteTitleSystemPrompt = `You are a no-nonsense technical editor. Rewrite the given blog post title into a plain, factual title that states what the post is actually about, based on its description. Remove hype, clickbait, questions, and marketing language. Keep it under 15 words. If the title is already factual and specific, return it unchanged. Respond with ONLY the title, without quotes.`
+
+const prerequisitesSystemPrompt = `You are a patient technical mentor. Given a technical blog post, list the 3-6 prerequisite concepts a reader should understand before reading it, ordered from most foundational to most specific. Return ONLY valid JSON: an array of objects with "concept" (short name of the concept), "reason" (one sentence on why it is needed for this post), and "keywords" (2-4 space-separated search keywords for finding articles about the concept). Do not list concepts the post itself explains in depth.`
+
+const researchSystemPrompt = `You are a research assistant for a senior software engineer. Answer the user's question using ONLY the numbered sources provided. Cite sources inline as [n] using their numbers, citing every claim that comes from a source. Where sources disagree, say so. If the sources do not answer part of the question, say what is missing rather than guessing. Write 2-5 short paragraphs in plain prose. Do NOT include a heading or a list of references — start directly with the answer.`
+
+// classifyMaxWords caps how much article content is sent for difficulty
+// classification; the opening of a post is enough to judge its depth.
+const classifyMaxWords = 1500
+
+const yearInReviewSystemPrompt = `You are a thoughtful reading companion writing a personal year-in-review. Given the list of technical blog posts the user finished reading this year, write 2-3 short paragraphs describing the main themes they explored, how their interests shifted over the year, and notable threads connecting the posts. Address the user as "you". Be specific about technologies and topics. Do NOT include a heading or a title — start directly with the first sentence.`
+
+// pingSystemPrompt and pingUserPrompt make up the minimal request used to
+// verify provider credentials.
+const (
+	pingSystemPrompt = `Reply with the single word OK.`
+	pingUserPrompt   = `ping`
+)
 
 // FilterAndRankPrompt builds the system and user prompts for the
 // filter-and-rank operation. When serendipity is true, the prompt
@@ -35,6 +72,47 @@ func FilterAndRankPrompt(preferences string, blogs []BlogEntry, maxResults int,
 	return systemPrompt, userPrompt
 }
 
+// topicImportanceLabels describe each topic importance level in prompts.
+var topicImportanceLabels = map[int]string{
+	1: "nice to have",
+	2: "minor interest",
+	3: "interested",
+	4: "strong interest",
+	5: "must have",
+}
+
+// FormatTopics renders weighted topics as the preferences text passed to
+// FilterAndRankPrompt, one topic per line, most important first.
+func FormatTopics(topics []models.Topic) string {
+	sorted := slices.Clone(topics)
+	slices.SortStableFunc(sorted, func(a, b models.Topic) int {
+		return cmp.Compare(b.Importance, a.Importance)
+	})
+
+	var b strings.Builder
+	for _, t := range sorted {
+		fmt.Fprintf(&b, "- %s (importance %d/%d: %s)\n",
+			t.Topic, t.Importance, models.MaxTopicImportance, topicImportanceLabels[t.Importance])
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// LearningPathPrompt builds the system and user prompts for the learning
+// path generator.
+func LearningPathPrompt(topic string, blogs []BlogEntry, maxItems int) (systemPrompt string, userPrompt string) {
+	systemPrompt = fmt.Sprintf(learningPathSystemPromptTmpl, maxItems)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Topic: %s\n\nCandidate Blog Posts:\n", topic)
+	for i, blog := range blogs {
+		fmt.Fprintf(&b, "%d. ID: %d | Title: %s | Source: %s | Description: %s\n",
+			i+1, blog.ID, blog.Title, blog.Source, blog.Description)
+	}
+
+	userPrompt = b.String()
+	return systemPrompt, userPrompt
+}
+
 // SummarizePrompt builds the system and user prompts for the blog
 // summarization operation.
 func SummarizePrompt(title, source, content string) (systemPrompt string, userPrompt string) {
@@ -50,6 +128,162 @@ func SummarizePrompt(title, source, content string) (systemPrompt string, userPr
 	return systemPrompt, userPrompt
 }
 
+// ClassifyDifficultyPrompt builds the system and user prompts for the
+// difficulty classification operation. Content is truncated to the first
+// classifyMaxWords words.
+func ClassifyDifficultyPrompt(title, source, content string) (systemPrompt string, userPrompt string) {
+	systemPrompt = classifyDifficultySystemPrompt
+	content = truncateWords(content, classifyMaxWords)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Blog Title: %s\n", title)
+	fmt.Fprintf(&b, "Blog Source: %s\n", source)
+	b.WriteString("Blog Content:\n")
+	b.WriteString(content)
+
+	userPrompt = b.String()
+	return systemPrompt, userPrompt
+}
+
+// parseSummary decodes the JSON object returned by the summarize operation.
+// A response that is not JSON is taken as the summary text on its own, so a
+// model that ignores the format still yields a usable summary.
+func parseSummary(text string) Summary {
+	var raw struct {
+		Summary    string `json:"summary"`
+		Difficulty string `json:"difficulty"`
+		Category   string `json:"category"`
+	}
+	if err := json.Unmarshal([]byte(extractJSON(text)), &raw); err != nil || raw.Summary == "" {
+		return Summary{Text: strings.TrimSpace(text)}
+	}
+
+	summary := Summary{Text: strings.TrimSpace(raw.Summary)}
+	if difficulty, err := parseDifficulty(raw.Difficulty); err == nil {
+		summary.Difficulty = difficulty
+	}
+	category := strings.ToLower(strings.TrimSpace(raw.Category))
+	if models.IsValidCategory(category) {
+		summary.Category = category
+	} else if category != "" {
+		summary.Category = "other"
+	}
+	return summary
+}
+
+// parseDifficulty normalizes a model's classification answer into one of
+// models.Difficulties.
+func parseDifficulty(text string) (string, error) {
+	label := strings.ToLower(strings.Trim(strings.TrimSpace(text), "\"'`.* "))
+	label = strings.ReplaceAll(label, " ", "-")
+	if models.IsValidDifficulty(label) {
+		return label, nil
+	}
+	return "", fmt.Errorf("unrecognized difficulty %q", text)
+}
+
+// RewriteTitlePrompt builds the system and user prompts for the title
+// rewriting operation. Only the description is sent, which keeps the call
+// cheap enough to run on every discovery result.
+func RewriteTitlePrompt(title, source, description string) (systemPrompt string, userPrompt string) {
+	systemPrompt = rewriteTitleSystemPrompt
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Blog Title: %s\n", title)
+	fmt.Fprintf(&b, "Blog Source: %s\n", source)
+	fmt.Fprintf(&b, "Blog Description: %s\n", description)
+
+	userPrompt = b.String()
+	return systemPrompt, userPrompt
+}
+
+// parseRewrittenTitle cleans up a model's rewritten title, falling back to
+// the original when the response is empty.
+func parseRewrittenTitle(text, original string) string {
+	title, _, _ := strings.Cut(strings.TrimSpace(text), "\n")
+	title = strings.TrimPrefix(title, "Title:")
+	title = strings.Trim(strings.TrimSpace(title), "\"'`*")
+	if title == "" {
+		return original
+	}
+	return title
+}
+
+// PrerequisitesPrompt builds the system and user prompts for the
+// prerequisite suggestion operation. Content is truncated to the first
+// classifyMaxWords words, which is enough to judge what the post assumes.
+func PrerequisitesPrompt(title, source, content string) (systemPrompt string, userPrompt string) {
+	systemPrompt = prerequisitesSystemPrompt
+	content = truncateWords(content, classifyMaxWords)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Blog Title: %s\n", title)
+	fmt.Fprintf(&b, "Blog Source: %s\n", source)
+	b.WriteString("Blog Content:\n")
+	b.WriteString(content)
+
+	userPrompt = b.String()
+	return systemPrompt, userPrompt
+}
+
+// parsePrerequisites decodes the JSON array returned by the prerequisites
+// operation, dropping entries without a concept.
+func parsePrerequisites(text string) ([]Prerequisite, error) {
+	var prereqs []Prerequisite
+	if err := json.Unmarshal([]byte(extractJSON(text)), &prereqs); err != nil {
+		return nil, fmt.Errorf("parsing response JSON: %w", err)
+	}
+
+	kept := make([]Prerequisite, 0, len(prereqs))
+	for _, p := range prereqs {
+		if strings.TrimSpace(p.Concept) != "" {
+			kept = append(kept, p)
+		}
+	}
+	return kept, nil
+}
+
+// truncateWords returns the first n whitespace-separated words of s.
+func truncateWords(s string, n int) string {
+	if words := strings.Fields(s); len(words) > n {
+		return strings.Join(words[:n], " ")
+	}
+	return s
+}
+
+// YearInReviewPrompt builds the system and user prompts for the narrative
+// section of the annual reading report.
+func YearInReviewPrompt(year int, blogs []BlogEntry) (systemPrompt string, userPrompt string) {
+	systemPrompt = yearInReviewSystemPrompt
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Year: %d\n\nPosts Read:\n", year)
+	for i, blog := range blogs {
+		fmt.Fprintf(&b, "%d. %s | Source: %s | Published: %s | Description: %s\n",
+			i+1, blog.Title, blog.Source, blog.PublishedAt, blog.Description)
+	}
+
+	userPrompt = b.String()
+	return systemPrompt, userPrompt
+}
+
+// ResearchPrompt builds the system and user prompts for answering a research
+// question. Sources are numbered from 1 in the order given. Callers pass each
+// source's summary as its Description to keep the prompt small.
+func ResearchPrompt(question string, blogs []BlogEntry) (systemPrompt string, userPrompt string) {
+	systemPrompt = researchSystemPrompt
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Question: %s\n\nSources:\n", question)
+	for i, blog := range blogs {
+		fmt.Fprintf(&b, "[%d] %s | Source: %s | Published: %s\n%s\n\n",
+			i+1, blog.Title, blog.Source, blog.PublishedAt, blog.Description)
+	}
+
+	userPrompt = strings.TrimRight(b.String(), "\n")
+	return systemPrompt, userPrompt
+}
+
 // extractJSON strips markdown code fences from a string that may contain
 // JSON wrapped in ```json ... ``` or ``` ... ``` blocks. This handles the
 // common case where LLMs return JSON inside code fences.
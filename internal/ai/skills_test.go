@@ -3,6 +3,8 @@ package ai
 import (
 	"strings"
 	"testing"
+
+	"github.com/hoanghai1803/apricot/internal/models"
 )
 
 func TestFilterAndRankPrompt(t *testing.T) {
@@ -214,3 +216,163 @@ func TestExtractJSON(t *testing.T) {
 		})
 	}
 }
+
+func TestClassifyDifficultyPrompt_TruncatesContent(t *testing.T) {
+	content := strings.Repeat("word ", classifyMaxWords+100)
+
+	_, userPrompt := ClassifyDifficultyPrompt("Title", "Source", content)
+
+	if got := strings.Count(userPrompt, "word"); got != classifyMaxWords {
+		t.Errorf("prompt contains %d content words, want %d", got, classifyMaxWords)
+	}
+}
+
+func TestParseDifficulty(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    string
+		wantErr bool
+	}{
+		{input: "intro", want: "intro"},
+		{input: "  Intermediate.\n", want: "intermediate"},
+		{input: `"deep-dive"`, want: "deep-dive"},
+		{input: "Deep Dive", want: "deep-dive"},
+		{input: "**deep-dive**", want: "deep-dive"},
+		{input: "advanced", wantErr: true},
+		{input: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := parseDifficulty(tt.input)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("parseDifficulty(%q) = %q, want error", tt.input, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseDifficulty(%q) error: %v", tt.input, err)
+			}
+			if got != tt.want {
+				t.Errorf("parseDifficulty(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParsePrerequisites(t *testing.T) {
+	input := "```json\n" + `[
+		{"concept": "Consistent hashing", "reason": "Used for partitioning.", "keywords": "consistent hashing ring"},
+		{"concept": "", "reason": "empty", "keywords": "x"},
+		{"concept": "Raft", "reason": "Replication protocol.", "keywords": "raft consensus"}
+	]` + "\n```"
+
+	got, err := parsePrerequisites(input)
+	if err != nil {
+		t.Fatalf("parsePrerequisites() error: %v", err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("got %d prerequisites, want 2", len(got))
+	}
+	if got[0].Concept != "Consistent hashing" || got[1].Keywords != "raft consensus" {
+		t.Errorf("unexpected prerequisites: %+v", got)
+	}
+
+	if _, err := parsePrerequisites("not json"); err == nil {
+		t.Error("expected error for invalid JSON")
+	}
+}
+
+func TestParseRewrittenTitle(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{input: "How We Cut Build Times by 40% with Remote Caching", want: "How We Cut Build Times by 40% with Remote Caching"},
+		{input: `"Migrating Payments to Postgres"`, want: "Migrating Payments to Postgres"},
+		{input: "Title: Sharding the Job Queue\nThis title is factual.", want: "Sharding the Job Queue"},
+		{input: "  ", want: "Original"},
+	}
+
+	for _, tt := range tests {
+		if got := parseRewrittenTitle(tt.input, "Original"); got != tt.want {
+			t.Errorf("parseRewrittenTitle(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestParseSummary(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  Summary
+	}{
+		{
+			name:  "json",
+			input: `{"summary": "The post explains B-trees.", "difficulty": "Deep Dive", "category": "databases"}`,
+			want:  Summary{Text: "The post explains B-trees.", Difficulty: "deep-dive", Category: "databases"},
+		},
+		{
+			name:  "fenced json with unknown category",
+			input: "```json\n{\"summary\": \"Text.\", \"difficulty\": \"intro\", \"category\": \"gardening\"}\n```",
+			want:  Summary{Text: "Text.", Difficulty: "intro", Category: "other"},
+		},
+		{
+			name:  "invalid difficulty is dropped",
+			input: `{"summary": "Text.", "difficulty": "expert", "category": "security"}`,
+			want:  Summary{Text: "Text.", Category: "security"},
+		},
+		{
+			name:  "plain text falls back to summary only",
+			input: "  The post explains B-trees.\n",
+			want:  Summary{Text: "The post explains B-trees."},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parseSummary(tt.input); got != tt.want {
+				t.Errorf("parseSummary() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResearchPrompt_NumbersSources(t *testing.T) {
+	blogs := []BlogEntry{
+		{ID: 10, Title: "Raft", Source: "A", Description: "raft summary"},
+		{ID: 20, Title: "Paxos", Source: "B", Description: "paxos summary"},
+	}
+
+	systemPrompt, userPrompt := ResearchPrompt("How does consensus work?", blogs)
+
+	if !strings.Contains(systemPrompt, "[n]") {
+		t.Error("system prompt does not ask for [n] citations")
+	}
+	for _, want := range []string{"Question: How does consensus work?", "[1] Raft", "raft summary", "[2] Paxos"} {
+		if !strings.Contains(userPrompt, want) {
+			t.Errorf("user prompt missing %q:\n%s", want, userPrompt)
+		}
+	}
+}
+
+func TestFormatTopics_MostImportantFirst(t *testing.T) {
+	got := FormatTopics([]models.Topic{
+		{Topic: "Kubernetes", Importance: 1},
+		{Topic: "database internals", Importance: 5},
+	})
+
+	want := "- database internals (importance 5/5: must have)\n- Kubernetes (importance 1/5: nice to have)"
+	if got != want {
+		t.Errorf("FormatTopics() =\n%s\nwant\n%s", got, want)
+	}
+
+	systemPrompt, userPrompt := FilterAndRankPrompt(got, nil, 5, false)
+	if !strings.Contains(systemPrompt, "importance") {
+		t.Error("system prompt does not explain topic importance")
+	}
+	if !strings.Contains(userPrompt, got) {
+		t.Errorf("user prompt missing weighted topics:\n%s", userPrompt)
+	}
+}
@@ -0,0 +1,133 @@
+package handlers
+
+import (
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/hoanghai1803/apricot/internal/ai"
+	"github.com/hoanghai1803/apricot/internal/config"
+)
+
+// ListAIModels handles GET /api/ai/models. It queries the configured
+// provider's model list and reports whether the configured model name is
+// among them, so typos surface on the settings screen instead of at
+// discovery time.
+func ListAIModels(aiProvider ai.AIProvider, cfg *config.Config) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if aiProvider == nil {
+			writeError(w, http.StatusServiceUnavailable,
+				"AI provider not configured. Add your API key to config.toml")
+			return
+		}
+
+		models, err := aiProvider.ListModels(r.Context())
+		if err != nil {
+			slog.WarnContext(r.Context(), "failed to list AI models", "provider", cfg.AI.Provider, "error", err)
+			writeStageError(r.Context(), w, err, "listing AI models", http.StatusBadGateway, err.Error())
+			return
+		}
+
+		available := false
+		for _, m := range models {
+			if m.ID == cfg.AI.Model {
+				available = true
+				break
+			}
+		}
+
+		writeJSON(w, http.StatusOK, map[string]any{
+			"provider":                   cfg.AI.Provider,
+			"configured_model":           cfg.AI.Model,
+			"configured_model_available": available,
+			"models":                     models,
+		})
+	}
+}
+
+// maxAITestBodyBytes limits the size of a POST /api/ai/test body.
+const maxAITestBodyBytes = 64 * 1024
+
+// TestAIProvider handles POST /api/ai/test. It performs a cheap ping call to
+// verify the API key and model. An optional JSON body
+// {"provider", "api_key", "model"} tests unsaved settings; omitted fields
+// fall back to the configured values, except that a provider other than the
+// configured one needs its own api_key, so the saved key is never sent to
+// another vendor. Failures are reported in the response body with ok=false
+// rather than as an HTTP error.
+func TestAIProvider(aiProvider ai.AIProvider, cfg *config.Config) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Provider string `json:"provider"`
+			APIKey   string `json:"api_key"`
+			Model    string `json:"model"`
+		}
+		if r.Body != nil {
+			data, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxAITestBodyBytes))
+			if err != nil {
+				writeError(w, http.StatusBadRequest, "Failed to read request body")
+				return
+			}
+			if len(data) > 0 {
+				if err := json.Unmarshal(data, &body); err != nil {
+					writeError(w, http.StatusBadRequest, "Invalid JSON body")
+					return
+				}
+			}
+		}
+
+		providerCfg := ai.ProviderConfig{
+			Provider: cfg.AI.Provider,
+			APIKey:   cfg.AI.APIKey,
+			Model:    cfg.AI.Model,
+		}
+		overridden := false
+		if v := strings.TrimSpace(body.Provider); v != "" {
+			providerCfg.Provider = v
+			overridden = true
+		}
+		if v := strings.TrimSpace(body.APIKey); v != "" {
+			providerCfg.APIKey = v
+			overridden = true
+		} else if providerCfg.Provider != cfg.AI.Provider {
+			writeError(w, http.StatusBadRequest, "api_key is required when testing a provider other than the configured one")
+			return
+		}
+		if v := strings.TrimSpace(body.Model); v != "" {
+			providerCfg.Model = v
+			overridden = true
+		}
+
+		provider := aiProvider
+		if overridden || provider == nil {
+			if providerCfg.APIKey == "" {
+				writeError(w, http.StatusBadRequest, "No API key configured or provided")
+				return
+			}
+			p, err := ai.NewProvider(providerCfg)
+			if err != nil {
+				writeError(w, http.StatusBadRequest, err.Error())
+				return
+			}
+			provider = p
+		}
+
+		start := time.Now()
+		err := provider.Ping(r.Context())
+		resp := map[string]any{
+			"ok":         err == nil,
+			"provider":   providerCfg.Provider,
+			"model":      providerCfg.Model,
+			"latency_ms": time.Since(start).Milliseconds(),
+		}
+		if err != nil {
+			slog.WarnContext(r.Context(), "AI provider test failed", "provider", providerCfg.Provider, "model", providerCfg.Model, "error", err)
+			resp["error"] = err.Error()
+		}
+
+		writeJSON(w, http.StatusOK, resp)
+	}
+}
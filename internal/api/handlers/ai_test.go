@@ -0,0 +1,184 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/hoanghai1803/apricot/internal/ai"
+	"github.com/hoanghai1803/apricot/internal/config"
+)
+
+// stubAIProvider is a minimal ai.AIProvider for handler tests.
+type stubAIProvider struct {
+	models     []ai.ModelInfo
+	pingErr    error
+	difficulty string
+	prereqs    []ai.Prerequisite
+	title      string
+	summary    ai.Summary
+	sources    []ai.BlogEntry
+}
+
+// FilterAndRank returns the blogs in reverse order, so tests can tell the
+// AI's ranking from the input order.
+func (p *stubAIProvider) FilterAndRank(_ context.Context, _ string, blogs []ai.BlogEntry, maxResults int, _ bool) ([]ai.RankedBlog, error) {
+	var ranked []ai.RankedBlog
+	for i := len(blogs) - 1; i >= 0 && len(ranked) < maxResults; i-- {
+		ranked = append(ranked, ai.RankedBlog{ID: blogs[i].ID, Reason: "relevant"})
+	}
+	return ranked, nil
+}
+
+// BuildLearningPath returns the candidates in reverse order, so tests can
+// tell the AI's ordering from the search ranking.
+func (p *stubAIProvider) BuildLearningPath(_ context.Context, _ string, blogs []ai.BlogEntry, maxItems int) ([]ai.RankedBlog, error) {
+	var ranked []ai.RankedBlog
+	for i := len(blogs) - 1; i >= 0 && len(ranked) < maxItems; i-- {
+		ranked = append(ranked, ai.RankedBlog{ID: blogs[i].ID, Reason: "step"})
+	}
+	return ranked, nil
+}
+
+func (p *stubAIProvider) Summarize(context.Context, ai.BlogEntry) (ai.Summary, error) {
+	return p.summary, nil
+}
+
+func (p *stubAIProvider) ClassifyDifficulty(context.Context, ai.BlogEntry) (string, error) {
+	return p.difficulty, nil
+}
+
+func (p *stubAIProvider) RewriteTitle(_ context.Context, blog ai.BlogEntry) (string, error) {
+	if p.title == "" {
+		return blog.Title, nil
+	}
+	return p.title, nil
+}
+
+func (p *stubAIProvider) SuggestPrerequisites(context.Context, ai.BlogEntry) ([]ai.Prerequisite, error) {
+	return p.prereqs, nil
+}
+
+// SynthesizeAnswer records the sources it was given and cites the first.
+func (p *stubAIProvider) SynthesizeAnswer(_ context.Context, _ string, blogs []ai.BlogEntry) (string, error) {
+	p.sources = blogs
+	return "answer [1]", nil
+}
+
+func (p *stubAIProvider) NarrateYear(context.Context, int, []ai.BlogEntry) (string, error) {
+	return "", nil
+}
+
+func (p *stubAIProvider) ListModels(context.Context) ([]ai.ModelInfo, error) {
+	return p.models, nil
+}
+
+func (p *stubAIProvider) Ping(context.Context) error {
+	return p.pingErr
+}
+
+func testAIConfig() *config.Config {
+	return &config.Config{AI: config.AIConfig{Provider: "anthropic", APIKey: "k", Model: "claude-haiku-4-5"}}
+}
+
+func TestListAIModels(t *testing.T) {
+	provider := &stubAIProvider{models: []ai.ModelInfo{{ID: "claude-haiku-4-5"}, {ID: "claude-sonnet-4-5"}}}
+
+	w := httptest.NewRecorder()
+	ListAIModels(provider, testAIConfig()).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/ai/models", nil))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("got status %d, want %d", w.Code, http.StatusOK)
+	}
+
+	var resp struct {
+		Available bool           `json:"configured_model_available"`
+		Models    []ai.ModelInfo `json:"models"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("decoding response: %v", err)
+	}
+	if !resp.Available {
+		t.Error("configured_model_available = false, want true")
+	}
+	if len(resp.Models) != 2 {
+		t.Errorf("got %d models, want 2", len(resp.Models))
+	}
+}
+
+func TestListAIModels_NoProvider(t *testing.T) {
+	w := httptest.NewRecorder()
+	ListAIModels(nil, testAIConfig()).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/ai/models", nil))
+
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("got status %d, want %d", w.Code, http.StatusServiceUnavailable)
+	}
+}
+
+func TestTestAIProvider(t *testing.T) {
+	tests := []struct {
+		name    string
+		pingErr error
+		wantOK  bool
+	}{
+		{name: "ping succeeds", wantOK: true},
+		{name: "ping fails", pingErr: errors.New("invalid x-api-key"), wantOK: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider := &stubAIProvider{pingErr: tt.pingErr}
+
+			w := httptest.NewRecorder()
+			TestAIProvider(provider, testAIConfig()).ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/ai/test", nil))
+
+			if w.Code != http.StatusOK {
+				t.Fatalf("got status %d, want %d", w.Code, http.StatusOK)
+			}
+			var resp map[string]any
+			if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+				t.Fatalf("decoding response: %v", err)
+			}
+			if resp["ok"] != tt.wantOK {
+				t.Errorf("ok = %v, want %v", resp["ok"], tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestTestAIProvider_InvalidOverride(t *testing.T) {
+	body := strings.NewReader(`{"provider": "nope", "api_key": "k"}`)
+
+	w := httptest.NewRecorder()
+	TestAIProvider(nil, testAIConfig()).ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/ai/test", body))
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("got status %d, want %d", w.Code, http.StatusBadRequest)
+	}
+}
+
+func TestTestAIProvider_OtherProviderNeedsKey(t *testing.T) {
+	body := strings.NewReader(`{"provider": "openai", "model": "gpt-4o-mini"}`)
+
+	w := httptest.NewRecorder()
+	TestAIProvider(&stubAIProvider{}, testAIConfig()).ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/ai/test", body))
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("got status %d, want %d", w.Code, http.StatusBadRequest)
+	}
+}
+
+func TestTestAIProvider_OversizedBody(t *testing.T) {
+	body := strings.NewReader(`{"model": "` + strings.Repeat("x", maxAITestBodyBytes) + `"}`)
+
+	w := httptest.NewRecorder()
+	TestAIProvider(&stubAIProvider{}, testAIConfig()).ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/ai/test", body))
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("got status %d, want %d", w.Code, http.StatusBadRequest)
+	}
+}
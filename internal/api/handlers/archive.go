@@ -0,0 +1,134 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/hoanghai1803/apricot/internal/archive"
+	"github.com/hoanghai1803/apricot/internal/models"
+	"github.com/hoanghai1803/apricot/internal/storage"
+)
+
+// ExportArchive handles GET /api/export. It streams the sources, posts and
+// summaries, reading list, tags, and preferences as an export archive, with
+// a closing manifest of schema version, record counts, and checksums.
+// Records are written as they are read (see storage.ExportAll), so a large
+// database is never held in memory.
+func ExportArchive(store storage.Store) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		version, err := store.SchemaVersion(ctx)
+		if err != nil {
+			slog.ErrorContext(ctx, "failed to get schema version", "error", err)
+			writeError(w, http.StatusInternalServerError, "Failed to export data")
+			return
+		}
+		now := time.Now()
+		started := false
+		err = store.ExportAll(ctx, func(data *models.ArchiveStream) error {
+			w.Header().Set("Content-Type", "application/json")
+			w.Header().Set("Content-Disposition",
+				fmt.Sprintf(`attachment; filename="apricot-export-%s.json"`, now.Format("2006-01-02")))
+			w.WriteHeader(http.StatusOK)
+			started = true
+			return archive.WriteStream(w, data, version, now)
+		})
+		switch {
+		case err == nil:
+		case !started:
+			slog.ErrorContext(ctx, "failed to export data", "error", err)
+			writeError(w, http.StatusInternalServerError, "Failed to export data")
+		default:
+			// The status is already sent, so the failure can only be
+			// logged; the archive is left without its manifest and fails
+			// verification.
+			slog.ErrorContext(ctx, "failed to write export archive", "error", err)
+		}
+	}
+}
+
+// maxImportBodyBytes limits the size of a POST /api/import body.
+const maxImportBodyBytes = 512 << 20
+
+// ImportArchive handles POST /api/import. The body is an export archive,
+// which is verified against its manifest before anything is written, or,
+// with ?format=instapaper or ?format=omnivore, another app's export (see
+// archive.ReadAny); without format the kind of file is detected.
+// Archives from a newer Apricot are refused with 409 and a migration hint;
+// malformed or modified archives with 400. Records that already exist are
+// skipped, or replaced with ?on_conflict=overwrite. With ?dry_run=true
+// nothing is written, and the response lists what would be created, merged,
+// overwritten, or skipped. Bodies over maxImportBodyBytes get 413.
+func ImportArchive(store storage.Store) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		opts := storage.ImportOptions{
+			OnConflict: r.URL.Query().Get("on_conflict"),
+			DryRun:     r.URL.Query().Get("dry_run") == "true",
+		}
+		switch opts.OnConflict {
+		case "", storage.ConflictSkip, storage.ConflictOverwrite:
+		default:
+			writeError(w, http.StatusBadRequest, "on_conflict must be skip or overwrite")
+			return
+		}
+
+		version, err := store.SchemaVersion(ctx)
+		if err != nil {
+			slog.ErrorContext(ctx, "failed to get schema version", "error", err)
+			writeError(w, http.StatusInternalServerError, "Failed to import data")
+			return
+		}
+
+		// Refuse a body announced as too large before reading any of it.
+		if r.ContentLength > maxImportBodyBytes {
+			writeImportTooLarge(w)
+			return
+		}
+		body := http.MaxBytesReader(w, r.Body, maxImportBodyBytes)
+		data, manifest, err := archive.ReadAny(body, r.URL.Query().Get("format"), version)
+		if err != nil {
+			var tooLarge *http.MaxBytesError
+			switch {
+			case errors.As(err, &tooLarge):
+				writeImportTooLarge(w)
+			case errors.Is(err, archive.ErrIncompatible):
+				writeError(w, http.StatusConflict, err.Error())
+			default:
+				writeError(w, http.StatusBadRequest, err.Error())
+			}
+			return
+		}
+
+		result, err := store.ImportArchive(ctx, data, opts)
+		if err != nil {
+			slog.ErrorContext(ctx, "failed to import archive", "error", err)
+			writeError(w, http.StatusInternalServerError, "Failed to import data")
+			return
+		}
+		if opts.DryRun {
+			writeJSON(w, http.StatusOK, result)
+			return
+		}
+
+		exportedAt := "unknown" // exports from other apps have no manifest
+		if manifest != nil {
+			exportedAt = manifest.ExportedAt.Format(time.RFC3339)
+		}
+		slog.InfoContext(r.Context(), "imported archive",
+			"exported_at", exportedAt, "on_conflict", result.OnConflict,
+			"items_added", result.ItemsAdded, "items_merged", result.ItemsMerged,
+			"items_overwritten", result.ItemsOverwritten, "items_skipped", result.ItemsSkipped)
+		writeJSON(w, http.StatusOK, result)
+	}
+}
+
+// writeImportTooLarge responds 413 to an import over maxImportBodyBytes.
+func writeImportTooLarge(w http.ResponseWriter) {
+	writeError(w, http.StatusRequestEntityTooLarge, fmt.Sprintf("Import is larger than %d MB", maxImportBodyBytes>>20))
+}
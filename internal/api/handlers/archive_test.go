@@ -0,0 +1,187 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/hoanghai1803/apricot/internal/models"
+)
+
+func TestExportImportArchive(t *testing.T) {
+	src := newTestStore(t)
+	blogID := seedBlog(t, src)
+	if err := src.AddToReadingList(t.Context(), blogID); err != nil {
+		t.Fatalf("AddToReadingList: %v", err)
+	}
+
+	r := httptest.NewRequest(http.MethodGet, "/api/export", nil)
+	w := httptest.NewRecorder()
+	ExportArchive(src).ServeHTTP(w, r)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("export status %d, want %d; body: %s", w.Code, http.StatusOK, w.Body.String())
+	}
+	if !strings.Contains(w.Header().Get("Content-Disposition"), "apricot-export-") {
+		t.Errorf("Content-Disposition = %q", w.Header().Get("Content-Disposition"))
+	}
+	exported := w.Body.Bytes()
+
+	dst := newTestStore(t)
+	r = httptest.NewRequest(http.MethodPost, "/api/import?dry_run=true", bytes.NewReader(exported))
+	w = httptest.NewRecorder()
+	ImportArchive(dst).ServeHTTP(w, r)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("dry-run import status %d, want %d; body: %s", w.Code, http.StatusOK, w.Body.String())
+	}
+	var preview models.ArchiveImportResult
+	if err := json.NewDecoder(w.Body).Decode(&preview); err != nil {
+		t.Fatalf("decoding response: %v", err)
+	}
+	if !preview.DryRun || preview.ItemsAdded != 1 || len(preview.Changes) == 0 {
+		t.Errorf("dry-run result = %+v, want 1 item to add with changes listed", preview)
+	}
+	items, err := dst.GetReadingList(t.Context(), "")
+	if err != nil {
+		t.Fatalf("GetReadingList: %v", err)
+	}
+	if len(items) != 0 {
+		t.Fatalf("dry run imported %d items, want 0", len(items))
+	}
+
+	r = httptest.NewRequest(http.MethodPost, "/api/import", bytes.NewReader(exported))
+	w = httptest.NewRecorder()
+	ImportArchive(dst).ServeHTTP(w, r)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("import status %d, want %d; body: %s", w.Code, http.StatusOK, w.Body.String())
+	}
+	var result models.ArchiveImportResult
+	if err := json.NewDecoder(w.Body).Decode(&result); err != nil {
+		t.Fatalf("decoding response: %v", err)
+	}
+	if result.DryRun || result.ItemsAdded != 1 || result.Changes != nil {
+		t.Errorf("result = %+v, want 1 item added", result)
+	}
+}
+
+func TestImportArchive_Rejects(t *testing.T) {
+	store := newTestStore(t)
+
+	r := httptest.NewRequest(http.MethodGet, "/api/export", nil)
+	w := httptest.NewRecorder()
+	ExportArchive(store).ServeHTTP(w, r)
+	valid := w.Body.String()
+
+	tests := []struct {
+		name       string
+		body       string
+		wantStatus int
+	}{
+		{"not an archive", `{"hello": "world"}`, http.StatusBadRequest},
+		{"modified", strings.Replace(valid, `"sources": [`, `"sources": [{"feed_url": "https://x"},`, 1), http.StatusBadRequest},
+		{"newer schema", strings.Replace(valid, `"schema_version": `, `"schema_version": 9`, 1), http.StatusConflict},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, "/api/import", strings.NewReader(tt.body))
+			w := httptest.NewRecorder()
+			ImportArchive(store).ServeHTTP(w, r)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("got status %d, want %d; body: %s", w.Code, tt.wantStatus, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestImportArchive_TooLarge(t *testing.T) {
+	store := newTestStore(t)
+
+	// Announced as too large: refused before the body is read.
+	r := httptest.NewRequest(http.MethodPost, "/api/import", strings.NewReader(`{}`))
+	r.ContentLength = maxImportBodyBytes + 1
+	w := httptest.NewRecorder()
+	ImportArchive(store).ServeHTTP(w, r)
+	if w.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("Content-Length over the limit: got status %d, want %d", w.Code, http.StatusRequestEntityTooLarge)
+	}
+
+	// Unannounced: cut off while reading.
+	body := io.MultiReader(strings.NewReader("url,title\n"), &linesReader{n: maxImportBodyBytes})
+	r = httptest.NewRequest(http.MethodPost, "/api/import?format=instapaper", body)
+	r.ContentLength = -1
+	w = httptest.NewRecorder()
+	ImportArchive(store).ServeHTTP(w, r)
+	if w.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("streamed body over the limit: got status %d, want %d; body: %s", w.Code, http.StatusRequestEntityTooLarge, w.Body.String())
+	}
+}
+
+// linesReader reads n bytes of 64 KB lines that are not URLs.
+type linesReader struct {
+	n, read int
+}
+
+var notURLLine = append(bytes.Repeat([]byte("x"), 64<<10-1), '\n')
+
+func (r *linesReader) Read(p []byte) (int, error) {
+	if r.read == r.n {
+		return 0, io.EOF
+	}
+	line := notURLLine[r.read%len(notURLLine):]
+	n := copy(p[:min(len(p), r.n-r.read)], line)
+	r.read += n
+	return n, nil
+}
+
+func TestImportArchive_InvalidConflictPolicy(t *testing.T) {
+	store := newTestStore(t)
+
+	r := httptest.NewRequest(http.MethodPost, "/api/import?on_conflict=merge", strings.NewReader(`{}`))
+	w := httptest.NewRecorder()
+	ImportArchive(store).ServeHTTP(w, r)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("got status %d, want %d; body: %s", w.Code, http.StatusBadRequest, w.Body.String())
+	}
+}
+
+func TestImportArchive_Instapaper(t *testing.T) {
+	store := newTestStore(t)
+	csv := "URL,Title,Selection,Folder,Timestamp\n" +
+		"https://a.example/one,One,,Archive,1700000000\n" +
+		"https://a.example/two,Two,,Reading Group,1700000100\n"
+
+	r := httptest.NewRequest(http.MethodPost, "/api/import?format=instapaper", strings.NewReader(csv))
+	w := httptest.NewRecorder()
+	ImportArchive(store).ServeHTTP(w, r)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status %d, want %d; body: %s", w.Code, http.StatusOK, w.Body.String())
+	}
+	var result models.ArchiveImportResult
+	if err := json.NewDecoder(w.Body).Decode(&result); err != nil {
+		t.Fatalf("decoding result: %v", err)
+	}
+	if result.ItemsAdded != 2 {
+		t.Errorf("items added = %d, want 2", result.ItemsAdded)
+	}
+
+	items, err := store.GetReadingList(t.Context(), "")
+	if err != nil {
+		t.Fatalf("GetReadingList: %v", err)
+	}
+	status := map[string]string{}
+	for _, item := range items {
+		status[item.Blog.Title] = item.Status + " " + strings.Join(item.Tags, ",")
+	}
+	if status["One"] != "read " || status["Two"] != "unread reading group" {
+		t.Errorf("imported items = %v, want One read and Two unread, tagged by folder", status)
+	}
+}
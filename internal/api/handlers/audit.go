@@ -0,0 +1,38 @@
+package handlers
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/hoanghai1803/apricot/internal/storage"
+)
+
+// GetAuditLog handles GET /api/admin/audit?entity=...&entity_id=...&action=...
+// &limit=...&offset=.... It returns audit log entries, newest first: source
+// toggles, preference and tag changes, and deletions, each with the values
+// before and after. Filters are optional; the limit defaults to 50.
+func GetAuditLog(store storage.Store) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		limit, offset, err := parsePage(r, 50)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		q := r.URL.Query()
+		entries, err := store.ListAuditLog(r.Context(), storage.AuditFilter{
+			Entity:   q.Get("entity"),
+			EntityID: q.Get("entity_id"),
+			Action:   q.Get("action"),
+			Limit:    limit,
+			Offset:   offset,
+		})
+		if err != nil {
+			slog.ErrorContext(r.Context(), "failed to list audit log", "error", err)
+			writeError(w, http.StatusInternalServerError, "Failed to get audit log")
+			return
+		}
+
+		writeJSON(w, http.StatusOK, entries)
+	}
+}
@@ -0,0 +1,50 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/hoanghai1803/apricot/internal/models"
+	"github.com/hoanghai1803/apricot/internal/storage"
+)
+
+func TestGetAuditLog(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+	if err := store.SetPreference(ctx, "max_results", 10); err != nil {
+		t.Fatalf("SetPreference: %v", err)
+	}
+	if err := store.UpdateTag(ctx, "go", "#00add8", ""); err != nil {
+		t.Fatalf("UpdateTag: %v", err)
+	}
+
+	r := httptest.NewRequest(http.MethodGet, "/api/admin/audit?entity=preference", nil)
+	w := httptest.NewRecorder()
+	GetAuditLog(store).ServeHTTP(w, r)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status %d, want %d; body: %s", w.Code, http.StatusOK, w.Body.String())
+	}
+	var entries []models.AuditEntry
+	if err := json.NewDecoder(w.Body).Decode(&entries); err != nil {
+		t.Fatalf("decoding response: %v", err)
+	}
+	if len(entries) != 1 || entries[0].Action != storage.AuditPreferenceSet || entries[0].EntityID != "max_results" {
+		t.Errorf("entries = %+v, want the preference change only", entries)
+	}
+}
+
+func TestGetAuditLog_InvalidLimit(t *testing.T) {
+	store := newTestStore(t)
+
+	r := httptest.NewRequest(http.MethodGet, "/api/admin/audit?limit=-1", nil)
+	w := httptest.NewRecorder()
+	GetAuditLog(store).ServeHTTP(w, r)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("status %d, want %d", w.Code, http.StatusBadRequest)
+	}
+}
@@ -0,0 +1,57 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/hoanghai1803/apricot/internal/backup"
+	"github.com/hoanghai1803/apricot/internal/jobs"
+)
+
+// CreateBackup handles POST /api/admin/backup. It queues a "backup" job
+// (see BackupJob) and returns 202 Accepted with the job; poll
+// GET /api/jobs/{id} for the new backup. backups is nil when the database
+// is not SQLite; Postgres is backed up with its own tools.
+func CreateBackup(backups *backup.Manager, runner *jobs.Manager) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if backups == nil {
+			writeError(w, http.StatusServiceUnavailable,
+				"Backups are only available with the sqlite storage driver. Use pg_dump for Postgres")
+			return
+		}
+
+		job, err := runner.Enqueue(r.Context(), "backup", struct{}{})
+		if err != nil {
+			slog.ErrorContext(r.Context(), "failed to queue backup", "error", err)
+			writeError(w, http.StatusInternalServerError, "Failed to start backup")
+			return
+		}
+
+		w.Header().Set("Location", fmt.Sprintf("/api/jobs/%d", job.ID))
+		writeJSON(w, http.StatusAccepted, job)
+	}
+}
+
+// BackupJob returns the "backup" job kind, which writes a copy of the
+// database to the backup directory, deletes backups beyond the configured
+// number to keep, and returns the new backup. A failed backup is retried
+// twice.
+func BackupJob(backups *backup.Manager) jobs.Kind {
+	return jobs.Kind{
+		Name:        "backup",
+		Timeout:     10 * time.Minute,
+		MaxAttempts: 3,
+		Handler: func(ctx context.Context, _ json.RawMessage) (any, error) {
+			b, err := backups.Run(ctx)
+			if err != nil {
+				return nil, err
+			}
+			slog.InfoContext(ctx, "wrote backup", "name", b.Name, "bytes", b.Size)
+			return b, nil
+		},
+	}
+}
@@ -0,0 +1,62 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hoanghai1803/apricot/internal/backup"
+	"github.com/hoanghai1803/apricot/internal/jobs"
+	"github.com/hoanghai1803/apricot/internal/models"
+)
+
+func TestCreateBackup(t *testing.T) {
+	store := newTestStore(t)
+	backups := backup.NewManager(store, t.TempDir(), 3)
+	runner := jobs.NewManager(store, 1)
+	runner.Register(BackupJob(backups))
+
+	r := httptest.NewRequest(http.MethodPost, "/api/admin/backup", nil)
+	w := httptest.NewRecorder()
+	CreateBackup(backups, runner).ServeHTTP(w, r)
+
+	if w.Code != http.StatusAccepted {
+		t.Fatalf("status %d, want %d; body: %s", w.Code, http.StatusAccepted, w.Body.String())
+	}
+	var job models.Job
+	if err := json.NewDecoder(w.Body).Decode(&job); err != nil {
+		t.Fatalf("decoding response: %v", err)
+	}
+
+	if err := runner.Drain(context.Background()); err != nil {
+		t.Fatalf("Drain() error: %v", err)
+	}
+	got, err := runner.Get(context.Background(), job.ID)
+	if err != nil {
+		t.Fatalf("Get() error: %v", err)
+	}
+	if got.Status != models.JobSucceeded {
+		t.Fatalf("backup job = %+v, want it to have succeeded", got)
+	}
+	var b backup.Backup
+	if err := json.Unmarshal(got.Result, &b); err != nil {
+		t.Fatalf("decoding result: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(backups.Dir(), b.Name)); err != nil {
+		t.Errorf("backup file %q: %v", b.Name, err)
+	}
+}
+
+func TestCreateBackup_Unavailable(t *testing.T) {
+	r := httptest.NewRequest(http.MethodPost, "/api/admin/backup", nil)
+	w := httptest.NewRecorder()
+	CreateBackup(nil, nil).ServeHTTP(w, r)
+
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("status %d, want %d", w.Code, http.StatusServiceUnavailable)
+	}
+}
@@ -0,0 +1,448 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hoanghai1803/apricot/internal/bookmarks"
+	"github.com/hoanghai1803/apricot/internal/config"
+	"github.com/hoanghai1803/apricot/internal/jobs"
+	"github.com/hoanghai1803/apricot/internal/models"
+	"github.com/hoanghai1803/apricot/internal/notify"
+	"github.com/hoanghai1803/apricot/internal/storage"
+)
+
+// BookmarkSyncResult is the result of a "bookmarks" job: how many items
+// and bookmarks each step of the sync touched.
+type BookmarkSyncResult struct {
+	Service   string `json:"service"`
+	Imported  int    `json:"imported"`  // bookmarks added to the reading list
+	Exported  int    `json:"exported"`  // items bookmarked in the service
+	Pulled    int    `json:"pulled"`    // items updated from their bookmark
+	Pushed    int    `json:"pushed"`    // bookmarks updated from their item
+	Removed   int    `json:"removed"`   // items removed with their bookmark
+	Deleted   int    `json:"deleted"`   // bookmarks deleted with their item
+	Conflicts int    `json:"conflicts"` // changed on both sides; settled by bookmarks.conflict
+}
+
+// SyncBookmarks handles POST /api/admin/bookmarks/sync. It queues a
+// "bookmarks" job (see BookmarksJob) and returns 202 Accepted with the
+// job. svc is nil when no bookmarking service is configured.
+func SyncBookmarks(svc bookmarks.Service, runner *jobs.Manager) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if svc == nil {
+			writeError(w, http.StatusServiceUnavailable, "Bookmark sync is not configured. Add a [bookmarks] section to config.toml")
+			return
+		}
+
+		job, err := runner.Enqueue(r.Context(), "bookmarks", struct{}{})
+		if err != nil {
+			slog.ErrorContext(r.Context(), "failed to queue bookmark sync", "error", err)
+			writeError(w, http.StatusInternalServerError, "Failed to start bookmark sync")
+			return
+		}
+
+		w.Header().Set("Location", fmt.Sprintf("/api/jobs/%d", job.ID))
+		writeJSON(w, http.StatusAccepted, job)
+	}
+}
+
+// BookmarksJob returns the "bookmarks" job kind, which mirrors the reading
+// list to the bookmarking service svc, both ways:
+//
+//   - A bookmark not yet in Apricot is quick-saved, so its page is fetched
+//     in the background, with its title, tags, notes, and read state. One
+//     for a post already on the list is linked to its item, merging their
+//     tags and keeping whichever notes are set (the conflict winner's, if
+//     both are).
+//   - An item not yet bookmarked is bookmarked.
+//   - The notes, tags, and read state of a linked pair are copied from the
+//     side that changed since the last sync to the other. If both did, the
+//     side named by bookmarks.conflict wins.
+//   - Deleting either side deletes the other, unless the other changed
+//     since the last sync; then it is copied back instead.
+//
+// Syncs run one at a time. A failed sync is retried twice, picking up
+// where it stopped, unless the service refused the token.
+func BookmarksJob(store storage.Store, svc bookmarks.Service, runner *jobs.Manager, notifier *notify.Notifier, cfg *config.Config) jobs.Kind {
+	var mu sync.Mutex
+	return jobs.Kind{
+		Name:        "bookmarks",
+		Timeout:     30 * time.Minute, // Pinboard allows one request every 3 seconds
+		MaxAttempts: 3,
+		Backoff:     5 * time.Minute,
+		Handler: func(ctx context.Context, _ json.RawMessage) (any, error) {
+			mu.Lock()
+			defer mu.Unlock()
+
+			s := &bookmarkSync{
+				store:      store,
+				svc:        svc,
+				runner:     runner,
+				notifier:   notifier,
+				remoteWins: cfg.Bookmarks.Conflict == "remote",
+				result:     BookmarkSyncResult{Service: svc.Name()},
+			}
+			err := s.run(ctx)
+			if errors.Is(err, bookmarks.ErrUnauthorized) {
+				return nil, jobs.Permanent(err)
+			}
+			if err != nil {
+				return nil, err
+			}
+			slog.InfoContext(ctx, "synced bookmarks", "service", svc.Name(),
+				"imported", s.result.Imported, "exported", s.result.Exported,
+				"pulled", s.result.Pulled, "pushed", s.result.Pushed,
+				"removed", s.result.Removed, "deleted", s.result.Deleted,
+				"conflicts", s.result.Conflicts)
+			return s.result, nil
+		},
+	}
+}
+
+// bookmarkSync is one run of the "bookmarks" job.
+type bookmarkSync struct {
+	store      storage.Store
+	svc        bookmarks.Service
+	runner     *jobs.Manager
+	notifier   *notify.Notifier
+	remoteWins bool
+	result     BookmarkSyncResult
+}
+
+// run syncs the reading list with the service; see BookmarksJob. Each
+// change is recorded in its link as it is made, so a sync that fails
+// halfway is finished by the next.
+func (s *bookmarkSync) run(ctx context.Context) error {
+	remote, err := s.svc.List(ctx)
+	if err != nil {
+		return fmt.Errorf("listing bookmarks: %w", err)
+	}
+	list, err := s.store.GetReadingListFiltered(ctx, storage.ReadingListFilter{WithoutContent: true})
+	if err != nil {
+		return fmt.Errorf("loading reading list: %w", err)
+	}
+	links, err := s.store.ListBookmarkLinks(ctx, s.svc.Name())
+	if err != nil {
+		return fmt.Errorf("loading bookmark links: %w", err)
+	}
+
+	items := make(map[int64]*models.ReadingListItem, len(list))
+	for i := range list {
+		if list[i].Blog != nil {
+			items[list[i].ID] = &list[i]
+		}
+	}
+	bookmarksByID := make(map[string]bookmarks.Bookmark, len(remote))
+	for _, b := range remote {
+		bookmarksByID[b.ID] = b
+	}
+
+	// Linked pairs, including those where one side is gone.
+	linkedItems := make(map[int64]bool)
+	linkedBookmarks := make(map[string]bool)
+	for _, link := range links {
+		item, hasItem := items[link.ReadingListID]
+		hasItem = hasItem && item.Blog.URL == link.URL
+		b, hasBookmark := bookmarksByID[link.RemoteID]
+
+		var err error
+		switch {
+		case hasItem && hasBookmark:
+			linkedItems[item.ID], linkedBookmarks[b.ID] = true, true
+			err = s.syncPair(ctx, link, item, b)
+		case hasBookmark:
+			if bookmarks.Fingerprint(b) != link.RemoteHash {
+				// Edited since: leave it to be imported again below.
+				err = s.store.DeleteBookmarkLink(ctx, link.Service, link.RemoteID)
+				break
+			}
+			linkedBookmarks[b.ID] = true
+			err = s.deleteBookmark(ctx, link)
+		case hasItem:
+			if s.fingerprint(item) != link.LocalHash {
+				// Edited since: leave it to be bookmarked again below.
+				err = s.store.DeleteBookmarkLink(ctx, link.Service, link.RemoteID)
+				break
+			}
+			linkedItems[item.ID] = true
+			err = s.removeItem(ctx, link, item)
+		default:
+			err = s.store.DeleteBookmarkLink(ctx, link.Service, link.RemoteID)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	// Bookmarks without a link: link them to the item for the same post,
+	// or import them.
+	itemsByURL := make(map[string]*models.ReadingListItem)
+	for _, item := range items {
+		if !linkedItems[item.ID] {
+			itemsByURL[item.Blog.URL] = item
+		}
+	}
+	for _, b := range remote {
+		if linkedBookmarks[b.ID] {
+			continue
+		}
+		if item, ok := itemsByURL[b.URL]; ok {
+			linkedItems[item.ID] = true
+			delete(itemsByURL, b.URL)
+			if err := s.linkPair(ctx, item, b); err != nil {
+				return err
+			}
+			continue
+		}
+		id, err := s.importBookmark(ctx, b)
+		if err != nil {
+			return err
+		}
+		linkedItems[id] = true // so it isn't bookmarked again below
+	}
+
+	// Items without a link: bookmark them.
+	for _, item := range list {
+		if item.Blog == nil || linkedItems[item.ID] {
+			continue
+		}
+		if err := s.exportItem(ctx, &item); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// syncPair copies the changes since the last sync between a linked item
+// and bookmark.
+func (s *bookmarkSync) syncPair(ctx context.Context, link models.BookmarkLink, item *models.ReadingListItem, b bookmarks.Bookmark) error {
+	localChanged := s.fingerprint(item) != link.LocalHash
+	remoteChanged := bookmarks.Fingerprint(b) != link.RemoteHash
+	if localChanged && remoteChanged {
+		s.result.Conflicts++
+		localChanged, remoteChanged = !s.remoteWins, s.remoteWins
+	}
+
+	switch {
+	case localChanged:
+		s.result.Pushed++
+		return s.push(ctx, item, s.itemBookmark(item, b.ID))
+	case remoteChanged:
+		s.result.Pulled++
+		return s.pull(ctx, item, b)
+	}
+	return nil
+}
+
+// linkPair links a bookmark to the item for the same post, found on the
+// first sync or after one side was recreated, merging the two: tags from
+// both, and the notes and read state from whichever side has them, or the
+// conflict winner if both do.
+func (s *bookmarkSync) linkPair(ctx context.Context, item *models.ReadingListItem, b bookmarks.Bookmark) error {
+	local := s.itemBookmark(item, b.ID)
+	merged := local
+	for _, tag := range b.Tags {
+		if !slices.ContainsFunc(merged.Tags, func(t string) bool { return strings.EqualFold(t, tag) }) {
+			merged.Tags = append(merged.Tags, tag)
+		}
+	}
+	if strings.TrimSpace(local.Notes) == "" || (s.remoteWins && strings.TrimSpace(b.Notes) != "") {
+		merged.Notes = b.Notes
+	}
+	if b.Read != nil && (local.Read == nil || s.remoteWins) {
+		merged.Read = b.Read
+	}
+
+	hash := bookmarks.Fingerprint(merged)
+	if hash != bookmarks.Fingerprint(local) {
+		if err := s.applyBookmark(ctx, item, merged); err != nil {
+			return err
+		}
+		s.result.Pulled++
+	}
+	if hash != bookmarks.Fingerprint(b) {
+		s.result.Pushed++
+		return s.push(ctx, item, merged)
+	}
+	return s.saveLink(ctx, item, b)
+}
+
+// push updates the bookmark of item to b and records the link.
+func (s *bookmarkSync) push(ctx context.Context, item *models.ReadingListItem, b bookmarks.Bookmark) error {
+	stored, err := s.svc.Update(ctx, b)
+	if err != nil {
+		return fmt.Errorf("updating bookmark of %s: %w", item.Blog.URL, err)
+	}
+	return s.saveLink(ctx, item, stored)
+}
+
+// pull updates item from its bookmark b and records the link.
+func (s *bookmarkSync) pull(ctx context.Context, item *models.ReadingListItem, b bookmarks.Bookmark) error {
+	if err := s.applyBookmark(ctx, item, b); err != nil {
+		return err
+	}
+	return s.saveLink(ctx, item, b)
+}
+
+// applyBookmark sets the notes, tags, and read state of item to b's, and
+// reloads it.
+func (s *bookmarkSync) applyBookmark(ctx context.Context, item *models.ReadingListItem, b bookmarks.Bookmark) error {
+	notes := ""
+	if item.Notes != nil {
+		notes = *item.Notes
+	}
+	if strings.TrimSpace(b.Notes) != strings.TrimSpace(notes) {
+		if err := s.store.UpdateReadingListNotes(ctx, item.ID, strings.TrimSpace(b.Notes)); err != nil {
+			return fmt.Errorf("updating notes of item %d: %w", item.ID, err)
+		}
+	}
+
+	want := make(map[string]bool, len(b.Tags))
+	for _, tag := range b.Tags {
+		if tag = strings.ToLower(strings.TrimSpace(tag)); tag != "" {
+			want[tag] = true
+		}
+	}
+	for _, tag := range item.Tags {
+		if !want[tag] {
+			if err := s.store.RemoveTagFromItem(ctx, item.ID, tag); err != nil {
+				return fmt.Errorf("untagging item %d: %w", item.ID, err)
+			}
+		}
+		delete(want, tag)
+	}
+	for tag := range want {
+		if err := s.store.AddTagToItem(ctx, item.ID, tag); err != nil {
+			return fmt.Errorf("tagging item %d: %w", item.ID, err)
+		}
+	}
+
+	if b.Read != nil && *b.Read != itemRead(item) {
+		status := "unread"
+		if *b.Read {
+			status = "read"
+		}
+		if err := s.store.UpdateReadingListStatus(ctx, item.ID, status); err != nil {
+			return fmt.Errorf("updating status of item %d: %w", item.ID, err)
+		}
+	}
+
+	updated, err := s.store.GetReadingListItemByID(ctx, item.ID)
+	if err != nil {
+		return fmt.Errorf("reloading item %d: %w", item.ID, err)
+	}
+	*item = *updated
+	return nil
+}
+
+// importBookmark quick-saves bookmark b, links it to the new item, and
+// returns the item's ID, or 0 if b was skipped.
+func (s *bookmarkSync) importBookmark(ctx context.Context, b bookmarks.Bookmark) (int64, error) {
+	resp, addErr := quickSave(ctx, s.store, s.runner, s.notifier, saveRequest{
+		URL:   b.URL,
+		Title: b.Title,
+		Tags:  b.Tags,
+		Notes: b.Notes,
+	})
+	if addErr != nil {
+		if addErr.status == http.StatusBadRequest {
+			slog.WarnContext(ctx, "skipped bookmark", "url", b.URL, "error", addErr.message)
+			return 0, nil
+		}
+		return 0, fmt.Errorf("importing %s: %s", b.URL, addErr.message)
+	}
+	if b.Read != nil && *b.Read {
+		if err := s.store.UpdateReadingListStatus(ctx, resp.ItemID, "read"); err != nil {
+			return 0, fmt.Errorf("updating status of item %d: %w", resp.ItemID, err)
+		}
+	}
+	item, err := s.store.GetReadingListItemByID(ctx, resp.ItemID)
+	if err != nil {
+		return 0, fmt.Errorf("loading imported item %d: %w", resp.ItemID, err)
+	}
+	s.result.Imported++
+	return item.ID, s.saveLink(ctx, item, b)
+}
+
+// exportItem bookmarks item and links it to the new bookmark.
+func (s *bookmarkSync) exportItem(ctx context.Context, item *models.ReadingListItem) error {
+	stored, err := s.svc.Create(ctx, s.itemBookmark(item, ""))
+	if err != nil {
+		return fmt.Errorf("bookmarking %s: %w", item.Blog.URL, err)
+	}
+	s.result.Exported++
+	return s.saveLink(ctx, item, stored)
+}
+
+// deleteBookmark deletes the bookmark of a removed item and its link.
+func (s *bookmarkSync) deleteBookmark(ctx context.Context, link models.BookmarkLink) error {
+	if err := s.svc.Delete(ctx, link.RemoteID); err != nil {
+		return fmt.Errorf("deleting bookmark of %s: %w", link.URL, err)
+	}
+	s.result.Deleted++
+	return s.store.DeleteBookmarkLink(ctx, link.Service, link.RemoteID)
+}
+
+// removeItem removes the item of a deleted bookmark and its link. The
+// removal, with the item's notes, is recorded in the audit log.
+func (s *bookmarkSync) removeItem(ctx context.Context, link models.BookmarkLink, item *models.ReadingListItem) error {
+	if err := s.store.RemoveFromReadingList(ctx, item.ID); err != nil && !errors.Is(err, storage.ErrNotFound) {
+		return fmt.Errorf("removing item %d: %w", item.ID, err)
+	}
+	s.result.Removed++
+	return s.store.DeleteBookmarkLink(ctx, link.Service, link.RemoteID)
+}
+
+// saveLink records that item and bookmark b mirror each other as they are
+// now.
+func (s *bookmarkSync) saveLink(ctx context.Context, item *models.ReadingListItem, b bookmarks.Bookmark) error {
+	return s.store.SaveBookmarkLink(ctx, &models.BookmarkLink{
+		Service:       s.svc.Name(),
+		RemoteID:      b.ID,
+		ReadingListID: item.ID,
+		URL:           item.Blog.URL,
+		LocalHash:     s.fingerprint(item),
+		RemoteHash:    bookmarks.Fingerprint(b),
+	})
+}
+
+// itemBookmark returns item as a bookmark with the given ID. Its read
+// state is left out for a service that doesn't track it.
+func (s *bookmarkSync) itemBookmark(item *models.ReadingListItem, id string) bookmarks.Bookmark {
+	b := bookmarks.Bookmark{
+		ID:    id,
+		URL:   item.Blog.URL,
+		Title: item.Blog.Title,
+		Tags:  slices.Clone(item.Tags),
+	}
+	if item.Blog.RewrittenTitle != "" {
+		b.Title = item.Blog.RewrittenTitle
+	}
+	if item.Notes != nil {
+		b.Notes = *item.Notes
+	}
+	if s.svc.TracksRead() {
+		read := itemRead(item)
+		b.Read = &read
+	}
+	return b
+}
+
+// fingerprint returns the fingerprint of item's synced fields.
+func (s *bookmarkSync) fingerprint(item *models.ReadingListItem) string {
+	return bookmarks.Fingerprint(s.itemBookmark(item, ""))
+}
+
+// itemRead reports whether item counts as read in a bookmarking service.
+func itemRead(item *models.ReadingListItem) bool {
+	return item.Status == "read" || item.Status == "archived"
+}
@@ -0,0 +1,201 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strconv"
+	"testing"
+
+	"github.com/hoanghai1803/apricot/internal/bookmarks"
+	"github.com/hoanghai1803/apricot/internal/config"
+	"github.com/hoanghai1803/apricot/internal/jobs"
+	"github.com/hoanghai1803/apricot/internal/models"
+)
+
+// fakeBookmarks is an in-memory bookmarking service that tracks read
+// state, like Pinboard.
+type fakeBookmarks struct {
+	marks  map[string]bookmarks.Bookmark
+	nextID int
+}
+
+func (f *fakeBookmarks) Name() string     { return "fake" }
+func (f *fakeBookmarks) TracksRead() bool { return true }
+
+func (f *fakeBookmarks) List(context.Context) ([]bookmarks.Bookmark, error) {
+	var all []bookmarks.Bookmark
+	for _, b := range f.marks {
+		all = append(all, b)
+	}
+	return all, nil
+}
+
+func (f *fakeBookmarks) Create(_ context.Context, b bookmarks.Bookmark) (bookmarks.Bookmark, error) {
+	f.nextID++
+	b.ID = strconv.Itoa(f.nextID)
+	f.marks[b.ID] = b
+	return b, nil
+}
+
+func (f *fakeBookmarks) Update(_ context.Context, b bookmarks.Bookmark) (bookmarks.Bookmark, error) {
+	f.marks[b.ID] = b
+	return b, nil
+}
+
+func (f *fakeBookmarks) Delete(_ context.Context, id string) error {
+	delete(f.marks, id)
+	return nil
+}
+
+// byURL returns the bookmark for url.
+func (f *fakeBookmarks) byURL(url string) (bookmarks.Bookmark, bool) {
+	for _, b := range f.marks {
+		if b.URL == url {
+			return b, true
+		}
+	}
+	return bookmarks.Bookmark{}, false
+}
+
+func TestSyncBookmarks(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+	read := true
+
+	// On the list only; on the list and bookmarked; bookmarked only.
+	addItem := func(url, notes string) int64 {
+		t.Helper()
+		blogID, err := store.CreateCustomBlog(ctx, url, "Post "+url, "", "", "example.com")
+		if err != nil {
+			t.Fatalf("CreateCustomBlog: %v", err)
+		}
+		if err := store.AddToReadingList(ctx, blogID); err != nil {
+			t.Fatalf("AddToReadingList: %v", err)
+		}
+		id, _ := store.GetReadingListIDByBlogID(ctx, blogID)
+		if notes != "" {
+			store.UpdateReadingListNotes(ctx, id, notes)
+		}
+		return id
+	}
+	localOnly := addItem("https://a.example/local", "")
+	both := addItem("https://a.example/both", "My notes.")
+	svc := &fakeBookmarks{nextID: 100, marks: map[string]bookmarks.Bookmark{
+		"1": {ID: "1", URL: "https://a.example/both", Tags: []string{"go"}, Notes: "Their notes."},
+		"2": {ID: "2", URL: "https://a.example/remote", Title: "Remote", Tags: []string{"db"}, Notes: "Saved on my phone.", Read: &read},
+	}}
+
+	cfg := &config.Config{Bookmarks: config.BookmarksConfig{Conflict: "apricot"}}
+	runner := jobs.NewManager(store, 1)
+	runner.Register(BookmarksJob(store, svc, runner, nil, cfg))
+	sync := func() BookmarkSyncResult {
+		t.Helper()
+		w := httptest.NewRecorder()
+		SyncBookmarks(svc, runner).ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/admin/bookmarks/sync", nil))
+		if w.Code != http.StatusAccepted {
+			t.Fatalf("status %d, want %d; body: %s", w.Code, http.StatusAccepted, w.Body.String())
+		}
+		var job models.Job
+		json.NewDecoder(w.Body).Decode(&job)
+		if err := runner.Drain(ctx); err != nil {
+			t.Fatalf("Drain() error: %v", err)
+		}
+		done, err := runner.Get(ctx, job.ID)
+		if err != nil || done.Status != models.JobSucceeded {
+			t.Fatalf("bookmarks job = %+v, %v; want it to have succeeded", done, err)
+		}
+		var result BookmarkSyncResult
+		json.Unmarshal(done.Result, &result)
+		return result
+	}
+	item := func(id int64) *models.ReadingListItem {
+		t.Helper()
+		got, err := store.GetReadingListItemByID(ctx, id)
+		if err != nil {
+			t.Fatalf("GetReadingListItemByID(%d): %v", id, err)
+		}
+		return got
+	}
+
+	// The first sync imports, exports, and merges the pair.
+	got := sync()
+	if got.Imported != 1 || got.Exported != 1 || got.Pulled != 1 || got.Pushed != 1 {
+		t.Errorf("first sync = %+v, want 1 imported, exported, pulled, and pushed", got)
+	}
+	if b, ok := svc.byURL("https://a.example/local"); !ok || b.Title != "Post https://a.example/local" || b.Read == nil || *b.Read {
+		t.Errorf("bookmark of the local item = %+v, %v; want it created unread", b, ok)
+	}
+	if it := item(both); *it.Notes != "My notes." || !slices.Equal(it.Tags, []string{"go"}) {
+		t.Errorf("merged item = notes %q, tags %v; want its own notes and the bookmark's tag", *it.Notes, it.Tags)
+	}
+	if b := svc.marks["1"]; b.Notes != "My notes." || !slices.Equal(b.Tags, []string{"go"}) {
+		t.Errorf("merged bookmark = %+v, want the item's notes", b)
+	}
+	imported, err := store.GetBlogByURL(ctx, "https://a.example/remote")
+	if err != nil {
+		t.Fatalf("imported post: %v", err)
+	}
+	importedID, _ := store.GetReadingListIDByBlogID(ctx, imported.ID)
+	if it := item(importedID); it.Status != "read" || *it.Notes != "Saved on my phone." || !slices.Equal(it.Tags, []string{"db"}) {
+		t.Errorf("imported item = %+v, want it read, with the bookmark's notes and tags", it)
+	}
+
+	if got := sync(); got != (BookmarkSyncResult{Service: "fake"}) {
+		t.Errorf("sync with no changes = %+v, want nothing done", got)
+	}
+
+	// A change on one side is copied to the other; on both, Apricot wins.
+	b := svc.marks["2"]
+	b.Tags = []string{"db", "postgres"}
+	svc.marks["2"] = b
+	store.UpdateReadingListStatus(ctx, localOnly, "read")
+	store.AddTagToItem(ctx, both, "mine")
+	b = svc.marks["1"]
+	b.Notes = "Edited on my phone."
+	svc.marks["1"] = b
+
+	got = sync()
+	if got.Pulled != 1 || got.Pushed != 2 || got.Conflicts != 1 {
+		t.Errorf("sync after edits = %+v, want 1 pulled, 2 pushed, 1 conflict", got)
+	}
+	if it := item(importedID); !slices.Equal(it.Tags, []string{"db", "postgres"}) {
+		t.Errorf("tags = %v, want the bookmark's new tag pulled", it.Tags)
+	}
+	if b, _ := svc.byURL("https://a.example/local"); b.Read == nil || !*b.Read {
+		t.Errorf("bookmark = %+v, want it marked read", b)
+	}
+	if b := svc.marks["1"]; b.Notes != "My notes." || !slices.Contains(b.Tags, "mine") {
+		t.Errorf("conflicting bookmark = %+v, want Apricot's side", b)
+	}
+
+	// Deleting either side deletes the other.
+	if err := store.RemoveFromReadingList(ctx, localOnly); err != nil {
+		t.Fatalf("RemoveFromReadingList: %v", err)
+	}
+	delete(svc.marks, "2")
+	got = sync()
+	if got.Deleted != 1 || got.Removed != 1 {
+		t.Errorf("sync after deletes = %+v, want 1 deleted and 1 removed", got)
+	}
+	if _, ok := svc.byURL("https://a.example/local"); ok {
+		t.Error("bookmark of the removed item still exists")
+	}
+	if _, err := store.GetReadingListItemByID(ctx, importedID); err == nil {
+		t.Error("item of the deleted bookmark is still on the list")
+	}
+	links, _ := store.ListBookmarkLinks(ctx, "fake")
+	if len(links) != 1 || links[0].ReadingListID != both {
+		t.Errorf("links = %+v, want only the pair's", links)
+	}
+}
+
+func TestSyncBookmarks_NotConfigured(t *testing.T) {
+	w := httptest.NewRecorder()
+	SyncBookmarks(nil, nil).ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/admin/bookmarks/sync", nil))
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("status %d, want %d", w.Code, http.StatusServiceUnavailable)
+	}
+}
@@ -0,0 +1,205 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/hoanghai1803/apricot/internal/config"
+	"github.com/hoanghai1803/apricot/internal/email"
+	"github.com/hoanghai1803/apricot/internal/jobs"
+	"github.com/hoanghai1803/apricot/internal/models"
+	"github.com/hoanghai1803/apricot/internal/storage"
+)
+
+// digestLookback is how far back a digest looks when no earlier digest is
+// on record. Finished jobs are pruned after a week, so it is also the
+// furthest back any digest looks.
+const digestLookback = 7 * 24 * time.Hour
+
+// DigestResult is the result of a "digest" job.
+type DigestResult struct {
+	Posts      int       `json:"posts"`
+	Recipients int       `json:"recipients"`
+	Since      time.Time `json:"since"`
+}
+
+// SendDigest handles POST /api/admin/digest. It queues a "digest" job (see
+// DigestJob) and returns 202 Accepted with the job. mailer is nil when
+// email is not configured.
+func SendDigest(mailer *email.Mailer, runner *jobs.Manager) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if mailer == nil {
+			writeError(w, http.StatusServiceUnavailable, "Email is not configured. Add an [email] section to config.toml")
+			return
+		}
+
+		job, err := runner.Enqueue(r.Context(), "digest", struct{}{})
+		if err != nil {
+			slog.ErrorContext(r.Context(), "failed to queue digest", "error", err)
+			writeError(w, http.StatusInternalServerError, "Failed to start digest")
+			return
+		}
+
+		w.Header().Set("Location", fmt.Sprintf("/api/jobs/%d", job.ID))
+		writeJSON(w, http.StatusAccepted, job)
+	}
+}
+
+// DigestJob returns the "digest" job kind, which emails the posts picked by
+// discovery since the last digest to the configured recipients, at most
+// email.digest_top of them, newest run first. Nothing is sent if there are
+// no new posts. A failed send is retried twice.
+func DigestJob(store storage.Store, mailer *email.Mailer, cfg *config.Config) jobs.Kind {
+	return jobs.Kind{
+		Name:        "digest",
+		Timeout:     2 * time.Minute,
+		MaxAttempts: 3,
+		Backoff:     5 * time.Minute,
+		Handler: func(ctx context.Context, _ json.RawMessage) (any, error) {
+			now := time.Now()
+			since := now.Add(-digestLookback)
+			last, err := store.ListJobs(ctx, storage.JobFilter{Kind: "digest", Status: models.JobSucceeded, Limit: 1})
+			if err != nil {
+				return nil, fmt.Errorf("finding the last digest: %w", err)
+			}
+			if len(last) == 1 && last[0].FinishedAt != nil && last[0].FinishedAt.After(since) {
+				since = *last[0].FinishedAt
+			}
+
+			posts, err := digestPosts(ctx, store, since, cfg.Email.DigestTop)
+			if err != nil {
+				return nil, err
+			}
+			result := DigestResult{Posts: len(posts), Since: since.UTC()}
+			if len(posts) == 0 {
+				slog.InfoContext(ctx, "no new posts for the digest", "since", since)
+				return result, nil
+			}
+
+			msg, err := renderDigest(posts, now)
+			if err != nil {
+				return nil, jobs.Permanent(err)
+			}
+			msg.To = cfg.Email.To
+			if err := mailer.Send(ctx, msg); err != nil {
+				return nil, fmt.Errorf("sending digest: %w", err)
+			}
+			result.Recipients = len(msg.To)
+			slog.InfoContext(ctx, "sent digest", "posts", len(posts), "recipients", len(msg.To))
+			return result, nil
+		},
+	}
+}
+
+// digestPosts returns up to top posts picked by discovery sessions created
+// after since, newest session first, each post once.
+func digestPosts(ctx context.Context, store storage.Store, since time.Time, top int) ([]DiscoverResult, error) {
+	const page = 20
+	posts := []DiscoverResult{}
+	seen := make(map[string]bool)
+	for offset := 0; ; offset += page {
+		sessions, err := store.ListSessions(ctx, page, offset)
+		if err != nil {
+			return nil, fmt.Errorf("listing sessions: %w", err)
+		}
+		for i := range sessions {
+			if !sessions[i].CreatedAt.After(since) {
+				return posts, nil
+			}
+			results, _, err := decodeSession(&sessions[i])
+			if err != nil {
+				slog.WarnContext(ctx, "skipping unreadable session in digest", "session_id", sessions[i].ID, "error", err)
+				continue
+			}
+			for _, r := range results {
+				if seen[r.URL] {
+					continue
+				}
+				seen[r.URL] = true
+				posts = append(posts, r)
+				if len(posts) == top {
+					return posts, nil
+				}
+			}
+		}
+		if len(sessions) < page {
+			return posts, nil
+		}
+	}
+}
+
+// digestPost is a post as the digest templates show it.
+type digestPost struct {
+	Title, URL, Source, Summary string
+	Minutes                     int
+}
+
+var digestHTML = template.Must(template.New("digest").Parse(`<!DOCTYPE html>
+<html>
+<body style="margin:0;padding:24px;background:#fafaf9;font-family:-apple-system,'Segoe UI',Helvetica,Arial,sans-serif;color:#1c1917">
+<div style="max-width:600px;margin:0 auto">
+<h1 style="font-size:20px;margin:0 0 4px">{{.Heading}}</h1>
+<p style="margin:0 0 24px;color:#78716c;font-size:14px">{{.Date}}</p>
+{{range .Posts}}<div style="margin:0 0 24px">
+<a href="{{.URL}}" style="font-size:17px;font-weight:600;color:#c2410c;text-decoration:none">{{.Title}}</a>
+<p style="margin:4px 0;color:#78716c;font-size:13px">{{.Source}}{{if .Minutes}} · {{.Minutes}} min read{{end}}</p>
+{{if .Summary}}<p style="margin:8px 0 0;font-size:15px;line-height:1.5">{{.Summary}}</p>{{end}}
+</div>
+{{end}}<p style="margin:32px 0 0;color:#a8a29e;font-size:12px">Sent by Apricot.</p>
+</div>
+</body>
+</html>
+`))
+
+// renderDigest returns the digest email of posts, without recipients.
+func renderDigest(posts []DiscoverResult, now time.Time) (email.Message, error) {
+	data := struct {
+		Heading, Date string
+		Posts         []digestPost
+	}{
+		Heading: fmt.Sprintf("%d posts picked for you", len(posts)),
+		Date:    now.Format("Monday, 2 January 2006"),
+	}
+	if len(posts) == 1 {
+		data.Heading = "1 post picked for you"
+	}
+
+	var text strings.Builder
+	fmt.Fprintf(&text, "%s\n%s\n", data.Heading, data.Date)
+	for _, r := range posts {
+		p := digestPost{Title: r.Title, URL: r.URL, Source: r.Source, Summary: r.Summary}
+		if r.RewrittenTitle != "" {
+			p.Title = r.RewrittenTitle
+		}
+		if r.ReadingTimeMinutes != nil {
+			p.Minutes = *r.ReadingTimeMinutes
+		}
+		data.Posts = append(data.Posts, p)
+
+		fmt.Fprintf(&text, "\n%s\n%s", p.Title, p.Source)
+		if p.Minutes > 0 {
+			fmt.Fprintf(&text, " · %d min read", p.Minutes)
+		}
+		if p.Summary != "" {
+			fmt.Fprintf(&text, "\n%s", p.Summary)
+		}
+		fmt.Fprintf(&text, "\n%s\n", p.URL)
+	}
+
+	var html bytes.Buffer
+	if err := digestHTML.Execute(&html, data); err != nil {
+		return email.Message{}, fmt.Errorf("rendering digest: %w", err)
+	}
+	return email.Message{
+		Subject: "Apricot: " + data.Heading,
+		HTML:    html.String(),
+		Text:    text.String(),
+	}, nil
+}
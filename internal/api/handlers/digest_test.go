@@ -0,0 +1,71 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hoanghai1803/apricot/internal/models"
+)
+
+func TestDigestPosts(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+	for _, results := range []string{
+		`[{"title":"One","url":"https://a.example/1"},{"title":"Two","url":"https://a.example/2"}]`,
+		`[{"title":"Two","url":"https://a.example/2"},{"title":"Three","url":"https://a.example/3","rewritten_title":"Better Three","reading_time_minutes":4,"source":"A Blog","summary":"Why <three> wins."}]`,
+	} {
+		if _, err := store.CreateSession(ctx, &models.DiscoverySession{
+			PreferencesSnapshot: "{}",
+			BlogsSelected:       "[]",
+			ResultsJSON:         results,
+		}); err != nil {
+			t.Fatalf("CreateSession: %v", err)
+		}
+	}
+
+	posts, err := digestPosts(ctx, store, time.Now().Add(-time.Hour), 10)
+	if err != nil {
+		t.Fatalf("digestPosts() error: %v", err)
+	}
+	var titles []string
+	for _, p := range posts {
+		titles = append(titles, p.Title)
+	}
+	if got := strings.Join(titles, ","); got != "Two,Three,One" {
+		t.Errorf("posts = %s, want Two,Three,One (newest run first, each once)", got)
+	}
+	if posts, _ := digestPosts(ctx, store, time.Now().Add(-time.Hour), 2); len(posts) != 2 {
+		t.Errorf("got %d posts with top 2", len(posts))
+	}
+	if posts, _ := digestPosts(ctx, store, time.Now().Add(time.Hour), 10); len(posts) != 0 {
+		t.Errorf("got %d posts from before since, want none", len(posts))
+	}
+
+	msg, err := renderDigest(posts, time.Date(2026, 10, 12, 8, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("renderDigest() error: %v", err)
+	}
+	if msg.Subject != "Apricot: 3 posts picked for you" {
+		t.Errorf("Subject = %q", msg.Subject)
+	}
+	for _, want := range []string{`href="https://a.example/3"`, "Better Three", "A Blog · 4 min read", "Why &lt;three&gt; wins.", "Monday, 12 October 2026"} {
+		if !strings.Contains(msg.HTML, want) {
+			t.Errorf("HTML is missing %q", want)
+		}
+	}
+	if !strings.Contains(msg.Text, "Better Three\nA Blog · 4 min read\nWhy <three> wins.\nhttps://a.example/3\n") {
+		t.Errorf("Text = %q, want the post in plain text", msg.Text)
+	}
+}
+
+func TestSendDigest_NotConfigured(t *testing.T) {
+	w := httptest.NewRecorder()
+	SendDigest(nil, nil).ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/admin/digest", nil))
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("status %d, want %d", w.Code, http.StatusServiceUnavailable)
+	}
+}
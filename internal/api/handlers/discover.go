@@ -4,14 +4,21 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"io"
 	"log/slog"
 	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/hoanghai1803/apricot/internal/ai"
 	"github.com/hoanghai1803/apricot/internal/config"
 	"github.com/hoanghai1803/apricot/internal/feeds"
+	"github.com/hoanghai1803/apricot/internal/jobs"
 	"github.com/hoanghai1803/apricot/internal/models"
+	"github.com/hoanghai1803/apricot/internal/notify"
 	"github.com/hoanghai1803/apricot/internal/storage"
 )
 
@@ -24,376 +31,1227 @@ type DiscoverResult struct {
 	PublishedAt *string `json:"published_at,omitempty"`
 	Summary     string  `json:"summary"`
 	Reason      string  `json:"reason"`
+	Difficulty  string  `json:"difficulty,omitempty"`
+	Category    string  `json:"category,omitempty"`
+
+	RewrittenTitle     string `json:"rewritten_title,omitempty"`
+	ReadingTimeMinutes *int   `json:"reading_time_minutes,omitempty"`
 }
 
 // DiscoverResponse is the full response for discovery endpoints.
 type DiscoverResponse struct {
-	Results     []DiscoverResult `json:"results"`
+	Results     []DiscoverResult   `json:"results"`
 	FailedFeeds []feeds.FailedFeed `json:"failed_feeds"`
-	SessionID   int64            `json:"session_id"`
-	CreatedAt   string           `json:"created_at"`
+	SessionID   int64              `json:"session_id"`
+	CreatedAt   string             `json:"created_at"`
+
+	// Scheduled is set on the discovery.completed event of a run started
+	// by discover_schedule rather than by hand.
+	Scheduled bool `json:"scheduled,omitempty"`
+
+	// Partial is set when the run used up its time budget, so some feeds
+	// went unfetched or some ranked posts unsummarized.
+	Partial bool `json:"partial,omitempty"`
+
+	// Fallback is set when AI ranking failed and the posts were ranked by
+	// topic keywords and recency instead (see rankByKeywords).
+	Fallback bool `json:"fallback,omitempty"`
+}
+
+// DiscoverCandidate is a fetched post reported by a dry-run discovery.
+type DiscoverCandidate struct {
+	Title       string     `json:"title"`
+	URL         string     `json:"url"`
+	Source      string     `json:"source"`
+	PublishedAt *time.Time `json:"published_at,omitempty"`
+}
+
+// DryRunResponse is the response for a dry-run discovery. It lists the
+// candidates that would be sent to the AI and the estimated cost of doing so.
+type DryRunResponse struct {
+	DryRun      bool                `json:"dry_run"`
+	Candidates  []DiscoverCandidate `json:"candidates"`
+	FailedFeeds []feeds.FailedFeed  `json:"failed_feeds"`
+	Estimate    ai.Estimate         `json:"estimate"`
+	Partial     bool                `json:"partial,omitempty"` // some feeds were cut off by the time budget
 }
 
-// Discover handles POST /api/discover. It orchestrates the full discovery
-// pipeline: fetch feeds, rank with AI, extract full content, summarize, and
-// return the top results.
-func Discover(store *storage.Store, aiProvider ai.AIProvider, fetcher *feeds.Fetcher, cfg *config.Config) http.HandlerFunc {
+// Discover handles POST /api/discover. It checks the request, then queues
+// a "discover" job (see DiscoverJob) and returns 202 Accepted with the job;
+// poll GET /api/jobs/{id} for its result. With "dry_run" set (in the body or
+// as a query parameter) the job stops after fetching and returns the
+// candidate list with an estimated token count and cost, without calling
+// the AI or saving posts. A body that is not valid JSON gets 400 Bad
+// Request, so a mistyped dry run never becomes a billed one.
+//
+// With "since_last" set (in the body or as a query parameter) only posts
+// new since the latest session are considered (see newSince), which keeps
+// a daily run from paying to rank the same posts again.
+//
+// "max_reading_minutes" (in the body or as a query parameter, defaulting to
+// the preference of the same name) skips posts that take longer to read.
+// Posts whose length is already known are dropped before ranking, so they
+// don't take up result slots; the rest are checked once their content is
+// extracted.
+func Discover(store storage.Store, aiProvider ai.AIProvider, runner *jobs.Manager) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		ctx := r.Context()
 
 		// 1. Parse optional request body for mode.
 		var reqBody struct {
-			Mode string `json:"mode"` // "normal" (default) or "serendipity"
+			Mode       string `json:"mode"`       // "normal" (default) or "serendipity"
+			Difficulty string `json:"difficulty"` // optional: only keep posts at this level
+			DryRun     bool   `json:"dry_run"`    // fetch and estimate cost without calling the AI
+			SinceLast  bool   `json:"since_last"` // only consider posts new since the last session
+
+			MaxReadingMinutes int `json:"max_reading_minutes"` // optional: skip longer posts
 		}
 		if r.Body != nil {
-			if body, err := io.ReadAll(r.Body); err == nil && len(body) > 0 {
-				_ = json.Unmarshal(body, &reqBody)
+			body, err := io.ReadAll(r.Body)
+			if err != nil {
+				writeError(w, http.StatusBadRequest, "Failed to read request body")
+				return
+			}
+			if len(body) > 0 {
+				if err := json.Unmarshal(body, &reqBody); err != nil {
+					writeError(w, http.StatusBadRequest, "Invalid JSON body")
+					return
+				}
 			}
 		}
-		serendipity := reqBody.Mode == "serendipity"
+		dryRun := reqBody.DryRun || r.URL.Query().Get("dry_run") == "true"
+		sinceLast := reqBody.SinceLast || r.URL.Query().Get("since_last") == "true"
+
+		if reqBody.Difficulty != "" && !models.IsValidDifficulty(reqBody.Difficulty) {
+			writeError(w, http.StatusBadRequest, "difficulty must be one of intro, intermediate, deep-dive")
+			return
+		}
+
+		maxMinutes, err := maxReadingMinutes(ctx, store, r, reqBody.MaxReadingMinutes)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
 
-		// 2. Check if AI provider is configured.
-		if aiProvider == nil {
+		// 2. Check if AI provider is configured. A dry run never calls it.
+		if aiProvider == nil && !dryRun {
 			writeError(w, http.StatusServiceUnavailable,
 				"AI provider not configured. Add your API key to config.toml")
 			return
 		}
 
-		// 2. Load user preferences.
-		var topics string
-		if err := store.GetPreference(ctx, "topics", &topics); err != nil {
+		// 3. Check that there is something to discover. The job loads
+		// preferences and sources again when it runs.
+		if _, err := loadTopics(ctx, store); err != nil {
 			if errors.Is(err, storage.ErrNotFound) {
 				writeError(w, http.StatusBadRequest,
 					"No preferences set. Please set your interests first.")
 				return
 			}
-			slog.Error("failed to load preferences", "error", err)
+			slog.ErrorContext(ctx, "failed to load preferences", "error", err)
 			writeError(w, http.StatusInternalServerError, "Failed to load preferences")
 			return
 		}
-
-		// 3. Load max discovery results preference.
-		maxResults := 10
-		var maxResultsPref int
-		if err := store.GetPreference(ctx, "max_results", &maxResultsPref); err == nil {
-			if maxResultsPref >= 5 && maxResultsPref <= 20 {
-				maxResults = maxResultsPref
-			}
-		}
-
-		// 4. Load feed preferences for mode, max articles, and lookback days.
-		fetchOpts := buildFetchOptions(store, cfg, ctx)
-
-		// 5. Get active sources.
 		sources, err := store.GetActiveSources(ctx)
 		if err != nil {
-			slog.Error("failed to get sources", "error", err)
+			slog.ErrorContext(ctx, "failed to get sources", "error", err)
 			writeError(w, http.StatusInternalServerError, "Failed to get sources")
 			return
 		}
-
 		if len(sources) == 0 {
 			writeError(w, http.StatusBadRequest, "No active sources configured")
 			return
 		}
 
-		// 6. Fetch feeds.
-		slog.Info("fetching feeds", "sources", len(sources), "mode", fetchOpts.Mode)
-		fetchResult, err := fetcher.FetchAll(ctx, sources, fetchOpts)
+		// 4. Queue the run.
+		job, err := runner.Enqueue(ctx, "discover", discoverPayload{
+			Serendipity:       reqBody.Mode == "serendipity",
+			Difficulty:        reqBody.Difficulty,
+			DryRun:            dryRun,
+			MaxReadingMinutes: maxMinutes,
+			SinceLast:         sinceLast,
+		})
 		if err != nil {
-			slog.Error("failed to fetch feeds", "error", err)
-			writeError(w, http.StatusInternalServerError, "Failed to fetch feeds")
+			slog.ErrorContext(ctx, "failed to queue discovery", "error", err)
+			writeError(w, http.StatusInternalServerError, "Failed to start discovery")
 			return
 		}
 
-		blogs := fetchResult.Blogs
-		failedFeeds := fetchResult.Failed
+		w.Header().Set("Location", fmt.Sprintf("/api/jobs/%d", job.ID))
+		writeJSON(w, http.StatusAccepted, job)
+	}
+}
 
-		slog.Info("fetched blogs", "count", len(blogs), "failed", len(failedFeeds))
+// QueueDiscovery queues a discovery run with the options a plain press of
+// the discover button would use: normal mode, any difficulty, and the
+// max_reading_minutes preference. It is how scheduled runs start, and marks
+// the run as scheduled.
+func QueueDiscovery(ctx context.Context, store storage.Store, runner *jobs.Manager) (*models.Job, error) {
+	var maxMinutes int
+	if err := store.GetPreference(ctx, "max_reading_minutes", &maxMinutes); err != nil || maxMinutes < 0 {
+		maxMinutes = 0
+	}
+	return runner.Enqueue(ctx, "discover", discoverPayload{MaxReadingMinutes: maxMinutes, Scheduled: true})
+}
 
-		// Record source health for all sources.
-		failedNames := make(map[string]string, len(failedFeeds))
-		for _, ff := range failedFeeds {
-			failedNames[ff.Source] = ff.Error
-		}
-		for _, src := range sources {
-			if errMsg, failed := failedNames[src.Name]; failed {
-				_ = store.UpdateSourceHealth(ctx, src.Name, false, errMsg)
-			} else {
-				_ = store.UpdateSourceHealth(ctx, src.Name, true, "")
+// discoverPayload is the payload of a "discover" job.
+type discoverPayload struct {
+	Serendipity       bool   `json:"serendipity,omitempty"`
+	Difficulty        string `json:"difficulty,omitempty"`
+	DryRun            bool   `json:"dry_run,omitempty"`
+	MaxReadingMinutes int    `json:"max_reading_minutes,omitempty"`
+	Scheduled         bool   `json:"scheduled,omitempty"`
+	SinceLast         bool   `json:"since_last,omitempty"`
+
+	// RetryFailed is the ID of a session whose failed feeds to fetch
+	// again, instead of all active sources (see RetryFailedFeeds).
+	RetryFailed int64 `json:"retry_failed,omitempty"`
+}
+
+// DiscoverJob returns the "discover" job kind, which runs the discovery
+// pipeline (see runDiscovery) with the options queued by Discover, using
+// the preferences and sources current when it starts. A run is not retried:
+// it may already have spent tokens, and the user can simply start another.
+// A finished run emits discovery.completed with its DiscoverResponse,
+// marked if the run was scheduled.
+func DiscoverJob(store storage.Store, aiProvider ai.AIProvider, fetcher *feeds.Fetcher, cfg *config.Config, notifier *notify.Notifier) jobs.Kind {
+	return jobs.Kind{
+		Name:        "discover",
+		Timeout:     time.Duration(cfg.Server.DiscoveryTimeoutSeconds) * time.Second,
+		MaxAttempts: 1,
+		Handler: func(ctx context.Context, payload json.RawMessage) (any, error) {
+			var p discoverPayload
+			if err := json.Unmarshal(payload, &p); err != nil {
+				return nil, jobs.Permanent(fmt.Errorf("decoding payload: %w", err))
 			}
+			result, err := discover(ctx, store, aiProvider, fetcher, cfg, p)
+			if resp, ok := result.(DiscoverResponse); ok {
+				resp.Scheduled = p.Scheduled
+				notifier.Emit(ctx, notify.EventDiscoveryCompleted, resp)
+			}
+			return result, err
+		},
+	}
+}
+
+// RunDiscovery runs discovery at once, with the options QueueDiscovery
+// uses, and returns its results. It is how the discover command runs
+// without a server; no event is emitted.
+func RunDiscovery(ctx context.Context, store storage.Store, aiProvider ai.AIProvider, fetcher *feeds.Fetcher, cfg *config.Config) (DiscoverResponse, error) {
+	var maxMinutes int
+	if err := store.GetPreference(ctx, "max_reading_minutes", &maxMinutes); err != nil || maxMinutes < 0 {
+		maxMinutes = 0
+	}
+	result, err := discover(ctx, store, aiProvider, fetcher, cfg, discoverPayload{MaxReadingMinutes: maxMinutes})
+	if err != nil {
+		return DiscoverResponse{}, err
+	}
+	return result.(DiscoverResponse), nil
+}
+
+// discover checks that discovery can run, then runs it (see runDiscovery)
+// with the options in p, using the preferences and sources current now.
+func discover(ctx context.Context, store storage.Store, aiProvider ai.AIProvider, fetcher *feeds.Fetcher, cfg *config.Config, p discoverPayload) (any, error) {
+	if aiProvider == nil && !p.DryRun {
+		return nil, jobs.Permanent(errors.New("AI provider not configured. Add your API key to config.toml"))
+	}
+
+	topics, err := loadTopics(ctx, store)
+	if errors.Is(err, storage.ErrNotFound) {
+		return nil, jobs.Permanent(errors.New("No preferences set. Please set your interests first."))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("loading preferences: %w", err)
+	}
+	var sources []models.BlogSource
+	if p.RetryFailed != 0 {
+		sources, err = failedSources(ctx, store, p.RetryFailed)
+		if errors.Is(err, storage.ErrNotFound) || errors.Is(err, errNothingToRetry) {
+			return nil, jobs.Permanent(err)
 		}
+	} else {
+		sources, err = store.GetActiveSources(ctx)
+		if err == nil && len(sources) == 0 {
+			return nil, jobs.Permanent(errors.New("No active sources configured"))
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("getting sources: %w", err)
+	}
 
-		if len(blogs) == 0 {
-			resp := DiscoverResponse{
-				Results:     []DiscoverResult{},
-				FailedFeeds: ensureFailedFeeds(failedFeeds),
-			}
-			writeJSON(w, http.StatusOK, resp)
-			return
+	// With since_last, only posts new since the latest session count; the
+	// first run considers them all.
+	var since time.Time
+	if p.SinceLast {
+		latest, err := store.GetLatestSession(ctx)
+		if err != nil && !errors.Is(err, storage.ErrNotFound) {
+			return nil, fmt.Errorf("getting latest session: %w", err)
 		}
+		if err == nil {
+			since = latest.CreatedAt
+		}
+	}
 
-		// 7. Save fetched blogs to storage.
-		if err := store.SaveBlogs(ctx, blogs); err != nil {
-			slog.Error("failed to save blogs", "error", err)
-			writeError(w, http.StatusInternalServerError, "Failed to save blogs")
-			return
+	return runDiscovery(ctx, store, aiProvider, fetcher, cfg, discoveryRun{
+		topics:      topics,
+		sources:     sources,
+		serendipity: p.Serendipity,
+		difficulty:  p.Difficulty,
+		dryRun:      p.DryRun,
+		maxMinutes:  p.MaxReadingMinutes,
+		merge:       p.RetryFailed != 0,
+		since:       since,
+	})
+}
+
+// discoveryRun holds the checked options of a discovery run.
+type discoveryRun struct {
+	topics      string
+	sources     []models.BlogSource
+	serendipity bool
+	difficulty  string // only keep posts at this level, if set
+	dryRun      bool
+	maxMinutes  int       // skip longer posts, if > 0
+	merge       bool      // add the results to the latest session's (see mergeLatest)
+	since       time.Time // skip posts seen before, if set (see newSince)
+}
+
+// runDiscovery runs the discovery pipeline: fetch feeds, rank with AI,
+// extract full content, summarize, and record the session. It returns a
+// DiscoverResponse, or a DryRunResponse for a dry run.
+//
+// Fetching and enrichment stop at cfg.Server.DiscoveryBudget: feeds not
+// fetched by then are reported as failed, and only the posts enriched by
+// then are returned, with Partial set. Ranking always runs to completion,
+// bounded only by ctx, since without it there is nothing to return.
+func runDiscovery(ctx context.Context, store storage.Store, aiProvider ai.AIProvider, fetcher *feeds.Fetcher, cfg *config.Config, run discoveryRun) (any, error) {
+	budgetCtx := ctx
+	if budget := cfg.Server.DiscoveryBudget(); budget > 0 {
+		var cancel context.CancelFunc
+		budgetCtx, cancel = context.WithTimeout(ctx, budget)
+		defer cancel()
+	}
+
+	// 1. Load the max discovery results setting.
+	maxResults := LoadSettings(ctx, store, cfg).MaxResults
+
+	// Rewriting sensational titles is opt-in.
+	rewriteTitles := titleRewriteEnabled(ctx, store)
+
+	// 2. Load feed preferences for mode, max articles, and lookback days.
+	fetchOpts := buildFetchOptions(store, cfg, ctx)
+
+	// 3. Fetch feeds.
+	slog.InfoContext(ctx, "fetching feeds", "sources", len(run.sources), "mode", fetchOpts.Mode)
+	fetchResult, err := fetcher.FetchAll(budgetCtx, run.sources, fetchOpts)
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to fetch feeds", "error", err)
+		return nil, stageError(ctx, err, "fetching feeds", "Failed to fetch feeds")
+	}
+
+	blogs := fetchResult.Blogs
+	failedFeeds := fetchResult.Failed
+	partial := budgetCtx.Err() != nil
+
+	slog.InfoContext(ctx, "fetched blogs", "count", len(blogs), "failed", len(failedFeeds))
+	if partial {
+		slog.WarnContext(ctx, "discovery budget ran out while fetching feeds", "budget", cfg.Server.DiscoveryBudget())
+	}
+
+	// Record source health for all sources.
+	failedNames := make(map[string]string, len(failedFeeds))
+	for _, ff := range failedFeeds {
+		failedNames[ff.Source] = ff.Error
+	}
+	for _, src := range run.sources {
+		if errMsg, failed := failedNames[src.Name]; failed {
+			_ = store.UpdateSourceHealth(ctx, src.Name, false, errMsg)
+		} else {
+			_ = store.UpdateSourceHealth(ctx, src.Name, true, "")
 		}
+	}
+
+	if !run.since.IsZero() {
+		fetched := len(blogs)
+		blogs = newSince(ctx, store, blogs, run.since)
+		slog.InfoContext(ctx, "skipped posts seen before the last session",
+			"count", fetched-len(blogs), "since", run.since)
+	}
 
-		// 8. Convert to AI blog entries.
-		blogEntries := make([]ai.BlogEntry, len(blogs))
+	if run.dryRun {
+		rankLimit := maxResults
+		if run.difficulty != "" || run.maxMinutes > 0 {
+			rankLimit = maxResults * 2
+		}
+		candidates := make([]DiscoverCandidate, len(blogs))
 		for i, b := range blogs {
-			var publishedAt string
-			if b.PublishedAt != nil {
-				publishedAt = b.PublishedAt.Format("2006-01-02")
-			}
-			blogEntries[i] = ai.BlogEntry{
-				ID:          b.ID,
+			candidates[i] = DiscoverCandidate{
 				Title:       b.Title,
+				URL:         b.URL,
 				Source:      b.Source,
-				PublishedAt: publishedAt,
-				Description: b.Description,
-				FullContent: b.FullContent,
+				PublishedAt: b.PublishedAt,
 			}
 		}
+		return DryRunResponse{
+			DryRun:      true,
+			Candidates:  candidates,
+			FailedFeeds: ensureFailedFeeds(failedFeeds),
+			Estimate:    ai.EstimateDiscovery(cfg.AI.Model, run.topics, toBlogEntries(blogs), rankLimit, run.serendipity),
+			Partial:     partial,
+		}, nil
+	}
 
-		// We need to look up blogs by URL to get their stored IDs, since
-		// SaveBlogs does upserts and we need the database IDs for ranking.
-		blogByURL := make(map[string]*models.Blog, len(blogs))
-		for i := range blogs {
-			blogByURL[blogs[i].URL] = &blogs[i]
+	if len(blogs) == 0 {
+		if run.merge {
+			return recordDiscovery(ctx, store, cfg, run, []DiscoverResult{}, failedFeeds, 0, partial, false), nil
 		}
+		return DiscoverResponse{
+			Results:     []DiscoverResult{},
+			FailedFeeds: ensureFailedFeeds(failedFeeds),
+			Partial:     partial,
+		}, nil
+	}
+
+	// 4. Save fetched blogs to storage.
+	if err := store.SaveBlogs(ctx, blogs); err != nil {
+		slog.ErrorContext(ctx, "failed to save blogs", "error", err)
+		return nil, stageError(ctx, err, "saving posts", "Failed to save blogs")
+	}
+
+	// 5. Convert to AI blog entries.
+	blogEntries := toBlogEntries(blogs)
 
-		// Refresh blog entries with stored IDs.
-		for i, entry := range blogEntries {
-			if entry.ID == 0 {
-				// Look up the stored blog by URL to get the real ID.
-				if b, ok := blogByURL[blogs[i].URL]; ok {
-					stored, err := store.GetBlogByURL(ctx, b.URL)
-					if err == nil {
-						blogEntries[i].ID = stored.ID
+	// We need to look up blogs by URL to get their stored IDs, since
+	// SaveBlogs does upserts and we need the database IDs for ranking.
+	blogByURL := make(map[string]*models.Blog, len(blogs))
+	for i := range blogs {
+		blogByURL[blogs[i].URL] = &blogs[i]
+	}
+
+	// Refresh blog entries with stored IDs, dropping posts already known
+	// to be too long to read so they don't compete for ranking slots.
+	candidates := blogEntries[:0]
+	tooLong := 0
+	for i, entry := range blogEntries {
+		if entry.ID == 0 {
+			// Look up the stored blog by URL to get the real ID.
+			if b, ok := blogByURL[blogs[i].URL]; ok {
+				stored, err := store.GetBlogByURL(ctx, b.URL)
+				if err == nil {
+					entry.ID = stored.ID
+					if run.maxMinutes > 0 && knownReadingTime(b, stored) > run.maxMinutes {
+						tooLong++
+						continue
 					}
 				}
 			}
 		}
+		candidates = append(candidates, entry)
+	}
+	blogEntries = candidates
+	if tooLong > 0 {
+		slog.InfoContext(ctx, "skipped long posts before ranking", "count", tooLong, "max_reading_minutes", run.maxMinutes)
+	}
+
+	if len(blogEntries) == 0 {
+		if run.merge {
+			return recordDiscovery(ctx, store, cfg, run, []DiscoverResult{}, failedFeeds, 0, partial, false), nil
+		}
+		return DiscoverResponse{
+			Results:     []DiscoverResult{},
+			FailedFeeds: ensureFailedFeeds(failedFeeds),
+			Partial:     partial,
+		}, nil
+	}
+
+	// 6. Filter and rank with AI. When filtering by difficulty or length,
+	// rank extra candidates so that dropping mismatches still fills the
+	// result slots.
+	rankLimit := maxResults
+	if run.difficulty != "" || run.maxMinutes > 0 {
+		rankLimit = maxResults * 2
+	}
+
+	slog.InfoContext(ctx, "ranking blogs with AI", "entries", len(blogEntries))
+	// If the AI fails, rank by topic keywords and recency rather than
+	// throw the fetched posts away, unless the deadline has passed.
+	ranked, err := aiProvider.FilterAndRank(ctx, run.topics, blogEntries, rankLimit, run.serendipity)
+	fallback := false
+	if err != nil {
+		if ctx.Err() != nil {
+			slog.ErrorContext(ctx, "failed to rank blogs", "error", err)
+			return nil, stageError(ctx, err, "ranking posts with AI", "Failed to rank blogs with AI")
+		}
+		slog.WarnContext(ctx, "failed to rank blogs with AI; ranking by topic keywords", "error", err)
+		ranked = rankByKeywords(blogEntries, topicKeywords(ctx, store), rankLimit, time.Now())
+		fallback = true
+	}
 
-		// 9. Filter and rank with AI.
-		slog.Info("ranking blogs with AI", "entries", len(blogEntries))
-		ranked, err := aiProvider.FilterAndRank(ctx, topics, blogEntries, maxResults, serendipity)
+	// Limit to configured max.
+	if len(ranked) > rankLimit {
+		ranked = ranked[:rankLimit]
+	}
+
+	// Optionally let the user's history with each source nudge the order.
+	if sourceWeightingEnabled(ctx, store) {
+		scores, err := store.GetSourceScores(ctx, scoreWindowStart(defaultScoreWindowDays))
 		if err != nil {
-			slog.Error("failed to rank blogs", "error", err)
-			writeError(w, http.StatusInternalServerError, "Failed to rank blogs with AI")
-			return
+			slog.WarnContext(ctx, "failed to load source scores", "error", err)
+		} else {
+			scoreByName := make(map[string]float64, len(scores))
+			for _, sc := range scores {
+				scoreByName[sc.Name] = sc.Score
+			}
+			sourceOf := make(map[int64]string, len(blogEntries))
+			for _, e := range blogEntries {
+				sourceOf[e.ID] = e.Source
+			}
+			ranked = weightBySourceScore(ranked, sourceOf, scoreByName)
 		}
+	}
+
+	slog.InfoContext(ctx, "ranked blogs", "count", len(ranked))
+
+	// 7. Enrich each ranked blog: extract full content if missing,
+	// summarize. Past the budget, stop with the results so far.
+	results := make([]DiscoverResult, 0, len(ranked))
 
-		// Limit to configured max.
-		if len(ranked) > maxResults {
-			ranked = ranked[:maxResults]
+	for _, rb := range ranked {
+		if len(results) >= maxResults {
+			break
+		}
+		if budgetCtx.Err() != nil {
+			partial = true
+			break
 		}
 
-		slog.Info("ranked blogs", "count", len(ranked))
+		blog, err := store.GetBlogByID(ctx, rb.ID)
+		if err != nil {
+			slog.WarnContext(ctx, "ranked blog not found in storage", "id", rb.ID, "error", err)
+			continue
+		}
 
-		// 10. Enrich each ranked blog: extract full content if missing, summarize.
-		results := make([]DiscoverResult, 0, len(ranked))
-		selectedIDs := make([]int64, 0, len(ranked))
+		// Extract full content if missing.
+		extractContent(budgetCtx, store, fetcher, blog)
+		ensureReadingTime(ctx, store, blog)
+		if run.maxMinutes > 0 && blog.ReadingTimeMinutes != nil && *blog.ReadingTimeMinutes > run.maxMinutes {
+			continue
+		}
 
-		for _, rb := range ranked {
-			blog, err := store.GetBlogByID(ctx, rb.ID)
-			if err != nil {
-				slog.Warn("ranked blog not found in storage", "id", rb.ID, "error", err)
+		// When filtering by difficulty, classify up front so mismatches
+		// are dropped before spending tokens on a summary. Otherwise the
+		// level comes with the summary below.
+		if run.difficulty != "" {
+			classifyDifficulty(budgetCtx, store, aiProvider, blog)
+			if blog.Difficulty != run.difficulty {
 				continue
 			}
+		}
+		if rewriteTitles {
+			rewriteTitle(budgetCtx, store, aiProvider, blog)
+		}
 
-			// Extract full content if missing.
-			if blog.FullContent == "" {
-				slog.Info("extracting article", "url", blog.URL)
-				content, err := fetcher.ExtractArticle(ctx, blog.URL)
-				if err != nil {
-					slog.Warn("failed to extract article", "url", blog.URL, "error", err)
-				} else {
-					blog.FullContent = content
-					if _, err := store.UpsertBlog(ctx, blog); err != nil {
-						slog.Warn("failed to update blog content", "id", blog.ID, "error", err)
-					}
-				}
-			}
+		// 8. Summarize if not cached.
+		summary := ensureSummary(budgetCtx, store, aiProvider, cfg.AI.Model, blog)
 
-			// 11. Summarize if not cached.
-			var summary string
-			hasSummary, err := store.HasSummary(ctx, blog.ID)
-			if err != nil {
-				slog.Warn("failed to check summary cache", "id", blog.ID, "error", err)
-			}
+		// Adopt the level produced with the summary, falling back to a
+		// standalone classification for summaries that predate it.
+		adoptDifficulty(ctx, store, blog, summary.Difficulty)
+		classifyDifficulty(budgetCtx, store, aiProvider, blog)
 
-			if hasSummary {
-				cached, err := store.GetSummaryByBlogID(ctx, blog.ID)
-				if err == nil {
-					summary = cached.Summary
-				}
-			} else {
-				slog.Info("summarizing blog", "id", blog.ID, "title", blog.Title)
-				var publishedAt string
-				if blog.PublishedAt != nil {
-					publishedAt = blog.PublishedAt.Format("2006-01-02")
-				}
-				entry := ai.BlogEntry{
-					ID:          blog.ID,
-					Title:       blog.Title,
-					Source:      blog.Source,
-					PublishedAt: publishedAt,
-					Description: blog.Description,
-					FullContent: blog.FullContent,
-				}
-				aiSummary, err := aiProvider.Summarize(ctx, entry)
-				if err != nil {
-					slog.Warn("failed to summarize blog", "id", blog.ID, "error", err)
-					aiSummary = blog.Description // fallback to description
-				}
-				summary = aiSummary
-
-				// Cache the summary.
-				if err := store.UpsertSummary(ctx, &models.BlogSummary{
-					BlogID:    blog.ID,
-					Summary:   summary,
-					ModelUsed: cfg.AI.Model,
-				}); err != nil {
-					slog.Warn("failed to cache summary", "id", blog.ID, "error", err)
+		// A post whose enrichment the budget cut short is left out rather
+		// than shown with a fallback summary.
+		if budgetCtx.Err() != nil {
+			partial = true
+			break
+		}
+
+		// 9. Build result.
+		var pubAt *string
+		if blog.PublishedAt != nil {
+			v := blog.PublishedAt.Format("2006-01-02T15:04:05Z")
+			pubAt = &v
+		}
+
+		results = append(results, DiscoverResult{
+			ID:          blog.ID,
+			Title:       blog.Title,
+			URL:         blog.URL,
+			Source:      blog.Source,
+			PublishedAt: pubAt,
+			Summary:     summary.Summary,
+			Reason:      rb.Reason,
+			Difficulty:  blog.Difficulty,
+			Category:    summary.Category,
+
+			RewrittenTitle:     blog.RewrittenTitle,
+			ReadingTimeMinutes: blog.ReadingTimeMinutes,
+		})
+	}
+
+	// Enrichment failures are soft, so check whether the hard deadline
+	// cut it short rather than return half-summarized results.
+	if err := ctx.Err(); err != nil {
+		slog.ErrorContext(ctx, "discovery ran out of time", "error", err)
+		return nil, stageError(ctx, err, "summarizing posts", "Discovery was cancelled")
+	}
+
+	if partial {
+		slog.WarnContext(ctx, "discovery budget ran out; returning partial results",
+			"budget", cfg.Server.DiscoveryBudget(), "results", len(results))
+	}
+
+	// 10. Record the session and return the results.
+	return recordDiscovery(ctx, store, cfg, run, results, failedFeeds, len(blogEntries), partial, fallback), nil
+}
+
+// recordDiscovery records the results of a discovery run as a session and
+// returns them. A retry of failed feeds first merges them with the latest
+// session's (see mergeLatest).
+func recordDiscovery(ctx context.Context, store storage.Store, cfg *config.Config, run discoveryRun,
+	results []DiscoverResult, failedFeeds []feeds.FailedFeed, considered int, partial, fallback bool) DiscoverResponse {
+	if run.merge {
+		var latestFallback bool
+		results, failedFeeds, latestFallback = mergeLatest(ctx, store, run.sources, results, failedFeeds)
+		fallback = fallback || latestFallback
+	}
+
+	selectedIDs := make([]int64, len(results))
+	for i, r := range results {
+		selectedIDs[i] = r.ID
+	}
+	selectedJSON, _ := json.Marshal(selectedIDs)
+	resultsJSON, _ := json.Marshal(results)
+	failedFeedsJSON, _ := json.Marshal(ensureFailedFeeds(failedFeeds))
+
+	session := &models.DiscoverySession{
+		PreferencesSnapshot: run.topics,
+		BlogsConsidered:     considered,
+		BlogsSelected:       string(selectedJSON),
+		ModelUsed:           cfg.AI.Model,
+		ResultsJSON:         string(resultsJSON),
+		FailedFeedsJSON:     string(failedFeedsJSON),
+		Fallback:            fallback,
+	}
+	sessionID, err := store.CreateSession(ctx, session)
+	if err != nil {
+		slog.WarnContext(ctx, "failed to create discovery session", "error", err)
+	}
+
+	return DiscoverResponse{
+		Results:     results,
+		FailedFeeds: ensureFailedFeeds(failedFeeds),
+		SessionID:   sessionID,
+		CreatedAt:   session.CreatedAt.Format("2006-01-02T15:04:05Z"),
+		Partial:     partial,
+		Fallback:    fallback,
+	}
+}
+
+// GetLatestDiscovery handles GET /api/discover/latest. It returns the most
+// recent discovery session's stored results without triggering a new discovery.
+// The optional "difficulty" query parameter filters the stored results; a
+// level that does not exist gets 400 Bad Request.
+func GetLatestDiscovery(store storage.Store) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		difficulty := r.URL.Query().Get("difficulty")
+		if difficulty != "" && !models.IsValidDifficulty(difficulty) {
+			writeError(w, http.StatusBadRequest, "difficulty must be one of intro, intermediate, deep-dive")
+			return
+		}
+
+		resp, err := LatestDiscovery(ctx, store)
+		if err != nil {
+			slog.ErrorContext(ctx, "failed to load latest discovery", "error", err)
+			writeError(w, http.StatusInternalServerError, "Failed to load latest discovery")
+			return
+		}
+		if difficulty != "" {
+			filtered := make([]DiscoverResult, 0, len(resp.Results))
+			for _, res := range resp.Results {
+				if res.Difficulty == difficulty {
+					filtered = append(filtered, res)
 				}
 			}
+			resp.Results = filtered
+		}
 
-			// 12. Build result.
-			var pubAt *string
-			if blog.PublishedAt != nil {
-				v := blog.PublishedAt.Format("2006-01-02T15:04:05Z")
-				pubAt = &v
-			}
+		writeJSON(w, http.StatusOK, resp)
+	}
+}
+
+// LatestDiscovery returns the results of the latest discovery session, or
+// none if discovery has never run. The tui command shows them this way.
+func LatestDiscovery(ctx context.Context, store storage.Store) (DiscoverResponse, error) {
+	session, err := store.GetLatestSession(ctx)
+	if errors.Is(err, storage.ErrNotFound) {
+		return DiscoverResponse{
+			Results:     []DiscoverResult{},
+			FailedFeeds: []feeds.FailedFeed{},
+		}, nil
+	}
+	if err != nil {
+		return DiscoverResponse{}, fmt.Errorf("getting latest session: %w", err)
+	}
+
+	results, failedFeeds, err := decodeSession(session)
+	if err != nil {
+		return DiscoverResponse{}, fmt.Errorf("decoding session %d results: %w", session.ID, err)
+	}
+	return DiscoverResponse{
+		Results:     results,
+		FailedFeeds: failedFeeds,
+		SessionID:   session.ID,
+		CreatedAt:   session.CreatedAt.Format("2006-01-02T15:04:05Z"),
+		Fallback:    session.Fallback,
+	}, nil
+}
+
+// DiscoverySessionSummary describes a past discovery run in the session
+// history, without its results.
+type DiscoverySessionSummary struct {
+	ID              int64  `json:"id"`
+	CreatedAt       string `json:"created_at"`
+	ModelUsed       string `json:"model_used"`
+	InputTokens     *int   `json:"input_tokens,omitempty"`
+	OutputTokens    *int   `json:"output_tokens,omitempty"`
+	BlogsConsidered int    `json:"blogs_considered"`
+	ResultCount     int    `json:"result_count"`
+	FailedFeedCount int    `json:"failed_feed_count"`
+	Fallback        bool   `json:"fallback,omitempty"` // ranked without AI
+}
+
+// DiscoverySessionPage is a page of the discovery session history.
+type DiscoverySessionPage struct {
+	Sessions []DiscoverySessionSummary `json:"sessions"`
+	Total    int                       `json:"total"`
+	Limit    int                       `json:"limit"`
+	Offset   int                       `json:"offset"`
+}
+
+// DiscoverySessionDetail is a past discovery run with its stored results.
+type DiscoverySessionDetail struct {
+	DiscoverResponse
+	ModelUsed       string `json:"model_used"`
+	InputTokens     *int   `json:"input_tokens,omitempty"`
+	OutputTokens    *int   `json:"output_tokens,omitempty"`
+	BlogsConsidered int    `json:"blogs_considered"`
+}
 
-			results = append(results, DiscoverResult{
-				ID:          blog.ID,
-				Title:       blog.Title,
-				URL:         blog.URL,
-				Source:      blog.Source,
-				PublishedAt: pubAt,
-				Summary:     summary,
-				Reason:      rb.Reason,
-			})
-
-			selectedIDs = append(selectedIDs, blog.ID)
-		}
-
-		// 13. Create audit session with full results.
-		selectedJSON, _ := json.Marshal(selectedIDs)
-		resultsJSON, _ := json.Marshal(results)
-		failedFeedsJSON, _ := json.Marshal(ensureFailedFeeds(failedFeeds))
-
-		session := &models.DiscoverySession{
-			PreferencesSnapshot: topics,
-			BlogsConsidered:     len(blogEntries),
-			BlogsSelected:       string(selectedJSON),
-			ModelUsed:            cfg.AI.Model,
-			ResultsJSON:         string(resultsJSON),
-			FailedFeedsJSON:     string(failedFeedsJSON),
-		}
-		sessionID, err := store.CreateSession(ctx, session)
+// ListDiscoverySessions handles GET /api/discover/sessions. It returns a page
+// of past discovery runs, newest first, with their result and failed feed
+// counts. The limit defaults to 20.
+func ListDiscoverySessions(store storage.Store) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		limit, offset, err := parsePage(r, 20)
 		if err != nil {
-			slog.Warn("failed to create discovery session", "error", err)
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
 		}
 
-		// 14. Return response.
-		resp := DiscoverResponse{
-			Results:     results,
-			FailedFeeds: ensureFailedFeeds(failedFeeds),
-			SessionID:   sessionID,
-			CreatedAt:   session.CreatedAt.Format("2006-01-02T15:04:05Z"),
+		sessions, err := store.ListSessions(ctx, limit, offset)
+		if err != nil {
+			slog.ErrorContext(ctx, "failed to list discovery sessions", "error", err)
+			writeError(w, http.StatusInternalServerError, "Failed to load discovery history")
+			return
+		}
+		total, err := store.CountSessions(ctx)
+		if err != nil {
+			slog.ErrorContext(ctx, "failed to count discovery sessions", "error", err)
+			writeError(w, http.StatusInternalServerError, "Failed to load discovery history")
+			return
 		}
 
-		writeJSON(w, http.StatusOK, resp)
+		page := DiscoverySessionPage{
+			Sessions: make([]DiscoverySessionSummary, 0, len(sessions)),
+			Total:    total,
+			Limit:    limit,
+			Offset:   offset,
+		}
+		for i := range sessions {
+			session := &sessions[i]
+			results, failedFeeds, err := decodeSession(session)
+			if err != nil {
+				slog.WarnContext(ctx, "failed to unmarshal session results", "session_id", session.ID, "error", err)
+			}
+			page.Sessions = append(page.Sessions, summarizeSession(session, len(results), len(failedFeeds)))
+		}
+
+		writeJSON(w, http.StatusOK, page)
 	}
 }
 
-// GetLatestDiscovery handles GET /api/discover/latest. It returns the most
-// recent discovery session's stored results without triggering a new discovery.
-func GetLatestDiscovery(store *storage.Store) http.HandlerFunc {
+// GetDiscoverySession handles GET /api/discover/sessions/{id}. It returns a
+// past discovery run's stored results and failed feeds with the model used
+// and its token counts.
+func GetDiscoverySession(store storage.Store) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		ctx := r.Context()
 
-		session, err := store.GetLatestSession(ctx)
+		id, err := parseID(r, "id")
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "Invalid session ID")
+			return
+		}
+
+		session, err := store.GetSession(ctx, id)
 		if err != nil {
 			if errors.Is(err, storage.ErrNotFound) {
-				writeJSON(w, http.StatusOK, DiscoverResponse{
-					Results:     []DiscoverResult{},
-					FailedFeeds: []feeds.FailedFeed{},
-				})
+				writeError(w, http.StatusNotFound, "Discovery session not found")
 				return
 			}
-			slog.Error("failed to get latest session", "error", err)
-			writeError(w, http.StatusInternalServerError, "Failed to load latest discovery")
+			slog.ErrorContext(ctx, "failed to get discovery session", "id", id, "error", err)
+			writeError(w, http.StatusInternalServerError, "Failed to load discovery session")
 			return
 		}
 
-		var results []DiscoverResult
-		if session.ResultsJSON != "" {
-			if err := json.Unmarshal([]byte(session.ResultsJSON), &results); err != nil {
-				slog.Error("failed to unmarshal session results", "error", err)
-				writeError(w, http.StatusInternalServerError, "Failed to parse stored results")
+		results, failedFeeds, err := decodeSession(session)
+		if err != nil {
+			slog.ErrorContext(ctx, "failed to unmarshal session results", "id", id, "error", err)
+			writeError(w, http.StatusInternalServerError, "Failed to parse stored results")
+			return
+		}
+
+		writeJSON(w, http.StatusOK, DiscoverySessionDetail{
+			DiscoverResponse: DiscoverResponse{
+				Results:     results,
+				FailedFeeds: failedFeeds,
+				SessionID:   session.ID,
+				CreatedAt:   session.CreatedAt.Format("2006-01-02T15:04:05Z"),
+				Fallback:    session.Fallback,
+			},
+			ModelUsed:       session.ModelUsed,
+			InputTokens:     session.InputTokens,
+			OutputTokens:    session.OutputTokens,
+			BlogsConsidered: session.BlogsConsidered,
+		})
+	}
+}
+
+// SessionComparison is the difference between two discovery runs: the
+// articles the later run found that the earlier one did not, the ones it
+// no longer returned, and how the topics they were ranked against changed.
+type SessionComparison struct {
+	A           DiscoverySessionSummary `json:"a"`
+	B           DiscoverySessionSummary `json:"b"`
+	New         []DiscoverResult        `json:"new"`
+	Dropped     []DiscoverResult        `json:"dropped"`
+	Kept        []DiscoverResult        `json:"kept"`
+	Preferences PreferencesDiff         `json:"preferences"`
+}
+
+// PreferencesDiff compares the preference snapshots of two discovery runs
+// line by line. A topic whose importance changed shows up as removed with
+// its old importance and added with its new one.
+type PreferencesDiff struct {
+	Changed bool     `json:"changed"`
+	Added   []string `json:"added"`
+	Removed []string `json:"removed"`
+}
+
+// CompareDiscoverySessions handles GET /api/discover/sessions/compare?a=&b=.
+// It reports the articles session b returned that a did not (new), those a
+// returned that b did not (dropped), those both returned (kept, in b's
+// order), and the lines added to or removed from the preference snapshot.
+func CompareDiscoverySessions(store storage.Store) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		var sessions [2]*models.DiscoverySession
+		for i, param := range []string{"a", "b"} {
+			id, err := strconv.ParseInt(r.URL.Query().Get(param), 10, 64)
+			if err != nil || id <= 0 {
+				writeError(w, http.StatusBadRequest, fmt.Sprintf("%s must be a session ID", param))
 				return
 			}
+			session, err := store.GetSession(ctx, id)
+			if err != nil {
+				if errors.Is(err, storage.ErrNotFound) {
+					writeError(w, http.StatusNotFound, fmt.Sprintf("Discovery session %d not found", id))
+					return
+				}
+				slog.ErrorContext(ctx, "failed to get discovery session", "id", id, "error", err)
+				writeError(w, http.StatusInternalServerError, "Failed to load discovery session")
+				return
+			}
+			sessions[i] = session
 		}
-		if results == nil {
-			results = []DiscoverResult{}
+
+		cmp, err := compareSessions(sessions[0], sessions[1])
+		if err != nil {
+			slog.ErrorContext(ctx, "failed to unmarshal session results", "error", err)
+			writeError(w, http.StatusInternalServerError, "Failed to parse stored results")
+			return
 		}
+		writeJSON(w, http.StatusOK, cmp)
+	}
+}
 
-		var failedFeeds []feeds.FailedFeed
-		if session.FailedFeedsJSON != "" {
-			if err := json.Unmarshal([]byte(session.FailedFeedsJSON), &failedFeeds); err != nil {
-				slog.Warn("failed to unmarshal failed feeds", "error", err)
+// compareSessions diffs the results and preference snapshots of sessions a
+// and b. Articles are matched by blog ID.
+func compareSessions(a, b *models.DiscoverySession) (*SessionComparison, error) {
+	resultsA, failedA, err := decodeSession(a)
+	if err != nil {
+		return nil, fmt.Errorf("session %d: %w", a.ID, err)
+	}
+	resultsB, failedB, err := decodeSession(b)
+	if err != nil {
+		return nil, fmt.Errorf("session %d: %w", b.ID, err)
+	}
+
+	cmp := &SessionComparison{
+		A:       summarizeSession(a, len(resultsA), len(failedA)),
+		B:       summarizeSession(b, len(resultsB), len(failedB)),
+		New:     []DiscoverResult{},
+		Dropped: []DiscoverResult{},
+		Kept:    []DiscoverResult{},
+	}
+
+	inA := make(map[int64]bool, len(resultsA))
+	for _, res := range resultsA {
+		inA[res.ID] = true
+	}
+	inB := make(map[int64]bool, len(resultsB))
+	for _, res := range resultsB {
+		inB[res.ID] = true
+		if inA[res.ID] {
+			cmp.Kept = append(cmp.Kept, res)
+		} else {
+			cmp.New = append(cmp.New, res)
+		}
+	}
+	for _, res := range resultsA {
+		if !inB[res.ID] {
+			cmp.Dropped = append(cmp.Dropped, res)
+		}
+	}
+
+	cmp.Preferences = diffLines(a.PreferencesSnapshot, b.PreferencesSnapshot)
+	return cmp, nil
+}
+
+// diffLines reports the non-blank lines of b missing from a (added) and of
+// a missing from b (removed), each in its original order.
+func diffLines(a, b string) PreferencesDiff {
+	split := func(s string) []string {
+		var lines []string
+		for _, line := range strings.Split(s, "\n") {
+			if line = strings.TrimSpace(line); line != "" {
+				lines = append(lines, line)
 			}
 		}
-		if failedFeeds == nil {
-			failedFeeds = []feeds.FailedFeed{}
+		return lines
+	}
+	linesA, linesB := split(a), split(b)
+
+	diff := PreferencesDiff{Added: []string{}, Removed: []string{}}
+	for _, line := range linesB {
+		if !slices.Contains(linesA, line) {
+			diff.Added = append(diff.Added, line)
 		}
+	}
+	for _, line := range linesA {
+		if !slices.Contains(linesB, line) {
+			diff.Removed = append(diff.Removed, line)
+		}
+	}
+	diff.Changed = len(diff.Added) > 0 || len(diff.Removed) > 0
+	return diff
+}
 
-		resp := DiscoverResponse{
-			Results:     results,
-			FailedFeeds: failedFeeds,
-			SessionID:   session.ID,
-			CreatedAt:   session.CreatedAt.Format("2006-01-02T15:04:05Z"),
+// summarizeSession describes session for the session history.
+func summarizeSession(session *models.DiscoverySession, results, failedFeeds int) DiscoverySessionSummary {
+	return DiscoverySessionSummary{
+		ID:              session.ID,
+		CreatedAt:       session.CreatedAt.Format("2006-01-02T15:04:05Z"),
+		ModelUsed:       session.ModelUsed,
+		InputTokens:     session.InputTokens,
+		OutputTokens:    session.OutputTokens,
+		BlogsConsidered: session.BlogsConsidered,
+		ResultCount:     results,
+		FailedFeedCount: failedFeeds,
+		Fallback:        session.Fallback,
+	}
+}
+
+// decodeSession unmarshals a session's stored results and failed feeds,
+// returning empty slices rather than nil. Unreadable failed feeds are logged
+// and dropped, since they are informational; unreadable results are an error.
+func decodeSession(session *models.DiscoverySession) ([]DiscoverResult, []feeds.FailedFeed, error) {
+	results := []DiscoverResult{}
+	if session.ResultsJSON != "" {
+		if err := json.Unmarshal([]byte(session.ResultsJSON), &results); err != nil {
+			return []DiscoverResult{}, []feeds.FailedFeed{}, err
 		}
+		if results == nil {
+			results = []DiscoverResult{}
+		}
+	}
 
-		writeJSON(w, http.StatusOK, resp)
+	var failedFeeds []feeds.FailedFeed
+	if session.FailedFeedsJSON != "" {
+		if err := json.Unmarshal([]byte(session.FailedFeedsJSON), &failedFeeds); err != nil {
+			slog.Warn("failed to unmarshal failed feeds", "session_id", session.ID, "error", err)
+		}
 	}
+	return results, ensureFailedFeeds(failedFeeds), nil
 }
 
-// buildFetchOptions reads user feed preferences and falls back to config defaults.
-func buildFetchOptions(store *storage.Store, cfg *config.Config, ctx context.Context) feeds.FetchOptions {
-	opts := feeds.FetchOptions{
-		Mode:         "recent_posts",
-		MaxArticles:  cfg.Feeds.MaxArticlesPerFeed,
-		LookbackDays: cfg.Feeds.LookbackDays,
+// buildFetchOptions returns the fetch options of the feed settings, which
+// fall back to config defaults.
+func buildFetchOptions(store storage.Store, cfg *config.Config, ctx context.Context) feeds.FetchOptions {
+	s := LoadSettings(ctx, store, cfg)
+	return feeds.FetchOptions{
+		Mode:         s.FeedMode,
+		MaxArticles:  s.MaxArticlesPerFeed,
+		LookbackDays: s.LookbackDays,
+	}
+}
+
+// extractContent fetches and stores the full article text of blog if it is
+// missing. Failures are logged and leave the content empty.
+func extractContent(ctx context.Context, store storage.Store, fetcher *feeds.Fetcher, blog *models.Blog) {
+	if blog.FullContent != "" {
+		return
+	}
+
+	slog.InfoContext(ctx, "extracting article", "url", blog.URL)
+	content, err := fetcher.ExtractArticle(ctx, blog.URL)
+	if err != nil {
+		slog.WarnContext(ctx, "failed to extract article", "url", blog.URL, "error", err)
+		return
+	}
+	blog.FullContent = content
+	blog.ContentHash = feeds.HashContent(content)
+	if _, err := store.UpsertBlog(ctx, blog); err != nil {
+		slog.WarnContext(ctx, "failed to update blog content", "id", blog.ID, "error", err)
 	}
+}
 
-	var feedMode string
-	if err := store.GetPreference(ctx, "feed_mode", &feedMode); err == nil {
-		if feedMode == "recent_posts" || feedMode == "time_range" {
-			opts.Mode = feedMode
+// ensureReadingTime calculates and stores blog's reading time from its
+// content if it has none yet.
+func ensureReadingTime(ctx context.Context, store storage.Store, blog *models.Blog) {
+	if blog.ReadingTimeMinutes != nil || blog.FullContent == "" {
+		return
+	}
+	minutes := feeds.CalculateReadingTime(blog.FullContent)
+	if minutes <= 0 {
+		return
+	}
+	if err := store.UpdateReadingTime(ctx, blog.ID, minutes); err != nil {
+		slog.WarnContext(ctx, "failed to cache reading time", "blog_id", blog.ID, "error", err)
+	}
+	blog.ReadingTimeMinutes = &minutes
+}
+
+// newSince returns the fetched blogs that are new since the given time:
+// published after it, or not stored until after it. The rest were already
+// candidates in an earlier session.
+func newSince(ctx context.Context, store storage.Store, blogs []models.Blog, since time.Time) []models.Blog {
+	fresh := make([]models.Blog, 0, len(blogs))
+	for _, b := range blogs {
+		if b.PublishedAt != nil && b.PublishedAt.After(since) {
+			fresh = append(fresh, b)
+			continue
+		}
+		stored, err := store.GetBlogByURL(ctx, b.URL)
+		if err != nil || stored.FetchedAt.After(since) {
+			fresh = append(fresh, b)
 		}
 	}
+	return fresh
+}
 
-	var maxArticles int
-	if err := store.GetPreference(ctx, "max_articles_per_feed", &maxArticles); err == nil {
-		if maxArticles >= 5 && maxArticles <= 20 {
-			opts.MaxArticles = maxArticles
+// knownReadingTime returns the reading time of a fetched post as far as it
+// is known before extraction: from the stored post's cached reading time or
+// content, else from the content its feed carried. It returns 0 if unknown.
+func knownReadingTime(fetched, stored *models.Blog) int {
+	switch {
+	case stored.ReadingTimeMinutes != nil:
+		return *stored.ReadingTimeMinutes
+	case stored.FullContent != "":
+		return feeds.CalculateReadingTime(stored.FullContent)
+	case fetched.ReadingTimeMinutes != nil:
+		return *fetched.ReadingTimeMinutes
+	}
+	return 0
+}
+
+// maxReadingMinutes returns the reading time limit for a discovery run: the
+// "max_reading_minutes" query parameter if given, else fromBody if set, else
+// the preference of the same name. 0 means no limit; a query parameter of 0
+// ignores the preference.
+func maxReadingMinutes(ctx context.Context, store storage.Store, r *http.Request, fromBody int) (int, error) {
+	limit := fromBody
+	if v := r.URL.Query().Get("max_reading_minutes"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			return 0, fmt.Errorf("max_reading_minutes must be a whole number of minutes")
 		}
+		return n, nil // an explicit 0 lifts the preferred limit for this run
+	}
+	if limit < 0 {
+		return 0, fmt.Errorf("max_reading_minutes cannot be negative")
+	}
+	if limit == 0 {
+		var pref int
+		if err := store.GetPreference(ctx, "max_reading_minutes", &pref); err == nil && pref > 0 {
+			limit = pref
+		}
+	}
+	return limit, nil
+}
+
+// ensureSummary returns the stored summary of blog, generating and storing
+// one first if none exists or the stored one is stale because the blog's
+// content changed. If summarization fails, a stale summary is kept and
+// otherwise the blog's description stands in for the summary.
+func ensureSummary(ctx context.Context, store storage.Store, aiProvider ai.AIProvider, model string, blog *models.Blog) models.BlogSummary {
+	cached, err := store.GetSummaryByBlogID(ctx, blog.ID)
+	if err != nil && !errors.Is(err, storage.ErrNotFound) {
+		slog.WarnContext(ctx, "failed to check summary cache", "id", blog.ID, "error", err)
+	}
+	if cached != nil && !cached.Stale {
+		return *cached
 	}
 
-	var lookbackDays int
-	if err := store.GetPreference(ctx, "lookback_days", &lookbackDays); err == nil {
-		if lookbackDays >= 1 && lookbackDays <= 30 {
-			opts.LookbackDays = lookbackDays
+	if cached != nil {
+		slog.InfoContext(ctx, "resummarizing updated blog", "id", blog.ID, "title", blog.Title)
+	} else {
+		slog.InfoContext(ctx, "summarizing blog", "id", blog.ID, "title", blog.Title)
+	}
+	var publishedAt string
+	if blog.PublishedAt != nil {
+		publishedAt = blog.PublishedAt.Format("2006-01-02")
+	}
+	entry := ai.BlogEntry{
+		ID:          blog.ID,
+		Title:       blog.Title,
+		Source:      blog.Source,
+		PublishedAt: publishedAt,
+		Description: blog.Description,
+		FullContent: blog.FullContent,
+	}
+	aiSummary, err := aiProvider.Summarize(ctx, entry)
+	if err != nil {
+		slog.WarnContext(ctx, "failed to summarize blog", "id", blog.ID, "error", err)
+		if cached != nil {
+			return *cached // an outdated summary beats the description
 		}
+		aiSummary = ai.Summary{Text: blog.Description} // fallback to description
 	}
 
-	return opts
+	summary := models.BlogSummary{
+		BlogID:     blog.ID,
+		Summary:    aiSummary.Text,
+		Difficulty: aiSummary.Difficulty,
+		Category:   aiSummary.Category,
+		ModelUsed:  model,
+	}
+	if err := store.UpsertSummary(ctx, &summary); err != nil {
+		slog.WarnContext(ctx, "failed to cache summary", "id", blog.ID, "error", err)
+	}
+	return summary
+}
+
+// adoptDifficulty stores difficulty as blog's level if the blog has not been
+// classified yet and difficulty is valid.
+func adoptDifficulty(ctx context.Context, store storage.Store, blog *models.Blog, difficulty string) {
+	if blog.Difficulty != "" || !models.IsValidDifficulty(difficulty) {
+		return
+	}
+	if err := store.UpdateBlogDifficulty(ctx, blog.ID, difficulty); err != nil {
+		slog.WarnContext(ctx, "failed to save difficulty", "id", blog.ID, "error", err)
+		return
+	}
+	blog.Difficulty = difficulty
+}
+
+// titleRewriteEnabled reports whether the "rewrite_titles" preference is set.
+func titleRewriteEnabled(ctx context.Context, store storage.Store) bool {
+	var enabled bool
+	if err := store.GetPreference(ctx, "rewrite_titles", &enabled); err != nil {
+		return false
+	}
+	return enabled
+}
+
+// rewriteTitle asks the AI for a factual version of blog's title and stores
+// it alongside the original, unless that was already done. Failures are
+// logged and leave the title unprocessed.
+func rewriteTitle(ctx context.Context, store storage.Store, aiProvider ai.AIProvider, blog *models.Blog) {
+	if aiProvider == nil || blog.RewrittenTitle != "" {
+		return
+	}
+
+	title, err := aiProvider.RewriteTitle(ctx, ai.BlogEntry{
+		ID:          blog.ID,
+		Title:       blog.Title,
+		Source:      blog.Source,
+		Description: blog.Description,
+	})
+	if err != nil {
+		slog.WarnContext(ctx, "failed to rewrite title", "id", blog.ID, "error", err)
+		return
+	}
+
+	if err := store.UpdateBlogRewrittenTitle(ctx, blog.ID, title); err != nil {
+		slog.WarnContext(ctx, "failed to save rewritten title", "id", blog.ID, "error", err)
+		return
+	}
+	blog.RewrittenTitle = title
+}
+
+// toBlogEntries converts fetched blogs to the simplified entries used in AI
+// prompts.
+func toBlogEntries(blogs []models.Blog) []ai.BlogEntry {
+	entries := make([]ai.BlogEntry, len(blogs))
+	for i, b := range blogs {
+		var publishedAt string
+		if b.PublishedAt != nil {
+			publishedAt = b.PublishedAt.Format("2006-01-02")
+		}
+		entries[i] = ai.BlogEntry{
+			ID:          b.ID,
+			Title:       b.Title,
+			Source:      b.Source,
+			PublishedAt: publishedAt,
+			Description: b.Description,
+			FullContent: b.FullContent,
+		}
+	}
+	return entries
+}
+
+// classifyDifficulty estimates and persists the difficulty of blog when it
+// has not been classified yet. Failures are logged and leave the blog
+// unclassified.
+func classifyDifficulty(ctx context.Context, store storage.Store, aiProvider ai.AIProvider, blog *models.Blog) {
+	if aiProvider == nil || blog.Difficulty != "" {
+		return
+	}
+
+	difficulty, err := aiProvider.ClassifyDifficulty(ctx, ai.BlogEntry{
+		ID:          blog.ID,
+		Title:       blog.Title,
+		Source:      blog.Source,
+		Description: blog.Description,
+		FullContent: blog.FullContent,
+	})
+	if err != nil {
+		slog.WarnContext(ctx, "failed to classify difficulty", "id", blog.ID, "error", err)
+		return
+	}
+
+	if err := store.UpdateBlogDifficulty(ctx, blog.ID, difficulty); err != nil {
+		slog.WarnContext(ctx, "failed to save difficulty", "id", blog.ID, "error", err)
+		return
+	}
+	blog.Difficulty = difficulty
 }
 
 // ensureFailedFeeds returns an empty slice instead of nil for consistent
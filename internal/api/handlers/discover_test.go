@@ -0,0 +1,340 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/hoanghai1803/apricot/internal/ai"
+	"github.com/hoanghai1803/apricot/internal/feeds"
+	"github.com/hoanghai1803/apricot/internal/models"
+)
+
+func TestRewriteTitle(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+	blogID := seedBlog(t, store)
+
+	blog, err := store.GetBlogByID(ctx, blogID)
+	if err != nil {
+		t.Fatalf("GetBlogByID: %v", err)
+	}
+
+	rewriteTitle(ctx, store, &stubAIProvider{title: "Factual Title"}, blog)
+	if blog.RewrittenTitle != "Factual Title" {
+		t.Fatalf("RewrittenTitle = %q, want %q", blog.RewrittenTitle, "Factual Title")
+	}
+
+	// An already rewritten title is not sent to the AI again.
+	rewriteTitle(ctx, store, &stubAIProvider{title: "Other"}, blog)
+	stored, err := store.GetBlogByID(ctx, blogID)
+	if err != nil {
+		t.Fatalf("GetBlogByID: %v", err)
+	}
+	if stored.RewrittenTitle != "Factual Title" {
+		t.Errorf("stored RewrittenTitle = %q, want %q", stored.RewrittenTitle, "Factual Title")
+	}
+}
+
+func TestTitleRewriteEnabled(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+
+	if titleRewriteEnabled(ctx, store) {
+		t.Error("expected title rewriting to be off by default")
+	}
+	if err := store.SetPreference(ctx, "rewrite_titles", true); err != nil {
+		t.Fatalf("SetPreference: %v", err)
+	}
+	if !titleRewriteEnabled(ctx, store) {
+		t.Error("expected title rewriting to be on after setting the preference")
+	}
+}
+
+func TestClassifyDifficulty(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+	blogID := seedBlog(t, store)
+
+	blog, err := store.GetBlogByID(ctx, blogID)
+	if err != nil {
+		t.Fatalf("GetBlogByID: %v", err)
+	}
+
+	classifyDifficulty(ctx, store, &stubAIProvider{difficulty: models.DifficultyIntermediate}, blog)
+
+	stored, err := store.GetBlogByID(ctx, blogID)
+	if err != nil {
+		t.Fatalf("GetBlogByID: %v", err)
+	}
+	if stored.Difficulty != models.DifficultyIntermediate {
+		t.Errorf("stored Difficulty = %q, want %q", stored.Difficulty, models.DifficultyIntermediate)
+	}
+}
+
+func TestEnsureSummary_RegeneratesStaleSummary(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+	blogID := seedBlog(t, store)
+
+	blog, err := store.GetBlogByID(ctx, blogID)
+	if err != nil {
+		t.Fatalf("GetBlogByID: %v", err)
+	}
+	blog.FullContent = "first version"
+	blog.ContentHash = feeds.HashContent(blog.FullContent)
+	if _, err := store.UpsertBlog(ctx, blog); err != nil {
+		t.Fatalf("UpsertBlog: %v", err)
+	}
+
+	first := ensureSummary(ctx, store, &stubAIProvider{summary: ai.Summary{Text: "First."}}, "test-model", blog)
+	if first.Summary != "First." {
+		t.Fatalf("Summary = %q, want %q", first.Summary, "First.")
+	}
+
+	// A fresh summary is served from the cache.
+	cached := ensureSummary(ctx, store, &stubAIProvider{summary: ai.Summary{Text: "Other."}}, "test-model", blog)
+	if cached.Summary != "First." {
+		t.Errorf("cached Summary = %q, want %q", cached.Summary, "First.")
+	}
+
+	// Changed content makes the summary stale, so it is regenerated.
+	blog.FullContent = "second version"
+	blog.ContentHash = feeds.HashContent(blog.FullContent)
+	if _, err := store.UpsertBlog(ctx, blog); err != nil {
+		t.Fatalf("UpsertBlog: %v", err)
+	}
+	updated := ensureSummary(ctx, store, &stubAIProvider{summary: ai.Summary{Text: "Second."}}, "test-model", blog)
+	if updated.Summary != "Second." {
+		t.Errorf("updated Summary = %q, want %q", updated.Summary, "Second.")
+	}
+
+	stored, err := store.GetSummaryByBlogID(ctx, blogID)
+	if err != nil {
+		t.Fatalf("GetSummaryByBlogID: %v", err)
+	}
+	if stored.Summary != "Second." || stored.Stale {
+		t.Errorf("stored summary = %q (stale %v), want %q (fresh)", stored.Summary, stored.Stale, "Second.")
+	}
+}
+
+func TestMaxReadingMinutes(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+
+	limit := func(query string, fromBody int) (int, error) {
+		r := httptest.NewRequest(http.MethodPost, "/api/discover"+query, nil)
+		return maxReadingMinutes(ctx, store, r, fromBody)
+	}
+
+	if n, err := limit("", 0); err != nil || n != 0 {
+		t.Errorf("no limit set: got %d, %v; want 0", n, err)
+	}
+	if err := store.SetPreference(ctx, "max_reading_minutes", 20); err != nil {
+		t.Fatalf("SetPreference: %v", err)
+	}
+	tests := []struct {
+		query    string
+		fromBody int
+		want     int
+		wantErr  bool
+	}{
+		{"", 0, 20, false},
+		{"", 15, 15, false},
+		{"?max_reading_minutes=10", 15, 10, false},
+		{"?max_reading_minutes=0", 0, 0, false},
+		{"?max_reading_minutes=soon", 0, 0, true},
+		{"?max_reading_minutes=-5", 0, 0, true},
+		{"", -5, 0, true},
+	}
+	for _, tt := range tests {
+		n, err := limit(tt.query, tt.fromBody)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%q, body %d: error = %v, wantErr %v", tt.query, tt.fromBody, err, tt.wantErr)
+			continue
+		}
+		if n != tt.want {
+			t.Errorf("%q, body %d: got %d, want %d", tt.query, tt.fromBody, n, tt.want)
+		}
+	}
+}
+
+func TestKnownReadingTime(t *testing.T) {
+	cached, fromFeed := 25, 8
+	long := strings.Repeat("word ", 2000)
+
+	tests := []struct {
+		name            string
+		fetched, stored models.Blog
+		want            int
+	}{
+		{"cached", models.Blog{ReadingTimeMinutes: &fromFeed}, models.Blog{ReadingTimeMinutes: &cached}, 25},
+		{"stored content", models.Blog{ReadingTimeMinutes: &fromFeed}, models.Blog{FullContent: long}, feeds.CalculateReadingTime(long)},
+		{"feed content", models.Blog{ReadingTimeMinutes: &fromFeed}, models.Blog{}, 8},
+		{"unknown", models.Blog{}, models.Blog{}, 0},
+	}
+	for _, tt := range tests {
+		if got := knownReadingTime(&tt.fetched, &tt.stored); got != tt.want {
+			t.Errorf("%s: got %d, want %d", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestDiscoverySessionHistory(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+
+	inputTokens := 1200
+	first, err := store.CreateSession(ctx, &models.DiscoverySession{
+		PreferencesSnapshot: "{}",
+		BlogsConsidered:     40,
+		BlogsSelected:       "[1,2]",
+		ModelUsed:           "model-a",
+		InputTokens:         &inputTokens,
+		ResultsJSON:         `[{"id":1,"title":"One"},{"id":2,"title":"Two"}]`,
+		FailedFeedsJSON:     `[{"source":"Broken Blog","error":"timeout"}]`,
+	})
+	if err != nil {
+		t.Fatalf("CreateSession: %v", err)
+	}
+	if _, err := store.CreateSession(ctx, &models.DiscoverySession{
+		PreferencesSnapshot: "{}",
+		BlogsSelected:       "[]",
+		ModelUsed:           "model-b",
+	}); err != nil {
+		t.Fatalf("CreateSession: %v", err)
+	}
+
+	w := httptest.NewRecorder()
+	ListDiscoverySessions(store).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/discover/sessions?limit=1&offset=1", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("list: got status %d; body: %s", w.Code, w.Body.String())
+	}
+	var page DiscoverySessionPage
+	if err := json.NewDecoder(w.Body).Decode(&page); err != nil {
+		t.Fatalf("decoding list: %v", err)
+	}
+	if page.Total != 2 || len(page.Sessions) != 1 {
+		t.Fatalf("page = %+v, want 1 of 2 sessions", page)
+	}
+	if s := page.Sessions[0]; s.ID != first || s.ModelUsed != "model-a" || s.ResultCount != 2 || s.FailedFeedCount != 1 {
+		t.Errorf("session summary = %+v, want the first session with 2 results and 1 failed feed", s)
+	}
+
+	w = httptest.NewRecorder()
+	r := withURLParams(httptest.NewRequest(http.MethodGet, "/api/discover/sessions/x", nil), "id", jsonInt64(first))
+	GetDiscoverySession(store).ServeHTTP(w, r)
+	if w.Code != http.StatusOK {
+		t.Fatalf("detail: got status %d; body: %s", w.Code, w.Body.String())
+	}
+	var detail DiscoverySessionDetail
+	if err := json.NewDecoder(w.Body).Decode(&detail); err != nil {
+		t.Fatalf("decoding detail: %v", err)
+	}
+	if detail.SessionID != first || len(detail.Results) != 2 || len(detail.FailedFeeds) != 1 ||
+		detail.InputTokens == nil || *detail.InputTokens != 1200 || detail.BlogsConsidered != 40 {
+		t.Errorf("detail = %+v", detail)
+	}
+
+	w = httptest.NewRecorder()
+	r = withURLParams(httptest.NewRequest(http.MethodGet, "/api/discover/sessions/999", nil), "id", "999")
+	GetDiscoverySession(store).ServeHTTP(w, r)
+	if w.Code != http.StatusNotFound {
+		t.Errorf("missing session: got status %d, want %d", w.Code, http.StatusNotFound)
+	}
+}
+
+func TestGetLatestDiscovery_Difficulty(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+
+	if _, err := store.CreateSession(ctx, &models.DiscoverySession{
+		PreferencesSnapshot: "{}",
+		BlogsSelected:       "[1,2]",
+		ResultsJSON:         `[{"id":1,"title":"One","difficulty":"intro"},{"id":2,"title":"Two","difficulty":"deep-dive"}]`,
+	}); err != nil {
+		t.Fatalf("CreateSession: %v", err)
+	}
+
+	w := httptest.NewRecorder()
+	GetLatestDiscovery(store).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/discover/latest?difficulty=intro", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("got status %d; body: %s", w.Code, w.Body.String())
+	}
+	var resp DiscoverResponse
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("decoding response: %v", err)
+	}
+	if len(resp.Results) != 1 || resp.Results[0].ID != 1 {
+		t.Errorf("results = %+v, want only the intro post", resp.Results)
+	}
+
+	w = httptest.NewRecorder()
+	GetLatestDiscovery(store).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/discover/latest?difficulty=advnced", nil))
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("unknown difficulty: got status %d, want %d", w.Code, http.StatusBadRequest)
+	}
+}
+
+func TestCompareDiscoverySessions(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+
+	a, err := store.CreateSession(ctx, &models.DiscoverySession{
+		PreferencesSnapshot: "- go (importance 5/5: core)\n- rust (importance 3/5: regular)",
+		BlogsSelected:       "[1,2]",
+		ModelUsed:           "test-model",
+		ResultsJSON:         `[{"id":1,"title":"One"},{"id":2,"title":"Two"}]`,
+	})
+	if err != nil {
+		t.Fatalf("CreateSession: %v", err)
+	}
+	b, err := store.CreateSession(ctx, &models.DiscoverySession{
+		PreferencesSnapshot: "- go (importance 5/5: core)\n- databases (importance 4/5: high)",
+		BlogsSelected:       "[2,3]",
+		ModelUsed:           "test-model",
+		ResultsJSON:         `[{"id":3,"title":"Three"},{"id":2,"title":"Two"}]`,
+	})
+	if err != nil {
+		t.Fatalf("CreateSession: %v", err)
+	}
+
+	compare := func(query string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		CompareDiscoverySessions(store).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/discover/sessions/compare"+query, nil))
+		return w
+	}
+
+	w := compare("?a=" + jsonInt64(a) + "&b=" + jsonInt64(b))
+	if w.Code != http.StatusOK {
+		t.Fatalf("got status %d; body: %s", w.Code, w.Body.String())
+	}
+	var cmp SessionComparison
+	if err := json.NewDecoder(w.Body).Decode(&cmp); err != nil {
+		t.Fatalf("decoding response: %v", err)
+	}
+	if len(cmp.New) != 1 || cmp.New[0].ID != 3 {
+		t.Errorf("new = %+v, want article 3", cmp.New)
+	}
+	if len(cmp.Dropped) != 1 || cmp.Dropped[0].ID != 1 {
+		t.Errorf("dropped = %+v, want article 1", cmp.Dropped)
+	}
+	if len(cmp.Kept) != 1 || cmp.Kept[0].ID != 2 {
+		t.Errorf("kept = %+v, want article 2", cmp.Kept)
+	}
+	p := cmp.Preferences
+	if !p.Changed || len(p.Added) != 1 || !strings.HasPrefix(p.Added[0], "- databases") ||
+		len(p.Removed) != 1 || !strings.HasPrefix(p.Removed[0], "- rust") {
+		t.Errorf("preferences = %+v, want databases added and rust removed", p)
+	}
+
+	if w := compare("?a=" + jsonInt64(a)); w.Code != http.StatusBadRequest {
+		t.Errorf("missing b: got status %d, want %d", w.Code, http.StatusBadRequest)
+	}
+	if w := compare("?a=" + jsonInt64(a) + "&b=999"); w.Code != http.StatusNotFound {
+		t.Errorf("unknown b: got status %d, want %d", w.Code, http.StatusNotFound)
+	}
+}
@@ -0,0 +1,96 @@
+package handlers
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/hoanghai1803/apricot/internal/epub"
+	"github.com/hoanghai1803/apricot/internal/feeds"
+	"github.com/hoanghai1803/apricot/internal/storage"
+)
+
+// maxEPUBItems is the most reading list items in one EPUB.
+const maxEPUBItems = 50
+
+// ExportEPUB handles GET /api/export/epub?ids=1,2,3. It bundles the given
+// reading list items, in that order, into an EPUB (see epub.Write): each
+// article is fetched again for its HTML and images, falling back to the
+// stored text when the page cannot be had. A single item's book is titled
+// after it. Unknown items get 404.
+func ExportEPUB(store storage.Store, fetcher *feeds.Fetcher) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		ids, err := parseIDList(r.URL.Query().Get("ids"))
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		if len(ids) == 0 {
+			writeError(w, http.StatusBadRequest, "ids is required")
+			return
+		}
+		if len(ids) > maxEPUBItems {
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("At most %d items can be exported at once", maxEPUBItems))
+			return
+		}
+
+		now := time.Now()
+		book := epub.Book{Title: "Apricot, " + now.Format("January 2, 2006")}
+		for _, id := range ids {
+			item, err := store.GetReadingListItemByID(ctx, id)
+			if err != nil {
+				if errors.Is(err, storage.ErrNotFound) {
+					writeError(w, http.StatusNotFound, fmt.Sprintf("Reading list item %d not found", id))
+					return
+				}
+				slog.ErrorContext(ctx, "failed to get reading list item", "id", id, "error", err)
+				writeError(w, http.StatusInternalServerError, "Failed to export EPUB")
+				return
+			}
+			if item.Blog == nil {
+				continue
+			}
+			c := epub.Chapter{
+				Title:       item.Blog.Title,
+				Source:      item.Blog.Source,
+				URL:         item.Blog.URL,
+				PublishedAt: item.Blog.PublishedAt,
+				Text:        item.Blog.FullContent,
+			}
+			if item.Blog.RewrittenTitle != "" {
+				c.Title = item.Blog.RewrittenTitle
+			}
+			if fetcher != nil {
+				if c.HTML, err = fetcher.ExtractArticleHTML(ctx, c.URL); err != nil {
+					slog.WarnContext(ctx, "failed to fetch article for EPUB; using its stored text", "url", c.URL, "error", err)
+				}
+			}
+			book.Chapters = append(book.Chapters, c)
+		}
+		if len(ids) == 1 && len(book.Chapters) == 1 {
+			book.Title = book.Chapters[0].Title
+		}
+
+		var fetch epub.ImageFunc
+		if fetcher != nil {
+			fetch = fetcher.FetchImage
+		}
+		var buf bytes.Buffer
+		if err := epub.Write(ctx, &buf, book, fetch, now); err != nil {
+			slog.ErrorContext(ctx, "failed to write EPUB", "error", err)
+			writeError(w, http.StatusInternalServerError, "Failed to export EPUB")
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/epub+zip")
+		w.Header().Set("Content-Disposition",
+			fmt.Sprintf(`attachment; filename="apricot-%s.epub"`, now.Format("2006-01-02")))
+		w.WriteHeader(http.StatusOK)
+		w.Write(buf.Bytes()) //nolint:errcheck
+	}
+}
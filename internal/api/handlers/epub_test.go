@@ -0,0 +1,75 @@
+package handlers
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hoanghai1803/apricot/internal/models"
+)
+
+func TestExportEPUB(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+	blogID, err := store.UpsertBlog(ctx, &models.Blog{
+		SourceID:    1,
+		Title:       "Caching",
+		URL:         "https://a.example/cache",
+		FullContent: "First paragraph.\nSecond paragraph.",
+		FetchedAt:   time.Now(),
+	})
+	if err != nil {
+		t.Fatalf("UpsertBlog: %v", err)
+	}
+	if err := store.AddToReadingList(ctx, blogID); err != nil {
+		t.Fatalf("AddToReadingList: %v", err)
+	}
+	id, err := store.GetReadingListIDByBlogID(ctx, blogID)
+	if err != nil {
+		t.Fatalf("GetReadingListIDByBlogID: %v", err)
+	}
+
+	// Without a fetcher, the book is made of the stored text.
+	w := httptest.NewRecorder()
+	ExportEPUB(store, nil).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/export/epub?ids="+jsonInt64(id), nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status %d, want %d; body: %s", w.Code, http.StatusOK, w.Body.String())
+	}
+	if ct := w.Header().Get("Content-Type"); ct != "application/epub+zip" {
+		t.Errorf("Content-Type = %q", ct)
+	}
+	zr, err := zip.NewReader(bytes.NewReader(w.Body.Bytes()), int64(w.Body.Len()))
+	if err != nil {
+		t.Fatalf("reading EPUB: %v", err)
+	}
+	var chapter string
+	for _, f := range zr.File {
+		if f.Name == "OEBPS/chapter-1.xhtml" {
+			rc, _ := f.Open()
+			b, _ := io.ReadAll(rc)
+			rc.Close()
+			chapter = string(b)
+		}
+	}
+	if !strings.Contains(chapter, "<title>Caching</title>") || !strings.Contains(chapter, "<p>First paragraph.</p>\n<p>Second paragraph.</p>") {
+		t.Errorf("chapter = %s, want the item's title and text", chapter)
+	}
+
+	for query, want := range map[string]int{
+		"":                               http.StatusBadRequest,
+		"?ids=1,x":                       http.StatusBadRequest,
+		"?ids=" + jsonInt64(id) + ",999": http.StatusNotFound,
+	} {
+		w := httptest.NewRecorder()
+		ExportEPUB(store, nil).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/export/epub"+query, nil))
+		if w.Code != want {
+			t.Errorf("%q: status %d, want %d", query, w.Code, want)
+		}
+	}
+}
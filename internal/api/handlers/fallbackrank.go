@@ -0,0 +1,122 @@
+package handlers
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/hoanghai1803/apricot/internal/ai"
+	"github.com/hoanghai1803/apricot/internal/models"
+	"github.com/hoanghai1803/apricot/internal/storage"
+)
+
+// recencyHalfLife is how fast the recency bonus of the fallback ranking
+// decays: a post this old gets half the bonus of one published today.
+const recencyHalfLife = 7 * 24 * time.Hour
+
+// stopWords are left out of topic keywords, so that "testing in Go" does
+// not match every post that says "in".
+var stopWords = map[string]bool{
+	"a": true, "an": true, "and": true, "are": true, "as": true, "at": true,
+	"be": true, "by": true, "for": true, "from": true, "how": true, "in": true,
+	"into": true, "is": true, "it": true, "of": true, "on": true, "or": true,
+	"the": true, "to": true, "vs": true, "with": true,
+}
+
+// words splits s into lowercase words of letters and digits.
+func words(s string) []string {
+	return strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// topicKeywords returns the words of the user's topics with the importance
+// of the topic each comes from (the highest, if several share it). A
+// free-form topics preference gives every word the default importance.
+func topicKeywords(ctx context.Context, store storage.Store) map[string]int {
+	var raw json.RawMessage
+	if err := store.GetPreference(ctx, "topics", &raw); err != nil {
+		return nil
+	}
+	topics, text, err := parseTopics(raw)
+	if err != nil {
+		return nil
+	}
+	if topics == nil {
+		topics = []models.Topic{{Topic: text, Importance: models.DefaultTopicImportance}}
+	}
+
+	keywords := make(map[string]int)
+	for _, t := range topics {
+		for _, w := range words(t.Topic) {
+			if !stopWords[w] {
+				keywords[w] = max(keywords[w], t.Importance)
+			}
+		}
+	}
+	return keywords
+}
+
+// rankByKeywords ranks blogs without the AI, for when FilterAndRank fails.
+// Each post scores the importance of every topic keyword in its title,
+// doubled, and in its description, plus a recency bonus of up to one point
+// that halves every recencyHalfLife; undated posts get half a point. The
+// best limit posts are returned, ties keeping their feed order.
+func rankByKeywords(blogs []ai.BlogEntry, keywords map[string]int, limit int, now time.Time) []ai.RankedBlog {
+	type scored struct {
+		ranked ai.RankedBlog
+		score  float64
+	}
+	all := make([]scored, 0, len(blogs))
+	for _, b := range blogs {
+		var matched []string
+		var score float64
+		title, description := words(b.Title), words(b.Description)
+		for w, importance := range keywords {
+			inTitle, inDescription := slices.Contains(title, w), slices.Contains(description, w)
+			if inTitle {
+				score += 2 * float64(importance)
+			}
+			if inDescription {
+				score += float64(importance)
+			}
+			if inTitle || inDescription {
+				matched = append(matched, w)
+			}
+		}
+		score += recencyBonus(b.PublishedAt, now)
+
+		reason := "A recent post; ranked without AI, which was unavailable."
+		if len(matched) > 0 {
+			slices.Sort(matched)
+			reason = fmt.Sprintf("Mentions %s; ranked without AI, which was unavailable.", strings.Join(matched, ", "))
+		}
+		all = append(all, scored{ai.RankedBlog{ID: b.ID, Reason: reason}, score})
+	}
+
+	slices.SortStableFunc(all, func(a, b scored) int {
+		return cmp.Compare(b.score, a.score)
+	})
+	ranked := make([]ai.RankedBlog, 0, min(limit, len(all)))
+	for _, s := range all[:min(limit, len(all))] {
+		ranked = append(ranked, s.ranked)
+	}
+	return ranked
+}
+
+// recencyBonus scores a post published on publishedAt, a "2006-01-02"
+// date or empty if unknown.
+func recencyBonus(publishedAt string, now time.Time) float64 {
+	t, err := time.Parse("2006-01-02", publishedAt)
+	if err != nil {
+		return 0.5
+	}
+	age := max(now.Sub(t), 0)
+	return math.Pow(0.5, float64(age)/float64(recencyHalfLife))
+}
@@ -0,0 +1,66 @@
+package handlers
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hoanghai1803/apricot/internal/ai"
+	"github.com/hoanghai1803/apricot/internal/models"
+)
+
+func TestTopicKeywords(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+
+	if err := store.SetPreference(ctx, "topics", []models.Topic{
+		{Topic: "Distributed systems", Importance: 5},
+		{Topic: "Testing in Go", Importance: 2},
+		{Topic: "Go performance", Importance: 4},
+	}); err != nil {
+		t.Fatalf("SetPreference: %v", err)
+	}
+	got := topicKeywords(ctx, store)
+	want := map[string]int{"distributed": 5, "systems": 5, "testing": 2, "go": 4, "performance": 4}
+	if len(got) != len(want) {
+		t.Errorf("topicKeywords() = %v, want %v", got, want)
+	}
+	for w, importance := range want {
+		if got[w] != importance {
+			t.Errorf("topicKeywords()[%q] = %d, want %d", w, got[w], importance)
+		}
+	}
+
+	if err := store.SetPreference(ctx, "topics", "databases and caching"); err != nil {
+		t.Fatalf("SetPreference: %v", err)
+	}
+	if got := topicKeywords(ctx, store); len(got) != 2 || got["databases"] != models.DefaultTopicImportance {
+		t.Errorf("topicKeywords() for free-form topics = %v, want databases and caching", got)
+	}
+}
+
+func TestRankByKeywords(t *testing.T) {
+	now := time.Date(2026, 3, 15, 12, 0, 0, 0, time.UTC)
+	blogs := []ai.BlogEntry{
+		{ID: 1, Title: "Company offsite recap", PublishedAt: "2026-03-15"},
+		{ID: 2, Title: "Scaling our billing service", Description: "Lessons from distributed systems at scale", PublishedAt: "2026-01-01"},
+		{ID: 3, Title: "Distributed tracing for everyone", PublishedAt: "2026-03-14"},
+		{ID: 4, Title: "Hiring update"},
+	}
+	keywords := map[string]int{"distributed": 5, "systems": 5}
+
+	ranked := rankByKeywords(blogs, keywords, 3, now)
+	var ids []int64
+	for _, rb := range ranked {
+		ids = append(ids, rb.ID)
+	}
+	// A title match beats two description matches; with no match, a post
+	// from today beats an undated one.
+	if want := []int64{3, 2, 1}; len(ids) != len(want) || ids[0] != want[0] || ids[1] != want[1] || ids[2] != want[2] {
+		t.Errorf("ranked IDs = %v, want %v", ids, want)
+	}
+	if !strings.Contains(ranked[1].Reason, "distributed, systems") || !strings.Contains(ranked[2].Reason, "without AI") {
+		t.Errorf("reasons = %q, %q; want the matched keywords and a note about the AI", ranked[1].Reason, ranked[2].Reason)
+	}
+}
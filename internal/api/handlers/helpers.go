@@ -1,12 +1,17 @@
 package handlers
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/go-chi/chi/v5"
+
+	"github.com/hoanghai1803/apricot/internal/netguard"
 )
 
 // writeJSON encodes v as JSON and writes it to the response with the given
@@ -28,6 +33,41 @@ func writeError(w http.ResponseWriter, status int, message string) {
 	_ = json.NewEncoder(w).Encode(map[string]string{"error": message})
 }
 
+// writeStageError writes the error response for a failed stage of a
+// request, such as "ranking posts with AI". If the request ran out of time,
+// it writes 504 Gateway Timeout naming the stage, so the client can tell a
+// slow upstream from a failing one. A URL on an address netguard refuses
+// gets 403 Forbidden; anything else gets status and message.
+func writeStageError(ctx context.Context, w http.ResponseWriter, err error, stage string, status int, message string) {
+	if errors.Is(err, netguard.ErrBlocked) {
+		writeError(w, http.StatusForbidden, blockedMessage)
+		return
+	}
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusGatewayTimeout)
+		_ = json.NewEncoder(w).Encode(map[string]string{
+			"error": "Timed out while " + stage,
+			"stage": stage,
+		})
+		return
+	}
+	writeError(w, status, message)
+}
+
+// blockedMessage is the error for a URL on an address netguard refuses.
+const blockedMessage = "URL points to a private or local network address. Add it to [feeds] allow_networks to allow it"
+
+// stageError returns the error to report for a background pipeline that
+// failed at stage: a timeout names the stage, and anything else gets
+// message, as the job's error. The underlying error is logged by the caller.
+func stageError(ctx context.Context, err error, stage, message string) error {
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return errors.New("Timed out while " + stage)
+	}
+	return errors.New(message)
+}
+
 // parseID extracts an int64 from a chi URL parameter.
 func parseID(r *http.Request, param string) (int64, error) {
 	raw := chi.URLParam(r, param)
@@ -41,3 +81,66 @@ func parseID(r *http.Request, param string) (int64, error) {
 	}
 	return id, nil
 }
+
+// parseIDList parses a comma-separated list of IDs, dropping repeats.
+func parseIDList(s string) ([]int64, error) {
+	var ids []int64
+	seen := make(map[int64]bool)
+	for _, part := range strings.Split(s, ",") {
+		if part = strings.TrimSpace(part); part == "" {
+			continue
+		}
+		id, err := strconv.ParseInt(part, 10, 64)
+		if err != nil || id < 1 {
+			return nil, fmt.Errorf("invalid id %q", part)
+		}
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	return ids, nil
+}
+
+// parsePage reads the "limit" and "offset" query parameters of a paginated
+// list, using defaultLimit when limit is absent. Both must be non-negative
+// integers.
+func parsePage(r *http.Request, defaultLimit int) (limit, offset int, err error) {
+	limit = defaultLimit
+	if raw := r.URL.Query().Get("limit"); raw != "" {
+		if limit, err = strconv.Atoi(raw); err != nil || limit < 0 {
+			return 0, 0, errors.New("limit must be a non-negative integer")
+		}
+	}
+	if raw := r.URL.Query().Get("offset"); raw != "" {
+		if offset, err = strconv.Atoi(raw); err != nil || offset < 0 {
+			return 0, 0, errors.New("offset must be a non-negative integer")
+		}
+	}
+	return limit, offset, nil
+}
+
+// setETag sets the ETag header to a record's version, for clients to send
+// back in If-Match.
+func setETag(w http.ResponseWriter, version int64) {
+	w.Header().Set("ETag", strconv.Quote(strconv.FormatInt(version, 10)))
+}
+
+// parseIfMatch returns the version named by the request's If-Match header,
+// or 0 if the header is absent or "*". Weak validators are accepted, since
+// versions are compared by value.
+func parseIfMatch(r *http.Request) (int64, error) {
+	raw := strings.TrimSpace(r.Header.Get("If-Match"))
+	if raw == "" || raw == "*" {
+		return 0, nil
+	}
+	unquoted, err := strconv.Unquote(strings.TrimPrefix(raw, "W/"))
+	if err != nil {
+		return 0, errors.New(`If-Match must be a quoted version, such as "3"`)
+	}
+	version, err := strconv.ParseInt(unquoted, 10, 64)
+	if err != nil || version < 1 {
+		return 0, errors.New(`If-Match must be a quoted version, such as "3"`)
+	}
+	return version, nil
+}
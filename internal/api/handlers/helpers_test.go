@@ -3,6 +3,8 @@ package handlers
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -67,6 +69,34 @@ func TestWriteError(t *testing.T) {
 	}
 }
 
+func TestWriteStageError(t *testing.T) {
+	t.Run("deadline exceeded", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		err := fmt.Errorf("calling API: %w", context.DeadlineExceeded)
+		writeStageError(t.Context(), w, err, "ranking posts with AI", http.StatusInternalServerError, "Failed to rank")
+
+		if w.Code != http.StatusGatewayTimeout {
+			t.Errorf("got status %d, want %d", w.Code, http.StatusGatewayTimeout)
+		}
+		var got map[string]string
+		if err := json.NewDecoder(w.Body).Decode(&got); err != nil {
+			t.Fatalf("decoding response body: %v", err)
+		}
+		if got["stage"] != "ranking posts with AI" {
+			t.Errorf("got stage %q, want %q", got["stage"], "ranking posts with AI")
+		}
+	})
+
+	t.Run("other error", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		writeStageError(t.Context(), w, errors.New("boom"), "ranking posts with AI", http.StatusInternalServerError, "Failed to rank")
+
+		if w.Code != http.StatusInternalServerError {
+			t.Errorf("got status %d, want %d", w.Code, http.StatusInternalServerError)
+		}
+	})
+}
+
 func TestParseID(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -0,0 +1,68 @@
+package handlers
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/hoanghai1803/apricot/internal/jobs"
+	"github.com/hoanghai1803/apricot/internal/models"
+	"github.com/hoanghai1803/apricot/internal/storage"
+)
+
+// ListJobs handles GET /api/jobs. It returns background jobs, newest first,
+// optionally filtered by ?kind= and ?status= (queued, running, succeeded or
+// failed), a page at a time with ?limit= (default 50; 0 for all) and ?offset=.
+func ListJobs(runner *jobs.Manager) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		filter := storage.JobFilter{Kind: q.Get("kind"), Status: q.Get("status")}
+		switch filter.Status {
+		case "", models.JobQueued, models.JobRunning, models.JobSucceeded, models.JobFailed:
+		default:
+			writeError(w, http.StatusBadRequest, "status must be one of queued, running, succeeded, failed")
+			return
+		}
+
+		limit, offset, err := parsePage(r, 50)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		filter.Limit, filter.Offset = limit, offset
+
+		list, err := runner.List(r.Context(), filter)
+		if err != nil {
+			slog.ErrorContext(r.Context(), "failed to list jobs", "error", err)
+			writeError(w, http.StatusInternalServerError, "Failed to list jobs")
+			return
+		}
+
+		writeJSON(w, http.StatusOK, list)
+	}
+}
+
+// GetJob handles GET /api/jobs/{id}. It returns a background job's status
+// and attempts and, once it has finished, its result or error.
+func GetJob(runner *jobs.Manager) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := parseID(r, "id")
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "Invalid job ID")
+			return
+		}
+
+		job, err := runner.Get(r.Context(), id)
+		if err != nil {
+			if errors.Is(err, storage.ErrNotFound) {
+				writeError(w, http.StatusNotFound, "Job not found")
+				return
+			}
+			slog.ErrorContext(r.Context(), "failed to load job", "id", id, "error", err)
+			writeError(w, http.StatusInternalServerError, "Failed to load job")
+			return
+		}
+
+		writeJSON(w, http.StatusOK, job)
+	}
+}
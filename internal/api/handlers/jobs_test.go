@@ -0,0 +1,465 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/hoanghai1803/apricot/internal/ai"
+	"github.com/hoanghai1803/apricot/internal/config"
+	"github.com/hoanghai1803/apricot/internal/feeds"
+	"github.com/hoanghai1803/apricot/internal/jobs"
+	"github.com/hoanghai1803/apricot/internal/models"
+	"github.com/hoanghai1803/apricot/internal/storage"
+)
+
+func TestDiscover_RunsAsJob(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+
+	feed := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/rss+xml")
+		_, _ = w.Write([]byte(`<?xml version="1.0"?><rss version="2.0"><channel><title>Test</title>
+			<item><title>Consensus in practice</title><link>https://example.com/raft</link>
+			<pubDate>` + time.Now().UTC().Format(time.RFC1123Z) + `</pubDate></item>
+			</channel></rss>`))
+	}))
+	defer feed.Close()
+
+	if err := store.SetPreference(ctx, "topics", "distributed systems"); err != nil {
+		t.Fatalf("SetPreference: %v", err)
+	}
+	if _, err := store.DB().ExecContext(ctx, `UPDATE blog_sources SET is_active = 0`); err != nil {
+		t.Fatalf("deactivating sources: %v", err)
+	}
+	if _, err := store.DB().ExecContext(ctx,
+		`UPDATE blog_sources SET is_active = 1, feed_url = ? WHERE id = (SELECT MIN(id) FROM blog_sources WHERE feed_url != 'custom://user-added')`,
+		feed.URL); err != nil {
+		t.Fatalf("pointing a source at the test feed: %v", err)
+	}
+
+	cfg := &config.Config{
+		AI:     config.AIConfig{Model: "test-model"},
+		Feeds:  config.FeedsConfig{MaxArticlesPerFeed: 10, LookbackDays: 30},
+		Server: config.ServerConfig{DiscoveryTimeoutSeconds: 30},
+	}
+	runner := jobs.NewManager(store, 1)
+	runner.Register(DiscoverJob(store, &stubAIProvider{}, feeds.NewFetcher(nil), cfg, nil))
+	discover := Discover(store, &stubAIProvider{}, runner)
+
+	w := httptest.NewRecorder()
+	discover.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/discover", strings.NewReader(`{"dry_run": true}`)))
+	if w.Code != http.StatusAccepted {
+		t.Fatalf("got status %d, want %d; body: %s", w.Code, http.StatusAccepted, w.Body.String())
+	}
+	var job models.Job
+	if err := json.NewDecoder(w.Body).Decode(&job); err != nil {
+		t.Fatalf("decoding job: %v", err)
+	}
+	if job.Kind != "discover" || w.Header().Get("Location") != "/api/jobs/"+jsonInt64(job.ID) {
+		t.Errorf("job = %+v, Location = %q", job, w.Header().Get("Location"))
+	}
+
+	if err := runner.Drain(ctx); err != nil {
+		t.Fatalf("Drain() error: %v", err)
+	}
+	w = httptest.NewRecorder()
+	GetJob(runner).ServeHTTP(w, withURLParams(httptest.NewRequest(http.MethodGet, "/api/jobs/x", nil), "id", jsonInt64(job.ID)))
+	if w.Code != http.StatusOK {
+		t.Fatalf("GetJob: got status %d; body: %s", w.Code, w.Body.String())
+	}
+	if err := json.NewDecoder(w.Body).Decode(&job); err != nil {
+		t.Fatalf("decoding job: %v", err)
+	}
+	if job.Status != models.JobSucceeded {
+		t.Fatalf("job = %+v, want it to have succeeded", job)
+	}
+	var result DryRunResponse
+	if err := json.Unmarshal(job.Result, &result); err != nil {
+		t.Fatalf("decoding result: %v", err)
+	}
+	if !result.DryRun || len(result.Candidates) != 1 {
+		t.Errorf("result = %+v, want a dry run with the one feed post", result)
+	}
+
+	// Requests that can't run fail at once rather than as a job.
+	w = httptest.NewRecorder()
+	discover.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/discover", strings.NewReader(`{"difficulty": "hard"}`)))
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("invalid difficulty: got status %d, want %d", w.Code, http.StatusBadRequest)
+	}
+
+	// A dry run asked for in a body that doesn't parse must not become a
+	// real run.
+	for _, body := range []string{`{"dry_run": "true"}`, `{"dry_run": true`} {
+		w = httptest.NewRecorder()
+		discover.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/discover", strings.NewReader(body)))
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("%s: got status %d, want %d", body, w.Code, http.StatusBadRequest)
+		}
+	}
+	if queued, err := runner.List(ctx, storage.JobFilter{}); err != nil || len(queued) != 1 {
+		t.Errorf("jobs = %+v, %v; want only the first dry run", queued, err)
+	}
+
+	w = httptest.NewRecorder()
+	GetJob(runner).ServeHTTP(w, withURLParams(httptest.NewRequest(http.MethodGet, "/api/jobs/999", nil), "id", "999"))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("unknown job: got status %d, want %d", w.Code, http.StatusNotFound)
+	}
+}
+
+func TestListJobs(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+
+	runner := jobs.NewManager(store, 1)
+	runner.Register(jobs.Kind{Name: "a", Handler: func(ctx context.Context, _ json.RawMessage) (any, error) { return nil, nil }})
+	runner.Register(jobs.Kind{Name: "b", Handler: func(ctx context.Context, _ json.RawMessage) (any, error) { return nil, nil }})
+	for _, kind := range []string{"a", "b", "a"} {
+		if _, err := runner.Enqueue(ctx, kind, nil); err != nil {
+			t.Fatalf("Enqueue(%q) error: %v", kind, err)
+		}
+	}
+
+	tests := []struct {
+		query    string
+		wantCode int
+		wantLen  int
+	}{
+		{"", http.StatusOK, 3},
+		{"?kind=a", http.StatusOK, 2},
+		{"?status=queued&limit=1", http.StatusOK, 1},
+		{"?status=succeeded", http.StatusOK, 0},
+		{"?status=done", http.StatusBadRequest, 0},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		ListJobs(runner).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/jobs"+tt.query, nil))
+		if w.Code != tt.wantCode {
+			t.Errorf("%q: got status %d, want %d", tt.query, w.Code, tt.wantCode)
+			continue
+		}
+		if tt.wantCode != http.StatusOK {
+			continue
+		}
+		var list []models.Job
+		if err := json.NewDecoder(w.Body).Decode(&list); err != nil {
+			t.Fatalf("%q: decoding response: %v", tt.query, err)
+		}
+		if len(list) != tt.wantLen {
+			t.Errorf("%q: got %d jobs, want %d", tt.query, len(list), tt.wantLen)
+		}
+	}
+}
+
+func TestQueueDiscovery(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+
+	if err := store.SetPreference(ctx, "max_reading_minutes", 15); err != nil {
+		t.Fatalf("SetPreference: %v", err)
+	}
+	runner := jobs.NewManager(store, 1)
+	runner.Register(jobs.Kind{Name: "discover", Handler: func(ctx context.Context, _ json.RawMessage) (any, error) { return nil, nil }})
+
+	job, err := QueueDiscovery(ctx, store, runner)
+	if err != nil {
+		t.Fatalf("QueueDiscovery() error: %v", err)
+	}
+	var payload discoverPayload
+	if err := json.Unmarshal(job.Payload, &payload); err != nil {
+		t.Fatalf("decoding payload: %v", err)
+	}
+	if payload != (discoverPayload{MaxReadingMinutes: 15, Scheduled: true}) {
+		t.Errorf("payload = %+v, want a normal scheduled run under the 15-minute preference", payload)
+	}
+}
+
+func TestRunDiscovery_Checks(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+	cfg := &config.Config{Server: config.ServerConfig{DiscoveryTimeoutSeconds: 30}}
+
+	if _, err := RunDiscovery(ctx, store, nil, feeds.NewFetcher(nil), cfg); err == nil || !strings.Contains(err.Error(), "AI provider not configured") {
+		t.Errorf("without a provider: error = %v", err)
+	}
+	if _, err := RunDiscovery(ctx, store, &stubAIProvider{}, feeds.NewFetcher(nil), cfg); err == nil || !strings.Contains(err.Error(), "No preferences set") {
+		t.Errorf("without topics: error = %v", err)
+	}
+}
+
+// slowSummarizer summarizes the first post at once and blocks on the rest
+// until the context ends.
+type slowSummarizer struct {
+	stubAIProvider
+	calls int
+}
+
+func (p *slowSummarizer) Summarize(ctx context.Context, _ ai.BlogEntry) (ai.Summary, error) {
+	p.calls++
+	if p.calls == 1 {
+		return ai.Summary{Text: "Quick summary."}, nil
+	}
+	<-ctx.Done()
+	return ai.Summary{}, ctx.Err()
+}
+
+func TestRunDiscovery_Budget(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+
+	// Posts link to a closed port, so extracting them fails at once.
+	feed := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/slow" {
+			<-r.Context().Done()
+			return
+		}
+		w.Header().Set("Content-Type", "application/rss+xml")
+		_, _ = w.Write([]byte(`<?xml version="1.0"?><rss version="2.0"><channel><title>Test</title>
+			<item><title>Consensus in practice</title><link>http://localhost:1/raft</link></item>
+			<item><title>Paxos made simple</title><link>http://localhost:1/paxos</link></item>
+			</channel></rss>`))
+	}))
+	defer feed.Close()
+
+	if err := store.SetPreference(ctx, "topics", "distributed systems"); err != nil {
+		t.Fatalf("SetPreference: %v", err)
+	}
+	if _, err := store.DB().ExecContext(ctx, `UPDATE blog_sources SET is_active = 0`); err != nil {
+		t.Fatalf("deactivating sources: %v", err)
+	}
+	var ids []int64
+	rows, err := store.DB().QueryContext(ctx, `SELECT id FROM blog_sources WHERE feed_url != 'custom://user-added' ORDER BY id LIMIT 2`)
+	if err != nil {
+		t.Fatalf("listing sources: %v", err)
+	}
+	for rows.Next() {
+		var id int64
+		_ = rows.Scan(&id)
+		ids = append(ids, id)
+	}
+	rows.Close()
+	if _, err := store.DB().ExecContext(ctx, `UPDATE blog_sources SET is_active = 1, feed_url = ? WHERE id = ?`, feed.URL, ids[0]); err != nil {
+		t.Fatalf("pointing a source at the test feed: %v", err)
+	}
+
+	cfg := &config.Config{
+		AI:     config.AIConfig{Model: "test-model"},
+		Feeds:  config.FeedsConfig{MaxArticlesPerFeed: 10, LookbackDays: 30},
+		Server: config.ServerConfig{DiscoveryTimeoutSeconds: 30, DiscoveryBudgetSeconds: 1},
+	}
+
+	// A summary that outlasts the budget is left out.
+	start := time.Now()
+	resp, err := RunDiscovery(ctx, store, &slowSummarizer{}, feeds.NewFetcher(nil), cfg)
+	if err != nil {
+		t.Fatalf("RunDiscovery() error: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 10*time.Second {
+		t.Errorf("RunDiscovery() took %v, want it to stop near the 1s budget", elapsed)
+	}
+	if !resp.Partial || len(resp.Results) != 1 || resp.Results[0].Summary != "Quick summary." {
+		t.Errorf("response = %+v, want the one summarized post, marked partial", resp)
+	}
+	if resp.SessionID == 0 {
+		t.Error("partial results were not recorded as a session")
+	}
+
+	// A feed that outlasts the budget is reported as failed. A different
+	// host name keeps the fetcher's per-host rate limit out of the way.
+	slowURL := strings.Replace(feed.URL, "127.0.0.1", "localhost", 1) + "/slow"
+	if _, err := store.DB().ExecContext(ctx, `UPDATE blog_sources SET is_active = 1, feed_url = ? WHERE id = ?`, slowURL, ids[1]); err != nil {
+		t.Fatalf("pointing a source at the slow feed: %v", err)
+	}
+	resp, err = RunDiscovery(ctx, store, &stubAIProvider{}, feeds.NewFetcher(nil), cfg)
+	if err != nil {
+		t.Fatalf("RunDiscovery() error: %v", err)
+	}
+	if !resp.Partial || len(resp.FailedFeeds) != 1 {
+		t.Errorf("response = %+v, want the slow feed failed, marked partial", resp)
+	}
+}
+
+// failingRanker is an AI provider whose ranking always fails.
+type failingRanker struct {
+	stubAIProvider
+}
+
+func (p *failingRanker) FilterAndRank(context.Context, string, []ai.BlogEntry, int, bool) ([]ai.RankedBlog, error) {
+	return nil, errors.New("unexpected end of JSON input")
+}
+
+func TestRunDiscovery_FallbackRanking(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+
+	feed := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/rss+xml")
+		_, _ = w.Write([]byte(`<?xml version="1.0"?><rss version="2.0"><channel><title>Test</title>
+			<item><title>Team offsite recap</title><link>http://localhost:1/offsite</link></item>
+			<item><title>Consensus in distributed systems</title><link>http://localhost:1/raft</link></item>
+			</channel></rss>`))
+	}))
+	defer feed.Close()
+
+	if err := store.SetPreference(ctx, "topics", "distributed systems"); err != nil {
+		t.Fatalf("SetPreference: %v", err)
+	}
+	if _, err := store.DB().ExecContext(ctx, `UPDATE blog_sources SET is_active = 0`); err != nil {
+		t.Fatalf("deactivating sources: %v", err)
+	}
+	if _, err := store.DB().ExecContext(ctx,
+		`UPDATE blog_sources SET is_active = 1, feed_url = ? WHERE id = (SELECT MIN(id) FROM blog_sources WHERE feed_url != 'custom://user-added')`,
+		feed.URL); err != nil {
+		t.Fatalf("pointing a source at the test feed: %v", err)
+	}
+
+	cfg := &config.Config{
+		AI:     config.AIConfig{Model: "test-model"},
+		Feeds:  config.FeedsConfig{MaxArticlesPerFeed: 10, LookbackDays: 30},
+		Server: config.ServerConfig{DiscoveryTimeoutSeconds: 30},
+	}
+	resp, err := RunDiscovery(ctx, store, &failingRanker{}, feeds.NewFetcher(nil), cfg)
+	if err != nil {
+		t.Fatalf("RunDiscovery() error: %v", err)
+	}
+	if !resp.Fallback || len(resp.Results) != 2 || resp.Results[0].Title != "Consensus in distributed systems" {
+		t.Fatalf("response = %+v, want both posts, the matching one first, marked fallback", resp)
+	}
+
+	latest, err := LatestDiscovery(ctx, store)
+	if err != nil || !latest.Fallback || latest.SessionID != resp.SessionID {
+		t.Errorf("LatestDiscovery() = %+v, %v; want the session marked fallback", latest, err)
+	}
+}
+
+func TestDiscover_RetryFailed(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+
+	feed := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/rss+xml")
+		_, _ = w.Write([]byte(`<?xml version="1.0"?><rss version="2.0"><channel><title>Test</title>
+			<item><title>Back online</title><link>http://localhost:1/back</link></item>
+			</channel></rss>`))
+	}))
+	defer feed.Close()
+
+	if err := store.SetPreference(ctx, "topics", "distributed systems"); err != nil {
+		t.Fatalf("SetPreference: %v", err)
+	}
+	var name string
+	if err := store.DB().QueryRowContext(ctx,
+		`SELECT name FROM blog_sources WHERE id = (SELECT MIN(id) FROM blog_sources WHERE feed_url != 'custom://user-added')`,
+	).Scan(&name); err != nil {
+		t.Fatalf("finding a source: %v", err)
+	}
+	if _, err := store.DB().ExecContext(ctx, `UPDATE blog_sources SET feed_url = ? WHERE name = ?`, feed.URL, name); err != nil {
+		t.Fatalf("pointing a source at the test feed: %v", err)
+	}
+
+	// A past run found one post, and the source's feed and another failed.
+	failedJSON, _ := json.Marshal([]feeds.FailedFeed{{Source: name, Error: "timeout"}, {Source: "Gone Blog", Error: "404"}})
+	sessionID, err := store.CreateSession(ctx, &models.DiscoverySession{
+		PreferencesSnapshot: "distributed systems",
+		BlogsSelected:       "[1000]",
+		ResultsJSON:         `[{"id": 1000, "title": "Earlier post"}]`,
+		FailedFeedsJSON:     string(failedJSON),
+	})
+	if err != nil {
+		t.Fatalf("CreateSession: %v", err)
+	}
+
+	cfg := &config.Config{
+		AI:     config.AIConfig{Model: "test-model"},
+		Feeds:  config.FeedsConfig{MaxArticlesPerFeed: 10, LookbackDays: 30},
+		Server: config.ServerConfig{DiscoveryTimeoutSeconds: 30},
+	}
+	result, err := discover(ctx, store, &stubAIProvider{}, feeds.NewFetcher(nil), cfg, discoverPayload{RetryFailed: sessionID})
+	if err != nil {
+		t.Fatalf("discover() error: %v", err)
+	}
+	resp := result.(DiscoverResponse)
+	if len(resp.Results) != 2 || resp.Results[0].Title != "Earlier post" || resp.Results[1].Title != "Back online" {
+		t.Errorf("results = %+v, want the earlier post then the retried feed's", resp.Results)
+	}
+	if len(resp.FailedFeeds) != 1 || resp.FailedFeeds[0].Source != "Gone Blog" {
+		t.Errorf("failed feeds = %+v, want only the feed not retried", resp.FailedFeeds)
+	}
+	if resp.SessionID == sessionID {
+		t.Error("want the merged results recorded as a new session")
+	}
+
+	// The merged session has nothing left to retry that is a source.
+	if _, err := discover(ctx, store, &stubAIProvider{}, feeds.NewFetcher(nil), cfg, discoverPayload{RetryFailed: resp.SessionID}); !errors.Is(err, errNothingToRetry) {
+		t.Errorf("retrying the merged session: error = %v, want errNothingToRetry", err)
+	}
+}
+
+func TestDiscover_SinceLast(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+
+	var published atomic.Bool
+	feed := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		items := `<item><title>Old news</title><link>http://localhost:1/old</link></item>`
+		if published.Load() {
+			items += `<item><title>Fresh post</title><link>http://localhost:1/fresh</link></item>`
+		}
+		w.Header().Set("Content-Type", "application/rss+xml")
+		_, _ = w.Write([]byte(`<?xml version="1.0"?><rss version="2.0"><channel><title>Test</title>` + items + `</channel></rss>`))
+	}))
+	defer feed.Close()
+
+	if err := store.SetPreference(ctx, "topics", "distributed systems"); err != nil {
+		t.Fatalf("SetPreference: %v", err)
+	}
+	if _, err := store.DB().ExecContext(ctx, `UPDATE blog_sources SET is_active = 0`); err != nil {
+		t.Fatalf("deactivating sources: %v", err)
+	}
+	if _, err := store.DB().ExecContext(ctx,
+		`UPDATE blog_sources SET is_active = 1, feed_url = ? WHERE id = (SELECT MIN(id) FROM blog_sources WHERE feed_url != 'custom://user-added')`,
+		feed.URL); err != nil {
+		t.Fatalf("pointing a source at the test feed: %v", err)
+	}
+
+	cfg := &config.Config{
+		AI:     config.AIConfig{Model: "test-model"},
+		Feeds:  config.FeedsConfig{MaxArticlesPerFeed: 10, LookbackDays: 30},
+		Server: config.ServerConfig{DiscoveryTimeoutSeconds: 30},
+	}
+	run := func() DiscoverResponse {
+		t.Helper()
+		result, err := discover(ctx, store, &stubAIProvider{}, feeds.NewFetcher(nil), cfg, discoverPayload{SinceLast: true})
+		if err != nil {
+			t.Fatalf("discover() error: %v", err)
+		}
+		return result.(DiscoverResponse)
+	}
+
+	// With no earlier session, every post is a candidate.
+	if resp := run(); len(resp.Results) != 1 {
+		t.Fatalf("first run results = %+v, want the one post", resp.Results)
+	}
+
+	// Back-date the session, as if it ran yesterday, and publish a post.
+	if _, err := store.DB().ExecContext(ctx, `UPDATE blogs SET fetched_at = datetime('now', '-2 days')`); err != nil {
+		t.Fatalf("back-dating posts: %v", err)
+	}
+	if _, err := store.DB().ExecContext(ctx, `UPDATE discovery_sessions SET created_at = datetime('now', '-1 day')`); err != nil {
+		t.Fatalf("back-dating the session: %v", err)
+	}
+	published.Store(true)
+
+	resp := run()
+	if len(resp.Results) != 1 || resp.Results[0].Title != "Fresh post" {
+		t.Errorf("second run results = %+v, want only the new post", resp.Results)
+	}
+}
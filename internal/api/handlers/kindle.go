@@ -0,0 +1,233 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/hoanghai1803/apricot/internal/config"
+	"github.com/hoanghai1803/apricot/internal/email"
+	"github.com/hoanghai1803/apricot/internal/feeds"
+	"github.com/hoanghai1803/apricot/internal/jobs"
+	"github.com/hoanghai1803/apricot/internal/kindle"
+	"github.com/hoanghai1803/apricot/internal/models"
+	"github.com/hoanghai1803/apricot/internal/storage"
+)
+
+const (
+	// kindleWeek is how far back a compilation looks for unread items.
+	kindleWeek = 7 * 24 * time.Hour
+
+	// kindleMaxArticles is the most articles sent in one document.
+	kindleMaxArticles = 30
+)
+
+// KindleResult is the result of a "kindle" job.
+type KindleResult struct {
+	Articles int    `json:"articles"`
+	Document string `json:"document,omitempty"` // file name, if sent
+
+	// Skipped lists the items left out because their article text could
+	// not be extracted.
+	Skipped []int64 `json:"skipped,omitempty"`
+}
+
+// kindlePayload is the payload of a "kindle" job. No IDs means the weekly
+// compilation.
+type kindlePayload struct {
+	IDs []int64 `json:"ids,omitempty"`
+}
+
+// SendItemToKindle handles POST /api/reading-list/{id}/kindle. It queues a
+// "kindle" job (see KindleJob) for the item and returns 202 Accepted with
+// the job.
+func SendItemToKindle(store storage.Store, mailer *email.Mailer, runner *jobs.Manager, cfg *config.Config) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		if mailer == nil || !cfg.Kindle.Enabled() {
+			writeError(w, http.StatusServiceUnavailable, "Send to Kindle is not configured. Add [email] and [kindle] sections to config.toml")
+			return
+		}
+		id, err := parseID(r, "id")
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		if _, err := store.GetReadingListItemByID(ctx, id); err != nil {
+			if errors.Is(err, storage.ErrNotFound) {
+				writeError(w, http.StatusNotFound, "Reading list item not found")
+				return
+			}
+			slog.ErrorContext(ctx, "failed to get reading list item", "id", id, "error", err)
+			writeError(w, http.StatusInternalServerError, "Failed to send to Kindle")
+			return
+		}
+
+		enqueueKindle(w, r, runner, kindlePayload{IDs: []int64{id}})
+	}
+}
+
+// SendToKindle handles POST /api/kindle. With a body of {"ids": [...]} it
+// sends those reading list items as one document; without one it sends the
+// weekly compilation. It queues a "kindle" job (see KindleJob) and returns
+// 202 Accepted with the job.
+func SendToKindle(mailer *email.Mailer, runner *jobs.Manager, cfg *config.Config) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if mailer == nil || !cfg.Kindle.Enabled() {
+			writeError(w, http.StatusServiceUnavailable, "Send to Kindle is not configured. Add [email] and [kindle] sections to config.toml")
+			return
+		}
+		var payload kindlePayload
+		if r.ContentLength != 0 {
+			if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+				writeError(w, http.StatusBadRequest, "Invalid JSON body")
+				return
+			}
+		}
+		if len(payload.IDs) > kindleMaxArticles {
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("At most %d items can be sent at once", kindleMaxArticles))
+			return
+		}
+
+		enqueueKindle(w, r, runner, payload)
+	}
+}
+
+// enqueueKindle queues a "kindle" job with payload and responds with it.
+func enqueueKindle(w http.ResponseWriter, r *http.Request, runner *jobs.Manager, payload kindlePayload) {
+	job, err := runner.Enqueue(r.Context(), "kindle", payload)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "failed to queue Kindle delivery", "error", err)
+		writeError(w, http.StatusInternalServerError, "Failed to send to Kindle")
+		return
+	}
+
+	w.Header().Set("Location", fmt.Sprintf("/api/jobs/%d", job.ID))
+	writeJSON(w, http.StatusAccepted, job)
+}
+
+// KindleJob returns the "kindle" job kind, which emails reading list items
+// to the Kindle address as one HTML document (see kindle.Render), which
+// Amazon converts into a book. Articles not extracted yet are extracted
+// first; items whose text cannot be had are left out. The weekly
+// compilation holds the unread items added in the past week, and is not
+// sent when there are none. A failed send is retried twice.
+func KindleJob(store storage.Store, fetcher *feeds.Fetcher, mailer *email.Mailer, cfg *config.Config) jobs.Kind {
+	return jobs.Kind{
+		Name:        "kindle",
+		Timeout:     5 * time.Minute,
+		MaxAttempts: 3,
+		Backoff:     5 * time.Minute,
+		Handler: func(ctx context.Context, payload json.RawMessage) (any, error) {
+			var p kindlePayload
+			if err := json.Unmarshal(payload, &p); err != nil {
+				return nil, jobs.Permanent(fmt.Errorf("decoding payload: %w", err))
+			}
+
+			now := time.Now()
+			ids, title := p.IDs, "Apricot, "+now.Format("January 2, 2006")
+			if len(ids) == 0 {
+				var err error
+				if ids, err = kindleCompilation(ctx, store, now); err != nil {
+					return nil, err
+				}
+				title = "Apricot, week of " + now.Add(-kindleWeek).Format("January 2, 2006")
+			}
+
+			var result KindleResult
+			var articles []kindle.Article
+			for _, id := range ids {
+				item, err := store.GetReadingListItemByID(ctx, id)
+				if errors.Is(err, storage.ErrNotFound) {
+					continue
+				}
+				if err != nil {
+					return nil, fmt.Errorf("loading item %d: %w", id, err)
+				}
+				if item.Blog == nil {
+					continue
+				}
+				if item.Blog.FullContent == "" && fetcher != nil {
+					extractContent(ctx, store, fetcher, item.Blog)
+				}
+				if item.Blog.FullContent == "" {
+					result.Skipped = append(result.Skipped, id)
+					continue
+				}
+				articles = append(articles, kindleArticle(item))
+			}
+			result.Articles = len(articles)
+			if len(articles) == 0 {
+				if len(p.IDs) == 0 {
+					slog.InfoContext(ctx, "no unread items for the Kindle compilation")
+					return result, nil
+				}
+				return nil, jobs.Permanent(errors.New("none of the items has article text to send"))
+			}
+			if len(p.IDs) == 1 {
+				title = articles[0].Title
+			}
+
+			result.Document = kindle.FileName(title)
+			msg := email.Message{
+				To:      []string{cfg.Kindle.Address},
+				Subject: title,
+				Text:    "Sent from Apricot.",
+				HTML:    "<p>Sent from Apricot.</p>",
+				Attachments: []email.Attachment{{
+					Name:        result.Document,
+					ContentType: "text/html",
+					Data:        kindle.Render(title, articles),
+				}},
+			}
+			if err := mailer.Send(ctx, msg); err != nil {
+				return nil, fmt.Errorf("sending to Kindle: %w", err)
+			}
+			slog.InfoContext(ctx, "sent to Kindle", "articles", len(articles), "document", result.Document)
+			return result, nil
+		},
+	}
+}
+
+// kindleCompilation returns the IDs of the unread items added in the week
+// before now, at most kindleMaxArticles of them, in reading list order.
+func kindleCompilation(ctx context.Context, store storage.Store, now time.Time) ([]int64, error) {
+	items, err := store.GetReadingListFiltered(ctx, storage.ReadingListFilter{
+		Status:         "unread",
+		Snoozed:        storage.SnoozeHide,
+		WithoutContent: true,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("loading reading list: %w", err)
+	}
+	var ids []int64
+	for _, item := range items {
+		if item.AddedAt.After(now.Add(-kindleWeek)) {
+			ids = append(ids, item.ID)
+		}
+		if len(ids) == kindleMaxArticles {
+			break
+		}
+	}
+	return ids, nil
+}
+
+// kindleArticle returns item as an article for a Kindle document.
+func kindleArticle(item *models.ReadingListItem) kindle.Article {
+	a := kindle.Article{
+		Title:       item.Blog.Title,
+		Source:      item.Blog.Source,
+		URL:         item.Blog.URL,
+		PublishedAt: item.Blog.PublishedAt,
+		Text:        item.Blog.FullContent,
+	}
+	if item.Blog.RewrittenTitle != "" {
+		a.Title = item.Blog.RewrittenTitle
+	}
+	return a
+}
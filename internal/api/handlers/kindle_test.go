@@ -0,0 +1,77 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hoanghai1803/apricot/internal/config"
+	"github.com/hoanghai1803/apricot/internal/email"
+	"github.com/hoanghai1803/apricot/internal/jobs"
+	"github.com/hoanghai1803/apricot/internal/models"
+)
+
+func TestKindleCompilation(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+	var ids []int64
+	for _, u := range []string{"https://a.example/1", "https://a.example/2"} {
+		blogID, err := store.UpsertBlog(ctx, &models.Blog{SourceID: 1, Title: u, URL: u, FetchedAt: time.Now()})
+		if err != nil {
+			t.Fatalf("UpsertBlog: %v", err)
+		}
+		if err := store.AddToReadingList(ctx, blogID); err != nil {
+			t.Fatalf("AddToReadingList: %v", err)
+		}
+		id, err := store.GetReadingListIDByBlogID(ctx, blogID)
+		if err != nil {
+			t.Fatalf("GetReadingListIDByBlogID: %v", err)
+		}
+		ids = append(ids, id)
+	}
+	if err := store.UpdateReadingListStatus(ctx, ids[0], "read"); err != nil {
+		t.Fatalf("UpdateReadingListStatus: %v", err)
+	}
+
+	got, err := kindleCompilation(ctx, store, time.Now())
+	if err != nil {
+		t.Fatalf("kindleCompilation() error: %v", err)
+	}
+	if len(got) != 1 || got[0] != ids[1] {
+		t.Errorf("kindleCompilation() = %v, want only the unread item %d", got, ids[1])
+	}
+	if got, _ := kindleCompilation(ctx, store, time.Now().Add(2*kindleWeek)); len(got) != 0 {
+		t.Errorf("kindleCompilation() a fortnight later = %v, want none", got)
+	}
+}
+
+func TestSendToKindle(t *testing.T) {
+	store := newTestStore(t)
+	runner := jobs.NewManager(store, 1)
+	mailer := &email.Mailer{Host: "smtp.example.com", Port: 587, From: "a@example.com"}
+	cfg := &config.Config{Kindle: config.KindleConfig{Address: "me@kindle.com"}}
+
+	w := httptest.NewRecorder()
+	SendToKindle(nil, runner, cfg).ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/kindle", nil))
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("without email: status %d, want %d", w.Code, http.StatusServiceUnavailable)
+	}
+
+	ids := "[" + strings.TrimSuffix(strings.Repeat("1,", kindleMaxArticles+1), ",") + "]"
+	w = httptest.NewRecorder()
+	SendToKindle(mailer, runner, cfg).ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/kindle", strings.NewReader(`{"ids":`+ids+`}`)))
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("too many items: status %d, want %d", w.Code, http.StatusBadRequest)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/api/reading-list/99/kindle", nil)
+	req = withURLParams(req, "id", "99")
+	w = httptest.NewRecorder()
+	SendItemToKindle(store, mailer, runner, cfg).ServeHTTP(w, req)
+	if w.Code != http.StatusNotFound {
+		t.Errorf("missing item: status %d, want %d", w.Code, http.StatusNotFound)
+	}
+}
@@ -0,0 +1,42 @@
+package handlers
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/hoanghai1803/apricot/internal/storage"
+)
+
+// GetDBStats handles GET /api/admin/db-stats. It returns the database size,
+// the size of SQLite's write-ahead log and free pages, row counts per table,
+// and when the database was last vacuumed and maintained.
+func GetDBStats(store storage.Store) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		stats, err := store.DBStats(r.Context())
+		if err != nil {
+			slog.ErrorContext(r.Context(), "failed to get database stats", "error", err)
+			writeError(w, http.StatusInternalServerError, "Failed to get database stats")
+			return
+		}
+
+		writeJSON(w, http.StatusOK, stats)
+	}
+}
+
+// RunMaintenance handles POST /api/admin/maintenance. It rebuilds the
+// search index, vacuums, and optimizes the database, and returns the steps
+// run with the database size before and after.
+func RunMaintenance(store storage.Store) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		result, err := store.RunMaintenance(r.Context())
+		if err != nil {
+			slog.ErrorContext(r.Context(), "failed to run database maintenance", "error", err)
+			writeError(w, http.StatusInternalServerError, "Failed to run database maintenance")
+			return
+		}
+
+		slog.InfoContext(r.Context(), "ran database maintenance", "steps", result.Steps,
+			"bytes_before", result.SizeBeforeBytes, "bytes_after", result.SizeAfterBytes)
+		writeJSON(w, http.StatusOK, result)
+	}
+}
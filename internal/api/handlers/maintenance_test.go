@@ -0,0 +1,48 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/hoanghai1803/apricot/internal/models"
+)
+
+func TestGetDBStats(t *testing.T) {
+	store := newTestStore(t)
+
+	r := httptest.NewRequest(http.MethodGet, "/api/admin/db-stats", nil)
+	w := httptest.NewRecorder()
+	GetDBStats(store).ServeHTTP(w, r)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status %d, want %d; body: %s", w.Code, http.StatusOK, w.Body.String())
+	}
+	var stats models.DBStats
+	if err := json.NewDecoder(w.Body).Decode(&stats); err != nil {
+		t.Fatalf("decoding response: %v", err)
+	}
+	if stats.Driver != "sqlite" || stats.SizeBytes == 0 || len(stats.Tables) == 0 {
+		t.Errorf("stats = %+v, want sqlite with size and tables", stats)
+	}
+}
+
+func TestRunMaintenance(t *testing.T) {
+	store := newTestStore(t)
+
+	r := httptest.NewRequest(http.MethodPost, "/api/admin/maintenance", nil)
+	w := httptest.NewRecorder()
+	RunMaintenance(store).ServeHTTP(w, r)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status %d, want %d; body: %s", w.Code, http.StatusOK, w.Body.String())
+	}
+	var result models.MaintenanceResult
+	if err := json.NewDecoder(w.Body).Decode(&result); err != nil {
+		t.Fatalf("decoding response: %v", err)
+	}
+	if len(result.Steps) == 0 {
+		t.Error("maintenance ran no steps")
+	}
+}
@@ -0,0 +1,24 @@
+package handlers
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/hoanghai1803/apricot/internal/storage"
+)
+
+// GetMigrationStatus handles GET /api/admin/migrations. It lists the schema
+// migrations this build knows about, which of them are applied, and whether
+// each can be rolled back with the -rollback-to flag.
+func GetMigrationStatus(store storage.Store) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		status, err := store.MigrationStatus(r.Context())
+		if err != nil {
+			slog.ErrorContext(r.Context(), "failed to get migration status", "error", err)
+			writeError(w, http.StatusInternalServerError, "Failed to get migration status")
+			return
+		}
+
+		writeJSON(w, http.StatusOK, status)
+	}
+}
@@ -0,0 +1,31 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/hoanghai1803/apricot/internal/models"
+)
+
+func TestGetMigrationStatus(t *testing.T) {
+	store := newTestStore(t)
+
+	r := httptest.NewRequest(http.MethodGet, "/api/admin/migrations", nil)
+	w := httptest.NewRecorder()
+	GetMigrationStatus(store).ServeHTTP(w, r)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status %d, want %d; body: %s", w.Code, http.StatusOK, w.Body.String())
+	}
+	var status models.MigrationStatus
+	if err := json.NewDecoder(w.Body).Decode(&status); err != nil {
+		t.Fatalf("decoding response: %v", err)
+	}
+	if status.Pending != 0 || len(status.Migrations) == 0 ||
+		status.Version != status.Migrations[len(status.Migrations)-1].Version {
+		t.Errorf("status = version %d, %d pending, %d migrations; want all applied",
+			status.Version, status.Pending, len(status.Migrations))
+	}
+}
@@ -0,0 +1,99 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+
+	"github.com/hoanghai1803/apricot/internal/notify"
+	"github.com/hoanghai1803/apricot/internal/storage"
+)
+
+// ItemEvent is the data of item.added and item.finished events.
+type ItemEvent struct {
+	ItemID int64  `json:"item_id"`
+	BlogID int64  `json:"blog_id"`
+	Title  string `json:"title"`
+	URL    string `json:"url"`
+	Source string `json:"source"`
+	Status string `json:"status"`
+}
+
+// notifyItem emits event for reading list item id, if anyone wants it.
+func notifyItem(ctx context.Context, store storage.Store, notifier *notify.Notifier, event string, id int64) {
+	if !notifier.Wants(event) {
+		return
+	}
+	item, err := store.GetReadingListItemByID(ctx, id)
+	if err != nil {
+		slog.WarnContext(ctx, "failed to load item for event", "event", event, "id", id, "error", err)
+		return
+	}
+	data := ItemEvent{ItemID: item.ID, BlogID: item.BlogID, Status: item.Status}
+	if item.Blog != nil {
+		data.Title, data.URL, data.Source = item.Blog.Title, item.Blog.URL, item.Blog.Source
+	}
+	notifier.Emit(ctx, event, data)
+}
+
+// notifyAdded emits item.added for the reading list item of blogID.
+func notifyAdded(ctx context.Context, store storage.Store, notifier *notify.Notifier, blogID int64) {
+	if !notifier.Wants(notify.EventItemAdded) {
+		return
+	}
+	id, err := store.GetReadingListIDByBlogID(ctx, blogID)
+	if err != nil {
+		slog.WarnContext(ctx, "failed to find item for event", "blog_id", blogID, "error", err)
+		return
+	}
+	notifyItem(ctx, store, notifier, notify.EventItemAdded, id)
+}
+
+// unfinished returns those of ids whose items are not marked read yet, if
+// anyone wants item.finished events. Call it before marking items read, and
+// pass the result to notifyFinished after, so an item is only announced
+// the first time it is finished.
+func unfinished(ctx context.Context, store storage.Store, notifier *notify.Notifier, ids ...int64) []int64 {
+	if !notifier.Wants(notify.EventItemFinished) {
+		return nil
+	}
+	var out []int64
+	for _, id := range ids {
+		item, err := store.GetReadingListItemByID(ctx, id)
+		if err == nil && item.Status != "read" {
+			out = append(out, id)
+		}
+	}
+	return out
+}
+
+// notifyFinished emits item.finished for each of ids.
+func notifyFinished(ctx context.Context, store storage.Store, notifier *notify.Notifier, ids []int64) {
+	for _, id := range ids {
+		notifyItem(ctx, store, notifier, notify.EventItemFinished, id)
+	}
+}
+
+// SaveFromChat returns the notify.SaveFunc chat commands use to add a post
+// they were sent to the reading list. It emits item.added.
+func SaveFromChat(store storage.Store, notifier *notify.Notifier) notify.SaveFunc {
+	return func(ctx context.Context, url string) (string, error) {
+		blog, err := store.GetBlogByURL(ctx, url)
+		if errors.Is(err, storage.ErrNotFound) {
+			return "", errors.New("that post is no longer in Apricot")
+		}
+		if err != nil {
+			slog.ErrorContext(ctx, "failed to look up post from chat", "url", url, "error", err)
+			return "", errors.New("failed to look up the post")
+		}
+		if err := store.AddToReadingList(ctx, blog.ID); err != nil {
+			if errors.Is(err, storage.ErrAlreadyOnList) {
+				return "", errors.New("already on your reading list")
+			}
+			slog.ErrorContext(ctx, "failed to add post from chat", "blog_id", blog.ID, "error", err)
+			return "", errors.New("failed to add to the reading list")
+		}
+		notifyAdded(ctx, store, notifier, blog.ID)
+		return blog.Title, nil
+	}
+}
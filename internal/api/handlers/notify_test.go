@@ -0,0 +1,88 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/hoanghai1803/apricot/internal/jobs"
+	"github.com/hoanghai1803/apricot/internal/notify"
+	"github.com/hoanghai1803/apricot/internal/storage"
+)
+
+func TestReadingListEvents(t *testing.T) {
+	store := newTestStore(t)
+	runner := jobs.NewManager(store, 1)
+	var events []notify.Event
+	hook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var e notify.Event
+		json.NewDecoder(r.Body).Decode(&e) //nolint:errcheck
+		events = append(events, e)
+	}))
+	defer hook.Close()
+	notifier := notify.New(runner, notify.Webhook{URL: hook.URL, Events: []string{notify.EventItemAdded, notify.EventItemFinished}})
+	runner.Register(notifier.Job())
+	ctx := context.Background()
+
+	blogID := seedBlog(t, store)
+	body, _ := json.Marshal(map[string]int64{"blog_id": blogID})
+	w := httptest.NewRecorder()
+	AddToReadingList(store, notifier).ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/reading-list", bytes.NewReader(body)))
+	if w.Code != http.StatusCreated {
+		t.Fatalf("POST got status %d, want %d", w.Code, http.StatusCreated)
+	}
+	itemID, err := store.GetReadingListIDByBlogID(ctx, blogID)
+	if err != nil {
+		t.Fatalf("GetReadingListIDByBlogID() error: %v", err)
+	}
+
+	// Scrolling past 90% twice finishes the item only once.
+	for _, progress := range []int{95, 100} {
+		r := httptest.NewRequest(http.MethodPatch, "/api/reading-list/1/progress", bytes.NewBufferString(`{"progress": `+jsonInt64(int64(progress))+`}`))
+		w := httptest.NewRecorder()
+		UpdateReadingProgress(store, notifier).ServeHTTP(w, withURLParams(r, "id", jsonInt64(itemID)))
+		if w.Code != http.StatusOK {
+			t.Fatalf("PATCH progress got status %d, want %d", w.Code, http.StatusOK)
+		}
+	}
+
+	if err := runner.Drain(ctx); err != nil {
+		t.Fatalf("Drain() error: %v", err)
+	}
+	if len(events) != 2 || events[0].Type != notify.EventItemAdded || events[1].Type != notify.EventItemFinished {
+		t.Fatalf("events = %+v, want item.added then item.finished", events)
+	}
+	var data ItemEvent
+	if err := json.Unmarshal(events[1].Data, &data); err != nil {
+		t.Fatalf("decoding event data: %v", err)
+	}
+	if data.ItemID != itemID || data.URL != "https://example.com/test-post" || data.Status != "read" {
+		t.Errorf("item.finished data = %+v, want the finished item", data)
+	}
+
+	jobsRun, _ := runner.List(ctx, storage.JobFilter{Kind: "notify"})
+	if len(jobsRun) != 2 {
+		t.Errorf("queued %d webhook jobs, want 2", len(jobsRun))
+	}
+}
+
+func TestSaveFromChat(t *testing.T) {
+	store := newTestStore(t)
+	save := SaveFromChat(store, nil)
+	ctx := context.Background()
+	seedBlog(t, store)
+
+	title, err := save(ctx, "https://example.com/test-post")
+	if err != nil || title == "" {
+		t.Fatalf("save() = %q, %v; want the post's title", title, err)
+	}
+	if _, err := save(ctx, "https://example.com/test-post"); err == nil {
+		t.Error("saving the post twice: want an error")
+	}
+	if _, err := save(ctx, "https://example.com/unknown"); err == nil {
+		t.Error("saving an unknown post: want an error")
+	}
+}
@@ -0,0 +1,272 @@
+package handlers
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hoanghai1803/apricot/internal/config"
+	"github.com/hoanghai1803/apricot/internal/jobs"
+	"github.com/hoanghai1803/apricot/internal/models"
+	"github.com/hoanghai1803/apricot/internal/notion"
+	"github.com/hoanghai1803/apricot/internal/storage"
+)
+
+// NotionResult is the result of a "notion" job.
+type NotionResult struct {
+	Created   int `json:"created"`
+	Updated   int `json:"updated"`
+	Unchanged int `json:"unchanged"`
+	Archived  int `json:"archived"`
+
+	// Missing lists the mapped properties that the database does not have,
+	// which were not written.
+	Missing []string `json:"missing,omitempty"`
+}
+
+// ExportNotion handles POST /api/admin/notion. It queues a "notion" job
+// (see NotionJob) and returns 202 Accepted with the job. client is nil
+// when Notion is not configured.
+func ExportNotion(client *notion.Client, runner *jobs.Manager) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if client == nil {
+			writeError(w, http.StatusServiceUnavailable, "Notion is not configured. Add a [notion] section to config.toml")
+			return
+		}
+
+		job, err := runner.Enqueue(r.Context(), "notion", struct{}{})
+		if err != nil {
+			slog.ErrorContext(r.Context(), "failed to queue Notion export", "error", err)
+			writeError(w, http.StatusInternalServerError, "Failed to start Notion export")
+			return
+		}
+
+		w.Header().Set("Location", fmt.Sprintf("/api/jobs/%d", job.ID))
+		writeJSON(w, http.StatusAccepted, job)
+	}
+}
+
+// NotionJob returns the "notion" job kind, which keeps a page in the
+// configured Notion database for every reading list item, with the fields
+// mapped in [notion.properties]. A page is updated only when its item
+// changed, and archived when its item is removed. Exports run one at a
+// time. A failed export is retried twice, picking up where it stopped,
+// unless Notion refused the token or a property has a type the field
+// cannot be written to.
+func NotionJob(store storage.Store, client *notion.Client, cfg *config.Config) jobs.Kind {
+	var mu sync.Mutex
+	return jobs.Kind{
+		Name:        "notion",
+		Timeout:     30 * time.Minute, // Notion allows about three requests a second
+		MaxAttempts: 3,
+		Backoff:     5 * time.Minute,
+		Handler: func(ctx context.Context, _ json.RawMessage) (any, error) {
+			mu.Lock()
+			defer mu.Unlock()
+
+			result, err := exportNotion(ctx, store, client, cfg.Notion)
+			if errors.Is(err, notion.ErrUnauthorized) || errors.Is(err, notion.ErrNotFound) {
+				return nil, jobs.Permanent(err)
+			}
+			if err != nil {
+				return nil, err
+			}
+			if len(result.Missing) > 0 {
+				slog.WarnContext(ctx, "Notion database is missing mapped properties", "properties", result.Missing)
+			}
+			slog.InfoContext(ctx, "exported reading list to Notion", "created", result.Created,
+				"updated", result.Updated, "unchanged", result.Unchanged, "archived", result.Archived)
+			return result, nil
+		},
+	}
+}
+
+// exportNotion brings the pages in cfg's database in line with the
+// reading list. ErrNotFound means the database itself is missing; pages
+// deleted in Notion are created again.
+func exportNotion(ctx context.Context, store storage.Store, client *notion.Client, cfg config.NotionConfig) (NotionResult, error) {
+	var result NotionResult
+	db, err := client.Database(ctx, cfg.DatabaseID)
+	if err != nil {
+		return result, fmt.Errorf("reading the database: %w", err)
+	}
+	mapping, missing := notionMapping(db, cfg.Properties)
+	result.Missing = missing
+
+	items, err := store.GetReadingListFiltered(ctx, storage.ReadingListFilter{WithoutContent: true})
+	if err != nil {
+		return result, fmt.Errorf("loading reading list: %w", err)
+	}
+	existing, err := store.ListNotionPages(ctx, cfg.DatabaseID)
+	if err != nil {
+		return result, err
+	}
+	pages := make(map[int64]models.NotionPage, len(existing))
+	for _, p := range existing {
+		pages[p.ReadingListID] = p
+	}
+
+	for _, item := range items {
+		if item.Blog == nil {
+			continue
+		}
+		props, err := notionProperties(item, db, mapping)
+		if err != nil {
+			return result, jobs.Permanent(err)
+		}
+		hash, err := notionHash(props)
+		if err != nil {
+			return result, err
+		}
+
+		page, ok := pages[item.ID]
+		delete(pages, item.ID)
+		if ok && page.URL != item.Blog.URL {
+			// The item ID was reused for another post.
+			if err := archiveNotionPage(ctx, store, client, page); err != nil {
+				return result, err
+			}
+			result.Archived++
+			ok = false
+		}
+		switch {
+		case ok && page.Hash == hash:
+			result.Unchanged++
+			continue
+		case ok:
+			err := client.UpdatePage(ctx, page.PageID, props)
+			if errors.Is(err, notion.ErrNotFound) {
+				ok = false
+				break
+			}
+			if err != nil {
+				return result, fmt.Errorf("updating the page of item %d: %w", item.ID, err)
+			}
+			result.Updated++
+		}
+		if !ok {
+			id, err := client.CreatePage(ctx, cfg.DatabaseID, props)
+			if err != nil {
+				return result, fmt.Errorf("creating a page for item %d: %w", item.ID, err)
+			}
+			page = models.NotionPage{DatabaseID: cfg.DatabaseID, ReadingListID: item.ID, PageID: id}
+			result.Created++
+		}
+		page.URL, page.Hash = item.Blog.URL, hash
+		if err := store.SaveNotionPage(ctx, &page); err != nil {
+			return result, err
+		}
+	}
+
+	for _, page := range pages {
+		if err := archiveNotionPage(ctx, store, client, page); err != nil {
+			return result, err
+		}
+		result.Archived++
+	}
+	return result, nil
+}
+
+// archiveNotionPage archives the page of a removed item and forgets it.
+func archiveNotionPage(ctx context.Context, store storage.Store, client *notion.Client, page models.NotionPage) error {
+	err := client.ArchivePage(ctx, page.PageID)
+	if err != nil && !errors.Is(err, notion.ErrNotFound) {
+		return fmt.Errorf("archiving the page of item %d: %w", page.ReadingListID, err)
+	}
+	return store.DeleteNotionPage(ctx, page.DatabaseID, page.ReadingListID)
+}
+
+// notionMapping returns the database property to write each field to,
+// with the title in the database's title property unless the configuration
+// maps it. It also returns the mapped properties the database does not
+// have.
+func notionMapping(db *notion.Database, properties map[string]string) (map[string]string, []string) {
+	mapping := make(map[string]string)
+	var missing []string
+	if _, ok := properties["title"]; !ok {
+		mapping["title"] = db.TitleProperty()
+	}
+	for field, name := range properties {
+		if name == "" {
+			continue
+		}
+		if _, ok := db.Properties[name]; !ok {
+			missing = append(missing, name)
+			continue
+		}
+		mapping[field] = name
+	}
+	slices.Sort(missing)
+	return mapping, missing
+}
+
+// notionProperties returns the property values of item's page, given the
+// property each field goes to.
+func notionProperties(item models.ReadingListItem, db *notion.Database, mapping map[string]string) (notion.Properties, error) {
+	props := make(notion.Properties, len(mapping))
+	for field, name := range mapping {
+		typ := db.Properties[name]
+		var (
+			value any
+			err   error
+		)
+		switch field {
+		case "title":
+			title := item.Blog.Title
+			if item.Blog.RewrittenTitle != "" {
+				title = item.Blog.RewrittenTitle
+			}
+			value, err = notion.Text(typ, title)
+		case "url":
+			value, err = notion.Text(typ, item.Blog.URL)
+		case "source":
+			value, err = notion.Text(typ, item.Blog.Source)
+		case "status":
+			// Capitalized, as Notion's own options are.
+			status := item.Status
+			if status != "" {
+				status = strings.ToUpper(status[:1]) + status[1:]
+			}
+			value, err = notion.Text(typ, status)
+		case "summary":
+			var summary string
+			if item.Summary != nil {
+				summary = *item.Summary
+			}
+			value, err = notion.Text(typ, summary)
+		case "category":
+			value, err = notion.Text(typ, item.Category)
+		case "tags":
+			value, err = notion.List(typ, item.Tags)
+		case "added":
+			value, err = notion.Date(typ, &item.AddedAt)
+		case "read":
+			value, err = notion.Date(typ, item.ReadAt)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("notion property %q (%s): %w", name, field, err)
+		}
+		props[name] = value
+	}
+	return props, nil
+}
+
+// notionHash returns a hash of props, to tell whether a page needs
+// updating.
+func notionHash(props notion.Properties) (string, error) {
+	b, err := json.Marshal(props) // map keys are sorted
+	if err != nil {
+		return "", fmt.Errorf("encoding notion properties: %w", err)
+	}
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:8]), nil
+}
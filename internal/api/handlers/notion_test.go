@@ -0,0 +1,156 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/hoanghai1803/apricot/internal/config"
+	"github.com/hoanghai1803/apricot/internal/jobs"
+	"github.com/hoanghai1803/apricot/internal/models"
+	"github.com/hoanghai1803/apricot/internal/notion"
+)
+
+// fakeNotion serves the parts of the Notion API the export uses, for one
+// database, keeping its pages in memory.
+type fakeNotion struct {
+	pages    map[string]map[string]json.RawMessage
+	archived map[string]bool
+}
+
+func (f *fakeNotion) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch {
+	case r.Method == http.MethodGet && r.URL.Path == "/databases/db1":
+		w.Write([]byte(`{"properties":{"Name":{"type":"title"},"URL":{"type":"url"},
+			"Source":{"type":"select"},"Status":{"type":"select"},"Tags":{"type":"multi_select"}}}`))
+	case r.Method == http.MethodPost && r.URL.Path == "/pages":
+		var body struct {
+			Properties map[string]json.RawMessage `json:"properties"`
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		id := fmt.Sprintf("page%d", len(f.pages)+1)
+		f.pages[id] = body.Properties
+		fmt.Fprintf(w, `{"id":%q}`, id)
+	case r.Method == http.MethodPatch && strings.HasPrefix(r.URL.Path, "/pages/"):
+		id := strings.TrimPrefix(r.URL.Path, "/pages/")
+		if f.pages[id] == nil {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		var body struct {
+			Properties map[string]json.RawMessage `json:"properties"`
+			Archived   bool                       `json:"archived"`
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		for name, v := range body.Properties {
+			f.pages[id][name] = v
+		}
+		f.archived[id] = f.archived[id] || body.Archived
+		w.Write([]byte(`{}`))
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func TestExportNotion(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+	blogID := seedBlog(t, store)
+	if err := store.AddToReadingList(ctx, blogID); err != nil {
+		t.Fatalf("AddToReadingList: %v", err)
+	}
+	list, err := store.GetReadingList(ctx, "")
+	if err != nil || len(list) != 1 {
+		t.Fatalf("GetReadingList: %v, %d items", err, len(list))
+	}
+	itemID := list[0].ID
+
+	fake := &fakeNotion{pages: map[string]map[string]json.RawMessage{}, archived: map[string]bool{}}
+	srv := httptest.NewServer(fake)
+	defer srv.Close()
+
+	client := &notion.Client{Token: "secret", API: srv.URL, HTTP: srv.Client()}
+	cfg := &config.Config{Notion: config.NotionConfig{
+		Token:      "secret",
+		DatabaseID: "db1",
+		Properties: map[string]string{"url": "URL", "status": "Status", "summary": "Summary", "tags": ""},
+	}}
+	runner := jobs.NewManager(store, 1)
+	runner.Register(NotionJob(store, client, cfg))
+
+	export := func() NotionResult {
+		t.Helper()
+		w := httptest.NewRecorder()
+		ExportNotion(client, runner).ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/admin/notion", nil))
+		if w.Code != http.StatusAccepted {
+			t.Fatalf("status %d, want %d; body: %s", w.Code, http.StatusAccepted, w.Body.String())
+		}
+		var job models.Job
+		if err := json.NewDecoder(w.Body).Decode(&job); err != nil {
+			t.Fatalf("decoding response: %v", err)
+		}
+		if err := runner.Drain(ctx); err != nil {
+			t.Fatalf("Drain() error: %v", err)
+		}
+		done, err := runner.Get(ctx, job.ID)
+		if err != nil || done.Status != models.JobSucceeded {
+			t.Fatalf("notion job = %+v, %v; want it to have succeeded", done, err)
+		}
+		var result NotionResult
+		if err := json.Unmarshal(done.Result, &result); err != nil {
+			t.Fatalf("decoding result: %v", err)
+		}
+		return result
+	}
+
+	res := export()
+	if res.Created != 1 || len(res.Missing) != 1 || res.Missing[0] != "Summary" {
+		t.Errorf("first export = %+v, want 1 created and Summary missing", res)
+	}
+	page := fake.pages["page1"]
+	if string(page["Name"]) != `{"title":[{"text":{"content":"Test Blog Post"}}]}` ||
+		string(page["URL"]) != `{"url":"https://example.com/test-post"}` ||
+		string(page["Status"]) != `{"select":{"name":"Unread"}}` {
+		t.Errorf("page properties = %s", page)
+	}
+	if _, ok := page["Tags"]; ok {
+		t.Error("Tags was written, though mapped to nothing")
+	}
+
+	if res := export(); res.Unchanged != 1 || res.Created+res.Updated != 0 {
+		t.Errorf("second export = %+v, want 1 unchanged", res)
+	}
+
+	if err := store.UpdateReadingListStatus(ctx, itemID, "read"); err != nil {
+		t.Fatalf("UpdateReadingListStatus: %v", err)
+	}
+	if res := export(); res.Updated != 1 {
+		t.Errorf("export after reading = %+v, want 1 updated", res)
+	}
+	if got := string(fake.pages["page1"]["Status"]); got != `{"select":{"name":"Read"}}` {
+		t.Errorf("Status = %s, want Read", got)
+	}
+
+	if err := store.RemoveFromReadingList(ctx, itemID); err != nil {
+		t.Fatalf("RemoveFromReadingList: %v", err)
+	}
+	if res := export(); res.Archived != 1 || !fake.archived["page1"] {
+		t.Errorf("export after removal = %+v, want page1 archived", res)
+	}
+	if pages, _ := store.ListNotionPages(ctx, "db1"); len(pages) != 0 {
+		t.Errorf("%d notion pages left on record, want 0", len(pages))
+	}
+}
+
+func TestExportNotion_NotConfigured(t *testing.T) {
+	runner := jobs.NewManager(newTestStore(t), 1)
+	w := httptest.NewRecorder()
+	ExportNotion(nil, runner).ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/admin/notion", nil))
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("status %d, want %d", w.Code, http.StatusServiceUnavailable)
+	}
+}
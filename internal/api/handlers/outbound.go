@@ -0,0 +1,28 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/hoanghai1803/apricot/internal/outbound"
+)
+
+// GetOutboundLog handles GET /api/admin/outbound. It returns the most recent
+// outbound HTTP requests made by the server, newest first. The optional
+// "purpose" query parameter (feed, extract, proxy, ai, notify) filters them.
+func GetOutboundLog() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		requests := outbound.Default.Requests()
+
+		if purpose := r.URL.Query().Get("purpose"); purpose != "" {
+			filtered := requests[:0]
+			for _, req := range requests {
+				if req.Purpose == purpose {
+					filtered = append(filtered, req)
+				}
+			}
+			requests = filtered
+		}
+
+		writeJSON(w, http.StatusOK, requests)
+	}
+}
@@ -0,0 +1,34 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/hoanghai1803/apricot/internal/outbound"
+)
+
+func TestGetOutboundLog_FiltersByPurpose(t *testing.T) {
+	orig := outbound.Default
+	outbound.Default = outbound.NewLog(10)
+	t.Cleanup(func() { outbound.Default = orig })
+
+	outbound.Default.Add(outbound.Request{Purpose: outbound.PurposeFeed, Host: "blog.example.com"})
+	outbound.Default.Add(outbound.Request{Purpose: outbound.PurposeAI, Host: "api.anthropic.com"})
+
+	r := httptest.NewRequest(http.MethodGet, "/api/admin/outbound?purpose=ai", nil)
+	w := httptest.NewRecorder()
+	GetOutboundLog().ServeHTTP(w, r)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("got status %d, want %d", w.Code, http.StatusOK)
+	}
+	var got []outbound.Request
+	if err := json.NewDecoder(w.Body).Decode(&got); err != nil {
+		t.Fatalf("decoding response: %v", err)
+	}
+	if len(got) != 1 || got[0].Host != "api.anthropic.com" {
+		t.Errorf("got %+v, want only the AI request", got)
+	}
+}
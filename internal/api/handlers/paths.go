@@ -0,0 +1,309 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/hoanghai1803/apricot/internal/ai"
+	"github.com/hoanghai1803/apricot/internal/models"
+	"github.com/hoanghai1803/apricot/internal/storage"
+)
+
+const (
+	// defaultPathItems is the number of articles a generated path aims for.
+	defaultPathItems = 8
+	maxPathItems     = 20
+
+	// pathCandidates caps how many matching articles are offered to the AI
+	// when generating a path.
+	pathCandidates = 50
+)
+
+// ListLearningPaths handles GET /api/paths. It returns all learning paths
+// with their completion counts, without items.
+func ListLearningPaths(store storage.Store) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		paths, err := store.ListLearningPaths(r.Context())
+		if err != nil {
+			slog.ErrorContext(r.Context(), "failed to list learning paths", "error", err)
+			writeError(w, http.StatusInternalServerError, "Failed to list learning paths")
+			return
+		}
+
+		writeJSON(w, http.StatusOK, paths)
+	}
+}
+
+// CreateLearningPath handles POST /api/paths. It creates a path from an
+// ordered list of reading list items.
+func CreateLearningPath(store storage.Store) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		var body struct {
+			Title       string                    `json:"title"`
+			Description string                    `json:"description"`
+			Items       []models.LearningPathStep `json:"items"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			writeError(w, http.StatusBadRequest, "Invalid JSON body")
+			return
+		}
+		if strings.TrimSpace(body.Title) == "" {
+			writeError(w, http.StatusBadRequest, "title is required")
+			return
+		}
+
+		id, err := store.CreateLearningPath(ctx, &models.LearningPath{
+			Title:       body.Title,
+			Description: body.Description,
+		}, body.Items)
+		if err != nil {
+			if isPathItemError(err) {
+				writeError(w, http.StatusBadRequest, err.Error())
+				return
+			}
+			slog.ErrorContext(ctx, "failed to create learning path", "error", err)
+			writeError(w, http.StatusInternalServerError, "Failed to create learning path")
+			return
+		}
+
+		writeLearningPath(w, r, store, id, http.StatusCreated)
+	}
+}
+
+// GenerateLearningPath handles POST /api/paths/generate. Given a topic, it
+// searches saved articles and posts fetched from sources, asks the AI to
+// pick and order the ones that teach the topic, adds any picks that are not
+// yet saved to the reading list, and stores the result as a new path.
+func GenerateLearningPath(store storage.Store, aiProvider ai.AIProvider) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		var body struct {
+			Topic    string `json:"topic"`
+			Title    string `json:"title"`
+			MaxItems int    `json:"max_items"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			writeError(w, http.StatusBadRequest, "Invalid JSON body")
+			return
+		}
+		body.Topic = strings.TrimSpace(body.Topic)
+		if body.Topic == "" {
+			writeError(w, http.StatusBadRequest, "topic is required")
+			return
+		}
+		if body.MaxItems <= 0 {
+			body.MaxItems = defaultPathItems
+		}
+		body.MaxItems = min(body.MaxItems, maxPathItems)
+		if body.Title == "" {
+			body.Title = "Learning path: " + body.Topic
+		}
+
+		if aiProvider == nil {
+			writeError(w, http.StatusServiceUnavailable,
+				"AI provider not configured. Add your API key to config.toml")
+			return
+		}
+
+		candidates, err := store.SearchBlogsAnyTerm(ctx, body.Topic, pathCandidates)
+		if err != nil {
+			slog.ErrorContext(ctx, "failed to search path candidates", "topic", body.Topic, "error", err)
+			writeError(w, http.StatusInternalServerError, "Failed to search articles")
+			return
+		}
+		if len(candidates) == 0 {
+			writeError(w, http.StatusNotFound, "No saved or fetched articles match this topic")
+			return
+		}
+
+		known := make(map[int64]bool, len(candidates))
+		entries := make([]ai.BlogEntry, len(candidates))
+		for i, b := range candidates {
+			known[b.ID] = true
+			entries[i] = ai.BlogEntry{
+				ID:          b.ID,
+				Title:       b.Title,
+				Source:      b.Source,
+				Description: b.Description,
+			}
+		}
+
+		ranked, err := aiProvider.BuildLearningPath(ctx, body.Topic, entries, body.MaxItems)
+		if err != nil {
+			slog.ErrorContext(ctx, "failed to build learning path", "topic", body.Topic, "error", err)
+			writeStageError(ctx, w, err, "building the learning path with AI", http.StatusInternalServerError, "Failed to build learning path with AI")
+			return
+		}
+
+		var steps []models.LearningPathStep
+		for _, rb := range ranked {
+			if !known[rb.ID] || len(steps) >= body.MaxItems {
+				continue
+			}
+			known[rb.ID] = false // skip duplicates
+
+			itemID, err := ensureOnReadingList(ctx, store, rb.ID)
+			if err != nil {
+				slog.ErrorContext(ctx, "failed to add path item to reading list", "blog_id", rb.ID, "error", err)
+				writeError(w, http.StatusInternalServerError, "Failed to add articles to reading list")
+				return
+			}
+			steps = append(steps, models.LearningPathStep{ReadingListID: itemID, Note: rb.Reason})
+		}
+		if len(steps) == 0 {
+			writeError(w, http.StatusNotFound, "No saved or fetched articles match this topic")
+			return
+		}
+
+		id, err := store.CreateLearningPath(ctx, &models.LearningPath{
+			Title: body.Title,
+			Topic: body.Topic,
+		}, steps)
+		if err != nil {
+			slog.ErrorContext(ctx, "failed to create learning path", "error", err)
+			writeError(w, http.StatusInternalServerError, "Failed to create learning path")
+			return
+		}
+
+		writeLearningPath(w, r, store, id, http.StatusCreated)
+	}
+}
+
+// GetLearningPath handles GET /api/paths/{id}. It returns the path with its
+// items in order.
+func GetLearningPath(store storage.Store) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := parseID(r, "id")
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		writeLearningPath(w, r, store, id, http.StatusOK)
+	}
+}
+
+// UpdateLearningPath handles PATCH /api/paths/{id}. It accepts optional
+// "title" and "description" fields, and an optional "items" list that
+// replaces the path's items and their order.
+func UpdateLearningPath(store storage.Store) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		id, err := parseID(r, "id")
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		var body struct {
+			Title       *string                    `json:"title"`
+			Description *string                    `json:"description"`
+			Items       *[]models.LearningPathStep `json:"items"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			writeError(w, http.StatusBadRequest, "Invalid JSON body")
+			return
+		}
+		if body.Title != nil && strings.TrimSpace(*body.Title) == "" {
+			writeError(w, http.StatusBadRequest, "title must not be empty")
+			return
+		}
+
+		if err := store.UpdateLearningPath(ctx, id, body.Title, body.Description); err != nil {
+			if errors.Is(err, storage.ErrNotFound) {
+				writeError(w, http.StatusNotFound, "Learning path not found")
+				return
+			}
+			slog.ErrorContext(ctx, "failed to update learning path", "id", id, "error", err)
+			writeError(w, http.StatusInternalServerError, "Failed to update learning path")
+			return
+		}
+
+		if body.Items != nil {
+			if err := store.SetLearningPathItems(ctx, id, *body.Items); err != nil {
+				if isPathItemError(err) {
+					writeError(w, http.StatusBadRequest, err.Error())
+					return
+				}
+				slog.ErrorContext(ctx, "failed to set learning path items", "id", id, "error", err)
+				writeError(w, http.StatusInternalServerError, "Failed to update learning path items")
+				return
+			}
+		}
+
+		writeLearningPath(w, r, store, id, http.StatusOK)
+	}
+}
+
+// DeleteLearningPath handles DELETE /api/paths/{id}. The reading list items
+// in the path are kept.
+func DeleteLearningPath(store storage.Store) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := parseID(r, "id")
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		if err := store.DeleteLearningPath(r.Context(), id); err != nil {
+			if errors.Is(err, storage.ErrNotFound) {
+				writeError(w, http.StatusNotFound, "Learning path not found")
+				return
+			}
+			slog.ErrorContext(r.Context(), "failed to delete learning path", "id", id, "error", err)
+			writeError(w, http.StatusInternalServerError, "Failed to delete learning path")
+			return
+		}
+
+		writeJSON(w, http.StatusOK, map[string]string{"status": "deleted"})
+	}
+}
+
+// writeLearningPath loads the path with the given ID and writes it with
+// status, or writes the appropriate error.
+func writeLearningPath(w http.ResponseWriter, r *http.Request, store storage.Store, id int64, status int) {
+	path, err := store.GetLearningPath(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			writeError(w, http.StatusNotFound, "Learning path not found")
+			return
+		}
+		slog.ErrorContext(r.Context(), "failed to get learning path", "id", id, "error", err)
+		writeError(w, http.StatusInternalServerError, "Failed to get learning path")
+		return
+	}
+
+	writeJSON(w, status, path)
+}
+
+// ensureOnReadingList returns the reading list item ID for blogID, adding
+// the blog to the reading list first if needed.
+func ensureOnReadingList(ctx context.Context, store storage.Store, blogID int64) (int64, error) {
+	id, err := store.GetReadingListIDByBlogID(ctx, blogID)
+	if err == nil {
+		return id, nil
+	}
+	if !errors.Is(err, storage.ErrNotFound) {
+		return 0, err
+	}
+
+	if err := store.AddToReadingList(ctx, blogID); err != nil {
+		return 0, err
+	}
+	return store.GetReadingListIDByBlogID(ctx, blogID)
+}
+
+// isPathItemError reports whether err describes an invalid item list, as
+// opposed to a storage failure.
+func isPathItemError(err error) bool {
+	msg := err.Error()
+	return strings.Contains(msg, "does not exist") || strings.Contains(msg, "more than once")
+}
@@ -0,0 +1,154 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/hoanghai1803/apricot/internal/models"
+)
+
+func TestGenerateLearningPath(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+
+	var blogIDs []int64
+	for _, title := range []string{"Raft consensus explained", "Paxos consensus in practice"} {
+		id, err := store.UpsertBlog(ctx, &models.Blog{
+			SourceID:  1,
+			Title:     title,
+			URL:       "https://example.com/" + title,
+			FetchedAt: time.Now(),
+		})
+		if err != nil {
+			t.Fatalf("seeding blog: %v", err)
+		}
+		blogIDs = append(blogIDs, id)
+	}
+	// Only the first is already saved; the generator must add the other.
+	if err := store.AddToReadingList(ctx, blogIDs[0]); err != nil {
+		t.Fatalf("AddToReadingList: %v", err)
+	}
+
+	body := `{"topic": "consensus", "max_items": 5}`
+	r := httptest.NewRequest(http.MethodPost, "/api/paths/generate", bytes.NewBufferString(body))
+	w := httptest.NewRecorder()
+	GenerateLearningPath(store, &stubAIProvider{}).ServeHTTP(w, r)
+
+	if w.Code != http.StatusCreated {
+		t.Fatalf("got status %d, want %d; body: %s", w.Code, http.StatusCreated, w.Body.String())
+	}
+
+	var path models.LearningPath
+	if err := json.NewDecoder(w.Body).Decode(&path); err != nil {
+		t.Fatalf("decoding response: %v", err)
+	}
+	if path.Title != "Learning path: consensus" || path.TotalItems != 2 {
+		t.Errorf("got title=%q total=%d", path.Title, path.TotalItems)
+	}
+	if len(path.Items) != 2 || path.Items[0].Note != "step" {
+		t.Fatalf("unexpected items: %+v", path.Items)
+	}
+
+	items, err := store.GetReadingList(ctx, "")
+	if err != nil {
+		t.Fatalf("GetReadingList: %v", err)
+	}
+	if len(items) != 2 {
+		t.Errorf("reading list has %d items, want 2", len(items))
+	}
+}
+
+func TestGenerateLearningPath_Errors(t *testing.T) {
+	store := newTestStore(t)
+
+	tests := []struct {
+		name       string
+		body       string
+		wantStatus int
+	}{
+		{"missing topic", `{}`, http.StatusBadRequest},
+		{"no matches", `{"topic": "nothing-matches-this"}`, http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, "/api/paths/generate", bytes.NewBufferString(tt.body))
+			w := httptest.NewRecorder()
+			GenerateLearningPath(store, &stubAIProvider{}).ServeHTTP(w, r)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("got status %d, want %d", w.Code, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestLearningPathCRUD(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+	blogID := seedBlog(t, store)
+	if err := store.AddToReadingList(ctx, blogID); err != nil {
+		t.Fatalf("AddToReadingList: %v", err)
+	}
+	itemID, err := store.GetReadingListIDByBlogID(ctx, blogID)
+	if err != nil {
+		t.Fatalf("GetReadingListIDByBlogID: %v", err)
+	}
+
+	// Create.
+	body := `{"title": "Basics", "items": [{"reading_list_id": ` + jsonInt64(itemID) + `}]}`
+	r := httptest.NewRequest(http.MethodPost, "/api/paths", bytes.NewBufferString(body))
+	w := httptest.NewRecorder()
+	CreateLearningPath(store).ServeHTTP(w, r)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("POST got status %d; body: %s", w.Code, w.Body.String())
+	}
+	var created models.LearningPath
+	if err := json.NewDecoder(w.Body).Decode(&created); err != nil {
+		t.Fatalf("decoding POST response: %v", err)
+	}
+	pathID := jsonInt64(created.ID)
+
+	// Invalid item.
+	r = httptest.NewRequest(http.MethodPost, "/api/paths", bytes.NewBufferString(`{"title": "Bad", "items": [{"reading_list_id": 99999}]}`))
+	w = httptest.NewRecorder()
+	CreateLearningPath(store).ServeHTTP(w, r)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("POST with missing item got status %d, want %d", w.Code, http.StatusBadRequest)
+	}
+
+	// Rename and clear items.
+	r = httptest.NewRequest(http.MethodPatch, "/api/paths/"+pathID, bytes.NewBufferString(`{"title": "Renamed", "items": []}`))
+	w = httptest.NewRecorder()
+	UpdateLearningPath(store).ServeHTTP(w, withURLParams(r, "id", pathID))
+	if w.Code != http.StatusOK {
+		t.Fatalf("PATCH got status %d; body: %s", w.Code, w.Body.String())
+	}
+	var updated models.LearningPath
+	if err := json.NewDecoder(w.Body).Decode(&updated); err != nil {
+		t.Fatalf("decoding PATCH response: %v", err)
+	}
+	if updated.Title != "Renamed" || updated.TotalItems != 0 {
+		t.Errorf("got title=%q total=%d, want Renamed and 0", updated.Title, updated.TotalItems)
+	}
+
+	// Delete, then get.
+	r = httptest.NewRequest(http.MethodDelete, "/api/paths/"+pathID, nil)
+	w = httptest.NewRecorder()
+	DeleteLearningPath(store).ServeHTTP(w, withURLParams(r, "id", pathID))
+	if w.Code != http.StatusOK {
+		t.Fatalf("DELETE got status %d", w.Code)
+	}
+
+	r = httptest.NewRequest(http.MethodGet, "/api/paths/"+pathID, nil)
+	w = httptest.NewRecorder()
+	GetLearningPath(store).ServeHTTP(w, withURLParams(r, "id", pathID))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("GET after delete got status %d, want %d", w.Code, http.StatusNotFound)
+	}
+}
@@ -0,0 +1,76 @@
+package handlers
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/hoanghai1803/apricot/internal/feeds"
+	"github.com/hoanghai1803/apricot/internal/pdf"
+	"github.com/hoanghai1803/apricot/internal/storage"
+)
+
+// ExportItemPDF handles GET /api/reading-list/{id}/pdf. It renders the
+// item's reader view, the article with its summary and notes, as a PDF
+// (see pdf.Write), extracting the article first if that has not been done.
+func ExportItemPDF(store storage.Store, fetcher *feeds.Fetcher) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		id, err := parseID(r, "id")
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		item, err := store.GetReadingListItemByID(ctx, id)
+		if err != nil {
+			if errors.Is(err, storage.ErrNotFound) {
+				writeError(w, http.StatusNotFound, "Reading list item not found")
+				return
+			}
+			slog.ErrorContext(ctx, "failed to get reading list item", "id", id, "error", err)
+			writeError(w, http.StatusInternalServerError, "Failed to export PDF")
+			return
+		}
+		if item.Blog == nil {
+			writeError(w, http.StatusNotFound, "Reading list item not found")
+			return
+		}
+		if fetcher != nil {
+			extractContent(ctx, store, fetcher, item.Blog)
+		}
+
+		doc := pdf.Document{
+			Title:       item.Blog.Title,
+			Source:      item.Blog.Source,
+			URL:         item.Blog.URL,
+			PublishedAt: item.Blog.PublishedAt,
+			Tags:        item.Tags,
+			Text:        item.Blog.FullContent,
+		}
+		if item.Blog.RewrittenTitle != "" {
+			doc.Title = item.Blog.RewrittenTitle
+		}
+		if item.Summary != nil {
+			doc.Summary = *item.Summary
+		}
+		if item.Notes != nil {
+			doc.Notes = *item.Notes
+		}
+
+		var buf bytes.Buffer
+		if err := pdf.Write(&buf, doc, time.Now()); err != nil {
+			slog.ErrorContext(ctx, "failed to write PDF", "id", id, "error", err)
+			writeError(w, http.StatusInternalServerError, "Failed to export PDF")
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/pdf")
+		w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="apricot-%d.pdf"`, id))
+		w.WriteHeader(http.StatusOK)
+		w.Write(buf.Bytes()) //nolint:errcheck
+	}
+}
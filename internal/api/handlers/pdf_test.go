@@ -0,0 +1,59 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/hoanghai1803/apricot/internal/models"
+)
+
+func TestExportItemPDF(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+	blogID, err := store.UpsertBlog(ctx, &models.Blog{
+		SourceID:    1,
+		Title:       "Caching",
+		URL:         "https://a.example/cache",
+		FullContent: "First paragraph.\nSecond paragraph.",
+		FetchedAt:   time.Now(),
+	})
+	if err != nil {
+		t.Fatalf("UpsertBlog: %v", err)
+	}
+	if err := store.AddToReadingList(ctx, blogID); err != nil {
+		t.Fatalf("AddToReadingList: %v", err)
+	}
+	id, err := store.GetReadingListIDByBlogID(ctx, blogID)
+	if err != nil {
+		t.Fatalf("GetReadingListIDByBlogID: %v", err)
+	}
+
+	w := httptest.NewRecorder()
+	req := withURLParams(httptest.NewRequest(http.MethodGet, "/api/reading-list/"+jsonInt64(id)+"/pdf", nil), "id", jsonInt64(id))
+	ExportItemPDF(store, nil).ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status %d, want %d; body: %s", w.Code, http.StatusOK, w.Body.String())
+	}
+	if ct := w.Header().Get("Content-Type"); ct != "application/pdf" {
+		t.Errorf("Content-Type = %q", ct)
+	}
+	if cd := w.Header().Get("Content-Disposition"); cd != `attachment; filename="apricot-`+jsonInt64(id)+`.pdf"` {
+		t.Errorf("Content-Disposition = %q", cd)
+	}
+	if !bytes.HasPrefix(w.Body.Bytes(), []byte("%PDF-")) {
+		t.Errorf("body is not a PDF: %q", w.Body.Bytes()[:min(w.Body.Len(), 20)])
+	}
+
+	for param, want := range map[string]int{"x": http.StatusBadRequest, "999": http.StatusNotFound} {
+		w := httptest.NewRecorder()
+		req := withURLParams(httptest.NewRequest(http.MethodGet, "/api/reading-list/"+param+"/pdf", nil), "id", param)
+		ExportItemPDF(store, nil).ServeHTTP(w, req)
+		if w.Code != want {
+			t.Errorf("id %q: status %d, want %d", param, w.Code, want)
+		}
+	}
+}
@@ -1,22 +1,28 @@
 package handlers
 
 import (
+	"context"
 	"encoding/json"
+	"fmt"
 	"log/slog"
 	"net/http"
+	"slices"
+	"strings"
 
+	"github.com/hoanghai1803/apricot/internal/ai"
+	"github.com/hoanghai1803/apricot/internal/models"
 	"github.com/hoanghai1803/apricot/internal/storage"
 )
 
 // GetPreferences handles GET /api/preferences. It returns all user
 // preferences as a JSON object.
-func GetPreferences(store *storage.Store) http.HandlerFunc {
+func GetPreferences(store storage.Store) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		ctx := r.Context()
 
 		prefs, err := store.GetAllPreferences(ctx)
 		if err != nil {
-			slog.Error("failed to get preferences", "error", err)
+			slog.ErrorContext(ctx, "failed to get preferences", "error", err)
 			writeError(w, http.StatusInternalServerError, "Failed to get preferences")
 			return
 		}
@@ -26,8 +32,10 @@ func GetPreferences(store *storage.Store) http.HandlerFunc {
 }
 
 // UpdatePreferences handles PUT /api/preferences. It accepts a JSON object
-// where each key-value pair is saved as a separate preference.
-func UpdatePreferences(store *storage.Store) http.HandlerFunc {
+// where each key-value pair is saved as a separate preference. "topics" must
+// be a list of {"topic", "importance"} objects with importance 1-5, or a
+// free-form string.
+func UpdatePreferences(store storage.Store) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		ctx := r.Context()
 
@@ -36,10 +44,16 @@ func UpdatePreferences(store *storage.Store) http.HandlerFunc {
 			writeError(w, http.StatusBadRequest, "Invalid JSON body")
 			return
 		}
+		if raw, ok := body["topics"]; ok {
+			if _, _, err := parseTopics(raw); err != nil {
+				writeError(w, http.StatusBadRequest, err.Error())
+				return
+			}
+		}
 
 		for key, value := range body {
 			if err := store.SetPreference(ctx, key, json.RawMessage(value)); err != nil {
-				slog.Error("failed to set preference", "key", key, "error", err)
+				slog.ErrorContext(ctx, "failed to set preference", "key", key, "error", err)
 				writeError(w, http.StatusInternalServerError, "Failed to save preferences")
 				return
 			}
@@ -48,7 +62,7 @@ func UpdatePreferences(store *storage.Store) http.HandlerFunc {
 		// Return the saved preferences.
 		prefs, err := store.GetAllPreferences(ctx)
 		if err != nil {
-			slog.Error("failed to get preferences after save", "error", err)
+			slog.ErrorContext(ctx, "failed to get preferences after save", "error", err)
 			writeError(w, http.StatusInternalServerError, "Failed to get preferences")
 			return
 		}
@@ -56,3 +70,52 @@ func UpdatePreferences(store *storage.Store) http.HandlerFunc {
 		writeJSON(w, http.StatusOK, prefs)
 	}
 }
+
+// loadTopics returns the "topics" preference as the interests text passed to
+// the AI: weighted topics are listed with their importance, and a free-form
+// string from older settings is used as is. Returns storage.ErrNotFound if
+// no topics are set.
+func loadTopics(ctx context.Context, store storage.Store) (string, error) {
+	var raw json.RawMessage
+	if err := store.GetPreference(ctx, "topics", &raw); err != nil {
+		return "", err
+	}
+	topics, text, err := parseTopics(raw)
+	if err != nil {
+		return "", err
+	}
+	if topics == nil {
+		return text, nil
+	}
+	if len(topics) == 0 {
+		return "", storage.ErrNotFound
+	}
+	return ai.FormatTopics(topics), nil
+}
+
+// parseTopics decodes a "topics" preference value. A list of weighted topics
+// is returned as topics (non-nil, possibly empty); a free-form string as
+// text.
+func parseTopics(raw json.RawMessage) (topics []models.Topic, text string, err error) {
+	if err := json.Unmarshal(raw, &text); err == nil {
+		return nil, text, nil
+	}
+	if err := json.Unmarshal(raw, &topics); err != nil {
+		return nil, "", fmt.Errorf("topics must be a list of {\"topic\", \"importance\"} objects or a string")
+	}
+
+	topics = slices.DeleteFunc(topics, func(t models.Topic) bool {
+		return strings.TrimSpace(t.Topic) == ""
+	})
+	if topics == nil {
+		topics = []models.Topic{}
+	}
+	for i := range topics {
+		topics[i].Topic = strings.TrimSpace(topics[i].Topic)
+		if topics[i].Importance < models.MinTopicImportance || topics[i].Importance > models.MaxTopicImportance {
+			return nil, "", fmt.Errorf("importance of topic %q must be between %d and %d",
+				topics[i].Topic, models.MinTopicImportance, models.MaxTopicImportance)
+		}
+	}
+	return topics, "", nil
+}
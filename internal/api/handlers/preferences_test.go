@@ -3,9 +3,14 @@ package handlers
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+
+	"github.com/hoanghai1803/apricot/internal/models"
+	"github.com/hoanghai1803/apricot/internal/storage"
 )
 
 func TestGetPreferencesEmpty(t *testing.T) {
@@ -95,3 +100,66 @@ func TestUpdatePreferencesInvalidJSON(t *testing.T) {
 		t.Fatalf("got status %d, want %d", w.Code, http.StatusBadRequest)
 	}
 }
+
+func TestUpdatePreferences_Topics(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       string
+		wantStatus int
+	}{
+		{"weighted", `{"topics": [{"topic": "database internals", "importance": 5}]}`, http.StatusOK},
+		{"free-form", `{"topics": "system design, databases"}`, http.StatusOK},
+		{"importance out of range", `{"topics": [{"topic": "Kubernetes", "importance": 9}]}`, http.StatusBadRequest},
+		{"wrong type", `{"topics": 42}`, http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := newTestStore(t)
+			r := httptest.NewRequest(http.MethodPut, "/api/preferences", bytes.NewBufferString(tt.body))
+			w := httptest.NewRecorder()
+			UpdatePreferences(store).ServeHTTP(w, r)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("got status %d, want %d; body: %s", w.Code, tt.wantStatus, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestLoadTopics(t *testing.T) {
+	store := newTestStore(t)
+	ctx := t.Context()
+
+	if _, err := loadTopics(ctx, store); !errors.Is(err, storage.ErrNotFound) {
+		t.Fatalf("loadTopics() with no topics error = %v, want ErrNotFound", err)
+	}
+
+	if err := store.SetPreference(ctx, "topics", "system design"); err != nil {
+		t.Fatalf("SetPreference: %v", err)
+	}
+	if got, err := loadTopics(ctx, store); err != nil || got != "system design" {
+		t.Errorf("loadTopics() = %q, %v; want the free-form string", got, err)
+	}
+
+	if err := store.SetPreference(ctx, "topics", []models.Topic{
+		{Topic: "Kubernetes", Importance: 2},
+		{Topic: "database internals", Importance: 5},
+	}); err != nil {
+		t.Fatalf("SetPreference: %v", err)
+	}
+	got, err := loadTopics(ctx, store)
+	if err != nil {
+		t.Fatalf("loadTopics() error: %v", err)
+	}
+	if !strings.HasPrefix(got, "- database internals (importance 5/5") {
+		t.Errorf("loadTopics() = %q, want the must-have topic first", got)
+	}
+
+	if err := store.SetPreference(ctx, "topics", []models.Topic{}); err != nil {
+		t.Fatalf("SetPreference: %v", err)
+	}
+	if _, err := loadTopics(ctx, store); !errors.Is(err, storage.ErrNotFound) {
+		t.Errorf("loadTopics() with an empty list error = %v, want ErrNotFound", err)
+	}
+}
@@ -0,0 +1,127 @@
+package handlers
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/hoanghai1803/apricot/internal/ai"
+	"github.com/hoanghai1803/apricot/internal/storage"
+)
+
+// prerequisiteArticlesPerConcept caps how many saved articles are linked to
+// each prerequisite concept.
+const prerequisiteArticlesPerConcept = 3
+
+// PrerequisiteArticle is a saved article that covers a prerequisite concept.
+type PrerequisiteArticle struct {
+	ID     int64  `json:"id"`
+	Title  string `json:"title"`
+	URL    string `json:"url"`
+	Source string `json:"source"`
+}
+
+// PrerequisiteStep is one concept in a learning path, with the articles from
+// the user's reading list that cover it.
+type PrerequisiteStep struct {
+	Concept  string                `json:"concept"`
+	Reason   string                `json:"reason"`
+	Articles []PrerequisiteArticle `json:"articles"`
+}
+
+// PrerequisitesResponse is the learning path leading up to a blog post.
+type PrerequisitesResponse struct {
+	BlogID        int64              `json:"blog_id"`
+	Title         string             `json:"title"`
+	Difficulty    string             `json:"difficulty,omitempty"`
+	Prerequisites []PrerequisiteStep `json:"prerequisites"`
+}
+
+// GetPrerequisites handles GET /api/blogs/{id}/prerequisites. It asks the AI
+// for the concepts to understand before reading the blog, most foundational
+// first, and links each to matching articles already on the reading list.
+// An article is linked to at most one concept so the result reads as a
+// learning path. Intended for deep-dive posts, but works for any blog.
+func GetPrerequisites(store storage.Store, aiProvider ai.AIProvider) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		id, err := parseID(r, "id")
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		if aiProvider == nil {
+			writeError(w, http.StatusServiceUnavailable,
+				"AI provider not configured. Add your API key to config.toml")
+			return
+		}
+
+		blog, err := store.GetBlogByID(ctx, id)
+		if err != nil {
+			if errors.Is(err, storage.ErrNotFound) {
+				writeError(w, http.StatusNotFound, "Blog not found")
+				return
+			}
+			slog.ErrorContext(ctx, "failed to get blog", "id", id, "error", err)
+			writeError(w, http.StatusInternalServerError, "Failed to get blog")
+			return
+		}
+
+		classifyDifficulty(ctx, store, aiProvider, blog)
+
+		prereqs, err := aiProvider.SuggestPrerequisites(ctx, ai.BlogEntry{
+			ID:          blog.ID,
+			Title:       blog.Title,
+			Source:      blog.Source,
+			Description: blog.Description,
+			FullContent: blog.FullContent,
+		})
+		if err != nil {
+			slog.ErrorContext(ctx, "failed to suggest prerequisites", "id", id, "error", err)
+			writeStageError(ctx, w, err, "suggesting prerequisites with AI", http.StatusInternalServerError, "Failed to suggest prerequisites with AI")
+			return
+		}
+
+		linked := map[int64]bool{blog.ID: true}
+		steps := make([]PrerequisiteStep, 0, len(prereqs))
+		for _, p := range prereqs {
+			step := PrerequisiteStep{
+				Concept:  p.Concept,
+				Reason:   p.Reason,
+				Articles: []PrerequisiteArticle{},
+			}
+
+			keywords := p.Keywords
+			if keywords == "" {
+				keywords = p.Concept
+			}
+			matches, err := store.SearchSavedBlogs(ctx, keywords, blog.ID, prerequisiteArticlesPerConcept*2)
+			if err != nil {
+				slog.WarnContext(ctx, "failed to search saved blogs", "concept", p.Concept, "error", err)
+			}
+			for _, m := range matches {
+				if linked[m.ID] || len(step.Articles) >= prerequisiteArticlesPerConcept {
+					continue
+				}
+				linked[m.ID] = true
+				step.Articles = append(step.Articles, PrerequisiteArticle{
+					ID:     m.ID,
+					Title:  m.Title,
+					URL:    m.URL,
+					Source: m.Source,
+				})
+			}
+
+			steps = append(steps, step)
+		}
+
+		writeJSON(w, http.StatusOK, PrerequisitesResponse{
+			BlogID:        blog.ID,
+			Title:         blog.Title,
+			Difficulty:    blog.Difficulty,
+			Prerequisites: steps,
+		})
+	}
+}
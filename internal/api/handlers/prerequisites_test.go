@@ -0,0 +1,97 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/hoanghai1803/apricot/internal/ai"
+	"github.com/hoanghai1803/apricot/internal/models"
+)
+
+func prerequisitesRequest(id string) *http.Request {
+	r := httptest.NewRequest(http.MethodGet, "/api/blogs/"+id+"/prerequisites", nil)
+	return withURLParams(r, "id", id)
+}
+
+func TestGetPrerequisites(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+	blogID := seedBlog(t, store)
+
+	savedID, err := store.UpsertBlog(ctx, &models.Blog{
+		SourceID:    1,
+		Title:       "Understanding Consistent Hashing",
+		URL:         "https://example.com/hashing",
+		Description: "Rings and virtual nodes",
+		FetchedAt:   time.Now(),
+	})
+	if err != nil {
+		t.Fatalf("seeding saved blog: %v", err)
+	}
+	if err := store.AddToReadingList(ctx, savedID); err != nil {
+		t.Fatalf("AddToReadingList: %v", err)
+	}
+
+	provider := &stubAIProvider{
+		difficulty: models.DifficultyDeepDive,
+		prereqs: []ai.Prerequisite{
+			{Concept: "Consistent hashing", Reason: "Used to partition keys.", Keywords: "consistent hashing"},
+			{Concept: "Vector clocks", Reason: "Used for versioning.", Keywords: "vector clocks"},
+		},
+	}
+
+	w := httptest.NewRecorder()
+	GetPrerequisites(store, provider).ServeHTTP(w, prerequisitesRequest(jsonInt64(blogID)))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("got status %d, want %d; body: %s", w.Code, http.StatusOK, w.Body.String())
+	}
+
+	var resp PrerequisitesResponse
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("decoding response: %v", err)
+	}
+	if resp.Difficulty != models.DifficultyDeepDive {
+		t.Errorf("difficulty = %q, want %q", resp.Difficulty, models.DifficultyDeepDive)
+	}
+	if len(resp.Prerequisites) != 2 {
+		t.Fatalf("got %d prerequisites, want 2", len(resp.Prerequisites))
+	}
+	if got := resp.Prerequisites[0].Articles; len(got) != 1 || got[0].ID != savedID {
+		t.Errorf("first step articles = %+v, want saved blog %d", got, savedID)
+	}
+	if got := resp.Prerequisites[1].Articles; len(got) != 0 {
+		t.Errorf("second step articles = %+v, want none", got)
+	}
+}
+
+func TestGetPrerequisites_Errors(t *testing.T) {
+	store := newTestStore(t)
+	blogID := jsonInt64(seedBlog(t, store))
+
+	tests := []struct {
+		name       string
+		provider   ai.AIProvider
+		id         string
+		wantStatus int
+	}{
+		{"no provider", nil, blogID, http.StatusServiceUnavailable},
+		{"invalid id", &stubAIProvider{}, "abc", http.StatusBadRequest},
+		{"unknown blog", &stubAIProvider{}, "99999", http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			GetPrerequisites(store, tt.provider).ServeHTTP(w, prerequisitesRequest(tt.id))
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("got status %d, want %d", w.Code, tt.wantStatus)
+			}
+		})
+	}
+}
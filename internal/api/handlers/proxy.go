@@ -1,109 +1,307 @@
 package handlers
 
 import (
+	"bytes"
+	"context"
 	"crypto/tls"
 	"io"
 	"log/slog"
+	"mime"
 	"net"
 	"net/http"
 	"net/url"
-	"regexp"
 	"strings"
 	"time"
+
+	"github.com/hoanghai1803/apricot/internal/cleanhtml"
+	"github.com/hoanghai1803/apricot/internal/netguard"
+	"github.com/hoanghai1803/apricot/internal/outbound"
+	"github.com/hoanghai1803/apricot/internal/pagecache"
 )
 
-// proxyClient is a dedicated HTTP client for proxying blog pages. It mirrors
-// the feeds fetcher transport settings (TLS 1.2+, browser-like User-Agent,
-// 20s TLS handshake timeout).
-var proxyClient = &http.Client{
-	Timeout: 30 * time.Second,
-	Transport: &http.Transport{
-		DialContext: (&net.Dialer{
-			Timeout:   30 * time.Second,
-			KeepAlive: 30 * time.Second,
-		}).DialContext,
-		ForceAttemptHTTP2:     true,
-		MaxIdleConns:          50,
-		IdleConnTimeout:       90 * time.Second,
-		TLSHandshakeTimeout:  20 * time.Second,
-		ExpectContinueTimeout: 1 * time.Second,
-		TLSClientConfig: &tls.Config{
-			MinVersion: tls.VersionTLS12,
+// newProxyClient returns a dedicated HTTP client for proxying blog pages. It
+// mirrors the feeds fetcher transport settings (TLS 1.2+, browser-like
+// User-Agent, 20s TLS handshake timeout) and dials through guard.
+func newProxyClient(guard *netguard.Guard) *http.Client {
+	return &http.Client{
+		Timeout: 30 * time.Second,
+		Transport: &outbound.Transport{
+			Purpose: outbound.PurposeProxy,
+			Base: &http.Transport{
+				DialContext: (&net.Dialer{
+					Timeout:   30 * time.Second,
+					KeepAlive: 30 * time.Second,
+					Control:   guard.Control,
+				}).DialContext,
+				ForceAttemptHTTP2:     true,
+				MaxIdleConns:          50,
+				IdleConnTimeout:       90 * time.Second,
+				TLSHandshakeTimeout:   20 * time.Second,
+				ExpectContinueTimeout: 1 * time.Second,
+				TLSClientConfig: &tls.Config{
+					MinVersion: tls.VersionTLS12,
+				},
+			},
 		},
-	},
+	}
 }
 
-var headTagRe = regexp.MustCompile(`(?i)<head[^>]*>`)
+// proxyCSP is the Content-Security-Policy for proxied HTML pages. Pages are
+// cleaned of scripts and frames, and the policy makes sure none slip
+// through; images, styles, and fonts may load from anywhere, and only
+// Apricot may frame the page.
+const proxyCSP = "script-src 'none'; object-src 'none'; frame-src 'none'; frame-ancestors 'self'"
+
+// maxProxyBytes limits the size of a page or asset the proxy reads.
+const maxProxyBytes = 10 * 1024 * 1024
+
+// proxyAssetURL returns the URL that loads asset through ProxyAsset.
+func proxyAssetURL(asset *url.URL) string {
+	return "/api/proxy/asset?url=" + url.QueryEscape(asset.String())
+}
 
 // ProxyPage handles GET /api/proxy?url=<encoded-url>. It fetches the target
-// page, strips X-Frame-Options and CSP frame-ancestors headers so it can be
-// embedded in an iframe, and injects <base target="_blank"> so all links
-// inside the iframe open in a new tab instead of navigating within it.
-func ProxyPage() http.HandlerFunc {
+// page and serves a clean copy for the reader's iframe (see cleanhtml):
+// scripts, frames, and trackers are removed, images and stylesheets from
+// the page's own site load through ProxyAsset, links are made absolute and
+// open in a new tab, and upstream X-Frame-Options and CSP headers are
+// dropped. XHTML is cleaned the same way and served as HTML. Other markup
+// that could run scripts, such as SVG and XML, gets 415 Unsupported Media
+// Type; anything else, such as a PDF, is passed through, up to
+// maxProxyBytes. Pages on addresses guard refuses get 403 Forbidden. HTML
+// pages are kept in cache, if it is not nil, for as long as their caching
+// headers allow; the X-Cache response header says whether a page came from
+// it.
+func ProxyPage(guard *netguard.Guard, cache *pagecache.Cache) http.HandlerFunc {
+	proxyClient := newProxyClient(guard)
 	return func(w http.ResponseWriter, r *http.Request) {
-		targetURL := r.URL.Query().Get("url")
-		if targetURL == "" {
-			writeError(w, http.StatusBadRequest, "url parameter is required")
+		targetURL, ok := proxyTarget(w, r)
+		if !ok {
 			return
 		}
 
-		parsed, err := url.Parse(targetURL)
-		if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") {
-			writeError(w, http.StatusBadRequest, "url must be a valid HTTP or HTTPS URL")
-			return
+		if cache != nil {
+			if page, ok := cache.Get(targetURL); ok {
+				w.Header().Set("X-Cache", "HIT")
+				writeProxiedHTML(r.Context(), w, pageBase(page), page.ContentType, http.StatusOK, page.Body)
+				return
+			}
 		}
 
-		req, err := http.NewRequestWithContext(r.Context(), "GET", targetURL, nil)
+		resp, err := proxyGet(r.Context(), proxyClient, targetURL, "text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8")
 		if err != nil {
-			writeError(w, http.StatusInternalServerError, "failed to create request")
+			slog.WarnContext(r.Context(), "proxy fetch failed", "url", targetURL, "error", err)
+			writeStageError(r.Context(), w, err, "fetching page", http.StatusBadGateway, "failed to fetch page")
+			return
+		}
+		defer resp.Body.Close()
+
+		// The transport decodes gzip itself, so any encoding left is one
+		// the page can't be read or cleaned in.
+		if ce := resp.Header.Get("Content-Encoding"); ce != "" && ce != "identity" {
+			writeError(w, http.StatusBadGateway, "page uses an unsupported content encoding")
 			return
 		}
 
-		// Browser-like headers to avoid bot detection.
-		req.Header.Set("User-Agent", "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/131.0.0.0 Safari/537.36")
-		req.Header.Set("Accept", "text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8")
-		req.Header.Set("Accept-Language", "en-US,en;q=0.9")
+		contentType := resp.Header.Get("Content-Type")
+		mt, _, _ := mime.ParseMediaType(contentType)
+		switch {
+		case mt == "text/html", mt == "application/xhtml+xml":
+		case isMarkup(mt):
+			writeError(w, http.StatusUnsupportedMediaType, "url is not an HTML page")
+			return
+		default:
+			// Other resources, such as a PDF: pass through.
+			if contentType == "" {
+				contentType = "application/octet-stream"
+			}
+			w.Header().Set("Content-Type", contentType)
+			w.WriteHeader(resp.StatusCode)
+			io.Copy(w, io.LimitReader(resp.Body, maxProxyBytes)) //nolint:errcheck
+			return
+		}
 
-		resp, err := proxyClient.Do(req)
+		body, err := io.ReadAll(io.LimitReader(resp.Body, maxProxyBytes))
 		if err != nil {
-			slog.Warn("proxy fetch failed", "url", targetURL, "error", err)
-			writeError(w, http.StatusBadGateway, "failed to fetch page")
+			writeStageError(r.Context(), w, err, "reading page", http.StatusBadGateway, "failed to read page")
 			return
 		}
-		defer resp.Body.Close()
+		if cache != nil {
+			cacheProxied(r.Context(), cache, targetURL, resp, body)
+			w.Header().Set("X-Cache", "MISS")
+		}
+		writeProxiedHTML(r.Context(), w, resp.Request.URL, contentType, resp.StatusCode, body)
+	}
+}
 
-		contentType := resp.Header.Get("Content-Type")
+// ProxyAsset handles GET /api/proxy/asset?url=<encoded-url>, which serves
+// the images, stylesheets, and fonts of pages shown by ProxyPage. URLs in
+// stylesheets are rewritten like those in the page. Other content types
+// get 415 Unsupported Media Type, so it can't stand in for ProxyPage.
+// Assets are cached like pages.
+func ProxyAsset(guard *netguard.Guard, cache *pagecache.Cache) http.HandlerFunc {
+	proxyClient := newProxyClient(guard)
+	return func(w http.ResponseWriter, r *http.Request) {
+		targetURL, ok := proxyTarget(w, r)
+		if !ok {
+			return
+		}
 
-		// For HTML pages: strip iframe-blocking headers, inject <base> tag.
-		if strings.Contains(contentType, "text/html") {
-			body, err := io.ReadAll(io.LimitReader(resp.Body, 10*1024*1024)) // 10 MB limit
-			if err != nil {
-				writeError(w, http.StatusBadGateway, "failed to read page")
+		if cache != nil {
+			if page, ok := cache.Get(targetURL); ok {
+				w.Header().Set("X-Cache", "HIT")
+				writeProxiedAsset(w, pageBase(page), page.ContentType, page.Body)
 				return
 			}
+		}
 
-			html := string(body)
-
-			// Inject <base href="original-origin" target="_blank"> after <head>
-			// so relative resources resolve correctly and links open in new tabs.
-			baseTag := `<base href="` + parsed.Scheme + `://` + parsed.Host + `/" target="_blank">`
-			if loc := headTagRe.FindStringIndex(html); loc != nil {
-				html = html[:loc[1]] + baseTag + html[loc[1]:]
-			}
+		resp, err := proxyGet(r.Context(), proxyClient, targetURL, "image/*,text/css,font/*;q=0.9,*/*;q=0.8")
+		if err != nil {
+			slog.WarnContext(r.Context(), "proxy asset fetch failed", "url", targetURL, "error", err)
+			writeStageError(r.Context(), w, err, "fetching asset", http.StatusBadGateway, "failed to fetch asset")
+			return
+		}
+		defer resp.Body.Close()
 
-			w.Header().Set("Content-Type", contentType)
-			// Do NOT copy X-Frame-Options or CSP headers from upstream.
+		if resp.StatusCode != http.StatusOK {
 			w.WriteHeader(resp.StatusCode)
-			io.WriteString(w, html) //nolint:errcheck
+			return
+		}
+		contentType := resp.Header.Get("Content-Type")
+		if !isProxyAsset(contentType) {
+			writeError(w, http.StatusUnsupportedMediaType, "url is not an image, stylesheet, or font")
 			return
 		}
 
-		// Non-HTML resources: pass through as-is.
-		w.Header().Set("Content-Type", contentType)
-		if ce := resp.Header.Get("Content-Encoding"); ce != "" {
-			w.Header().Set("Content-Encoding", ce)
+		body, err := io.ReadAll(io.LimitReader(resp.Body, maxProxyBytes))
+		if err != nil {
+			writeStageError(r.Context(), w, err, "reading asset", http.StatusBadGateway, "failed to read asset")
+			return
+		}
+		if cache != nil {
+			cacheProxied(r.Context(), cache, targetURL, resp, body)
+			w.Header().Set("X-Cache", "MISS")
 		}
-		w.WriteHeader(resp.StatusCode)
-		io.Copy(w, resp.Body) //nolint:errcheck
+		writeProxiedAsset(w, resp.Request.URL, contentType, body)
+	}
+}
+
+// proxyTarget returns the URL in the url query parameter, or writes 400 Bad
+// Request and returns false if it is not an HTTP or HTTPS URL.
+func proxyTarget(w http.ResponseWriter, r *http.Request) (string, bool) {
+	targetURL := r.URL.Query().Get("url")
+	if targetURL == "" {
+		writeError(w, http.StatusBadRequest, "url parameter is required")
+		return "", false
+	}
+	parsed, err := url.Parse(targetURL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") {
+		writeError(w, http.StatusBadRequest, "url must be a valid HTTP or HTTPS URL")
+		return "", false
+	}
+	return targetURL, true
+}
+
+// proxyGet fetches targetURL with browser-like headers, to avoid bot
+// detection.
+func proxyGet(ctx context.Context, client *http.Client, targetURL, accept string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, targetURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/131.0.0.0 Safari/537.36")
+	req.Header.Set("Accept", accept)
+	req.Header.Set("Accept-Language", "en-US,en;q=0.9")
+	return client.Do(req)
+}
+
+// cacheProxied stores a successful response in cache, if its caching
+// headers allow.
+func cacheProxied(ctx context.Context, cache *pagecache.Cache, targetURL string, resp *http.Response, body []byte) {
+	if resp.StatusCode != http.StatusOK {
+		return
+	}
+	expires, ok := pagecache.Expiry(resp.Header, time.Now())
+	if !ok {
+		return
+	}
+	page := &pagecache.Page{
+		URL:         targetURL,
+		ContentType: resp.Header.Get("Content-Type"),
+		Expires:     expires,
+		Body:        body,
+	}
+	if final := resp.Request.URL.String(); final != targetURL {
+		page.Location = final
+	}
+	if err := cache.Put(page); err != nil {
+		slog.WarnContext(ctx, "failed to cache proxied page", "url", targetURL, "error", err)
+	}
+}
+
+// pageBase returns the URL a cached page was served from, which its
+// relative URLs resolve against.
+func pageBase(page *pagecache.Page) *url.URL {
+	raw := page.URL
+	if page.Location != "" {
+		raw = page.Location
+	}
+	u, _ := url.Parse(raw) // validated before it was fetched
+	return u
+}
+
+// isMarkup reports whether a document of media type mt is markup other
+// than HTML that a browser may run scripts in, such as SVG or XML.
+func isMarkup(mt string) bool {
+	switch mt {
+	case "text/xml", "application/xml", "text/xsl":
+		return true
+	}
+	return strings.HasSuffix(mt, "+xml")
+}
+
+// isProxyAsset reports whether ProxyAsset serves content of contentType.
+func isProxyAsset(contentType string) bool {
+	mt, _, _ := mime.ParseMediaType(contentType)
+	switch {
+	case strings.HasPrefix(mt, "image/"), strings.HasPrefix(mt, "font/"),
+		strings.HasPrefix(mt, "application/font-"), strings.HasPrefix(mt, "application/x-font-"):
+		return true
+	}
+	return mt == "text/css" || mt == "application/vnd.ms-fontobject"
+}
+
+// writeProxiedHTML writes a clean copy of an HTML or XHTML page fetched
+// from base for the reader's iframe. The copy is HTML either way.
+func writeProxiedHTML(ctx context.Context, w http.ResponseWriter, base *url.URL, contentType string, status int, body []byte) {
+	cleaned, err := cleanhtml.Clean(bytes.NewReader(body), base, proxyAssetURL)
+	if err != nil {
+		slog.WarnContext(ctx, "failed to clean proxied page", "url", base.String(), "error", err)
+		writeError(w, http.StatusBadGateway, "failed to read page")
+		return
+	}
+
+	served := "text/html"
+	if _, params, err := mime.ParseMediaType(contentType); err == nil && params["charset"] != "" {
+		served = mime.FormatMediaType(served, map[string]string{"charset": params["charset"]})
+	}
+	w.Header().Set("Content-Type", served)
+	// Do NOT copy X-Frame-Options or CSP headers from upstream. Our API
+	// policy would stop the page loading its images and styles, so replace
+	// it with one for proxied pages.
+	w.Header().Set("Content-Security-Policy", proxyCSP)
+	w.WriteHeader(status)
+	w.Write(cleaned) //nolint:errcheck
+}
+
+// writeProxiedAsset writes an asset fetched from base, rewriting the URLs
+// in stylesheets.
+func writeProxiedAsset(w http.ResponseWriter, base *url.URL, contentType string, body []byte) {
+	if mt, _, _ := mime.ParseMediaType(contentType); mt == "text/css" {
+		body = []byte(cleanhtml.CSS(string(body), base, proxyAssetURL))
 	}
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Cache-Control", "private, max-age=3600")
+	w.WriteHeader(http.StatusOK)
+	w.Write(body) //nolint:errcheck
 }
@@ -0,0 +1,187 @@
+package handlers
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/hoanghai1803/apricot/internal/netguard"
+	"github.com/hoanghai1803/apricot/internal/pagecache"
+)
+
+func TestProxyPage(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Header().Set("X-Frame-Options", "DENY")
+		io.WriteString(w, `<html><head><title>Post</title><script src="/app.js"></script></head><body><img src="/a.png">Hello</body></html>`)
+	}))
+	defer upstream.Close()
+
+	proxy := func(guard *netguard.Guard) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodGet, "/api/proxy?url="+url.QueryEscape(upstream.URL+"/post"), nil)
+		w := httptest.NewRecorder()
+		ProxyPage(guard, nil)(w, r)
+		return w
+	}
+
+	t.Run("blocked", func(t *testing.T) {
+		guard, _ := netguard.New(nil)
+		w := proxy(guard)
+		if w.Code != http.StatusForbidden {
+			t.Errorf("got status %d, want %d for a loopback URL", w.Code, http.StatusForbidden)
+		}
+	})
+
+	t.Run("allowed", func(t *testing.T) {
+		guard, _ := netguard.New([]string{"127.0.0.0/8", "::1"})
+		w := proxy(guard)
+		if w.Code != http.StatusOK {
+			t.Fatalf("got status %d, want %d: %s", w.Code, http.StatusOK, w.Body)
+		}
+		body := w.Body.String()
+		if !strings.Contains(body, `<base target="_blank"/>`) {
+			t.Errorf("body = %q, want a <base> tag opening links in new tabs", body)
+		}
+		if want := `src="/api/proxy/asset?url=` + url.QueryEscape(upstream.URL+"/a.png") + `"`; !strings.Contains(body, want) {
+			t.Errorf("body = %q, want the image loaded through the proxy (%s)", body, want)
+		}
+		if strings.Contains(body, "<script") {
+			t.Errorf("body = %q, want scripts removed", body)
+		}
+		if got := w.Header().Get("X-Frame-Options"); got != "" {
+			t.Errorf("X-Frame-Options = %q, want upstream's header dropped", got)
+		}
+		if got := w.Header().Get("Content-Security-Policy"); got != proxyCSP {
+			t.Errorf("Content-Security-Policy = %q, want %q", got, proxyCSP)
+		}
+	})
+}
+
+func TestProxyPage_Cache(t *testing.T) {
+	fetches := 0
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetches++
+		w.Header().Set("Content-Type", "text/html")
+		if r.URL.Path == "/live" {
+			w.Header().Set("Cache-Control", "no-store")
+		}
+		io.WriteString(w, "<html><head></head><body>Hello</body></html>")
+	}))
+	defer upstream.Close()
+
+	guard, _ := netguard.New([]string{"127.0.0.0/8", "::1"})
+	cache, err := pagecache.Open(t.TempDir(), 1<<20)
+	if err != nil {
+		t.Fatalf("pagecache.Open() error: %v", err)
+	}
+	handler := ProxyPage(guard, cache)
+
+	get := func(path string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodGet, "/api/proxy?url="+url.QueryEscape(upstream.URL+path), nil)
+		w := httptest.NewRecorder()
+		handler(w, r)
+		if w.Code != http.StatusOK {
+			t.Fatalf("GET %s: got status %d, want %d", path, w.Code, http.StatusOK)
+		}
+		return w
+	}
+
+	for i, want := range []string{"MISS", "HIT"} {
+		w := get("/post")
+		if got := w.Header().Get("X-Cache"); got != want {
+			t.Errorf("request %d: X-Cache = %q, want %q", i+1, got, want)
+		}
+		if !strings.Contains(w.Body.String(), `<base target="_blank"/>`) {
+			t.Errorf("request %d: body = %q, want the page cleaned", i+1, w.Body)
+		}
+	}
+	if fetches != 1 {
+		t.Errorf("upstream fetched %d times, want 1", fetches)
+	}
+
+	get("/live")
+	get("/live")
+	if fetches != 3 {
+		t.Errorf("upstream fetched %d times, want a no-store page fetched every time", fetches)
+	}
+}
+
+func TestProxyPage_ContentTypes(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/page.xhtml":
+			w.Header().Set("Content-Type", "application/xhtml+xml; charset=utf-8")
+			io.WriteString(w, `<html xmlns="http://www.w3.org/1999/xhtml"><body><script>alert(1)</script>Hello</body></html>`)
+		case "/image.svg":
+			w.Header().Set("Content-Type", "image/svg+xml")
+			io.WriteString(w, `<svg xmlns="http://www.w3.org/2000/svg"><script>alert(1)</script></svg>`)
+		case "/big.pdf":
+			w.Header().Set("Content-Type", "application/pdf")
+			w.Write(make([]byte, maxProxyBytes+1024))
+		}
+	}))
+	defer upstream.Close()
+
+	guard, _ := netguard.New([]string{"127.0.0.0/8", "::1"})
+	get := func(path string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodGet, "/api/proxy?url="+url.QueryEscape(upstream.URL+path), nil)
+		w := httptest.NewRecorder()
+		ProxyPage(guard, nil)(w, r)
+		return w
+	}
+
+	w := get("/page.xhtml")
+	if w.Code != http.StatusOK || strings.Contains(w.Body.String(), "<script") {
+		t.Errorf("XHTML: status %d, body %q; want it cleaned", w.Code, w.Body)
+	}
+	if got := w.Header().Get("Content-Type"); got != "text/html; charset=utf-8" {
+		t.Errorf("XHTML: Content-Type = %q, want it served as HTML", got)
+	}
+
+	if w = get("/image.svg"); w.Code != http.StatusUnsupportedMediaType {
+		t.Errorf("SVG: got status %d, want %d", w.Code, http.StatusUnsupportedMediaType)
+	}
+
+	w = get("/big.pdf")
+	if w.Code != http.StatusOK || w.Body.Len() != maxProxyBytes {
+		t.Errorf("PDF: status %d, %d bytes; want %d bytes", w.Code, w.Body.Len(), maxProxyBytes)
+	}
+}
+
+func TestProxyAsset(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/css/site.css":
+			w.Header().Set("Content-Type", "text/css; charset=utf-8")
+			io.WriteString(w, ".hero { background: url(../img/hero.jpg) }")
+		default:
+			w.Header().Set("Content-Type", "text/html")
+			io.WriteString(w, "<html></html>")
+		}
+	}))
+	defer upstream.Close()
+
+	guard, _ := netguard.New([]string{"127.0.0.0/8", "::1"})
+	handler := ProxyAsset(guard, nil)
+	get := func(path string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodGet, "/api/proxy/asset?url="+url.QueryEscape(upstream.URL+path), nil)
+		w := httptest.NewRecorder()
+		handler(w, r)
+		return w
+	}
+
+	w := get("/css/site.css")
+	if w.Code != http.StatusOK {
+		t.Fatalf("got status %d, want %d", w.Code, http.StatusOK)
+	}
+	if want := `url("/api/proxy/asset?url=` + url.QueryEscape(upstream.URL+"/img/hero.jpg") + `")`; !strings.Contains(w.Body.String(), want) {
+		t.Errorf("stylesheet = %q, want its URLs rewritten to %s", w.Body, want)
+	}
+
+	if w := get("/post"); w.Code != http.StatusUnsupportedMediaType {
+		t.Errorf("HTML page: got status %d, want %d", w.Code, http.StatusUnsupportedMediaType)
+	}
+}
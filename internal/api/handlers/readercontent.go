@@ -0,0 +1,85 @@
+package handlers
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/url"
+
+	"github.com/hoanghai1803/apricot/internal/cleanhtml"
+	"github.com/hoanghai1803/apricot/internal/feeds"
+	"github.com/hoanghai1803/apricot/internal/storage"
+)
+
+// BlogContent is the response of GET /api/blogs/{id}/content: a post's
+// article as sanitized HTML for the reader view.
+type BlogContent struct {
+	BlogID int64  `json:"blog_id"`
+	Title  string `json:"title"`
+	URL    string `json:"url"`
+	HTML   string `json:"html"`
+}
+
+// GetBlogContent handles GET /api/blogs/{id}/content. It returns the post's
+// main content as HTML the reader can show in its own page (see
+// cleanhtml.Article), extracting it from the post's page on first request
+// and storing it until the post's content changes; the X-Cache response
+// header says whether it was stored. A page that can't be fetched is a 502,
+// and one with no readable content a 422, in which case the reader falls
+// back to the page proxy.
+func GetBlogContent(store storage.Store, fetcher *feeds.Fetcher) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		id, err := parseID(r, "id")
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		blog, err := store.GetBlogByID(ctx, id)
+		if err != nil {
+			if errors.Is(err, storage.ErrNotFound) {
+				writeError(w, http.StatusNotFound, "Blog not found")
+				return
+			}
+			slog.ErrorContext(ctx, "failed to get blog", "blog_id", id, "error", err)
+			writeError(w, http.StatusInternalServerError, "Failed to get blog")
+			return
+		}
+		content := BlogContent{BlogID: blog.ID, Title: blog.Title, URL: blog.URL}
+
+		content.HTML, err = store.GetReaderContent(ctx, id)
+		if err == nil {
+			w.Header().Set("X-Cache", "HIT")
+			writeJSON(w, http.StatusOK, content)
+			return
+		}
+		if !errors.Is(err, storage.ErrNotFound) {
+			slog.WarnContext(ctx, "failed to get stored reader content", "blog_id", id, "error", err)
+		}
+
+		page, err := url.Parse(blog.URL)
+		if err != nil || (page.Scheme != "http" && page.Scheme != "https") {
+			writeError(w, http.StatusUnprocessableEntity, "Post has no web page to read")
+			return
+		}
+		fragment, err := fetcher.ExtractArticleHTML(ctx, blog.URL)
+		if err != nil {
+			slog.WarnContext(ctx, "reader content extraction failed", "url", blog.URL, "error", err)
+			writeStageError(ctx, w, err, "extracting article", http.StatusBadGateway, "Failed to extract article")
+			return
+		}
+		content.HTML = cleanhtml.Article(fragment, page, proxyAssetURL)
+		if content.HTML == "" {
+			writeError(w, http.StatusUnprocessableEntity, "No readable content found on the page")
+			return
+		}
+
+		if err := store.SaveReaderContent(ctx, id, content.HTML); err != nil {
+			slog.WarnContext(ctx, "failed to store reader content", "blog_id", id, "error", err)
+		}
+		w.Header().Set("X-Cache", "MISS")
+		writeJSON(w, http.StatusOK, content)
+	}
+}
@@ -0,0 +1,86 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/hoanghai1803/apricot/internal/feeds"
+	"github.com/hoanghai1803/apricot/internal/models"
+)
+
+func TestGetBlogContent(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+
+	var fetches atomic.Int32
+	site := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetches.Add(1)
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write([]byte(`<!doctype html><html><head><title>Consensus</title></head><body>
+			<nav>Home | About</nav>
+			<article><h1>Consensus</h1>
+			<p>Raft elects a leader, which replicates a log to its followers. ` + strings.Repeat("Each entry is committed once a majority stores it. ", 20) + `</p>
+			<p onclick="track()">See <a href="/raft.pdf">the paper</a>.</p>
+			<script>track()</script>
+			<img src="/diagram.png" alt="Diagram"></article></body></html>`)) //nolint:errcheck
+	}))
+	defer site.Close()
+
+	blogID, err := store.UpsertBlog(ctx, &models.Blog{
+		SourceID:  1,
+		Title:     "Consensus",
+		URL:       site.URL + "/consensus",
+		FetchedAt: time.Now(),
+	})
+	if err != nil {
+		t.Fatalf("UpsertBlog: %v", err)
+	}
+
+	get := func(id string) *httptest.ResponseRecorder {
+		t.Helper()
+		w := httptest.NewRecorder()
+		GetBlogContent(store, feeds.NewFetcher(nil)).ServeHTTP(w,
+			withURLParams(httptest.NewRequest(http.MethodGet, "/api/blogs/x/content", nil), "id", id))
+		return w
+	}
+
+	w := get(jsonInt64(blogID))
+	if w.Code != http.StatusOK || w.Header().Get("X-Cache") != "MISS" {
+		t.Fatalf("got status %d, X-Cache %q, want %d, MISS; body: %s", w.Code, w.Header().Get("X-Cache"), http.StatusOK, w.Body.String())
+	}
+	var content BlogContent
+	if err := json.NewDecoder(w.Body).Decode(&content); err != nil {
+		t.Fatalf("decoding response: %v", err)
+	}
+	if content.BlogID != blogID || content.Title != "Consensus" {
+		t.Errorf("content = %+v, want the blog's ID and title", content)
+	}
+	for _, want := range []string{"Raft elects a leader", `href="` + site.URL + `/raft.pdf"`, `src="/api/proxy/asset?url=`} {
+		if !strings.Contains(content.HTML, want) {
+			t.Errorf("HTML lacks %s\n%s", want, content.HTML)
+		}
+	}
+	for _, unwanted := range []string{"<script", "onclick", "track()"} {
+		if strings.Contains(content.HTML, unwanted) {
+			t.Errorf("HTML still contains %s\n%s", unwanted, content.HTML)
+		}
+	}
+
+	// The second request is served from the store.
+	if w := get(jsonInt64(blogID)); w.Code != http.StatusOK || w.Header().Get("X-Cache") != "HIT" {
+		t.Errorf("second request: got status %d, X-Cache %q, want %d, HIT", w.Code, w.Header().Get("X-Cache"), http.StatusOK)
+	}
+	if n := fetches.Load(); n != 1 {
+		t.Errorf("page fetched %d times, want once", n)
+	}
+
+	if w := get("99999"); w.Code != http.StatusNotFound {
+		t.Errorf("unknown blog: got status %d, want %d", w.Code, http.StatusNotFound)
+	}
+}
@@ -1,30 +1,89 @@
 package handlers
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"log/slog"
 	"net/http"
 	"net/url"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/hoanghai1803/apricot/internal/ai"
 	"github.com/hoanghai1803/apricot/internal/config"
 	"github.com/hoanghai1803/apricot/internal/feeds"
 	"github.com/hoanghai1803/apricot/internal/models"
+	"github.com/hoanghai1803/apricot/internal/netguard"
+	"github.com/hoanghai1803/apricot/internal/notify"
 	"github.com/hoanghai1803/apricot/internal/storage"
 )
 
-// GetReadingList handles GET /api/reading-list. It returns all reading list
-// items, optionally filtered by the "status" query parameter.
-func GetReadingList(store *storage.Store) http.HandlerFunc {
+// Page sizes of GET /api/reading-list.
+const (
+	defaultReadingListLimit = 50
+	maxReadingListLimit     = 200
+)
+
+// GetReadingList handles GET /api/reading-list. It returns a page of
+// reading list items, optionally filtered by the "status", "difficulty", and
+// "category" query parameters, with the total number of matching items.
+// Snoozed items are hidden until their snooze ends; "snoozed=only" lists
+// just them and "snoozed=include" lists everything. "limit" and "offset"
+// select the page; the limit defaults to 50 and is capped at 200, so
+// clients page through a long list using the total. Post content is left
+// out; GET /api/reading-list/{id} has it.
+func GetReadingList(store storage.Store) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		ctx := r.Context()
-		status := r.URL.Query().Get("status")
+		filter := storage.ReadingListFilter{
+			Status:         r.URL.Query().Get("status"),
+			Difficulty:     r.URL.Query().Get("difficulty"),
+			Category:       r.URL.Query().Get("category"),
+			Snoozed:        storage.SnoozeHide,
+			WithoutContent: true,
+		}
+		switch r.URL.Query().Get("snoozed") {
+		case "":
+		case "only":
+			filter.Snoozed = storage.SnoozeOnly
+		case "include":
+			filter.Snoozed = ""
+		default:
+			writeError(w, http.StatusBadRequest, "snoozed must be one of only, include")
+			return
+		}
+
+		if filter.Difficulty != "" && !models.IsValidDifficulty(filter.Difficulty) {
+			writeError(w, http.StatusBadRequest, "difficulty must be one of intro, intermediate, deep-dive")
+			return
+		}
+		if filter.Category != "" && !models.IsValidCategory(filter.Category) {
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("unknown category %q", filter.Category))
+			return
+		}
+		limit, offset, err := parsePage(r, defaultReadingListLimit)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		if limit == 0 {
+			limit = defaultReadingListLimit
+		}
+		limit = min(limit, maxReadingListLimit)
+		filter.Limit, filter.Offset = limit, offset
 
-		items, err := store.GetReadingList(ctx, status)
+		items, err := store.GetReadingListFiltered(ctx, filter)
 		if err != nil {
-			slog.Error("failed to get reading list", "error", err)
+			slog.ErrorContext(ctx, "failed to get reading list", "error", err)
+			writeError(w, http.StatusInternalServerError, "Failed to get reading list")
+			return
+		}
+		total, err := store.CountReadingList(ctx, filter)
+		if err != nil {
+			slog.ErrorContext(ctx, "failed to count reading list", "error", err)
 			writeError(w, http.StatusInternalServerError, "Failed to get reading list")
 			return
 		}
@@ -33,27 +92,20 @@ func GetReadingList(store *storage.Store) http.HandlerFunc {
 			items = []models.ReadingListItem{}
 		}
 
-		// Calculate and cache reading time for items that don't have it yet.
-		for i := range items {
-			blog := items[i].Blog
-			if blog != nil && blog.ReadingTimeMinutes == nil && blog.FullContent != "" {
-				minutes := feeds.CalculateReadingTime(blog.FullContent)
-				if minutes > 0 {
-					if err := store.UpdateReadingTime(ctx, blog.ID, minutes); err != nil {
-						slog.Warn("failed to cache reading time", "blog_id", blog.ID, "error", err)
-					}
-					items[i].Blog.ReadingTimeMinutes = &minutes
-				}
-			}
-		}
+		fillReadingTimes(ctx, store, items)
 
-		writeJSON(w, http.StatusOK, items)
+		writeJSON(w, http.StatusOK, models.ReadingListPage{
+			Items:  items,
+			Total:  total,
+			Limit:  limit,
+			Offset: offset,
+		})
 	}
 }
 
 // AddToReadingList handles POST /api/reading-list. It adds a blog post to
-// the reading list by blog_id.
-func AddToReadingList(store *storage.Store) http.HandlerFunc {
+// the reading list by blog_id and emits item.added.
+func AddToReadingList(store storage.Store, notifier *notify.Notifier) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		ctx := r.Context()
 
@@ -71,18 +123,44 @@ func AddToReadingList(store *storage.Store) http.HandlerFunc {
 		}
 
 		if err := store.AddToReadingList(ctx, body.BlogID); err != nil {
-			slog.Warn("failed to add to reading list", "blog_id", body.BlogID, "error", err)
+			slog.WarnContext(ctx, "failed to add to reading list", "blog_id", body.BlogID, "error", err)
 			writeError(w, http.StatusBadRequest, err.Error())
 			return
 		}
+		notifyAdded(ctx, store, notifier, body.BlogID)
 
 		writeJSON(w, http.StatusCreated, map[string]string{"status": "added"})
 	}
 }
 
+// fillReadingTimes calculates and caches the reading time of items that
+// don't have it yet. Items listed without content have it loaded for just
+// these posts.
+func fillReadingTimes(ctx context.Context, store storage.Store, items []models.ReadingListItem) {
+	for i := range items {
+		blog := items[i].Blog
+		if blog == nil || blog.ReadingTimeMinutes != nil {
+			continue
+		}
+		full, err := store.GetBlogByID(ctx, blog.ID)
+		if err != nil || full.FullContent == "" {
+			continue
+		}
+		minutes := feeds.CalculateReadingTime(full.FullContent)
+		if minutes > 0 {
+			if err := store.UpdateReadingTime(ctx, blog.ID, minutes); err != nil {
+				slog.WarnContext(ctx, "failed to cache reading time", "blog_id", blog.ID, "error", err)
+			}
+			blog.ReadingTimeMinutes = &minutes
+		}
+	}
+}
+
 // UpdateReadingListItem handles PATCH /api/reading-list/{id}. It updates the
-// status and/or notes of a reading list item.
-func UpdateReadingListItem(store *storage.Store) http.HandlerFunc {
+// status, notes, and/or snooze of a reading list item. "snoozed_until" is an
+// RFC 3339 time in the future, or an empty string to cancel the snooze.
+// Marking an item read emits item.finished.
+func UpdateReadingListItem(store storage.Store, notifier *notify.Notifier) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		ctx := r.Context()
 
@@ -93,45 +171,209 @@ func UpdateReadingListItem(store *storage.Store) http.HandlerFunc {
 		}
 
 		var body struct {
-			Status *string `json:"status"`
-			Notes  *string `json:"notes"`
+			Status       *string `json:"status"`
+			Notes        *string `json:"notes"`
+			SnoozedUntil *string `json:"snoozed_until"`
 		}
 		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
 			writeError(w, http.StatusBadRequest, "Invalid JSON body")
 			return
 		}
 
-		if body.Status != nil {
-			if err := store.UpdateReadingListStatus(ctx, id, *body.Status); err != nil {
-				if errors.Is(err, storage.ErrNotFound) {
-					writeError(w, http.StatusNotFound, "Reading list item not found")
-					return
-				}
-				slog.Error("failed to update reading list status", "id", id, "error", err)
-				writeError(w, http.StatusBadRequest, err.Error())
+		var snoozeUntil *time.Time
+		if body.SnoozedUntil != nil && *body.SnoozedUntil != "" {
+			t, err := time.Parse(time.RFC3339, *body.SnoozedUntil)
+			if err != nil {
+				writeError(w, http.StatusBadRequest, "snoozed_until must be an RFC 3339 time")
+				return
+			}
+			if !t.After(time.Now()) {
+				writeError(w, http.StatusBadRequest, "snoozed_until must be in the future")
 				return
 			}
+			snoozeUntil = &t
 		}
 
-		if body.Notes != nil {
-			if err := store.UpdateReadingListNotes(ctx, id, *body.Notes); err != nil {
-				if errors.Is(err, storage.ErrNotFound) {
-					writeError(w, http.StatusNotFound, "Reading list item not found")
-					return
-				}
-				slog.Error("failed to update reading list notes", "id", id, "error", err)
-				writeError(w, http.StatusInternalServerError, "Failed to update notes")
+		if body.Status != nil && !storage.IsValidStatus(*body.Status) {
+			writeError(w, http.StatusBadRequest,
+				fmt.Sprintf("invalid reading list status %q: must be one of unread, reading, read, archived", *body.Status))
+			return
+		}
+
+		ifVersion, err := parseIfMatch(r)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		var finishing []int64
+		if body.Status != nil && *body.Status == "read" {
+			finishing = unfinished(ctx, store, notifier, id)
+		}
+
+		version, err := store.UpdateReadingListItem(ctx, id, storage.ReadingListUpdate{
+			Status:       body.Status,
+			Notes:        body.Notes,
+			Snooze:       body.SnoozedUntil != nil,
+			SnoozedUntil: snoozeUntil,
+			IfVersion:    ifVersion,
+		})
+		if err != nil {
+			switch {
+			case errors.Is(err, storage.ErrNotFound):
+				writeError(w, http.StatusNotFound, "Reading list item not found")
+			case errors.Is(err, storage.ErrVersionConflict):
+				writeError(w, http.StatusPreconditionFailed,
+					"This item was changed in another tab or window. Reload it and try again")
+			default:
+				slog.ErrorContext(ctx, "failed to update reading list item", "id", id, "error", err)
+				writeError(w, http.StatusInternalServerError, "Failed to update reading list item")
+			}
+			return
+		}
+
+		notifyFinished(ctx, store, notifier, finishing)
+
+		setETag(w, version)
+		writeJSON(w, http.StatusOK, map[string]any{"status": "updated", "version": version})
+	}
+}
+
+// ArchiveReadItems handles POST /api/reading-list/archive-read. It moves
+// every item marked "read" to "archived", which keeps it (and its reading
+// history) off the active list without deleting it.
+func ArchiveReadItems(store storage.Store) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		n, err := store.ArchiveReadItems(r.Context())
+		if err != nil {
+			slog.ErrorContext(r.Context(), "failed to archive read items", "error", err)
+			writeError(w, http.StatusInternalServerError, "Failed to archive read items")
+			return
+		}
+
+		writeJSON(w, http.StatusOK, map[string]int64{"archived": n})
+	}
+}
+
+// maxBulkItems is the most reading list items a bulk request may change.
+const maxBulkItems = 500
+
+// BulkUpdateReadingList handles POST /api/reading-list/bulk. The body names
+// the item IDs and an action: "set_status" (with "status"), "add_tag" (with
+// "tag"), "delete", or "archive". The action is applied to every item in one
+// transaction, so either all items change or none do; an unknown ID fails
+// the whole request with 404. Items newly marked read emit item.finished.
+func BulkUpdateReadingList(store storage.Store, notifier *notify.Notifier) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		var body struct {
+			IDs    []int64 `json:"ids"`
+			Action string  `json:"action"`
+			Status string  `json:"status"`
+			Tag    string  `json:"tag"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			writeError(w, http.StatusBadRequest, "Invalid JSON body")
+			return
+		}
+
+		if len(body.IDs) == 0 {
+			writeError(w, http.StatusBadRequest, "ids is required")
+			return
+		}
+		if len(body.IDs) > maxBulkItems {
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("at most %d ids per request", maxBulkItems))
+			return
+		}
+		switch body.Action {
+		case storage.BulkSetStatus:
+			if !storage.IsValidStatus(body.Status) {
+				writeError(w, http.StatusBadRequest, "status must be one of unread, reading, read, archived")
+				return
+			}
+		case storage.BulkAddTag:
+			if strings.TrimSpace(body.Tag) == "" {
+				writeError(w, http.StatusBadRequest, "tag is required")
+				return
+			}
+		case storage.BulkDelete, storage.BulkArchive:
+		default:
+			writeError(w, http.StatusBadRequest, "action must be one of set_status, add_tag, delete, archive")
+			return
+		}
+
+		var finishing []int64
+		if body.Action == storage.BulkSetStatus && body.Status == "read" {
+			finishing = unfinished(ctx, store, notifier, body.IDs...)
+		}
+
+		action := storage.BulkAction{Action: body.Action, Status: body.Status, Tag: body.Tag}
+		if err := store.BulkUpdateReadingList(ctx, body.IDs, action); err != nil {
+			if errors.Is(err, storage.ErrNotFound) {
+				writeError(w, http.StatusNotFound, err.Error())
 				return
 			}
+			slog.ErrorContext(ctx, "failed to apply bulk action", "action", body.Action, "count", len(body.IDs), "error", err)
+			writeError(w, http.StatusInternalServerError, "Failed to update reading list")
+			return
 		}
+		notifyFinished(ctx, store, notifier, finishing)
 
 		writeJSON(w, http.StatusOK, map[string]string{"status": "updated"})
 	}
 }
 
+// ReorderReadingList handles PATCH /api/reading-list/reorder. The body lists
+// item IDs in the order they should be read; they move to the front of the
+// queue that GET /api/reading-list returns, ahead of items placed earlier.
+// An unknown ID fails the whole request with 404.
+func ReorderReadingList(store storage.Store) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		var body struct {
+			IDs []int64 `json:"ids"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			writeError(w, http.StatusBadRequest, "Invalid JSON body")
+			return
+		}
+
+		if len(body.IDs) == 0 {
+			writeError(w, http.StatusBadRequest, "ids is required")
+			return
+		}
+		if len(body.IDs) > maxBulkItems {
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("at most %d ids per request", maxBulkItems))
+			return
+		}
+		seen := make(map[int64]bool, len(body.IDs))
+		for _, id := range body.IDs {
+			if seen[id] {
+				writeError(w, http.StatusBadRequest, fmt.Sprintf("id %d is listed more than once", id))
+				return
+			}
+			seen[id] = true
+		}
+
+		if err := store.ReorderReadingList(ctx, body.IDs); err != nil {
+			if errors.Is(err, storage.ErrNotFound) {
+				writeError(w, http.StatusNotFound, err.Error())
+				return
+			}
+			slog.ErrorContext(ctx, "failed to reorder reading list", "count", len(body.IDs), "error", err)
+			writeError(w, http.StatusInternalServerError, "Failed to reorder reading list")
+			return
+		}
+
+		writeJSON(w, http.StatusOK, map[string]string{"status": "reordered"})
+	}
+}
+
 // DeleteReadingListItem handles DELETE /api/reading-list/{id}. It removes
 // a reading list item by its ID.
-func DeleteReadingListItem(store *storage.Store) http.HandlerFunc {
+func DeleteReadingListItem(store storage.Store) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		ctx := r.Context()
 
@@ -146,7 +388,7 @@ func DeleteReadingListItem(store *storage.Store) http.HandlerFunc {
 				writeError(w, http.StatusNotFound, "Reading list item not found")
 				return
 			}
-			slog.Error("failed to remove from reading list", "id", id, "error", err)
+			slog.ErrorContext(ctx, "failed to remove from reading list", "id", id, "error", err)
 			writeError(w, http.StatusInternalServerError, "Failed to remove from reading list")
 			return
 		}
@@ -158,7 +400,7 @@ func DeleteReadingListItem(store *storage.Store) http.HandlerFunc {
 // GetReadingListItem handles GET /api/reading-list/{id}. It returns a single
 // reading list item with full blog content. On first access, it calculates and
 // caches the reading time.
-func GetReadingListItem(store *storage.Store, fetcher *feeds.Fetcher) http.HandlerFunc {
+func GetReadingListItem(store storage.Store, fetcher *feeds.Fetcher) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		ctx := r.Context()
 
@@ -174,7 +416,7 @@ func GetReadingListItem(store *storage.Store, fetcher *feeds.Fetcher) http.Handl
 				writeError(w, http.StatusNotFound, "Reading list item not found")
 				return
 			}
-			slog.Error("failed to get reading list item", "id", id, "error", err)
+			slog.ErrorContext(ctx, "failed to get reading list item", "id", id, "error", err)
 			writeError(w, http.StatusInternalServerError, "Failed to get reading list item")
 			return
 		}
@@ -182,14 +424,15 @@ func GetReadingListItem(store *storage.Store, fetcher *feeds.Fetcher) http.Handl
 		// Try to extract content if missing (some sites fail during discovery
 		// due to transient errors — retrying here may succeed).
 		if item.Blog != nil && item.Blog.FullContent == "" && item.Blog.URL != "" {
-			slog.Info("attempting on-demand content extraction", "url", item.Blog.URL)
+			slog.InfoContext(ctx, "attempting on-demand content extraction", "url", item.Blog.URL)
 			content, err := fetcher.ExtractArticle(ctx, item.Blog.URL)
 			if err != nil {
-				slog.Debug("on-demand extraction failed", "url", item.Blog.URL, "error", err)
+				slog.DebugContext(ctx, "on-demand extraction failed", "url", item.Blog.URL, "error", err)
 			} else if content != "" {
 				item.Blog.FullContent = content
+				item.Blog.ContentHash = feeds.HashContent(content)
 				if _, err := store.UpsertBlog(ctx, item.Blog); err != nil {
-					slog.Warn("failed to save extracted content", "blog_id", item.Blog.ID, "error", err)
+					slog.WarnContext(ctx, "failed to save extracted content", "blog_id", item.Blog.ID, "error", err)
 				}
 			}
 		}
@@ -199,19 +442,52 @@ func GetReadingListItem(store *storage.Store, fetcher *feeds.Fetcher) http.Handl
 			minutes := feeds.CalculateReadingTime(item.Blog.FullContent)
 			if minutes > 0 {
 				if err := store.UpdateReadingTime(ctx, item.Blog.ID, minutes); err != nil {
-					slog.Warn("failed to cache reading time", "blog_id", item.Blog.ID, "error", err)
+					slog.WarnContext(ctx, "failed to cache reading time", "blog_id", item.Blog.ID, "error", err)
 				}
 				item.Blog.ReadingTimeMinutes = &minutes
 			}
 		}
 
+		setETag(w, item.Version)
 		writeJSON(w, http.StatusOK, item)
 	}
 }
 
+// GetNoteHistory handles GET /api/reading-list/{id}/notes/history. It
+// returns the saved revisions of the item's Markdown notes, newest first,
+// so an overwritten version can be copied back.
+func GetNoteHistory(store storage.Store) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		id, err := parseID(r, "id")
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		revisions, err := store.GetNoteHistory(ctx, id)
+		if err != nil {
+			if errors.Is(err, storage.ErrNotFound) {
+				writeError(w, http.StatusNotFound, "Reading list item not found")
+				return
+			}
+			slog.ErrorContext(ctx, "failed to get note history", "id", id, "error", err)
+			writeError(w, http.StatusInternalServerError, "Failed to get note history")
+			return
+		}
+
+		writeJSON(w, http.StatusOK, revisions)
+	}
+}
+
 // UpdateReadingProgress handles PATCH /api/reading-list/{id}/progress.
-// It updates the scroll progress (0-100) and auto-marks as "read" at >= 90%.
-func UpdateReadingProgress(store *storage.Store) http.HandlerFunc {
+// It updates the scroll progress (0-100) and auto-marks as "read" at >= 90%,
+// emitting item.finished the first time. An optional "scroll_position", a
+// fraction from 0 to 1, records where the reader left off, which
+// GET /api/reading-list/{id} returns with the time it was last read so
+// another device can resume there.
+func UpdateReadingProgress(store storage.Store, notifier *notify.Notifier) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		ctx := r.Context()
 
@@ -222,7 +498,8 @@ func UpdateReadingProgress(store *storage.Store) http.HandlerFunc {
 		}
 
 		var body struct {
-			Progress int `json:"progress"`
+			Progress       int      `json:"progress"`
+			ScrollPosition *float64 `json:"scroll_position"`
 		}
 		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
 			writeError(w, http.StatusBadRequest, "Invalid JSON body")
@@ -234,12 +511,17 @@ func UpdateReadingProgress(store *storage.Store) http.HandlerFunc {
 			return
 		}
 
-		if err := store.UpdateReadingListProgress(ctx, id, body.Progress); err != nil {
+		if p := body.ScrollPosition; p != nil && (*p < 0 || *p > 1) {
+			writeError(w, http.StatusBadRequest, "scroll_position must be between 0 and 1")
+			return
+		}
+
+		if err := store.UpdateReadingListProgress(ctx, id, body.Progress, body.ScrollPosition); err != nil {
 			if errors.Is(err, storage.ErrNotFound) {
 				writeError(w, http.StatusNotFound, "Reading list item not found")
 				return
 			}
-			slog.Error("failed to update reading progress", "id", id, "error", err)
+			slog.ErrorContext(ctx, "failed to update reading progress", "id", id, "error", err)
 			writeError(w, http.StatusInternalServerError, "Failed to update progress")
 			return
 		}
@@ -247,10 +529,12 @@ func UpdateReadingProgress(store *storage.Store) http.HandlerFunc {
 		// Auto-mark as "read" when progress >= 90%.
 		autoRead := false
 		if body.Progress >= 90 {
+			finishing := unfinished(ctx, store, notifier, id)
 			if err := store.UpdateReadingListStatus(ctx, id, "read"); err != nil {
-				slog.Warn("failed to auto-mark as read", "id", id, "error", err)
+				slog.WarnContext(ctx, "failed to auto-mark as read", "id", id, "error", err)
 			} else {
 				autoRead = true
+				notifyFinished(ctx, store, notifier, finishing)
 			}
 		}
 
@@ -264,10 +548,9 @@ func UpdateReadingProgress(store *storage.Store) http.HandlerFunc {
 // AddCustomBlog handles POST /api/reading-list/custom. It fetches article
 // metadata from a user-provided URL and adds it to the reading list.
 // If an AI provider is configured, it also generates a summary for new blogs.
-func AddCustomBlog(store *storage.Store, fetcher *feeds.Fetcher, aiProvider ai.AIProvider, cfg *config.Config) http.HandlerFunc {
+// It emits item.added once the summary is done.
+func AddCustomBlog(store storage.Store, fetcher *feeds.Fetcher, aiProvider ai.AIProvider, cfg *config.Config, notifier *notify.Notifier) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		ctx := r.Context()
-
 		var body struct {
 			URL    string `json:"url"`
 			Source string `json:"source"`
@@ -277,120 +560,261 @@ func AddCustomBlog(store *storage.Store, fetcher *feeds.Fetcher, aiProvider ai.A
 			return
 		}
 
-		body.URL = strings.TrimSpace(body.URL)
-		body.Source = strings.TrimSpace(body.Source)
-
-		if body.URL == "" {
-			writeError(w, http.StatusBadRequest, "url is required")
+		blogID, err := addCustomURL(r.Context(), store, fetcher, aiProvider, cfg, notifier, body.URL, body.Source)
+		if err != nil {
+			writeError(w, err.status, err.message)
 			return
 		}
 
-		parsed, err := url.ParseRequestURI(body.URL)
-		if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") {
-			writeError(w, http.StatusBadRequest, "url must be a valid HTTP or HTTPS URL")
+		writeJSON(w, http.StatusCreated, map[string]any{
+			"status":  "added",
+			"blog_id": blogID,
+		})
+	}
+}
+
+// maxBatchURLs caps the URLs in one batch add.
+const maxBatchURLs = 100
+
+// batchWorkers is how many URLs of a batch add are fetched at once.
+const batchWorkers = 4
+
+// BatchAddResult is the outcome of one URL of a batch add. Status is
+// "added", "exists" (already on the reading list), or "failed" with Error.
+type BatchAddResult struct {
+	URL    string `json:"url"`
+	Status string `json:"status"`
+	BlogID int64  `json:"blog_id,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+// BatchAddCustomBlogs handles POST /api/reading-list/custom/batch. It adds
+// each of up to 100 URLs as AddCustomBlog does, several at once, and
+// returns the outcome of each in the order given. Blank and repeated URLs
+// are skipped. One URL failing does not fail the others.
+func BatchAddCustomBlogs(store storage.Store, fetcher *feeds.Fetcher, aiProvider ai.AIProvider, cfg *config.Config, notifier *notify.Notifier) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		var body struct {
+			URLs   []string `json:"urls"`
+			Source string   `json:"source"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			writeError(w, http.StatusBadRequest, "Invalid JSON body")
 			return
 		}
 
-		// Check if blog already exists by URL.
-		existing, err := store.GetBlogByURL(ctx, body.URL)
-		if err != nil && !errors.Is(err, storage.ErrNotFound) {
-			slog.Error("failed to check existing blog", "url", body.URL, "error", err)
-			writeError(w, http.StatusInternalServerError, "Failed to check existing blog")
+		var urls []string
+		seen := make(map[string]bool)
+		for _, u := range body.URLs {
+			u = strings.TrimSpace(u)
+			if u != "" && !seen[u] {
+				seen[u] = true
+				urls = append(urls, u)
+			}
+		}
+		if len(urls) == 0 {
+			writeError(w, http.StatusBadRequest, "urls is required")
+			return
+		}
+		if len(urls) > maxBatchURLs {
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("At most %d URLs can be added at once", maxBatchURLs))
 			return
 		}
 
-		var blogID int64
-		if existing != nil {
-			blogID = existing.ID
-		} else {
-			// Fetch article metadata from URL.
-			meta, err := fetcher.ExtractArticleMetadata(ctx, body.URL)
-			if err != nil {
-				slog.Warn("failed to extract article metadata", "url", body.URL, "error", err)
-				writeError(w, http.StatusUnprocessableEntity, "Could not fetch article from URL")
-				return
-			}
+		results := make([]BatchAddResult, len(urls))
+		next := make(chan int)
+		var wg sync.WaitGroup
+		for range min(batchWorkers, len(urls)) {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for i := range next {
+					results[i] = BatchAddResult{URL: urls[i], Status: "added"}
+					blogID, err := addCustomURL(ctx, store, fetcher, aiProvider, cfg, notifier, urls[i], body.Source)
+					switch {
+					case err == nil:
+						results[i].BlogID = blogID
+					case err.status == http.StatusConflict:
+						results[i].Status = "exists"
+					default:
+						results[i].Status, results[i].Error = "failed", err.message
+					}
+				}
+			}()
+		}
+		for i := range urls {
+			next <- i
+		}
+		close(next)
+		wg.Wait()
 
-			title := meta.Title
-			if title == "" {
-				title = body.URL
+		added, failed := 0, 0
+		for _, res := range results {
+			switch res.Status {
+			case "added":
+				added++
+			case "failed":
+				failed++
 			}
+		}
+		slog.InfoContext(ctx, "batch added to reading list", "urls", len(urls), "added", added, "failed", failed)
 
-			// Determine source display name.
-			customSource := body.Source
-			if customSource == "" {
-				customSource = meta.SiteName
-			}
-			if customSource == "" {
-				customSource = parsed.Hostname()
-			}
+		writeJSON(w, http.StatusOK, map[string]any{
+			"results": results,
+			"added":   added,
+			"failed":  failed,
+		})
+	}
+}
 
-			blogID, err = store.CreateCustomBlog(ctx, body.URL, title, meta.Excerpt, meta.TextContent, customSource)
-			if err != nil {
-				if strings.Contains(err.Error(), "UNIQUE constraint failed") {
-					// Race condition: blog was inserted between our check and insert.
-					existing, err2 := store.GetBlogByURL(ctx, body.URL)
-					if err2 != nil {
-						slog.Error("failed to get existing blog after conflict", "url", body.URL, "error", err2)
-						writeError(w, http.StatusInternalServerError, "Failed to add blog")
-						return
-					}
-					blogID = existing.ID
-				} else {
-					slog.Error("failed to create custom blog", "url", body.URL, "error", err)
-					writeError(w, http.StatusInternalServerError, "Failed to save blog")
-					return
-				}
+// addError is why a URL could not be added, as an HTTP status and a
+// message for the user.
+type addError struct {
+	status  int
+	message string
+}
+
+func (e *addError) Error() string { return e.message }
+
+// AddURL adds the post at rawURL to the reading list as AddCustomBlog does
+// and returns its blog ID. It is how the add command saves a post without a
+// server; no event is emitted.
+func AddURL(ctx context.Context, store storage.Store, fetcher *feeds.Fetcher, aiProvider ai.AIProvider, cfg *config.Config, rawURL, source string) (int64, error) {
+	blogID, err := addCustomURL(ctx, store, fetcher, aiProvider, cfg, nil, rawURL, source)
+	if err != nil {
+		return 0, err
+	}
+	return blogID, nil
+}
+
+// addCustomURL adds the post at rawURL to the reading list, fetching its
+// metadata and, with an AI provider, summarizing it first if Apricot does
+// not have it yet, and emits item.added. source names the post's source;
+// empty means the site's name or host.
+func addCustomURL(ctx context.Context, store storage.Store, fetcher *feeds.Fetcher, aiProvider ai.AIProvider, cfg *config.Config, notifier *notify.Notifier, rawURL, source string) (int64, *addError) {
+	rawURL = strings.TrimSpace(rawURL)
+	source = strings.TrimSpace(source)
+
+	if rawURL == "" {
+		return 0, &addError{http.StatusBadRequest, "url is required"}
+	}
+
+	parsed, err := url.ParseRequestURI(rawURL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") {
+		return 0, &addError{http.StatusBadRequest, "url must be a valid HTTP or HTTPS URL"}
+	}
+
+	// Check if blog already exists by URL.
+	existing, err := store.GetBlogByURL(ctx, rawURL)
+	if err != nil && !errors.Is(err, storage.ErrNotFound) {
+		slog.ErrorContext(ctx, "failed to check existing blog", "url", rawURL, "error", err)
+		return 0, &addError{http.StatusInternalServerError, "Failed to check existing blog"}
+	}
+
+	var blogID int64
+	if existing != nil {
+		blogID = existing.ID
+	} else {
+		// Fetch article metadata from URL.
+		meta, err := fetcher.ExtractArticleMetadata(ctx, rawURL)
+		if err != nil {
+			slog.WarnContext(ctx, "failed to extract article metadata", "url", rawURL, "error", err)
+			if errors.Is(err, netguard.ErrBlocked) {
+				return 0, &addError{http.StatusForbidden, blockedMessage}
 			}
+			return 0, &addError{http.StatusUnprocessableEntity, "Could not fetch article from URL"}
 		}
 
-		if err := store.AddToReadingList(ctx, blogID); err != nil {
-			if strings.Contains(err.Error(), "already on the reading list") {
-				writeError(w, http.StatusConflict, err.Error())
-				return
-			}
-			slog.Error("failed to add custom blog to reading list", "blog_id", blogID, "error", err)
-			writeError(w, http.StatusInternalServerError, "Failed to add to reading list")
-			return
+		title := meta.Title
+		if title == "" {
+			title = rawURL
 		}
 
-		// Generate AI summary if none exists yet.
-		if aiProvider != nil {
-			hasSummary, err := store.HasSummary(ctx, blogID)
-			if err != nil {
-				slog.Warn("failed to check summary cache", "blog_id", blogID, "error", err)
-			}
-			if !hasSummary {
-				blog, err := store.GetBlogByID(ctx, blogID)
-				if err != nil {
-					slog.Warn("failed to load blog for summarization", "blog_id", blogID, "error", err)
-				} else {
-					entry := ai.BlogEntry{
-						ID:          blog.ID,
-						Title:       blog.Title,
-						Source:      blog.Source,
-						Description: blog.Description,
-						FullContent: blog.FullContent,
-					}
-					summary, err := aiProvider.Summarize(ctx, entry)
-					if err != nil {
-						slog.Warn("failed to summarize custom blog", "blog_id", blogID, "error", err)
-					} else {
-						if err := store.UpsertSummary(ctx, &models.BlogSummary{
-							BlogID:    blogID,
-							Summary:   summary,
-							ModelUsed: cfg.AI.Model,
-						}); err != nil {
-							slog.Warn("failed to cache custom blog summary", "blog_id", blogID, "error", err)
-						}
-					}
+		// Determine source display name.
+		customSource := source
+		if customSource == "" {
+			customSource = meta.SiteName
+		}
+		if customSource == "" {
+			customSource = parsed.Hostname()
+		}
+
+		blogID, err = store.CreateCustomBlog(ctx, rawURL, title, meta.Excerpt, meta.TextContent, customSource)
+		if err != nil {
+			if strings.Contains(err.Error(), "UNIQUE constraint failed") {
+				// Race condition: blog was inserted between our check and insert.
+				existing, err2 := store.GetBlogByURL(ctx, rawURL)
+				if err2 != nil {
+					slog.ErrorContext(ctx, "failed to get existing blog after conflict", "url", rawURL, "error", err2)
+					return 0, &addError{http.StatusInternalServerError, "Failed to add blog"}
 				}
+				blogID = existing.ID
+			} else {
+				slog.ErrorContext(ctx, "failed to create custom blog", "url", rawURL, "error", err)
+				return 0, &addError{http.StatusInternalServerError, "Failed to save blog"}
 			}
 		}
+	}
 
-		writeJSON(w, http.StatusCreated, map[string]any{
-			"status":  "added",
-			"blog_id": blogID,
-		})
+	if err := store.AddToReadingList(ctx, blogID); err != nil {
+		if errors.Is(err, storage.ErrAlreadyOnList) {
+			return 0, &addError{http.StatusConflict, err.Error()}
+		}
+		slog.ErrorContext(ctx, "failed to add custom blog to reading list", "blog_id", blogID, "error", err)
+		return 0, &addError{http.StatusInternalServerError, "Failed to add to reading list"}
+	}
+
+	// Generate AI summary if none exists yet.
+	if aiProvider != nil {
+		summarizeCustomBlog(ctx, store, aiProvider, cfg, blogID)
+	}
+
+	notifyAdded(ctx, store, notifier, blogID)
+	return blogID, nil
+}
+
+// summarizeCustomBlog summarizes, classifies, and (if enabled) retitles an
+// added post that has no summary yet. Failures are logged; the post stays
+// on the reading list without them.
+func summarizeCustomBlog(ctx context.Context, store storage.Store, aiProvider ai.AIProvider, cfg *config.Config, blogID int64) {
+	hasSummary, err := store.HasSummary(ctx, blogID)
+	if err != nil {
+		slog.WarnContext(ctx, "failed to check summary cache", "blog_id", blogID, "error", err)
+	}
+	if hasSummary {
+		return
+	}
+	blog, err := store.GetBlogByID(ctx, blogID)
+	if err != nil {
+		slog.WarnContext(ctx, "failed to load blog for summarization", "blog_id", blogID, "error", err)
+		return
+	}
+	entry := ai.BlogEntry{
+		ID:          blog.ID,
+		Title:       blog.Title,
+		Source:      blog.Source,
+		Description: blog.Description,
+		FullContent: blog.FullContent,
+	}
+	summary, err := aiProvider.Summarize(ctx, entry)
+	if err != nil {
+		slog.WarnContext(ctx, "failed to summarize custom blog", "blog_id", blogID, "error", err)
+	} else {
+		if err := store.UpsertSummary(ctx, &models.BlogSummary{
+			BlogID:     blogID,
+			Summary:    summary.Text,
+			Difficulty: summary.Difficulty,
+			Category:   summary.Category,
+			ModelUsed:  cfg.AI.Model,
+		}); err != nil {
+			slog.WarnContext(ctx, "failed to cache custom blog summary", "blog_id", blogID, "error", err)
+		}
+		adoptDifficulty(ctx, store, blog, summary.Difficulty)
+	}
+	classifyDifficulty(ctx, store, aiProvider, blog)
+	if titleRewriteEnabled(ctx, store) {
+		rewriteTitle(ctx, store, aiProvider, blog)
 	}
 }
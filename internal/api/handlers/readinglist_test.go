@@ -6,10 +6,13 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/go-chi/chi/v5"
+	"github.com/hoanghai1803/apricot/internal/config"
+	"github.com/hoanghai1803/apricot/internal/feeds"
 	"github.com/hoanghai1803/apricot/internal/models"
 )
 
@@ -43,7 +46,7 @@ func TestReadingListAddAndGet(t *testing.T) {
 	postR := httptest.NewRequest(http.MethodPost, "/api/reading-list", bytes.NewBuffer(body))
 	postW := httptest.NewRecorder()
 
-	AddToReadingList(store).ServeHTTP(postW, postR)
+	AddToReadingList(store, nil).ServeHTTP(postW, postR)
 
 	if postW.Code != http.StatusCreated {
 		t.Fatalf("POST got status %d, want %d; body: %s", postW.Code, http.StatusCreated, postW.Body.String())
@@ -67,10 +70,11 @@ func TestReadingListAddAndGet(t *testing.T) {
 		t.Fatalf("GET got status %d, want %d", getW.Code, http.StatusOK)
 	}
 
-	var items []models.ReadingListItem
-	if err := json.NewDecoder(getW.Body).Decode(&items); err != nil {
+	var page models.ReadingListPage
+	if err := json.NewDecoder(getW.Body).Decode(&page); err != nil {
 		t.Fatalf("decoding GET response: %v", err)
 	}
+	items := page.Items
 
 	if len(items) != 1 {
 		t.Fatalf("got %d items, want 1", len(items))
@@ -92,7 +96,7 @@ func TestReadingListPatchStatus(t *testing.T) {
 	addBody, _ := json.Marshal(map[string]int64{"blog_id": blogID})
 	addR := httptest.NewRequest(http.MethodPost, "/api/reading-list", bytes.NewBuffer(addBody))
 	addW := httptest.NewRecorder()
-	AddToReadingList(store).ServeHTTP(addW, addR)
+	AddToReadingList(store, nil).ServeHTTP(addW, addR)
 
 	if addW.Code != http.StatusCreated {
 		t.Fatalf("add failed with status %d", addW.Code)
@@ -103,10 +107,11 @@ func TestReadingListPatchStatus(t *testing.T) {
 	getW := httptest.NewRecorder()
 	GetReadingList(store).ServeHTTP(getW, getR)
 
-	var items []models.ReadingListItem
-	if err := json.NewDecoder(getW.Body).Decode(&items); err != nil {
+	var page models.ReadingListPage
+	if err := json.NewDecoder(getW.Body).Decode(&page); err != nil {
 		t.Fatalf("decoding items: %v", err)
 	}
+	items := page.Items
 	if len(items) == 0 {
 		t.Fatal("no items in reading list")
 	}
@@ -121,7 +126,7 @@ func TestReadingListPatchStatus(t *testing.T) {
 	rctx.URLParams.Add("id", jsonInt64(itemID))
 	patchR = patchR.WithContext(context.WithValue(patchR.Context(), chi.RouteCtxKey, rctx))
 
-	UpdateReadingListItem(store).ServeHTTP(patchW, patchR)
+	UpdateReadingListItem(store, nil).ServeHTTP(patchW, patchR)
 
 	if patchW.Code != http.StatusOK {
 		t.Fatalf("PATCH got status %d, want %d; body: %s", patchW.Code, http.StatusOK, patchW.Body.String())
@@ -132,10 +137,11 @@ func TestReadingListPatchStatus(t *testing.T) {
 	getW2 := httptest.NewRecorder()
 	GetReadingList(store).ServeHTTP(getW2, getR2)
 
-	var items2 []models.ReadingListItem
-	if err := json.NewDecoder(getW2.Body).Decode(&items2); err != nil {
+	var page2 models.ReadingListPage
+	if err := json.NewDecoder(getW2.Body).Decode(&page2); err != nil {
 		t.Fatalf("decoding items: %v", err)
 	}
+	items2 := page2.Items
 	if len(items2) == 0 {
 		t.Fatal("no items after patch")
 	}
@@ -155,13 +161,64 @@ func TestReadingListPatchNotFound(t *testing.T) {
 	rctx.URLParams.Add("id", "99999")
 	patchR = patchR.WithContext(context.WithValue(patchR.Context(), chi.RouteCtxKey, rctx))
 
-	UpdateReadingListItem(store).ServeHTTP(patchW, patchR)
+	UpdateReadingListItem(store, nil).ServeHTTP(patchW, patchR)
 
 	if patchW.Code != http.StatusNotFound {
 		t.Fatalf("got status %d, want %d", patchW.Code, http.StatusNotFound)
 	}
 }
 
+func TestReadingListProgressPosition(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+
+	blogID := seedBlog(t, store)
+	if err := store.AddToReadingList(ctx, blogID); err != nil {
+		t.Fatalf("AddToReadingList: %v", err)
+	}
+	itemID, err := store.GetReadingListIDByBlogID(ctx, blogID)
+	if err != nil {
+		t.Fatalf("GetReadingListIDByBlogID: %v", err)
+	}
+
+	patch := func(body string) int {
+		t.Helper()
+		r := httptest.NewRequest(http.MethodPatch, "/api/reading-list/x/progress", bytes.NewBufferString(body))
+		w := httptest.NewRecorder()
+		UpdateReadingProgress(store, nil).ServeHTTP(w, withURLParams(r, "id", jsonInt64(itemID)))
+		return w.Code
+	}
+
+	if code := patch(`{"progress": 42, "scroll_position": 0.4237}`); code != http.StatusOK {
+		t.Fatalf("PATCH got status %d, want %d", code, http.StatusOK)
+	}
+	item, err := store.GetReadingListItemByID(ctx, itemID)
+	if err != nil {
+		t.Fatalf("GetReadingListItemByID: %v", err)
+	}
+	if item.Progress != 42 || item.ScrollPosition == nil || *item.ScrollPosition != 0.4237 {
+		t.Errorf("progress %d, scroll position %v; want 42 at 0.4237", item.Progress, item.ScrollPosition)
+	}
+	if item.LastReadAt == nil || time.Since(*item.LastReadAt) > time.Minute {
+		t.Errorf("last_read_at = %v, want about now", item.LastReadAt)
+	}
+
+	// Progress alone keeps the position; an out-of-range one is rejected.
+	if code := patch(`{"progress": 50}`); code != http.StatusOK {
+		t.Fatalf("PATCH without a position got status %d, want %d", code, http.StatusOK)
+	}
+	if code := patch(`{"progress": 50, "scroll_position": 1.5}`); code != http.StatusBadRequest {
+		t.Errorf("PATCH with scroll_position 1.5 got status %d, want %d", code, http.StatusBadRequest)
+	}
+	item, err = store.GetReadingListItemByID(ctx, itemID)
+	if err != nil {
+		t.Fatalf("GetReadingListItemByID: %v", err)
+	}
+	if item.Progress != 50 || item.ScrollPosition == nil || *item.ScrollPosition != 0.4237 {
+		t.Errorf("progress %d, scroll position %v; want 50 still at 0.4237", item.Progress, item.ScrollPosition)
+	}
+}
+
 func TestReadingListDelete(t *testing.T) {
 	store := newTestStore(t)
 	blogID := seedBlog(t, store)
@@ -170,17 +227,18 @@ func TestReadingListDelete(t *testing.T) {
 	addBody, _ := json.Marshal(map[string]int64{"blog_id": blogID})
 	addR := httptest.NewRequest(http.MethodPost, "/api/reading-list", bytes.NewBuffer(addBody))
 	addW := httptest.NewRecorder()
-	AddToReadingList(store).ServeHTTP(addW, addR)
+	AddToReadingList(store, nil).ServeHTTP(addW, addR)
 
 	// Get ID.
 	getR := httptest.NewRequest(http.MethodGet, "/api/reading-list", nil)
 	getW := httptest.NewRecorder()
 	GetReadingList(store).ServeHTTP(getW, getR)
 
-	var items []models.ReadingListItem
-	if err := json.NewDecoder(getW.Body).Decode(&items); err != nil {
+	var page models.ReadingListPage
+	if err := json.NewDecoder(getW.Body).Decode(&page); err != nil {
 		t.Fatalf("decoding items: %v", err)
 	}
+	items := page.Items
 	if len(items) == 0 {
 		t.Fatal("no items in reading list")
 	}
@@ -204,10 +262,11 @@ func TestReadingListDelete(t *testing.T) {
 	getW2 := httptest.NewRecorder()
 	GetReadingList(store).ServeHTTP(getW2, getR2)
 
-	var items2 []models.ReadingListItem
-	if err := json.NewDecoder(getW2.Body).Decode(&items2); err != nil {
+	var page2 models.ReadingListPage
+	if err := json.NewDecoder(getW2.Body).Decode(&page2); err != nil {
 		t.Fatalf("decoding items: %v", err)
 	}
+	items2 := page2.Items
 	if len(items2) != 0 {
 		t.Errorf("got %d items, want 0 after delete", len(items2))
 	}
@@ -238,7 +297,7 @@ func TestReadingListDuplicate(t *testing.T) {
 	body, _ := json.Marshal(map[string]int64{"blog_id": blogID})
 	r1 := httptest.NewRequest(http.MethodPost, "/api/reading-list", bytes.NewBuffer(body))
 	w1 := httptest.NewRecorder()
-	AddToReadingList(store).ServeHTTP(w1, r1)
+	AddToReadingList(store, nil).ServeHTTP(w1, r1)
 
 	if w1.Code != http.StatusCreated {
 		t.Fatalf("first add got status %d", w1.Code)
@@ -248,7 +307,7 @@ func TestReadingListDuplicate(t *testing.T) {
 	body2, _ := json.Marshal(map[string]int64{"blog_id": blogID})
 	r2 := httptest.NewRequest(http.MethodPost, "/api/reading-list", bytes.NewBuffer(body2))
 	w2 := httptest.NewRecorder()
-	AddToReadingList(store).ServeHTTP(w2, r2)
+	AddToReadingList(store, nil).ServeHTTP(w2, r2)
 
 	if w2.Code != http.StatusBadRequest {
 		t.Fatalf("duplicate add got status %d, want %d; body: %s", w2.Code, http.StatusBadRequest, w2.Body.String())
@@ -261,15 +320,533 @@ func TestReadingListMissingBlogID(t *testing.T) {
 	body := `{}`
 	r := httptest.NewRequest(http.MethodPost, "/api/reading-list", bytes.NewBufferString(body))
 	w := httptest.NewRecorder()
-	AddToReadingList(store).ServeHTTP(w, r)
+	AddToReadingList(store, nil).ServeHTTP(w, r)
 
 	if w.Code != http.StatusBadRequest {
 		t.Fatalf("got status %d, want %d", w.Code, http.StatusBadRequest)
 	}
 }
 
+func TestReadingListFilterByClassification(t *testing.T) {
+	store := newTestStore(t)
+	blogID := seedBlog(t, store)
+	ctx := context.Background()
+
+	if err := store.AddToReadingList(ctx, blogID); err != nil {
+		t.Fatalf("AddToReadingList: %v", err)
+	}
+	if err := store.UpdateBlogDifficulty(ctx, blogID, models.DifficultyIntro); err != nil {
+		t.Fatalf("UpdateBlogDifficulty: %v", err)
+	}
+	if err := store.UpsertSummary(ctx, &models.BlogSummary{
+		BlogID: blogID, Summary: "s", Category: "databases", ModelUsed: "m",
+	}); err != nil {
+		t.Fatalf("UpsertSummary: %v", err)
+	}
+
+	tests := []struct {
+		query      string
+		wantStatus int
+		wantItems  int
+	}{
+		{"?difficulty=intro", http.StatusOK, 1},
+		{"?difficulty=deep-dive", http.StatusOK, 0},
+		{"?difficulty=expert", http.StatusBadRequest, 0},
+		{"?category=databases", http.StatusOK, 1},
+		{"?category=security", http.StatusOK, 0},
+		{"?category=gardening", http.StatusBadRequest, 0},
+	}
+
+	for _, tt := range tests {
+		r := httptest.NewRequest(http.MethodGet, "/api/reading-list"+tt.query, nil)
+		w := httptest.NewRecorder()
+		GetReadingList(store).ServeHTTP(w, r)
+
+		if w.Code != tt.wantStatus {
+			t.Fatalf("%s: got status %d, want %d", tt.query, w.Code, tt.wantStatus)
+		}
+		if tt.wantStatus != http.StatusOK {
+			continue
+		}
+
+		var page models.ReadingListPage
+		if err := json.NewDecoder(w.Body).Decode(&page); err != nil {
+			t.Fatalf("%s: decoding response: %v", tt.query, err)
+		}
+		items := page.Items
+		if len(items) != tt.wantItems {
+			t.Errorf("%s: got %d items, want %d", tt.query, len(items), tt.wantItems)
+		}
+	}
+}
+
+func TestUpdateReadingListItem_Snooze(t *testing.T) {
+	store := newTestStore(t)
+	blogID := seedBlog(t, store)
+	ctx := context.Background()
+
+	if err := store.AddToReadingList(ctx, blogID); err != nil {
+		t.Fatalf("AddToReadingList: %v", err)
+	}
+	itemID, err := store.GetReadingListIDByBlogID(ctx, blogID)
+	if err != nil {
+		t.Fatalf("GetReadingListIDByBlogID: %v", err)
+	}
+
+	patch := func(body string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodPatch, "/api/reading-list/"+jsonInt64(itemID), bytes.NewBufferString(body))
+		rctx := chi.NewRouteContext()
+		rctx.URLParams.Add("id", jsonInt64(itemID))
+		r = r.WithContext(context.WithValue(r.Context(), chi.RouteCtxKey, rctx))
+		w := httptest.NewRecorder()
+		UpdateReadingListItem(store, nil).ServeHTTP(w, r)
+		return w
+	}
+	list := func(query string) []models.ReadingListItem {
+		r := httptest.NewRequest(http.MethodGet, "/api/reading-list"+query, nil)
+		w := httptest.NewRecorder()
+		GetReadingList(store).ServeHTTP(w, r)
+		if w.Code != http.StatusOK {
+			t.Fatalf("GET %s: got status %d, want %d", query, w.Code, http.StatusOK)
+		}
+		var page models.ReadingListPage
+		if err := json.NewDecoder(w.Body).Decode(&page); err != nil {
+			t.Fatalf("decoding response: %v", err)
+		}
+		return page.Items
+	}
+
+	for _, body := range []string{
+		`{"snoozed_until": "next week"}`,
+		`{"snoozed_until": "2001-01-01T00:00:00Z"}`,
+	} {
+		if w := patch(body); w.Code != http.StatusBadRequest {
+			t.Errorf("%s: got status %d, want %d", body, w.Code, http.StatusBadRequest)
+		}
+	}
+
+	until := time.Now().Add(48 * time.Hour).UTC().Format(time.RFC3339)
+	if w := patch(`{"snoozed_until": "` + until + `"}`); w.Code != http.StatusOK {
+		t.Fatalf("snooze: got status %d; body: %s", w.Code, w.Body.String())
+	}
+	if items := list(""); len(items) != 0 {
+		t.Errorf("default list has %d items, want the snoozed item hidden", len(items))
+	}
+	if items := list("?snoozed=only"); len(items) != 1 || items[0].SnoozedUntil == nil {
+		t.Errorf("?snoozed=only = %+v, want the snoozed item", items)
+	}
+	if items := list("?snoozed=include"); len(items) != 1 {
+		t.Errorf("?snoozed=include has %d items, want 1", len(items))
+	}
+
+	if w := patch(`{"snoozed_until": ""}`); w.Code != http.StatusOK {
+		t.Fatalf("unsnooze: got status %d; body: %s", w.Code, w.Body.String())
+	}
+	if items := list(""); len(items) != 1 {
+		t.Errorf("default list has %d items after unsnoozing, want 1", len(items))
+	}
+
+	r := httptest.NewRequest(http.MethodGet, "/api/reading-list?snoozed=maybe", nil)
+	w := httptest.NewRecorder()
+	GetReadingList(store).ServeHTTP(w, r)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("?snoozed=maybe: got status %d, want %d", w.Code, http.StatusBadRequest)
+	}
+}
+
+func TestArchiveReadItems(t *testing.T) {
+	store := newTestStore(t)
+	blogID := seedBlog(t, store)
+	ctx := context.Background()
+
+	if err := store.AddToReadingList(ctx, blogID); err != nil {
+		t.Fatalf("AddToReadingList: %v", err)
+	}
+	itemID, err := store.GetReadingListIDByBlogID(ctx, blogID)
+	if err != nil {
+		t.Fatalf("GetReadingListIDByBlogID: %v", err)
+	}
+	if err := store.UpdateReadingListStatus(ctx, itemID, "read"); err != nil {
+		t.Fatalf("UpdateReadingListStatus: %v", err)
+	}
+
+	r := httptest.NewRequest(http.MethodPost, "/api/reading-list/archive-read", nil)
+	w := httptest.NewRecorder()
+	ArchiveReadItems(store).ServeHTTP(w, r)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("got status %d, want %d; body: %s", w.Code, http.StatusOK, w.Body.String())
+	}
+	var resp map[string]int64
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("decoding response: %v", err)
+	}
+	if resp["archived"] != 1 {
+		t.Errorf("archived = %d, want 1", resp["archived"])
+	}
+
+	items, err := store.GetReadingList(ctx, "archived")
+	if err != nil {
+		t.Fatalf("GetReadingList: %v", err)
+	}
+	if len(items) != 1 {
+		t.Errorf("got %d archived items, want 1", len(items))
+	}
+}
+
+func TestReorderReadingList(t *testing.T) {
+	store := newTestStore(t)
+	blogID := seedBlog(t, store)
+	ctx := context.Background()
+
+	if err := store.AddToReadingList(ctx, blogID); err != nil {
+		t.Fatalf("AddToReadingList: %v", err)
+	}
+	itemID, err := store.GetReadingListIDByBlogID(ctx, blogID)
+	if err != nil {
+		t.Fatalf("GetReadingListIDByBlogID: %v", err)
+	}
+	id := jsonInt64(itemID)
+
+	tests := []struct {
+		name       string
+		body       string
+		wantStatus int
+	}{
+		{"no ids", `{"ids": []}`, http.StatusBadRequest},
+		{"duplicate id", `{"ids": [` + id + `, ` + id + `]}`, http.StatusBadRequest},
+		{"unknown id", `{"ids": [` + id + `, 99999]}`, http.StatusNotFound},
+		{"reorder", `{"ids": [` + id + `]}`, http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPatch, "/api/reading-list/reorder", bytes.NewBufferString(tt.body))
+			w := httptest.NewRecorder()
+			ReorderReadingList(store).ServeHTTP(w, r)
+			if w.Code != tt.wantStatus {
+				t.Errorf("got status %d, want %d; body: %s", w.Code, tt.wantStatus, w.Body.String())
+			}
+		})
+	}
+
+	item, err := store.GetReadingListItemByID(ctx, itemID)
+	if err != nil {
+		t.Fatalf("GetReadingListItemByID: %v", err)
+	}
+	if item.Position == nil || *item.Position != 1 {
+		t.Errorf("Position = %v, want 1", item.Position)
+	}
+}
+
+func TestBulkUpdateReadingList(t *testing.T) {
+	store := newTestStore(t)
+	blogID := seedBlog(t, store)
+	ctx := context.Background()
+
+	if err := store.AddToReadingList(ctx, blogID); err != nil {
+		t.Fatalf("AddToReadingList: %v", err)
+	}
+	itemID, err := store.GetReadingListIDByBlogID(ctx, blogID)
+	if err != nil {
+		t.Fatalf("GetReadingListIDByBlogID: %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		body       string
+		wantStatus int
+	}{
+		{"no ids", `{"ids": [], "action": "delete"}`, http.StatusBadRequest},
+		{"unknown action", `{"ids": [` + jsonInt64(itemID) + `], "action": "explode"}`, http.StatusBadRequest},
+		{"invalid status", `{"ids": [` + jsonInt64(itemID) + `], "action": "set_status", "status": "done"}`, http.StatusBadRequest},
+		{"missing tag", `{"ids": [` + jsonInt64(itemID) + `], "action": "add_tag"}`, http.StatusBadRequest},
+		{"unknown id", `{"ids": [` + jsonInt64(itemID) + `, 99999], "action": "set_status", "status": "read"}`, http.StatusNotFound},
+		{"set status", `{"ids": [` + jsonInt64(itemID) + `], "action": "set_status", "status": "reading"}`, http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, "/api/reading-list/bulk", bytes.NewBufferString(tt.body))
+			w := httptest.NewRecorder()
+			BulkUpdateReadingList(store, nil).ServeHTTP(w, r)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("got status %d, want %d; body: %s", w.Code, tt.wantStatus, w.Body.String())
+			}
+		})
+	}
+
+	items, err := store.GetReadingList(ctx, "reading")
+	if err != nil {
+		t.Fatalf("GetReadingList: %v", err)
+	}
+	if len(items) != 1 {
+		t.Errorf("got %d reading items, want 1 (only the successful request applied)", len(items))
+	}
+}
+
+func TestGetNoteHistory(t *testing.T) {
+	store := newTestStore(t)
+	blogID := seedBlog(t, store)
+	ctx := context.Background()
+
+	if err := store.AddToReadingList(ctx, blogID); err != nil {
+		t.Fatalf("AddToReadingList: %v", err)
+	}
+	itemID, err := store.GetReadingListIDByBlogID(ctx, blogID)
+	if err != nil {
+		t.Fatalf("GetReadingListIDByBlogID: %v", err)
+	}
+	for _, notes := range []string{"first", "second"} {
+		if err := store.UpdateReadingListNotes(ctx, itemID, notes); err != nil {
+			t.Fatalf("UpdateReadingListNotes: %v", err)
+		}
+	}
+
+	for _, tt := range []struct {
+		id         string
+		wantStatus int
+	}{
+		{jsonInt64(itemID), http.StatusOK},
+		{"99999", http.StatusNotFound},
+	} {
+		r := httptest.NewRequest(http.MethodGet, "/api/reading-list/"+tt.id+"/notes/history", nil)
+		rctx := chi.NewRouteContext()
+		rctx.URLParams.Add("id", tt.id)
+		r = r.WithContext(context.WithValue(r.Context(), chi.RouteCtxKey, rctx))
+		w := httptest.NewRecorder()
+
+		GetNoteHistory(store).ServeHTTP(w, r)
+
+		if w.Code != tt.wantStatus {
+			t.Fatalf("id %s: got status %d, want %d", tt.id, w.Code, tt.wantStatus)
+		}
+		if tt.wantStatus != http.StatusOK {
+			continue
+		}
+		var history []models.NoteRevision
+		if err := json.NewDecoder(w.Body).Decode(&history); err != nil {
+			t.Fatalf("decoding response: %v", err)
+		}
+		if len(history) != 2 || history[0].Notes != "second" {
+			t.Errorf("history = %+v, want second then first", history)
+		}
+	}
+}
+
+func TestReadingListPagination(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+
+	for i := range 3 {
+		id, err := store.UpsertBlog(ctx, &models.Blog{
+			SourceID:    1,
+			Title:       "Paged Post",
+			URL:         "https://example.com/paged-" + jsonInt64(int64(i)),
+			FullContent: strings.Repeat("word ", 600),
+			FetchedAt:   time.Now(),
+		})
+		if err != nil {
+			t.Fatalf("UpsertBlog: %v", err)
+		}
+		if err := store.AddToReadingList(ctx, id); err != nil {
+			t.Fatalf("AddToReadingList: %v", err)
+		}
+	}
+
+	r := httptest.NewRequest(http.MethodGet, "/api/reading-list?limit=2&offset=2", nil)
+	w := httptest.NewRecorder()
+	GetReadingList(store).ServeHTTP(w, r)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("got status %d, want %d; body: %s", w.Code, http.StatusOK, w.Body.String())
+	}
+	if strings.Contains(w.Body.String(), "full_content") {
+		t.Error("list response includes full_content")
+	}
+
+	var page models.ReadingListPage
+	if err := json.NewDecoder(w.Body).Decode(&page); err != nil {
+		t.Fatalf("decoding response: %v", err)
+	}
+	if page.Total != 3 || page.Limit != 2 || page.Offset != 2 {
+		t.Errorf("got total %d, limit %d, offset %d; want 3, 2, 2", page.Total, page.Limit, page.Offset)
+	}
+	if len(page.Items) != 1 {
+		t.Fatalf("got %d items, want 1", len(page.Items))
+	}
+	// Reading time is still computed even though content is left out.
+	if rt := page.Items[0].Blog.ReadingTimeMinutes; rt == nil || *rt != 3 {
+		t.Errorf("ReadingTimeMinutes = %v, want 3", rt)
+	}
+
+	// Without a limit, or with one too large, a page has a bounded size.
+	for query, want := range map[string]int{"": defaultReadingListLimit, "?limit=5000": maxReadingListLimit} {
+		w := httptest.NewRecorder()
+		GetReadingList(store).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/reading-list"+query, nil))
+		var page models.ReadingListPage
+		if err := json.NewDecoder(w.Body).Decode(&page); err != nil {
+			t.Fatalf("%q: decoding response: %v", query, err)
+		}
+		if page.Limit != want || len(page.Items) != 3 {
+			t.Errorf("%q: got limit %d and %d items, want limit %d and 3 items", query, page.Limit, len(page.Items), want)
+		}
+	}
+
+	for _, query := range []string{"?limit=-1", "?offset=abc"} {
+		r := httptest.NewRequest(http.MethodGet, "/api/reading-list"+query, nil)
+		w := httptest.NewRecorder()
+		GetReadingList(store).ServeHTTP(w, r)
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("%s: got status %d, want %d", query, w.Code, http.StatusBadRequest)
+		}
+	}
+}
+
 // jsonInt64 converts an int64 to its string representation for URL params.
 func jsonInt64(n int64) string {
 	b, _ := json.Marshal(n)
 	return string(b)
 }
+
+func TestUpdateReadingListItem_IfMatch(t *testing.T) {
+	store := newTestStore(t)
+	blogID := seedBlog(t, store)
+	ctx := context.Background()
+
+	if err := store.AddToReadingList(ctx, blogID); err != nil {
+		t.Fatalf("AddToReadingList: %v", err)
+	}
+	itemID, err := store.GetReadingListIDByBlogID(ctx, blogID)
+	if err != nil {
+		t.Fatalf("GetReadingListIDByBlogID: %v", err)
+	}
+
+	patch := func(body, ifMatch string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodPatch, "/api/reading-list/"+jsonInt64(itemID), bytes.NewBufferString(body))
+		if ifMatch != "" {
+			r.Header.Set("If-Match", ifMatch)
+		}
+		rctx := chi.NewRouteContext()
+		rctx.URLParams.Add("id", jsonInt64(itemID))
+		r = r.WithContext(context.WithValue(r.Context(), chi.RouteCtxKey, rctx))
+		w := httptest.NewRecorder()
+		UpdateReadingListItem(store, nil).ServeHTTP(w, r)
+		return w
+	}
+
+	// Two tabs load the item at version 1; the first edit wins.
+	w := patch(`{"status": "reading"}`, `"1"`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("first edit: got status %d; body: %s", w.Code, w.Body.String())
+	}
+	if got := w.Header().Get("ETag"); got != `"2"` {
+		t.Errorf("ETag = %q, want %q", got, `"2"`)
+	}
+
+	w = patch(`{"notes": "stale"}`, `"1"`)
+	if w.Code != http.StatusPreconditionFailed {
+		t.Fatalf("stale edit: got status %d, want %d", w.Code, http.StatusPreconditionFailed)
+	}
+	item, err := store.GetReadingListItemByID(ctx, itemID)
+	if err != nil {
+		t.Fatalf("GetReadingListItemByID: %v", err)
+	}
+	if item.Notes != nil && *item.Notes == "stale" {
+		t.Error("stale edit was applied")
+	}
+
+	if w := patch(`{"notes": "fresh"}`, `W/"2"`); w.Code != http.StatusOK {
+		t.Errorf("weak If-Match: got status %d; body: %s", w.Code, w.Body.String())
+	}
+	if w := patch(`{"notes": "no header"}`, ""); w.Code != http.StatusOK {
+		t.Errorf("no If-Match: got status %d; body: %s", w.Code, w.Body.String())
+	}
+	if w := patch(`{"notes": "bad"}`, "3"); w.Code != http.StatusBadRequest {
+		t.Errorf("unquoted If-Match: got status %d, want %d", w.Code, http.StatusBadRequest)
+	}
+}
+
+func TestBatchAddCustomBlogs(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+	onList := seedBlog(t, store)
+	if err := store.AddToReadingList(ctx, onList); err != nil {
+		t.Fatal(err)
+	}
+	site := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		w.Write([]byte(`<html><head><title>Post ` + r.URL.Path + `</title></head><body><article><p>` + strings.Repeat("Words. ", 50) + `</p></article></body></html>`)) //nolint:errcheck
+	}))
+	defer site.Close()
+
+	body, _ := json.Marshal(map[string]any{"urls": []string{
+		site.URL + "/a",
+		" " + site.URL + "/a",
+		"",
+		"not a url",
+		site.URL + "/b",
+		"https://example.com/test-post",
+	}})
+	w := httptest.NewRecorder()
+	handler := BatchAddCustomBlogs(store, feeds.NewFetcher(nil), nil, &config.Config{}, nil)
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/reading-list/custom/batch", bytes.NewReader(body)))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status %d, want %d; body: %s", w.Code, http.StatusOK, w.Body.String())
+	}
+
+	var resp struct {
+		Results []BatchAddResult `json:"results"`
+		Added   int              `json:"added"`
+		Failed  int              `json:"failed"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("decoding response: %v", err)
+	}
+	var statuses []string
+	for _, res := range resp.Results {
+		statuses = append(statuses, res.Status)
+	}
+	if got := strings.Join(statuses, ","); got != "added,failed,added,exists" {
+		t.Errorf("statuses = %s, want added,failed,added,exists", got)
+	}
+	if resp.Added != 2 || resp.Failed != 1 || resp.Results[1].Error == "" {
+		t.Errorf("response = %+v, want 2 added and 1 failed with a reason", resp)
+	}
+	if blog, err := store.GetBlogByURL(ctx, site.URL+"/b"); err != nil || blog.Title != "Post /b" {
+		t.Errorf("GetBlogByURL(/b) = %+v, %v; want the fetched post", blog, err)
+	}
+
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/reading-list/custom/batch", strings.NewReader(`{"urls": [" "]}`)))
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("blank URLs: status %d, want %d", w.Code, http.StatusBadRequest)
+	}
+}
+
+func TestAddURL(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+	site := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		w.Write([]byte(`<html><head><title>Post</title></head><body><article><p>` + strings.Repeat("Words. ", 50) + `</p></article></body></html>`)) //nolint:errcheck
+	}))
+	defer site.Close()
+
+	blogID, err := AddURL(ctx, store, feeds.NewFetcher(nil), nil, &config.Config{}, site.URL+"/post", "My Source")
+	if err != nil {
+		t.Fatalf("AddURL() error: %v", err)
+	}
+	if blog, err := store.GetBlogByID(ctx, blogID); err != nil || blog.Title != "Post" || blog.Source != "My Source" {
+		t.Errorf("GetBlogByID = %+v, %v; want the fetched post under the given source", blog, err)
+	}
+	if _, err := store.GetReadingListIDByBlogID(ctx, blogID); err != nil {
+		t.Errorf("post is not on the reading list: %v", err)
+	}
+
+	if _, err := AddURL(ctx, store, feeds.NewFetcher(nil), nil, &config.Config{}, site.URL+"/post", ""); err == nil || !strings.Contains(err.Error(), "already on the reading list") {
+		t.Errorf("adding again: error = %v", err)
+	}
+	if _, err := AddURL(ctx, store, feeds.NewFetcher(nil), nil, &config.Config{}, "not a url", ""); err == nil {
+		t.Error("adding an invalid URL: no error")
+	}
+}
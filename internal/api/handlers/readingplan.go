@@ -0,0 +1,160 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/hoanghai1803/apricot/internal/ai"
+	"github.com/hoanghai1803/apricot/internal/models"
+	"github.com/hoanghai1803/apricot/internal/storage"
+)
+
+// maxPlanMinutes caps the time budget of a reading plan.
+const maxPlanMinutes = 600
+
+// GetReadingPlan handles GET /api/reading-plan?minutes=45. It picks unread,
+// unsnoozed items whose combined reading time comes as close to the budget
+// as possible without going over, preferring items earlier in the queue.
+// Items whose reading time is unknown are left out. With "order=ai" and an
+// AI provider, the picks are ordered by relevance to the user's interests;
+// otherwise they keep queue order.
+func GetReadingPlan(store storage.Store, aiProvider ai.AIProvider) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		minutes, err := strconv.Atoi(r.URL.Query().Get("minutes"))
+		if err != nil || minutes <= 0 || minutes > maxPlanMinutes {
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("minutes must be between 1 and %d", maxPlanMinutes))
+			return
+		}
+		order := r.URL.Query().Get("order")
+		if order != "" && order != "queue" && order != "ai" {
+			writeError(w, http.StatusBadRequest, "order must be one of queue, ai")
+			return
+		}
+
+		items, err := store.GetReadingListFiltered(ctx, storage.ReadingListFilter{
+			Status:         "unread",
+			Snoozed:        storage.SnoozeHide,
+			WithoutContent: true,
+		})
+		if err != nil {
+			slog.ErrorContext(ctx, "failed to get reading list", "error", err)
+			writeError(w, http.StatusInternalServerError, "Failed to build reading plan")
+			return
+		}
+		fillReadingTimes(ctx, store, items)
+
+		plan := models.ReadingPlan{BudgetMinutes: minutes, Items: []models.ReadingListItem{}}
+		for _, i := range planItems(items, minutes) {
+			plan.Items = append(plan.Items, items[i])
+			plan.TotalMinutes += *items[i].Blog.ReadingTimeMinutes
+		}
+
+		if order == "ai" && aiProvider != nil && len(plan.Items) > 1 {
+			ordered, err := orderPlanByInterest(ctx, store, aiProvider, plan.Items)
+			if err != nil {
+				if ctx.Err() != nil {
+					writeStageError(ctx, w, err, "ordering the plan with AI", http.StatusInternalServerError, "Failed to order reading plan")
+					return
+				}
+				slog.WarnContext(ctx, "failed to order reading plan with AI, keeping queue order", "error", err)
+			} else {
+				plan.Items, plan.AIOrdered = ordered, true
+			}
+		}
+
+		writeJSON(w, http.StatusOK, plan)
+	}
+}
+
+// planItems returns the indexes, in queue order, of the items whose reading
+// times add up closest to budget without exceeding it. Among selections
+// with the same total, the one reached using earlier items wins.
+func planItems(items []models.ReadingListItem, budget int) []int {
+	// from[t] is the item whose addition first reached a total of t; the
+	// rest of that selection reached t minus its reading time.
+	reached := make([]bool, budget+1)
+	from := make([]int, budget+1)
+	reached[0] = true
+	for i, item := range items {
+		if item.Blog == nil || item.Blog.ReadingTimeMinutes == nil {
+			continue
+		}
+		m := *item.Blog.ReadingTimeMinutes
+		if m <= 0 || m > budget {
+			continue
+		}
+		for t := budget; t >= m; t-- {
+			if !reached[t] && reached[t-m] {
+				reached[t], from[t] = true, i
+			}
+		}
+	}
+
+	best := budget
+	for !reached[best] {
+		best--
+	}
+	var picked []int
+	for t := best; t > 0; t -= *items[from[t]].Blog.ReadingTimeMinutes {
+		picked = append(picked, from[t])
+	}
+	// Items were added in increasing index order, so walking back from the
+	// total lists them latest first.
+	for i, j := 0, len(picked)-1; i < j; i, j = i+1, j-1 {
+		picked[i], picked[j] = picked[j], picked[i]
+	}
+	return picked
+}
+
+// orderPlanByInterest asks the AI to rank the plan's items by relevance to
+// the user's topics. Items the AI leaves out keep their place after the
+// ranked ones.
+func orderPlanByInterest(ctx context.Context, store storage.Store, aiProvider ai.AIProvider, items []models.ReadingListItem) ([]models.ReadingListItem, error) {
+	topics, err := loadTopics(ctx, store)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return nil, fmt.Errorf("no interests set")
+		}
+		return nil, err
+	}
+
+	byBlog := make(map[int64]models.ReadingListItem, len(items))
+	entries := make([]ai.BlogEntry, len(items))
+	for i, item := range items {
+		byBlog[item.BlogID] = item
+		entries[i] = ai.BlogEntry{
+			ID:          item.BlogID,
+			Title:       item.Blog.Title,
+			Source:      item.Blog.Source,
+			Description: item.Blog.Description,
+		}
+		if item.Summary != nil {
+			entries[i].Description = *item.Summary
+		}
+	}
+
+	ranked, err := aiProvider.FilterAndRank(ctx, topics, entries, len(entries), false)
+	if err != nil {
+		return nil, err
+	}
+
+	ordered := make([]models.ReadingListItem, 0, len(items))
+	for _, rb := range ranked {
+		if item, ok := byBlog[rb.ID]; ok {
+			ordered = append(ordered, item)
+			delete(byBlog, rb.ID)
+		}
+	}
+	for _, item := range items {
+		if _, ok := byBlog[item.BlogID]; ok {
+			ordered = append(ordered, item)
+		}
+	}
+	return ordered, nil
+}
@@ -0,0 +1,119 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/hoanghai1803/apricot/internal/models"
+)
+
+func TestPlanItems(t *testing.T) {
+	item := func(minutes int) models.ReadingListItem {
+		return models.ReadingListItem{Blog: &models.Blog{ReadingTimeMinutes: &minutes}}
+	}
+	unknown := models.ReadingListItem{Blog: &models.Blog{}}
+
+	tests := []struct {
+		name   string
+		items  []models.ReadingListItem
+		budget int
+		want   []int
+	}{
+		{"exact fit beats greedy", []models.ReadingListItem{item(30), item(20), item(25)}, 45, []int{1, 2}},
+		{"earlier items preferred", []models.ReadingListItem{item(10), item(10), item(10)}, 20, []int{0, 1}},
+		{"nothing fits", []models.ReadingListItem{item(60)}, 45, nil},
+		{"unknown times skipped", []models.ReadingListItem{unknown, item(15)}, 45, []int{1}},
+		{"closest under budget", []models.ReadingListItem{item(12), item(25), item(40)}, 45, []int{2}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := planItems(tt.items, tt.budget); !slices.Equal(got, tt.want) {
+				t.Errorf("planItems() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetReadingPlan(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+
+	// Backdated below so the queue, newest first, is 30, 25, 20 minutes.
+	var blogIDs []int64
+	for i, minutes := range []int{30, 25, 20} {
+		blogID, err := store.UpsertBlog(ctx, &models.Blog{
+			SourceID:  1,
+			Title:     fmt.Sprintf("Post %d", i),
+			URL:       fmt.Sprintf("https://example.com/plan-%d", i),
+			FetchedAt: time.Now(),
+		})
+		if err != nil {
+			t.Fatalf("UpsertBlog: %v", err)
+		}
+		if err := store.UpdateReadingTime(ctx, blogID, minutes); err != nil {
+			t.Fatalf("UpdateReadingTime: %v", err)
+		}
+		if err := store.AddToReadingList(ctx, blogID); err != nil {
+			t.Fatalf("AddToReadingList: %v", err)
+		}
+		blogIDs = append(blogIDs, blogID)
+	}
+	if _, err := store.DB().ExecContext(ctx,
+		`UPDATE reading_list SET added_at = datetime('now', '-' || id || ' minutes')`); err != nil {
+		t.Fatalf("backdating added_at: %v", err)
+	}
+	if err := store.SetPreference(ctx, "topics", "distributed systems"); err != nil {
+		t.Fatalf("SetPreference: %v", err)
+	}
+
+	get := func(query string) (*httptest.ResponseRecorder, models.ReadingPlan) {
+		r := httptest.NewRequest(http.MethodGet, "/api/reading-plan"+query, nil)
+		w := httptest.NewRecorder()
+		GetReadingPlan(store, &stubAIProvider{}).ServeHTTP(w, r)
+		var plan models.ReadingPlan
+		if w.Code == http.StatusOK {
+			if err := json.NewDecoder(w.Body).Decode(&plan); err != nil {
+				t.Fatalf("decoding response: %v", err)
+			}
+		}
+		return w, plan
+	}
+	blogsOf := func(plan models.ReadingPlan) []int64 {
+		var ids []int64
+		for _, item := range plan.Items {
+			ids = append(ids, item.BlogID)
+		}
+		return ids
+	}
+
+	for _, query := range []string{"", "?minutes=0", "?minutes=abc", "?minutes=45&order=random"} {
+		if w, _ := get(query); w.Code != http.StatusBadRequest {
+			t.Errorf("%q: got status %d, want %d", query, w.Code, http.StatusBadRequest)
+		}
+	}
+
+	w, plan := get("?minutes=45")
+	if w.Code != http.StatusOK {
+		t.Fatalf("got status %d; body: %s", w.Code, w.Body.String())
+	}
+	if plan.TotalMinutes != 45 || plan.AIOrdered {
+		t.Errorf("plan = %d minutes, ai_ordered %v; want 45 in queue order", plan.TotalMinutes, plan.AIOrdered)
+	}
+	if got, want := blogsOf(plan), []int64{blogIDs[1], blogIDs[2]}; !slices.Equal(got, want) {
+		t.Errorf("plan blogs = %v, want %v", got, want)
+	}
+
+	_, plan = get("?minutes=45&order=ai")
+	if !plan.AIOrdered {
+		t.Error("expected the plan to be AI-ordered")
+	}
+	if got, want := blogsOf(plan), []int64{blogIDs[2], blogIDs[1]}; !slices.Equal(got, want) {
+		t.Errorf("AI-ordered plan blogs = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,159 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/hoanghai1803/apricot/internal/config"
+	"github.com/hoanghai1803/apricot/internal/jobs"
+	"github.com/hoanghai1803/apricot/internal/models"
+	"github.com/hoanghai1803/apricot/internal/readwise"
+	"github.com/hoanghai1803/apricot/internal/storage"
+)
+
+// ReadwiseResult is the result of a "readwise" job. Since is zero for a
+// full sync.
+type ReadwiseResult struct {
+	Items      int       `json:"items"`
+	Highlights int       `json:"highlights"`
+	Since      time.Time `json:"since,omitzero"`
+}
+
+// readwisePayload is the payload of a "readwise" job.
+type readwisePayload struct {
+	Full bool `json:"full,omitempty"`
+}
+
+// SyncReadwise handles POST /api/admin/readwise. It queues a "readwise"
+// job (see ReadwiseJob) and returns 202 Accepted with the job; ?full=true
+// sends every item's notes, not only those changed since the last sync.
+// client is nil when Readwise is not configured.
+func SyncReadwise(client *readwise.Client, runner *jobs.Manager) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if client == nil {
+			writeError(w, http.StatusServiceUnavailable, "Readwise is not configured. Add a [readwise] section to config.toml")
+			return
+		}
+
+		payload := readwisePayload{Full: r.URL.Query().Get("full") == "true"}
+		job, err := runner.Enqueue(r.Context(), "readwise", payload)
+		if err != nil {
+			slog.ErrorContext(r.Context(), "failed to queue Readwise sync", "error", err)
+			writeError(w, http.StatusInternalServerError, "Failed to start Readwise sync")
+			return
+		}
+
+		w.Header().Set("Location", fmt.Sprintf("/api/jobs/%d", job.ID))
+		writeJSON(w, http.StatusAccepted, job)
+	}
+}
+
+// ReadwiseJob returns the "readwise" job kind, which sends the notes of
+// reading list items to Readwise as highlights (see readwise.FromNotes),
+// each with its post's title, source, and URL. Only notes changed since
+// the last successful sync are sent; when none is on record, as after a
+// week without one, all are, which is harmless since Readwise skips
+// highlights it already has. A failed sync is retried twice, unless
+// Readwise refused the token.
+func ReadwiseJob(store storage.Store, client *readwise.Client, cfg *config.Config) jobs.Kind {
+	return jobs.Kind{
+		Name:        "readwise",
+		Timeout:     5 * time.Minute,
+		MaxAttempts: 3,
+		Backoff:     5 * time.Minute,
+		Handler: func(ctx context.Context, payload json.RawMessage) (any, error) {
+			var p readwisePayload
+			if err := json.Unmarshal(payload, &p); err != nil {
+				return nil, jobs.Permanent(fmt.Errorf("decoding payload: %w", err))
+			}
+
+			var since time.Time
+			if !p.Full {
+				last, err := store.ListJobs(ctx, storage.JobFilter{Kind: "readwise", Status: models.JobSucceeded, Limit: 1})
+				if err != nil {
+					return nil, fmt.Errorf("finding the last sync: %w", err)
+				}
+				// From when the last sync started, so notes edited while it
+				// ran are sent again.
+				if len(last) == 1 && last[0].StartedAt != nil {
+					since = *last[0].StartedAt
+				}
+			}
+
+			highlights, items, err := readwiseHighlights(ctx, store, cfg, since)
+			if err != nil {
+				return nil, err
+			}
+			result := ReadwiseResult{Items: items, Since: since.UTC()}
+			if len(highlights) == 0 {
+				slog.InfoContext(ctx, "no new notes for Readwise", "since", since)
+				return result, nil
+			}
+
+			sent, err := client.Push(ctx, highlights)
+			result.Highlights = sent
+			if errors.Is(err, readwise.ErrUnauthorized) {
+				return nil, jobs.Permanent(err)
+			}
+			if err != nil {
+				return nil, err
+			}
+			slog.InfoContext(ctx, "synced notes to Readwise", "items", items, "highlights", sent)
+			return result, nil
+		},
+	}
+}
+
+// readwiseHighlights returns the highlights for the notes of reading list
+// items last edited at or after since (every item's, if since is zero),
+// and how many items they come from.
+func readwiseHighlights(ctx context.Context, store storage.Store, cfg *config.Config, since time.Time) ([]readwise.Highlight, int, error) {
+	list, err := store.GetReadingListFiltered(ctx, storage.ReadingListFilter{WithoutContent: true})
+	if err != nil {
+		return nil, 0, fmt.Errorf("loading reading list: %w", err)
+	}
+	appURL := strings.TrimRight(cfg.Server.PublicURL, "/")
+
+	var (
+		highlights []readwise.Highlight
+		items      int
+	)
+	for _, item := range list {
+		if item.Notes == nil || strings.TrimSpace(*item.Notes) == "" || item.Blog == nil {
+			continue
+		}
+		history, err := store.GetNoteHistory(ctx, item.ID)
+		if err != nil {
+			return nil, 0, fmt.Errorf("loading note history of item %d: %w", item.ID, err)
+		}
+		var editedAt *time.Time
+		if len(history) > 0 {
+			editedAt = &history[0].CreatedAt
+		}
+		if !since.IsZero() && editedAt != nil && editedAt.Before(since) {
+			continue
+		}
+
+		title := item.Blog.Title
+		if item.Blog.RewrittenTitle != "" {
+			title = item.Blog.RewrittenTitle
+		}
+		for _, h := range readwise.FromNotes(*item.Notes) {
+			h.Title, h.Author, h.SourceURL = title, item.Blog.Source, item.Blog.URL
+			h.SourceType, h.Category = "apricot", "articles"
+			h.HighlightedAt = editedAt
+			if appURL != "" {
+				h.HighlightURL = fmt.Sprintf("%s/read/%d", appURL, item.ID)
+			}
+			highlights = append(highlights, h)
+		}
+		items++
+	}
+	return highlights, items, nil
+}
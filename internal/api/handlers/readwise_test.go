@@ -0,0 +1,95 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/hoanghai1803/apricot/internal/config"
+	"github.com/hoanghai1803/apricot/internal/jobs"
+	"github.com/hoanghai1803/apricot/internal/models"
+	"github.com/hoanghai1803/apricot/internal/readwise"
+)
+
+func TestSyncReadwise(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+	blogID := seedBlog(t, store)
+	if err := store.AddToReadingList(ctx, blogID); err != nil {
+		t.Fatalf("AddToReadingList: %v", err)
+	}
+	list, err := store.GetReadingList(ctx, "")
+	if err != nil || len(list) != 1 {
+		t.Fatalf("GetReadingList: %v, %d items", err, len(list))
+	}
+	itemID := list[0].ID
+	if err := store.UpdateReadingListNotes(ctx, itemID, "> Quoted line.\n\nMy take.\n\nA loose thought."); err != nil {
+		t.Fatalf("UpdateReadingListNotes: %v", err)
+	}
+
+	var got []readwise.Highlight
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Highlights []readwise.Highlight `json:"highlights"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("decoding body: %v", err)
+		}
+		got = append(got, body.Highlights...)
+	}))
+	defer srv.Close()
+
+	client := &readwise.Client{Token: "secret", API: srv.URL, HTTP: srv.Client()}
+	cfg := &config.Config{Server: config.ServerConfig{PublicURL: "https://apricot.example/"}}
+	runner := jobs.NewManager(store, 1)
+	runner.Register(ReadwiseJob(store, client, cfg))
+
+	w := httptest.NewRecorder()
+	SyncReadwise(client, runner).ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/admin/readwise?full=true", nil))
+	if w.Code != http.StatusAccepted {
+		t.Fatalf("status %d, want %d; body: %s", w.Code, http.StatusAccepted, w.Body.String())
+	}
+	var job models.Job
+	if err := json.NewDecoder(w.Body).Decode(&job); err != nil {
+		t.Fatalf("decoding response: %v", err)
+	}
+	if err := runner.Drain(ctx); err != nil {
+		t.Fatalf("Drain() error: %v", err)
+	}
+	done, err := runner.Get(ctx, job.ID)
+	if err != nil || done.Status != models.JobSucceeded {
+		t.Fatalf("readwise job = %+v, %v; want it to have succeeded", done, err)
+	}
+
+	var result ReadwiseResult
+	if err := json.Unmarshal(done.Result, &result); err != nil {
+		t.Fatalf("decoding result: %v", err)
+	}
+	if result.Items != 1 || result.Highlights != 2 || !result.Since.IsZero() {
+		t.Errorf("result = %+v, want 2 highlights from 1 item, with no since", result)
+	}
+	if len(got) != 2 {
+		t.Fatalf("Readwise got %d highlights, want 2: %+v", len(got), got)
+	}
+	h := got[0]
+	if h.Text != "Quoted line." || h.Note != "My take." || got[1].Text != "A loose thought." {
+		t.Errorf("highlights = %+v, want the quote with its note, then the loose paragraph", got)
+	}
+	if h.Title != "Test Blog Post" || h.SourceURL != "https://example.com/test-post" || h.HighlightedAt == nil {
+		t.Errorf("highlight = %+v, want the post's title and URL and when the notes were edited", h)
+	}
+	if want := "https://apricot.example/read/" + strconv.FormatInt(itemID, 10); h.HighlightURL != want {
+		t.Errorf("HighlightURL = %q, want %q", h.HighlightURL, want)
+	}
+}
+
+func TestSyncReadwise_NotConfigured(t *testing.T) {
+	w := httptest.NewRecorder()
+	SyncReadwise(nil, nil).ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/admin/readwise", nil))
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("status %d, want %d", w.Code, http.StatusServiceUnavailable)
+	}
+}
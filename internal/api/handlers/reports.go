@@ -0,0 +1,170 @@
+package handlers
+
+import (
+	"fmt"
+	"html/template"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/hoanghai1803/apricot/internal/ai"
+	"github.com/hoanghai1803/apricot/internal/models"
+	"github.com/hoanghai1803/apricot/internal/storage"
+)
+
+// GetYearReport handles GET /api/reports/year/{year}. It composes an annual
+// reading report with an AI-written narrative (when a provider is
+// configured). The optional "format" query parameter selects "json"
+// (default), "markdown", or "html"; the latter two are served as file
+// downloads for sharing.
+func GetYearReport(store storage.Store, aiProvider ai.AIProvider) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		year, err := strconv.Atoi(chi.URLParam(r, "year"))
+		if err != nil || year < 1970 || year > 9999 {
+			writeError(w, http.StatusBadRequest, "year must be a four-digit year")
+			return
+		}
+
+		format := r.URL.Query().Get("format")
+		switch format {
+		case "", "json", "markdown", "md", "html":
+		default:
+			writeError(w, http.StatusBadRequest, "format must be one of json, markdown, html")
+			return
+		}
+
+		report, err := store.GetYearReport(ctx, year)
+		if err != nil {
+			slog.ErrorContext(ctx, "failed to build year report", "year", year, "error", err)
+			writeError(w, http.StatusInternalServerError, "Failed to build report")
+			return
+		}
+
+		if aiProvider != nil && len(report.Articles) > 0 {
+			entries := make([]ai.BlogEntry, len(report.Articles))
+			for i, a := range report.Articles {
+				entries[i] = ai.BlogEntry{
+					ID:          a.BlogID,
+					Title:       a.Title,
+					Source:      a.Source,
+					PublishedAt: a.ReadAt.Format("2006-01-02"),
+					Description: a.Description,
+				}
+			}
+			narrative, err := aiProvider.NarrateYear(ctx, year, entries)
+			if err != nil {
+				slog.WarnContext(ctx, "failed to generate year narrative", "year", year, "error", err)
+			} else {
+				report.Narrative = strings.TrimSpace(narrative)
+			}
+		}
+
+		switch format {
+		case "markdown", "md":
+			w.Header().Set("Content-Type", "text/markdown; charset=utf-8")
+			w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="apricot-%d.md"`, year))
+			w.WriteHeader(http.StatusOK)
+			_, _ = w.Write([]byte(renderYearReportMarkdown(report)))
+		case "html":
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="apricot-%d.html"`, year))
+			w.WriteHeader(http.StatusOK)
+			if err := yearReportHTML.Execute(w, report); err != nil {
+				slog.ErrorContext(ctx, "failed to render year report", "year", year, "error", err)
+			}
+		default:
+			writeJSON(w, http.StatusOK, report)
+		}
+	}
+}
+
+// renderYearReportMarkdown renders a YearReport as a shareable Markdown
+// document.
+func renderYearReportMarkdown(report *models.YearReport) string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "# My %d in Reading\n\n", report.Year)
+	fmt.Fprintf(&b, "- **Articles read:** %d\n", report.ArticlesRead)
+	fmt.Fprintf(&b, "- **Time spent reading:** %d minutes\n", report.TotalMinutes)
+	if report.LongestRead != nil {
+		fmt.Fprintf(&b, "- **Longest read:** [%s](%s) (%d min)\n",
+			report.LongestRead.Title, report.LongestRead.URL, *report.LongestRead.ReadingTimeMinutes)
+	}
+	b.WriteString("\n")
+
+	if report.Narrative != "" {
+		b.WriteString("## Themes\n\n")
+		b.WriteString(report.Narrative)
+		b.WriteString("\n\n")
+	}
+
+	if len(report.TopSources) > 0 {
+		b.WriteString("## Top Sources\n\n")
+		for i, c := range report.TopSources {
+			fmt.Fprintf(&b, "%d. %s (%d)\n", i+1, c.Name, c.Count)
+		}
+		b.WriteString("\n")
+	}
+
+	if len(report.TopTags) > 0 {
+		b.WriteString("## Top Tags\n\n")
+		for i, c := range report.TopTags {
+			fmt.Fprintf(&b, "%d. %s (%d)\n", i+1, c.Name, c.Count)
+		}
+		b.WriteString("\n")
+	}
+
+	if len(report.Notes) > 0 {
+		b.WriteString("## From My Notes\n\n")
+		for _, n := range report.Notes {
+			fmt.Fprintf(&b, "> %s\n>\n> — on [%s](%s)\n\n",
+				strings.ReplaceAll(n.Note, "\n", "\n> "), n.Title, n.URL)
+		}
+	}
+
+	if len(report.Articles) > 0 {
+		b.WriteString("## Everything I Read\n\n")
+		for _, a := range report.Articles {
+			fmt.Fprintf(&b, "- %s — [%s](%s) · %s\n", a.ReadAt.Format("Jan 2"), a.Title, a.URL, a.Source)
+		}
+	}
+
+	return b.String()
+}
+
+// yearReportHTML renders a YearReport as a standalone HTML page.
+var yearReportHTML = template.Must(template.New("year-report").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>My {{.Year}} in Reading</title>
+<style>
+body { font-family: system-ui, sans-serif; max-width: 42rem; margin: 2rem auto; padding: 0 1rem; line-height: 1.6; color: #1f2937; }
+h1, h2 { color: #c2410c; }
+blockquote { border-left: 3px solid #fdba74; margin: 1rem 0; padding-left: 1rem; color: #4b5563; }
+</style>
+</head>
+<body>
+<h1>My {{.Year}} in Reading</h1>
+<ul>
+<li><strong>Articles read:</strong> {{.ArticlesRead}}</li>
+<li><strong>Time spent reading:</strong> {{.TotalMinutes}} minutes</li>
+{{with .LongestRead}}<li><strong>Longest read:</strong> <a href="{{.URL}}">{{.Title}}</a> ({{.ReadingTimeMinutes}} min)</li>{{end}}
+</ul>
+{{with .Narrative}}<h2>Themes</h2>
+<p>{{.}}</p>{{end}}
+{{with .TopSources}}<h2>Top Sources</h2>
+<ol>{{range .}}<li>{{.Name}} ({{.Count}})</li>{{end}}</ol>{{end}}
+{{with .TopTags}}<h2>Top Tags</h2>
+<ol>{{range .}}<li>{{.Name}} ({{.Count}})</li>{{end}}</ol>{{end}}
+{{with .Notes}}<h2>From My Notes</h2>
+{{range .}}<blockquote>{{.Note}}<br>— on <a href="{{.URL}}">{{.Title}}</a></blockquote>{{end}}{{end}}
+{{with .Articles}}<h2>Everything I Read</h2>
+<ul>{{range .}}<li>{{.ReadAt.Format "Jan 2"}} — <a href="{{.URL}}">{{.Title}}</a> · {{.Source}}</li>{{end}}</ul>{{end}}
+</body>
+</html>
+`))
@@ -0,0 +1,89 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/hoanghai1803/apricot/internal/models"
+)
+
+// yearReportRequest builds a GET request for the given year with the chi
+// URL parameter set.
+func yearReportRequest(year, query string) *http.Request {
+	r := httptest.NewRequest(http.MethodGet, "/api/reports/year/"+year+query, nil)
+	return withURLParams(r, "year", year)
+}
+
+func TestGetYearReport_JSON(t *testing.T) {
+	store := newTestStore(t)
+	blogID := seedBlog(t, store)
+	if err := store.AddToReadingList(context.Background(), blogID); err != nil {
+		t.Fatalf("adding to reading list: %v", err)
+	}
+	if _, err := store.DB().Exec(
+		`UPDATE reading_list SET status = 'read', read_at = '2025-06-01 12:00:00'`,
+	); err != nil {
+		t.Fatalf("marking read: %v", err)
+	}
+
+	w := httptest.NewRecorder()
+	GetYearReport(store, nil).ServeHTTP(w, yearReportRequest("2025", ""))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("got status %d, want %d; body: %s", w.Code, http.StatusOK, w.Body.String())
+	}
+
+	var report models.YearReport
+	if err := json.NewDecoder(w.Body).Decode(&report); err != nil {
+		t.Fatalf("decoding response: %v", err)
+	}
+	if report.Year != 2025 || report.ArticlesRead != 1 {
+		t.Errorf("got year=%d articles_read=%d, want 2025 and 1", report.Year, report.ArticlesRead)
+	}
+}
+
+func TestGetYearReport_Markdown(t *testing.T) {
+	store := newTestStore(t)
+
+	w := httptest.NewRecorder()
+	GetYearReport(store, nil).ServeHTTP(w, yearReportRequest("2025", "?format=markdown"))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("got status %d, want %d", w.Code, http.StatusOK)
+	}
+	if ct := w.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/markdown") {
+		t.Errorf("got Content-Type %q, want text/markdown", ct)
+	}
+	if !strings.Contains(w.Body.String(), "# My 2025 in Reading") {
+		t.Errorf("markdown missing heading: %s", w.Body.String())
+	}
+}
+
+func TestGetYearReport_InvalidInput(t *testing.T) {
+	store := newTestStore(t)
+
+	tests := []struct {
+		name  string
+		year  string
+		query string
+	}{
+		{name: "non-numeric year", year: "abcd"},
+		{name: "out of range year", year: "12"},
+		{name: "unknown format", year: "2025", query: "?format=pdf"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			GetYearReport(store, nil).ServeHTTP(w, yearReportRequest(tt.year, tt.query))
+
+			if w.Code != http.StatusBadRequest {
+				t.Errorf("got status %d, want %d", w.Code, http.StatusBadRequest)
+			}
+		})
+	}
+}
@@ -0,0 +1,180 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/hoanghai1803/apricot/internal/ai"
+	"github.com/hoanghai1803/apricot/internal/storage"
+)
+
+const (
+	// defaultScoreWindowDays is the activity window used for source scores
+	// when none is requested, and when weighting discovery rankings.
+	defaultScoreWindowDays = 90
+
+	// defaultStatsWindowDays is the period covered by source stats when
+	// none is requested: long enough to see a source go quiet.
+	defaultStatsWindowDays = 180
+
+	// sourceScoreWeight is the share of a post's ranking decided by its
+	// source's score when ranking weights are enabled; the rest comes from
+	// the AI's relevance order.
+	sourceScoreWeight = 0.3
+)
+
+// GetSourceScores handles GET /api/sources/scores. It scores each source by
+// the user's saves, reads, and thumbs feedback over the last "days" days
+// (default 90; 0 for all time).
+func GetSourceScores(store storage.Store) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		days := defaultScoreWindowDays
+		if v := r.URL.Query().Get("days"); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil || n < 0 {
+				writeError(w, http.StatusBadRequest, "days must be a non-negative integer")
+				return
+			}
+			days = n
+		}
+
+		scores, err := store.GetSourceScores(r.Context(), scoreWindowStart(days))
+		if err != nil {
+			slog.ErrorContext(r.Context(), "failed to get source scores", "error", err)
+			writeError(w, http.StatusInternalServerError, "Failed to get source scores")
+			return
+		}
+
+		writeJSON(w, http.StatusOK, scores)
+	}
+}
+
+// GetSourceStats handles GET /api/sources/{id}/stats. It reports what the
+// source produced over the last "days" days (default 180; 0 for all time),
+// month by month: posts fetched, selected by discovery, and read, with how
+// high discovery ranked them. A source nothing is read from is one to
+// prune.
+func GetSourceStats(store storage.Store) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		id, err := parseID(r, "id")
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		days := defaultStatsWindowDays
+		if v := r.URL.Query().Get("days"); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil || n < 0 {
+				writeError(w, http.StatusBadRequest, "days must be a non-negative integer")
+				return
+			}
+			days = n
+		}
+
+		stats, err := store.GetSourceStats(ctx, id, scoreWindowStart(days))
+		if err != nil {
+			if errors.Is(err, storage.ErrNotFound) {
+				writeError(w, http.StatusNotFound, "Source not found")
+				return
+			}
+			slog.ErrorContext(ctx, "failed to get source stats", "id", id, "error", err)
+			writeError(w, http.StatusInternalServerError, "Failed to get source stats")
+			return
+		}
+
+		writeJSON(w, http.StatusOK, stats)
+	}
+}
+
+// SetBlogFeedback handles PUT /api/blogs/{id}/feedback. The body's "rating"
+// is 1 for thumbs up, -1 for thumbs down, or 0 to clear the rating.
+func SetBlogFeedback(store storage.Store) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := parseID(r, "id")
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		var body struct {
+			Rating int `json:"rating"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			writeError(w, http.StatusBadRequest, "Invalid JSON body")
+			return
+		}
+		if body.Rating < -1 || body.Rating > 1 {
+			writeError(w, http.StatusBadRequest, "rating must be 1, -1, or 0")
+			return
+		}
+
+		if err := store.SetBlogFeedback(r.Context(), id, body.Rating); err != nil {
+			if errors.Is(err, storage.ErrNotFound) {
+				writeError(w, http.StatusNotFound, "Blog not found")
+				return
+			}
+			slog.ErrorContext(r.Context(), "failed to set blog feedback", "id", id, "error", err)
+			writeError(w, http.StatusInternalServerError, "Failed to save feedback")
+			return
+		}
+
+		writeJSON(w, http.StatusOK, map[string]string{"status": "updated"})
+	}
+}
+
+// scoreWindowStart returns the start of a scoring window of the given number
+// of days, or the zero time for all time.
+func scoreWindowStart(days int) time.Time {
+	if days == 0 {
+		return time.Time{}
+	}
+	return time.Now().AddDate(0, 0, -days)
+}
+
+// sourceWeightingEnabled reports whether the user opted in to weighting
+// discovery rankings by source score via the "weight_by_source_score"
+// preference.
+func sourceWeightingEnabled(ctx context.Context, store storage.Store) bool {
+	var enabled bool
+	if err := store.GetPreference(ctx, "weight_by_source_score", &enabled); err != nil {
+		return false
+	}
+	return enabled
+}
+
+// weightBySourceScore reorders ranked by blending the AI's relevance order
+// with the score of each post's source. sourceOf maps blog IDs to source
+// names and scores maps source names to scores; posts from unscored sources
+// get a neutral 0.5.
+func weightBySourceScore(ranked []ai.RankedBlog, sourceOf map[int64]string, scores map[string]float64) []ai.RankedBlog {
+	n := float64(len(ranked))
+	weight := make(map[int64]float64, len(ranked))
+	for i, rb := range ranked {
+		score, ok := scores[sourceOf[rb.ID]]
+		if !ok {
+			score = 0.5
+		}
+		relevance := 1 - float64(i)/n
+		weight[rb.ID] = (1-sourceScoreWeight)*relevance + sourceScoreWeight*score
+	}
+
+	weighted := slices.Clone(ranked)
+	slices.SortStableFunc(weighted, func(a, b ai.RankedBlog) int {
+		switch {
+		case weight[a.ID] > weight[b.ID]:
+			return -1
+		case weight[a.ID] < weight[b.ID]:
+			return 1
+		}
+		return 0
+	})
+	return weighted
+}
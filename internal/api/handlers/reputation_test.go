@@ -0,0 +1,124 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/hoanghai1803/apricot/internal/ai"
+	"github.com/hoanghai1803/apricot/internal/models"
+)
+
+func TestSetBlogFeedback(t *testing.T) {
+	store := newTestStore(t)
+	blogID := seedBlog(t, store)
+
+	tests := []struct {
+		name       string
+		id         string
+		body       string
+		wantStatus int
+	}{
+		{"thumbs up", jsonInt64(blogID), `{"rating": 1}`, http.StatusOK},
+		{"clear", jsonInt64(blogID), `{"rating": 0}`, http.StatusOK},
+		{"out of range", jsonInt64(blogID), `{"rating": 5}`, http.StatusBadRequest},
+		{"invalid json", jsonInt64(blogID), `{`, http.StatusBadRequest},
+		{"missing blog", "9999", `{"rating": -1}`, http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPut, "/api/blogs/"+tt.id+"/feedback", bytes.NewBufferString(tt.body))
+			r = withURLParams(r, "id", tt.id)
+			w := httptest.NewRecorder()
+			SetBlogFeedback(store).ServeHTTP(w, r)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("got status %d, want %d; body: %s", w.Code, tt.wantStatus, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestGetSourceScores(t *testing.T) {
+	store := newTestStore(t)
+
+	r := httptest.NewRequest(http.MethodGet, "/api/sources/scores?days=0", nil)
+	w := httptest.NewRecorder()
+	GetSourceScores(store).ServeHTTP(w, r)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("got status %d, want %d", w.Code, http.StatusOK)
+	}
+	var scores []models.SourceScore
+	if err := json.NewDecoder(w.Body).Decode(&scores); err != nil {
+		t.Fatalf("decoding response: %v", err)
+	}
+	if len(scores) == 0 || scores[0].Score != 0.5 {
+		t.Errorf("expected neutral scores for seeded sources, got %+v", scores)
+	}
+
+	r = httptest.NewRequest(http.MethodGet, "/api/sources/scores?days=-1", nil)
+	w = httptest.NewRecorder()
+	GetSourceScores(store).ServeHTTP(w, r)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("got status %d for negative days, want %d", w.Code, http.StatusBadRequest)
+	}
+}
+
+func TestGetSourceStats(t *testing.T) {
+	store := newTestStore(t)
+	handler := GetSourceStats(store)
+
+	r := withURLParams(httptest.NewRequest(http.MethodGet, "/api/sources/1/stats?days=0", nil), "id", "1")
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+	if w.Code != http.StatusOK {
+		t.Fatalf("got status %d, want %d", w.Code, http.StatusOK)
+	}
+	var stats models.SourceStats
+	if err := json.NewDecoder(w.Body).Decode(&stats); err != nil {
+		t.Fatalf("decoding response: %v", err)
+	}
+	if stats.SourceID != 1 || stats.Name == "" || stats.Months == nil {
+		t.Errorf("got %+v, want the seeded source's stats", stats)
+	}
+
+	for _, tc := range []struct {
+		id, query string
+		want      int
+	}{
+		{"1", "?days=-1", http.StatusBadRequest},
+		{"x", "", http.StatusBadRequest},
+		{"9999", "", http.StatusNotFound},
+	} {
+		r := withURLParams(httptest.NewRequest(http.MethodGet, "/api/sources/"+tc.id+"/stats"+tc.query, nil), "id", tc.id)
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		if w.Code != tc.want {
+			t.Errorf("%s%s: got status %d, want %d", tc.id, tc.query, w.Code, tc.want)
+		}
+	}
+}
+
+func TestWeightBySourceScore(t *testing.T) {
+	ranked := []ai.RankedBlog{{ID: 1}, {ID: 2}, {ID: 3}}
+	sourceOf := map[int64]string{1: "Meh", 2: "Great", 3: "Unknown"}
+	scores := map[string]float64{"Meh": 0.1, "Great": 0.9}
+
+	got := weightBySourceScore(ranked, sourceOf, scores)
+
+	// Weights: 1 -> 0.7*1 + 0.3*0.1 = 0.73, 2 -> 0.7*(2/3) + 0.3*0.9 ≈ 0.737,
+	// 3 -> 0.7*(1/3) + 0.3*0.5 ≈ 0.383.
+	want := []int64{2, 1, 3}
+	for i, rb := range got {
+		if rb.ID != want[i] {
+			t.Fatalf("got order %v, want %v", got, want)
+		}
+	}
+	if ranked[0].ID != 1 {
+		t.Error("weightBySourceScore modified its input")
+	}
+}
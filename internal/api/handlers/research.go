@@ -0,0 +1,328 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+	"unicode"
+
+	"github.com/hoanghai1803/apricot/internal/ai"
+	"github.com/hoanghai1803/apricot/internal/config"
+	"github.com/hoanghai1803/apricot/internal/feeds"
+	"github.com/hoanghai1803/apricot/internal/models"
+	"github.com/hoanghai1803/apricot/internal/storage"
+)
+
+const (
+	// defaultResearchSources is the number of articles a report cites when
+	// the request does not say.
+	defaultResearchSources = 8
+	maxResearchSources     = 15
+
+	// defaultResearchFresh caps how many freshly fetched posts are added to
+	// the archive matches.
+	defaultResearchFresh = 4
+
+	// researchReportsLimit caps how many reports GET /api/research returns.
+	researchReportsLimit = 50
+)
+
+// researchStopwords are common question words dropped before searching the
+// archive, since matching any of them would match nearly every article.
+var researchStopwords = map[string]bool{
+	"about": true, "an": true, "and": true, "are": true, "as": true,
+	"at": true, "be": true, "between": true, "by": true, "can": true,
+	"do": true, "does": true, "for": true, "from": true, "how": true,
+	"in": true, "into": true, "is": true, "it": true, "my": true,
+	"of": true, "on": true, "or": true, "should": true, "that": true,
+	"the": true, "their": true, "there": true, "these": true,
+	"this": true, "to": true, "we": true, "what": true, "when": true,
+	"where": true, "which": true, "who": true, "why": true,
+	"will": true, "with": true, "would": true, "you": true,
+	"your": true,
+}
+
+// Research handles POST /api/research. Given a question, it searches the
+// reading list for matching articles, optionally fetches the user's sources
+// and has the AI pick a bounded number of relevant fresh posts, summarizes
+// every selected article, and synthesizes an answer that cites them. The
+// result is saved as a research report.
+func Research(store storage.Store, aiProvider ai.AIProvider, fetcher *feeds.Fetcher, cfg *config.Config) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		var body struct {
+			Question   string `json:"question"`
+			MaxSources int    `json:"max_sources"`
+			Fresh      *bool  `json:"fresh"`     // fetch sources for new posts (default true)
+			MaxFresh   *int   `json:"max_fresh"` // cap on fetched posts to cite
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			writeError(w, http.StatusBadRequest, "Invalid JSON body")
+			return
+		}
+		body.Question = strings.TrimSpace(body.Question)
+		if body.Question == "" {
+			writeError(w, http.StatusBadRequest, "question is required")
+			return
+		}
+		if body.MaxSources <= 0 {
+			body.MaxSources = defaultResearchSources
+		}
+		body.MaxSources = min(body.MaxSources, maxResearchSources)
+		maxFresh := defaultResearchFresh
+		if body.MaxFresh != nil {
+			maxFresh = max(*body.MaxFresh, 0)
+		}
+		if body.Fresh != nil && !*body.Fresh {
+			maxFresh = 0
+		}
+		maxFresh = min(maxFresh, body.MaxSources)
+
+		if aiProvider == nil {
+			writeError(w, http.StatusServiceUnavailable,
+				"AI provider not configured. Add your API key to config.toml")
+			return
+		}
+
+		// 1. Search the archive, leaving room for fresh posts.
+		keywords := researchKeywords(body.Question)
+		archived, err := store.SearchSavedBlogs(ctx, keywords, 0, body.MaxSources)
+		if err != nil {
+			slog.ErrorContext(ctx, "failed to search archive", "question", body.Question, "error", err)
+			writeStageError(ctx, w, err, "searching articles", http.StatusInternalServerError, "Failed to search articles")
+			return
+		}
+		if len(archived) > body.MaxSources-maxFresh {
+			archived = archived[:body.MaxSources-maxFresh]
+		}
+
+		selected := make([]models.Blog, 0, body.MaxSources)
+		origins := make(map[int64]string, body.MaxSources)
+		for _, b := range archived {
+			selected = append(selected, b)
+			origins[b.ID] = models.ResearchOriginArchive
+		}
+
+		// 2. Fetch sources and let the AI pick the most relevant new posts.
+		if maxFresh > 0 {
+			for _, b := range freshResearchBlogs(ctx, store, aiProvider, fetcher, cfg, body.Question, origins, maxFresh) {
+				selected = append(selected, b)
+				origins[b.ID] = models.ResearchOriginFresh
+			}
+		}
+
+		if len(selected) == 0 {
+			writeError(w, http.StatusNotFound, "No saved or fetched articles match this question")
+			return
+		}
+
+		// 3. Summarize each source and synthesize the answer from the
+		// summaries.
+		sources := make([]models.ResearchSource, len(selected))
+		entries := make([]ai.BlogEntry, len(selected))
+		for i := range selected {
+			blog := &selected[i]
+			extractContent(ctx, store, fetcher, blog)
+			summary := ensureSummary(ctx, store, aiProvider, cfg.AI.Model, blog)
+
+			var publishedAt string
+			if blog.PublishedAt != nil {
+				publishedAt = blog.PublishedAt.Format("2006-01-02")
+			}
+			entries[i] = ai.BlogEntry{
+				ID:          blog.ID,
+				Title:       blog.Title,
+				Source:      blog.Source,
+				PublishedAt: publishedAt,
+				Description: summary.Summary,
+			}
+			sources[i] = models.ResearchSource{
+				Ref:     i + 1,
+				BlogID:  blog.ID,
+				Title:   blog.Title,
+				URL:     blog.URL,
+				Source:  blog.Source,
+				Summary: summary.Summary,
+				Origin:  origins[blog.ID],
+			}
+		}
+
+		slog.InfoContext(ctx, "synthesizing research answer", "question", body.Question, "sources", len(entries))
+		answer, err := aiProvider.SynthesizeAnswer(ctx, body.Question, entries)
+		if err != nil {
+			slog.ErrorContext(ctx, "failed to synthesize answer", "question", body.Question, "error", err)
+			writeStageError(ctx, w, err, "synthesizing the answer with AI", http.StatusInternalServerError, "Failed to synthesize answer with AI")
+			return
+		}
+
+		// 4. Save the report.
+		report := &models.ResearchReport{
+			Question:  body.Question,
+			Answer:    answer,
+			Sources:   sources,
+			ModelUsed: cfg.AI.Model,
+		}
+		id, err := store.CreateResearchReport(ctx, report)
+		if err != nil {
+			slog.ErrorContext(ctx, "failed to save research report", "error", err)
+			writeError(w, http.StatusInternalServerError, "Failed to save research report")
+			return
+		}
+
+		writeResearchReport(w, r, store, id, http.StatusCreated)
+	}
+}
+
+// ListResearchReports handles GET /api/research. It returns the most recent
+// research reports, newest first.
+func ListResearchReports(store storage.Store) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		reports, err := store.ListResearchReports(r.Context(), researchReportsLimit)
+		if err != nil {
+			slog.ErrorContext(r.Context(), "failed to list research reports", "error", err)
+			writeError(w, http.StatusInternalServerError, "Failed to list research reports")
+			return
+		}
+
+		writeJSON(w, http.StatusOK, reports)
+	}
+}
+
+// GetResearchReport handles GET /api/research/{id}.
+func GetResearchReport(store storage.Store) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := parseID(r, "id")
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		writeResearchReport(w, r, store, id, http.StatusOK)
+	}
+}
+
+// DeleteResearchReport handles DELETE /api/research/{id}. The cited articles
+// are kept.
+func DeleteResearchReport(store storage.Store) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := parseID(r, "id")
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		if err := store.DeleteResearchReport(r.Context(), id); err != nil {
+			if errors.Is(err, storage.ErrNotFound) {
+				writeError(w, http.StatusNotFound, "Research report not found")
+				return
+			}
+			slog.ErrorContext(r.Context(), "failed to delete research report", "id", id, "error", err)
+			writeError(w, http.StatusInternalServerError, "Failed to delete research report")
+			return
+		}
+
+		writeJSON(w, http.StatusOK, map[string]string{"status": "deleted"})
+	}
+}
+
+// writeResearchReport loads the report with the given ID and writes it with
+// status, or writes the appropriate error.
+func writeResearchReport(w http.ResponseWriter, r *http.Request, store storage.Store, id int64, status int) {
+	report, err := store.GetResearchReport(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			writeError(w, http.StatusNotFound, "Research report not found")
+			return
+		}
+		slog.ErrorContext(r.Context(), "failed to get research report", "id", id, "error", err)
+		writeError(w, http.StatusInternalServerError, "Failed to get research report")
+		return
+	}
+
+	writeJSON(w, status, report)
+}
+
+// freshResearchBlogs fetches the active sources, saves the posts, and asks
+// the AI for up to limit posts relevant to question, skipping blogs already
+// in exclude. Failures are logged and yield no fresh posts, so research can
+// still proceed from the archive alone.
+func freshResearchBlogs(ctx context.Context, store storage.Store, aiProvider ai.AIProvider, fetcher *feeds.Fetcher, cfg *config.Config, question string, exclude map[int64]string, limit int) []models.Blog {
+	sources, err := store.GetActiveSources(ctx)
+	if err != nil {
+		slog.WarnContext(ctx, "failed to get sources for research", "error", err)
+		return nil
+	}
+	if len(sources) == 0 {
+		return nil
+	}
+
+	fetchResult, err := fetcher.FetchAll(ctx, sources, buildFetchOptions(store, cfg, ctx))
+	if err != nil {
+		slog.WarnContext(ctx, "failed to fetch feeds for research", "error", err)
+		return nil
+	}
+	if len(fetchResult.Blogs) == 0 {
+		return nil
+	}
+	if err := store.SaveBlogs(ctx, fetchResult.Blogs); err != nil {
+		slog.WarnContext(ctx, "failed to save fetched blogs", "error", err)
+		return nil
+	}
+
+	// Resolve stored IDs, since SaveBlogs upserts by URL.
+	byID := make(map[int64]*models.Blog, len(fetchResult.Blogs))
+	var candidates []models.Blog
+	for _, b := range fetchResult.Blogs {
+		stored, err := store.GetBlogByURL(ctx, b.URL)
+		if err != nil {
+			continue
+		}
+		if _, seen := exclude[stored.ID]; seen || byID[stored.ID] != nil {
+			continue
+		}
+		byID[stored.ID] = stored
+		candidates = append(candidates, *stored)
+	}
+	if len(candidates) == 0 {
+		return nil
+	}
+
+	ranked, err := aiProvider.FilterAndRank(ctx, question, toBlogEntries(candidates), limit, false)
+	if err != nil {
+		slog.WarnContext(ctx, "failed to rank fresh posts for research", "error", err)
+		return nil
+	}
+
+	var picked []models.Blog
+	for _, rb := range ranked {
+		if len(picked) >= limit {
+			break
+		}
+		if b, ok := byID[rb.ID]; ok {
+			picked = append(picked, *b)
+			delete(byID, rb.ID) // skip duplicates
+		}
+	}
+	return picked
+}
+
+// researchKeywords reduces a question to its search keywords by dropping
+// punctuation, single characters, and common question words.
+func researchKeywords(question string) string {
+	words := strings.FieldsFunc(strings.ToLower(question), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '-' && r != '+' && r != '#'
+	})
+
+	var keywords []string
+	for _, word := range words {
+		if len(word) < 2 || researchStopwords[word] {
+			continue
+		}
+		keywords = append(keywords, word)
+	}
+	return strings.Join(keywords, " ")
+}
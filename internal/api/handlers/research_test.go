@@ -0,0 +1,167 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/hoanghai1803/apricot/internal/ai"
+	"github.com/hoanghai1803/apricot/internal/models"
+)
+
+func TestResearch_ArchiveOnly(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+
+	for i, title := range []string{"Raft consensus explained", "Paxos consensus in practice", "Consensus at scale"} {
+		id, err := store.UpsertBlog(ctx, &models.Blog{
+			SourceID:    1,
+			Title:       title,
+			URL:         "https://example.com/" + title,
+			FullContent: "content",
+			FetchedAt:   time.Now(),
+		})
+		if err != nil {
+			t.Fatalf("seeding blog: %v", err)
+		}
+		// The last blog is fetched but not saved, so it is not in the archive.
+		if i < 2 {
+			if err := store.AddToReadingList(ctx, id); err != nil {
+				t.Fatalf("AddToReadingList: %v", err)
+			}
+		}
+	}
+
+	provider := &stubAIProvider{summary: ai.Summary{Text: "a summary"}}
+	body := `{"question": "How does consensus work?", "fresh": false}`
+	r := httptest.NewRequest(http.MethodPost, "/api/research", bytes.NewBufferString(body))
+	w := httptest.NewRecorder()
+	Research(store, provider, nil, testAIConfig()).ServeHTTP(w, r)
+
+	if w.Code != http.StatusCreated {
+		t.Fatalf("got status %d, want %d; body: %s", w.Code, http.StatusCreated, w.Body.String())
+	}
+
+	var report models.ResearchReport
+	if err := json.NewDecoder(w.Body).Decode(&report); err != nil {
+		t.Fatalf("decoding response: %v", err)
+	}
+	if report.ID == 0 || report.Answer != "answer [1]" || report.ModelUsed != "claude-haiku-4-5" {
+		t.Errorf("got id=%d answer=%q model=%q", report.ID, report.Answer, report.ModelUsed)
+	}
+	if len(report.Sources) != 2 {
+		t.Fatalf("got %d sources, want 2: %+v", len(report.Sources), report.Sources)
+	}
+	for i, src := range report.Sources {
+		if src.Ref != i+1 || src.Origin != models.ResearchOriginArchive || src.Summary != "a summary" {
+			t.Errorf("source %d: %+v", i, src)
+		}
+	}
+	if len(provider.sources) != 2 || provider.sources[0].Description != "a summary" {
+		t.Errorf("AI got sources %+v", provider.sources)
+	}
+
+	if _, err := store.GetResearchReport(ctx, report.ID); err != nil {
+		t.Errorf("report not saved: %v", err)
+	}
+}
+
+func TestResearch_Errors(t *testing.T) {
+	store := newTestStore(t)
+
+	tests := []struct {
+		name       string
+		provider   ai.AIProvider
+		body       string
+		wantStatus int
+	}{
+		{"invalid json", &stubAIProvider{}, `{`, http.StatusBadRequest},
+		{"missing question", &stubAIProvider{}, `{"question": "  "}`, http.StatusBadRequest},
+		{"no provider", nil, `{"question": "raft?"}`, http.StatusServiceUnavailable},
+		{"no matches", &stubAIProvider{}, `{"question": "raft?", "fresh": false}`, http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, "/api/research", bytes.NewBufferString(tt.body))
+			w := httptest.NewRecorder()
+			Research(store, tt.provider, nil, testAIConfig()).ServeHTTP(w, r)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("got status %d, want %d; body: %s", w.Code, tt.wantStatus, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestResearchKeywords(t *testing.T) {
+	tests := []struct {
+		question string
+		want     string
+	}{
+		{"How does Raft handle leader election?", "raft handle leader election"},
+		{"What is the best C++ allocator for Go?", "best c++ allocator go"},
+		{"why?", ""},
+	}
+
+	for _, tt := range tests {
+		if got := researchKeywords(tt.question); got != tt.want {
+			t.Errorf("researchKeywords(%q) = %q, want %q", tt.question, got, tt.want)
+		}
+	}
+}
+
+func TestGetResearchReport_NotFound(t *testing.T) {
+	store := newTestStore(t)
+
+	r := withURLParams(httptest.NewRequest(http.MethodGet, "/api/research/99", nil), "id", "99")
+	w := httptest.NewRecorder()
+	GetResearchReport(store).ServeHTTP(w, r)
+
+	if w.Code != http.StatusNotFound {
+		t.Errorf("got status %d, want %d", w.Code, http.StatusNotFound)
+	}
+}
+
+func TestResearch_MockProvider(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+
+	id, err := store.UpsertBlog(ctx, &models.Blog{
+		SourceID:    1,
+		Title:       "Raft leader election",
+		URL:         "https://example.com/raft",
+		FullContent: "content",
+		FetchedAt:   time.Now(),
+	})
+	if err != nil {
+		t.Fatalf("seeding blog: %v", err)
+	}
+	if err := store.AddToReadingList(ctx, id); err != nil {
+		t.Fatalf("AddToReadingList: %v", err)
+	}
+
+	body := `{"question": "How does raft elect a leader?", "fresh": false}`
+	r := httptest.NewRequest(http.MethodPost, "/api/research", bytes.NewBufferString(body))
+	w := httptest.NewRecorder()
+	Research(store, ai.NewMockProvider(), nil, testAIConfig()).ServeHTTP(w, r)
+
+	if w.Code != http.StatusCreated {
+		t.Fatalf("got status %d, want %d; body: %s", w.Code, http.StatusCreated, w.Body.String())
+	}
+
+	var report models.ResearchReport
+	if err := json.NewDecoder(w.Body).Decode(&report); err != nil {
+		t.Fatalf("decoding response: %v", err)
+	}
+	if len(report.Sources) != 1 || report.Sources[0].Summary != `Mock summary of "Raft leader election" from Other Blog.` {
+		t.Errorf("got sources %+v", report.Sources)
+	}
+	if report.Answer != `Mock answer to "How does raft elect a leader?" based on [1].` {
+		t.Errorf("got answer %q", report.Answer)
+	}
+}
@@ -0,0 +1,141 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"slices"
+
+	"github.com/hoanghai1803/apricot/internal/ai"
+	"github.com/hoanghai1803/apricot/internal/feeds"
+	"github.com/hoanghai1803/apricot/internal/jobs"
+	"github.com/hoanghai1803/apricot/internal/models"
+	"github.com/hoanghai1803/apricot/internal/storage"
+)
+
+// errNothingToRetry is returned by failedSources for a session none of
+// whose failed feeds is still an active source.
+var errNothingToRetry = errors.New("No failed feeds to retry")
+
+// RetryFailedFeeds handles POST /api/discover/sessions/{id}/retry-failed.
+// It queues a "discover" job that fetches only the feeds that failed in the
+// session and merges the posts it finds into the latest results (see
+// mergeLatest), so one flaky feed doesn't need a full run. It returns 202
+// Accepted with the job, as Discover does. A session with no failed feeds
+// that are still active sources is a 400.
+func RetryFailedFeeds(store storage.Store, aiProvider ai.AIProvider, runner *jobs.Manager) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		id, err := parseID(r, "id")
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "Invalid session ID")
+			return
+		}
+
+		if aiProvider == nil {
+			writeError(w, http.StatusServiceUnavailable,
+				"AI provider not configured. Add your API key to config.toml")
+			return
+		}
+
+		// The job looks the failed feeds up again when it runs.
+		if _, err := failedSources(ctx, store, id); err != nil {
+			switch {
+			case errors.Is(err, storage.ErrNotFound):
+				writeError(w, http.StatusNotFound, "Discovery session not found")
+			case errors.Is(err, errNothingToRetry):
+				writeError(w, http.StatusBadRequest, errNothingToRetry.Error())
+			default:
+				slog.ErrorContext(ctx, "failed to load failed feeds", "id", id, "error", err)
+				writeError(w, http.StatusInternalServerError, "Failed to load discovery session")
+			}
+			return
+		}
+
+		var maxMinutes int
+		if err := store.GetPreference(ctx, "max_reading_minutes", &maxMinutes); err != nil || maxMinutes < 0 {
+			maxMinutes = 0
+		}
+		job, err := runner.Enqueue(ctx, "discover", discoverPayload{
+			MaxReadingMinutes: maxMinutes,
+			RetryFailed:       id,
+		})
+		if err != nil {
+			slog.ErrorContext(ctx, "failed to queue discovery", "error", err)
+			writeError(w, http.StatusInternalServerError, "Failed to start discovery")
+			return
+		}
+
+		w.Header().Set("Location", fmt.Sprintf("/api/jobs/%d", job.ID))
+		writeJSON(w, http.StatusAccepted, job)
+	}
+}
+
+// failedSources returns the active sources whose feeds failed in session
+// id, matched by name.
+func failedSources(ctx context.Context, store storage.Store, id int64) ([]models.BlogSource, error) {
+	session, err := store.GetSession(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("getting session %d: %w", id, err)
+	}
+	_, failedFeeds, err := decodeSession(session)
+	if err != nil {
+		return nil, fmt.Errorf("decoding session %d results: %w", id, err)
+	}
+	active, err := store.GetActiveSources(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var sources []models.BlogSource
+	for _, src := range active {
+		if slices.ContainsFunc(failedFeeds, func(f feeds.FailedFeed) bool { return f.Source == src.Name }) {
+			sources = append(sources, src)
+		}
+	}
+	if len(sources) == 0 {
+		return nil, errNothingToRetry
+	}
+	return sources, nil
+}
+
+// mergeLatest merges the results of a retry of the retried sources into the
+// latest session's: its results come first, followed by new posts not among
+// them, and its failed feeds are kept except those retried, which fail only
+// if they did again. It also returns whether the latest session was ranked
+// without AI. With no latest session, the retry's own results are returned.
+func mergeLatest(ctx context.Context, store storage.Store, retried []models.BlogSource,
+	results []DiscoverResult, failedFeeds []feeds.FailedFeed) ([]DiscoverResult, []feeds.FailedFeed, bool) {
+	latest, err := store.GetLatestSession(ctx)
+	if err != nil {
+		if !errors.Is(err, storage.ErrNotFound) {
+			slog.WarnContext(ctx, "failed to load latest session to merge into", "error", err)
+		}
+		return results, failedFeeds, false
+	}
+	latestResults, latestFailed, err := decodeSession(latest)
+	if err != nil {
+		slog.WarnContext(ctx, "failed to decode latest session to merge into", "id", latest.ID, "error", err)
+		return results, failedFeeds, false
+	}
+
+	merged := latestResults
+	for _, r := range results {
+		if !slices.ContainsFunc(merged, func(m DiscoverResult) bool { return m.ID == r.ID }) {
+			merged = append(merged, r)
+		}
+	}
+
+	var failed []feeds.FailedFeed
+	for _, f := range latestFailed {
+		if !slices.ContainsFunc(retried, func(src models.BlogSource) bool { return src.Name == f.Source }) {
+			failed = append(failed, f)
+		}
+	}
+	failed = append(failed, failedFeeds...)
+
+	return merged, failed, latest.Fallback
+}
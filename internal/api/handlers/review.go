@@ -0,0 +1,72 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/hoanghai1803/apricot/internal/models"
+	"github.com/hoanghai1803/apricot/internal/storage"
+)
+
+// resurfaceEnabled reports whether the user opted in to spaced-repetition
+// review of read posts via the "resurface" preference.
+func resurfaceEnabled(ctx context.Context, store storage.Store) bool {
+	var enabled bool
+	if err := store.GetPreference(ctx, "resurface", &enabled); err != nil {
+		return false
+	}
+	return enabled
+}
+
+// GetReviewQueue handles GET /api/review. When the "resurface" preference
+// is on, it returns the read posts rated thumbs-up that are due for review
+// one week, one month, or three months after reading; otherwise the queue
+// is empty.
+func GetReviewQueue(store storage.Store) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		if !resurfaceEnabled(ctx, store) {
+			writeJSON(w, http.StatusOK, []models.ReviewItem{})
+			return
+		}
+
+		queue, err := store.GetReviewQueue(ctx, time.Now())
+		if err != nil {
+			slog.ErrorContext(ctx, "failed to get review queue", "error", err)
+			writeError(w, http.StatusInternalServerError, "Failed to get review queue")
+			return
+		}
+
+		writeJSON(w, http.StatusOK, queue)
+	}
+}
+
+// MarkReviewed handles POST /api/review/{id}. It marks the due review of a
+// reading list item as done, taking it off the queue until the next one.
+func MarkReviewed(store storage.Store) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		id, err := parseID(r, "id")
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		if err := store.MarkReviewed(ctx, id); err != nil {
+			if errors.Is(err, storage.ErrNotFound) {
+				writeError(w, http.StatusNotFound, "No review left for this item")
+				return
+			}
+			slog.ErrorContext(ctx, "failed to mark reviewed", "id", id, "error", err)
+			writeError(w, http.StatusInternalServerError, "Failed to mark reviewed")
+			return
+		}
+
+		writeJSON(w, http.StatusOK, map[string]string{"status": "reviewed"})
+	}
+}
@@ -0,0 +1,81 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/hoanghai1803/apricot/internal/models"
+)
+
+func TestReviewQueue(t *testing.T) {
+	store := newTestStore(t)
+	ctx := t.Context()
+	blogID := seedBlog(t, store)
+
+	if err := store.AddToReadingList(ctx, blogID); err != nil {
+		t.Fatalf("AddToReadingList: %v", err)
+	}
+	itemID, err := store.GetReadingListIDByBlogID(ctx, blogID)
+	if err != nil {
+		t.Fatalf("GetReadingListIDByBlogID: %v", err)
+	}
+	if err := store.UpdateReadingListStatus(ctx, itemID, "read"); err != nil {
+		t.Fatalf("UpdateReadingListStatus: %v", err)
+	}
+	if err := store.SetBlogFeedback(ctx, blogID, 1); err != nil {
+		t.Fatalf("SetBlogFeedback: %v", err)
+	}
+	// Read two weeks ago, so the first review is due.
+	if _, err := store.DB().ExecContext(ctx,
+		`UPDATE reading_list SET read_at = datetime('now', '-14 days') WHERE id = ?`, itemID); err != nil {
+		t.Fatalf("backdating read_at: %v", err)
+	}
+
+	getQueue := func() []models.ReviewItem {
+		t.Helper()
+		r := httptest.NewRequest(http.MethodGet, "/api/review", nil)
+		w := httptest.NewRecorder()
+		GetReviewQueue(store).ServeHTTP(w, r)
+		if w.Code != http.StatusOK {
+			t.Fatalf("GET got status %d, want %d", w.Code, http.StatusOK)
+		}
+		var queue []models.ReviewItem
+		if err := json.NewDecoder(w.Body).Decode(&queue); err != nil {
+			t.Fatalf("decoding response: %v", err)
+		}
+		return queue
+	}
+
+	if queue := getQueue(); len(queue) != 0 {
+		t.Errorf("got %d items with resurfacing off, want 0", len(queue))
+	}
+
+	if err := store.SetPreference(ctx, "resurface", true); err != nil {
+		t.Fatalf("SetPreference: %v", err)
+	}
+	queue := getQueue()
+	if len(queue) != 1 || queue[0].ID != itemID || queue[0].Review != 1 {
+		t.Fatalf("queue = %+v, want the first review of item %d", queue, itemID)
+	}
+
+	for _, want := range []int{http.StatusOK, http.StatusOK, http.StatusOK, http.StatusNotFound} {
+		r := httptest.NewRequest(http.MethodPost, "/api/review/"+jsonInt64(itemID), nil)
+		rctx := chi.NewRouteContext()
+		rctx.URLParams.Add("id", jsonInt64(itemID))
+		r = r.WithContext(context.WithValue(r.Context(), chi.RouteCtxKey, rctx))
+		w := httptest.NewRecorder()
+
+		MarkReviewed(store).ServeHTTP(w, r)
+
+		if w.Code != want {
+			t.Fatalf("POST got status %d, want %d", w.Code, want)
+		}
+	}
+	if queue := getQueue(); len(queue) != 0 {
+		t.Errorf("got %d items after all reviews, want 0", len(queue))
+	}
+}
@@ -0,0 +1,37 @@
+package handlers
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/hoanghai1803/apricot/internal/storage"
+)
+
+// GetBlogRevisions handles GET /api/blogs/{id}/revisions. It returns the
+// earlier versions of a post's content, newest first, kept whenever a
+// re-fetch found the post had been edited since.
+func GetBlogRevisions(store storage.Store) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		id, err := parseID(r, "id")
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		revisions, err := store.GetBlogRevisions(ctx, id)
+		if err != nil {
+			if errors.Is(err, storage.ErrNotFound) {
+				writeError(w, http.StatusNotFound, "Blog not found")
+				return
+			}
+			slog.ErrorContext(ctx, "failed to get blog revisions", "blog_id", id, "error", err)
+			writeError(w, http.StatusInternalServerError, "Failed to get blog revisions")
+			return
+		}
+
+		writeJSON(w, http.StatusOK, revisions)
+	}
+}
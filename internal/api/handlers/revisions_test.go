@@ -0,0 +1,64 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/hoanghai1803/apricot/internal/models"
+)
+
+func TestGetBlogRevisions(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+
+	blog := &models.Blog{
+		SourceID:    1,
+		Title:       "Edited Post",
+		URL:         "https://example.com/edited",
+		FullContent: "as first published",
+		ContentHash: "h1",
+		FetchedAt:   time.Now(),
+	}
+	blogID, err := store.UpsertBlog(ctx, blog)
+	if err != nil {
+		t.Fatalf("UpsertBlog: %v", err)
+	}
+	blog.FullContent, blog.ContentHash = "quietly edited", "h2"
+	if _, err := store.UpsertBlog(ctx, blog); err != nil {
+		t.Fatalf("UpsertBlog: %v", err)
+	}
+
+	get := func(id string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodGet, "/api/blogs/"+id+"/revisions", nil)
+		rctx := chi.NewRouteContext()
+		rctx.URLParams.Add("id", id)
+		r = r.WithContext(context.WithValue(r.Context(), chi.RouteCtxKey, rctx))
+		w := httptest.NewRecorder()
+		GetBlogRevisions(store).ServeHTTP(w, r)
+		return w
+	}
+
+	w := get(jsonInt64(blogID))
+	if w.Code != http.StatusOK {
+		t.Fatalf("got status %d, want %d; body: %s", w.Code, http.StatusOK, w.Body.String())
+	}
+	var revisions []models.BlogRevision
+	if err := json.NewDecoder(w.Body).Decode(&revisions); err != nil {
+		t.Fatalf("decoding response: %v", err)
+	}
+	if len(revisions) != 1 || revisions[0].Content != "as first published" {
+		t.Errorf("revisions = %+v, want the first published version", revisions)
+	}
+
+	if w := get("99999"); w.Code != http.StatusNotFound {
+		t.Errorf("unknown blog: got status %d, want %d", w.Code, http.StatusNotFound)
+	}
+	if w := get("abc"); w.Code != http.StatusBadRequest {
+		t.Errorf("invalid id: got status %d, want %d", w.Code, http.StatusBadRequest)
+	}
+}
@@ -0,0 +1,349 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html/template"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/hoanghai1803/apricot/internal/ai"
+	"github.com/hoanghai1803/apricot/internal/config"
+	"github.com/hoanghai1803/apricot/internal/feeds"
+	"github.com/hoanghai1803/apricot/internal/jobs"
+	"github.com/hoanghai1803/apricot/internal/netguard"
+	"github.com/hoanghai1803/apricot/internal/notify"
+	"github.com/hoanghai1803/apricot/internal/storage"
+)
+
+// SaveResponse is the response of POST /api/save. Status is "saved" for a
+// post new to Apricot, whose details are filled in by the job JobID, or
+// "exists" for one Apricot already had (it is added to the reading list if
+// it was not on it).
+type SaveResponse struct {
+	Status string `json:"status"`
+	ItemID int64  `json:"item_id"`
+	BlogID int64  `json:"blog_id"`
+	JobID  int64  `json:"job_id,omitempty"`
+}
+
+// savePayload is the payload of a "save" job.
+type savePayload struct {
+	BlogID int64  `json:"blog_id"`
+	Source string `json:"source,omitempty"`
+}
+
+// saveRequest is a page to quick-save, with what the client knows of it.
+type saveRequest struct {
+	URL    string   `json:"url"`
+	Title  string   `json:"title"`
+	Tags   []string `json:"tags"`
+	Notes  string   `json:"notes"`
+	Source string   `json:"source"`
+}
+
+// QuickSave handles POST /api/save, for browser extensions and other
+// clients that cannot wait. It takes {"url", "title"?, "tags"?, "notes"?,
+// "source"?}, puts the post on the reading list at once under the given
+// title (or its URL), and queues a "save" job (see SaveJob) to fetch and
+// summarize it. It returns 202 Accepted, or 200 if Apricot already had the
+// post. Notes given for a post already on the reading list are added after
+// its existing notes. Unlike POST /api/reading-list/custom, it never waits on the page or
+// the AI.
+func QuickSave(store storage.Store, runner *jobs.Manager, notifier *notify.Notifier) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req saveRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeError(w, http.StatusBadRequest, "Invalid JSON body")
+			return
+		}
+
+		resp, aerr := quickSave(r.Context(), store, runner, notifier, req)
+		if aerr != nil {
+			writeError(w, aerr.status, aerr.message)
+			return
+		}
+		if resp.Status == "exists" {
+			writeJSON(w, http.StatusOK, resp)
+			return
+		}
+		if resp.JobID != 0 {
+			w.Header().Set("Location", fmt.Sprintf("/api/jobs/%d", resp.JobID))
+		}
+		writeJSON(w, http.StatusAccepted, resp)
+	}
+}
+
+// quickSave saves req as QuickSave describes.
+func quickSave(ctx context.Context, store storage.Store, runner *jobs.Manager, notifier *notify.Notifier, req saveRequest) (SaveResponse, *addError) {
+	rawURL := strings.TrimSpace(req.URL)
+	parsed, err := url.ParseRequestURI(rawURL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") {
+		return SaveResponse{}, &addError{http.StatusBadRequest, "url must be a valid HTTP or HTTPS URL"}
+	}
+
+	resp := SaveResponse{Status: "exists"}
+	existing, err := store.GetBlogByURL(ctx, rawURL)
+	switch {
+	case err == nil:
+		resp.BlogID = existing.ID
+	case errors.Is(err, storage.ErrNotFound):
+		title := strings.TrimSpace(req.Title)
+		if title == "" {
+			title = rawURL
+		}
+		resp.Status = "saved"
+		resp.BlogID, err = store.CreateCustomBlog(ctx, rawURL, title, "", "", parsed.Hostname())
+		if err != nil {
+			slog.ErrorContext(ctx, "failed to create saved blog", "url", rawURL, "error", err)
+			return SaveResponse{}, &addError{http.StatusInternalServerError, "Failed to save blog"}
+		}
+	default:
+		slog.ErrorContext(ctx, "failed to check existing blog", "url", rawURL, "error", err)
+		return SaveResponse{}, &addError{http.StatusInternalServerError, "Failed to check existing blog"}
+	}
+
+	added := true
+	if err := store.AddToReadingList(ctx, resp.BlogID); err != nil {
+		if !errors.Is(err, storage.ErrAlreadyOnList) {
+			slog.ErrorContext(ctx, "failed to add saved blog to reading list", "blog_id", resp.BlogID, "error", err)
+			return SaveResponse{}, &addError{http.StatusInternalServerError, "Failed to add to reading list"}
+		}
+		added = false
+	}
+	resp.ItemID, err = store.GetReadingListIDByBlogID(ctx, resp.BlogID)
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to find saved item", "blog_id", resp.BlogID, "error", err)
+		return SaveResponse{}, &addError{http.StatusInternalServerError, "Failed to add to reading list"}
+	}
+
+	for _, tag := range req.Tags {
+		if tag = strings.TrimSpace(tag); tag == "" {
+			continue
+		}
+		if err := store.AddTagToItem(ctx, resp.ItemID, tag); err != nil {
+			slog.WarnContext(ctx, "failed to tag saved item", "item_id", resp.ItemID, "tag", tag, "error", err)
+		}
+	}
+	if notes := strings.TrimSpace(req.Notes); notes != "" {
+		if !added {
+			notes = appendNotes(ctx, store, resp.ItemID, notes)
+		}
+		if notes != "" {
+			if err := store.UpdateReadingListNotes(ctx, resp.ItemID, notes); err != nil {
+				slog.WarnContext(ctx, "failed to save notes", "item_id", resp.ItemID, "error", err)
+			}
+		}
+	}
+
+	if resp.Status == "exists" {
+		if added {
+			notifyItem(ctx, store, notifier, notify.EventItemAdded, resp.ItemID)
+		}
+		return resp, nil
+	}
+
+	job, err := runner.Enqueue(ctx, "save", savePayload{BlogID: resp.BlogID, Source: strings.TrimSpace(req.Source)})
+	if err != nil {
+		// The post is saved; it just keeps the title it was given.
+		slog.ErrorContext(ctx, "failed to queue save job", "blog_id", resp.BlogID, "error", err)
+		return resp, nil
+	}
+	resp.JobID = job.ID
+	return resp, nil
+}
+
+// appendNotes returns the notes of the existing item id with notes added
+// after them, so saving a post again never loses what was written about it.
+// It returns "" when there is nothing to write: the item already has those
+// notes, or they could not be read.
+func appendNotes(ctx context.Context, store storage.Store, id int64, notes string) string {
+	item, err := store.GetReadingListItemByID(ctx, id)
+	if err != nil {
+		slog.WarnContext(ctx, "failed to read notes", "item_id", id, "error", err)
+		return ""
+	}
+	if item.Notes == nil || strings.TrimSpace(*item.Notes) == "" {
+		return notes
+	}
+	existing := strings.TrimRight(*item.Notes, "\n")
+	if strings.Contains(existing, notes) {
+		return ""
+	}
+	return existing + "\n\n" + notes
+}
+
+// SaveJob returns the "save" job kind, which fetches a quick-saved post,
+// fills in its title, excerpt, content, and source, summarizes it if an AI
+// provider is configured, and emits item.added. Fetching is retried twice
+// unless the address is refused.
+func SaveJob(store storage.Store, fetcher *feeds.Fetcher, aiProvider ai.AIProvider, cfg *config.Config, notifier *notify.Notifier) jobs.Kind {
+	return jobs.Kind{
+		Name:        "save",
+		Timeout:     3 * time.Minute,
+		MaxAttempts: 3,
+		Handler: func(ctx context.Context, payload json.RawMessage) (any, error) {
+			var p savePayload
+			if err := json.Unmarshal(payload, &p); err != nil {
+				return nil, jobs.Permanent(fmt.Errorf("decoding payload: %w", err))
+			}
+			blog, err := store.GetBlogByID(ctx, p.BlogID)
+			if errors.Is(err, storage.ErrNotFound) {
+				return nil, jobs.Permanent(errors.New("the saved post was deleted"))
+			}
+			if err != nil {
+				return nil, fmt.Errorf("loading saved post: %w", err)
+			}
+
+			meta, err := fetcher.ExtractArticleMetadata(ctx, blog.URL)
+			if errors.Is(err, netguard.ErrBlocked) {
+				return nil, jobs.Permanent(errors.New(blockedMessage))
+			}
+			if err != nil {
+				return nil, fmt.Errorf("fetching %s: %w", blog.URL, err)
+			}
+
+			title := meta.Title
+			if title == "" {
+				title = blog.Title // as saved
+			}
+			source := p.Source
+			if source == "" {
+				source = meta.SiteName
+			}
+			if source == "" {
+				source = blog.Source
+			}
+			if err := store.UpdateCustomBlog(ctx, blog.ID, title, meta.Excerpt, meta.TextContent, source); err != nil {
+				return nil, fmt.Errorf("updating saved post: %w", err)
+			}
+
+			if aiProvider != nil {
+				summarizeCustomBlog(ctx, store, aiProvider, cfg, blog.ID)
+			}
+			notifyAdded(ctx, store, notifier, blog.ID)
+			return map[string]any{"blog_id": blog.ID, "title": title}, nil
+		},
+	}
+}
+
+// SavePage handles GET /save?url=, the target of the bookmarklet and of
+// the web app's share target, so phones can save pages without an
+// extension. It saves the page like POST /api/save and shows a small
+// confirmation page. A share sheet may pass the link in text instead of
+// url, so the first link in text is used when url is empty.
+//
+// The page only saves when the navigation started in Apricot itself or in
+// the browser (Sec-Fetch-Site is same-origin or none), so other sites
+// cannot fill the reading list. A bookmarklet's navigation counts as
+// cross-site: it gets a button to confirm instead. The auth cookie is not
+// sent on such navigations either, so Auth lets them through (see
+// CrossSite); the button's request carries it.
+func SavePage(store storage.Store, runner *jobs.Manager, notifier *notify.Notifier) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		req := saveRequest{URL: strings.TrimSpace(q.Get("url")), Title: q.Get("title")}
+		if req.URL == "" {
+			req.URL = firstLink(q.Get("text"))
+		}
+		page := savePageData{URL: req.URL, Title: strings.TrimSpace(req.Title)}
+		if page.Title == "" {
+			page.Title = req.URL
+		}
+
+		status := http.StatusOK
+		switch {
+		case req.URL == "":
+			status, page.Error = http.StatusBadRequest, "There is no link to save."
+		case CrossSite(r):
+			page.Confirm = true
+		default:
+			resp, aerr := quickSave(r.Context(), store, runner, notifier, req)
+			if aerr != nil {
+				status, page.Error = aerr.status, aerr.message
+				break
+			}
+			page.Exists = resp.Status == "exists"
+			page.ItemID = resp.ItemID
+		}
+
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Header().Set("Cache-Control", "no-store")
+		w.WriteHeader(status)
+		if err := savePageHTML.Execute(w, page); err != nil {
+			slog.ErrorContext(r.Context(), "failed to render save page", "error", err)
+		}
+	}
+}
+
+// CrossSite reports whether r may be a navigation from another site, which
+// GET /save only answers with a button to confirm. Only a Sec-Fetch-Site of
+// same-origin or none proves otherwise, so requests from browsers too old
+// to send the header count as cross-site.
+func CrossSite(r *http.Request) bool {
+	switch r.Header.Get("Sec-Fetch-Site") {
+	case "same-origin", "none":
+		return false
+	}
+	return true
+}
+
+// firstLink returns the first HTTP or HTTPS URL in text, or "".
+func firstLink(text string) string {
+	for _, field := range strings.Fields(text) {
+		if strings.HasPrefix(field, "http://") || strings.HasPrefix(field, "https://") {
+			return field
+		}
+	}
+	return ""
+}
+
+// savePageData is what the save page shows.
+type savePageData struct {
+	URL, Title, Error string
+	Confirm, Exists   bool
+	ItemID            int64
+}
+
+var savePageHTML = template.Must(template.New("save").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{if .Error}}Not saved{{else if .Confirm}}Save to Apricot{{else}}Saved{{end}} · Apricot</title>
+<style>
+body{margin:0;padding:32px 20px;background:#fafaf9;font-family:-apple-system,'Segoe UI',Helvetica,Arial,sans-serif;color:#1c1917}
+main{max-width:480px;margin:0 auto}
+h1{font-size:20px;margin:0 0 12px}
+.title{font-weight:600;margin:0 0 4px;overflow-wrap:anywhere}
+.url{color:#78716c;font-size:13px;margin:0 0 24px;overflow-wrap:anywhere}
+a{color:#c2410c}
+button{font:inherit;padding:10px 20px;border:0;border-radius:8px;background:#ea580c;color:#fff;cursor:pointer}
+nav{display:flex;gap:16px;font-size:14px}
+</style>
+</head>
+<body>
+<main>
+{{if .Error}}<h1>Not saved</h1>
+<p>{{.Error}}</p>
+{{else if .Confirm}}<h1>Save to Apricot?</h1>
+<p class="title">{{.Title}}</p>
+<p class="url">{{.URL}}</p>
+<form method="get" action="/save">
+<input type="hidden" name="url" value="{{.URL}}">
+<input type="hidden" name="title" value="{{.Title}}">
+<button type="submit">Save</button>
+</form>
+{{else}}<h1>{{if .Exists}}Already on your reading list{{else}}Saved to your reading list{{end}}</h1>
+<p class="title">{{.Title}}</p>
+<p class="url">{{.URL}}</p>
+<nav><a href="/read/{{.ItemID}}">Read it now</a><a href="/reading-list">Reading list</a>{{if .URL}}<a href="{{.URL}}">Back to the page</a>{{end}}</nav>
+{{end}}</main>
+</body>
+</html>
+`))
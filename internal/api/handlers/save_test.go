@@ -0,0 +1,161 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/hoanghai1803/apricot/internal/config"
+	"github.com/hoanghai1803/apricot/internal/feeds"
+	"github.com/hoanghai1803/apricot/internal/jobs"
+	"github.com/hoanghai1803/apricot/internal/models"
+)
+
+func TestQuickSave(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+	site := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		w.Write([]byte(`<html><head><title>Fetched Title</title></head><body><article><p>` + strings.Repeat("Words. ", 50) + `</p></article></body></html>`)) //nolint:errcheck
+	}))
+	defer site.Close()
+
+	runner := jobs.NewManager(store, 1)
+	runner.Register(SaveJob(store, feeds.NewFetcher(nil), nil, &config.Config{}, nil))
+	handler := QuickSave(store, runner, nil)
+
+	body := `{"url": "` + site.URL + `/post", "title": "Tab Title", "tags": ["go", " "], "notes": "read later"}`
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/save", strings.NewReader(body)))
+	if w.Code != http.StatusAccepted {
+		t.Fatalf("status %d, want %d; body: %s", w.Code, http.StatusAccepted, w.Body.String())
+	}
+	var resp SaveResponse
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("decoding response: %v", err)
+	}
+	if resp.Status != "saved" || resp.JobID == 0 || w.Header().Get("Location") == "" {
+		t.Errorf("response = %+v, want a saved post with a job", resp)
+	}
+
+	// The item is on the list at once, under the given title.
+	item, err := store.GetReadingListItemByID(ctx, resp.ItemID)
+	if err != nil {
+		t.Fatalf("GetReadingListItemByID() error: %v", err)
+	}
+	if item.Blog.Title != "Tab Title" || item.Notes == nil || *item.Notes != "read later" || len(item.Tags) != 1 || item.Tags[0] != "go" {
+		t.Errorf("item = %+v (blog %+v), want the given title, notes, and tag", item, item.Blog)
+	}
+
+	if err := runner.Drain(ctx); err != nil {
+		t.Fatalf("Drain() error: %v", err)
+	}
+	job, err := runner.Get(ctx, resp.JobID)
+	if err != nil || job.Status != models.JobSucceeded {
+		t.Fatalf("save job = %+v, %v; want it to have succeeded", job, err)
+	}
+	blog, err := store.GetBlogByID(ctx, resp.BlogID)
+	if err != nil {
+		t.Fatalf("GetBlogByID() error: %v", err)
+	}
+	if blog.Title != "Fetched Title" || blog.FullContent == "" {
+		t.Errorf("blog = %+v, want the fetched title and content", blog)
+	}
+
+	// Saving it again finds the existing post without fetching it again.
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/save", strings.NewReader(body)))
+	if w.Code != http.StatusOK {
+		t.Fatalf("second save: status %d, want %d", w.Code, http.StatusOK)
+	}
+	var again SaveResponse
+	if err := json.NewDecoder(w.Body).Decode(&again); err != nil || again.Status != "exists" || again.JobID != 0 {
+		t.Errorf("second save = %+v, %v; want exists with no job", again, err)
+	}
+
+	// Notes saved again are added after the existing ones, once.
+	for _, notes := range []string{"see part two", "see part two"} {
+		w = httptest.NewRecorder()
+		body := `{"url": "` + site.URL + `/post", "notes": "` + notes + `"}`
+		handler.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/save", strings.NewReader(body)))
+		if w.Code != http.StatusOK {
+			t.Fatalf("save with notes: status %d, want %d", w.Code, http.StatusOK)
+		}
+	}
+	item, err = store.GetReadingListItemByID(ctx, resp.ItemID)
+	if err != nil {
+		t.Fatalf("GetReadingListItemByID() error: %v", err)
+	}
+	if item.Notes == nil || *item.Notes != "read later\n\nsee part two" {
+		t.Errorf("notes = %v, want the new notes after the old", item.Notes)
+	}
+
+	for _, bad := range []string{`{"url": "ftp://example.com/x"}`, `{"url": ""}`, `not json`} {
+		w = httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/save", strings.NewReader(bad)))
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("%s: status %d, want %d", bad, w.Code, http.StatusBadRequest)
+		}
+	}
+}
+
+func TestSavePage(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+	runner := jobs.NewManager(store, 1)
+	handler := SavePage(store, runner, nil)
+
+	get := func(target, site string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodGet, target, nil)
+		if site != "" {
+			r.Header.Set("Sec-Fetch-Site", site)
+		}
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		return w
+	}
+
+	// A bookmarklet on another site only gets a button to confirm.
+	w := get("/save?url=https://a.example/post&title=A+%3Cb%3EPost%3C/b%3E", "cross-site")
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `<button type="submit">Save</button>`) {
+		t.Errorf("cross-site: status %d, body %s; want a confirmation button", w.Code, w.Body.String())
+	}
+	if strings.Contains(w.Body.String(), "<b>Post</b>") {
+		t.Error("cross-site: the title was not escaped")
+	}
+	if _, err := store.GetBlogByURL(ctx, "https://a.example/post"); err == nil {
+		t.Error("cross-site: the page was saved without confirmation")
+	}
+
+	// So does a request that does not say where it came from.
+	w = get("/save?url=https://a.example/post&title=A+Post", "")
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `<button type="submit">Save</button>`) {
+		t.Errorf("no Sec-Fetch-Site: status %d, body %s; want a confirmation button", w.Code, w.Body.String())
+	}
+	if _, err := store.GetBlogByURL(ctx, "https://a.example/post"); err == nil {
+		t.Error("no Sec-Fetch-Site: the page was saved without confirmation")
+	}
+
+	// Confirming saves it.
+	w = get("/save?url=https://a.example/post&title=A+Post", "same-origin")
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), "Saved to your reading list") {
+		t.Errorf("same-origin: status %d, body %s; want it saved", w.Code, w.Body.String())
+	}
+	blog, err := store.GetBlogByURL(ctx, "https://a.example/post")
+	if err != nil || blog.Title != "A Post" {
+		t.Fatalf("GetBlogByURL() = %+v, %v; want the saved post", blog, err)
+	}
+
+	// A share sheet may put the link in the text; this one is saved already.
+	w = get("/save?text=Worth+a+read+https://a.example/post", "none")
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), "Already on your reading list") {
+		t.Errorf("shared text: status %d, body %s; want it found", w.Code, w.Body.String())
+	}
+
+	if w = get("/save?text=no+link", "none"); w.Code != http.StatusBadRequest {
+		t.Errorf("no link: status %d, want %d", w.Code, http.StatusBadRequest)
+	}
+}
@@ -0,0 +1,110 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/hoanghai1803/apricot/internal/notify"
+	"github.com/hoanghai1803/apricot/internal/storage"
+)
+
+// SaveAllResponse is the body of SaveSessionResults: how many results were
+// added to the reading list and how many were on it already, and the posts
+// added, best first.
+type SaveAllResponse struct {
+	Added        int     `json:"added"`
+	AlreadySaved int     `json:"already_saved"`
+	BlogIDs      []int64 `json:"blog_ids"`
+}
+
+// SaveSessionResults handles POST /api/discover/sessions/{id}/save-all. It
+// adds the session's results to the reading list in one transaction, at the
+// head of the queue in their ranked order (see
+// storage.AddManyToReadingList). The optional "top" (in the
+// JSON body or the query) saves only the results ranked that high or
+// better, 1 being the first. Added posts emit item.added; results already
+// on the list are counted but left alone.
+func SaveSessionResults(store storage.Store, notifier *notify.Notifier) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		id, err := parseID(r, "id")
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "Invalid session ID")
+			return
+		}
+
+		var body struct {
+			Top int `json:"top"`
+		}
+		data, err := io.ReadAll(r.Body)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "Failed to read request body")
+			return
+		}
+		if len(data) > 0 {
+			if err := json.Unmarshal(data, &body); err != nil {
+				writeError(w, http.StatusBadRequest, "Invalid JSON body")
+				return
+			}
+		}
+		if v := r.URL.Query().Get("top"); v != "" {
+			if body.Top, err = strconv.Atoi(v); err != nil {
+				writeError(w, http.StatusBadRequest, "top must be a positive integer")
+				return
+			}
+		}
+		if body.Top < 0 {
+			writeError(w, http.StatusBadRequest, "top must be a positive integer")
+			return
+		}
+
+		session, err := store.GetSession(ctx, id)
+		if err != nil {
+			if errors.Is(err, storage.ErrNotFound) {
+				writeError(w, http.StatusNotFound, "Discovery session not found")
+				return
+			}
+			slog.ErrorContext(ctx, "failed to get discovery session", "id", id, "error", err)
+			writeError(w, http.StatusInternalServerError, "Failed to load discovery session")
+			return
+		}
+		results, _, err := decodeSession(session)
+		if err != nil {
+			slog.ErrorContext(ctx, "failed to unmarshal session results", "id", id, "error", err)
+			writeError(w, http.StatusInternalServerError, "Failed to parse stored results")
+			return
+		}
+		if body.Top > 0 {
+			results = results[:min(body.Top, len(results))]
+		}
+
+		blogIDs := make([]int64, 0, len(results))
+		for _, result := range results {
+			blogIDs = append(blogIDs, result.ID)
+		}
+		added, err := store.AddManyToReadingList(ctx, blogIDs)
+		if err != nil {
+			if errors.Is(err, storage.ErrNotFound) {
+				writeError(w, http.StatusNotFound, "A result's post no longer exists")
+				return
+			}
+			slog.ErrorContext(ctx, "failed to save session results", "id", id, "error", err)
+			writeError(w, http.StatusInternalServerError, "Failed to add to reading list")
+			return
+		}
+		for _, blogID := range added {
+			notifyAdded(ctx, store, notifier, blogID)
+		}
+
+		writeJSON(w, http.StatusOK, SaveAllResponse{
+			Added:        len(added),
+			AlreadySaved: len(blogIDs) - len(added),
+			BlogIDs:      added,
+		})
+	}
+}
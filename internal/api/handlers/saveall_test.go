@@ -0,0 +1,111 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"testing/iotest"
+	"time"
+
+	"github.com/hoanghai1803/apricot/internal/models"
+)
+
+func TestSaveSessionResults(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+
+	var ids []int64
+	var results []string
+	for i := range 4 {
+		id, err := store.UpsertBlog(ctx, &models.Blog{
+			SourceID: 1, Title: fmt.Sprintf("Post %d", i), URL: fmt.Sprintf("https://example.com/save-all-%d", i),
+			FetchedAt: time.Now(),
+		})
+		if err != nil {
+			t.Fatalf("UpsertBlog: %v", err)
+		}
+		ids = append(ids, id)
+		results = append(results, fmt.Sprintf(`{"id":%d,"title":"Post %d"}`, id, i))
+	}
+	sessionNum, err := store.CreateSession(ctx, &models.DiscoverySession{
+		PreferencesSnapshot: "{}",
+		BlogsSelected:       "[]",
+		ModelUsed:           "test",
+		ResultsJSON:         "[" + strings.Join(results, ",") + "]",
+	})
+	if err != nil {
+		t.Fatalf("CreateSession: %v", err)
+	}
+	// The third result is saved already.
+	if err := store.AddToReadingList(ctx, ids[2]); err != nil {
+		t.Fatalf("AddToReadingList: %v", err)
+	}
+
+	saveAll := func(id, body string) *httptest.ResponseRecorder {
+		t.Helper()
+		r := httptest.NewRequest(http.MethodPost, "/api/discover/sessions/"+id+"/save-all", strings.NewReader(body))
+		w := httptest.NewRecorder()
+		SaveSessionResults(store, nil).ServeHTTP(w, withURLParams(r, "id", id))
+		return w
+	}
+
+	sessionID := fmt.Sprint(sessionNum)
+	w := saveAll(sessionID, `{"top": 3}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status %d, want 200; body: %s", w.Code, w.Body.String())
+	}
+	var resp SaveAllResponse
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("decoding response: %v", err)
+	}
+	if resp.Added != 2 || resp.AlreadySaved != 1 || !slices.Equal(resp.BlogIDs, []int64{ids[0], ids[1]}) {
+		t.Errorf("response = %+v, want the first two added and one already saved", resp)
+	}
+
+	// The saved results head the reading list in their ranked order.
+	items, err := store.GetReadingList(ctx, "")
+	if err != nil {
+		t.Fatalf("GetReadingList: %v", err)
+	}
+	var order []int64
+	for _, item := range items {
+		order = append(order, item.BlogID)
+	}
+	if !slices.Equal(order, []int64{ids[0], ids[1], ids[2]}) {
+		t.Errorf("reading list order = %v, want %v", order, ids[:3])
+	}
+
+	// Without a cutoff, the rest are saved.
+	w = saveAll(sessionID, "")
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil || resp.Added != 1 || resp.AlreadySaved != 3 {
+		t.Errorf("second save = %d %+v, %v; want 1 added and 3 already saved", w.Code, resp, err)
+	}
+
+	for _, tc := range []struct {
+		id, body string
+		want     int
+	}{
+		{"x", "", http.StatusBadRequest},
+		{sessionID, "{", http.StatusBadRequest},
+		{sessionID, `{"top": -1}`, http.StatusBadRequest},
+		{"9999", "", http.StatusNotFound},
+	} {
+		if w := saveAll(tc.id, tc.body); w.Code != tc.want {
+			t.Errorf("save-all %s %q: status %d, want %d", tc.id, tc.body, w.Code, tc.want)
+		}
+	}
+
+	// A body that fails to read is not taken for an empty one.
+	r := httptest.NewRequest(http.MethodPost, "/api/discover/sessions/"+sessionID+"/save-all", iotest.ErrReader(errors.New("connection reset")))
+	w = httptest.NewRecorder()
+	SaveSessionResults(store, nil).ServeHTTP(w, withURLParams(r, "id", sessionID))
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("save-all with an unreadable body: status %d, want 400", w.Code)
+	}
+}
@@ -3,38 +3,54 @@ package handlers
 import (
 	"log/slog"
 	"net/http"
-	"strconv"
 
 	"github.com/hoanghai1803/apricot/internal/models"
 	"github.com/hoanghai1803/apricot/internal/storage"
 )
 
-// SearchBlogs handles GET /api/search?q={query}&limit={limit}. It performs
-// full-text search on blogs using FTS5.
-func SearchBlogs(store *storage.Store) http.HandlerFunc {
+// SearchBlogs handles GET /api/search?q={query}&limit={limit}&offset={offset}.
+// It performs full-text search on blogs using FTS5 and returns a page of
+// matches, each with its highlighted title and a snippet showing why it
+// matched, and the total number of matches. The limit defaults to 20.
+func SearchBlogs(store storage.Store) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		ctx := r.Context()
 
+		limit, offset, err := parsePage(r, 20)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		if limit == 0 {
+			limit = 20
+		}
+
 		query := r.URL.Query().Get("q")
 		if query == "" {
-			writeJSON(w, http.StatusOK, []models.Blog{})
+			writeJSON(w, http.StatusOK, models.SearchPage{
+				Results: []models.SearchResult{}, Limit: limit, Offset: offset,
+			})
 			return
 		}
 
-		limit := 20
-		if l := r.URL.Query().Get("limit"); l != "" {
-			if parsed, err := strconv.Atoi(l); err == nil && parsed > 0 {
-				limit = parsed
-			}
+		results, err := store.SearchBlogs(ctx, query, limit, offset)
+		if err != nil {
+			slog.ErrorContext(ctx, "failed to search blogs", "query", query, "error", err)
+			writeError(w, http.StatusInternalServerError, "Search failed")
+			return
 		}
-
-		blogs, err := store.SearchBlogs(ctx, query, limit)
+		total, err := store.CountSearchResults(ctx, query)
 		if err != nil {
-			slog.Error("failed to search blogs", "query", query, "error", err)
+			slog.ErrorContext(ctx, "failed to count search results", "query", query, "error", err)
 			writeError(w, http.StatusInternalServerError, "Search failed")
 			return
 		}
 
-		writeJSON(w, http.StatusOK, blogs)
+		writeJSON(w, http.StatusOK, models.SearchPage{
+			Results: results,
+			Total:   total,
+			Limit:   limit,
+			Offset:  offset,
+		})
 	}
 }
@@ -0,0 +1,211 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/hoanghai1803/apricot/internal/config"
+	"github.com/hoanghai1803/apricot/internal/cron"
+	"github.com/hoanghai1803/apricot/internal/storage"
+)
+
+// Settings are the settings that can be changed while Apricot runs, as
+// served by GET /api/settings. Each is kept as the preference of the same
+// name; those not set, or set out of range, take their default, from the
+// config file where it has one.
+type Settings struct {
+	FeedMode            string `json:"feed_mode"`             // "recent_posts" or "time_range"
+	MaxArticlesPerFeed  int    `json:"max_articles_per_feed"` // 5-20
+	LookbackDays        int    `json:"lookback_days"`         // 1-30
+	MaxResults          int    `json:"max_results"`           // 5-20
+	MaxReadingMinutes   int    `json:"max_reading_minutes"`   // 0 for no limit
+	Timezone            string `json:"timezone"`              // IANA name, for display
+	RewriteTitles       bool   `json:"rewrite_titles"`
+	WeightBySourceScore bool   `json:"weight_by_source_score"`
+	Resurface           bool   `json:"resurface"`
+
+	// DiscoverSchedule runs discovery automatically, as a cron expression;
+	// empty turns it off. It defaults to [feeds] discover_schedule.
+	DiscoverSchedule string `json:"discover_schedule"`
+
+	// NotificationTargets counts the configured webhooks, Slack, Discord,
+	// Telegram, and ntfy targets by kind. They hold credentials, so they
+	// are set in the config file only and cannot be changed here.
+	NotificationTargets map[string]int `json:"notification_targets"`
+}
+
+// settingsUpdate is the body of PUT /api/settings: the settings to change.
+type settingsUpdate struct {
+	FeedMode            *string `json:"feed_mode"`
+	MaxArticlesPerFeed  *int    `json:"max_articles_per_feed"`
+	LookbackDays        *int    `json:"lookback_days"`
+	MaxResults          *int    `json:"max_results"`
+	MaxReadingMinutes   *int    `json:"max_reading_minutes"`
+	Timezone            *string `json:"timezone"`
+	RewriteTitles       *bool   `json:"rewrite_titles"`
+	WeightBySourceScore *bool   `json:"weight_by_source_score"`
+	Resurface           *bool   `json:"resurface"`
+	DiscoverSchedule    *string `json:"discover_schedule"`
+}
+
+// LoadSettings returns the current settings.
+func LoadSettings(ctx context.Context, store storage.Store, cfg *config.Config) Settings {
+	s := Settings{
+		FeedMode:           "recent_posts",
+		MaxArticlesPerFeed: cfg.Feeds.MaxArticlesPerFeed,
+		LookbackDays:       cfg.Feeds.LookbackDays,
+		MaxResults:         10,
+		Timezone:           "UTC",
+		DiscoverSchedule:   cfg.Feeds.DiscoverSchedule,
+		NotificationTargets: map[string]int{
+			"webhooks": len(cfg.Webhooks),
+			"slack":    len(cfg.Slack),
+			"discord":  len(cfg.Discord),
+			"telegram": len(cfg.Telegram),
+			"ntfy":     len(cfg.Ntfy),
+		},
+	}
+
+	// Each stored value replaces the default if it is still valid.
+	var str string
+	var n int
+	if store.GetPreference(ctx, "feed_mode", &str) == nil && validFeedMode(str) {
+		s.FeedMode = str
+	}
+	if store.GetPreference(ctx, "max_articles_per_feed", &n) == nil && n >= 5 && n <= 20 {
+		s.MaxArticlesPerFeed = n
+	}
+	if store.GetPreference(ctx, "lookback_days", &n) == nil && n >= 1 && n <= 30 {
+		s.LookbackDays = n
+	}
+	if store.GetPreference(ctx, "max_results", &n) == nil && n >= 5 && n <= 20 {
+		s.MaxResults = n
+	}
+	if store.GetPreference(ctx, "max_reading_minutes", &n) == nil && n >= 0 {
+		s.MaxReadingMinutes = n
+	}
+	if store.GetPreference(ctx, "timezone", &str) == nil && str != "" {
+		s.Timezone = str
+	}
+	if store.GetPreference(ctx, "discover_schedule", &str) == nil && validSchedule(str) {
+		s.DiscoverSchedule = str
+	}
+	s.RewriteTitles = titleRewriteEnabled(ctx, store)
+	s.WeightBySourceScore = sourceWeightingEnabled(ctx, store)
+	s.Resurface = resurfaceEnabled(ctx, store)
+	return s
+}
+
+func validFeedMode(mode string) bool {
+	return mode == "recent_posts" || mode == "time_range"
+}
+
+// validSchedule reports whether spec is a cron expression or empty.
+func validSchedule(spec string) bool {
+	if spec == "" {
+		return true
+	}
+	_, err := cron.Parse(spec)
+	return err == nil
+}
+
+// validate checks the settings u changes.
+func (u settingsUpdate) validate() error {
+	inRange := func(name string, v *int, lo, hi int) error {
+		if v != nil && (*v < lo || *v > hi) {
+			return fmt.Errorf("%s must be between %d and %d", name, lo, hi)
+		}
+		return nil
+	}
+	if u.FeedMode != nil && !validFeedMode(*u.FeedMode) {
+		return errors.New(`feed_mode must be "recent_posts" or "time_range"`)
+	}
+	if err := errors.Join(
+		inRange("max_articles_per_feed", u.MaxArticlesPerFeed, 5, 20),
+		inRange("lookback_days", u.LookbackDays, 1, 30),
+		inRange("max_results", u.MaxResults, 5, 20),
+	); err != nil {
+		return err
+	}
+	if u.MaxReadingMinutes != nil && *u.MaxReadingMinutes < 0 {
+		return errors.New("max_reading_minutes cannot be negative")
+	}
+	if u.Timezone != nil {
+		if _, err := time.LoadLocation(*u.Timezone); err != nil || *u.Timezone == "" {
+			return fmt.Errorf("unknown timezone %q", *u.Timezone)
+		}
+	}
+	if u.DiscoverSchedule != nil {
+		if spec := strings.TrimSpace(*u.DiscoverSchedule); spec != "" {
+			if _, err := cron.Parse(spec); err != nil {
+				return fmt.Errorf("invalid discover_schedule: %w", err)
+			}
+		}
+	}
+	return nil
+}
+
+// GetSettings handles GET /api/settings. It returns the settings with
+// their defaults filled in.
+func GetSettings(store storage.Store, cfg *config.Config) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, LoadSettings(r.Context(), store, cfg))
+	}
+}
+
+// UpdateSettings handles PUT /api/settings. It changes the settings in the
+// body, leaving out ones unchanged, and returns them all as GET does. An
+// invalid value is a 400, and nothing is saved. notification_targets is
+// read-only and ignored.
+func UpdateSettings(store storage.Store, cfg *config.Config) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		var u settingsUpdate
+		if err := json.NewDecoder(r.Body).Decode(&u); err != nil {
+			writeError(w, http.StatusBadRequest, "Invalid JSON body")
+			return
+		}
+		if err := u.validate(); err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		if u.DiscoverSchedule != nil {
+			*u.DiscoverSchedule = strings.TrimSpace(*u.DiscoverSchedule)
+		}
+
+		for _, setting := range []struct {
+			key   string
+			value any
+			set   bool
+		}{
+			{"feed_mode", u.FeedMode, u.FeedMode != nil},
+			{"max_articles_per_feed", u.MaxArticlesPerFeed, u.MaxArticlesPerFeed != nil},
+			{"lookback_days", u.LookbackDays, u.LookbackDays != nil},
+			{"max_results", u.MaxResults, u.MaxResults != nil},
+			{"max_reading_minutes", u.MaxReadingMinutes, u.MaxReadingMinutes != nil},
+			{"timezone", u.Timezone, u.Timezone != nil},
+			{"rewrite_titles", u.RewriteTitles, u.RewriteTitles != nil},
+			{"weight_by_source_score", u.WeightBySourceScore, u.WeightBySourceScore != nil},
+			{"resurface", u.Resurface, u.Resurface != nil},
+			{"discover_schedule", u.DiscoverSchedule, u.DiscoverSchedule != nil},
+		} {
+			if !setting.set {
+				continue
+			}
+			if err := store.SetPreference(ctx, setting.key, setting.value); err != nil {
+				slog.ErrorContext(ctx, "failed to save setting", "key", setting.key, "error", err)
+				writeError(w, http.StatusInternalServerError, "Failed to save settings")
+				return
+			}
+		}
+
+		writeJSON(w, http.StatusOK, LoadSettings(ctx, store, cfg))
+	}
+}
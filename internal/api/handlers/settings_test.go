@@ -0,0 +1,83 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/hoanghai1803/apricot/internal/config"
+)
+
+func TestSettings(t *testing.T) {
+	store := newTestStore(t)
+	cfg := &config.Config{
+		Feeds: config.FeedsConfig{MaxArticlesPerFeed: 15, LookbackDays: 7, DiscoverSchedule: "0 7 * * *"},
+		Slack: []config.SlackConfig{{}},
+	}
+
+	get := func() Settings {
+		t.Helper()
+		w := httptest.NewRecorder()
+		GetSettings(store, cfg).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/settings", nil))
+		if w.Code != http.StatusOK {
+			t.Fatalf("GET got status %d, want %d", w.Code, http.StatusOK)
+		}
+		var s Settings
+		if err := json.NewDecoder(w.Body).Decode(&s); err != nil {
+			t.Fatalf("decoding response: %v", err)
+		}
+		return s
+	}
+	put := func(body string) *httptest.ResponseRecorder {
+		t.Helper()
+		w := httptest.NewRecorder()
+		UpdateSettings(store, cfg).ServeHTTP(w, httptest.NewRequest(http.MethodPut, "/api/settings", strings.NewReader(body)))
+		return w
+	}
+
+	s := get()
+	if s.FeedMode != "recent_posts" || s.MaxArticlesPerFeed != 15 || s.MaxResults != 10 ||
+		s.Timezone != "UTC" || s.DiscoverSchedule != "0 7 * * *" || s.RewriteTitles {
+		t.Errorf("defaults = %+v, want those of the config", s)
+	}
+	if s.NotificationTargets["slack"] != 1 || s.NotificationTargets["webhooks"] != 0 {
+		t.Errorf("notification_targets = %v, want 1 slack target", s.NotificationTargets)
+	}
+
+	w := put(`{"feed_mode": "time_range", "max_results": 15, "rewrite_titles": true, "discover_schedule": " "}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("PUT got status %d, want %d; body: %s", w.Code, http.StatusOK, w.Body.String())
+	}
+	s = get()
+	if s.FeedMode != "time_range" || s.MaxResults != 15 || !s.RewriteTitles || s.DiscoverSchedule != "" {
+		t.Errorf("after PUT = %+v, want the new values and no schedule", s)
+	}
+	if s.MaxArticlesPerFeed != 15 {
+		t.Errorf("max_articles_per_feed = %d, want it unchanged", s.MaxArticlesPerFeed)
+	}
+
+	// The settings are preferences, so the pipeline sees them.
+	var mode string
+	if err := store.GetPreference(t.Context(), "feed_mode", &mode); err != nil || mode != "time_range" {
+		t.Errorf("feed_mode preference = %q, %v; want time_range", mode, err)
+	}
+
+	for _, body := range []string{
+		`{"max_results": 50}`,
+		`{"feed_mode": "latest"}`,
+		`{"lookback_days": 0}`,
+		`{"max_reading_minutes": -5}`,
+		`{"timezone": "Mars/Olympus_Mons"}`,
+		`{"discover_schedule": "every morning"}`,
+		`{"max_results": "ten"}`,
+	} {
+		if w := put(body); w.Code != http.StatusBadRequest {
+			t.Errorf("PUT %s got status %d, want %d", body, w.Code, http.StatusBadRequest)
+		}
+	}
+	if s := get(); s.MaxResults != 15 {
+		t.Errorf("max_results after invalid PUTs = %d, want 15", s.MaxResults)
+	}
+}
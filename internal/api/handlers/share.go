@@ -0,0 +1,243 @@
+package handlers
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"html/template"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/hoanghai1803/apricot/internal/config"
+	"github.com/hoanghai1803/apricot/internal/models"
+	"github.com/hoanghai1803/apricot/internal/storage"
+)
+
+// CreateShareLink handles POST /api/reading-list/{id}/share. It makes a
+// public link to the item's title, summary, and notes, for people who
+// don't run Apricot, and returns it with 201 Created. The body is
+// optional; {"include_notes": false} leaves the notes off the page.
+func CreateShareLink(store storage.Store, cfg *config.Config) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		id, err := parseID(r, "id")
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		var body struct {
+			IncludeNotes *bool `json:"include_notes"`
+		}
+		if r.ContentLength != 0 {
+			if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+				writeError(w, http.StatusBadRequest, "Invalid JSON body")
+				return
+			}
+		}
+		includeNotes := body.IncludeNotes == nil || *body.IncludeNotes
+
+		link, err := store.CreateShareLink(ctx, id, newShareToken(), includeNotes)
+		if err != nil {
+			if errors.Is(err, storage.ErrNotFound) {
+				writeError(w, http.StatusNotFound, "Reading list item not found")
+				return
+			}
+			slog.ErrorContext(ctx, "failed to create share link", "id", id, "error", err)
+			writeError(w, http.StatusInternalServerError, "Failed to create share link")
+			return
+		}
+
+		link.URL = shareURL(r, cfg, link.Token)
+		writeJSON(w, http.StatusCreated, link)
+	}
+}
+
+// ListShareLinks handles GET /api/reading-list/{id}/share, returning the
+// item's share links, revoked ones included, newest first.
+func ListShareLinks(store storage.Store, cfg *config.Config) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		id, err := parseID(r, "id")
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		if _, err := store.GetReadingListItemByID(ctx, id); err != nil {
+			if errors.Is(err, storage.ErrNotFound) {
+				writeError(w, http.StatusNotFound, "Reading list item not found")
+				return
+			}
+			slog.ErrorContext(ctx, "failed to get reading list item", "id", id, "error", err)
+			writeError(w, http.StatusInternalServerError, "Failed to list share links")
+			return
+		}
+
+		links, err := store.ListShareLinks(ctx, id)
+		if err != nil {
+			slog.ErrorContext(ctx, "failed to list share links", "id", id, "error", err)
+			writeError(w, http.StatusInternalServerError, "Failed to list share links")
+			return
+		}
+		for i := range links {
+			if links[i].RevokedAt == nil {
+				links[i].URL = shareURL(r, cfg, links[i].Token)
+			}
+		}
+		writeJSON(w, http.StatusOK, links)
+	}
+}
+
+// RevokeShareLink handles DELETE /api/reading-list/{id}/share/{shareID}.
+// The link stops working at once; it stays listed as revoked.
+func RevokeShareLink(store storage.Store) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		id, err := parseID(r, "id")
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		shareID, err := parseID(r, "shareID")
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		if err := store.RevokeShareLink(ctx, id, shareID); err != nil {
+			if errors.Is(err, storage.ErrNotFound) {
+				writeError(w, http.StatusNotFound, "Share link not found")
+				return
+			}
+			slog.ErrorContext(ctx, "failed to revoke share link", "id", id, "share_id", shareID, "error", err)
+			writeError(w, http.StatusInternalServerError, "Failed to revoke share link")
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// SharedPage handles GET /s/{token}, the public page of a share link. It
+// needs no token (see Auth) and shows only the post's title, source,
+// summary, notes if the link includes them, and a link to the original.
+// Unknown and revoked links get the same 404.
+func SharedPage(store storage.Store) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Header().Set("Cache-Control", "no-store")
+		w.Header().Set("X-Robots-Tag", "noindex")
+
+		var page sharedPageData
+		status := http.StatusOK
+		link, err := store.GetShareLink(ctx, chi.URLParam(r, "token"))
+		var item *models.ReadingListItem
+		if err == nil {
+			item, err = store.GetReadingListItemByID(ctx, link.ReadingListID)
+		}
+		switch {
+		case errors.Is(err, storage.ErrNotFound):
+			status = http.StatusNotFound
+		case err != nil:
+			slog.ErrorContext(ctx, "failed to load shared item", "error", err)
+			status = http.StatusInternalServerError
+		default:
+			page = sharedPageFor(item, link.IncludeNotes)
+		}
+
+		w.WriteHeader(status)
+		if err := sharedPageHTML.Execute(w, page); err != nil {
+			slog.ErrorContext(ctx, "failed to render shared page", "error", err)
+		}
+	}
+}
+
+// newShareToken returns 32 random bytes, URL-safe, for a share link.
+func newShareToken() string {
+	b := make([]byte, 32)
+	_, _ = rand.Read(b)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// shareURL returns the public address of the share link with token: under
+// [server] public_url if set, else under the address r came to.
+func shareURL(r *http.Request, cfg *config.Config, token string) string {
+	base := strings.TrimRight(cfg.Server.PublicURL, "/")
+	if base == "" {
+		scheme := "http"
+		if r.TLS != nil {
+			scheme = "https"
+		}
+		base = scheme + "://" + r.Host
+	}
+	return base + "/s/" + token
+}
+
+// sharedPageData is what a shared page shows. An empty Title means the
+// link was not found.
+type sharedPageData struct {
+	Title, URL, Source, Summary, Notes string
+}
+
+// sharedPageFor returns the shared page of item.
+func sharedPageFor(item *models.ReadingListItem, includeNotes bool) sharedPageData {
+	var page sharedPageData
+	if item.Blog != nil {
+		page.Title, page.URL, page.Source = item.Blog.Title, item.Blog.URL, item.Blog.Source
+		if item.Blog.RewrittenTitle != "" {
+			page.Title = item.Blog.RewrittenTitle
+		}
+		page.Summary = item.Blog.Description
+	}
+	if item.Summary != nil && *item.Summary != "" {
+		page.Summary = *item.Summary
+	}
+	if includeNotes && item.Notes != nil {
+		page.Notes = strings.TrimSpace(*item.Notes)
+	}
+	if page.Title == "" {
+		page.Title = page.URL
+	}
+	return page
+}
+
+var sharedPageHTML = template.Must(template.New("shared").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<meta name="robots" content="noindex">
+<title>{{if .Title}}{{.Title}}{{else}}Link not found{{end}} · Apricot</title>
+<style>
+body{margin:0;padding:32px 20px;background:#fafaf9;font-family:-apple-system,'Segoe UI',Helvetica,Arial,sans-serif;color:#1c1917;line-height:1.6}
+main{max-width:640px;margin:0 auto}
+h1{font-size:24px;line-height:1.3;margin:0 0 4px}
+h2{font-size:15px;text-transform:uppercase;letter-spacing:.05em;color:#78716c;margin:32px 0 8px}
+.source{color:#78716c;font-size:14px;margin:0 0 24px}
+.notes{white-space:pre-wrap;background:#fff;border-left:3px solid #ea580c;padding:12px 16px;margin:0}
+a{color:#c2410c}
+footer{margin-top:40px;color:#a8a29e;font-size:12px}
+</style>
+</head>
+<body>
+<main>
+{{if .Title}}<h1>{{.Title}}</h1>
+{{if .Source}}<p class="source">{{.Source}}</p>{{end}}
+{{if .Summary}}<h2>Summary</h2>
+<p>{{.Summary}}</p>{{end}}
+{{if .Notes}}<h2>Notes</h2>
+<p class="notes">{{.Notes}}</p>{{end}}
+<p><a href="{{.URL}}">Read the original post</a></p>
+{{else}}<h1>Link not found</h1>
+<p>This link does not exist or is no longer shared.</p>
+{{end}}<footer>Shared from Apricot.</footer>
+</main>
+</body>
+</html>
+`))
@@ -0,0 +1,91 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/hoanghai1803/apricot/internal/config"
+	"github.com/hoanghai1803/apricot/internal/models"
+)
+
+func TestShareLinks(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+	blogID := seedBlog(t, store)
+	if err := store.AddToReadingList(ctx, blogID); err != nil {
+		t.Fatal(err)
+	}
+	itemID, err := store.GetReadingListIDByBlogID(ctx, blogID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := store.UpdateReadingListNotes(ctx, itemID, "My <private> take"); err != nil {
+		t.Fatal(err)
+	}
+	id := strconv.FormatInt(itemID, 10)
+	cfg := &config.Config{}
+	cfg.Server.PublicURL = "https://apricot.example/"
+
+	share := func(body string) models.ShareLink {
+		t.Helper()
+		r := withURLParams(httptest.NewRequest(http.MethodPost, "/api/reading-list/"+id+"/share", strings.NewReader(body)), "id", id)
+		w := httptest.NewRecorder()
+		CreateShareLink(store, cfg).ServeHTTP(w, r)
+		if w.Code != http.StatusCreated {
+			t.Fatalf("status %d, want %d; body: %s", w.Code, http.StatusCreated, w.Body.String())
+		}
+		var link models.ShareLink
+		if err := json.NewDecoder(w.Body).Decode(&link); err != nil {
+			t.Fatalf("decoding response: %v", err)
+		}
+		return link
+	}
+	view := func(token string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		SharedPage(store).ServeHTTP(w, withURLParams(httptest.NewRequest(http.MethodGet, "/s/"+token, nil), "token", token))
+		return w
+	}
+
+	withNotes := share("")
+	if len(withNotes.Token) < 40 || withNotes.URL != "https://apricot.example/s/"+withNotes.Token || !withNotes.IncludeNotes {
+		t.Errorf("link = %+v, want a long token under the public URL, with notes", withNotes)
+	}
+	w := view(withNotes.Token)
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), "My &lt;private&gt; take") ||
+		!strings.Contains(w.Body.String(), `href="https://example.com/test-post"`) {
+		t.Errorf("shared page: status %d, body %s; want the escaped notes and the original link", w.Code, w.Body.String())
+	}
+
+	withoutNotes := share(`{"include_notes": false}`)
+	if body := view(withoutNotes.Token).Body.String(); strings.Contains(body, "private") {
+		t.Errorf("shared page without notes shows them: %s", body)
+	}
+
+	r := withURLParams(httptest.NewRequest(http.MethodDelete, "/", nil), "id", id, "shareID", strconv.FormatInt(withNotes.ID, 10))
+	w = httptest.NewRecorder()
+	RevokeShareLink(store).ServeHTTP(w, r)
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("revoke: status %d, want %d", w.Code, http.StatusNoContent)
+	}
+	if w = view(withNotes.Token); w.Code != http.StatusNotFound {
+		t.Errorf("revoked link: status %d, want %d", w.Code, http.StatusNotFound)
+	}
+	if w = view("made-up"); w.Code != http.StatusNotFound {
+		t.Errorf("unknown link: status %d, want %d", w.Code, http.StatusNotFound)
+	}
+
+	w = httptest.NewRecorder()
+	ListShareLinks(store, cfg).ServeHTTP(w, withURLParams(httptest.NewRequest(http.MethodGet, "/", nil), "id", id))
+	var links []models.ShareLink
+	if err := json.NewDecoder(w.Body).Decode(&links); err != nil {
+		t.Fatalf("decoding list: %v", err)
+	}
+	if len(links) != 2 || links[0].URL == "" || links[1].RevokedAt == nil || links[1].URL != "" {
+		t.Errorf("links = %+v, want the live link with its URL and the revoked one without", links)
+	}
+}
@@ -10,13 +10,13 @@ import (
 )
 
 // GetSources handles GET /api/sources. It returns all blog sources.
-func GetSources(store *storage.Store) http.HandlerFunc {
+func GetSources(store storage.Store) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		ctx := r.Context()
 
 		sources, err := store.GetAllSources(ctx)
 		if err != nil {
-			slog.Error("failed to get sources", "error", err)
+			slog.ErrorContext(ctx, "failed to get sources", "error", err)
 			writeError(w, http.StatusInternalServerError, "Failed to get sources")
 			return
 		}
@@ -27,7 +27,7 @@ func GetSources(store *storage.Store) http.HandlerFunc {
 
 // ToggleSource handles PUT /api/sources/{id}. It toggles the is_active flag
 // for a blog source.
-func ToggleSource(store *storage.Store) http.HandlerFunc {
+func ToggleSource(store storage.Store) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		ctx := r.Context()
 
@@ -45,16 +45,28 @@ func ToggleSource(store *storage.Store) http.HandlerFunc {
 			return
 		}
 
-		if err := store.ToggleSource(ctx, id, body.IsActive); err != nil {
-			if errors.Is(err, storage.ErrNotFound) {
+		ifVersion, err := parseIfMatch(r)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		version, err := store.ToggleSource(ctx, id, body.IsActive, ifVersion)
+		if err != nil {
+			switch {
+			case errors.Is(err, storage.ErrNotFound):
 				writeError(w, http.StatusNotFound, "Source not found")
-				return
+			case errors.Is(err, storage.ErrVersionConflict):
+				writeError(w, http.StatusPreconditionFailed,
+					"This source was changed in another tab or window. Reload and try again")
+			default:
+				slog.ErrorContext(ctx, "failed to toggle source", "id", id, "error", err)
+				writeError(w, http.StatusInternalServerError, "Failed to toggle source")
 			}
-			slog.Error("failed to toggle source", "id", id, "error", err)
-			writeError(w, http.StatusInternalServerError, "Failed to toggle source")
 			return
 		}
 
-		writeJSON(w, http.StatusOK, map[string]string{"status": "updated"})
+		setETag(w, version)
+		writeJSON(w, http.StatusOK, map[string]any{"status": "updated", "version": version})
 	}
 }
@@ -6,6 +6,7 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"strconv"
 	"testing"
 
 	"github.com/go-chi/chi/v5"
@@ -87,6 +88,33 @@ func TestToggleSource(t *testing.T) {
 		}
 	})
 
+	t.Run("stale if-match", func(t *testing.T) {
+		sources, err := store.GetActiveSources(context.Background())
+		if err != nil || len(sources) == 0 {
+			t.Fatalf("GetActiveSources: %v (%d sources)", err, len(sources))
+		}
+		id := strconv.FormatInt(sources[0].ID, 10)
+		toggle := func(body string) *httptest.ResponseRecorder {
+			r := httptest.NewRequest(http.MethodPut, "/api/sources/"+id, bytes.NewBufferString(body))
+			r.Header.Set("If-Match", `"1"`)
+			w := httptest.NewRecorder()
+
+			rctx := chi.NewRouteContext()
+			rctx.URLParams.Add("id", id)
+			r = r.WithContext(context.WithValue(r.Context(), chi.RouteCtxKey, rctx))
+
+			ToggleSource(store).ServeHTTP(w, r)
+			return w
+		}
+
+		if w := toggle(`{"is_active": false}`); w.Code != http.StatusOK || w.Header().Get("ETag") != `"2"` {
+			t.Fatalf("first toggle: got status %d, ETag %q", w.Code, w.Header().Get("ETag"))
+		}
+		if w := toggle(`{"is_active": true}`); w.Code != http.StatusPreconditionFailed {
+			t.Fatalf("stale toggle: got status %d, want %d", w.Code, http.StatusPreconditionFailed)
+		}
+	})
+
 	t.Run("reactivate source", func(t *testing.T) {
 		body := `{"is_active": true}`
 		r := httptest.NewRequest(http.MethodPut, "/api/sources/1", bytes.NewBufferString(body))
@@ -0,0 +1,185 @@
+package handlers
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/hoanghai1803/apricot/internal/feeds"
+	"github.com/hoanghai1803/apricot/internal/jobs"
+	"github.com/hoanghai1803/apricot/internal/storage"
+)
+
+const (
+	// minSuggestionPosts is how many posts from a site must have been added
+	// to the reading list by URL for the site to be suggested as a source.
+	minSuggestionPosts = 2
+
+	// maxSuggestions bounds how many sites a "source_suggestions" job looks
+	// for feeds on, the sites with the most posts first.
+	maxSuggestions = 10
+
+	// maxSuggestionExamples is how many post titles a suggestion lists.
+	maxSuggestionExamples = 3
+)
+
+// SourceSuggestion is a site the user keeps adding posts from by URL, with
+// its feed: a source to subscribe to. Posts counts its posts on the reading
+// list, Read those read, and Examples holds a few of their titles.
+type SourceSuggestion struct {
+	SiteURL  string   `json:"site_url"`
+	FeedURL  string   `json:"feed_url"`
+	Title    string   `json:"title"`
+	Posts    int      `json:"posts"`
+	Read     int      `json:"read"`
+	Examples []string `json:"examples"`
+}
+
+// SourceSuggestionsResult is the result of a "source_suggestions" job.
+// NoFeed lists the sites that would have been suggested but have no feed
+// that could be found.
+type SourceSuggestionsResult struct {
+	Suggestions []SourceSuggestion `json:"suggestions"`
+	NoFeed      []string           `json:"no_feed,omitempty"`
+}
+
+// SuggestSources handles POST /api/sources/suggestions. It queues a
+// "source_suggestions" job (see SourceSuggestionsJob) and returns 202
+// Accepted with the job, whose result lists the suggestions.
+func SuggestSources(runner *jobs.Manager) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		job, err := runner.Enqueue(r.Context(), "source_suggestions", struct{}{})
+		if err != nil {
+			slog.ErrorContext(r.Context(), "failed to queue source suggestions", "error", err)
+			writeError(w, http.StatusInternalServerError, "Failed to start source suggestions")
+			return
+		}
+
+		w.Header().Set("Location", fmt.Sprintf("/api/jobs/%d", job.ID))
+		writeJSON(w, http.StatusAccepted, job)
+	}
+}
+
+// SourceSuggestionsJob returns the "source_suggestions" job kind. It looks
+// for sites whose posts the user has added to the reading list by URL at
+// least minSuggestionPosts times and that no source covers, and suggests
+// those with a feed it can find. Queued by SuggestSources and on the
+// [feeds] suggest_schedule.
+func SourceSuggestionsJob(store storage.Store, fetcher *feeds.Fetcher) jobs.Kind {
+	return jobs.Kind{
+		Name:        "source_suggestions",
+		Timeout:     10 * time.Minute, // a few feed lookups per site, a second apart
+		MaxAttempts: 2,
+		Handler: func(ctx context.Context, _ json.RawMessage) (any, error) {
+			result, err := suggestSources(ctx, store, fetcher)
+			if err != nil {
+				return nil, err
+			}
+			slog.InfoContext(ctx, "looked for source suggestions",
+				"suggestions", len(result.Suggestions), "no_feed", len(result.NoFeed))
+			return result, nil
+		},
+	}
+}
+
+// suggestSources finds the sources to suggest; see SourceSuggestionsJob.
+// Sites are told apart by host name, without any "www.", and a source
+// covers a site if its feed or site URL is on the same host.
+func suggestSources(ctx context.Context, store storage.Store, fetcher *feeds.Fetcher) (SourceSuggestionsResult, error) {
+	result := SourceSuggestionsResult{Suggestions: []SourceSuggestion{}}
+
+	customID, err := store.GetCustomSourceID(ctx)
+	if err != nil {
+		return result, err
+	}
+	sources, err := store.GetAllSources(ctx)
+	if err != nil {
+		return result, fmt.Errorf("loading sources: %w", err)
+	}
+	covered := make(map[string]bool)
+	feedURLs := make(map[string]bool)
+	for _, src := range sources {
+		covered[siteHost(src.FeedURL)] = true
+		covered[siteHost(src.SiteURL)] = true
+		feedURLs[src.FeedURL] = true
+	}
+
+	items, err := store.GetReadingListFiltered(ctx, storage.ReadingListFilter{WithoutContent: true})
+	if err != nil {
+		return result, fmt.Errorf("loading reading list: %w", err)
+	}
+	sites := make(map[string]*SourceSuggestion)
+	for _, item := range items {
+		if item.Blog == nil || item.Blog.SourceID != customID {
+			continue
+		}
+		host := siteHost(item.Blog.URL)
+		if host == "" || covered[host] {
+			continue
+		}
+		s, ok := sites[host]
+		if !ok {
+			u, _ := url.Parse(item.Blog.URL) // parsed by siteHost
+			s = &SourceSuggestion{SiteURL: u.Scheme + "://" + u.Host + "/", Title: item.Blog.Source}
+			sites[host] = s
+		}
+		s.Posts++
+		if item.Status == "read" || item.Status == "archived" {
+			s.Read++
+		}
+		if len(s.Examples) < maxSuggestionExamples {
+			s.Examples = append(s.Examples, item.Blog.Title)
+		}
+	}
+
+	var candidates []*SourceSuggestion
+	for _, s := range sites {
+		if s.Posts >= minSuggestionPosts {
+			candidates = append(candidates, s)
+		}
+	}
+	slices.SortFunc(candidates, func(a, b *SourceSuggestion) int {
+		return cmp.Or(cmp.Compare(b.Posts, a.Posts), cmp.Compare(b.Read, a.Read), cmp.Compare(a.SiteURL, b.SiteURL))
+	})
+
+	for _, s := range candidates[:min(maxSuggestions, len(candidates))] {
+		feed, err := fetcher.FindFeed(ctx, s.SiteURL)
+		if err != nil {
+			if ctx.Err() != nil {
+				return result, ctx.Err()
+			}
+			if !errors.Is(err, feeds.ErrNoFeed) {
+				slog.WarnContext(ctx, "failed to look for a feed", "site", s.SiteURL, "error", err)
+			}
+			result.NoFeed = append(result.NoFeed, s.SiteURL)
+			continue
+		}
+		if feedURLs[feed.URL] {
+			continue
+		}
+		s.FeedURL = feed.URL
+		if feed.Title != "" {
+			s.Title = feed.Title
+		}
+		result.Suggestions = append(result.Suggestions, *s)
+	}
+	return result, nil
+}
+
+// siteHost returns the lowercase host name of rawURL without any "www.",
+// or "" if it has none.
+func siteHost(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.")
+}
@@ -0,0 +1,110 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+
+	"github.com/hoanghai1803/apricot/internal/feeds"
+	"github.com/hoanghai1803/apricot/internal/jobs"
+	"github.com/hoanghai1803/apricot/internal/models"
+)
+
+func TestSuggestSources(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/":
+			fmt.Fprint(w, `<html><head><link rel="alternate" type="application/rss+xml" href="/rss"></head></html>`)
+		case "/rss":
+			fmt.Fprint(w, `<?xml version="1.0"?><rss version="2.0"><channel><title>Small Blog</title></channel></rss>`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	sources, err := store.GetAllSources(ctx)
+	if err != nil || len(sources) == 0 {
+		t.Fatalf("GetAllSources() = %d sources, %v", len(sources), err)
+	}
+	add := func(url, title, status string) {
+		t.Helper()
+		id, err := store.CreateCustomBlog(ctx, url, title, "", "", "")
+		if err != nil {
+			t.Fatalf("CreateCustomBlog(%s): %v", url, err)
+		}
+		if err := store.AddToReadingList(ctx, id); err != nil {
+			t.Fatalf("AddToReadingList: %v", err)
+		}
+		if status != "" {
+			itemID, err := store.GetReadingListIDByBlogID(ctx, id)
+			if err != nil {
+				t.Fatalf("GetReadingListIDByBlogID: %v", err)
+			}
+			if err := store.UpdateReadingListStatus(ctx, itemID, status); err != nil {
+				t.Fatalf("UpdateReadingListStatus: %v", err)
+			}
+		}
+	}
+	add(srv.URL+"/posts/one", "One", "read")
+	add(srv.URL+"/posts/two", "Two", "")
+	// A site a source covers, and one saved from only once.
+	add(sources[0].SiteURL+"/saved-1", "Covered 1", "")
+	add(sources[0].SiteURL+"/saved-2", "Covered 2", "")
+	add("https://once.example/post", "Once", "")
+
+	runner := jobs.NewManager(store, 1)
+	runner.Register(SourceSuggestionsJob(store, feeds.NewFetcher(nil)))
+
+	w := httptest.NewRecorder()
+	SuggestSources(runner).ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/sources/suggestions", nil))
+	if w.Code != http.StatusAccepted {
+		t.Fatalf("status %d, want %d; body: %s", w.Code, http.StatusAccepted, w.Body.String())
+	}
+	var job models.Job
+	if err := json.NewDecoder(w.Body).Decode(&job); err != nil {
+		t.Fatalf("decoding response: %v", err)
+	}
+	if err := runner.Drain(ctx); err != nil {
+		t.Fatalf("Drain() error: %v", err)
+	}
+	done, err := runner.Get(ctx, job.ID)
+	if err != nil || done.Status != models.JobSucceeded {
+		t.Fatalf("source_suggestions job = %+v, %v; want it to have succeeded", done, err)
+	}
+	var result SourceSuggestionsResult
+	if err := json.Unmarshal(done.Result, &result); err != nil {
+		t.Fatalf("decoding result: %v", err)
+	}
+
+	if len(result.Suggestions) != 1 || len(result.NoFeed) != 0 {
+		t.Fatalf("result = %+v, want one suggestion", result)
+	}
+	s := result.Suggestions[0]
+	if s.SiteURL != srv.URL+"/" || s.FeedURL != srv.URL+"/rss" || s.Title != "Small Blog" || s.Posts != 2 || s.Read != 1 {
+		t.Errorf("suggestion = %+v, want Small Blog's feed with 2 posts, 1 read", s)
+	}
+	slices.Sort(s.Examples)
+	if !slices.Equal(s.Examples, []string{"One", "Two"}) {
+		t.Errorf("Examples = %v, want One and Two", s.Examples)
+	}
+}
+
+func TestSiteHost(t *testing.T) {
+	for in, want := range map[string]string{
+		"https://www.Example.com/post": "example.com",
+		"http://blog.example.com:8080": "blog.example.com",
+		"not a url\x7f":                "",
+	} {
+		if got := siteHost(in); got != want {
+			t.Errorf("siteHost(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
@@ -3,8 +3,11 @@ package handlers
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
 	"log/slog"
 	"net/http"
+	"regexp"
+	"strings"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/hoanghai1803/apricot/internal/storage"
@@ -12,7 +15,7 @@ import (
 
 // AddTagToItem handles POST /api/reading-list/{id}/tags. It adds a tag to a
 // reading list item. The tag is created if it doesn't exist.
-func AddTagToItem(store *storage.Store) http.HandlerFunc {
+func AddTagToItem(store storage.Store) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		ctx := r.Context()
 
@@ -40,7 +43,7 @@ func AddTagToItem(store *storage.Store) http.HandlerFunc {
 				writeError(w, http.StatusNotFound, "Reading list item not found")
 				return
 			}
-			slog.Error("failed to add tag", "id", id, "tag", body.Tag, "error", err)
+			slog.ErrorContext(ctx, "failed to add tag", "id", id, "tag", body.Tag, "error", err)
 			writeError(w, http.StatusInternalServerError, "Failed to add tag")
 			return
 		}
@@ -51,7 +54,7 @@ func AddTagToItem(store *storage.Store) http.HandlerFunc {
 
 // RemoveTagFromItem handles DELETE /api/reading-list/{id}/tags/{tag}. It
 // removes a tag from a reading list item and cleans up unused tags.
-func RemoveTagFromItem(store *storage.Store) http.HandlerFunc {
+func RemoveTagFromItem(store storage.Store) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		ctx := r.Context()
 
@@ -72,7 +75,7 @@ func RemoveTagFromItem(store *storage.Store) http.HandlerFunc {
 				writeError(w, http.StatusNotFound, "Tag not found on this item")
 				return
 			}
-			slog.Error("failed to remove tag", "id", id, "tag", tag, "error", err)
+			slog.ErrorContext(ctx, "failed to remove tag", "id", id, "tag", tag, "error", err)
 			writeError(w, http.StatusInternalServerError, "Failed to remove tag")
 			return
 		}
@@ -81,15 +84,16 @@ func RemoveTagFromItem(store *storage.Store) http.HandlerFunc {
 	}
 }
 
-// GetAllTags handles GET /api/tags. It returns all distinct tag names for
-// autocomplete.
-func GetAllTags(store *storage.Store) http.HandlerFunc {
+// GetAllTags handles GET /api/tags. It returns every tag with its color,
+// description, and number of tagged items, for autocomplete and tag
+// management.
+func GetAllTags(store storage.Store) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		ctx := r.Context()
 
 		tags, err := store.GetAllTags(ctx)
 		if err != nil {
-			slog.Error("failed to get tags", "error", err)
+			slog.ErrorContext(ctx, "failed to get tags", "error", err)
 			writeError(w, http.StatusInternalServerError, "Failed to get tags")
 			return
 		}
@@ -97,3 +101,51 @@ func GetAllTags(store *storage.Store) http.HandlerFunc {
 		writeJSON(w, http.StatusOK, tags)
 	}
 }
+
+// tagColorPattern matches the CSS hex colors accepted for tags.
+var tagColorPattern = regexp.MustCompile(`^#(?:[0-9a-fA-F]{3}|[0-9a-fA-F]{6})$`)
+
+// maxTagDescription is the longest tag description accepted, in bytes.
+const maxTagDescription = 500
+
+// UpdateTag handles PUT /api/tags/{tag}. It sets the tag's color (a CSS hex
+// color such as "#f59e0b") and description; empty values clear them. The
+// tag is created if it doesn't exist, so it can be set up before use.
+func UpdateTag(store storage.Store) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		tag := chi.URLParam(r, "tag")
+		if strings.TrimSpace(tag) == "" {
+			writeError(w, http.StatusBadRequest, "tag parameter is required")
+			return
+		}
+
+		var body struct {
+			Color       string `json:"color"`
+			Description string `json:"description"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			writeError(w, http.StatusBadRequest, "Invalid JSON body")
+			return
+		}
+
+		if body.Color != "" && !tagColorPattern.MatchString(body.Color) {
+			writeError(w, http.StatusBadRequest, "color must be a hex color such as #f59e0b")
+			return
+		}
+		body.Description = strings.TrimSpace(body.Description)
+		if len(body.Description) > maxTagDescription {
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("description must be at most %d characters", maxTagDescription))
+			return
+		}
+
+		if err := store.UpdateTag(ctx, tag, strings.ToLower(body.Color), body.Description); err != nil {
+			slog.ErrorContext(ctx, "failed to update tag", "tag", tag, "error", err)
+			writeError(w, http.StatusInternalServerError, "Failed to update tag")
+			return
+		}
+
+		writeJSON(w, http.StatusOK, map[string]string{"status": "updated"})
+	}
+}
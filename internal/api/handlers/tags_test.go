@@ -0,0 +1,102 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/hoanghai1803/apricot/internal/models"
+	"github.com/hoanghai1803/apricot/internal/storage"
+)
+
+// tagStoreStub serves tags from memory. Any other store method panics on
+// the nil embedded Store, failing the test.
+type tagStoreStub struct {
+	storage.Store
+	tags []models.Tag
+	err  error
+}
+
+func (s *tagStoreStub) GetAllTags(context.Context) ([]models.Tag, error) {
+	return s.tags, s.err
+}
+
+func TestGetAllTags_Stub(t *testing.T) {
+	tests := []struct {
+		name       string
+		store      *tagStoreStub
+		wantStatus int
+		wantTags   int
+	}{
+		{"tags", &tagStoreStub{tags: []models.Tag{{Name: "go"}, {Name: "rust"}}}, http.StatusOK, 2},
+		{"store error", &tagStoreStub{err: errors.New("disk full")}, http.StatusInternalServerError, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/api/tags", nil)
+			w := httptest.NewRecorder()
+			GetAllTags(tt.store).ServeHTTP(w, r)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status %d, want %d; body: %s", w.Code, tt.wantStatus, w.Body.String())
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+			var tags []models.Tag
+			if err := json.NewDecoder(w.Body).Decode(&tags); err != nil {
+				t.Fatalf("decoding response: %v", err)
+			}
+			if len(tags) != tt.wantTags {
+				t.Errorf("got %d tags, want %d", len(tags), tt.wantTags)
+			}
+		})
+	}
+}
+
+func TestUpdateTagAndGetAllTags(t *testing.T) {
+	store := newTestStore(t)
+
+	tests := []struct {
+		body       string
+		wantStatus int
+	}{
+		{`{"color": "orange"}`, http.StatusBadRequest},
+		{`{"color": "#F59E0B", "description": " Storage engines "}`, http.StatusOK},
+	}
+	for _, tt := range tests {
+		r := httptest.NewRequest(http.MethodPut, "/api/tags/databases", bytes.NewBufferString(tt.body))
+		rctx := chi.NewRouteContext()
+		rctx.URLParams.Add("tag", "databases")
+		r = r.WithContext(context.WithValue(r.Context(), chi.RouteCtxKey, rctx))
+		w := httptest.NewRecorder()
+
+		UpdateTag(store).ServeHTTP(w, r)
+
+		if w.Code != tt.wantStatus {
+			t.Errorf("%s: got status %d, want %d; body: %s", tt.body, w.Code, tt.wantStatus, w.Body.String())
+		}
+	}
+
+	r := httptest.NewRequest(http.MethodGet, "/api/tags", nil)
+	w := httptest.NewRecorder()
+	GetAllTags(store).ServeHTTP(w, r)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("GET got status %d, want %d", w.Code, http.StatusOK)
+	}
+	var tags []models.Tag
+	if err := json.NewDecoder(w.Body).Decode(&tags); err != nil {
+		t.Fatalf("decoding response: %v", err)
+	}
+	want := models.Tag{Name: "databases", Color: "#f59e0b", Description: "Storage engines"}
+	if len(tags) != 1 || tags[0] != want {
+		t.Errorf("tags = %+v, want [%+v]", tags, want)
+	}
+}
@@ -2,15 +2,17 @@ package handlers
 
 import (
 	"context"
+	"net/http"
 	"testing"
 
+	"github.com/go-chi/chi/v5"
 	"github.com/hoanghai1803/apricot/internal/storage"
 )
 
 // newTestStore creates an in-memory SQLite store with migrations applied and
 // default sources seeded. It registers a cleanup function to close the database
 // when the test completes.
-func newTestStore(t *testing.T) *storage.Store {
+func newTestStore(t *testing.T) *storage.SQLiteStore {
 	t.Helper()
 
 	db, err := storage.OpenDatabase(":memory:")
@@ -23,10 +25,20 @@ func newTestStore(t *testing.T) *storage.Store {
 		t.Fatalf("running migrations: %v", err)
 	}
 
-	store := storage.NewStore(db)
+	store := storage.NewSQLiteStore(db)
 	if err := store.SeedDefaults(context.Background()); err != nil {
 		t.Fatalf("seeding defaults: %v", err)
 	}
 
 	return store
 }
+
+// withURLParams returns a copy of r whose chi route context carries the given
+// key/value URL parameters, as if the request had been routed by chi.
+func withURLParams(r *http.Request, kv ...string) *http.Request {
+	rctx := chi.NewRouteContext()
+	for i := 0; i+1 < len(kv); i += 2 {
+		rctx.URLParams.Add(kv[i], kv[i+1])
+	}
+	return r.WithContext(context.WithValue(r.Context(), chi.RouteCtxKey, rctx))
+}
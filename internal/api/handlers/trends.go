@@ -0,0 +1,224 @@
+package handlers
+
+import (
+	"cmp"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+	"unicode"
+
+	"github.com/hoanghai1803/apricot/internal/models"
+	"github.com/hoanghai1803/apricot/internal/storage"
+)
+
+const (
+	// defaultTrendWeeks and maxTrendWeeks bound the "weeks" parameter of
+	// GetTrends.
+	defaultTrendWeeks = 8
+	maxTrendWeeks     = 52
+
+	// defaultTrendLimit and maxTrendLimit bound the "limit" parameter.
+	defaultTrendLimit = 10
+	maxTrendLimit     = 50
+
+	// minTrendSources is how many sources must mention a word for it to be
+	// a topic. It keeps out company and product names, which a company's
+	// blog repeats in every post.
+	minTrendSources = 2
+)
+
+// trendStopWords are left out of trending topics on top of stopWords: the
+// filler that blog titles and descriptions share whatever they are about.
+var trendStopWords = map[string]bool{
+	"about": true, "across": true, "after": true, "all": true, "also": true,
+	"announcing": true, "any": true, "article": true, "back": true, "been": true,
+	"before": true, "being": true, "best": true, "better": true, "between": true,
+	"blog": true, "build": true, "building": true, "built": true, "but": true,
+	"can": true, "could": true, "did": true, "do": true, "does": true,
+	"don": true, "each": true, "every": true, "first": true, "get": true,
+	"getting": true, "has": true, "have": true, "help": true, "her": true,
+	"here": true, "his": true, "if": true, "introducing": true, "its": true,
+	"just": true, "learn": true, "learned": true, "lessons": true, "like": true,
+	"ll": true, "make": true, "making": true, "many": true, "more": true,
+	"most": true, "my": true, "new": true, "no": true, "not": true,
+	"now": true, "one": true, "only": true, "other": true, "our": true,
+	"out": true, "over": true, "part": true, "post": true, "re": true,
+	"read": true, "really": true, "so": true, "some": true, "team": true,
+	"teams": true, "than": true, "that": true, "their": true, "them": true,
+	"then": true, "there": true, "these": true, "they": true, "things": true,
+	"this": true, "those": true, "through": true, "time": true, "today": true,
+	"two": true, "up": true, "us": true, "use": true, "used": true,
+	"using": true, "ve": true, "was": true, "way": true, "ways": true,
+	"we": true, "week": true, "were": true, "what": true, "when": true,
+	"where": true, "which": true, "while": true, "who": true, "why": true,
+	"will": true, "work": true, "would": true, "year": true, "you": true,
+	"your": true,
+}
+
+// TrendTopic is a word that came up in posts from several sources, with the
+// number of posts and of sources mentioning it.
+type TrendTopic struct {
+	Topic   string `json:"topic"`
+	Posts   int    `json:"posts"`
+	Sources int    `json:"sources"`
+}
+
+// TrendWeek is the trending topics of one week, starting on WeekStart, a
+// Monday, among the Posts published that week.
+type TrendWeek struct {
+	WeekStart string       `json:"week_start"`
+	Posts     int          `json:"posts"`
+	Topics    []TrendTopic `json:"topics"`
+}
+
+// TrendsResponse is the body of GetTrends: every week of the window, newest
+// first, and the topics of the whole window.
+type TrendsResponse struct {
+	Weeks   []TrendWeek  `json:"weeks"`
+	Overall []TrendTopic `json:"overall"`
+}
+
+// GetTrends handles GET /api/stats/trends. It reports the topics that come
+// up most across the posts fetched from all sources, active or not and
+// whatever the user's preferences, week by week. Topics are the words of
+// the posts' titles and descriptions, less filler, that appear in posts
+// from at least minTrendSources sources. Posts are counted in the week
+// they were published, or fetched if undated. The optional "weeks" (1-52,
+// default 8) and "limit" (1-50, default 10) parameters set how many weeks
+// are covered, the current one included, and how many topics each lists.
+func GetTrends(store storage.Store) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		q := r.URL.Query()
+
+		weeks, err := boundedParam(q.Get("weeks"), defaultTrendWeeks, maxTrendWeeks)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "weeks must be an integer between 1 and 52")
+			return
+		}
+		limit, err := boundedParam(q.Get("limit"), defaultTrendLimit, maxTrendLimit)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "limit must be an integer between 1 and 50")
+			return
+		}
+
+		start := weekStart(time.Now()).AddDate(0, 0, -7*(weeks-1))
+		blogs, err := store.GetBlogsFetchedSince(ctx, start)
+		if err != nil {
+			slog.ErrorContext(ctx, "failed to load fetched blogs for trends", "error", err)
+			writeError(w, http.StatusInternalServerError, "Failed to get trends")
+			return
+		}
+
+		writeJSON(w, http.StatusOK, computeTrends(blogs, start, weeks, limit))
+	}
+}
+
+// boundedParam parses the query parameter v, an integer between 1 and
+// maxValue, defaulting to def when empty.
+func boundedParam(v string, def, maxValue int) (int, error) {
+	if v == "" {
+		return def, nil
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		return 0, err
+	}
+	if n < 1 || n > maxValue {
+		return 0, strconv.ErrRange
+	}
+	return n, nil
+}
+
+// weekStart returns midnight UTC on the Monday of t's week.
+func weekStart(t time.Time) time.Time {
+	t = t.UTC()
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	return day.AddDate(0, 0, -((int(day.Weekday()) + 6) % 7))
+}
+
+// computeTrends buckets blogs into the weeks weeks from start and ranks the
+// topics of each week and of them all, keeping the top limit. Posts
+// published before start, as when a new feed's back catalogue is fetched,
+// are left out.
+func computeTrends(blogs []models.Blog, start time.Time, weeks, limit int) TrendsResponse {
+	type counts struct {
+		posts   map[string]int
+		sources map[string]map[int64]bool
+		total   int
+	}
+	newCounts := func() *counts {
+		return &counts{posts: make(map[string]int), sources: make(map[string]map[int64]bool)}
+	}
+	add := func(c *counts, topics []string, sourceID int64) {
+		c.total++
+		for _, t := range topics {
+			c.posts[t]++
+			if c.sources[t] == nil {
+				c.sources[t] = make(map[int64]bool)
+			}
+			c.sources[t][sourceID] = true
+		}
+	}
+
+	overall := newCounts()
+	byWeek := make([]*counts, weeks)
+	for i := range byWeek {
+		byWeek[i] = newCounts()
+	}
+	for _, b := range blogs {
+		at := b.FetchedAt
+		if b.PublishedAt != nil && b.PublishedAt.Before(at) {
+			at = *b.PublishedAt
+		}
+		week := int(weekStart(at).Sub(start) / (7 * 24 * time.Hour))
+		if at.Before(start) || week >= weeks {
+			continue
+		}
+		topics := postTopics(b)
+		add(byWeek[week], topics, b.SourceID)
+		add(overall, topics, b.SourceID)
+	}
+
+	rank := func(c *counts) []TrendTopic {
+		topics := []TrendTopic{}
+		for t, n := range c.posts {
+			if len(c.sources[t]) >= minTrendSources {
+				topics = append(topics, TrendTopic{Topic: t, Posts: n, Sources: len(c.sources[t])})
+			}
+		}
+		slices.SortFunc(topics, func(a, b TrendTopic) int {
+			return cmp.Or(cmp.Compare(b.Posts, a.Posts), cmp.Compare(b.Sources, a.Sources), cmp.Compare(a.Topic, b.Topic))
+		})
+		return topics[:min(limit, len(topics))]
+	}
+
+	resp := TrendsResponse{Weeks: make([]TrendWeek, 0, weeks), Overall: rank(overall)}
+	for i := weeks - 1; i >= 0; i-- {
+		resp.Weeks = append(resp.Weeks, TrendWeek{
+			WeekStart: start.AddDate(0, 0, 7*i).Format("2006-01-02"),
+			Posts:     byWeek[i].total,
+			Topics:    rank(byWeek[i]),
+		})
+	}
+	return resp
+}
+
+// postTopics returns the distinct candidate topics of b's title and
+// description: words of two or more characters that are not all digits
+// nor filler.
+func postTopics(b models.Blog) []string {
+	var topics []string
+	for _, w := range words(b.Title + " " + b.Description) {
+		if len([]rune(w)) < 2 || stopWords[w] || trendStopWords[w] || slices.Contains(topics, w) {
+			continue
+		}
+		if !slices.ContainsFunc([]rune(w), unicode.IsLetter) {
+			continue
+		}
+		topics = append(topics, w)
+	}
+	return topics
+}
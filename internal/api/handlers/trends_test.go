@@ -0,0 +1,134 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/hoanghai1803/apricot/internal/models"
+)
+
+func TestWeekStart(t *testing.T) {
+	tests := []struct {
+		in   time.Time
+		want string
+	}{
+		{time.Date(2026, 10, 12, 0, 0, 0, 0, time.UTC), "2026-10-12"},   // Monday
+		{time.Date(2026, 10, 16, 15, 4, 0, 0, time.UTC), "2026-10-12"},  // Friday
+		{time.Date(2026, 10, 18, 23, 59, 0, 0, time.UTC), "2026-10-12"}, // Sunday
+	}
+	for _, tt := range tests {
+		if got := weekStart(tt.in).Format("2006-01-02"); got != tt.want {
+			t.Errorf("weekStart(%v) = %s, want %s", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestComputeTrends(t *testing.T) {
+	start := time.Date(2026, 10, 5, 0, 0, 0, 0, time.UTC)
+	at := func(days int) *time.Time {
+		v := start.AddDate(0, 0, days)
+		return &v
+	}
+	fetched := start.AddDate(0, 0, 10)
+	blogs := []models.Blog{
+		// This week.
+		{SourceID: 1, Title: "Scaling Postgres at Acme", PublishedAt: at(8), FetchedAt: fetched},
+		{SourceID: 2, Title: "How we tuned Postgres", Description: "Postgres vacuum, in depth", PublishedAt: at(9), FetchedAt: fetched},
+		{SourceID: 1, Title: "Acme's Rust services", PublishedAt: at(9), FetchedAt: fetched},
+		// Last week, and undated posts in the week they were fetched.
+		{SourceID: 3, Title: "Rust in 2026", PublishedAt: at(1), FetchedAt: fetched},
+		{SourceID: 1, Title: "Rewriting in Rust", PublishedAt: at(2), FetchedAt: fetched},
+		{SourceID: 4, Title: "Postgres upgrades", FetchedAt: fetched},
+		// Before the window.
+		{SourceID: 5, Title: "Rust, years ago", PublishedAt: at(-30), FetchedAt: fetched},
+	}
+
+	got := computeTrends(blogs, start, 2, 10)
+
+	topics := func(list []TrendTopic) []string {
+		var names []string
+		for _, t := range list {
+			names = append(names, t.Topic)
+		}
+		return names
+	}
+	if len(got.Weeks) != 2 {
+		t.Fatalf("got %d weeks, want 2", len(got.Weeks))
+	}
+	this, last := got.Weeks[0], got.Weeks[1]
+	if this.WeekStart != "2026-10-12" || this.Posts != 4 {
+		t.Errorf("this week = %s with %d posts, want 2026-10-12 with 4", this.WeekStart, this.Posts)
+	}
+	// "acme" is only ever mentioned by one source, and "2026" is a number.
+	if want := []string{"postgres"}; !slices.Equal(topics(this.Topics), want) {
+		t.Errorf("this week's topics = %v, want %v", topics(this.Topics), want)
+	}
+	if this.Topics[0].Posts != 3 || this.Topics[0].Sources != 3 {
+		t.Errorf("postgres = %+v, want 3 posts from 3 sources", this.Topics[0])
+	}
+	if last.WeekStart != "2026-10-05" || last.Posts != 2 {
+		t.Errorf("last week = %s with %d posts, want 2026-10-05 with 2", last.WeekStart, last.Posts)
+	}
+	if want := []string{"rust"}; !slices.Equal(topics(last.Topics), want) {
+		t.Errorf("last week's topics = %v, want %v", topics(last.Topics), want)
+	}
+	if want := []string{"postgres", "rust"}; !slices.Equal(topics(got.Overall), want) {
+		t.Errorf("overall topics = %v, want %v", topics(got.Overall), want)
+	}
+
+	if got := computeTrends(blogs, start, 2, 1); len(got.Overall) != 1 {
+		t.Errorf("limit 1 gave %d overall topics, want 1", len(got.Overall))
+	}
+}
+
+func TestGetTrends(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+	now := time.Now()
+	sources, err := store.GetActiveSources(ctx)
+	if err != nil || len(sources) < 2 {
+		t.Fatalf("GetActiveSources() = %d sources, %v; want at least 2", len(sources), err)
+	}
+	if err := store.SaveBlogs(ctx, []models.Blog{
+		{SourceID: sources[0].ID, Title: "Kubernetes autoscaling", URL: "https://a.example/k8s", PublishedAt: &now, FetchedAt: now},
+		{SourceID: sources[1].ID, Title: "Our Kubernetes journey", URL: "https://b.example/k8s", PublishedAt: &now, FetchedAt: now},
+	}); err != nil {
+		t.Fatalf("SaveBlogs() error: %v", err)
+	}
+
+	handler := GetTrends(store)
+	for _, query := range []string{"?weeks=0", "?weeks=53", "?limit=x", "?limit=51"} {
+		w := httptest.NewRecorder()
+		handler(w, httptest.NewRequest(http.MethodGet, "/api/stats/trends"+query, nil))
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("%s status = %d, want 400", query, w.Code)
+		}
+	}
+
+	w := httptest.NewRecorder()
+	handler(w, httptest.NewRequest(http.MethodGet, "/api/stats/trends?weeks=4", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %s", w.Code, w.Body.String())
+	}
+	var resp TrendsResponse
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("decoding response: %v", err)
+	}
+	if len(resp.Weeks) != 4 {
+		t.Fatalf("got %d weeks, want 4", len(resp.Weeks))
+	}
+	if resp.Weeks[0].WeekStart != weekStart(now).Format("2006-01-02") || resp.Weeks[0].Posts != 2 {
+		t.Errorf("first week = %+v, want this week with 2 posts", resp.Weeks[0])
+	}
+	if len(resp.Overall) != 1 || resp.Overall[0].Topic != "kubernetes" {
+		t.Errorf("overall = %+v, want kubernetes", resp.Overall)
+	}
+	if resp.Weeks[3].Topics == nil {
+		t.Error("empty week topics = null, want []")
+	}
+}
@@ -0,0 +1,101 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/hoanghai1803/apricot/internal/config"
+	"github.com/hoanghai1803/apricot/internal/jobs"
+	"github.com/hoanghai1803/apricot/internal/storage"
+	"github.com/hoanghai1803/apricot/internal/vault"
+)
+
+// ExportVault handles POST /api/admin/vault. It queues a "vault" job (see
+// VaultJob) and returns 202 Accepted with the job.
+func ExportVault(runner *jobs.Manager, cfg *config.Config) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !cfg.Vault.Enabled() {
+			writeError(w, http.StatusServiceUnavailable, "Vault export is not configured. Add a [vault] section to config.toml")
+			return
+		}
+
+		job, err := runner.Enqueue(r.Context(), "vault", struct{}{})
+		if err != nil {
+			slog.ErrorContext(r.Context(), "failed to queue vault export", "error", err)
+			writeError(w, http.StatusInternalServerError, "Failed to start vault export")
+			return
+		}
+
+		w.Header().Set("Location", fmt.Sprintf("/api/jobs/%d", job.ID))
+		writeJSON(w, http.StatusAccepted, job)
+	}
+}
+
+// VaultJob returns the "vault" job kind, which writes every read or
+// archived reading list item to the vault directory as a Markdown note
+// (see vault.Export) and returns a vault.Result. Notes edited by hand since
+// the last export are left alone. A failed export is retried twice.
+func VaultJob(store storage.Store, cfg *config.Config) jobs.Kind {
+	return jobs.Kind{
+		Name:        "vault",
+		Timeout:     5 * time.Minute,
+		MaxAttempts: 3,
+		Handler: func(ctx context.Context, _ json.RawMessage) (any, error) {
+			notes, err := vaultNotes(ctx, store)
+			if err != nil {
+				return nil, err
+			}
+			result, err := vault.Export(cfg.Vault.Dir, notes)
+			if err != nil {
+				return nil, err
+			}
+			if len(result.Edited) > 0 {
+				slog.InfoContext(ctx, "left hand-edited vault notes alone", "notes", result.Edited)
+			}
+			slog.InfoContext(ctx, "exported vault notes", "dir", cfg.Vault.Dir,
+				"written", result.Written, "unchanged", result.Unchanged)
+			return result, nil
+		},
+	}
+}
+
+// vaultNotes returns the read and archived reading list items as notes.
+func vaultNotes(ctx context.Context, store storage.Store) ([]vault.Note, error) {
+	var notes []vault.Note
+	for _, status := range []string{"read", "archived"} {
+		items, err := store.GetReadingListFiltered(ctx, storage.ReadingListFilter{Status: status, WithoutContent: true})
+		if err != nil {
+			return nil, fmt.Errorf("loading %s items: %w", status, err)
+		}
+		for _, item := range items {
+			if item.Blog == nil {
+				continue
+			}
+			n := vault.Note{
+				ID:       item.ID,
+				Title:    item.Blog.Title,
+				URL:      item.Blog.URL,
+				Source:   item.Blog.Source,
+				Category: item.Category,
+				Tags:     item.Tags,
+				AddedAt:  item.AddedAt,
+				ReadAt:   item.ReadAt,
+			}
+			if item.Blog.RewrittenTitle != "" {
+				n.Title = item.Blog.RewrittenTitle
+			}
+			if item.Summary != nil {
+				n.Summary = *item.Summary
+			}
+			if item.Notes != nil {
+				n.Notes = *item.Notes
+			}
+			notes = append(notes, n)
+		}
+	}
+	return notes, nil
+}
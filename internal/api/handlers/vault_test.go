@@ -0,0 +1,78 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/hoanghai1803/apricot/internal/config"
+	"github.com/hoanghai1803/apricot/internal/jobs"
+	"github.com/hoanghai1803/apricot/internal/models"
+	"github.com/hoanghai1803/apricot/internal/vault"
+)
+
+func TestExportVault(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+	for _, url := range []string{"https://a.example/read", "https://a.example/unread"} {
+		blogID, err := store.CreateCustomBlog(ctx, url, "Post "+strings.TrimPrefix(url, "https://a.example/"), "", "", "example.com")
+		if err != nil {
+			t.Fatalf("CreateCustomBlog: %v", err)
+		}
+		if err := store.AddToReadingList(ctx, blogID); err != nil {
+			t.Fatalf("AddToReadingList: %v", err)
+		}
+		if strings.HasSuffix(url, "/read") {
+			id, _ := store.GetReadingListIDByBlogID(ctx, blogID)
+			store.UpdateReadingListNotes(ctx, id, "Worth it.")
+			store.UpdateReadingListStatus(ctx, id, "read")
+		}
+	}
+
+	cfg := &config.Config{Vault: config.VaultConfig{Dir: filepath.Join(t.TempDir(), "vault")}}
+	runner := jobs.NewManager(store, 1)
+	runner.Register(VaultJob(store, cfg))
+
+	w := httptest.NewRecorder()
+	ExportVault(runner, cfg).ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/admin/vault", nil))
+	if w.Code != http.StatusAccepted {
+		t.Fatalf("status %d, want %d; body: %s", w.Code, http.StatusAccepted, w.Body.String())
+	}
+	var job models.Job
+	json.NewDecoder(w.Body).Decode(&job)
+	if err := runner.Drain(ctx); err != nil {
+		t.Fatalf("Drain() error: %v", err)
+	}
+	done, err := runner.Get(ctx, job.ID)
+	if err != nil || done.Status != models.JobSucceeded {
+		t.Fatalf("vault job = %+v, %v; want it to have succeeded", done, err)
+	}
+	var result vault.Result
+	json.Unmarshal(done.Result, &result)
+	if result.Written != 1 {
+		t.Errorf("result = %+v, want only the read post written", result)
+	}
+
+	got, err := os.ReadFile(filepath.Join(cfg.Vault.Dir, "Post read.md"))
+	if err != nil {
+		t.Fatalf("reading note: %v", err)
+	}
+	for _, want := range []string{`url: "https://a.example/read"`, "source: \"example.com\"", "read: ", "## Notes\n\nWorth it."} {
+		if !strings.Contains(string(got), want) {
+			t.Errorf("note is missing %q:\n%s", want, got)
+		}
+	}
+}
+
+func TestExportVault_NotConfigured(t *testing.T) {
+	w := httptest.NewRecorder()
+	ExportVault(nil, &config.Config{}).ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/admin/vault", nil))
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("status %d, want %d", w.Code, http.StatusServiceUnavailable)
+	}
+}
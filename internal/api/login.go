@@ -0,0 +1,80 @@
+package api
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// LoginCode returns a sign-in code for ?login=, good once until expires.
+// It is signed with token, so Auth can check it without keeping a list of
+// the codes it issued, and it never reveals the token itself: apricot
+// serve opens the browser with one rather than with ?token=, which would
+// leave the token in the browser's history and in the process list.
+func LoginCode(token string, expires time.Time) string {
+	nonce := make([]byte, 12)
+	_, _ = rand.Read(nonce)
+	payload := strconv.FormatInt(expires.Unix(), 10) + "." + hex.EncodeToString(nonce)
+	return payload + "." + loginMAC(token, payload)
+}
+
+// loginMAC returns the signature of the login code payload under token.
+func loginMAC(token, payload string) string {
+	mac := hmac.New(sha256.New, []byte(token))
+	mac.Write([]byte("login:" + payload))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// loginCodes remembers the login codes that have been used until they
+// expire, so each signs in only once.
+type loginCodes struct {
+	mu   sync.Mutex
+	used map[string]time.Time
+}
+
+// redeem reports whether code is a login code for token that has neither
+// expired nor been used, and marks it used.
+func (l *loginCodes) redeem(token, code string, now time.Time) bool {
+	payload, sig, ok := cutLast(code, ".")
+	if !ok || !hmac.Equal([]byte(sig), []byte(loginMAC(token, payload))) {
+		return false
+	}
+	unix, _, _ := strings.Cut(payload, ".")
+	secs, err := strconv.ParseInt(unix, 10, 64)
+	if err != nil {
+		return false
+	}
+	expires := time.Unix(secs, 0)
+	if !now.Before(expires) {
+		return false
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for c, exp := range l.used {
+		if !now.Before(exp) {
+			delete(l.used, c)
+		}
+	}
+	if _, seen := l.used[payload]; seen {
+		return false
+	}
+	if l.used == nil {
+		l.used = make(map[string]time.Time)
+	}
+	l.used[payload] = expires
+	return true
+}
+
+// cutLast slices s around the last instance of sep.
+func cutLast(s, sep string) (before, after string, found bool) {
+	if i := strings.LastIndex(s, sep); i >= 0 {
+		return s[:i], s[i+len(sep):], true
+	}
+	return s, "", false
+}
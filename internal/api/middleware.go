@@ -1,16 +1,30 @@
 package api
 
 import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"log/slog"
 	"net/http"
+	"net/url"
 	"runtime/debug"
+	"strings"
 	"time"
+
+	"github.com/hoanghai1803/apricot/internal/api/handlers"
+	"github.com/hoanghai1803/apricot/internal/logctx"
 )
 
-// responseWriter wraps http.ResponseWriter to capture the status code.
+// responseWriter wraps http.ResponseWriter to capture the status code and
+// the size of the body.
 type responseWriter struct {
 	http.ResponseWriter
 	statusCode int
+	bytes      int
 }
 
 // WriteHeader captures the status code before delegating to the underlying
@@ -20,6 +34,97 @@ func (rw *responseWriter) WriteHeader(code int) {
 	rw.ResponseWriter.WriteHeader(code)
 }
 
+// Write counts the bytes written before delegating to the underlying
+// ResponseWriter.
+func (rw *responseWriter) Write(b []byte) (int, error) {
+	n, err := rw.ResponseWriter.Write(b)
+	rw.bytes += n
+	return n, err
+}
+
+// RequestIDHeader is the header that carries a request's ID.
+const RequestIDHeader = "X-Request-ID"
+
+// RequestID gives every request an ID: the client's X-Request-ID if it sent
+// a plausible one, or else a new random one. The ID is returned in the
+// X-Request-ID response header and added to every log record made with the
+// request's context (see logctx), including those of jobs it queues.
+func RequestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(RequestIDHeader)
+		if !validRequestID(id) {
+			id = newRequestID()
+		}
+		w.Header().Set(RequestIDHeader, id)
+		next.ServeHTTP(w, r.WithContext(logctx.With(r.Context(), "request_id", id)))
+	})
+}
+
+// validRequestID reports whether a client-supplied request ID is safe to
+// log: 1 to 64 letters, digits, dots, dashes, and underscores.
+func validRequestID(id string) bool {
+	if id == "" || len(id) > 64 {
+		return false
+	}
+	for _, c := range id {
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '.' || c == '-' || c == '_') {
+			return false
+		}
+	}
+	return true
+}
+
+// newRequestID returns a random 16-character hex ID.
+func newRequestID() string {
+	b := make([]byte, 8)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// AccessLog returns middleware that writes one record per request to
+// logger: request ID, method, path, status, response size, duration, client
+// address, and user agent. It reads the ID from the response header, so it
+// may wrap the whole router, RequestID included. It runs before Auth, so
+// the ?token= or ?login= of a sign-in is redacted from the logged query.
+func AccessLog(logger *slog.Logger) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			rw := &responseWriter{ResponseWriter: w, statusCode: http.StatusOK}
+
+			next.ServeHTTP(rw, r)
+
+			logger.LogAttrs(r.Context(), slog.LevelInfo, "request",
+				slog.String("request_id", rw.Header().Get(RequestIDHeader)),
+				slog.String("method", r.Method),
+				slog.String("path", r.URL.Path),
+				slog.String("query", redactQuery(r.URL.RawQuery)),
+				slog.Int("status", rw.statusCode),
+				slog.Int("bytes", rw.bytes),
+				slog.Float64("duration_ms", float64(time.Since(start).Microseconds())/1000),
+				slog.String("remote", ClientIP(r)),
+				slog.String("user_agent", r.UserAgent()),
+			)
+		})
+	}
+}
+
+// redactQuery returns rawQuery with the value of any "token" or "login"
+// parameter replaced, so neither the auth token nor a login code reaches
+// the access log.
+func redactQuery(rawQuery string) string {
+	q, _ := url.ParseQuery(rawQuery)
+	if !q.Has("token") && !q.Has("login") {
+		return rawQuery
+	}
+	for _, key := range []string{"token", "login"} {
+		if q.Has(key) {
+			q.Set(key, "REDACTED")
+		}
+	}
+	return q.Encode()
+}
+
 // RequestLogger logs every HTTP request with method, path, status code, and
 // duration using the slog structured logger.
 func RequestLogger(next http.Handler) http.Handler {
@@ -33,7 +138,7 @@ func RequestLogger(next http.Handler) http.Handler {
 
 		next.ServeHTTP(rw, r)
 
-		slog.Info("http request",
+		slog.InfoContext(r.Context(), "http request",
 			"method", r.Method,
 			"path", r.URL.Path,
 			"status", rw.statusCode,
@@ -48,7 +153,7 @@ func Recovery(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		defer func() {
 			if rec := recover(); rec != nil {
-				slog.Error("panic recovered",
+				slog.ErrorContext(r.Context(), "panic recovered",
 					"panic", rec,
 					"stack", string(debug.Stack()),
 				)
@@ -60,19 +165,218 @@ func Recovery(next http.Handler) http.Handler {
 	})
 }
 
-// CORS sets permissive CORS headers on every response and handles OPTIONS
-// preflight requests by returning 204 No Content immediately.
-func CORS(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Set("Access-Control-Allow-Origin", "*")
-		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, PATCH, DELETE, OPTIONS")
-		w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
-
-		if r.Method == http.MethodOptions {
-			w.WriteHeader(http.StatusNoContent)
-			return
+// CORS returns middleware that lets pages on the given origins (such as
+// "https://example.com") call the API from the browser. "*" allows any
+// origin. The web UI is served from the same origin as the API, so with no
+// origins configured no CORS headers are sent and browsers refuse
+// cross-origin reads. Preflight requests from an allowed origin are answered
+// with 204 No Content before they reach Auth, as browsers send them without
+// credentials.
+func CORS(origins []string) func(http.Handler) http.Handler {
+	allowed := make(map[string]bool, len(origins))
+	for _, o := range origins {
+		allowed[o] = true
+	}
+	return func(next http.Handler) http.Handler {
+		if len(allowed) == 0 {
+			return next
 		}
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Add("Vary", "Origin")
+			origin := r.Header.Get("Origin")
+			if origin == "" || !(allowed["*"] || allowed[origin]) {
+				next.ServeHTTP(w, r)
+				return
+			}
 
+			w.Header().Set("Access-Control-Allow-Origin", origin)
+			w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, PATCH, DELETE, OPTIONS")
+			w.Header().Set("Access-Control-Allow-Headers", "Authorization, Content-Type, "+RequestIDHeader)
+			w.Header().Set("Access-Control-Expose-Headers", "Location, Retry-After, "+RequestIDHeader)
+
+			if r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != "" {
+				w.Header().Set("Access-Control-Max-Age", "600")
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// Content-Security-Policy values set by SecurityHeaders. The web UI loads
+// only its own scripts and styles (plus inline style attributes), shows
+// images from anywhere over HTTPS, and frames only the article proxy. API
+// responses are data and may load nothing.
+const (
+	appCSP = "default-src 'self'; script-src 'self'; style-src 'self' 'unsafe-inline'; " +
+		"img-src 'self' data: https:; connect-src 'self'; frame-src 'self'; object-src 'none'; " +
+		"base-uri 'self'; form-action 'self'; frame-ancestors 'self'"
+	apiCSP = "default-src 'none'; frame-ancestors 'self'"
+)
+
+// SecurityHeaders sets the standard hardening headers on every response:
+// no MIME sniffing, no Referer sent to other sites, framing only by the app
+// itself, a Content-Security-Policy, and over TLS, Strict-Transport-Security.
+// Handlers may replace the CSP; ProxyPage does, since the pages it serves
+// load their own scripts and styles.
+func SecurityHeaders(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		h := w.Header()
+		h.Set("X-Content-Type-Options", "nosniff")
+		h.Set("Referrer-Policy", "no-referrer")
+		h.Set("X-Frame-Options", "SAMEORIGIN")
+		if strings.HasPrefix(r.URL.Path, "/api/") {
+			h.Set("Content-Security-Policy", apiCSP)
+		} else {
+			h.Set("Content-Security-Policy", appCSP)
+		}
+		if r.TLS != nil {
+			h.Set("Strict-Transport-Security", "max-age=31536000")
+		}
 		next.ServeHTTP(w, r)
 	})
 }
+
+// deadlineWriter wraps http.ResponseWriter to record whether a response was
+// started.
+type deadlineWriter struct {
+	http.ResponseWriter
+	wrote bool
+}
+
+// WriteHeader records that the response was started before delegating to the
+// underlying ResponseWriter.
+func (dw *deadlineWriter) WriteHeader(code int) {
+	dw.wrote = true
+	dw.ResponseWriter.WriteHeader(code)
+}
+
+// Write records that the response was started before delegating to the
+// underlying ResponseWriter.
+func (dw *deadlineWriter) Write(b []byte) (int, error) {
+	dw.wrote = true
+	return dw.ResponseWriter.Write(b)
+}
+
+// Deadline returns middleware that bounds each request's context to timeout,
+// so calls to a stuck upstream (a feed, an article page, the AI provider)
+// are cancelled instead of hanging the handler. Handlers report timeouts
+// with the stage that ran out of time; if one returns after the deadline
+// without responding, Deadline responds with 504 Gateway Timeout.
+func Deadline(timeout time.Duration) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx, cancel := context.WithTimeout(r.Context(), timeout)
+			defer cancel()
+
+			dw := &deadlineWriter{ResponseWriter: w}
+			next.ServeHTTP(dw, r.WithContext(ctx))
+
+			if !dw.wrote && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				slog.WarnContext(r.Context(), "request timed out", "method", r.Method, "path", r.URL.Path, "timeout", timeout.String())
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(http.StatusGatewayTimeout)
+				_ = json.NewEncoder(w).Encode(map[string]string{
+					"error": fmt.Sprintf("Request timed out after %s", timeout),
+				})
+			}
+		})
+	}
+}
+
+// authCookie is the cookie that carries the auth token for browsers.
+const authCookie = "apricot_token"
+
+// Auth returns middleware that requires token on every request, either as
+// an "Authorization: Bearer" header (for scripts and other clients) or in
+// the auth cookie (for the web UI). Opening any page with ?token= set to
+// the token sets the cookie and redirects to the same page without it, so
+// a phone only has to follow that link once. ?login= with a code from
+// LoginCode signs in the same way, but only once and only until it
+// expires. saveToken, if set, is also
+// accepted as a bearer token, but only for POST /api/save, so a browser
+// extension can save pages without full access. A cross-site GET /save is
+// let through without a token: the browser withholds the SameSite cookie,
+// and the page only offers a button, whose request carries it. The public
+// pages of share links, GET /s/{token}, need no token either.
+func Auth(token, saveToken string) func(http.Handler) http.Handler {
+	var codes loginCodes
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			q := r.URL.Query()
+			signIn := r.Method == http.MethodGet &&
+				((q.Has("token") && tokenMatches(q.Get("token"), token)) ||
+					(q.Has("login") && codes.redeem(token, q.Get("login"), time.Now())))
+			if signIn {
+				http.SetCookie(w, &http.Cookie{
+					Name:     authCookie,
+					Value:    token,
+					Path:     "/",
+					MaxAge:   365 * 24 * 60 * 60,
+					HttpOnly: true,
+					Secure:   r.TLS != nil,
+					SameSite: http.SameSiteStrictMode,
+				})
+				q.Del("token")
+				q.Del("login")
+				u := *r.URL
+				u.RawQuery = q.Encode()
+				http.Redirect(w, r, u.RequestURI(), http.StatusSeeOther)
+				return
+			}
+
+			if bearer, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok {
+				if tokenMatches(bearer, token) || (saveToken != "" && isSave(r) && tokenMatches(bearer, saveToken)) {
+					next.ServeHTTP(w, r)
+					return
+				}
+			}
+			if c, err := r.Cookie(authCookie); err == nil && tokenMatches(c.Value, token) {
+				next.ServeHTTP(w, r)
+				return
+			}
+			if isSavePrompt(r) || isSharedPage(r) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			slog.WarnContext(r.Context(), "unauthorized request", "method", r.Method, "path", r.URL.Path, "remote", r.RemoteAddr)
+			w.Header().Set("WWW-Authenticate", `Bearer realm="apricot"`)
+			if strings.HasPrefix(r.URL.Path, "/api/") {
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(http.StatusUnauthorized)
+				_ = json.NewEncoder(w).Encode(map[string]string{
+					"error": "Missing or invalid auth token",
+				})
+				return
+			}
+			http.Error(w, "Apricot requires a token. Open this page with ?token=<your auth_token> to sign in.",
+				http.StatusUnauthorized)
+		})
+	}
+}
+
+// isSave reports whether r is a quick save, the one request the save token
+// is good for.
+func isSave(r *http.Request) bool {
+	return r.Method == http.MethodPost && r.URL.Path == "/api/save"
+}
+
+// isSavePrompt reports whether r is a cross-site GET /save, which only
+// asks to confirm (see handlers.SavePage).
+func isSavePrompt(r *http.Request) bool {
+	return r.Method == http.MethodGet && r.URL.Path == "/save" && handlers.CrossSite(r)
+}
+
+// isSharedPage reports whether r is for the public page of a share link.
+func isSharedPage(r *http.Request) bool {
+	return r.Method == http.MethodGet && strings.HasPrefix(r.URL.Path, "/s/")
+}
+
+// tokenMatches compares a presented token with the configured one in
+// constant time.
+func tokenMatches(got, want string) bool {
+	return subtle.ConstantTimeCompare([]byte(got), []byte(want)) == 1
+}
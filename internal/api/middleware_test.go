@@ -1,17 +1,26 @@
 package api
 
 import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"strings"
 	"testing"
+	"time"
+
+	"github.com/hoanghai1803/apricot/internal/logctx"
 )
 
 func TestCORSHeaders(t *testing.T) {
-	handler := CORS(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	handler := CORS([]string{"https://example.com"})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusOK)
 	}))
 
-	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r := httptest.NewRequest(http.MethodGet, "/api/blogs", nil)
+	r.Header.Set("Origin", "https://example.com")
 	w := httptest.NewRecorder()
 
 	handler.ServeHTTP(w, r)
@@ -20,9 +29,10 @@ func TestCORSHeaders(t *testing.T) {
 		header string
 		want   string
 	}{
-		{"Access-Control-Allow-Origin", "*"},
+		{"Access-Control-Allow-Origin", "https://example.com"},
 		{"Access-Control-Allow-Methods", "GET, POST, PUT, PATCH, DELETE, OPTIONS"},
-		{"Access-Control-Allow-Headers", "Content-Type"},
+		{"Access-Control-Allow-Headers", "Authorization, Content-Type, X-Request-ID"},
+		{"Vary", "Origin"},
 	}
 
 	for _, tt := range tests {
@@ -37,13 +47,45 @@ func TestCORSHeaders(t *testing.T) {
 	}
 }
 
+func TestCORSOtherOrigins(t *testing.T) {
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+
+	tests := []struct {
+		name    string
+		origins []string
+		origin  string
+		want    string
+	}{
+		{"none configured", nil, "https://example.com", ""},
+		{"not listed", []string{"https://example.com"}, "https://evil.example", ""},
+		{"same origin", []string{"https://example.com"}, "", ""},
+		{"wildcard", []string{"*"}, "https://anywhere.example", "https://anywhere.example"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/api/blogs", nil)
+			if tt.origin != "" {
+				r.Header.Set("Origin", tt.origin)
+			}
+			w := httptest.NewRecorder()
+			CORS(tt.origins)(ok).ServeHTTP(w, r)
+
+			if got := w.Header().Get("Access-Control-Allow-Origin"); got != tt.want {
+				t.Errorf("Access-Control-Allow-Origin = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
 func TestCORSPreflight(t *testing.T) {
 	innerCalled := false
-	handler := CORS(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	handler := CORS([]string{"https://example.com"})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		innerCalled = true
 	}))
 
 	r := httptest.NewRequest(http.MethodOptions, "/api/discover", nil)
+	r.Header.Set("Origin", "https://example.com")
+	r.Header.Set("Access-Control-Request-Method", http.MethodPost)
 	w := httptest.NewRecorder()
 
 	handler.ServeHTTP(w, r)
@@ -57,8 +99,41 @@ func TestCORSPreflight(t *testing.T) {
 	}
 
 	// CORS headers should still be set.
-	if got := w.Header().Get("Access-Control-Allow-Origin"); got != "*" {
-		t.Errorf("Access-Control-Allow-Origin = %q, want %q", got, "*")
+	if got := w.Header().Get("Access-Control-Allow-Origin"); got != "https://example.com" {
+		t.Errorf("Access-Control-Allow-Origin = %q, want %q", got, "https://example.com")
+	}
+}
+
+func TestSecurityHeaders(t *testing.T) {
+	handler := SecurityHeaders(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	for path, wantCSP := range map[string]string{
+		"/":          appCSP,
+		"/reading":   appCSP,
+		"/api/blogs": apiCSP,
+	} {
+		r := httptest.NewRequest(http.MethodGet, path, nil)
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+
+		for header, want := range map[string]string{
+			"X-Content-Type-Options":    "nosniff",
+			"Referrer-Policy":           "no-referrer",
+			"X-Frame-Options":           "SAMEORIGIN",
+			"Content-Security-Policy":   wantCSP,
+			"Strict-Transport-Security": "",
+		} {
+			if got := w.Header().Get(header); got != want {
+				t.Errorf("GET %s: %s = %q, want %q", path, header, got, want)
+			}
+		}
+	}
+
+	r := httptest.NewRequest(http.MethodGet, "https://apricot.example.com/", nil)
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+	if got := w.Header().Get("Strict-Transport-Security"); got == "" {
+		t.Error("Strict-Transport-Security not set over TLS")
 	}
 }
 
@@ -112,3 +187,223 @@ func TestRequestLoggerSetsStatus(t *testing.T) {
 		t.Errorf("got status %d, want %d", w.Code, http.StatusCreated)
 	}
 }
+
+func TestDeadline(t *testing.T) {
+	t.Run("handler responds in time", func(t *testing.T) {
+		handler := Deadline(time.Second)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if _, ok := r.Context().Deadline(); !ok {
+				t.Error("request context has no deadline")
+			}
+			w.WriteHeader(http.StatusOK)
+		}))
+
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/tags", nil))
+
+		if w.Code != http.StatusOK {
+			t.Errorf("got status %d, want %d", w.Code, http.StatusOK)
+		}
+	})
+
+	t.Run("stuck handler times out", func(t *testing.T) {
+		handler := Deadline(10 * time.Millisecond)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			<-r.Context().Done()
+		}))
+
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/proxy", nil))
+
+		if w.Code != http.StatusGatewayTimeout {
+			t.Errorf("got status %d, want %d", w.Code, http.StatusGatewayTimeout)
+		}
+	})
+}
+
+func TestAuth(t *testing.T) {
+	const token, saveToken = "0123456789abcdef", "fedcba9876543210"
+	handler := Auth(token, saveToken)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	tests := []struct {
+		name     string
+		method   string
+		path     string
+		header   string
+		cookie   string
+		wantCode int
+	}{
+		{"no token", "GET", "/api/tags", "", "", http.StatusUnauthorized},
+		{"no token on a page", "GET", "/reading-list", "", "", http.StatusUnauthorized},
+		{"bearer token", "GET", "/api/tags", "Bearer " + token, "", http.StatusOK},
+		{"wrong bearer token", "GET", "/api/tags", "Bearer nope", "", http.StatusUnauthorized},
+		{"cookie", "GET", "/api/tags", "", token, http.StatusOK},
+		{"wrong cookie", "GET", "/", "", "nope", http.StatusUnauthorized},
+		{"wrong query token", "GET", "/?token=nope", "", "", http.StatusUnauthorized},
+		{"save token saves", "POST", "/api/save", "Bearer " + saveToken, "", http.StatusOK},
+		{"save token elsewhere", "GET", "/api/tags", "Bearer " + saveToken, "", http.StatusUnauthorized},
+		{"save token as cookie", "POST", "/api/save", "", saveToken, http.StatusUnauthorized},
+		{"shared page", "GET", "/s/abc", "", "", http.StatusOK},
+		{"shared page, other method", "POST", "/s/abc", "", "", http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(tt.method, tt.path, nil)
+			if tt.header != "" {
+				r.Header.Set("Authorization", tt.header)
+			}
+			if tt.cookie != "" {
+				r.AddCookie(&http.Cookie{Name: authCookie, Value: tt.cookie})
+			}
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, r)
+			if w.Code != tt.wantCode {
+				t.Errorf("got status %d, want %d", w.Code, tt.wantCode)
+			}
+		})
+	}
+
+	t.Run("query token signs in", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/reading-list?token="+token+"&tab=unread", nil))
+		if w.Code != http.StatusSeeOther {
+			t.Fatalf("got status %d, want %d", w.Code, http.StatusSeeOther)
+		}
+		if got := w.Header().Get("Location"); got != "/reading-list?tab=unread" {
+			t.Errorf("Location = %q, want the page without the token", got)
+		}
+		cookies := w.Result().Cookies()
+		if len(cookies) != 1 || cookies[0].Name != authCookie || cookies[0].Value != token || !cookies[0].HttpOnly {
+			t.Errorf("cookies = %+v, want an HttpOnly auth cookie", cookies)
+		}
+	})
+
+	t.Run("login code signs in once", func(t *testing.T) {
+		code := LoginCode(token, time.Now().Add(time.Minute))
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/?login="+url.QueryEscape(code), nil))
+		if w.Code != http.StatusSeeOther || w.Header().Get("Location") != "/" {
+			t.Fatalf("got status %d to %q, want %d to /", w.Code, w.Header().Get("Location"), http.StatusSeeOther)
+		}
+		if cookies := w.Result().Cookies(); len(cookies) != 1 || cookies[0].Value != token {
+			t.Errorf("cookies = %+v, want the auth cookie", cookies)
+		}
+
+		for name, code := range map[string]string{
+			"used":         code,
+			"expired":      LoginCode(token, time.Now().Add(-time.Second)),
+			"other token":  LoginCode("another-token", time.Now().Add(time.Minute)),
+			"not a code":   "123.abc",
+			"the token":    token,
+			"tampered exp": "9" + code[1:],
+		} {
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/?login="+url.QueryEscape(code), nil))
+			if w.Code != http.StatusUnauthorized {
+				t.Errorf("%s code: got status %d, want %d", name, w.Code, http.StatusUnauthorized)
+			}
+		}
+	})
+
+	t.Run("cross-site save page", func(t *testing.T) {
+		for site, want := range map[string]int{"cross-site": http.StatusOK, "none": http.StatusUnauthorized} {
+			r := httptest.NewRequest(http.MethodGet, "/save?url=https://example.com/", nil)
+			r.Header.Set("Sec-Fetch-Site", site)
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, r)
+			if w.Code != want {
+				t.Errorf("Sec-Fetch-Site %s: got status %d, want %d", site, w.Code, want)
+			}
+		}
+	})
+}
+
+func TestRequestID(t *testing.T) {
+	var gotID any
+	handler := RequestID(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotID, _ = logctx.Value(r.Context(), "request_id")
+	}))
+
+	t.Run("generated", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/tags", nil))
+		id := w.Header().Get(RequestIDHeader)
+		if len(id) != 16 || gotID != id {
+			t.Errorf("X-Request-ID = %q, context ID = %v; want the same new 16-character ID", id, gotID)
+		}
+	})
+
+	t.Run("from the client", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodGet, "/api/tags", nil)
+		r.Header.Set(RequestIDHeader, "retry-42.a_b")
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		if got := w.Header().Get(RequestIDHeader); got != "retry-42.a_b" || gotID != got {
+			t.Errorf("X-Request-ID = %q, context ID = %v; want the client's ID", got, gotID)
+		}
+	})
+
+	t.Run("unsafe client ID replaced", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodGet, "/api/tags", nil)
+		r.Header.Set(RequestIDHeader, "evil\nlog line")
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		if got := w.Header().Get(RequestIDHeader); got == "evil\nlog line" || len(got) != 16 {
+			t.Errorf("X-Request-ID = %q, want a new ID", got)
+		}
+	})
+}
+
+func TestAccessLog(t *testing.T) {
+	var buf bytes.Buffer
+	handler := AccessLog(slog.New(slog.NewJSONHandler(&buf, nil)))(RequestID(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte("hello"))
+	})))
+
+	r := httptest.NewRequest(http.MethodPost, "/api/reading-list?x=1", nil)
+	r.Header.Set(RequestIDHeader, "abc")
+	handler.ServeHTTP(httptest.NewRecorder(), r)
+
+	var record map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &record); err != nil {
+		t.Fatalf("decoding access log line %q: %v", buf.String(), err)
+	}
+	for key, want := range map[string]any{
+		"request_id": "abc",
+		"method":     "POST",
+		"path":       "/api/reading-list",
+		"query":      "x=1",
+		"status":     float64(http.StatusCreated),
+		"bytes":      float64(5),
+	} {
+		if record[key] != want {
+			t.Errorf("%s = %v, want %v", key, record[key], want)
+		}
+	}
+}
+
+func TestAccessLogRedactsToken(t *testing.T) {
+	var buf bytes.Buffer
+	handler := AccessLog(slog.New(slog.NewJSONHandler(&buf, nil)))(Auth("secret", "")(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})))
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/reading?token=secret&tab=unread", nil))
+
+	if strings.Contains(buf.String(), "secret") {
+		t.Errorf("access log contains the token: %s", buf.String())
+	}
+	var record map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &record); err != nil {
+		t.Fatalf("decoding access log line %q: %v", buf.String(), err)
+	}
+	if want := "tab=unread&token=REDACTED"; record["query"] != want {
+		t.Errorf("query = %v, want %q", record["query"], want)
+	}
+
+	buf.Reset()
+	code := LoginCode("secret", time.Now().Add(time.Minute))
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/?login="+url.QueryEscape(code), nil))
+	if strings.Contains(buf.String(), code) {
+		t.Errorf("access log contains the login code: %s", buf.String())
+	}
+}
@@ -0,0 +1,130 @@
+package api
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"log/slog"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RateLimiter limits how often each client may make requests, with a token
+// bucket per client: a bucket holds up to a minute's worth of requests and
+// refills at the configured rate, so short bursts pass and sustained
+// hammering is turned away with 429 Too Many Requests.
+type RateLimiter struct {
+	rate  float64 // tokens added per second
+	burst float64 // bucket size
+	key   func(*http.Request) string
+	now   func() time.Time
+
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+}
+
+// bucket is one client's token bucket.
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// NewRateLimiter returns a RateLimiter allowing perMinute requests a minute
+// to each client, as identified by key (see ClientIP and ClientToken).
+func NewRateLimiter(perMinute int, key func(*http.Request) string) *RateLimiter {
+	return &RateLimiter{
+		rate:    float64(perMinute) / 60,
+		burst:   float64(perMinute),
+		key:     key,
+		now:     time.Now,
+		buckets: make(map[string]*bucket),
+	}
+}
+
+// Allow takes a token from the client's bucket. If it is empty, Allow
+// returns false and how long until a token is available.
+func (l *RateLimiter) Allow(client string) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	l.sweep(now)
+
+	b, ok := l.buckets[client]
+	if !ok {
+		b = &bucket{tokens: l.burst, last: now}
+		l.buckets[client] = b
+	}
+	b.tokens = min(l.burst, b.tokens+now.Sub(b.last).Seconds()*l.rate)
+	b.last = now
+
+	if b.tokens < 1 {
+		wait := time.Duration((1 - b.tokens) / l.rate * float64(time.Second))
+		return false, wait
+	}
+	b.tokens--
+	return true, 0
+}
+
+// sweep forgets, at most once a minute, clients whose buckets have refilled,
+// so the map doesn't grow with every address ever seen. l.mu must be held.
+func (l *RateLimiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < time.Minute {
+		return
+	}
+	l.lastSweep = now
+	for client, b := range l.buckets {
+		if b.tokens+now.Sub(b.last).Seconds()*l.rate >= l.burst {
+			delete(l.buckets, client)
+		}
+	}
+}
+
+// Middleware rejects requests from clients over their limit with 429 and a
+// Retry-After header.
+func (l *RateLimiter) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		client := l.key(r)
+		ok, wait := l.Allow(client)
+		if !ok {
+			slog.WarnContext(r.Context(), "rate limited", "method", r.Method, "path", r.URL.Path, "client", client)
+			w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusTooManyRequests)
+			_ = json.NewEncoder(w).Encode(map[string]string{
+				"error": "Too many requests. Try again in a moment",
+			})
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// ClientIP identifies a client by the IP address it connected from.
+// X-Forwarded-For is ignored: the server is not meant to sit behind a
+// proxy, and the header is trivially forged.
+func ClientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// ClientToken identifies a client by a hash of its bearer token, so the
+// token itself never reaches the logs, falling back to its IP address. Only
+// use it behind Auth, which rejects unknown tokens; otherwise a client could
+// dodge its limit by inventing new ones.
+func ClientToken(r *http.Request) string {
+	if token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok && token != "" {
+		sum := sha256.Sum256([]byte(token))
+		return "token:" + hex.EncodeToString(sum[:8])
+	}
+	return ClientIP(r)
+}
@@ -0,0 +1,73 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestRateLimiter(t *testing.T) {
+	l := NewRateLimiter(60, ClientIP) // one a second, bursts of 60
+	now := time.Date(2025, 3, 4, 5, 0, 0, 0, time.UTC)
+	l.now = func() time.Time { return now }
+
+	for i := range 60 {
+		if ok, _ := l.Allow("10.0.0.1"); !ok {
+			t.Fatalf("request %d of a burst of 60 was refused", i+1)
+		}
+	}
+	ok, wait := l.Allow("10.0.0.1")
+	if ok || wait <= 0 || wait > time.Second {
+		t.Errorf("Allow() past the burst = %v, %v; want refused for up to a second", ok, wait)
+	}
+	if ok, _ := l.Allow("10.0.0.2"); !ok {
+		t.Error("another client was refused")
+	}
+
+	now = now.Add(time.Second)
+	if ok, _ := l.Allow("10.0.0.1"); !ok {
+		t.Error("Allow() after a second's refill was refused")
+	}
+
+	// Refilled buckets are forgotten.
+	now = now.Add(2 * time.Minute)
+	l.Allow("10.0.0.3")
+	if _, ok := l.buckets["10.0.0.2"]; ok || len(l.buckets) != 1 {
+		t.Errorf("buckets after sweeping = %v, want only the new client", l.buckets)
+	}
+}
+
+func TestRateLimiterMiddleware(t *testing.T) {
+	l := NewRateLimiter(1, ClientToken)
+	handler := l.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	request := func(token string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodGet, "/api/proxy", nil)
+		r.RemoteAddr = "192.168.1.5:51234"
+		if token != "" {
+			r.Header.Set("Authorization", "Bearer "+token)
+		}
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		return w
+	}
+
+	if w := request(""); w.Code != http.StatusOK {
+		t.Fatalf("first request: got status %d, want %d", w.Code, http.StatusOK)
+	}
+	w := request("")
+	if w.Code != http.StatusTooManyRequests {
+		t.Fatalf("second request: got status %d, want %d", w.Code, http.StatusTooManyRequests)
+	}
+	if got := w.Header().Get("Retry-After"); got != "60" {
+		t.Errorf("Retry-After = %q, want %q", got, "60")
+	}
+
+	// A token has its own bucket, whatever address it comes from.
+	if w := request("phone-token"); w.Code != http.StatusOK {
+		t.Errorf("request with a token: got status %d, want %d", w.Code, http.StatusOK)
+	}
+}
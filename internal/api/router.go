@@ -4,55 +4,237 @@ import (
 	"embed"
 	"io/fs"
 	"net/http"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/hoanghai1803/apricot/internal/ai"
 	"github.com/hoanghai1803/apricot/internal/api/handlers"
+	"github.com/hoanghai1803/apricot/internal/backup"
+	"github.com/hoanghai1803/apricot/internal/bookmarks"
 	"github.com/hoanghai1803/apricot/internal/config"
+	"github.com/hoanghai1803/apricot/internal/email"
 	"github.com/hoanghai1803/apricot/internal/feeds"
+	"github.com/hoanghai1803/apricot/internal/jobs"
+	"github.com/hoanghai1803/apricot/internal/netguard"
+	"github.com/hoanghai1803/apricot/internal/notify"
+	"github.com/hoanghai1803/apricot/internal/notion"
+	"github.com/hoanghai1803/apricot/internal/pagecache"
+	"github.com/hoanghai1803/apricot/internal/readwise"
 	"github.com/hoanghai1803/apricot/internal/storage"
 )
 
 //go:embed all:dist
 var distFS embed.FS
 
-// NewRouter creates and configures the HTTP router with all API routes and
-// static file serving for the React SPA.
-func NewRouter(store *storage.Store, aiProvider ai.AIProvider, fetcher *feeds.Fetcher, cfg *config.Config) *chi.Mux {
+// NewRouter creates and configures the HTTP router with all API routes and,
+// unless cfg.Server.Headless is set, static file serving for the React SPA.
+func NewRouter(store storage.Store, aiProvider ai.AIProvider, fetcher *feeds.Fetcher, backups *backup.Manager, proxyCache *pagecache.Cache, runner *jobs.Manager, notifier *notify.Notifier, mailer *email.Mailer, rw *readwise.Client, bm bookmarks.Service, nc *notion.Client, cfg *config.Config) *chi.Mux {
+	// Register the background jobs the routes below queue.
+	runner.Register(notifier.Job())
+	runner.Register(handlers.DiscoverJob(store, aiProvider, fetcher, cfg, notifier))
+	runner.Register(handlers.SaveJob(store, fetcher, aiProvider, cfg, notifier))
+	runner.Register(handlers.SourceSuggestionsJob(store, fetcher))
+	if backups != nil {
+		runner.Register(handlers.BackupJob(backups))
+	}
+	if mailer != nil {
+		runner.Register(handlers.DigestJob(store, mailer, cfg))
+	}
+	if mailer != nil && cfg.Kindle.Enabled() {
+		runner.Register(handlers.KindleJob(store, fetcher, mailer, cfg))
+	}
+	if rw != nil {
+		runner.Register(handlers.ReadwiseJob(store, rw, cfg))
+	}
+	if bm != nil {
+		runner.Register(handlers.BookmarksJob(store, bm, runner, notifier, cfg))
+	}
+	if cfg.Vault.Enabled() {
+		runner.Register(handlers.VaultJob(store, cfg))
+	}
+	if nc != nil {
+		runner.Register(handlers.NotionJob(store, nc, cfg))
+	}
+
+	guard, _ := netguard.New(cfg.Feeds.AllowNetworks) // already validated by config.Load
+
 	r := chi.NewRouter()
 
 	// Global middleware.
+	r.Use(RequestID)
 	r.Use(RequestLogger)
 	r.Use(Recovery)
-	r.Use(CORS)
+	r.Use(SecurityHeaders)
+	r.Use(CORS(cfg.Server.CORSOrigins))
+	if cfg.Server.AuthToken != "" {
+		r.Use(Auth(cfg.Server.AuthToken, cfg.Server.SaveToken))
+	}
+
+	// API sub-router. Every route gets a deadline by class, so no request
+	// can hang on a stuck upstream.
+	requestTimeout := time.Duration(cfg.Server.RequestTimeoutSeconds) * time.Second
+	fetchTimeout := time.Duration(cfg.Server.FetchTimeoutSeconds) * time.Second
+	discoveryTimeout := time.Duration(cfg.Server.DiscoveryTimeoutSeconds) * time.Second
+
+	// Rate limits per client, by token when one is required (every device
+	// shares it) or else by address. Expensive requests count against both.
+	// Routes Auth lets through without a token are limited by address
+	// (limitByIP), since ClientToken trusts tokens Auth has checked.
+	clientKey := ClientIP
+	if cfg.Server.AuthToken != "" {
+		clientKey = ClientToken
+	}
+	limitBy := func(perMinute int, key func(*http.Request) string) func(http.Handler) http.Handler {
+		if perMinute <= 0 {
+			return func(next http.Handler) http.Handler { return next }
+		}
+		return NewRateLimiter(perMinute, key).Middleware
+	}
+	limit := func(perMinute int) func(http.Handler) http.Handler {
+		return limitBy(perMinute, clientKey)
+	}
+	limitByIP := func(perMinute int) func(http.Handler) http.Handler {
+		return limitBy(perMinute, ClientIP)
+	}
+	expensive := limit(cfg.Server.ExpensiveRateLimitPerMinute)
 
-	// API sub-router.
 	r.Route("/api", func(api chi.Router) {
-		api.Post("/discover", handlers.Discover(store, aiProvider, fetcher, cfg))
-		api.Get("/discover/latest", handlers.GetLatestDiscovery(store))
+		api.Use(limit(cfg.Server.RateLimitPerMinute))
+
+		// Quick reads and writes against the database.
+		api.Group(func(api chi.Router) {
+			api.Use(Deadline(requestTimeout))
+
+			api.With(expensive).Post("/discover", handlers.Discover(store, aiProvider, runner))
+			api.Get("/discover/latest", handlers.GetLatestDiscovery(store))
+			api.Get("/discover/sessions", handlers.ListDiscoverySessions(store))
+			api.Get("/discover/sessions/compare", handlers.CompareDiscoverySessions(store))
+			api.Get("/discover/sessions/{id}", handlers.GetDiscoverySession(store))
+			api.With(expensive).Post("/discover/sessions/{id}/retry-failed", handlers.RetryFailedFeeds(store, aiProvider, runner))
+			api.Post("/discover/sessions/{id}/save-all", handlers.SaveSessionResults(store, notifier))
+
+			api.Get("/jobs", handlers.ListJobs(runner))
+			api.Get("/jobs/{id}", handlers.GetJob(runner))
+			api.Post("/admin/backup", handlers.CreateBackup(backups, runner))
+			api.Post("/admin/digest", handlers.SendDigest(mailer, runner))
+			api.Post("/admin/readwise", handlers.SyncReadwise(rw, runner))
+			api.Post("/admin/bookmarks/sync", handlers.SyncBookmarks(bm, runner))
+			api.Post("/admin/vault", handlers.ExportVault(runner, cfg))
+			api.Post("/admin/notion", handlers.ExportNotion(nc, runner))
+
+			api.Get("/preferences", handlers.GetPreferences(store))
+			api.Put("/preferences", handlers.UpdatePreferences(store))
+			api.Get("/settings", handlers.GetSettings(store, cfg))
+			api.Put("/settings", handlers.UpdateSettings(store, cfg))
+
+			api.Get("/reading-list", handlers.GetReadingList(store))
+			api.Post("/reading-list", handlers.AddToReadingList(store, notifier))
+			api.Post("/save", handlers.QuickSave(store, runner, notifier))
+			api.Post("/reading-list/archive-read", handlers.ArchiveReadItems(store))
+			api.Post("/reading-list/bulk", handlers.BulkUpdateReadingList(store, notifier))
+			api.Patch("/reading-list/reorder", handlers.ReorderReadingList(store))
+			api.Patch("/reading-list/{id}", handlers.UpdateReadingListItem(store, notifier))
+			api.Patch("/reading-list/{id}/progress", handlers.UpdateReadingProgress(store, notifier))
+			api.Get("/reading-list/{id}/notes/history", handlers.GetNoteHistory(store))
+			api.Delete("/reading-list/{id}", handlers.DeleteReadingListItem(store))
+			api.Post("/reading-list/{id}/tags", handlers.AddTagToItem(store))
+			api.Delete("/reading-list/{id}/tags/{tag}", handlers.RemoveTagFromItem(store))
+			api.Get("/reading-list/{id}/share", handlers.ListShareLinks(store, cfg))
+			api.Post("/reading-list/{id}/share", handlers.CreateShareLink(store, cfg))
+			api.Delete("/reading-list/{id}/share/{shareID}", handlers.RevokeShareLink(store))
+			api.Post("/reading-list/{id}/kindle", handlers.SendItemToKindle(store, mailer, runner, cfg))
+			api.Post("/kindle", handlers.SendToKindle(mailer, runner, cfg))
+
+			api.Get("/review", handlers.GetReviewQueue(store))
+			api.Post("/review/{id}", handlers.MarkReviewed(store))
+
+			api.Get("/tags", handlers.GetAllTags(store))
+			api.Put("/tags/{tag}", handlers.UpdateTag(store))
+			api.Get("/search", handlers.SearchBlogs(store))
 
-		api.Get("/preferences", handlers.GetPreferences(store))
-		api.Put("/preferences", handlers.UpdatePreferences(store))
+			api.Get("/paths", handlers.ListLearningPaths(store))
+			api.Post("/paths", handlers.CreateLearningPath(store))
+			api.Get("/paths/{id}", handlers.GetLearningPath(store))
+			api.Patch("/paths/{id}", handlers.UpdateLearningPath(store))
+			api.Delete("/paths/{id}", handlers.DeleteLearningPath(store))
 
-		api.Get("/reading-list", handlers.GetReadingList(store))
-		api.Post("/reading-list", handlers.AddToReadingList(store))
-		api.Post("/reading-list/custom", handlers.AddCustomBlog(store, fetcher, aiProvider, cfg))
-		api.Get("/reading-list/{id}", handlers.GetReadingListItem(store, fetcher))
-		api.Patch("/reading-list/{id}", handlers.UpdateReadingListItem(store))
-		api.Patch("/reading-list/{id}/progress", handlers.UpdateReadingProgress(store))
-		api.Delete("/reading-list/{id}", handlers.DeleteReadingListItem(store))
-		api.Post("/reading-list/{id}/tags", handlers.AddTagToItem(store))
-		api.Delete("/reading-list/{id}/tags/{tag}", handlers.RemoveTagFromItem(store))
+			api.Get("/research", handlers.ListResearchReports(store))
+			api.Get("/research/{id}", handlers.GetResearchReport(store))
+			api.Delete("/research/{id}", handlers.DeleteResearchReport(store))
 
-		api.Get("/tags", handlers.GetAllTags(store))
-		api.Get("/search", handlers.SearchBlogs(store))
+			api.Put("/blogs/{id}/feedback", handlers.SetBlogFeedback(store))
+			api.Get("/blogs/{id}/revisions", handlers.GetBlogRevisions(store))
 
-		api.Get("/sources", handlers.GetSources(store))
-		api.Put("/sources/{id}", handlers.ToggleSource(store))
+			api.Get("/sources", handlers.GetSources(store))
+			api.Get("/sources/scores", handlers.GetSourceScores(store))
+			api.Get("/sources/{id}/stats", handlers.GetSourceStats(store))
+			api.Post("/sources/suggestions", handlers.SuggestSources(runner))
+			api.Get("/stats/trends", handlers.GetTrends(store))
+			api.Put("/sources/{id}", handlers.ToggleSource(store))
 
-		api.Get("/proxy", handlers.ProxyPage())
+			api.Get("/admin/outbound", handlers.GetOutboundLog())
+			api.Get("/admin/migrations", handlers.GetMigrationStatus(store))
+			api.Get("/admin/db-stats", handlers.GetDBStats(store))
+			api.Get("/admin/audit", handlers.GetAuditLog(store))
+		})
+
+		// Requests that make a single upstream call.
+		api.Group(func(api chi.Router) {
+			api.Use(expensive)
+			api.Use(Deadline(fetchTimeout))
+
+			api.Get("/reading-list/{id}", handlers.GetReadingListItem(store, fetcher))
+			api.Get("/reading-list/{id}/pdf", handlers.ExportItemPDF(store, fetcher))
+			api.Get("/blogs/{id}/content", handlers.GetBlogContent(store, fetcher))
+
+			api.Get("/ai/models", handlers.ListAIModels(aiProvider, cfg))
+			api.Post("/ai/test", handlers.TestAIProvider(aiProvider, cfg))
+
+			api.Get("/proxy", handlers.ProxyPage(guard, proxyCache))
+		})
+
+		// The images and stylesheets of proxied pages: single upstream calls,
+		// but a page loads many at once, so only the general limit applies.
+		api.Group(func(api chi.Router) {
+			api.Use(Deadline(fetchTimeout))
+
+			api.Get("/proxy/asset", handlers.ProxyAsset(guard, proxyCache))
+		})
+
+		// Long-running requests: feed fetching, AI pipelines, and bulk data.
+		api.Group(func(api chi.Router) {
+			api.Use(expensive)
+			api.Use(Deadline(discoveryTimeout))
+
+			api.Post("/reading-list/custom", handlers.AddCustomBlog(store, fetcher, aiProvider, cfg, notifier))
+			api.Post("/reading-list/custom/batch", handlers.BatchAddCustomBlogs(store, fetcher, aiProvider, cfg, notifier))
+			api.Post("/paths/generate", handlers.GenerateLearningPath(store, aiProvider))
+			api.Post("/research", handlers.Research(store, aiProvider, fetcher, cfg))
+			api.Get("/blogs/{id}/prerequisites", handlers.GetPrerequisites(store, aiProvider))
+			api.Get("/reports/year/{year}", handlers.GetYearReport(store, aiProvider))
+			api.Get("/reading-plan", handlers.GetReadingPlan(store, aiProvider))
+
+			api.Get("/export", handlers.ExportArchive(store))
+			api.Get("/export/epub", handlers.ExportEPUB(store, fetcher))
+			api.Post("/import", handlers.ImportArchive(store))
+			api.Post("/admin/maintenance", handlers.RunMaintenance(store))
+		})
 	})
 
+	// The bookmarklet and share target page, rendered by the server so it
+	// works before the SPA loads.
+	r.With(limitByIP(cfg.Server.RateLimitPerMinute), Deadline(requestTimeout)).
+		Get("/save", handlers.SavePage(store, runner, notifier))
+
+	// Public pages of share links, open to anyone with the link.
+	r.With(limitByIP(cfg.Server.RateLimitPerMinute), Deadline(requestTimeout)).
+		Get("/s/{token}", handlers.SharedPage(store))
+
+	// Headless servers leave the web UI to a frontend of the user's own.
+	if cfg.Server.Headless {
+		return r
+	}
+
 	// Serve React SPA from the embedded dist/ directory.
 	distContent, _ := fs.Sub(distFS, "dist")
 	fileServer := http.FileServer(http.FS(distContent))
@@ -0,0 +1,90 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/hoanghai1803/apricot/internal/config"
+	"github.com/hoanghai1803/apricot/internal/feeds"
+	"github.com/hoanghai1803/apricot/internal/jobs"
+	"github.com/hoanghai1803/apricot/internal/storage"
+)
+
+func TestNewRouter_Headless(t *testing.T) {
+	db, err := storage.OpenDatabase(":memory:")
+	if err != nil {
+		t.Fatalf("opening test db: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	if err := storage.RunMigrations(db); err != nil {
+		t.Fatalf("running migrations: %v", err)
+	}
+	store := storage.NewSQLiteStore(db)
+
+	for _, headless := range []bool{false, true} {
+		cfg := &config.Config{Server: config.ServerConfig{
+			Headless:                headless,
+			RequestTimeoutSeconds:   5,
+			FetchTimeoutSeconds:     5,
+			DiscoveryTimeoutSeconds: 5,
+		}}
+		router := NewRouter(store, nil, feeds.NewFetcher(nil), nil, nil, jobs.NewManager(store, 1), nil, nil, nil, nil, nil, cfg)
+
+		wantUI := http.StatusOK
+		if headless {
+			wantUI = http.StatusNotFound
+		}
+		for path, want := range map[string]int{
+			"/":                  wantUI,
+			"/reading-list":      wantUI,
+			"/api/reading-list":  http.StatusOK,
+			"/s/no-such-share":   http.StatusNotFound,
+			"/api/no-such-route": http.StatusNotFound,
+		} {
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+			if w.Code != want {
+				t.Errorf("headless=%v: GET %s: status %d, want %d", headless, path, w.Code, want)
+			}
+		}
+	}
+}
+
+func TestNewRouter_PublicRoutesLimitedByAddress(t *testing.T) {
+	db, err := storage.OpenDatabase(":memory:")
+	if err != nil {
+		t.Fatalf("opening test db: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	if err := storage.RunMigrations(db); err != nil {
+		t.Fatalf("running migrations: %v", err)
+	}
+	store := storage.NewSQLiteStore(db)
+
+	cfg := &config.Config{Server: config.ServerConfig{
+		AuthToken:               "secret",
+		RateLimitPerMinute:      1,
+		RequestTimeoutSeconds:   5,
+		FetchTimeoutSeconds:     5,
+		DiscoveryTimeoutSeconds: 5,
+	}}
+	router := NewRouter(store, nil, feeds.NewFetcher(nil), nil, nil, jobs.NewManager(store, 1), nil, nil, nil, nil, nil, cfg)
+
+	// Auth lets these through without a token, so inventing a new one for
+	// each request must not buy a fresh limit.
+	for _, path := range []string{"/s/no-such-share", "/save?url=https://example.com/post"} {
+		var codes []int
+		for _, token := range []string{"made-up-1", "made-up-2"} {
+			r := httptest.NewRequest(http.MethodGet, path, nil)
+			r.Header.Set("Authorization", "Bearer "+token)
+			r.Header.Set("Sec-Fetch-Site", "cross-site") // as from the bookmarklet
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, r)
+			codes = append(codes, w.Code)
+		}
+		if codes[1] != http.StatusTooManyRequests {
+			t.Errorf("GET %s: statuses %v, want the second rate limited", path, codes)
+		}
+	}
+}
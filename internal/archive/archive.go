@@ -0,0 +1,388 @@
+// Package archive reads and writes Apricot export archives.
+//
+// An archive is a JSON document with a manifest and one section per kind of
+// record. The manifest records the archive format version, the database
+// schema version of the exporting instance, and a row count and SHA-256
+// checksum for each section, so that a truncated, edited, or incompatible
+// archive is rejected before anything is imported.
+//
+// Archives are written one record per line with the manifest last, so an
+// export can be streamed and one cut short fails verification.
+package archive
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"iter"
+	"time"
+
+	"github.com/hoanghai1803/apricot/internal/models"
+)
+
+// FormatVersion is the version of the archive layout written by Write.
+// Bump it whenever a section is added, removed, or changes shape.
+//
+// Format 2 added the blogs and tags sections.
+const FormatVersion = 2
+
+// MinFormatVersion is the oldest archive format Read accepts.
+const MinFormatVersion = 1
+
+// App identifies archives written by Apricot.
+const App = "apricot"
+
+// Section names, as used for the top-level keys and in the manifest.
+const (
+	SectionSources     = "sources"
+	SectionBlogs       = "blogs"
+	SectionReadingList = "reading_list"
+	SectionTags        = "tags"
+	SectionPreferences = "preferences"
+)
+
+// sectionSince maps sections added after the first format to the format
+// that introduced them. Older archives are read without them.
+var sectionSince = map[string]int{
+	SectionBlogs: 2,
+	SectionTags:  2,
+}
+
+// ErrInvalid is wrapped by errors for archives that are malformed or fail
+// verification.
+var ErrInvalid = errors.New("invalid archive")
+
+// ErrIncompatible is wrapped by errors for well-formed archives that this
+// version of Apricot cannot import. The message says how to migrate.
+var ErrIncompatible = errors.New("incompatible archive")
+
+// Manifest describes an archive's contents.
+type Manifest struct {
+	App           string            `json:"app"`
+	FormatVersion int               `json:"format_version"`
+	SchemaVersion int               `json:"schema_version"`
+	ExportedAt    time.Time         `json:"exported_at"`
+	Counts        map[string]int    `json:"counts"`
+	Checksums     map[string]string `json:"checksums"` // "sha256:<hex>" of each section's compact JSON
+}
+
+// document is the on-disk layout of an archive. Sections are kept raw so
+// that checksums are computed over exactly the bytes that were read.
+type document struct {
+	Manifest    *Manifest       `json:"manifest"`
+	Sources     json.RawMessage `json:"sources"`
+	Blogs       json.RawMessage `json:"blogs"`
+	ReadingList json.RawMessage `json:"reading_list"`
+	Tags        json.RawMessage `json:"tags"`
+	Preferences json.RawMessage `json:"preferences"`
+}
+
+// Write encodes data as an archive to w. schemaVersion is the schema
+// version of the exporting database.
+func Write(w io.Writer, data *models.ArchiveData, schemaVersion int, exportedAt time.Time) error {
+	return WriteStream(w, &models.ArchiveStream{
+		Sources:     records(data.Sources),
+		Blogs:       records(data.Blogs),
+		ReadingList: records(data.ReadingList),
+		Tags:        records(data.Tags),
+		Preferences: data.Preferences,
+	}, schemaVersion, exportedAt)
+}
+
+// records returns an iterator over list that never yields an error.
+func records[T any](list []T) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		for _, rec := range list {
+			if !yield(rec, nil) {
+				return
+			}
+		}
+	}
+}
+
+// WriteStream encodes data as an archive to w a record at a time, as the
+// iterators yield them. schemaVersion is the schema version of the
+// exporting database. If an iterator yields an error, writing stops and
+// the archive is left without its manifest.
+func WriteStream(w io.Writer, data *models.ArchiveStream, schemaVersion int, exportedAt time.Time) error {
+	aw := &archiveWriter{
+		w: bufio.NewWriter(w),
+		m: &Manifest{
+			App:           App,
+			FormatVersion: FormatVersion,
+			SchemaVersion: schemaVersion,
+			ExportedAt:    exportedAt.UTC(),
+			Counts:        make(map[string]int, 5),
+			Checksums:     make(map[string]string, 5),
+		},
+	}
+
+	aw.raw("{")
+	writeList(aw, SectionSources, data.Sources)
+	writeList(aw, SectionBlogs, data.Blogs)
+	writeList(aw, SectionReadingList, data.ReadingList)
+	writeList(aw, SectionTags, data.Tags)
+
+	prefs := data.Preferences
+	if prefs == nil {
+		prefs = map[string]json.RawMessage{}
+	}
+	aw.begin(SectionPreferences)
+	aw.encode(SectionPreferences, prefs)
+	aw.end(SectionPreferences, len(prefs))
+
+	// The manifest goes last so that its checksums cover what came before.
+	manifest, err := json.MarshalIndent(aw.m, "  ", "  ")
+	if err != nil {
+		return fmt.Errorf("encoding manifest: %w", err)
+	}
+	aw.key("manifest")
+	aw.raw(string(manifest) + "\n}\n")
+
+	if aw.err != nil {
+		return aw.err
+	}
+	if err := aw.w.Flush(); err != nil {
+		return fmt.Errorf("writing archive: %w", err)
+	}
+	return nil
+}
+
+// writeList writes a section holding a list of records, one per line.
+func writeList[T any](aw *archiveWriter, name string, records iter.Seq2[T, error]) {
+	aw.begin(name)
+	aw.hashed("[")
+	n := 0
+	if records != nil {
+		for rec, err := range records {
+			if err != nil && aw.err == nil {
+				aw.err = fmt.Errorf("reading %s: %w", name, err)
+			}
+			if aw.err != nil {
+				break
+			}
+			if n > 0 {
+				aw.hashed(",")
+			}
+			aw.raw("\n    ")
+			aw.encode(name, rec)
+			n++
+		}
+	}
+	if n > 0 {
+		aw.raw("\n  ")
+	}
+	aw.hashed("]")
+	aw.end(name, n)
+}
+
+// archiveWriter writes the top-level object of an archive, recording the
+// count and checksum of each section in the manifest. Checksums cover only
+// the compact JSON of a section, not the whitespace between records. The
+// first error sticks and turns later writes into no-ops.
+type archiveWriter struct {
+	w    *bufio.Writer
+	m    *Manifest
+	sum  hash.Hash // checksum of the section being written
+	keys int       // top-level members written so far
+	err  error
+}
+
+// raw writes s without adding it to the section checksum.
+func (a *archiveWriter) raw(s string) {
+	if a.err != nil {
+		return
+	}
+	if _, err := a.w.WriteString(s); err != nil {
+		a.err = fmt.Errorf("writing archive: %w", err)
+	}
+}
+
+// hashed writes s and adds it to the section checksum.
+func (a *archiveWriter) hashed(s string) {
+	a.raw(s)
+	a.sum.Write([]byte(s))
+}
+
+// encode writes v as compact JSON and adds it to the section checksum.
+func (a *archiveWriter) encode(section string, v any) {
+	if a.err != nil {
+		return
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		a.err = fmt.Errorf("encoding %s: %w", section, err)
+		return
+	}
+	a.hashed(string(b))
+}
+
+// key starts the next top-level member.
+func (a *archiveWriter) key(name string) {
+	if a.keys > 0 {
+		a.raw(",")
+	}
+	a.keys++
+	a.raw("\n  \"" + name + "\": ")
+}
+
+// begin starts a section.
+func (a *archiveWriter) begin(name string) {
+	a.key(name)
+	a.sum = sha256.New()
+}
+
+// end records a finished section in the manifest.
+func (a *archiveWriter) end(name string, count int) {
+	a.m.Counts[name] = count
+	a.m.Checksums[name] = fmt.Sprintf("sha256:%x", a.sum.Sum(nil))
+}
+
+// Read decodes and verifies an archive from r. schemaVersion is the schema
+// version of the importing database; archives exported from a newer schema
+// are refused. Errors wrap ErrInvalid or ErrIncompatible, and also any
+// error reading r.
+func Read(r io.Reader, schemaVersion int) (*models.ArchiveData, *Manifest, error) {
+	var doc document
+	if err := json.NewDecoder(r).Decode(&doc); err != nil {
+		return nil, nil, fmt.Errorf("%w: decoding JSON: %w", ErrInvalid, err)
+	}
+
+	m := doc.Manifest
+	if m == nil || m.App != App {
+		return nil, nil, fmt.Errorf("%w: not an Apricot export (missing manifest)", ErrInvalid)
+	}
+	if err := checkVersions(m, schemaVersion); err != nil {
+		return nil, nil, err
+	}
+
+	for name, section := range doc.sections() {
+		if len(section) == 0 {
+			if sectionSince[name] > m.FormatVersion {
+				continue
+			}
+			return nil, nil, fmt.Errorf("%w: missing section %q", ErrInvalid, name)
+		}
+		want, ok := m.Checksums[name]
+		if !ok {
+			return nil, nil, fmt.Errorf("%w: manifest has no checksum for section %q", ErrInvalid, name)
+		}
+		if got := checksum(section); got != want {
+			return nil, nil, fmt.Errorf("%w: checksum mismatch in section %q; the file was modified or truncated", ErrInvalid, name)
+		}
+	}
+
+	var data models.ArchiveData
+	if err := json.Unmarshal(doc.Sources, &data.Sources); err != nil {
+		return nil, nil, fmt.Errorf("%w: decoding sources: %v", ErrInvalid, err)
+	}
+	if len(doc.Blogs) > 0 {
+		if err := json.Unmarshal(doc.Blogs, &data.Blogs); err != nil {
+			return nil, nil, fmt.Errorf("%w: decoding blogs: %v", ErrInvalid, err)
+		}
+	}
+	if err := json.Unmarshal(doc.ReadingList, &data.ReadingList); err != nil {
+		return nil, nil, fmt.Errorf("%w: decoding reading list: %v", ErrInvalid, err)
+	}
+	if len(doc.Tags) > 0 {
+		if err := json.Unmarshal(doc.Tags, &data.Tags); err != nil {
+			return nil, nil, fmt.Errorf("%w: decoding tags: %v", ErrInvalid, err)
+		}
+	}
+	if err := json.Unmarshal(doc.Preferences, &data.Preferences); err != nil {
+		return nil, nil, fmt.Errorf("%w: decoding preferences: %v", ErrInvalid, err)
+	}
+
+	counts := map[string]int{
+		SectionSources:     len(data.Sources),
+		SectionBlogs:       len(data.Blogs),
+		SectionReadingList: len(data.ReadingList),
+		SectionTags:        len(data.Tags),
+		SectionPreferences: len(data.Preferences),
+	}
+	for name, got := range counts {
+		if want := m.Counts[name]; got != want {
+			return nil, nil, fmt.Errorf("%w: section %q has %d records, manifest says %d", ErrInvalid, name, got, want)
+		}
+	}
+
+	if err := validate(&data); err != nil {
+		return nil, nil, err
+	}
+	return &data, m, nil
+}
+
+// validate checks the records of an archive for missing keys and invalid
+// values.
+func validate(data *models.ArchiveData) error {
+	for i, src := range data.Sources {
+		if src.FeedURL == "" {
+			return fmt.Errorf("%w: source %d has no feed_url", ErrInvalid, i)
+		}
+	}
+	for i, post := range data.Blogs {
+		if post.URL == "" || post.Title == "" {
+			return fmt.Errorf("%w: blog %d needs a url and a title", ErrInvalid, i)
+		}
+	}
+	for i, item := range data.ReadingList {
+		if item.URL == "" || item.Title == "" {
+			return fmt.Errorf("%w: reading list item %d needs a url and a title", ErrInvalid, i)
+		}
+		switch item.Status {
+		case "", "unread", "reading", "read", "archived":
+		default:
+			return fmt.Errorf("%w: reading list item %q has invalid status %q", ErrInvalid, item.URL, item.Status)
+		}
+	}
+	for i, tag := range data.Tags {
+		if tag.Name == "" {
+			return fmt.Errorf("%w: tag %d has no name", ErrInvalid, i)
+		}
+	}
+	return nil
+}
+
+// checkVersions refuses archives whose format or schema this version of
+// Apricot cannot import, with a hint on how to migrate.
+func checkVersions(m *Manifest, schemaVersion int) error {
+	switch {
+	case m.FormatVersion > FormatVersion:
+		return fmt.Errorf("%w: archive format %d is newer than the supported format %d; upgrade Apricot to import it",
+			ErrIncompatible, m.FormatVersion, FormatVersion)
+	case m.FormatVersion < MinFormatVersion:
+		return fmt.Errorf("%w: archive format %d is no longer supported (current format %d); import it with the Apricot version that wrote it, upgrade that instance, and export again",
+			ErrIncompatible, m.FormatVersion, FormatVersion)
+	case m.SchemaVersion > schemaVersion:
+		return fmt.Errorf("%w: archive was exported from database schema %d but this database is at schema %d; upgrade Apricot to import it",
+			ErrIncompatible, m.SchemaVersion, schemaVersion)
+	}
+	return nil
+}
+
+// sections returns the raw sections of doc keyed by name.
+func (doc *document) sections() map[string]json.RawMessage {
+	return map[string]json.RawMessage{
+		SectionSources:     doc.Sources,
+		SectionBlogs:       doc.Blogs,
+		SectionReadingList: doc.ReadingList,
+		SectionTags:        doc.Tags,
+		SectionPreferences: doc.Preferences,
+	}
+}
+
+// checksum returns the SHA-256 checksum of a section's compact JSON, so
+// that re-indenting an archive does not invalidate it.
+func checksum(section json.RawMessage) string {
+	var buf bytes.Buffer
+	if err := json.Compact(&buf, section); err != nil {
+		buf.Reset()
+		buf.Write(section)
+	}
+	return fmt.Sprintf("sha256:%x", sha256.Sum256(buf.Bytes()))
+}
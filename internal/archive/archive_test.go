@@ -0,0 +1,205 @@
+package archive
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hoanghai1803/apricot/internal/models"
+)
+
+func testData() *models.ArchiveData {
+	return &models.ArchiveData{
+		Sources: []models.ArchiveSource{
+			{Name: "Test Blog", Company: "Test", FeedURL: "https://test.com/feed", SiteURL: "https://test.com", IsActive: true},
+		},
+		Blogs: []models.ArchivePost{
+			{URL: "https://test.com/older", Title: "Older", SourceFeedURL: "https://test.com/feed", Summary: "A summary."},
+		},
+		ReadingList: []models.ArchiveItem{
+			{ArchivePost: models.ArchivePost{URL: "https://test.com/post", Title: "Post", SourceFeedURL: "https://test.com/feed"},
+				Status: "read", Tags: []string{"go"}, AddedAt: time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)},
+		},
+		Tags:        []models.ArchiveTag{{Name: "go", Color: "#00add8"}},
+		Preferences: map[string]json.RawMessage{"topics": json.RawMessage(`"databases"`)},
+	}
+}
+
+func writeArchive(t *testing.T, data *models.ArchiveData, schemaVersion int) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := Write(&buf, data, schemaVersion, time.Now()); err != nil {
+		t.Fatalf("Write() error: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func TestWriteRead_RoundTrip(t *testing.T) {
+	raw := writeArchive(t, testData(), 17)
+
+	data, m, err := Read(bytes.NewReader(raw), 17)
+	if err != nil {
+		t.Fatalf("Read() error: %v", err)
+	}
+	if m.FormatVersion != FormatVersion || m.SchemaVersion != 17 {
+		t.Errorf("manifest versions = %d/%d, want %d/17", m.FormatVersion, m.SchemaVersion, FormatVersion)
+	}
+	if m.Counts[SectionReadingList] != 1 {
+		t.Errorf("reading list count = %d, want 1", m.Counts[SectionReadingList])
+	}
+	if len(data.ReadingList) != 1 || data.ReadingList[0].URL != "https://test.com/post" {
+		t.Errorf("unexpected reading list: %+v", data.ReadingList)
+	}
+	if len(data.Blogs) != 1 || data.Blogs[0].Summary != "A summary." {
+		t.Errorf("unexpected blogs: %+v", data.Blogs)
+	}
+	if len(data.Tags) != 1 || data.Tags[0].Color != "#00add8" {
+		t.Errorf("unexpected tags: %+v", data.Tags)
+	}
+	if string(data.Preferences["topics"]) != `"databases"` {
+		t.Errorf("topics = %s, want %q", data.Preferences["topics"], "databases")
+	}
+}
+
+func TestWrite_OneRecordPerLine(t *testing.T) {
+	raw := string(writeArchive(t, testData(), 17))
+
+	if !strings.Contains(raw, "\n    {\"url\":\"https://test.com/post\"") {
+		t.Errorf("reading list item not on its own line:\n%s", raw)
+	}
+	if strings.Index(raw, `"manifest"`) < strings.Index(raw, `"preferences"`) {
+		t.Errorf("manifest is not the last section:\n%s", raw)
+	}
+}
+
+func TestWriteStream_StopsOnError(t *testing.T) {
+	failing := func(yield func(models.ArchivePost, error) bool) {
+		if yield(models.ArchivePost{URL: "https://test.com/first", Title: "First"}, nil) {
+			yield(models.ArchivePost{}, errors.New("connection lost"))
+		}
+	}
+
+	var buf bytes.Buffer
+	err := WriteStream(&buf, &models.ArchiveStream{Blogs: failing}, 17, time.Now())
+	if err == nil || !strings.Contains(err.Error(), "connection lost") {
+		t.Fatalf("WriteStream() error = %v, want the iterator's error", err)
+	}
+	if strings.Contains(buf.String(), `"manifest"`) {
+		t.Errorf("archive has a manifest after a failed read:\n%s", buf.String())
+	}
+}
+
+func TestRead_FormatOneArchive(t *testing.T) {
+	sources := `[{"name":"Test Blog","company":"Test","feed_url":"https://test.com/feed","site_url":"https://test.com","is_active":true}]`
+	items := `[{"url":"https://test.com/post","title":"Post","status":"unread","progress":0,"tags":[],"added_at":"2025-01-02T03:04:05Z"}]`
+	prefs := `{}`
+	raw := `{"manifest":{"app":"apricot","format_version":1,"schema_version":17,"exported_at":"2025-01-02T03:04:05Z",` +
+		`"counts":{"sources":1,"reading_list":1,"preferences":0},` +
+		`"checksums":{"sources":"` + checksum(json.RawMessage(sources)) +
+		`","reading_list":"` + checksum(json.RawMessage(items)) +
+		`","preferences":"` + checksum(json.RawMessage(prefs)) + `"}},` +
+		`"sources":` + sources + `,"reading_list":` + items + `,"preferences":` + prefs + `}`
+
+	data, _, err := Read(strings.NewReader(raw), 17)
+	if err != nil {
+		t.Fatalf("Read() error: %v", err)
+	}
+	if len(data.ReadingList) != 1 || len(data.Blogs) != 0 || len(data.Tags) != 0 {
+		t.Errorf("unexpected data: %+v", data)
+	}
+}
+
+func TestRead_AcceptsReformattedArchive(t *testing.T) {
+	raw := writeArchive(t, testData(), 17)
+
+	var compact bytes.Buffer
+	if err := json.Compact(&compact, raw); err != nil {
+		t.Fatalf("compacting archive: %v", err)
+	}
+	if _, _, err := Read(&compact, 17); err != nil {
+		t.Errorf("Read() of compacted archive error: %v", err)
+	}
+}
+
+func TestRead_Rejects(t *testing.T) {
+	valid := string(writeArchive(t, testData(), 17))
+
+	tests := []struct {
+		name    string
+		archive string
+		schema  int
+		want    error
+	}{
+		{
+			name:    "not json",
+			archive: "not json",
+			schema:  17,
+			want:    ErrInvalid,
+		},
+		{
+			name:    "no manifest",
+			archive: `{"sources": []}`,
+			schema:  17,
+			want:    ErrInvalid,
+		},
+		{
+			name:    "modified section",
+			archive: strings.Replace(valid, `"Post"`, `"Edited"`, 1),
+			schema:  17,
+			want:    ErrInvalid,
+		},
+		{
+			name:    "newer schema",
+			archive: valid,
+			schema:  16,
+			want:    ErrIncompatible,
+		},
+		{
+			name:    "newer format",
+			archive: strings.Replace(valid, `"format_version": 2`, `"format_version": 3`, 1),
+			schema:  17,
+			want:    ErrIncompatible,
+		},
+		{
+			name:    "missing blogs section",
+			archive: strings.Replace(valid, `"blogs": [`, `"posts": [`, 1),
+			schema:  17,
+			want:    ErrInvalid,
+		},
+		{
+			name:    "count mismatch",
+			archive: strings.Replace(valid, `"reading_list": 1`, `"reading_list": 2`, 1),
+			schema:  17,
+			want:    ErrInvalid,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := Read(strings.NewReader(tt.archive), tt.schema)
+			if !errors.Is(err, tt.want) {
+				t.Errorf("Read() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestRead_OlderSchemaAccepted(t *testing.T) {
+	raw := writeArchive(t, testData(), 12)
+	if _, _, err := Read(bytes.NewReader(raw), 17); err != nil {
+		t.Errorf("Read() of archive from older schema error: %v", err)
+	}
+}
+
+func TestRead_InvalidRecords(t *testing.T) {
+	data := testData()
+	data.ReadingList[0].Status = "skimmed"
+	raw := writeArchive(t, data, 17)
+
+	if _, _, err := Read(bytes.NewReader(raw), 17); !errors.Is(err, ErrInvalid) {
+		t.Errorf("Read() error = %v, want %v", err, ErrInvalid)
+	}
+}
@@ -0,0 +1,284 @@
+package archive
+
+import (
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hoanghai1803/apricot/internal/models"
+)
+
+// Import formats accepted by ReadAny besides Apricot's own archives.
+const (
+	FormatApricot    = "apricot"
+	FormatInstapaper = "instapaper" // Instapaper's CSV export
+	FormatOmnivore   = "omnivore"   // Omnivore's export zip, or one of its metadata JSON files
+)
+
+// maxOmnivoreSize caps an Omnivore export zip, which has to be read into
+// memory. Exports include every saved page's content, so they run large.
+const maxOmnivoreSize = 256 << 20
+
+// ReadAny reads an import in the given format, or, if format is empty, in
+// whichever format the data looks like: a zip or a JSON array is taken for
+// Omnivore, a JSON object for an Apricot archive, and anything else for an
+// Instapaper CSV. Only Apricot archives have a manifest; for the others it
+// is nil, and every post lands on the reading list as a user-added post.
+func ReadAny(r io.Reader, format string, schemaVersion int) (*models.ArchiveData, *Manifest, error) {
+	br := bufio.NewReader(r)
+	if format == "" {
+		format = detectFormat(br)
+	}
+
+	var (
+		data *models.ArchiveData
+		err  error
+	)
+	switch format {
+	case FormatApricot:
+		return Read(br, schemaVersion)
+	case FormatInstapaper:
+		data, err = ReadInstapaper(br)
+	case FormatOmnivore:
+		data, err = ReadOmnivore(br)
+	default:
+		return nil, nil, fmt.Errorf("%w: unknown import format %q", ErrInvalid, format)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := validate(data); err != nil {
+		return nil, nil, err
+	}
+	return data, nil, nil
+}
+
+// detectFormat guesses the format of the data in br from its first bytes.
+func detectFormat(br *bufio.Reader) string {
+	head, _ := br.Peek(512)
+	head = bytes.TrimLeft(head, "\ufeff \t\r\n")
+	switch {
+	case bytes.HasPrefix(head, []byte("PK\x03\x04")), bytes.HasPrefix(head, []byte("[")):
+		return FormatOmnivore
+	case bytes.HasPrefix(head, []byte("{")):
+		return FormatApricot
+	default:
+		return FormatInstapaper
+	}
+}
+
+// ReadInstapaper converts an Instapaper CSV export (columns URL, Title,
+// Selection, Folder, Timestamp, and in newer exports Tags) to reading list
+// items. Posts in the Archive folder are marked read, with no time of
+// reading, which the export lacks; Starred ones get the
+// "starred" tag, and those in folders of their own a tag named after the
+// folder. The selection becomes the post's description.
+func ReadInstapaper(r io.Reader) (*models.ArchiveData, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	header, err := cr.Read()
+	if err != nil {
+		return nil, fmt.Errorf("%w: reading Instapaper CSV header: %w", ErrInvalid, err)
+	}
+	col := make(map[string]int, len(header))
+	for i, name := range header {
+		col[strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))] = i
+	}
+	if _, ok := col["url"]; !ok {
+		return nil, fmt.Errorf("%w: not an Instapaper export (no URL column)", ErrInvalid)
+	}
+	field := func(rec []string, name string) string {
+		if i, ok := col[name]; ok && i < len(rec) {
+			return strings.TrimSpace(rec[i])
+		}
+		return ""
+	}
+
+	data := &models.ArchiveData{}
+	for line := 2; ; line++ {
+		rec, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: reading Instapaper CSV: %w", ErrInvalid, err)
+		}
+		item, ok := importedItem(field(rec, "url"), field(rec, "title"))
+		if !ok {
+			continue
+		}
+		item.Description = field(rec, "selection")
+		if ts, err := strconv.ParseInt(field(rec, "timestamp"), 10, 64); err == nil && ts > 0 {
+			item.AddedAt = time.Unix(ts, 0).UTC()
+		}
+
+		switch folder := field(rec, "folder"); strings.ToLower(folder) {
+		case "", "unread":
+		case "archive":
+			item.Status = "read"
+		case "starred":
+			item.Tags = append(item.Tags, "starred")
+		default:
+			item.Tags = append(item.Tags, folder)
+		}
+		if raw := field(rec, "tags"); raw != "" && raw != "[]" {
+			var tags []string
+			if err := json.Unmarshal([]byte(raw), &tags); err != nil {
+				return nil, fmt.Errorf("%w: line %d: tags %q are not a JSON list", ErrInvalid, line, raw)
+			}
+			item.Tags = append(item.Tags, tags...)
+		}
+		data.ReadingList = append(data.ReadingList, item)
+	}
+	return data, nil
+}
+
+// omnivoreItem is a saved page in an Omnivore export's metadata files.
+type omnivoreItem struct {
+	Slug            string    `json:"slug"`
+	Title           string    `json:"title"`
+	Description     string    `json:"description"`
+	URL             string    `json:"url"`
+	State           string    `json:"state"`
+	ReadingProgress float64   `json:"readingProgress"`
+	Labels          []string  `json:"labels"`
+	SavedAt         time.Time `json:"savedAt"`
+	UpdatedAt       time.Time `json:"updatedAt"`
+	PublishedAt     time.Time `json:"publishedAt"`
+}
+
+// ReadOmnivore converts an Omnivore export to reading list items. It takes
+// the export zip, whose metadata_*.json files list the saved pages and
+// whose highlights/<slug>.md files become the items' notes, or a single
+// metadata file. Labels become tags; archived pages are marked read, and
+// pages partly read are marked as being read, keeping their progress. A
+// read page's last update stands in for when it was read.
+// Deleted pages are left out.
+func ReadOmnivore(r io.Reader) (*models.ArchiveData, error) {
+	raw, err := io.ReadAll(io.LimitReader(r, maxOmnivoreSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("%w: reading Omnivore export: %w", ErrInvalid, err)
+	}
+	if len(raw) > maxOmnivoreSize {
+		return nil, fmt.Errorf("%w: Omnivore export is larger than %d MiB", ErrInvalid, maxOmnivoreSize>>20)
+	}
+
+	var (
+		items      []omnivoreItem
+		highlights = map[string]string{}
+	)
+	if !bytes.HasPrefix(raw, []byte("PK\x03\x04")) {
+		if err := json.Unmarshal(raw, &items); err != nil {
+			return nil, fmt.Errorf("%w: decoding Omnivore metadata: %v", ErrInvalid, err)
+		}
+	} else {
+		zr, err := zip.NewReader(bytes.NewReader(raw), int64(len(raw)))
+		if err != nil {
+			return nil, fmt.Errorf("%w: opening Omnivore export: %v", ErrInvalid, err)
+		}
+		found := false
+		for _, f := range zr.File {
+			name := path.Base(f.Name)
+			switch {
+			case strings.HasPrefix(name, "metadata_") && strings.HasSuffix(name, ".json"):
+				var page []omnivoreItem
+				if err := readZipJSON(f, &page); err != nil {
+					return nil, fmt.Errorf("%w: decoding %s: %v", ErrInvalid, f.Name, err)
+				}
+				items = append(items, page...)
+				found = true
+			case path.Base(path.Dir(f.Name)) == "highlights" && strings.HasSuffix(name, ".md"):
+				b, err := readZipFile(f)
+				if err != nil {
+					return nil, fmt.Errorf("%w: reading %s: %v", ErrInvalid, f.Name, err)
+				}
+				highlights[strings.TrimSuffix(name, ".md")] = strings.TrimSpace(string(b))
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("%w: not an Omnivore export (no metadata files)", ErrInvalid)
+		}
+	}
+
+	data := &models.ArchiveData{}
+	for _, o := range items {
+		if strings.EqualFold(o.State, "deleted") {
+			continue
+		}
+		item, ok := importedItem(o.URL, o.Title)
+		if !ok {
+			continue
+		}
+		item.Description = o.Description
+		item.Tags = append(item.Tags, o.Labels...)
+		item.Notes = highlights[o.Slug]
+		if !o.SavedAt.IsZero() {
+			item.AddedAt = o.SavedAt.UTC()
+		}
+		if !o.PublishedAt.IsZero() {
+			published := o.PublishedAt.UTC()
+			item.PublishedAt = &published
+		}
+		item.Progress = min(100, max(0, int(o.ReadingProgress)))
+		switch {
+		case strings.EqualFold(o.State, "archived") || item.Progress >= 100:
+			item.Status = "read"
+			if !o.UpdatedAt.IsZero() {
+				readAt := o.UpdatedAt.UTC()
+				item.ReadAt = &readAt
+			}
+		case item.Progress > 0:
+			item.Status = "reading"
+		}
+		data.ReadingList = append(data.ReadingList, item)
+	}
+	return data, nil
+}
+
+// importedItem returns an unread reading list item for a post saved in
+// another app, or false if rawURL is not an HTTP or HTTPS URL. The post is
+// user-added, with its host as its source; title defaults to the URL.
+func importedItem(rawURL, title string) (models.ArchiveItem, bool) {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return models.ArchiveItem{}, false
+	}
+	if title == "" {
+		title = rawURL
+	}
+	return models.ArchiveItem{
+		ArchivePost: models.ArchivePost{URL: rawURL, Title: title, CustomSource: u.Hostname()},
+		Status:      "unread",
+		Tags:        []string{},
+		AddedAt:     time.Now().UTC(),
+	}, true
+}
+
+// readZipJSON decodes the JSON file f into v.
+func readZipJSON(f *zip.File, v any) error {
+	b, err := readZipFile(f)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, v)
+}
+
+// readZipFile returns the contents of f.
+func readZipFile(f *zip.File) ([]byte, error) {
+	rc, err := f.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	return io.ReadAll(rc)
+}
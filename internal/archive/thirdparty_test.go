@@ -0,0 +1,105 @@
+package archive
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+const instapaperCSV = "\ufeffURL,Title,Selection,Folder,Timestamp,Tags\n" +
+	"https://a.example/one,One,A quote,Unread,1700000000,\"[\"\"go\"\"]\"\n" +
+	"https://a.example/two,,,Archive,1700000100,[]\n" +
+	"https://a.example/three,Three,,Starred,1700000200,\n" +
+	"https://a.example/four,Four,,Databases,1700000300,\n" +
+	"not a url,Five,,Unread,1700000400,\n"
+
+func TestReadAny_Instapaper(t *testing.T) {
+	data, manifest, err := ReadAny(strings.NewReader(instapaperCSV), "", 1)
+	if err != nil {
+		t.Fatalf("ReadAny() error: %v", err)
+	}
+	if manifest != nil {
+		t.Errorf("manifest = %+v, want none", manifest)
+	}
+	items := data.ReadingList
+	if len(items) != 4 {
+		t.Fatalf("got %d items, want 4 (the bad URL skipped)", len(items))
+	}
+	want := []struct{ title, status, tags string }{
+		{"One", "unread", "go"},
+		{"https://a.example/two", "read", ""},
+		{"Three", "unread", "starred"},
+		{"Four", "unread", "Databases"},
+	}
+	for i, w := range want {
+		got := items[i]
+		if got.Title != w.title || got.Status != w.status || strings.Join(got.Tags, ",") != w.tags {
+			t.Errorf("item %d = %q %s [%s], want %q %s [%s]", i,
+				got.Title, got.Status, strings.Join(got.Tags, ","), w.title, w.status, w.tags)
+		}
+	}
+	if items[0].Description != "A quote" || items[0].CustomSource != "a.example" || items[0].AddedAt.Unix() != 1700000000 {
+		t.Errorf("item 0 = %+v, want the selection, host, and timestamp", items[0])
+	}
+}
+
+func TestReadAny_Omnivore(t *testing.T) {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for name, body := range map[string]string{
+		"metadata_0_to_2.json": `[
+			{"slug": "one", "title": "One", "url": "https://b.example/one", "state": "Archived",
+			 "readingProgress": 40, "labels": ["Newsletter"], "savedAt": "2024-05-01T10:00:00Z", "updatedAt": "2024-05-03T10:00:00Z"},
+			{"slug": "two", "title": "Two", "url": "https://b.example/two", "state": "Succeeded", "readingProgress": 25.5}
+		]`,
+		"metadata_2_to_3.json": `[{"slug": "gone", "title": "Gone", "url": "https://b.example/gone", "state": "Deleted"}]`,
+		"highlights/one.md":    "> a highlight\n",
+		"content/one.html":     "<p>ignored</p>",
+	} {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.Write([]byte(body)) //nolint:errcheck
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	data, _, err := ReadAny(bytes.NewReader(buf.Bytes()), "", 1)
+	if err != nil {
+		t.Fatalf("ReadAny() error: %v", err)
+	}
+	items := data.ReadingList
+	if len(items) != 2 {
+		t.Fatalf("got %d items, want 2 (the deleted page left out)", len(items))
+	}
+	byTitle := map[string]int{items[0].Title: 0, items[1].Title: 1}
+	one, two := items[byTitle["One"]], items[byTitle["Two"]]
+	if one.Status != "read" || one.ReadAt == nil || one.Notes != "> a highlight" || strings.Join(one.Tags, ",") != "Newsletter" {
+		t.Errorf("archived page = %+v, want read with its highlights and label", one)
+	}
+	if two.Status != "reading" || two.Progress != 25 {
+		t.Errorf("partly read page = %+v, want reading at 25%%", two)
+	}
+
+	// A single metadata file works too.
+	data, _, err = ReadAny(strings.NewReader(`[{"title": "Solo", "url": "https://b.example/solo"}]`), "", 1)
+	if err != nil || len(data.ReadingList) != 1 || data.ReadingList[0].Status != "unread" {
+		t.Errorf("ReadAny(metadata file) = %+v, %v; want one unread item", data, err)
+	}
+}
+
+func TestReadAny_Invalid(t *testing.T) {
+	for name, tc := range map[string]struct{ body, format string }{
+		"csv without URL column": {"Title,Folder\nOne,Unread\n", ""},
+		"zip without metadata":   {"PK\x03\x04garbage", FormatOmnivore},
+		"unknown format":         {"{}", "pocket"},
+	} {
+		if _, _, err := ReadAny(strings.NewReader(tc.body), tc.format, 1); !errors.Is(err, ErrInvalid) {
+			t.Errorf("%s: error = %v, want ErrInvalid", name, err)
+		}
+	}
+}
@@ -0,0 +1,178 @@
+// Package backup writes timestamped copies of the database to a directory,
+// on demand and on a schedule, and deletes all but the most recent ones.
+package backup
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Backup file names are the prefix, the UTC creation time in nameLayout, and
+// the suffix, so that they sort by age.
+const (
+	namePrefix = "apricot-"
+	nameSuffix = ".db"
+	nameLayout = "20060102-150405"
+)
+
+// Source is a database that can copy itself to a file that does not exist
+// yet. *storage.SQLiteStore satisfies this interface.
+type Source interface {
+	BackupTo(ctx context.Context, path string) error
+}
+
+// Backup describes a backup file.
+type Backup struct {
+	Name      string    `json:"name"`
+	Size      int64     `json:"size"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Manager writes backups of a store into a directory and keeps the newest
+// Keep of them. It is safe for concurrent use; backups are taken one at a
+// time.
+type Manager struct {
+	store Source
+	dir   string
+	keep  int
+
+	mu  sync.Mutex
+	now func() time.Time
+}
+
+// NewManager returns a Manager that writes backups of store to dir and keeps
+// the newest keep of them. keep < 1 keeps them all.
+func NewManager(store Source, dir string, keep int) *Manager {
+	return &Manager{store: store, dir: dir, keep: keep, now: time.Now}
+}
+
+// Dir returns the directory backups are written to.
+func (m *Manager) Dir() string {
+	return m.dir
+}
+
+// Run takes a backup now and then deletes the backups beyond the newest
+// Keep. A failure to delete old backups is logged, not returned.
+func (m *Manager) Run(ctx context.Context) (*Backup, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if err := os.MkdirAll(m.dir, 0o755); err != nil {
+		return nil, fmt.Errorf("creating backup directory: %w", err)
+	}
+
+	createdAt := m.now().UTC().Truncate(time.Second)
+	name := namePrefix + createdAt.Format(nameLayout) + nameSuffix
+	path := filepath.Join(m.dir, name)
+
+	// Write to a temporary file first so that a failed or interrupted
+	// backup never looks like a complete one.
+	tmp := path + ".tmp"
+	if err := os.Remove(tmp); err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("removing stale temporary backup: %w", err)
+	}
+	if err := m.store.BackupTo(ctx, tmp); err != nil {
+		os.Remove(tmp) //nolint:errcheck // best effort
+		return nil, err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp) //nolint:errcheck // best effort
+		return nil, fmt.Errorf("finishing backup: %w", err)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading backup size: %w", err)
+	}
+
+	if err := m.rotate(); err != nil {
+		slog.Warn("failed to delete old backups", "dir", m.dir, "error", err)
+	}
+	return &Backup{Name: name, Size: info.Size(), CreatedAt: createdAt}, nil
+}
+
+// List returns the backups in the directory, newest first. A missing
+// directory has no backups.
+func (m *Manager) List() ([]Backup, error) {
+	entries, err := os.ReadDir(m.dir)
+	if os.IsNotExist(err) {
+		return []Backup{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading backup directory: %w", err)
+	}
+
+	backups := []Backup{}
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || !strings.HasPrefix(name, namePrefix) || !strings.HasSuffix(name, nameSuffix) {
+			continue
+		}
+		createdAt, err := time.Parse(nameLayout, strings.TrimSuffix(strings.TrimPrefix(name, namePrefix), nameSuffix))
+		if err != nil {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			return nil, fmt.Errorf("reading backup %q: %w", name, err)
+		}
+		backups = append(backups, Backup{Name: name, Size: info.Size(), CreatedAt: createdAt})
+	}
+	sort.Slice(backups, func(i, j int) bool {
+		return backups[i].CreatedAt.After(backups[j].CreatedAt)
+	})
+	return backups, nil
+}
+
+// rotate deletes the backups beyond the newest Keep.
+func (m *Manager) rotate() error {
+	if m.keep < 1 {
+		return nil
+	}
+	backups, err := m.List()
+	if err != nil {
+		return err
+	}
+	for _, b := range backups[min(m.keep, len(backups)):] {
+		if err := os.Remove(filepath.Join(m.dir, b.Name)); err != nil {
+			return fmt.Errorf("deleting backup %q: %w", b.Name, err)
+		}
+		slog.Info("deleted old backup", "name", b.Name)
+	}
+	return nil
+}
+
+// Schedule takes a backup every interval until ctx is done. The first one
+// is taken as soon as the newest existing backup is interval old, so
+// restarts neither skip nor repeat a backup.
+func (m *Manager) Schedule(ctx context.Context, interval time.Duration) {
+	wait := time.Duration(0)
+	if backups, err := m.List(); err == nil && len(backups) > 0 {
+		wait = max(interval-m.now().Sub(backups[0].CreatedAt), 0)
+	}
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C:
+		}
+
+		b, err := m.Run(ctx)
+		if err != nil {
+			slog.Warn("scheduled backup failed", "error", err)
+		} else {
+			slog.Info("wrote scheduled backup", "name", b.Name, "bytes", b.Size)
+		}
+		timer.Reset(interval)
+	}
+}
@@ -0,0 +1,88 @@
+package backup
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/hoanghai1803/apricot/internal/storage"
+)
+
+func newTestManager(t *testing.T, keep int) *Manager {
+	t.Helper()
+	db, err := storage.OpenDatabase(":memory:")
+	if err != nil {
+		t.Fatalf("OpenDatabase: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	if err := storage.RunMigrations(db); err != nil {
+		t.Fatalf("RunMigrations: %v", err)
+	}
+	return NewManager(storage.NewSQLiteStore(db), filepath.Join(t.TempDir(), "backups"), keep)
+}
+
+func TestRun(t *testing.T) {
+	m := newTestManager(t, 3)
+	m.now = func() time.Time { return time.Date(2025, 3, 4, 5, 6, 7, 0, time.UTC) }
+
+	b, err := m.Run(context.Background())
+	if err != nil {
+		t.Fatalf("Run() error: %v", err)
+	}
+	if b.Name != "apricot-20250304-050607.db" || b.Size == 0 {
+		t.Errorf("backup = %+v, want apricot-20250304-050607.db with a size", b)
+	}
+	if _, err := os.Stat(filepath.Join(m.Dir(), b.Name)); err != nil {
+		t.Errorf("backup file: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(m.Dir(), b.Name+".tmp")); !os.IsNotExist(err) {
+		t.Errorf("temporary file left behind: %v", err)
+	}
+}
+
+func TestRun_Rotates(t *testing.T) {
+	m := newTestManager(t, 2)
+	start := time.Date(2025, 3, 4, 0, 0, 0, 0, time.UTC)
+
+	// Files that are not backups are left alone.
+	if err := os.MkdirAll(m.Dir(), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(m.Dir(), "notes.txt"), nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	for i := range 4 {
+		m.now = func() time.Time { return start.Add(time.Duration(i) * time.Hour) }
+		if _, err := m.Run(context.Background()); err != nil {
+			t.Fatalf("Run() #%d error: %v", i, err)
+		}
+	}
+	backups, err := m.List()
+	if err != nil {
+		t.Fatalf("List() error: %v", err)
+	}
+	want := []string{"apricot-20250304-030000.db", "apricot-20250304-020000.db"}
+	if len(backups) != len(want) {
+		t.Fatalf("List() = %+v, want %v", backups, want)
+	}
+	for i, name := range want {
+		if backups[i].Name != name {
+			t.Errorf("List()[%d] = %q, want %q", i, backups[i].Name, name)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(m.Dir(), "notes.txt")); err != nil {
+		t.Errorf("non-backup file was removed: %v", err)
+	}
+}
+
+func TestList_MissingDir(t *testing.T) {
+	m := newTestManager(t, 2)
+
+	backups, err := m.List()
+	if err != nil || len(backups) != 0 {
+		t.Errorf("List() = %v, %v; want no backups", backups, err)
+	}
+}
@@ -0,0 +1,156 @@
+// Package bookmarks talks to bookmarking services, Raindrop.io and
+// Pinboard, so the reading list can be mirrored to an account there and
+// bookmarks saved from other devices can come back into Apricot.
+package bookmarks
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/hoanghai1803/apricot/internal/outbound"
+)
+
+// Names of the supported services.
+const (
+	ServiceRaindrop = "raindrop"
+	ServicePinboard = "pinboard"
+)
+
+// ErrUnauthorized is returned when the service refuses the token.
+var ErrUnauthorized = errors.New("the bookmarking service rejected the token")
+
+// Bookmark is a bookmark in a service. Only Notes, Tags, and Read are kept
+// in step with the reading list; Title is sent when a bookmark is created
+// and taken when one is imported.
+type Bookmark struct {
+	ID    string // the service's ID for it
+	URL   string
+	Title string
+	Notes string
+	Tags  []string
+
+	// Read is whether the post has been read, or nil for a service that
+	// doesn't track it (see Service.TracksRead).
+	Read *bool
+}
+
+// Service is an account in a bookmarking service.
+type Service interface {
+	// Name is the service's name, such as ServiceRaindrop.
+	Name() string
+
+	// TracksRead reports whether the service keeps a read state.
+	TracksRead() bool
+
+	// List returns every bookmark being synced.
+	List(ctx context.Context) ([]Bookmark, error)
+
+	// Create adds b and returns it as the service stored it, with its ID.
+	Create(ctx context.Context, b Bookmark) (Bookmark, error)
+
+	// Update replaces the notes, tags, and read state of bookmark b.ID and
+	// returns it as the service stored it.
+	Update(ctx context.Context, b Bookmark) (Bookmark, error)
+
+	// Delete deletes bookmark id.
+	Delete(ctx context.Context, id string) error
+}
+
+// New returns the Service called name, with the given token. collection
+// is the Raindrop collection to sync; Pinboard ignores it.
+func New(name, token string, collection int64) (Service, error) {
+	switch name {
+	case ServiceRaindrop:
+		return &Raindrop{Token: token, Collection: collection}, nil
+	case ServicePinboard:
+		return &Pinboard{Token: token}, nil
+	default:
+		return nil, fmt.Errorf("unknown bookmarking service %q", name)
+	}
+}
+
+// Fingerprint returns a hash of b's synced fields, its notes, tags, and
+// read state, for telling whether it changed since it was last seen. Tag
+// order and case, and space around the notes, don't count.
+func Fingerprint(b Bookmark) string {
+	tags := make([]string, 0, len(b.Tags))
+	for _, tag := range b.Tags {
+		tags = append(tags, strings.ToLower(strings.TrimSpace(tag)))
+	}
+	slices.Sort(tags)
+
+	read := "-"
+	if b.Read != nil {
+		read = fmt.Sprint(*b.Read)
+	}
+	h := sha256.New()
+	fmt.Fprintf(h, "%s\x00%s\x00%s", strings.TrimSpace(b.Notes), strings.Join(slices.Compact(tags), "\x01"), read)
+	return hex.EncodeToString(h.Sum(nil)[:16])
+}
+
+// StatusError is an unexpected HTTP status from a service.
+type StatusError struct {
+	Service string
+	Code    int
+	Body    string
+}
+
+func (e *StatusError) Error() string {
+	if e.Body == "" {
+		return fmt.Sprintf("%s returned HTTP %d", e.Service, e.Code)
+	}
+	return fmt.Sprintf("%s returned HTTP %d: %s", e.Service, e.Code, e.Body)
+}
+
+// defaultClient is the HTTP client used when a Service has none.
+var defaultClient = &http.Client{
+	Timeout:   time.Minute,
+	Transport: &outbound.Transport{Purpose: outbound.PurposeSync},
+}
+
+// send makes req with client (defaultClient if nil) and returns the
+// response body, up to limit bytes, or an error for a non-2xx status.
+func send(client *http.Client, service string, req *http.Request, limit int64) ([]byte, error) {
+	if client == nil {
+		client = defaultClient
+	}
+	req.Header.Set("User-Agent", "Apricot")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("calling %s: %w", service, err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, limit))
+	if err != nil {
+		return nil, fmt.Errorf("reading %s response: %w", service, err)
+	}
+
+	switch {
+	case resp.StatusCode < 300:
+		return body, nil
+	case resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden:
+		return nil, ErrUnauthorized
+	case resp.StatusCode == http.StatusTooManyRequests:
+		return nil, fmt.Errorf("%s rate limit reached; retry after %s seconds", service, resp.Header.Get("Retry-After"))
+	default:
+		return nil, &StatusError{Service: service, Code: resp.StatusCode, Body: truncate(strings.TrimSpace(string(body)), 200)}
+	}
+}
+
+// truncate cuts s to at most n runes, ending in an ellipsis if cut.
+func truncate(s string, n int) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+	return string(r[:n-1]) + "…"
+}
@@ -0,0 +1,142 @@
+package bookmarks
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFingerprint(t *testing.T) {
+	read, unread := true, false
+	base := Bookmark{Notes: "Good post.", Tags: []string{"go", "Databases"}, Read: &unread}
+
+	same := Bookmark{ID: "7", URL: "https://a.example", Title: "Other", Notes: "  Good post.\n",
+		Tags: []string{"databases", "go", "go"}, Read: &unread}
+	if Fingerprint(base) != Fingerprint(same) {
+		t.Error("fingerprints differ for bookmarks differing only in ID, title, tag order and case, and spacing")
+	}
+	for name, b := range map[string]Bookmark{
+		"notes":   {Notes: "Great post.", Tags: base.Tags, Read: &unread},
+		"tags":    {Notes: base.Notes, Tags: []string{"go"}, Read: &unread},
+		"read":    {Notes: base.Notes, Tags: base.Tags, Read: &read},
+		"no read": {Notes: base.Notes, Tags: base.Tags},
+	} {
+		if Fingerprint(b) == Fingerprint(base) {
+			t.Errorf("changing the %s kept the fingerprint", name)
+		}
+	}
+}
+
+func TestRaindrop(t *testing.T) {
+	var created map[string]any
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/raindrops/-1":
+			// Two pages: a full one, then one with a single bookmark.
+			var items []raindrop
+			n := raindropPage
+			if r.URL.Query().Get("page") == "1" {
+				n = 1
+			}
+			for i := range n {
+				items = append(items, raindrop{ID: int64(i + 1), Link: fmt.Sprintf("https://a.example/%d", i)})
+			}
+			json.NewEncoder(w).Encode(map[string]any{"items": items})
+		case r.Method == http.MethodPost && r.URL.Path == "/raindrop":
+			json.NewDecoder(r.Body).Decode(&created)
+			fmt.Fprint(w, `{"result":true,"item":{"_id":99,"link":"https://b.example","title":"B","note":"n","tags":["go"]}}`)
+		case r.Method == http.MethodDelete && r.URL.Path == "/raindrop/99":
+			fmt.Fprint(w, `{"result":true}`)
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	ctx := context.Background()
+	c := &Raindrop{Token: "secret", API: srv.URL, HTTP: srv.Client()}
+	all, err := c.List(ctx)
+	if err != nil {
+		t.Fatalf("List() error: %v", err)
+	}
+	if len(all) != raindropPage+1 || all[0].ID != "1" || all[0].URL != "https://a.example/0" || all[0].Read != nil {
+		t.Errorf("List() = %d bookmarks, first %+v; want both pages", len(all), all[0])
+	}
+
+	b, err := c.Create(ctx, Bookmark{URL: "https://b.example", Title: "B", Notes: "n", Tags: []string{"go"}})
+	if err != nil {
+		t.Fatalf("Create() error: %v", err)
+	}
+	if b.ID != "99" || b.Notes != "n" {
+		t.Errorf("Create() = %+v, want the stored bookmark", b)
+	}
+	if col, _ := created["collection"].(map[string]any); col["$id"] != float64(-1) {
+		t.Errorf("created in collection %v, want Unsorted (-1)", created["collection"])
+	}
+	if err := c.Delete(ctx, "99"); err != nil {
+		t.Errorf("Delete() error: %v", err)
+	}
+
+	c.Token = "wrong"
+	if _, err := c.List(ctx); !errors.Is(err, ErrUnauthorized) {
+		t.Errorf("List() with a bad token error = %v, want ErrUnauthorized", err)
+	}
+}
+
+func TestPinboard(t *testing.T) {
+	var added []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if q.Get("auth_token") != "user:abc" || q.Get("format") != "json" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch r.URL.Path {
+		case "/posts/all":
+			fmt.Fprint(w, `[{"href":"https://a.example","description":"A","extended":"notes","tags":"go db","toread":"yes"}]`)
+		case "/posts/add":
+			added = append(added, q.Get("tags")+"|"+q.Get("toread")+"|"+q.Get("description"))
+			fmt.Fprint(w, `{"result_code":"done"}`)
+		case "/posts/delete":
+			fmt.Fprint(w, `{"result_code":"item not found"}`)
+		}
+	}))
+	defer srv.Close()
+
+	ctx := context.Background()
+	c := &Pinboard{Token: "user:abc", API: srv.URL, HTTP: srv.Client(), Interval: time.Millisecond}
+	all, err := c.List(ctx)
+	if err != nil {
+		t.Fatalf("List() error: %v", err)
+	}
+	if len(all) != 1 || all[0].ID != "https://a.example" || all[0].Notes != "notes" ||
+		strings.Join(all[0].Tags, ",") != "go,db" || all[0].Read == nil || *all[0].Read {
+		t.Errorf("List() = %+v, want one unread bookmark", all)
+	}
+
+	read := true
+	b, err := c.Create(ctx, Bookmark{URL: "https://b.example", Tags: []string{"machine learning", " "}, Read: &read})
+	if err != nil {
+		t.Fatalf("Create() error: %v", err)
+	}
+	if b.ID != "https://b.example" || strings.Join(b.Tags, ",") != "machine-learning" {
+		t.Errorf("Create() = %+v, want the URL as its ID and tags without spaces", b)
+	}
+	if len(added) != 1 || added[0] != "machine-learning|no|https://b.example" {
+		t.Errorf("posts/add got %q, want the fitted tags, toread=no, and the URL as the title", added)
+	}
+	if err := c.Delete(ctx, "https://gone.example"); err != nil {
+		t.Errorf("Delete() of a missing bookmark error: %v", err)
+	}
+}
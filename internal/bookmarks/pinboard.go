@@ -0,0 +1,202 @@
+package bookmarks
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// PinboardAPI is the Pinboard API's base URL.
+const PinboardAPI = "https://api.pinboard.in/v1"
+
+// pinboardInterval is the least time between requests that Pinboard's
+// rate limit allows.
+const pinboardInterval = 3 * time.Second
+
+// Limits on field lengths, from the Pinboard API documentation.
+const (
+	maxPinboardTitle = 255
+	maxPinboardNotes = 65536
+	maxPinboardTag   = 255
+)
+
+// Pinboard is a Pinboard account, used with the API token from
+// https://pinboard.in/settings/password ("user:HEX"). A bookmark's ID is
+// its URL, and its read state is Pinboard's "to read" flag.
+type Pinboard struct {
+	Token string
+
+	// API is the API's base URL; empty means PinboardAPI.
+	API string
+
+	// HTTP makes the requests; nil means a client with a one-minute
+	// timeout whose requests are logged as syncs.
+	HTTP *http.Client
+
+	// Interval is the least time between requests; 0 means three seconds,
+	// as Pinboard asks.
+	Interval time.Duration
+
+	mu   sync.Mutex
+	last time.Time
+}
+
+// pin is a bookmark as the Pinboard API encodes it.
+type pin struct {
+	Href        string `json:"href"`
+	Description string `json:"description"`
+	Extended    string `json:"extended"`
+	Tags        string `json:"tags"`
+	ToRead      string `json:"toread"`
+}
+
+func (c *Pinboard) Name() string     { return ServicePinboard }
+func (c *Pinboard) TracksRead() bool { return true }
+
+// List returns every bookmark in the account.
+func (c *Pinboard) List(ctx context.Context) ([]Bookmark, error) {
+	var pins []pin
+	if err := c.do(ctx, "/posts/all", nil, &pins); err != nil {
+		return nil, err
+	}
+	all := make([]Bookmark, 0, len(pins))
+	for _, p := range pins {
+		read := p.ToRead != "yes"
+		all = append(all, Bookmark{
+			ID:    p.Href,
+			URL:   p.Href,
+			Title: p.Description,
+			Notes: p.Extended,
+			Tags:  strings.Fields(p.Tags),
+			Read:  &read,
+		})
+	}
+	return all, nil
+}
+
+// Create adds b.
+func (c *Pinboard) Create(ctx context.Context, b Bookmark) (Bookmark, error) {
+	b.ID = b.URL
+	return c.add(ctx, b)
+}
+
+// Update replaces bookmark b.ID. Pinboard has no partial update, so b's
+// title is sent too; one left empty keeps the URL as the title.
+func (c *Pinboard) Update(ctx context.Context, b Bookmark) (Bookmark, error) {
+	b.URL = b.ID
+	return c.add(ctx, b)
+}
+
+// add creates or replaces b, after fitting it to Pinboard's limits: tags
+// cannot contain spaces, which become hyphens.
+func (c *Pinboard) add(ctx context.Context, b Bookmark) (Bookmark, error) {
+	if b.Title == "" {
+		b.Title = b.URL
+	}
+	b.Title = truncate(b.Title, maxPinboardTitle)
+	b.Notes = truncate(b.Notes, maxPinboardNotes)
+	tags := make([]string, 0, len(b.Tags))
+	for _, tag := range b.Tags {
+		if tag = strings.Join(strings.Fields(tag), "-"); tag != "" {
+			tags = append(tags, truncate(tag, maxPinboardTag))
+		}
+	}
+	b.Tags = tags
+
+	params := url.Values{
+		"url":         {b.URL},
+		"description": {b.Title},
+		"extended":    {b.Notes},
+		"tags":        {strings.Join(b.Tags, " ")},
+		"replace":     {"yes"},
+	}
+	if b.Read != nil {
+		toRead := "no"
+		if !*b.Read {
+			toRead = "yes"
+		}
+		params.Set("toread", toRead)
+	}
+	var resp struct {
+		ResultCode string `json:"result_code"`
+	}
+	if err := c.do(ctx, "/posts/add", params, &resp); err != nil {
+		return Bookmark{}, err
+	}
+	if resp.ResultCode != "done" {
+		return Bookmark{}, fmt.Errorf("pinboard refused %s: %s", b.URL, resp.ResultCode)
+	}
+	return b, nil
+}
+
+// Delete deletes the bookmark with URL id.
+func (c *Pinboard) Delete(ctx context.Context, id string) error {
+	var resp struct {
+		ResultCode string `json:"result_code"`
+	}
+	if err := c.do(ctx, "/posts/delete", url.Values{"url": {id}}, &resp); err != nil {
+		return err
+	}
+	// A bookmark that is already gone is as good as deleted.
+	if resp.ResultCode != "done" && resp.ResultCode != "item not found" {
+		return fmt.Errorf("pinboard refused to delete %s: %s", id, resp.ResultCode)
+	}
+	return nil
+}
+
+// do calls the API method at path with params, waiting out the rate limit
+// first, and decodes the JSON response into out.
+func (c *Pinboard) do(ctx context.Context, path string, params url.Values, out any) error {
+	if err := c.wait(ctx); err != nil {
+		return err
+	}
+	api := c.API
+	if api == "" {
+		api = PinboardAPI
+	}
+	if params == nil {
+		params = url.Values{}
+	}
+	params.Set("auth_token", c.Token)
+	params.Set("format", "json")
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimRight(api, "/")+path+"?"+params.Encode(), nil)
+	if err != nil {
+		return err
+	}
+	data, err := send(c.HTTP, "pinboard", req, 64<<20)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("decoding pinboard response: %w", err)
+	}
+	return nil
+}
+
+// wait blocks until Interval has passed since the previous request.
+func (c *Pinboard) wait(ctx context.Context) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	interval := c.Interval
+	if interval == 0 {
+		interval = pinboardInterval
+	}
+	if d := time.Until(c.last.Add(interval)); d > 0 {
+		t := time.NewTimer(d)
+		defer t.Stop()
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-t.C:
+		}
+	}
+	c.last = time.Now()
+	return nil
+}
@@ -0,0 +1,189 @@
+package cleanhtml
+
+import (
+	"bytes"
+	"net/url"
+	"slices"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// articleKept lists the elements Article keeps; the others are replaced by
+// their content, except those in articleDropped.
+var articleKept = map[atom.Atom]bool{
+	atom.A: true, atom.Abbr: true, atom.B: true, atom.Blockquote: true,
+	atom.Br: true, atom.Caption: true, atom.Cite: true, atom.Code: true,
+	atom.Dd: true, atom.Del: true, atom.Details: true, atom.Div: true,
+	atom.Dl: true, atom.Dt: true, atom.Em: true, atom.Figcaption: true,
+	atom.Figure: true, atom.H1: true, atom.H2: true, atom.H3: true,
+	atom.H4: true, atom.H5: true, atom.H6: true, atom.Hr: true,
+	atom.I: true, atom.Img: true, atom.Ins: true, atom.Kbd: true,
+	atom.Li: true, atom.Mark: true, atom.Ol: true, atom.P: true,
+	atom.Picture: true, atom.Pre: true, atom.Q: true, atom.S: true,
+	atom.Samp: true, atom.Small: true, atom.Source: true, atom.Span: true,
+	atom.Strong: true, atom.Sub: true, atom.Summary: true, atom.Sup: true,
+	atom.Table: true, atom.Tbody: true, atom.Td: true, atom.Tfoot: true,
+	atom.Th: true, atom.Thead: true, atom.Tr: true, atom.U: true,
+	atom.Ul: true, atom.Var: true,
+}
+
+// articleDropped lists the elements Article removes along with their
+// content.
+var articleDropped = map[atom.Atom]bool{
+	atom.Script: true, atom.Style: true, atom.Noscript: true,
+	atom.Iframe: true, atom.Frame: true, atom.Object: true,
+	atom.Embed: true, atom.Applet: true, atom.Form: true,
+	atom.Button: true, atom.Input: true, atom.Select: true,
+	atom.Textarea: true, atom.Canvas: true, atom.Video: true,
+	atom.Audio: true, atom.Svg: true, atom.Math: true,
+	atom.Template: true, atom.Link: true, atom.Meta: true,
+	atom.Base: true,
+}
+
+// articleAttrs lists the attributes Article keeps, by element; the rest are
+// dropped, styles, ids, and classes included, so the article takes the
+// reader's styling. A class starting "language-" is kept on <code> for
+// syntax highlighting.
+var articleAttrs = map[atom.Atom][]string{
+	atom.A:       {"href", "title"},
+	atom.Abbr:    {"title"},
+	atom.Img:     {"src", "srcset", "alt", "title", "width", "height"},
+	atom.Source:  {"srcset", "media", "type"},
+	atom.Ol:      {"start", "reversed"},
+	atom.Td:      {"colspan", "rowspan"},
+	atom.Th:      {"colspan", "rowspan", "scope"},
+	atom.Details: {"open"},
+}
+
+// Article sanitizes fragment, an article's main content as extracted from
+// page by readability, for display inside the reader's own page. Only
+// plain formatting, links, tables, and images are kept, with no scripts,
+// styles, or event handlers. Links are made absolute and open in a new
+// tab; images on page's host load through proxy, like Clean's, and the
+// rest from their absolute URL. Tracking pixels and tracker images go.
+func Article(fragment string, page *url.URL, proxy ProxyFunc) string {
+	body := &html.Node{Type: html.ElementNode, Data: "body", DataAtom: atom.Body}
+	nodes, err := html.ParseFragment(strings.NewReader(fragment), body)
+	if err != nil {
+		// The parser only fails on a failing reader.
+		return ""
+	}
+	for _, n := range nodes {
+		body.AppendChild(n)
+	}
+	c := &cleaner{page: page, base: page, proxy: proxy}
+	c.sanitizeChildren(body)
+
+	var buf bytes.Buffer
+	for n := body.FirstChild; n != nil; n = n.NextSibling {
+		html.Render(&buf, n) //nolint:errcheck // writes to a buffer
+	}
+	return strings.TrimSpace(buf.String())
+}
+
+// sanitizeChildren sanitizes the children of n in place.
+func (c *cleaner) sanitizeChildren(n *html.Node) {
+	for child := n.FirstChild; child != nil; {
+		next := child.NextSibling
+		switch child.Type {
+		case html.ElementNode:
+			c.sanitize(child)
+		case html.TextNode:
+		default:
+			n.RemoveChild(child)
+		}
+		child = next
+	}
+}
+
+// sanitize sanitizes element n, which may remove it or replace it with its
+// children.
+func (c *cleaner) sanitize(n *html.Node) {
+	parent := n.Parent
+	if articleDropped[n.DataAtom] || n.Namespace != "" {
+		parent.RemoveChild(n)
+		return
+	}
+	c.sanitizeChildren(n)
+	if !articleKept[n.DataAtom] {
+		for child := n.FirstChild; child != nil; child = n.FirstChild {
+			n.RemoveChild(child)
+			parent.InsertBefore(child, n)
+		}
+		parent.RemoveChild(n)
+		return
+	}
+
+	var attrs []html.Attribute
+	for _, a := range n.Attr {
+		key := strings.ToLower(a.Key)
+		if a.Namespace != "" {
+			continue
+		}
+		if n.DataAtom == atom.Code && key == "class" {
+			for _, class := range strings.Fields(a.Val) {
+				if strings.HasPrefix(class, "language-") {
+					attrs = append(attrs, html.Attribute{Key: "class", Val: class})
+					break
+				}
+			}
+			continue
+		}
+		for _, kept := range articleAttrs[n.DataAtom] {
+			if key == kept {
+				attrs = append(attrs, html.Attribute{Key: key, Val: a.Val})
+			}
+		}
+	}
+	n.Attr = attrs
+
+	switch n.DataAtom {
+	case atom.A:
+		raw := attr(n, "href")
+		href, ok := c.link(raw)
+		n.Attr = slices.DeleteFunc(n.Attr, func(a html.Attribute) bool { return a.Key == "href" })
+		switch {
+		case raw == "" || !ok:
+		case strings.HasPrefix(href, "#"):
+			n.Attr = append(n.Attr, html.Attribute{Key: "href", Val: href})
+		case isWebLink(href):
+			n.Attr = append(n.Attr,
+				html.Attribute{Key: "href", Val: href},
+				html.Attribute{Key: "target", Val: "_blank"},
+				html.Attribute{Key: "rel", Val: "noopener noreferrer"})
+		}
+	case atom.Img:
+		src := strings.TrimSpace(attr(n, "src"))
+		u, err := c.base.Parse(src)
+		if src == "" || err != nil || (u.Scheme != "http" && u.Scheme != "https") || c.drop(n) {
+			parent.RemoveChild(n)
+			return
+		}
+		setAttr(n, "src", c.asset(src))
+		if srcset := attr(n, "srcset"); srcset != "" {
+			setAttr(n, "srcset", c.srcset(srcset))
+		}
+		n.Attr = append(n.Attr, html.Attribute{Key: "loading", Val: "lazy"})
+	case atom.Source:
+		if srcset := attr(n, "srcset"); srcset != "" {
+			setAttr(n, "srcset", c.srcset(srcset))
+		}
+	}
+}
+
+// isWebLink reports whether href is an absolute http, https, or mailto URL.
+func isWebLink(href string) bool {
+	u, err := url.Parse(href)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https" || u.Scheme == "mailto")
+}
+
+// setAttr sets n's attribute key, which it has, to val.
+func setAttr(n *html.Node, key, val string) {
+	for i := range n.Attr {
+		if n.Attr[i].Key == key {
+			n.Attr[i].Val = val
+		}
+	}
+}
@@ -0,0 +1,330 @@
+// Package cleanhtml turns an article page into a self-contained, script-free
+// document for the reader's page proxy, and an extracted article into safe
+// HTML for the reader view.
+//
+// Clean removes scripts, embedded frames and plugins, event handler
+// attributes, resource hints, and known trackers, and rewrites URLs so the
+// page needs nothing from its own site at display time: images, stylesheets,
+// and icons on the page's host are loaded through a proxy URL, other assets
+// and all links are made absolute, and links open in a new tab. CSS rewrites
+// the url() references in a stylesheet the same way. Article keeps only the
+// plain formatting, links, and images of an article's content, for the
+// reader to show in its own page.
+package cleanhtml
+
+import (
+	"bytes"
+	"io"
+	"net/url"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// ProxyFunc returns the URL to load an asset through.
+type ProxyFunc func(asset *url.URL) string
+
+// removed lists the elements dropped along with their content.
+var removed = map[atom.Atom]bool{
+	atom.Script:   true,
+	atom.Iframe:   true,
+	atom.Frame:    true,
+	atom.Frameset: true,
+	atom.Object:   true,
+	atom.Embed:    true,
+	atom.Applet:   true,
+	atom.Base:     true, // replaced by Clean's own
+}
+
+// keptLinkRels lists the <link> relations kept; the rest (preload,
+// prefetch, preconnect, manifest, and the like) make requests the reader
+// doesn't need.
+var keptLinkRels = []string{"stylesheet", "icon", "shortcut", "apple-touch-icon", "canonical", "alternate"}
+
+// trackerHosts lists analytics and advertising hosts whose assets are
+// dropped. Subdomains match too.
+var trackerHosts = []string{
+	"google-analytics.com",
+	"googletagmanager.com",
+	"googlesyndication.com",
+	"doubleclick.net",
+	"facebook.net",
+	"facebook.com",
+	"scorecardresearch.com",
+	"quantserve.com",
+	"hotjar.com",
+	"segment.io",
+	"mixpanel.com",
+	"pixel.wp.com",
+	"stats.wp.com",
+	"bat.bing.com",
+	"analytics.twitter.com",
+	"ads.linkedin.com",
+	"px.ads.linkedin.com",
+}
+
+// Clean reads an HTML page fetched from page and writes the cleaned page to
+// a new buffer. Assets on page's host are loaded through proxy.
+func Clean(r io.Reader, page *url.URL, proxy ProxyFunc) ([]byte, error) {
+	// With scripting off, <noscript> content is parsed as markup, which
+	// Clean then keeps in place of the scripts it removes.
+	doc, err := html.ParseWithOptions(r, html.ParseOptionEnableScripting(false))
+	if err != nil {
+		return nil, err
+	}
+
+	c := &cleaner{page: page, base: page, proxy: proxy}
+	if href, ok := findBase(doc); ok {
+		if u, err := page.Parse(href); err == nil {
+			c.base = u
+		}
+	}
+	c.clean(doc)
+	if head := find(doc, atom.Head); head != nil {
+		head.InsertBefore(&html.Node{
+			Type:     html.ElementNode,
+			DataAtom: atom.Base,
+			Data:     "base",
+			Attr:     []html.Attribute{{Key: "target", Val: "_blank"}},
+		}, head.FirstChild)
+	}
+
+	var buf bytes.Buffer
+	if err := html.Render(&buf, doc); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// CSS rewrites the url() and @import references in a stylesheet fetched
+// from base, as Clean does for assets in a page.
+func CSS(css string, base *url.URL, proxy ProxyFunc) string {
+	c := &cleaner{page: base, base: base, proxy: proxy}
+	return c.css(css)
+}
+
+// cleaner holds the state of one Clean call.
+type cleaner struct {
+	page  *url.URL // where the page was fetched from; its host is proxied
+	base  *url.URL // what relative URLs resolve against
+	proxy ProxyFunc
+}
+
+// clean cleans n's children, recursively.
+func (c *cleaner) clean(n *html.Node) {
+	for child := n.FirstChild; child != nil; {
+		next := child.NextSibling
+		switch {
+		case child.Type == html.CommentNode:
+			n.RemoveChild(child)
+		case child.Type != html.ElementNode:
+		case c.drop(child):
+			n.RemoveChild(child)
+		case child.DataAtom == atom.Noscript:
+			// Replace the <noscript> with its content.
+			c.clean(child)
+			for grandchild := child.FirstChild; grandchild != nil; grandchild = child.FirstChild {
+				child.RemoveChild(grandchild)
+				n.InsertBefore(grandchild, child)
+			}
+			n.RemoveChild(child)
+		default:
+			c.rewrite(child)
+			c.clean(child)
+		}
+		child = next
+	}
+}
+
+// drop reports whether element n is removed.
+func (c *cleaner) drop(n *html.Node) bool {
+	if removed[n.DataAtom] {
+		return true
+	}
+	switch n.DataAtom {
+	case atom.Link:
+		rels := strings.Fields(strings.ToLower(attr(n, "rel")))
+		if !slices.ContainsFunc(rels, func(rel string) bool { return slices.Contains(keptLinkRels, rel) }) {
+			return true
+		}
+	case atom.Meta:
+		switch strings.ToLower(attr(n, "http-equiv")) {
+		case "refresh", "set-cookie":
+			return true
+		}
+	case atom.Img:
+		// Tracking pixels.
+		if w, h := attr(n, "width"), attr(n, "height"); (w == "0" || w == "1") && (h == "0" || h == "1") {
+			return true
+		}
+	}
+	for _, key := range []string{"src", "href"} {
+		if v := attr(n, key); v != "" {
+			if u, err := c.base.Parse(strings.TrimSpace(v)); err == nil && isTracker(u.Hostname()) && n.DataAtom != atom.A {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// rewrite cleans element n's attributes.
+func (c *cleaner) rewrite(n *html.Node) {
+	attrs := n.Attr[:0]
+	for _, a := range n.Attr {
+		key := strings.ToLower(a.Key)
+		switch {
+		case strings.HasPrefix(key, "on"), key == "ping", key == "integrity", key == "nonce":
+			// Event handlers and tracking beacons go; integrity hashes
+			// would not match stylesheets rewritten by the proxy.
+			continue
+		case key == "style":
+			a.Val = c.css(a.Val)
+		case key == "srcset":
+			a.Val = c.srcset(a.Val)
+		case isAsset(n, key):
+			a.Val = c.asset(a.Val)
+		case key == "href" || key == "action" || key == "cite" || key == "src":
+			v, ok := c.link(a.Val)
+			if !ok {
+				continue
+			}
+			a.Val = v
+		}
+		attrs = append(attrs, a)
+	}
+	n.Attr = attrs
+
+	if n.DataAtom == atom.Style {
+		for child := n.FirstChild; child != nil; child = child.NextSibling {
+			if child.Type == html.TextNode {
+				child.Data = c.css(child.Data)
+			}
+		}
+	}
+}
+
+// isAsset reports whether attribute key of element n loads an image,
+// stylesheet, or icon that should come through the proxy.
+func isAsset(n *html.Node, key string) bool {
+	switch n.DataAtom {
+	case atom.Img, atom.Source, atom.Input:
+		return key == "src"
+	case atom.Link:
+		rel := strings.ToLower(attr(n, "rel"))
+		return key == "href" && (strings.Contains(rel, "stylesheet") || strings.Contains(rel, "icon"))
+	case atom.Video:
+		return key == "poster"
+	}
+	return key == "background"
+}
+
+// link resolves a link to an absolute URL. It returns false for
+// javascript: URLs, which are removed.
+func (c *cleaner) link(v string) (string, bool) {
+	v = strings.TrimSpace(v)
+	if strings.HasPrefix(strings.ToLower(v), "javascript:") {
+		return "", false
+	}
+	if strings.HasPrefix(v, "#") {
+		return v, true
+	}
+	u, err := c.base.Parse(v)
+	if err != nil {
+		return v, true
+	}
+	return u.String(), true
+}
+
+// asset resolves an asset URL, routing it through the proxy if it is on the
+// page's host.
+func (c *cleaner) asset(v string) string {
+	v = strings.TrimSpace(v)
+	u, err := c.base.Parse(v)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return v
+	}
+	if strings.EqualFold(u.Host, c.page.Host) {
+		return c.proxy(u)
+	}
+	return u.String()
+}
+
+// srcset rewrites each candidate URL in a srcset attribute.
+func (c *cleaner) srcset(v string) string {
+	candidates := strings.Split(v, ",")
+	for i, candidate := range candidates {
+		fields := strings.Fields(candidate)
+		if len(fields) == 0 {
+			continue
+		}
+		fields[0] = c.asset(fields[0])
+		candidates[i] = strings.Join(fields, " ")
+	}
+	return strings.Join(candidates, ", ")
+}
+
+// cssURLRe matches url(...) and @import "..." references in CSS.
+var cssURLRe = regexp.MustCompile(`url\(\s*(?:"([^"]*)"|'([^']*)'|([^)'"\s]*))\s*\)|@import\s+(?:"([^"]*)"|'([^']*)')`)
+
+// css rewrites the URLs in a stylesheet or style attribute.
+func (c *cleaner) css(css string) string {
+	return cssURLRe.ReplaceAllStringFunc(css, func(m string) string {
+		sub := cssURLRe.FindStringSubmatch(m)
+		ref := sub[1] + sub[2] + sub[3] + sub[4] + sub[5]
+		if ref == "" || strings.HasPrefix(ref, "data:") || strings.HasPrefix(ref, "#") {
+			return m
+		}
+		rewritten := strconv.Quote(c.asset(ref))
+		if strings.HasPrefix(m, "@import") {
+			return "@import " + rewritten
+		}
+		return "url(" + rewritten + ")"
+	})
+}
+
+// isTracker reports whether host is, or is under, a known tracker host.
+func isTracker(host string) bool {
+	host = strings.ToLower(host)
+	for _, t := range trackerHosts {
+		if host == t || strings.HasSuffix(host, "."+t) {
+			return true
+		}
+	}
+	return false
+}
+
+// findBase returns the href of the document's first <base>, if any.
+func findBase(doc *html.Node) (string, bool) {
+	if n := find(doc, atom.Base); n != nil && attr(n, "href") != "" {
+		return attr(n, "href"), true
+	}
+	return "", false
+}
+
+// find returns the first element of type a under n, depth first.
+func find(n *html.Node, a atom.Atom) *html.Node {
+	if n.Type == html.ElementNode && n.DataAtom == a {
+		return n
+	}
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		if found := find(child, a); found != nil {
+			return found
+		}
+	}
+	return nil
+}
+
+// attr returns the value of n's attribute key, or "".
+func attr(n *html.Node, key string) string {
+	for _, a := range n.Attr {
+		if strings.EqualFold(a.Key, key) {
+			return a.Val
+		}
+	}
+	return ""
+}
@@ -0,0 +1,129 @@
+package cleanhtml
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func testProxy(u *url.URL) string {
+	return "/proxy?url=" + url.QueryEscape(u.String())
+}
+
+func TestClean(t *testing.T) {
+	page, _ := url.Parse("https://blog.example.com/posts/hello/")
+	in := `<!doctype html><html><head>
+<title>Hello</title>
+<base href="https://blog.example.com/posts/hello/">
+<link rel="stylesheet" href="/css/site.css" integrity="sha384-abc">
+<link rel="preload" href="/fonts/inter.woff2" as="font">
+<meta http-equiv="refresh" content="0; url=https://elsewhere.example">
+<script src="/app.js"></script>
+<script>track()</script>
+<style>body { background: url(img/bg.png) }</style>
+</head><body onload="init()">
+<noscript><img src="fallback.png" alt="fallback"></noscript>
+<p style="background-image: url('/img/hero.jpg')">Text <a href="../other/" ping="https://t.example/ping">next</a>
+<a href="javascript:alert(1)">bad</a></p>
+<img src="photo.jpg" srcset="photo-2x.jpg 2x, https://cdn.example.net/p.jpg 3x" onclick="zoom()">
+<img src="https://www.google-analytics.com/collect?v=1" alt="">
+<img src="/pixel.gif" width="1" height="1">
+<iframe src="https://www.youtube.com/embed/x"></iframe>
+<!-- a comment -->
+</body></html>`
+
+	out, err := Clean(strings.NewReader(in), page, testProxy)
+	if err != nil {
+		t.Fatalf("Clean() error: %v", err)
+	}
+	got := string(out)
+
+	for _, want := range []string{
+		`<base target="_blank"/>`,
+		`href="/proxy?url=https%3A%2F%2Fblog.example.com%2Fcss%2Fsite.css"`,
+		`url("/proxy?url=https%3A%2F%2Fblog.example.com%2Fposts%2Fhello%2Fimg%2Fbg.png")`,
+		`url(&#34;/proxy?url=https%3A%2F%2Fblog.example.com%2Fimg%2Fhero.jpg&#34;)`,
+		`src="/proxy?url=https%3A%2F%2Fblog.example.com%2Fposts%2Fhello%2Ffallback.png"`,
+		`src="/proxy?url=https%3A%2F%2Fblog.example.com%2Fposts%2Fhello%2Fphoto.jpg"`,
+		`srcset="/proxy?url=https%3A%2F%2Fblog.example.com%2Fposts%2Fhello%2Fphoto-2x.jpg 2x, https://cdn.example.net/p.jpg 3x"`,
+		`<a href="https://blog.example.com/posts/other/">next</a>`,
+		`<a>bad</a>`,
+		`<title>Hello</title>`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("output lacks %s\n%s", want, got)
+		}
+	}
+	for _, unwanted := range []string{
+		"<script", "track()", "onload", "onclick", "ping=", "integrity", "preload",
+		"refresh", "google-analytics", "pixel.gif", "<iframe", "youtube", "<noscript", "a comment",
+		`href="https://blog.example.com/posts/hello/"`,
+	} {
+		if strings.Contains(got, unwanted) {
+			t.Errorf("output still contains %s\n%s", unwanted, got)
+		}
+	}
+}
+
+func TestCSS(t *testing.T) {
+	base, _ := url.Parse("https://blog.example.com/css/site.css")
+	in := `@import "print.css"; .a { background: url(../img/a.png) } .b { background: url("data:image/png;base64,xyz") } ` +
+		`@font-face { src: url('https://fonts.example.net/f.woff2') }`
+	got := CSS(in, base, testProxy)
+
+	for _, want := range []string{
+		`@import "/proxy?url=https%3A%2F%2Fblog.example.com%2Fcss%2Fprint.css"`,
+		`url("/proxy?url=https%3A%2F%2Fblog.example.com%2Fimg%2Fa.png")`,
+		`url("data:image/png;base64,xyz")`,
+		`url("https://fonts.example.net/f.woff2")`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("CSS() = %s\nwant it to contain %s", got, want)
+		}
+	}
+}
+
+func TestArticle(t *testing.T) {
+	page, _ := url.Parse("https://blog.example.com/posts/hello/")
+	in := `<div id="readability-page-1" class="page"><h2 style="color: red">Intro</h2>
+<p onclick="track()">Read <a href="../other/" class="x">the next post</a>, <a href="#notes">the notes</a>,
+<a href="javascript:alert(1)">this</a> and <a href="data:text/html,hi">that</a>.</p>
+<script>track()</script><style>p { color: red }</style>
+<form action="/subscribe"><input name="email"><button>Subscribe</button></form>
+<img src="diagram.png" alt="Diagram" srcset="diagram-2x.png 2x">
+<img src="https://cdn.example.net/photo.jpg" alt="Photo">
+<img src="https://www.google-analytics.com/collect?v=1"><img src="/pixel.gif" width="1" height="1">
+<img src="data:image/png;base64,xyz">
+<pre><code class="hljs language-go">fmt.Println("hi")</code></pre>
+<iframe src="https://www.youtube.com/embed/x"></iframe><svg><circle r="1"></circle></svg>
+<table><tr><td colspan="2" bgcolor="red">cell</td></tr></table>
+<custom-widget>kept text</custom-widget><!-- a comment --></div>`
+
+	got := Article(in, page, testProxy)
+
+	for _, want := range []string{
+		`<h2>Intro</h2>`,
+		`<p>Read <a href="https://blog.example.com/posts/other/" target="_blank" rel="noopener noreferrer">the next post</a>`,
+		`<a href="#notes">the notes</a>`,
+		`<a>this</a>`,
+		`<a>that</a>`,
+		`<img src="/proxy?url=https%3A%2F%2Fblog.example.com%2Fposts%2Fhello%2Fdiagram.png" alt="Diagram" srcset="/proxy?url=https%3A%2F%2Fblog.example.com%2Fposts%2Fhello%2Fdiagram-2x.png 2x" loading="lazy"/>`,
+		`<img src="https://cdn.example.net/photo.jpg" alt="Photo" loading="lazy"/>`,
+		`<code class="language-go">`,
+		`<td colspan="2">cell</td>`,
+		`kept text`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("output lacks %s\n%s", want, got)
+		}
+	}
+	for _, unwanted := range []string{
+		"readability-page", `class="page"`, "style", "onclick", "track()", "<script", "<form", "<input", "Subscribe",
+		"google-analytics", "pixel.gif", "data:", "hljs", "<iframe", "youtube", "<svg",
+		"bgcolor", "custom-widget", "a comment",
+	} {
+		if strings.Contains(got, unwanted) {
+			t.Errorf("output still contains %s\n%s", unwanted, got)
+		}
+	}
+}
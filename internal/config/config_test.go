@@ -1,9 +1,14 @@
 package config
 
 import (
+	"fmt"
+	"maps"
 	"os"
 	"path/filepath"
+	"slices"
+	"strings"
 	"testing"
+	"time"
 )
 
 // writeTestConfig is a helper that writes a TOML config file to a temp directory
@@ -18,7 +23,24 @@ func writeTestConfig(t *testing.T, content string) string {
 	return path
 }
 
+// clearKeyEnv unsets, for the rest of the test, the environment variables
+// that would override the API key and the settings under test, so the
+// tests pass in a shell that has them set.
+func clearKeyEnv(t *testing.T) {
+	t.Helper()
+	for _, name := range []string{"AI_API_KEY", "ANTHROPIC_API_KEY", "OPENAI_API_KEY"} {
+		t.Setenv(name, "")
+		t.Setenv(name+"_FILE", "")
+	}
+	for _, kv := range os.Environ() {
+		if name, _, _ := strings.Cut(kv, "="); strings.HasPrefix(name, EnvPrefix) {
+			t.Setenv(name, "")
+		}
+	}
+}
+
 func TestLoad_ValidConfig(t *testing.T) {
+	clearKeyEnv(t)
 	content := `
 [ai]
 provider = "openai"
@@ -28,6 +50,7 @@ model = "gpt-4o"
 [server]
 port = 9090
 auto_open_browser = false
+headless = true
 
 [feeds]
 refresh_interval_minutes = 30
@@ -56,8 +79,11 @@ lookback_days = 14
 	if cfg.Server.Port != 9090 {
 		t.Errorf("Server.Port = %d, want %d", cfg.Server.Port, 9090)
 	}
-	if cfg.Server.AutoOpenBrowser != false {
-		t.Errorf("Server.AutoOpenBrowser = %v, want %v", cfg.Server.AutoOpenBrowser, false)
+	if cfg.Server.AutoOpenBrowser == nil || *cfg.Server.AutoOpenBrowser {
+		t.Errorf("Server.AutoOpenBrowser = %v, want explicitly false", cfg.Server.AutoOpenBrowser)
+	}
+	if !cfg.Server.Headless {
+		t.Error("Server.Headless = false, want true")
 	}
 
 	// Feeds config
@@ -73,6 +99,7 @@ lookback_days = 14
 }
 
 func TestLoad_MissingFile_CreatesDefault(t *testing.T) {
+	clearKeyEnv(t)
 	dir := t.TempDir()
 	path := filepath.Join(dir, "config.toml")
 
@@ -96,8 +123,11 @@ func TestLoad_MissingFile_CreatesDefault(t *testing.T) {
 	if cfg.Server.Port != 8080 {
 		t.Errorf("Server.Port = %d, want %d", cfg.Server.Port, 8080)
 	}
-	if cfg.Server.AutoOpenBrowser != true {
-		t.Errorf("Server.AutoOpenBrowser = %v, want %v", cfg.Server.AutoOpenBrowser, true)
+	if !cfg.Server.OpenBrowser() {
+		t.Error("Server.OpenBrowser() = false, want true")
+	}
+	if cfg.Server.Headless {
+		t.Error("Server.Headless = true, want false by default")
 	}
 	if cfg.Feeds.RefreshIntervalMinutes != 60 {
 		t.Errorf("Feeds.RefreshIntervalMinutes = %d, want %d", cfg.Feeds.RefreshIntervalMinutes, 60)
@@ -111,6 +141,7 @@ func TestLoad_MissingFile_CreatesDefault(t *testing.T) {
 }
 
 func TestLoad_DefaultsApplied(t *testing.T) {
+	clearKeyEnv(t)
 	// Minimal config: only provide required valid provider, let everything
 	// else fall through to defaults.
 	content := `
@@ -137,6 +168,21 @@ api_key = "sk-test"
 	if cfg.Server.Port != 8080 {
 		t.Errorf("Server.Port = %d, want default %d", cfg.Server.Port, 8080)
 	}
+	if cfg.Server.RequestTimeoutSeconds != 5 {
+		t.Errorf("Server.RequestTimeoutSeconds = %d, want default %d", cfg.Server.RequestTimeoutSeconds, 5)
+	}
+	if cfg.Server.FetchTimeoutSeconds != 30 {
+		t.Errorf("Server.FetchTimeoutSeconds = %d, want default %d", cfg.Server.FetchTimeoutSeconds, 30)
+	}
+	if cfg.Server.DiscoveryTimeoutSeconds != 300 {
+		t.Errorf("Server.DiscoveryTimeoutSeconds = %d, want default %d", cfg.Server.DiscoveryTimeoutSeconds, 300)
+	}
+	if cfg.Server.ShutdownTimeoutSeconds != 30 {
+		t.Errorf("Server.ShutdownTimeoutSeconds = %d, want default %d", cfg.Server.ShutdownTimeoutSeconds, 30)
+	}
+	if cfg.Server.AutoOpenBrowser != nil || !cfg.Server.OpenBrowser() {
+		t.Errorf("Server.AutoOpenBrowser = %v, OpenBrowser() = false; want unset and true", cfg.Server.AutoOpenBrowser)
+	}
 	if cfg.Feeds.RefreshIntervalMinutes != 60 {
 		t.Errorf("Feeds.RefreshIntervalMinutes = %d, want default %d", cfg.Feeds.RefreshIntervalMinutes, 60)
 	}
@@ -149,6 +195,7 @@ api_key = "sk-test"
 }
 
 func TestLoad_EnvVar_AIAPIKey(t *testing.T) {
+	clearKeyEnv(t)
 	content := `
 [ai]
 provider = "anthropic"
@@ -167,7 +214,29 @@ api_key = "from-config"
 	}
 }
 
+func TestLoad_EnvVar_SecretKey(t *testing.T) {
+	path := writeTestConfig(t, `
+[ai]
+provider = "mock"
+`)
+	t.Setenv("APRICOT_SECRET_KEY", "a long enough secret")
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load(%q) unexpected error: %v", path, err)
+	}
+	if cfg.Storage.SecretKey != "a long enough secret" {
+		t.Errorf("Storage.SecretKey = %q, want the APRICOT_SECRET_KEY value", cfg.Storage.SecretKey)
+	}
+
+	t.Setenv("APRICOT_SECRET_KEY", "short")
+	if _, err := Load(path); err == nil {
+		t.Errorf("Load(%q) expected error for a short APRICOT_SECRET_KEY, got nil", path)
+	}
+}
+
 func TestLoad_EnvVar_AnthropicAPIKey(t *testing.T) {
+	clearKeyEnv(t)
 	content := `
 [ai]
 provider = "anthropic"
@@ -187,6 +256,7 @@ api_key = "from-config"
 }
 
 func TestLoad_EnvVar_OpenAIAPIKey(t *testing.T) {
+	clearKeyEnv(t)
 	content := `
 [ai]
 provider = "openai"
@@ -206,6 +276,7 @@ api_key = "from-config"
 }
 
 func TestLoad_EnvVar_AIAPIKey_TakesPrecedence(t *testing.T) {
+	clearKeyEnv(t)
 	content := `
 [ai]
 provider = "anthropic"
@@ -225,6 +296,184 @@ api_key = "from-config"
 	}
 }
 
+func TestLoad_APIKeyFile(t *testing.T) {
+	clearKeyEnv(t)
+	dir := t.TempDir()
+	keyPath := filepath.Join(dir, "ai_api_key")
+	if err := os.WriteFile(keyPath, []byte("from-file\n"), 0o600); err != nil {
+		t.Fatalf("writing key file: %v", err)
+	}
+
+	path := writeTestConfig(t, fmt.Sprintf(`
+[ai]
+provider = "anthropic"
+api_key_file = %q
+`, keyPath))
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load(%q) unexpected error: %v", path, err)
+	}
+	if cfg.AI.APIKey != "from-file" {
+		t.Errorf("AI.APIKey = %q, want %q", cfg.AI.APIKey, "from-file")
+	}
+
+	t.Setenv("AI_API_KEY", "from-env")
+	if cfg, err := Load(path); err != nil || cfg.AI.APIKey != "from-env" {
+		t.Errorf("Load(%q) with AI_API_KEY = %v, %v; want the key from the environment", path, cfg, err)
+	}
+
+	for name, content := range map[string]string{
+		"both":    fmt.Sprintf("[ai]\napi_key = \"sk\"\napi_key_file = %q\n", keyPath),
+		"missing": fmt.Sprintf("[ai]\napi_key_file = %q\n", filepath.Join(dir, "nope")),
+	} {
+		t.Run(name, func(t *testing.T) {
+			path := writeTestConfig(t, content)
+			if _, err := Load(path); err == nil {
+				t.Errorf("Load(%q) expected error, got nil", path)
+			}
+		})
+	}
+}
+
+func TestLoad_EnvFile(t *testing.T) {
+	clearKeyEnv(t)
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		p := filepath.Join(dir, name)
+		if err := os.WriteFile(p, []byte(content), 0o600); err != nil {
+			t.Fatalf("writing %s: %v", name, err)
+		}
+		return p
+	}
+	path := writeTestConfig(t, "[ai]\nprovider = \"anthropic\"\n")
+	t.Setenv("ANTHROPIC_API_KEY_FILE", write("anthropic", "sk-ant-file\n"))
+	t.Setenv("APRICOT_SECRET_KEY_FILE", write("secret", "a long enough secret\n"))
+	t.Setenv("APRICOT_SERVER_PORT_FILE", write("port", "9090"))
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load(%q) unexpected error: %v", path, err)
+	}
+	if cfg.AI.APIKey != "sk-ant-file" || cfg.Storage.SecretKey != "a long enough secret" || cfg.Server.Port != 9090 {
+		t.Errorf("APIKey = %q, SecretKey = %q, Port = %d; want the values from the files",
+			cfg.AI.APIKey, cfg.Storage.SecretKey, cfg.Server.Port)
+	}
+
+	// The variable itself wins over its file.
+	t.Setenv("ANTHROPIC_API_KEY", "sk-ant-env")
+	if cfg, err := Load(path); err != nil || cfg.AI.APIKey != "sk-ant-env" {
+		t.Errorf("Load(%q) with ANTHROPIC_API_KEY = %v, %v; want the key from the variable", path, cfg, err)
+	}
+
+	t.Setenv("APRICOT_SAVE_TOKEN_FILE", filepath.Join(dir, "nope"))
+	if _, err := Load(path); err == nil {
+		t.Errorf("Load(%q) with a missing APRICOT_SAVE_TOKEN_FILE expected error, got nil", path)
+	}
+}
+
+func TestLoad_EnvSettings(t *testing.T) {
+	path := writeTestConfig(t, `
+[ai]
+provider = "anthropic"
+model = "from-config"
+
+[server]
+port = 8080
+auto_open_browser = true
+`)
+	t.Setenv("APRICOT_AI_PROVIDER", "mock")
+	t.Setenv("APRICOT_AI_MODEL", "from-env")
+	t.Setenv("APRICOT_SERVER_PORT", "9090")
+	t.Setenv("APRICOT_SERVER_AUTO_OPEN_BROWSER", "false")
+	t.Setenv("APRICOT_FEEDS_ALLOW_NETWORKS", "10.0.0.0/8, 192.168.1.0/24")
+	t.Setenv("APRICOT_FEEDS_LOOKBACK_DAYS", "30")
+	t.Setenv("APRICOT_STORAGE_BACKUP_DIR", "/backups")
+	t.Setenv("APRICOT_EMAIL_DIGEST_TOP", "")
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load(%q) unexpected error: %v", path, err)
+	}
+	if cfg.AI.Provider != "mock" || cfg.AI.Model != "from-env" {
+		t.Errorf("AI = %+v, want the provider and model from the environment", cfg.AI)
+	}
+	if cfg.Server.Port != 9090 || cfg.Server.OpenBrowser() {
+		t.Errorf("Server.Port = %d, OpenBrowser() = true; want 9090, false", cfg.Server.Port)
+	}
+	if want := []string{"10.0.0.0/8", "192.168.1.0/24"}; !slices.Equal(cfg.Feeds.AllowNetworks, want) {
+		t.Errorf("Feeds.AllowNetworks = %q, want %q", cfg.Feeds.AllowNetworks, want)
+	}
+	if cfg.Feeds.LookbackDays != 30 || cfg.Storage.BackupDir != "/backups" {
+		t.Errorf("LookbackDays = %d, BackupDir = %q; want 30, /backups", cfg.Feeds.LookbackDays, cfg.Storage.BackupDir)
+	}
+	if cfg.Email.DigestTop != 10 {
+		t.Errorf("Email.DigestTop = %d, want the default 10 for an empty variable", cfg.Email.DigestTop)
+	}
+
+	for name, value := range map[string]string{
+		"APRICOT_SERVER_PORT":              "http",
+		"APRICOT_SERVER_AUTO_OPEN_BROWSER": "sometimes",
+		"APRICOT_FEEDS_LOOKBACK_DAYS":      "0",
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Setenv(name, value)
+			if _, err := Load(path); err == nil {
+				t.Errorf("Load(%q) with %s=%q expected error, got nil", path, name, value)
+			}
+		})
+	}
+}
+
+func TestDefault(t *testing.T) {
+	t.Setenv("APRICOT_AI_PROVIDER", "mock")
+	t.Setenv("APRICOT_SERVER_PORT", "9090")
+
+	cfg, err := Default()
+	if err != nil {
+		t.Fatalf("Default() unexpected error: %v", err)
+	}
+	if cfg.Server.Port != 9090 || cfg.AI.Provider != "mock" || cfg.AI.Model != "claude-haiku-4-5" {
+		t.Errorf("Port = %d, Provider = %q, Model = %q; want the defaults with the environment applied",
+			cfg.Server.Port, cfg.AI.Provider, cfg.AI.Model)
+	}
+}
+
+func TestLoad_ModelParams(t *testing.T) {
+	path := writeTestConfig(t, `
+[ai]
+provider = "mock"
+
+[ai.rank]
+max_tokens = 8192
+temperature = 0.2
+`)
+	t.Setenv("APRICOT_AI_SUMMARIZE_TOP_P", "0.9")
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load(%q) unexpected error: %v", path, err)
+	}
+	if r := cfg.AI.Rank; r.MaxTokens != 8192 || r.Temperature == nil || *r.Temperature != 0.2 || r.TopP != nil {
+		t.Errorf("AI.Rank = %+v, want max_tokens 8192 and temperature 0.2", r)
+	}
+	if s := cfg.AI.Summarize; s.MaxTokens != 1024 || s.Temperature != nil || s.TopP == nil || *s.TopP != 0.9 {
+		t.Errorf("AI.Summarize = %+v, want the default max_tokens 1024 and top_p 0.9", s)
+	}
+
+	for name, content := range map[string]string{
+		"max_tokens":  "[ai.rank]\nmax_tokens = -1\n",
+		"temperature": "[ai.summarize]\ntemperature = 2.5\n",
+		"top_p":       "[ai.rank]\ntop_p = 0\n",
+	} {
+		t.Run(name, func(t *testing.T) {
+			path := writeTestConfig(t, "[ai]\nprovider = \"mock\"\n\n"+content)
+			if _, err := Load(path); err == nil {
+				t.Errorf("Load(%q) with invalid %s expected error, got nil", path, name)
+			}
+		})
+	}
+}
+
 func TestLoad_InvalidProvider(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -252,6 +501,21 @@ api_key = "sk-test"
 	}
 }
 
+func TestLoad_MockProviderWithoutKey(t *testing.T) {
+	path := writeTestConfig(t, `
+[ai]
+provider = "mock"
+`)
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load(%q) unexpected error: %v", path, err)
+	}
+	if cfg.AI.Provider != "mock" {
+		t.Errorf("AI.Provider = %q, want %q", cfg.AI.Provider, "mock")
+	}
+}
+
 func TestLoad_InvalidPort(t *testing.T) {
 	tests := []struct {
 		name string
@@ -311,7 +575,697 @@ lookback_days = ` + tt.days + `
 	}
 }
 
+func TestLoad_Listen(t *testing.T) {
+	tests := []struct {
+		name    string
+		server  string
+		env     string
+		wantErr bool
+	}{
+		{"default", ``, "", false},
+		{"loopback address", `listen = "127.0.0.1"`, "", false},
+		{"all interfaces without a token", `listen = "0.0.0.0"`, "", true},
+		{"all interfaces with a token", `listen = "0.0.0.0"` + "\n" + `auth_token = "0123456789abcdef"`, "", false},
+		{"token from the environment", `listen = "0.0.0.0"`, "0123456789abcdef", false},
+		{"short token", `auth_token = "secret"`, "", true},
+		{"save token", `auth_token = "0123456789abcdef"` + "\n" + `save_token = "fedcba9876543210"`, "", false},
+		{"save token without auth token", `save_token = "fedcba9876543210"`, "", true},
+		{"save token same as auth token", `auth_token = "0123456789abcdef"` + "\n" + `save_token = "0123456789abcdef"`, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("APRICOT_AUTH_TOKEN", tt.env)
+			content := `
+[ai]
+provider = "mock"
+
+[server]
+` + tt.server + `
+`
+			cfg, err := Load(writeTestConfig(t, content))
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("Load() expected an error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Load() unexpected error: %v", err)
+			}
+			if cfg.Server.Listen == "" {
+				t.Error("Server.Listen is empty, want a default")
+			}
+			if tt.env != "" && cfg.Server.AuthToken != tt.env {
+				t.Errorf("Server.AuthToken = %q, want %q from the environment", cfg.Server.AuthToken, tt.env)
+			}
+		})
+	}
+}
+
+func TestLoad_TLS(t *testing.T) {
+	tests := []struct {
+		name    string
+		server  string
+		wantTLS bool
+		wantErr bool
+	}{
+		{"off", ``, false, false},
+		{"certificate", "tls_cert = \"cert.pem\"\ntls_key = \"key.pem\"", true, false},
+		{"certificate without key", `tls_cert = "cert.pem"`, false, true},
+		{"acme", `acme_host = "apricot.example.com"`, true, false},
+		{"acme and certificate", "acme_host = \"apricot.example.com\"\ntls_cert = \"cert.pem\"\ntls_key = \"key.pem\"", false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			content := "[ai]\nprovider = \"mock\"\n\n[server]\n" + tt.server + "\n"
+			cfg, err := Load(writeTestConfig(t, content))
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("Load() expected an error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Load() unexpected error: %v", err)
+			}
+			if got := cfg.Server.TLSEnabled(); got != tt.wantTLS {
+				t.Errorf("TLSEnabled() = %v, want %v", got, tt.wantTLS)
+			}
+		})
+	}
+}
+
+func TestLoad_InvalidRateLimit(t *testing.T) {
+	content := `
+[ai]
+provider = "mock"
+
+[server]
+expensive_rate_limit_per_minute = -1
+`
+	path := writeTestConfig(t, content)
+	if _, err := Load(path); err == nil {
+		t.Fatalf("Load(%q) expected error for a negative rate limit, got nil", path)
+	}
+}
+
+func TestLoad_CORSOrigins(t *testing.T) {
+	tests := []struct {
+		origins string
+		wantErr bool
+	}{
+		{`[]`, false},
+		{`["*"]`, false},
+		{`["https://example.com", "http://localhost:5173"]`, false},
+		{`["https://example.com/"]`, true},
+		{`["https://Example.com"]`, true},
+		{`["example.com"]`, true},
+		{`["ftp://example.com"]`, true},
+	}
+	for _, tt := range tests {
+		content := "[ai]\nprovider = \"mock\"\n\n[server]\ncors_origins = " + tt.origins + "\n"
+		_, err := Load(writeTestConfig(t, content))
+		if gotErr := err != nil; gotErr != tt.wantErr {
+			t.Errorf("cors_origins = %s: Load() error = %v, want error %v", tt.origins, err, tt.wantErr)
+		}
+	}
+}
+
+func TestLoad_InvalidAllowNetworks(t *testing.T) {
+	content := `
+[ai]
+provider = "mock"
+
+[feeds]
+allow_networks = ["192.168.1.0/24", "lan"]
+`
+	path := writeTestConfig(t, content)
+	if _, err := Load(path); err == nil {
+		t.Fatalf("Load(%q) expected error for an invalid network, got nil", path)
+	}
+}
+
+func TestLoad_Logging(t *testing.T) {
+	path := writeTestConfig(t, "[ai]\nprovider = \"mock\"\n")
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load(%q) unexpected error: %v", path, err)
+	}
+	want := LoggingConfig{Level: "info", Format: "text", MaxMB: 10, Keep: 5}
+	if cfg.Logging != want {
+		t.Errorf("Logging = %+v, want the defaults %+v", cfg.Logging, want)
+	}
+
+	for name, content := range map[string]string{
+		"level":  "level = \"verbose\"",
+		"format": "format = \"logfmt\"",
+		"max_mb": "max_mb = -1",
+		"keep":   "keep = -1",
+	} {
+		t.Run(name, func(t *testing.T) {
+			path := writeTestConfig(t, "[ai]\nprovider = \"mock\"\n\n[logging]\n"+content+"\n")
+			if _, err := Load(path); err == nil {
+				t.Errorf("Load(%q) with invalid %s expected error, got nil", path, name)
+			}
+		})
+	}
+}
+
+func TestLoad_Webhooks(t *testing.T) {
+	content := `
+[ai]
+provider = "mock"
+
+[[webhooks]]
+url = "https://hooks.example.com/apricot"
+events = ["discovery.completed"]
+secret = "s3cret"
+
+[[webhooks]]
+url = "http://192.168.1.10:5678/webhook"
+`
+	cfg, err := Load(writeTestConfig(t, content))
+	if err != nil {
+		t.Fatalf("Load() unexpected error: %v", err)
+	}
+	if len(cfg.Webhooks) != 2 || cfg.Webhooks[0].Secret != "s3cret" || len(cfg.Webhooks[1].Events) != 0 {
+		t.Errorf("Webhooks = %+v, want both tables", cfg.Webhooks)
+	}
+
+	for name, hook := range map[string]string{
+		"bad url":   "url = \"hooks.example.com\"",
+		"bad event": "url = \"https://hooks.example.com\"\nevents = [\"item.deleted\"]",
+	} {
+		content := "[ai]\nprovider = \"mock\"\n\n[[webhooks]]\n" + hook + "\n"
+		if _, err := Load(writeTestConfig(t, content)); err == nil {
+			t.Errorf("%s: Load() expected an error, got nil", name)
+		}
+	}
+}
+
+func TestLoad_Email(t *testing.T) {
+	content := `
+[ai]
+provider = "mock"
+
+[email]
+smtp_host = "smtp.example.com"
+from = "Apricot <apricot@example.com>"
+to = ["team@example.com"]
+digest_schedule = "0 8 * * 1"
+`
+	t.Setenv("APRICOT_SMTP_PASSWORD", "hunter2")
+	cfg, err := Load(writeTestConfig(t, content))
+	if err != nil {
+		t.Fatalf("Load() unexpected error: %v", err)
+	}
+	if !cfg.Email.Enabled() || cfg.Email.SMTPPort != 587 || cfg.Email.DigestTop != 10 || cfg.Email.Password != "hunter2" {
+		t.Errorf("Email = %+v, want defaults and the password from the environment", cfg.Email)
+	}
+
+	for name, section := range map[string]string{
+		"no recipients": "smtp_host = \"smtp.example.com\"\nfrom = \"a@example.com\"",
+		"bad sender":    "smtp_host = \"smtp.example.com\"\nfrom = \"apricot\"\nto = [\"b@example.com\"]",
+		"bad schedule":  "smtp_host = \"smtp.example.com\"\nfrom = \"a@example.com\"\nto = [\"b@example.com\"]\ndigest_schedule = \"weekly\"",
+	} {
+		content := "[ai]\nprovider = \"mock\"\n\n[email]\n" + section + "\n"
+		if _, err := Load(writeTestConfig(t, content)); err == nil {
+			t.Errorf("%s: Load() expected an error, got nil", name)
+		}
+	}
+}
+
+func TestLoad_Readwise(t *testing.T) {
+	content := `
+[ai]
+provider = "mock"
+
+[readwise]
+sync_schedule = "0 * * * *"
+`
+	t.Setenv("APRICOT_READWISE_TOKEN", "rw-token")
+	cfg, err := Load(writeTestConfig(t, content))
+	if err != nil {
+		t.Fatalf("Load() unexpected error: %v", err)
+	}
+	if !cfg.Readwise.Enabled() || cfg.Readwise.Token != "rw-token" {
+		t.Errorf("Readwise = %+v, want the token from the environment", cfg.Readwise)
+	}
+
+	t.Setenv("APRICOT_READWISE_TOKEN", "")
+	for name, section := range map[string]string{
+		"schedule without token": "sync_schedule = \"0 * * * *\"",
+		"bad schedule":           "token = \"x\"\nsync_schedule = \"hourly\"",
+	} {
+		content := "[ai]\nprovider = \"mock\"\n\n[readwise]\n" + section + "\n"
+		if _, err := Load(writeTestConfig(t, content)); err == nil {
+			t.Errorf("%s: Load() expected an error, got nil", name)
+		}
+	}
+}
+
+func TestLoad_Bookmarks(t *testing.T) {
+	content := `
+[ai]
+provider = "mock"
+
+[bookmarks]
+service = "raindrop"
+collection = 4242
+sync_schedule = "*/30 * * * *"
+`
+	t.Setenv("APRICOT_BOOKMARKS_TOKEN", "rd-token")
+	cfg, err := Load(writeTestConfig(t, content))
+	if err != nil {
+		t.Fatalf("Load() unexpected error: %v", err)
+	}
+	if !cfg.Bookmarks.Enabled() || cfg.Bookmarks.Token != "rd-token" || cfg.Bookmarks.Collection != 4242 {
+		t.Errorf("Bookmarks = %+v, want the token from the environment", cfg.Bookmarks)
+	}
+	if cfg.Bookmarks.Conflict != "apricot" {
+		t.Errorf("Conflict = %q, want apricot by default", cfg.Bookmarks.Conflict)
+	}
+
+	t.Setenv("APRICOT_BOOKMARKS_TOKEN", "")
+	for name, section := range map[string]string{
+		"schedule without token": "service = \"pinboard\"\nsync_schedule = \"0 * * * *\"",
+		"unknown service":        "service = \"delicious\"\ntoken = \"x\"",
+		"no service":             "token = \"x\"",
+		"bad conflict":           "service = \"pinboard\"\ntoken = \"x\"\nconflict = \"newest\"",
+		"bad schedule":           "service = \"pinboard\"\ntoken = \"x\"\nsync_schedule = \"hourly\"",
+	} {
+		content := "[ai]\nprovider = \"mock\"\n\n[bookmarks]\n" + section + "\n"
+		if _, err := Load(writeTestConfig(t, content)); err == nil {
+			t.Errorf("%s: Load() expected an error, got nil", name)
+		}
+	}
+}
+
+func TestLoad_Vault(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	content := `
+[ai]
+provider = "mock"
+
+[vault]
+dir = "~/Obsidian/Reading"
+export_schedule = "0 * * * *"
+`
+	cfg, err := Load(writeTestConfig(t, content))
+	if err != nil {
+		t.Fatalf("Load() unexpected error: %v", err)
+	}
+	if want := filepath.Join(home, "Obsidian", "Reading"); cfg.Vault.Dir != want {
+		t.Errorf("Vault.Dir = %q, want %q", cfg.Vault.Dir, want)
+	}
+
+	for name, section := range map[string]string{
+		"relative dir":         "dir = \"Obsidian\"",
+		"schedule without dir": "export_schedule = \"0 * * * *\"",
+		"bad schedule":         "dir = \"/vault\"\nexport_schedule = \"hourly\"",
+	} {
+		content := "[ai]\nprovider = \"mock\"\n\n[vault]\n" + section + "\n"
+		if _, err := Load(writeTestConfig(t, content)); err == nil {
+			t.Errorf("%s: Load() expected an error, got nil", name)
+		}
+	}
+}
+
+func TestLoad_Kindle(t *testing.T) {
+	const emailSection = "[email]\nsmtp_host = \"smtp.example.com\"\nfrom = \"a@example.com\"\nto = [\"b@example.com\"]\n\n"
+	content := "[ai]\nprovider = \"mock\"\n\n" + emailSection +
+		"[kindle]\naddress = \"me@kindle.com\"\ncompilation_schedule = \"0 7 * * 6\"\n"
+	cfg, err := Load(writeTestConfig(t, content))
+	if err != nil {
+		t.Fatalf("Load() unexpected error: %v", err)
+	}
+	if !cfg.Kindle.Enabled() || cfg.Kindle.CompilationSchedule != "0 7 * * 6" {
+		t.Errorf("Kindle = %+v", cfg.Kindle)
+	}
+
+	for name, content := range map[string]string{
+		"no email":                 "[kindle]\naddress = \"me@kindle.com\"",
+		"bad address":              emailSection + "[kindle]\naddress = \"kindle\"",
+		"schedule without address": emailSection + "[kindle]\ncompilation_schedule = \"0 7 * * 6\"",
+		"bad schedule":             emailSection + "[kindle]\naddress = \"me@kindle.com\"\ncompilation_schedule = \"weekly\"",
+	} {
+		content := "[ai]\nprovider = \"mock\"\n\n" + content + "\n"
+		if _, err := Load(writeTestConfig(t, content)); err == nil {
+			t.Errorf("%s: Load() expected an error, got nil", name)
+		}
+	}
+}
+
+func TestLoad_Notion(t *testing.T) {
+	t.Setenv("APRICOT_NOTION_TOKEN", "secret_env")
+	content := `
+[ai]
+provider = "mock"
+
+[notion]
+token = "secret_file"
+database_id = "0123456789abcdef0123456789abcdef"
+`
+	cfg, err := Load(writeTestConfig(t, content))
+	if err != nil {
+		t.Fatalf("Load() unexpected error: %v", err)
+	}
+	if cfg.Notion.Token != "secret_env" {
+		t.Errorf("Notion.Token = %q, want the environment's", cfg.Notion.Token)
+	}
+	if !maps.Equal(cfg.Notion.Properties, DefaultNotionProperties) {
+		t.Errorf("Notion.Properties = %v, want the defaults", cfg.Notion.Properties)
+	}
+
+	content += "\n[notion.properties]\ntitle = \"Name\"\nstatus = \"\"\n"
+	cfg, err = Load(writeTestConfig(t, content))
+	if err != nil {
+		t.Fatalf("Load() unexpected error: %v", err)
+	}
+	if want := map[string]string{"title": "Name", "status": ""}; !maps.Equal(cfg.Notion.Properties, want) {
+		t.Errorf("Notion.Properties = %v, want %v", cfg.Notion.Properties, want)
+	}
+
+	t.Setenv("APRICOT_NOTION_TOKEN", "")
+	for name, section := range map[string]string{
+		"no database":            "token = \"secret\"",
+		"unknown field":          "token = \"secret\"\ndatabase_id = \"abc\"\n[notion.properties]\nauthor = \"Author\"",
+		"schedule without token": "database_id = \"abc\"\nsync_schedule = \"0 * * * *\"",
+		"bad schedule":           "token = \"secret\"\ndatabase_id = \"abc\"\nsync_schedule = \"hourly\"",
+	} {
+		content := "[ai]\nprovider = \"mock\"\n\n[notion]\n" + section + "\n"
+		if _, err := Load(writeTestConfig(t, content)); err == nil {
+			t.Errorf("%s: Load() expected an error, got nil", name)
+		}
+	}
+}
+
+func TestLoad_Ntfy(t *testing.T) {
+	content := `
+[ai]
+provider = "mock"
+
+[server]
+public_url = "http://laptop.local:8080"
+
+[[ntfy]]
+topic = "apricot-3f9a1c"
+must_read = ["postgres"]
+`
+	cfg, err := Load(writeTestConfig(t, content))
+	if err != nil {
+		t.Fatalf("Load() unexpected error: %v", err)
+	}
+	if len(cfg.Ntfy) != 1 || cfg.Ntfy[0].Server != "https://ntfy.sh" || cfg.Ntfy[0].ManualRuns {
+		t.Errorf("Ntfy = %+v, want ntfy.sh for scheduled runs only", cfg.Ntfy)
+	}
+
+	for name, tables := range map[string]string{
+		"no topic":   "[[ntfy]]\nserver = \"https://ntfy.sh\"",
+		"topic path": "[[ntfy]]\ntopic = \"a/b\"",
+		"bad server": "[[ntfy]]\ntopic = \"a\"\nserver = \"ntfy.sh\"",
+		"public url": "[server]\npublic_url = \"laptop.local\"",
+	} {
+		content := "[ai]\nprovider = \"mock\"\n\n" + tables + "\n"
+		if _, err := Load(writeTestConfig(t, content)); err == nil {
+			t.Errorf("%s: Load() expected an error, got nil", name)
+		}
+	}
+}
+
+func TestLoad_Slack(t *testing.T) {
+	content := `
+[ai]
+provider = "mock"
+
+[[slack]]
+webhook_url = "https://hooks.slack.com/services/T0/B0/x"
+top = 3
+`
+	cfg, err := Load(writeTestConfig(t, content))
+	if err != nil {
+		t.Fatalf("Load() unexpected error: %v", err)
+	}
+	if len(cfg.Slack) != 1 || cfg.Slack[0].Top != 3 {
+		t.Errorf("Slack = %+v, want the table", cfg.Slack)
+	}
+
+	for name, table := range map[string]string{
+		"http url": "webhook_url = \"http://hooks.slack.com/services/x\"",
+		"negative": "webhook_url = \"https://hooks.slack.com/services/x\"\ntop = -1",
+	} {
+		content := "[ai]\nprovider = \"mock\"\n\n[[slack]]\n" + table + "\n"
+		if _, err := Load(writeTestConfig(t, content)); err == nil {
+			t.Errorf("%s: Load() expected an error, got nil", name)
+		}
+	}
+}
+
+func TestLoad_DiscordTelegram(t *testing.T) {
+	content := `
+[ai]
+provider = "mock"
+
+[[discord]]
+webhook_url = "https://discord.com/api/webhooks/1/x"
+
+[[telegram]]
+bot_token = "123:abc"
+chat_id = -100200
+top = 3
+`
+	cfg, err := Load(writeTestConfig(t, content))
+	if err != nil {
+		t.Fatalf("Load() unexpected error: %v", err)
+	}
+	if len(cfg.Discord) != 1 || len(cfg.Telegram) != 1 || cfg.Telegram[0].ChatID != -100200 {
+		t.Errorf("Discord = %+v, Telegram = %+v; want both tables", cfg.Discord, cfg.Telegram)
+	}
+
+	for name, tables := range map[string]string{
+		"discord top":  "[[discord]]\nwebhook_url = \"https://discord.com/api/webhooks/1/x\"\ntop = 11",
+		"no chat":      "[[telegram]]\nbot_token = \"123:abc\"",
+		"bot reused":   "[[telegram]]\nbot_token = \"123:abc\"\nchat_id = 1\n\n[[telegram]]\nbot_token = \"123:abc\"\nchat_id = 2",
+		"discord http": "[[discord]]\nwebhook_url = \"http://discord.com/api/webhooks/1/x\"",
+	} {
+		content := "[ai]\nprovider = \"mock\"\n\n" + tables + "\n"
+		if _, err := Load(writeTestConfig(t, content)); err == nil {
+			t.Errorf("%s: Load() expected an error, got nil", name)
+		}
+	}
+}
+
+func TestIsLoopback(t *testing.T) {
+	for host, want := range map[string]bool{
+		"localhost":   true,
+		"127.0.0.1":   true,
+		"::1":         true,
+		"[::1]":       true,
+		"0.0.0.0":     false,
+		"192.168.1.5": false,
+		"apricot.lan": false,
+	} {
+		if got := IsLoopback(host); got != want {
+			t.Errorf("IsLoopback(%q) = %v, want %v", host, got, want)
+		}
+	}
+}
+
+func TestLoad_DiscoverSchedule(t *testing.T) {
+	content := `
+[ai]
+provider = "anthropic"
+api_key = "sk-test"
+
+[feeds]
+discover_schedule = "0 7 * * 1-5"
+`
+	cfg, err := Load(writeTestConfig(t, content))
+	if err != nil {
+		t.Fatalf("Load() unexpected error: %v", err)
+	}
+	if cfg.Feeds.DiscoverSchedule != "0 7 * * 1-5" {
+		t.Errorf("Feeds.DiscoverSchedule = %q, want %q", cfg.Feeds.DiscoverSchedule, "0 7 * * 1-5")
+	}
+
+	path := writeTestConfig(t, strings.Replace(content, "0 7 * * 1-5", "7am on weekdays", 1))
+	if _, err := Load(path); err == nil {
+		t.Fatalf("Load(%q) expected error for an invalid discover_schedule, got nil", path)
+	}
+}
+
+func TestLoad_SuggestSchedule(t *testing.T) {
+	content := `
+[ai]
+provider = "anthropic"
+api_key = "sk-test"
+
+[feeds]
+suggest_schedule = "0 9 * * 1"
+`
+	cfg, err := Load(writeTestConfig(t, content))
+	if err != nil {
+		t.Fatalf("Load() unexpected error: %v", err)
+	}
+	if cfg.Feeds.SuggestSchedule != "0 9 * * 1" {
+		t.Errorf("Feeds.SuggestSchedule = %q, want %q", cfg.Feeds.SuggestSchedule, "0 9 * * 1")
+	}
+
+	path := writeTestConfig(t, strings.Replace(content, "0 9 * * 1", "mondays", 1))
+	if _, err := Load(path); err == nil {
+		t.Fatalf("Load(%q) expected error for an invalid suggest_schedule, got nil", path)
+	}
+}
+
+func TestLoad_InvalidColdStorageMonths(t *testing.T) {
+	content := `
+[ai]
+provider = "anthropic"
+api_key = "sk-test"
+
+[storage]
+cold_storage_months = -1
+`
+	path := writeTestConfig(t, content)
+
+	if _, err := Load(path); err == nil {
+		t.Fatalf("Load(%q) expected error for negative cold_storage_months, got nil", path)
+	}
+}
+
+func TestLoad_InvalidRetentionDays(t *testing.T) {
+	content := `
+[ai]
+provider = "anthropic"
+api_key = "sk-test"
+
+[storage]
+retention_days = -1
+`
+	path := writeTestConfig(t, content)
+
+	if _, err := Load(path); err == nil {
+		t.Fatalf("Load(%q) expected error for negative retention_days, got nil", path)
+	}
+}
+
+func TestLoad_InvalidBackupSettings(t *testing.T) {
+	for _, setting := range []string{"backup_interval_hours = -1", "backup_keep = -2"} {
+		t.Run(setting, func(t *testing.T) {
+			path := writeTestConfig(t, `
+[ai]
+provider = "anthropic"
+api_key = "sk-test"
+
+[storage]
+`+setting+`
+`)
+			if _, err := Load(path); err == nil {
+				t.Fatalf("Load(%q) expected error for %s, got nil", path, setting)
+			}
+		})
+	}
+}
+
+func TestLoad_StorageDriver(t *testing.T) {
+	path := writeTestConfig(t, `
+[ai]
+provider = "anthropic"
+api_key = "sk-test"
+
+[storage]
+driver = "postgres"
+postgres_dsn = "postgres://apricot@localhost/apricot"
+max_open_conns = 8
+`)
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load(%q) unexpected error: %v", path, err)
+	}
+	if cfg.Storage.Driver != "postgres" || cfg.Storage.MaxOpenConns != 8 {
+		t.Errorf("Storage = %+v, want postgres driver with 8 max open conns", cfg.Storage)
+	}
+
+	path = writeTestConfig(t, `
+[ai]
+provider = "anthropic"
+api_key = "sk-test"
+`)
+	if cfg, err = Load(path); err != nil {
+		t.Fatalf("Load(%q) unexpected error: %v", path, err)
+	}
+	if cfg.Storage.Driver != "sqlite" {
+		t.Errorf("Storage.Driver = %q, want default %q", cfg.Storage.Driver, "sqlite")
+	}
+}
+
+func TestLoad_InvalidStorageDriver(t *testing.T) {
+	for _, settings := range []string{
+		`driver = "mysql"`,
+		`driver = "postgres"`, // no postgres_dsn
+		"max_open_conns = -1",
+	} {
+		t.Run(settings, func(t *testing.T) {
+			path := writeTestConfig(t, `
+[ai]
+provider = "anthropic"
+api_key = "sk-test"
+
+[storage]
+`+settings+`
+`)
+			if _, err := Load(path); err == nil {
+				t.Fatalf("Load(%q) expected error for %s, got nil", path, settings)
+			}
+		})
+	}
+}
+
+func TestLoad_InvalidTimeout(t *testing.T) {
+	content := `
+[ai]
+provider = "anthropic"
+api_key = "sk-test"
+
+[server]
+discovery_timeout_seconds = -5
+`
+	path := writeTestConfig(t, content)
+
+	if _, err := Load(path); err == nil {
+		t.Fatalf("Load(%q) expected error for negative discovery_timeout_seconds, got nil", path)
+	}
+}
+
+func TestLoad_DiscoveryBudget(t *testing.T) {
+	path := writeTestConfig(t, `
+[server]
+discovery_timeout_seconds = 100
+`)
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load(%q) unexpected error: %v", path, err)
+	}
+	if got := cfg.Server.DiscoveryBudget(); got != 80*time.Second {
+		t.Errorf("DiscoveryBudget() = %v, want 4/5 of the timeout", got)
+	}
+
+	for _, budget := range []string{"-1", "100", "120"} {
+		path := writeTestConfig(t, `
+[server]
+discovery_timeout_seconds = 100
+discovery_budget_seconds = `+budget+`
+`)
+		if _, err := Load(path); err == nil {
+			t.Errorf("Load() with discovery_budget_seconds = %s expected error, got nil", budget)
+		}
+	}
+}
+
 func TestLoad_EmptyAPIKey_NoError(t *testing.T) {
+	clearKeyEnv(t)
+
 	content := `
 [ai]
 provider = "anthropic"
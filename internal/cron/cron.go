@@ -0,0 +1,182 @@
+// Package cron parses standard five-field cron expressions ("minute hour
+// day-of-month month day-of-week") and computes when they next fire.
+package cron
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schedule is a parsed cron expression. The zero Schedule never fires.
+type Schedule struct {
+	minute, hour, dom, month, dow uint64 // bit n set means value n matches
+
+	// domAny and dowAny record a "*" day field. When both day fields are
+	// restricted, a day matches if either does, as in Vixie cron.
+	domAny, dowAny bool
+}
+
+// field describes the values one position of an expression accepts.
+type field struct {
+	name     string
+	min, max int
+	names    []string // names for min, min+1, ..., if any
+}
+
+var (
+	minuteField = field{name: "minute", min: 0, max: 59}
+	hourField   = field{name: "hour", min: 0, max: 23}
+	domField    = field{name: "day of month", min: 1, max: 31}
+	monthField  = field{name: "month", min: 1, max: 12,
+		names: []string{"jan", "feb", "mar", "apr", "may", "jun", "jul", "aug", "sep", "oct", "nov", "dec"}}
+	// Sunday is 0 or 7.
+	dowField = field{name: "day of week", min: 0, max: 7,
+		names: []string{"sun", "mon", "tue", "wed", "thu", "fri", "sat"}}
+)
+
+// macros are the shorthand expressions Parse accepts.
+var macros = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// Parse parses a five-field cron expression such as "0 7 * * 1-5" (07:00 on
+// weekdays). Each field is "*" or a comma-separated list of values and
+// ranges ("1-5"), either optionally with a step ("*/15", "0-30/10").
+// Months and days of the week may be given by their three-letter English
+// names. The shorthands @hourly, @daily, @weekly, @monthly, and @yearly are
+// also accepted.
+func Parse(spec string) (Schedule, error) {
+	spec = strings.TrimSpace(spec)
+	if expanded, ok := macros[strings.ToLower(spec)]; ok {
+		spec = expanded
+	}
+	parts := strings.Fields(spec)
+	if len(parts) != 5 {
+		return Schedule{}, fmt.Errorf("cron expression %q: want 5 fields (minute hour day-of-month month day-of-week), got %d", spec, len(parts))
+	}
+
+	var s Schedule
+	var err error
+	if s.minute, err = minuteField.parse(parts[0]); err != nil {
+		return Schedule{}, err
+	}
+	if s.hour, err = hourField.parse(parts[1]); err != nil {
+		return Schedule{}, err
+	}
+	if s.dom, err = domField.parse(parts[2]); err != nil {
+		return Schedule{}, err
+	}
+	if s.month, err = monthField.parse(parts[3]); err != nil {
+		return Schedule{}, err
+	}
+	if s.dow, err = dowField.parse(parts[4]); err != nil {
+		return Schedule{}, err
+	}
+	if s.dow&(1<<7) != 0 {
+		s.dow |= 1 // 7 is Sunday too
+	}
+	s.domAny = strings.HasPrefix(parts[2], "*")
+	s.dowAny = strings.HasPrefix(parts[4], "*")
+	return s, nil
+}
+
+// parse parses one field of an expression into a bit set.
+func (f field) parse(expr string) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(expr, ",") {
+		rangeExpr, stepExpr, hasStep := strings.Cut(part, "/")
+		step := 1
+		if hasStep {
+			n, err := strconv.Atoi(stepExpr)
+			if err != nil || n < 1 {
+				return 0, fmt.Errorf("cron %s field %q: invalid step %q", f.name, expr, stepExpr)
+			}
+			step = n
+		}
+
+		lo, hi := f.min, f.max
+		if rangeExpr != "*" {
+			loExpr, hiExpr, isRange := strings.Cut(rangeExpr, "-")
+			var err error
+			if lo, err = f.value(loExpr); err != nil {
+				return 0, fmt.Errorf("cron %s field %q: %w", f.name, expr, err)
+			}
+			switch {
+			case isRange:
+				if hi, err = f.value(hiExpr); err != nil {
+					return 0, fmt.Errorf("cron %s field %q: %w", f.name, expr, err)
+				}
+				if hi < lo {
+					return 0, fmt.Errorf("cron %s field %q: range %q runs backwards", f.name, expr, rangeExpr)
+				}
+			case !hasStep:
+				hi = lo // a single value; "5/15" means from 5 on
+			}
+		}
+
+		for v := lo; v <= hi; v += step {
+			bits |= 1 << v
+		}
+	}
+	return bits, nil
+}
+
+// value parses a single number or name of the field.
+func (f field) value(s string) (int, error) {
+	for i, name := range f.names {
+		if strings.EqualFold(s, name) {
+			return f.min + i, nil
+		}
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid value %q", s)
+	}
+	if n < f.min || n > f.max {
+		return 0, fmt.Errorf("value %d out of range %d-%d", n, f.min, f.max)
+	}
+	return n, nil
+}
+
+// Next returns the first time after t, to the minute and in t's location,
+// at which the schedule fires, or the zero time if it never does within
+// five years (as for "0 0 30 2 *").
+func (s Schedule) Next(t time.Time) time.Time {
+	loc := t.Location()
+	t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), 0, 0, loc).Add(time.Minute)
+	limit := t.AddDate(5, 0, 0)
+
+	for t.Before(limit) {
+		switch {
+		case s.month&(1<<uint(t.Month())) == 0:
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, loc)
+		case !s.dayMatches(t):
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, loc)
+		case s.hour&(1<<uint(t.Hour())) == 0:
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, loc)
+		case s.minute&(1<<uint(t.Minute())) == 0:
+			t = t.Add(time.Minute)
+		default:
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+// dayMatches reports whether the schedule fires on t's day.
+func (s Schedule) dayMatches(t time.Time) bool {
+	domMatch := s.dom&(1<<uint(t.Day())) != 0
+	dowMatch := s.dow&(1<<uint(t.Weekday())) != 0
+	if s.domAny || s.dowAny {
+		return domMatch && dowMatch
+	}
+	return domMatch || dowMatch
+}
@@ -0,0 +1,72 @@
+package cron
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNext(t *testing.T) {
+	// Thursday, 2025-03-06 09:30.
+	from := time.Date(2025, 3, 6, 9, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		spec string
+		want time.Time
+	}{
+		{"* * * * *", time.Date(2025, 3, 6, 9, 31, 0, 0, time.UTC)},
+		{"0 7 * * 1-5", time.Date(2025, 3, 7, 7, 0, 0, 0, time.UTC)},
+		{"0 7 * * mon", time.Date(2025, 3, 10, 7, 0, 0, 0, time.UTC)},
+		{"0 7 * * 7", time.Date(2025, 3, 9, 7, 0, 0, 0, time.UTC)},
+		{"*/15 * * * *", time.Date(2025, 3, 6, 9, 45, 0, 0, time.UTC)},
+		{"0,45 9-10 * * *", time.Date(2025, 3, 6, 9, 45, 0, 0, time.UTC)},
+		{"0 0 1 jan *", time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{"@daily", time.Date(2025, 3, 7, 0, 0, 0, 0, time.UTC)},
+		// Both day fields restricted: either may match.
+		{"0 12 15 * fri", time.Date(2025, 3, 7, 12, 0, 0, 0, time.UTC)},
+		{"0 0 29 2 *", time.Date(2028, 2, 29, 0, 0, 0, 0, time.UTC)},
+		{"0 0 30 2 *", time.Time{}},
+	}
+	for _, tt := range tests {
+		s, err := Parse(tt.spec)
+		if err != nil {
+			t.Errorf("Parse(%q) error: %v", tt.spec, err)
+			continue
+		}
+		if got := s.Next(from); !got.Equal(tt.want) {
+			t.Errorf("Parse(%q).Next(%v) = %v, want %v", tt.spec, from, got, tt.want)
+		}
+	}
+}
+
+func TestNext_KeepsLocation(t *testing.T) {
+	loc := time.FixedZone("UTC+7", 7*60*60)
+	s, err := Parse("0 7 * * *")
+	if err != nil {
+		t.Fatalf("Parse() error: %v", err)
+	}
+	got := s.Next(time.Date(2025, 3, 6, 6, 59, 30, 0, loc))
+	if want := time.Date(2025, 3, 6, 7, 0, 0, 0, loc); !got.Equal(want) || got.Location() != loc {
+		t.Errorf("Next() = %v, want %v", got, want)
+	}
+}
+
+func TestParse_Invalid(t *testing.T) {
+	for _, spec := range []string{
+		"",
+		"0 7 * *",
+		"0 7 * * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"5-1 * * * *",
+		"*/0 * * * *",
+		"a * * * *",
+		"* * * foo *",
+	} {
+		if _, err := Parse(spec); err == nil {
+			t.Errorf("Parse(%q) succeeded, want an error", spec)
+		}
+	}
+}
@@ -0,0 +1,213 @@
+// Package email sends HTML email over SMTP.
+package email
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"fmt"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	netmail "net/mail"
+	"net/smtp"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Mailer sends mail through an SMTP server. On port 465 it speaks TLS from
+// the start; on any other port it upgrades with STARTTLS when the server
+// offers it. It only sends a password over TLS or to localhost.
+type Mailer struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+
+	// From is the sender, as an address or "Name <address>".
+	From string
+}
+
+// Message is an email with an HTML body and a plain-text alternative, and
+// any files attached.
+type Message struct {
+	To          []string
+	Subject     string
+	HTML        string
+	Text        string
+	Attachments []Attachment
+}
+
+// Attachment is a file attached to a message.
+type Attachment struct {
+	Name        string // file name
+	ContentType string
+	Data        []byte
+}
+
+// Send sends msg.
+func (m *Mailer) Send(ctx context.Context, msg Message) error {
+	from, err := netmail.ParseAddress(m.From)
+	if err != nil {
+		return fmt.Errorf("parsing sender: %w", err)
+	}
+	data, err := m.build(msg, time.Now())
+	if err != nil {
+		return err
+	}
+
+	addr := net.JoinHostPort(m.Host, strconv.Itoa(m.Port))
+	var conn net.Conn
+	if m.Port == 465 {
+		conn, err = (&tls.Dialer{Config: &tls.Config{ServerName: m.Host}}).DialContext(ctx, "tcp", addr)
+	} else {
+		conn, err = (&net.Dialer{}).DialContext(ctx, "tcp", addr)
+	}
+	if err != nil {
+		return fmt.Errorf("connecting to %s: %w", addr, err)
+	}
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(time.Minute)
+	}
+	conn.SetDeadline(deadline) //nolint:errcheck
+
+	c, err := smtp.NewClient(conn, m.Host)
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("starting SMTP session: %w", err)
+	}
+	defer c.Close()
+
+	if ok, _ := c.Extension("STARTTLS"); ok && m.Port != 465 {
+		if err := c.StartTLS(&tls.Config{ServerName: m.Host}); err != nil {
+			return fmt.Errorf("starting TLS: %w", err)
+		}
+	}
+	if m.Username != "" {
+		if err := c.Auth(smtp.PlainAuth("", m.Username, m.Password, m.Host)); err != nil {
+			return fmt.Errorf("authenticating: %w", err)
+		}
+	}
+	if err := c.Mail(from.Address); err != nil {
+		return fmt.Errorf("sending MAIL FROM: %w", err)
+	}
+	for _, to := range msg.To {
+		rcpt, err := netmail.ParseAddress(to)
+		if err != nil {
+			return fmt.Errorf("parsing recipient %q: %w", to, err)
+		}
+		if err := c.Rcpt(rcpt.Address); err != nil {
+			return fmt.Errorf("sending RCPT TO %s: %w", rcpt.Address, err)
+		}
+	}
+	w, err := c.Data()
+	if err != nil {
+		return fmt.Errorf("sending DATA: %w", err)
+	}
+	if _, err := w.Write(data); err != nil {
+		return fmt.Errorf("sending message: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("sending message: %w", err)
+	}
+	return c.Quit()
+}
+
+// build returns msg as a MIME message with text and HTML alternatives,
+// wrapped with its attachments, if any, in a multipart/mixed message.
+func (m *Mailer) build(msg Message, now time.Time) ([]byte, error) {
+	body, contentType, err := alternatives(msg)
+	if err != nil {
+		return nil, err
+	}
+	if len(msg.Attachments) > 0 {
+		if body, contentType, err = mixed(body, contentType, msg.Attachments); err != nil {
+			return nil, err
+		}
+	}
+
+	var out bytes.Buffer
+	for _, h := range [][2]string{
+		{"From", m.From},
+		{"To", strings.Join(msg.To, ", ")},
+		{"Subject", mime.QEncoding.Encode("utf-8", msg.Subject)},
+		{"Date", now.Format(time.RFC1123Z)},
+		{"MIME-Version", "1.0"},
+		{"Content-Type", contentType},
+	} {
+		fmt.Fprintf(&out, "%s: %s\r\n", h[0], h[1])
+	}
+	out.WriteString("\r\n")
+	out.Write(body)
+	return out.Bytes(), nil
+}
+
+// alternatives returns the multipart/alternative body of msg's text and
+// HTML, and its content type.
+func alternatives(msg Message) ([]byte, string, error) {
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	for _, part := range []struct{ contentType, content string }{
+		{"text/plain; charset=utf-8", msg.Text},
+		{"text/html; charset=utf-8", msg.HTML},
+	} {
+		pw, err := mw.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {part.contentType},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return nil, "", err
+		}
+		qw := quotedprintable.NewWriter(pw)
+		if _, err := qw.Write([]byte(part.content)); err != nil {
+			return nil, "", err
+		}
+		if err := qw.Close(); err != nil {
+			return nil, "", err
+		}
+	}
+	if err := mw.Close(); err != nil {
+		return nil, "", err
+	}
+	return body.Bytes(), "multipart/alternative; boundary=" + mw.Boundary(), nil
+}
+
+// mixed returns a multipart/mixed body of content, of type contentType,
+// followed by attachments in base64, and its content type.
+func mixed(content []byte, contentType string, attachments []Attachment) ([]byte, string, error) {
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	pw, err := mw.CreatePart(textproto.MIMEHeader{"Content-Type": {contentType}})
+	if err != nil {
+		return nil, "", err
+	}
+	if _, err := pw.Write(content); err != nil {
+		return nil, "", err
+	}
+	for _, a := range attachments {
+		pw, err := mw.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {mime.FormatMediaType(a.ContentType, map[string]string{"name": a.Name})},
+			"Content-Disposition":       {mime.FormatMediaType("attachment", map[string]string{"filename": a.Name})},
+			"Content-Transfer-Encoding": {"base64"},
+		})
+		if err != nil {
+			return nil, "", err
+		}
+		// Base64 in lines of 76 characters, as MIME requires.
+		encoded := base64.StdEncoding.EncodeToString(a.Data)
+		for len(encoded) > 76 {
+			fmt.Fprintf(pw, "%s\r\n", encoded[:76])
+			encoded = encoded[76:]
+		}
+		fmt.Fprintf(pw, "%s\r\n", encoded)
+	}
+	if err := mw.Close(); err != nil {
+		return nil, "", err
+	}
+	return body.Bytes(), "multipart/mixed; boundary=" + mw.Boundary(), nil
+}
@@ -0,0 +1,161 @@
+package email
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/base64"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/mail"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeSMTP accepts one message on a local port and sends its envelope and
+// data on the returned channel.
+func fakeSMTP(t *testing.T) (int, <-chan []string) {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+
+	got := make(chan []string, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		r := bufio.NewReader(conn)
+		reply := func(s string) { conn.Write([]byte(s + "\r\n")) } //nolint:errcheck
+		var lines []string
+		reply("220 fake ESMTP")
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				return
+			}
+			line = strings.TrimRight(line, "\r\n")
+			switch cmd := strings.ToUpper(strings.Fields(line + " x")[0]); cmd {
+			case "EHLO", "HELO":
+				reply("250 fake")
+			case "MAIL", "RCPT":
+				lines = append(lines, line)
+				reply("250 ok")
+			case "DATA":
+				reply("354 go ahead")
+				for {
+					l, err := r.ReadString('\n')
+					if err != nil {
+						return
+					}
+					if l == ".\r\n" {
+						break
+					}
+					lines = append(lines, strings.TrimRight(l, "\r\n"))
+				}
+				reply("250 queued")
+			case "QUIT":
+				reply("221 bye")
+				got <- lines
+				return
+			default:
+				reply("502 not implemented")
+			}
+		}
+	}()
+	return ln.Addr().(*net.TCPAddr).Port, got
+}
+
+func TestSend(t *testing.T) {
+	port, got := fakeSMTP(t)
+	m := &Mailer{Host: "127.0.0.1", Port: port, From: "Apricot <apricot@example.com>"}
+	msg := Message{
+		To:      []string{"Ann <ann@example.com>", "bob@example.com"},
+		Subject: "Your digest ☕",
+		HTML:    "<p>Hello</p>",
+		Text:    "Hello",
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := m.Send(ctx, msg); err != nil {
+		t.Fatalf("Send() error: %v", err)
+	}
+
+	lines := <-got
+	for _, want := range []string{"MAIL FROM:<apricot@example.com>", "RCPT TO:<ann@example.com>", "RCPT TO:<bob@example.com>"} {
+		if !slices.Contains(lines, want) {
+			t.Errorf("envelope is missing %q:\n%s", want, strings.Join(lines, "\n"))
+		}
+	}
+
+	data := strings.Join(lines[3:], "\r\n")
+	parsed, err := mail.ReadMessage(strings.NewReader(data))
+	if err != nil {
+		t.Fatalf("parsing sent message: %v", err)
+	}
+	subject, err := new(mime.WordDecoder).DecodeHeader(parsed.Header.Get("Subject"))
+	if err != nil || subject != msg.Subject {
+		t.Errorf("Subject = %q (%v), want %q", subject, err, msg.Subject)
+	}
+	if !strings.HasPrefix(parsed.Header.Get("Content-Type"), "multipart/alternative") {
+		t.Errorf("Content-Type = %q, want multipart/alternative", parsed.Header.Get("Content-Type"))
+	}
+	if !strings.Contains(data, "<p>Hello</p>") {
+		t.Error("message is missing the HTML body")
+	}
+}
+
+func TestSend_BadSender(t *testing.T) {
+	m := &Mailer{Host: "127.0.0.1", Port: 1, From: "not an address"}
+	if err := m.Send(context.Background(), Message{To: []string{"a@example.com"}}); err == nil {
+		t.Error("Send() with an invalid sender: want an error")
+	}
+}
+
+func TestBuild_Attachments(t *testing.T) {
+	m := &Mailer{From: "apricot@example.com"}
+	doc := []byte(strings.Repeat("<p>Chapter</p>", 20))
+	data, err := m.build(Message{
+		To:          []string{"me@kindle.com"},
+		Subject:     "Reading",
+		HTML:        "<p>Attached.</p>",
+		Text:        "Attached.",
+		Attachments: []Attachment{{Name: "Café notes.html", ContentType: "text/html", Data: doc}},
+	}, time.Now())
+	if err != nil {
+		t.Fatalf("build() error: %v", err)
+	}
+
+	parsed, err := mail.ReadMessage(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("parsing message: %v", err)
+	}
+	mediaType, params, err := mime.ParseMediaType(parsed.Header.Get("Content-Type"))
+	if err != nil || mediaType != "multipart/mixed" {
+		t.Fatalf("Content-Type = %q (%v), want multipart/mixed", mediaType, err)
+	}
+	mr := multipart.NewReader(parsed.Body, params["boundary"])
+	first, err := mr.NextPart()
+	if err != nil || !strings.HasPrefix(first.Header.Get("Content-Type"), "multipart/alternative") {
+		t.Fatalf("first part = %v (%v), want the alternatives", first.Header, err)
+	}
+	attached, err := mr.NextPart()
+	if err != nil {
+		t.Fatalf("reading attachment: %v", err)
+	}
+	if attached.FileName() != "Café notes.html" {
+		t.Errorf("attachment name = %q", attached.FileName())
+	}
+	got, err := io.ReadAll(base64.NewDecoder(base64.StdEncoding, attached))
+	if err != nil || !bytes.Equal(got, doc) {
+		t.Errorf("attachment = %q (%v), want the document", got, err)
+	}
+}
@@ -0,0 +1,163 @@
+package epub
+
+import (
+	"bytes"
+	"net/url"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// kept lists the elements kept in a chapter; the others are replaced by
+// their content, except those in dropped.
+var kept = map[atom.Atom]bool{
+	atom.A: true, atom.Abbr: true, atom.B: true, atom.Blockquote: true,
+	atom.Br: true, atom.Caption: true, atom.Cite: true, atom.Code: true,
+	atom.Dd: true, atom.Del: true, atom.Div: true, atom.Dl: true,
+	atom.Dt: true, atom.Em: true, atom.Figcaption: true, atom.Figure: true,
+	atom.H1: true, atom.H2: true, atom.H3: true, atom.H4: true,
+	atom.H5: true, atom.H6: true, atom.Hr: true, atom.I: true,
+	atom.Img: true, atom.Ins: true, atom.Kbd: true, atom.Li: true,
+	atom.Mark: true, atom.Ol: true, atom.P: true, atom.Pre: true,
+	atom.Q: true, atom.S: true, atom.Samp: true, atom.Small: true,
+	atom.Span: true, atom.Strong: true, atom.Sub: true, atom.Sup: true,
+	atom.Table: true, atom.Tbody: true, atom.Td: true, atom.Tfoot: true,
+	atom.Th: true, atom.Thead: true, atom.Tr: true, atom.U: true,
+	atom.Ul: true, atom.Var: true,
+}
+
+// dropped lists the elements removed along with their content.
+var dropped = map[atom.Atom]bool{
+	atom.Script: true, atom.Style: true, atom.Noscript: true,
+	atom.Iframe: true, atom.Object: true, atom.Embed: true,
+	atom.Form: true, atom.Button: true, atom.Input: true,
+	atom.Select: true, atom.Textarea: true, atom.Canvas: true,
+	atom.Video: true, atom.Audio: true, atom.Source: true,
+	atom.Svg: true, atom.Math: true, atom.Template: true,
+}
+
+// keptAttrs lists the attributes kept, by element; the rest are dropped,
+// ids and classes included, so chapters cannot clash.
+var keptAttrs = map[atom.Atom][]string{
+	atom.A:   {"href"},
+	atom.Img: {"src", "alt"},
+	atom.Td:  {"colspan", "rowspan"},
+	atom.Th:  {"colspan", "rowspan"},
+}
+
+// clean parses fragment, an article's HTML, and returns it as XHTML that
+// holds only plain formatting, links to web pages, and images, after
+// calling image with each image's absolute URL. image returns the path
+// the image is stored under in the book, or "" to leave it out.
+func clean(fragment string, page *url.URL, image func(src string) string) string {
+	body := &html.Node{Type: html.ElementNode, Data: "body", DataAtom: atom.Body}
+	nodes, err := html.ParseFragment(strings.NewReader(fragment), body)
+	if err != nil {
+		// The parser only fails on a failing reader.
+		return ""
+	}
+	for _, n := range nodes {
+		body.AppendChild(n)
+	}
+	cleanChildren(body, page, image)
+
+	var b bytes.Buffer
+	for n := body.FirstChild; n != nil; n = n.NextSibling {
+		// html.Render writes void elements as <br/>, which XHTML needs.
+		html.Render(&b, n) //nolint:errcheck
+	}
+	return b.String()
+}
+
+// cleanChildren cleans the children of n in place.
+func cleanChildren(n *html.Node, page *url.URL, image func(string) string) {
+	for c := n.FirstChild; c != nil; {
+		next := c.NextSibling
+		switch c.Type {
+		case html.ElementNode:
+			cleanElement(c, page, image)
+		case html.TextNode:
+		default:
+			n.RemoveChild(c)
+		}
+		c = next
+	}
+}
+
+// cleanElement cleans n, which may remove it or replace it with its
+// children.
+func cleanElement(n *html.Node, page *url.URL, image func(string) string) {
+	parent := n.Parent
+	if dropped[n.DataAtom] || n.Namespace != "" {
+		parent.RemoveChild(n)
+		return
+	}
+	cleanChildren(n, page, image)
+	if !kept[n.DataAtom] {
+		for c := n.FirstChild; c != nil; c = n.FirstChild {
+			n.RemoveChild(c)
+			parent.InsertBefore(c, n)
+		}
+		parent.RemoveChild(n)
+		return
+	}
+
+	var attrs []html.Attribute
+	for _, a := range n.Attr {
+		for _, key := range keptAttrs[n.DataAtom] {
+			if a.Namespace == "" && a.Key == key {
+				attrs = append(attrs, a)
+			}
+		}
+	}
+	n.Attr = attrs
+
+	switch n.DataAtom {
+	case atom.A:
+		href := resolve(page, attr(n, "href"))
+		n.Attr = nil
+		if href != "" {
+			n.Attr = []html.Attribute{{Key: "href", Val: href}}
+		}
+	case atom.Img:
+		var src string
+		if u := resolve(page, attr(n, "src")); u != "" {
+			src = image(u)
+		}
+		if src == "" {
+			parent.RemoveChild(n)
+			return
+		}
+		n.Attr = []html.Attribute{{Key: "src", Val: src}, {Key: "alt", Val: attr(n, "alt")}}
+	}
+}
+
+// resolve returns ref as an absolute http(s) URL relative to page, or ""
+// if it is not one.
+func resolve(page *url.URL, ref string) string {
+	if ref == "" {
+		return ""
+	}
+	u, err := url.Parse(strings.TrimSpace(ref))
+	if err != nil {
+		return ""
+	}
+	if page != nil {
+		u = page.ResolveReference(u)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return ""
+	}
+	return u.String()
+}
+
+// attr returns the value of n's attribute key.
+func attr(n *html.Node, key string) string {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return a.Val
+		}
+	}
+	return ""
+}
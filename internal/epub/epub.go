@@ -0,0 +1,301 @@
+// Package epub bundles articles into an EPUB 3 book for e-readers: one
+// chapter per article, cleaned down to plain formatting (see clean), with
+// its images stored in the book and a table of contents that EPUB 2
+// readers understand too.
+package epub
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"html/template"
+	"io"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Chapter is an article to include in a book.
+type Chapter struct {
+	Title       string
+	Source      string
+	URL         string
+	PublishedAt *time.Time
+
+	// HTML is the article's content as an HTML fragment, such as
+	// go-readability extracts. Text is used instead when it is empty.
+	HTML string
+
+	// Text is the article's plain text, paragraphs on separate lines.
+	Text string
+}
+
+// Book is the content of an EPUB.
+type Book struct {
+	Title    string
+	Chapters []Chapter
+}
+
+// ImageFunc fetches the image at an absolute URL, returning its content
+// and media type.
+type ImageFunc func(ctx context.Context, url string) ([]byte, string, error)
+
+// MaxImages is the most images stored in one book; later ones are left
+// out.
+const MaxImages = 200
+
+// imageExts maps the image types EPUB readers must support to file
+// extensions. Images of other types are left out.
+var imageExts = map[string]string{
+	"image/jpeg":    ".jpg",
+	"image/png":     ".png",
+	"image/gif":     ".gif",
+	"image/webp":    ".webp",
+	"image/svg+xml": ".svg",
+}
+
+// image is an image stored in the book.
+type image struct {
+	ID, Path, MediaType string
+	data                []byte
+}
+
+// chapter is a chapter as the templates show it.
+type chapter struct {
+	Chapter
+	ID, Path, Date string
+	Order          int // position in the book, from 1
+	Content        template.HTML
+	Paragraphs     []string
+}
+
+// Write writes book to w as an EPUB, fetching the chapters' images with
+// fetch; an image that cannot be fetched is left out, as are all of them
+// if fetch is nil. modified is the
+// book's modification time, which readers show as its date.
+func Write(ctx context.Context, w io.Writer, book Book, fetch ImageFunc, modified time.Time) error {
+	var (
+		chapters []chapter
+		images   []*image
+		byURL    = make(map[string]*image)
+	)
+	store := func(src string) string {
+		if img, ok := byURL[src]; ok {
+			if img == nil {
+				return ""
+			}
+			return img.Path
+		}
+		byURL[src] = nil
+		if fetch == nil || len(images) >= MaxImages || ctx.Err() != nil {
+			return ""
+		}
+		data, mediaType, err := fetch(ctx, src)
+		ext, ok := imageExts[mediaType]
+		if err != nil || !ok {
+			return ""
+		}
+		img := &image{
+			ID:        fmt.Sprintf("image-%d", len(images)+1),
+			Path:      fmt.Sprintf("images/%d%s", len(images)+1, ext),
+			MediaType: mediaType,
+			data:      data,
+		}
+		images = append(images, img)
+		byURL[src] = img
+		return img.Path
+	}
+
+	for i, c := range book.Chapters {
+		ch := chapter{
+			Chapter: c,
+			ID:      fmt.Sprintf("chapter-%d", i+1),
+			Path:    fmt.Sprintf("chapter-%d.xhtml", i+1),
+			Order:   i + 1,
+		}
+		if c.PublishedAt != nil {
+			ch.Date = c.PublishedAt.Format("January 2, 2006")
+		}
+		if c.HTML != "" {
+			page, _ := url.Parse(c.URL)
+			ch.Content = template.HTML(clean(c.HTML, page, store))
+		}
+		if ch.Content == "" {
+			for _, line := range strings.Split(c.Text, "\n") {
+				if line = strings.TrimSpace(line); line != "" {
+					ch.Paragraphs = append(ch.Paragraphs, line)
+				}
+			}
+		}
+		chapters = append(chapters, ch)
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	data := struct {
+		Title, ID, Modified string
+		Chapters            []chapter
+		Images              []*image
+	}{
+		Title:    book.Title,
+		ID:       bookID(book),
+		Modified: modified.UTC().Format(time.RFC3339),
+		Chapters: chapters,
+		Images:   images,
+	}
+
+	zw := zip.NewWriter(w)
+	// The mimetype file comes first and uncompressed, so the file's type
+	// can be read from its first bytes.
+	mw, err := zw.CreateHeader(&zip.FileHeader{Name: "mimetype", Method: zip.Store})
+	if err != nil {
+		return err
+	}
+	if _, err := io.WriteString(mw, "application/epub+zip"); err != nil {
+		return err
+	}
+	files := []struct {
+		name string
+		tmpl *template.Template
+		data any
+	}{
+		{"META-INF/container.xml", containerXML, nil},
+		{"OEBPS/content.opf", contentOPF, data},
+		{"OEBPS/nav.xhtml", navXHTML, data},
+		{"OEBPS/toc.ncx", tocNCX, data},
+		{"OEBPS/style.css", styleCSS, nil},
+	}
+	for _, ch := range chapters {
+		files = append(files, struct {
+			name string
+			tmpl *template.Template
+			data any
+		}{"OEBPS/" + ch.Path, chapterXHTML, ch})
+	}
+	for _, f := range files {
+		var b bytes.Buffer
+		if err := f.tmpl.Execute(&b, f.data); err != nil {
+			return fmt.Errorf("rendering %s: %w", f.name, err)
+		}
+		fw, err := zw.Create(f.name)
+		if err != nil {
+			return err
+		}
+		if _, err := fw.Write(b.Bytes()); err != nil {
+			return err
+		}
+	}
+	for _, img := range images {
+		fw, err := zw.Create("OEBPS/" + img.Path)
+		if err != nil {
+			return err
+		}
+		if _, err := fw.Write(img.data); err != nil {
+			return err
+		}
+	}
+	return zw.Close()
+}
+
+// bookID returns an identifier for book that stays the same when the same
+// articles are exported again.
+func bookID(book Book) string {
+	h := sha256.New()
+	for _, c := range book.Chapters {
+		fmt.Fprintln(h, c.URL)
+	}
+	return "apricot-" + hex.EncodeToString(h.Sum(nil)[:16])
+}
+
+var containerXML = template.Must(template.New("container").Parse(`<?xml version="1.0" encoding="UTF-8"?>
+<container version="1.0" xmlns="urn:oasis:names:tc:opendocument:xmlns:container">
+  <rootfiles>
+    <rootfile full-path="OEBPS/content.opf" media-type="application/oebps-package+xml"/>
+  </rootfiles>
+</container>
+`))
+
+var contentOPF = template.Must(template.New("opf").Parse(`<?xml version="1.0" encoding="UTF-8"?>
+<package xmlns="http://www.idpf.org/2007/opf" version="3.0" unique-identifier="book-id">
+  <metadata xmlns:dc="http://purl.org/dc/elements/1.1/">
+    <dc:identifier id="book-id">{{.ID}}</dc:identifier>
+    <dc:title>{{.Title}}</dc:title>
+    <dc:language>en</dc:language>
+    <dc:creator>Apricot</dc:creator>
+    <meta property="dcterms:modified">{{.Modified}}</meta>
+  </metadata>
+  <manifest>
+    <item id="nav" href="nav.xhtml" media-type="application/xhtml+xml" properties="nav"/>
+    <item id="ncx" href="toc.ncx" media-type="application/x-dtbncx+xml"/>
+    <item id="style" href="style.css" media-type="text/css"/>
+{{range .Chapters}}    <item id="{{.ID}}" href="{{.Path}}" media-type="application/xhtml+xml"/>
+{{end}}{{range .Images}}    <item id="{{.ID}}" href="{{.Path}}" media-type="{{.MediaType}}"/>
+{{end}}  </manifest>
+  <spine toc="ncx">
+    <itemref idref="nav"/>
+{{range .Chapters}}    <itemref idref="{{.ID}}"/>
+{{end}}  </spine>
+</package>
+`))
+
+var navXHTML = template.Must(template.New("nav").Parse(`<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE html>
+<html xmlns="http://www.w3.org/1999/xhtml" xmlns:epub="http://www.idpf.org/2007/ops" lang="en">
+<head>
+<title>{{.Title}}</title>
+<link rel="stylesheet" type="text/css" href="style.css"/>
+</head>
+<body>
+<nav epub:type="toc" id="toc">
+<h1>{{.Title}}</h1>
+<ol>
+{{range .Chapters}}<li><a href="{{.Path}}">{{.Title}}</a>{{if .Source}} <span class="source">{{.Source}}</span>{{end}}</li>
+{{end}}</ol>
+</nav>
+</body>
+</html>
+`))
+
+var tocNCX = template.Must(template.New("ncx").Parse(`<?xml version="1.0" encoding="UTF-8"?>
+<ncx xmlns="http://www.daisy.org/z3986/2005/ncx/" version="2005-1">
+  <head>
+    <meta name="dtb:uid" content="{{.ID}}"/>
+  </head>
+  <docTitle><text>{{.Title}}</text></docTitle>
+  <navMap>
+{{range .Chapters}}    <navPoint id="nav-{{.ID}}" playOrder="{{.Order}}">
+      <navLabel><text>{{.Title}}</text></navLabel>
+      <content src="{{.Path}}"/>
+    </navPoint>
+{{end}}  </navMap>
+</ncx>
+`))
+
+var chapterXHTML = template.Must(template.New("chapter").Parse(`<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE html>
+<html xmlns="http://www.w3.org/1999/xhtml" lang="en">
+<head>
+<title>{{.Title}}</title>
+<link rel="stylesheet" type="text/css" href="style.css"/>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+{{if or .Source .Date}}<p class="byline">{{.Source}}{{if and .Source .Date}} · {{end}}{{.Date}}</p>
+{{end}}{{if .Content}}{{.Content}}
+{{else}}{{range .Paragraphs}}<p>{{.}}</p>
+{{end}}{{end}}<p class="source"><a href="{{.URL}}">{{.URL}}</a></p>
+</body>
+</html>
+`))
+
+var styleCSS = template.Must(template.New("style").Parse(`body { line-height: 1.5; }
+img { max-width: 100%; height: auto; }
+pre { white-space: pre-wrap; font-size: 0.85em; }
+blockquote { margin-left: 1em; padding-left: 1em; border-left: 2px solid #999; }
+.byline, .source { font-style: italic; }
+.source { margin-top: 2em; word-wrap: break-word; }
+`))
@@ -0,0 +1,138 @@
+package epub
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/xml"
+	"errors"
+	"io"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestClean(t *testing.T) {
+	page, _ := url.Parse("https://a.example/posts/cache")
+	var fetched []string
+	image := func(src string) string {
+		fetched = append(fetched, src)
+		if strings.HasSuffix(src, "missing.png") {
+			return ""
+		}
+		return "images/1.png"
+	}
+	got := clean(`<div id="main" class="x"><p onclick="evil()">Hi <a href="/about">me</a> &amp; <a href="javascript:alert(1)">you</a><br>
+<img src="fig.png" alt="A figure" width="10"><img src="missing.png"></p>
+<script>alert(1)</script><custom-tag>kept text</custom-tag><svg><circle/></svg>
+<pre>a &lt; b</pre></div>`, page, image)
+
+	for _, want := range []string{
+		`<div><p>Hi <a href="https://a.example/about">me</a> &amp; <a>you</a><br/>`,
+		`<img src="images/1.png" alt="A figure"/></p>`,
+		`kept text`,
+		`<pre>a &lt; b</pre>`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("cleaned HTML is missing %q:\n%s", want, got)
+		}
+	}
+	for _, unwanted := range []string{"script", "alert", "onclick", "custom-tag", "circle", "missing.png", `id="main"`} {
+		if strings.Contains(got, unwanted) {
+			t.Errorf("cleaned HTML still has %q:\n%s", unwanted, got)
+		}
+	}
+	if len(fetched) != 2 || fetched[0] != "https://a.example/posts/fig.png" {
+		t.Errorf("images fetched = %v, want the two images by absolute URL", fetched)
+	}
+}
+
+func TestWrite(t *testing.T) {
+	published := time.Date(2026, 10, 12, 0, 0, 0, 0, time.UTC)
+	book := Book{
+		Title: "Reading <list>",
+		Chapters: []Chapter{
+			{
+				Title:       "Caching & you",
+				Source:      "A Blog",
+				URL:         "https://a.example/cache?a=1&b=2",
+				PublishedAt: &published,
+				HTML:        `<p>Intro</p><img src="/fig.png"><img src="/fig.png"><img src="/gone.png">`,
+			},
+			{Title: "Plain", URL: "https://b.example/plain", Text: "One.\n\nTwo."},
+		},
+	}
+	var fetches int
+	fetch := func(_ context.Context, src string) ([]byte, string, error) {
+		fetches++
+		if strings.HasSuffix(src, "gone.png") {
+			return nil, "", errors.New("404")
+		}
+		return []byte("PNG"), "image/png", nil
+	}
+
+	var buf bytes.Buffer
+	if err := Write(context.Background(), &buf, book, fetch, time.Now()); err != nil {
+		t.Fatalf("Write() error: %v", err)
+	}
+	if fetches != 2 {
+		t.Errorf("fetched %d images, want 2 (each URL once)", fetches)
+	}
+
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("reading zip: %v", err)
+	}
+	if f := zr.File[0]; f.Name != "mimetype" || f.Method != zip.Store {
+		t.Errorf("first file = %s (method %d), want mimetype stored uncompressed", f.Name, f.Method)
+	}
+	files := make(map[string]string)
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatalf("opening %s: %v", f.Name, err)
+		}
+		b, _ := io.ReadAll(rc)
+		rc.Close()
+		files[f.Name] = string(b)
+	}
+	if files["mimetype"] != "application/epub+zip" {
+		t.Errorf("mimetype = %q", files["mimetype"])
+	}
+
+	for name, content := range files {
+		if !strings.HasSuffix(name, ".xml") && !strings.HasSuffix(name, ".opf") &&
+			!strings.HasSuffix(name, ".ncx") && !strings.HasSuffix(name, ".xhtml") {
+			continue
+		}
+		d := xml.NewDecoder(strings.NewReader(content))
+		for {
+			_, err := d.Token()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				t.Errorf("%s is not well-formed XML: %v\n%s", name, err, content)
+				break
+			}
+		}
+	}
+
+	for name, want := range map[string]string{
+		"OEBPS/content.opf":      `<item id="image-1" href="images/1.png" media-type="image/png"/>`,
+		"OEBPS/nav.xhtml":        `<li><a href="chapter-1.xhtml">Caching &amp; you</a> <span class="source">A Blog</span></li>`,
+		"OEBPS/toc.ncx":          `<navPoint id="nav-chapter-2" playOrder="2">`,
+		"OEBPS/chapter-1.xhtml":  `<p class="byline">A Blog · October 12, 2026</p>`,
+		"OEBPS/chapter-2.xhtml":  "<p>One.</p>\n<p>Two.</p>",
+		"OEBPS/images/1.png":     "PNG",
+		"META-INF/container.xml": `full-path="OEBPS/content.opf"`,
+	} {
+		if !strings.Contains(files[name], want) {
+			t.Errorf("%s is missing %q:\n%s", name, want, files[name])
+		}
+	}
+	if n := strings.Count(files["OEBPS/chapter-1.xhtml"], `<img src="images/1.png"`); n != 2 {
+		t.Errorf("chapter 1 has %d copies of the image, want 2:\n%s", n, files["OEBPS/chapter-1.xhtml"])
+	}
+}
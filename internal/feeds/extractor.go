@@ -3,12 +3,15 @@ package feeds
 import (
 	"context"
 	"fmt"
+	"io"
+	"mime"
 	"net/http"
 	"net/url"
 	"strings"
 	"time"
 
 	readability "github.com/go-shiori/go-readability"
+	"github.com/hoanghai1803/apricot/internal/outbound"
 )
 
 // ArticleMetadata holds full metadata extracted from a web page.
@@ -23,8 +26,8 @@ type ArticleMetadata struct {
 // extractFullText fetches the web page using the given HTTP client and returns
 // its main readable text content using go-readability's FromReader. Using the
 // shared HTTP client ensures consistent User-Agent headers and TLS settings.
-func extractFullText(client *http.Client, rawURL string) (string, error) {
-	article, err := fetchAndParse(client, rawURL)
+func extractFullText(ctx context.Context, client *http.Client, rawURL string) (string, error) {
+	article, err := fetchAndParse(ctx, client, rawURL)
 	if err != nil {
 		return "", err
 	}
@@ -38,7 +41,7 @@ func (f *Fetcher) ExtractArticleMetadata(ctx context.Context, rawURL string) (*A
 	domain := extractDomain(rawURL)
 	f.waitForRateLimit(domain)
 
-	article, err := fetchAndParse(f.client, rawURL)
+	article, err := fetchAndParse(ctx, f.client, rawURL)
 	if err != nil {
 		return nil, err
 	}
@@ -55,11 +58,62 @@ func (f *Fetcher) ExtractArticleMetadata(ctx context.Context, rawURL string) (*A
 	return meta, nil
 }
 
+// ExtractArticleHTML fetches the web page at articleURL and returns its main
+// content as an HTML fragment, as go-readability extracted it, with links
+// and image sources made absolute.
+func (f *Fetcher) ExtractArticleHTML(ctx context.Context, articleURL string) (string, error) {
+	f.waitForRateLimit(extractDomain(articleURL))
+
+	article, err := fetchAndParse(ctx, f.client, articleURL)
+	if err != nil {
+		return "", fmt.Errorf("extracting article from %q: %w", articleURL, err)
+	}
+	return article.Content, nil
+}
+
+// maxImageBytes limits the size of an image FetchImage reads.
+const maxImageBytes = 5 << 20
+
+// FetchImage fetches the image at imageURL and returns its content and
+// media type. Like a browser loading a page's images, it skips the
+// per-domain rate limit.
+func (f *Fetcher) FetchImage(ctx context.Context, imageURL string) ([]byte, string, error) {
+	req, err := http.NewRequestWithContext(outbound.WithPurpose(ctx, outbound.PurposeExtract), http.MethodGet, imageURL, nil)
+	if err != nil {
+		return nil, "", fmt.Errorf("invalid image URL %q: %w", imageURL, err)
+	}
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return nil, "", fmt.Errorf("fetching image: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, "", fmt.Errorf("fetching image: HTTP %d for %s", resp.StatusCode, imageURL)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxImageBytes+1))
+	if err != nil {
+		return nil, "", fmt.Errorf("reading image: %w", err)
+	}
+	if len(data) > maxImageBytes {
+		return nil, "", fmt.Errorf("image %s is larger than %d bytes", imageURL, maxImageBytes)
+	}
+	mediaType, _, err := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if err != nil || !strings.HasPrefix(mediaType, "image/") {
+		mediaType, _, _ = mime.ParseMediaType(http.DetectContentType(data))
+	}
+	return data, mediaType, nil
+}
+
 // fetchAndParse fetches a page using the given HTTP client and parses it with
 // go-readability's FromReader. This avoids readability's internal HTTP client
 // which has shorter timeouts and a bot-like User-Agent.
-func fetchAndParse(client *http.Client, rawURL string) (readability.Article, error) {
-	resp, err := client.Get(rawURL)
+func fetchAndParse(ctx context.Context, client *http.Client, rawURL string) (readability.Article, error) {
+	req, err := http.NewRequestWithContext(outbound.WithPurpose(ctx, outbound.PurposeExtract), http.MethodGet, rawURL, nil)
+	if err != nil {
+		return readability.Article{}, fmt.Errorf("readability extraction: invalid URL %q: %w", rawURL, err)
+	}
+	resp, err := client.Do(req)
 	if err != nil {
 		return readability.Article{}, fmt.Errorf("readability extraction: failed to fetch the page: %w", err)
 	}
@@ -12,6 +12,8 @@ import (
 	"time"
 
 	"github.com/hoanghai1803/apricot/internal/models"
+	"github.com/hoanghai1803/apricot/internal/netguard"
+	"github.com/hoanghai1803/apricot/internal/outbound"
 	"github.com/mmcdole/gofeed"
 	"golang.org/x/sync/errgroup"
 )
@@ -63,12 +65,14 @@ type Fetcher struct {
 
 // NewFetcher creates a Fetcher with a custom HTTP client configured with a
 // 30-second timeout, increased TLS handshake timeout, and browser-like
-// User-Agent.
-func NewFetcher() *Fetcher {
+// User-Agent. Connections go through guard, which refuses local and private
+// addresses; a nil guard allows any address.
+func NewFetcher(guard *netguard.Guard) *Fetcher {
 	transport := &http.Transport{
 		DialContext: (&net.Dialer{
 			Timeout:   30 * time.Second,
 			KeepAlive: 30 * time.Second,
+			Control:   guard.Control,
 		}).DialContext,
 		ForceAttemptHTTP2:     true,
 		MaxIdleConns:          100,
@@ -84,7 +88,7 @@ func NewFetcher() *Fetcher {
 		client: &http.Client{
 			Timeout: httpTimeout,
 			Transport: &userAgentTransport{
-				base: transport,
+				base: &outbound.Transport{Base: transport, Purpose: outbound.PurposeFeed},
 			},
 		},
 		rateLimiter: make(map[string]time.Time),
@@ -123,7 +127,7 @@ func (f *Fetcher) FetchAll(ctx context.Context, sources []models.BlogSource, opt
 		g.Go(func() error {
 			blogs, err := f.fetchSingleFeed(ctx, src, opts)
 			if err != nil {
-				slog.Warn("failed to fetch feed",
+				slog.WarnContext(ctx, "failed to fetch feed",
 					"source", src.Name,
 					"url", src.FeedURL,
 					"error", err,
@@ -143,7 +147,7 @@ func (f *Fetcher) FetchAll(ctx context.Context, sources []models.BlogSource, opt
 			result.Blogs = append(result.Blogs, blogs...)
 			mu.Unlock()
 
-			slog.Info("fetched feed",
+			slog.InfoContext(ctx, "fetched feed",
 				"source", src.Name,
 				"items", len(blogs),
 			)
@@ -174,19 +178,22 @@ func (f *Fetcher) fetchSingleFeed(ctx context.Context, source models.BlogSource,
 		fp := gofeed.NewParser()
 		fp.Client = f.client
 
-		feed, err := fp.ParseURLWithContext(source.FeedURL, ctx)
+		feed, err := fp.ParseURLWithContext(source.FeedURL, outbound.WithPurpose(ctx, outbound.PurposeFeed))
 		if err != nil {
 			lastErr = err
-			if attempt < maxRetries-1 {
+			if attempt < maxRetries-1 && ctx.Err() == nil {
 				delay := retryBaseDelay * time.Duration(1<<attempt)
-				slog.Debug("retrying feed fetch",
+				slog.DebugContext(ctx, "retrying feed fetch",
 					"source", source.Name,
 					"attempt", attempt+1,
 					"delay", delay,
 					"error", err,
 				)
-				time.Sleep(delay)
-				continue
+				select {
+				case <-time.After(delay):
+					continue
+				case <-ctx.Done():
+				}
 			}
 			break
 		}
@@ -205,7 +212,7 @@ func (f *Fetcher) ExtractArticle(ctx context.Context, articleURL string) (string
 	domain := extractDomain(articleURL)
 	f.waitForRateLimit(domain)
 
-	text, err := extractFullText(f.client, articleURL)
+	text, err := extractFullText(ctx, f.client, articleURL)
 	if err != nil {
 		return "", fmt.Errorf("extracting article from %q: %w", articleURL, err)
 	}
@@ -0,0 +1,128 @@
+package feeds
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/hoanghai1803/apricot/internal/outbound"
+	"github.com/mmcdole/gofeed"
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// maxHomePageBytes limits how much of a site's home page FindFeed reads
+// looking for feed links.
+const maxHomePageBytes = 2 << 20
+
+// ErrNoFeed is returned by FindFeed for a site with no feed it could find.
+var ErrNoFeed = errors.New("no feed found")
+
+// commonFeedPaths are tried, after the feeds a site's home page links to,
+// by FindFeed.
+var commonFeedPaths = []string{"/feed", "/feed.xml", "/rss.xml", "/atom.xml", "/index.xml", "/rss"}
+
+// feedTypes are the media types of the feed links FindFeed follows.
+var feedTypes = []string{"application/rss+xml", "application/atom+xml", "application/feed+json"}
+
+// FoundFeed is a feed FindFeed found for a site.
+type FoundFeed struct {
+	URL   string
+	Title string
+}
+
+// FindFeed looks for the RSS or Atom feed of the site at siteURL: first the
+// feeds its home page links to, then a few common locations. It returns
+// the first that parses as a feed, or ErrNoFeed.
+func (f *Fetcher) FindFeed(ctx context.Context, siteURL string) (*FoundFeed, error) {
+	site, err := url.Parse(siteURL)
+	if err != nil || (site.Scheme != "http" && site.Scheme != "https") {
+		return nil, fmt.Errorf("invalid site URL %q", siteURL)
+	}
+
+	// A home page that fails to load may still have a feed at a common
+	// path.
+	candidates, _ := f.feedLinks(ctx, site.String())
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+	for _, p := range commonFeedPaths {
+		candidates = append(candidates, site.ResolveReference(&url.URL{Path: p}).String())
+	}
+
+	var tried []string
+	for _, c := range candidates {
+		if slices.Contains(tried, c) {
+			continue
+		}
+		tried = append(tried, c)
+
+		f.waitForRateLimit(extractDomain(c))
+		fp := gofeed.NewParser()
+		fp.Client = f.client
+		feed, err := fp.ParseURLWithContext(c, outbound.WithPurpose(ctx, outbound.PurposeFeed))
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			continue
+		}
+		return &FoundFeed{URL: c, Title: strings.TrimSpace(feed.Title)}, nil
+	}
+	return nil, ErrNoFeed
+}
+
+// feedLinks returns the absolute URLs of the feeds the page at pageURL
+// links to with <link rel="alternate">, in page order.
+func (f *Fetcher) feedLinks(ctx context.Context, pageURL string) ([]string, error) {
+	f.waitForRateLimit(extractDomain(pageURL))
+
+	req, err := http.NewRequestWithContext(outbound.WithPurpose(ctx, outbound.PurposeFeed), http.MethodGet, pageURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("fetching %s: %w", pageURL, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetching %s: HTTP %d", pageURL, resp.StatusCode)
+	}
+
+	doc, err := html.Parse(io.LimitReader(resp.Body, maxHomePageBytes))
+	if err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", pageURL, err)
+	}
+
+	var links []string
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.ElementNode && n.DataAtom == atom.Link && isFeedLink(n) {
+			if u, err := resp.Request.URL.Parse(strings.TrimSpace(getAttr(n, "href"))); err == nil &&
+				(u.Scheme == "http" || u.Scheme == "https") {
+				links = append(links, u.String())
+			}
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(doc)
+	return links, nil
+}
+
+// isFeedLink reports whether the <link> element n points to a feed.
+func isFeedLink(n *html.Node) bool {
+	if !slices.Contains(strings.Fields(strings.ToLower(getAttr(n, "rel"))), "alternate") || getAttr(n, "href") == "" {
+		return false
+	}
+	mediaType, _, err := mime.ParseMediaType(getAttr(n, "type"))
+	return err == nil && slices.Contains(feedTypes, mediaType)
+}
@@ -0,0 +1,63 @@
+package feeds
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+const finderAtom = `<?xml version="1.0" encoding="utf-8"?>
+<feed xmlns="http://www.w3.org/2005/Atom"><title>Example Engineering</title></feed>`
+
+func TestFindFeed(t *testing.T) {
+	tests := []struct {
+		name  string
+		home  string
+		feeds map[string]string
+		want  string
+	}{
+		{
+			name:  "linked from the home page",
+			home:  `<html><head><link rel="alternate" type="application/atom+xml; charset=utf-8" href="/blog/atom"></head></html>`,
+			feeds: map[string]string{"/blog/atom": finderAtom},
+			want:  "/blog/atom",
+		},
+		{
+			name:  "at a common path",
+			home:  `<html><head><link rel="stylesheet" type="text/css" href="/style.css"></head></html>`,
+			feeds: map[string]string{"/feed": finderAtom},
+			want:  "/feed",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path == "/" {
+					fmt.Fprint(w, tt.home)
+					return
+				}
+				if body, ok := tt.feeds[r.URL.Path]; ok {
+					w.Header().Set("Content-Type", "application/atom+xml")
+					fmt.Fprint(w, body)
+					return
+				}
+				http.NotFound(w, r)
+			}))
+			defer srv.Close()
+
+			got, err := NewFetcher(nil).FindFeed(context.Background(), srv.URL+"/")
+			if err != nil {
+				t.Fatalf("FindFeed() error: %v", err)
+			}
+			if got.URL != srv.URL+tt.want || got.Title != "Example Engineering" {
+				t.Errorf("FindFeed() = %+v, want %s titled Example Engineering", got, srv.URL+tt.want)
+			}
+		})
+	}
+
+	if _, err := NewFetcher(nil).FindFeed(context.Background(), "ftp://example.com/"); err == nil {
+		t.Error("FindFeed(ftp URL) error = nil, want an error")
+	}
+}
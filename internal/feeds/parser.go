@@ -57,6 +57,8 @@ func parseFeedItemsRecent(source models.BlogSource, feed *gofeed.Feed, maxArticl
 			PublishedAt: publishedAt,
 			FetchedAt:   now,
 			ContentHash: computeHash(item.Link),
+
+			ReadingTimeMinutes: feedReadingTime(item),
 		})
 	}
 
@@ -95,12 +97,34 @@ func parseFeedItemsTimeRange(source models.BlogSource, feed *gofeed.Feed, lookba
 			PublishedAt: publishedAt,
 			FetchedAt:   now,
 			ContentHash: computeHash(item.Link),
+
+			ReadingTimeMinutes: feedReadingTime(item),
 		})
 	}
 
 	return blogs
 }
 
+// feedReadingTime estimates the reading time of a feed item from the
+// content the feed carries, or returns nil if it carries none. Feeds that
+// publish only an excerpt give an underestimate.
+func feedReadingTime(item *gofeed.Item) *int {
+	if item.Content == "" {
+		return nil
+	}
+	minutes := CalculateReadingTime(stripHTML(item.Content))
+	if minutes <= 0 {
+		return nil
+	}
+	return &minutes
+}
+
+// HashContent returns the content hash of an article's extracted text,
+// which storage uses to detect when a post changes after publication.
+func HashContent(content string) string {
+	return computeHash(content)
+}
+
 // computeHash returns the SHA-256 hex digest of the given string.
 func computeHash(s string) string {
 	h := sha256.Sum256([]byte(s))
@@ -1,6 +1,7 @@
 package feeds
 
 import (
+	"strings"
 	"testing"
 	"time"
 
@@ -153,6 +154,31 @@ func TestParseFeedItems_FieldMapping(t *testing.T) {
 	}
 }
 
+func TestParseFeedItems_ReadingTime(t *testing.T) {
+	feed := &gofeed.Feed{
+		Items: []*gofeed.Item{
+			{
+				Title:   "Long Read",
+				Link:    "https://example.com/long",
+				Content: "<p>" + strings.Repeat("word ", 2000) + "</p>",
+			},
+			{Title: "Excerpt Only", Link: "https://example.com/short"},
+		},
+	}
+
+	blogs := parseFeedItems(models.BlogSource{ID: 1}, feed, FetchOptions{Mode: "recent_posts", MaxArticles: 10})
+	if len(blogs) != 2 {
+		t.Fatalf("expected 2 blogs, got %d", len(blogs))
+	}
+	want := CalculateReadingTime(strings.Repeat("word ", 2000))
+	if got := blogs[0].ReadingTimeMinutes; got == nil || *got != want {
+		t.Errorf("ReadingTimeMinutes = %v, want %d from the feed content", got, want)
+	}
+	if blogs[1].ReadingTimeMinutes != nil {
+		t.Errorf("ReadingTimeMinutes = %d, want nil without feed content", *blogs[1].ReadingTimeMinutes)
+	}
+}
+
 func TestParseFeedItems_TimeRangeMode(t *testing.T) {
 	now := time.Now()
 	recent := now.Add(-2 * 24 * time.Hour) // 2 days ago
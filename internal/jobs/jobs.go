@@ -0,0 +1,282 @@
+// Package jobs runs long operations in the background from a queue kept in
+// the database, so that a request can start one, return at once, and let the
+// client poll for the result instead of holding a connection open for
+// minutes. Queued jobs survive a restart, failed attempts are retried with
+// backoff, and finished jobs can be looked up afterwards.
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/hoanghai1803/apricot/internal/logctx"
+	"github.com/hoanghai1803/apricot/internal/models"
+	"github.com/hoanghai1803/apricot/internal/storage"
+)
+
+// Handler does the work of a job, given its payload. Its result is stored
+// as JSON. A Handler should give up when ctx is done.
+type Handler func(ctx context.Context, payload json.RawMessage) (any, error)
+
+// Kind describes how to run the jobs of one kind.
+type Kind struct {
+	Name    string
+	Handler Handler
+
+	// Timeout bounds each attempt; 0 means no limit.
+	Timeout time.Duration
+
+	// MaxAttempts is how many times a failing job is tried (at least once).
+	// Retries wait Backoff (default 30 seconds), doubling after each.
+	MaxAttempts int
+	Backoff     time.Duration
+}
+
+// defaultBackoff is the wait before the first retry of a Kind without a
+// Backoff.
+const defaultBackoff = 30 * time.Second
+
+// keepFinished is how long finished jobs are kept before being pruned.
+const keepFinished = 7 * 24 * time.Hour
+
+// pollInterval is how often idle workers check the queue for jobs that
+// became due, such as retries, without being woken by Enqueue.
+const pollInterval = 5 * time.Second
+
+// ErrUnknownKind is returned by Enqueue for a kind that was not registered.
+var ErrUnknownKind = errors.New("unknown job kind")
+
+// permanentError marks an error that retrying won't fix.
+type permanentError struct{ err error }
+
+func (e *permanentError) Error() string { return e.err.Error() }
+func (e *permanentError) Unwrap() error { return e.err }
+
+// Permanent wraps err so that the job fails at once instead of being
+// retried, for failures such as missing configuration.
+func Permanent(err error) error {
+	return &permanentError{err}
+}
+
+// Manager queues jobs in a store and runs them on a pool of workers. It is
+// safe for concurrent use.
+type Manager struct {
+	store   storage.JobStore
+	workers int
+	wake    chan struct{}
+	now     func() time.Time
+
+	mu    sync.RWMutex
+	kinds map[string]Kind
+}
+
+// NewManager returns a Manager that keeps its queue in store and runs up to
+// workers jobs at a time (at least one) once Run is called.
+func NewManager(store storage.JobStore, workers int) *Manager {
+	return &Manager{
+		store:   store,
+		workers: max(workers, 1),
+		wake:    make(chan struct{}, 1),
+		now:     time.Now,
+		kinds:   make(map[string]Kind),
+	}
+}
+
+// Register sets how jobs of kind.Name are run. It must be called before
+// jobs of that kind are enqueued or, after a restart, run.
+func (m *Manager) Register(kind Kind) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.kinds[kind.Name] = kind
+}
+
+// kind returns the registered Kind with the given name.
+func (m *Manager) kind(name string) (Kind, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	k, ok := m.kinds[name]
+	return k, ok
+}
+
+// Enqueue queues a job of the given kind with payload, which is stored as
+// JSON, and returns it.
+func (m *Manager) Enqueue(ctx context.Context, kind string, payload any) (*models.Job, error) {
+	k, ok := m.kind(kind)
+	if !ok {
+		return nil, fmt.Errorf("%w %q", ErrUnknownKind, kind)
+	}
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("encoding %s job payload: %w", kind, err)
+	}
+	job, err := m.store.CreateJob(ctx, kind, data, k.MaxAttempts)
+	if err != nil {
+		return nil, err
+	}
+	// Logged with the caller's context, this ties the job to the request
+	// that queued it.
+	slog.InfoContext(ctx, "queued job", "job", job.ID, "kind", kind)
+
+	select {
+	case m.wake <- struct{}{}:
+	default:
+	}
+	return job, nil
+}
+
+// Get returns the job with the given ID, or an error wrapping
+// storage.ErrNotFound.
+func (m *Manager) Get(ctx context.Context, id int64) (*models.Job, error) {
+	return m.store.GetJob(ctx, id)
+}
+
+// List returns the jobs matching filter, newest first.
+func (m *Manager) List(ctx context.Context, filter storage.JobFilter) ([]models.Job, error) {
+	return m.store.ListJobs(ctx, filter)
+}
+
+// Run requeues jobs a previous process left running, then runs queued jobs
+// on the worker pool until ctx is done. Finished jobs older than a week are
+// pruned every hour. Cancelling ctx cancels running jobs and Run returns
+// once they have stopped; they are queued again by the next Run.
+func (m *Manager) Run(ctx context.Context) {
+	if n, err := m.store.RequeueInterruptedJobs(ctx); err != nil {
+		slog.WarnContext(ctx, "failed to requeue interrupted jobs", "error", err)
+	} else if n > 0 {
+		slog.InfoContext(ctx, "requeued interrupted jobs", "jobs", n)
+	}
+
+	var wg sync.WaitGroup
+	for range m.workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			m.work(ctx)
+		}()
+	}
+
+	ticker := time.NewTicker(time.Hour)
+	defer ticker.Stop()
+	for {
+		if n, err := m.store.PruneJobs(ctx, m.now().Add(-keepFinished)); err != nil {
+			slog.WarnContext(ctx, "failed to prune finished jobs", "error", err)
+		} else if n > 0 {
+			slog.InfoContext(ctx, "pruned finished jobs", "jobs", n)
+		}
+
+		select {
+		case <-ctx.Done():
+			wg.Wait()
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// work runs due jobs until ctx is done, waiting for Enqueue or the poll
+// interval when there are none.
+func (m *Manager) work(ctx context.Context) {
+	timer := time.NewTimer(pollInterval)
+	defer timer.Stop()
+	for {
+		ran, err := m.RunNext(ctx)
+		if err != nil && ctx.Err() == nil {
+			slog.WarnContext(ctx, "failed to run job", "error", err)
+		}
+		if ran {
+			continue
+		}
+
+		timer.Reset(pollInterval)
+		select {
+		case <-ctx.Done():
+			return
+		case <-m.wake:
+		case <-timer.C:
+		}
+	}
+}
+
+// RunNext claims the oldest due job and runs it, reporting whether there
+// was one. The returned error is about the queue, not the job; a job's
+// failure is recorded on the job.
+func (m *Manager) RunNext(ctx context.Context) (bool, error) {
+	job, err := m.store.ClaimJob(ctx, m.now())
+	if errors.Is(err, storage.ErrNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	// Everything the job logs carries its ID.
+	ctx = logctx.With(ctx, "job", job.ID)
+
+	k, ok := m.kind(job.Kind)
+	if !ok {
+		return true, m.store.FailJob(ctx, job.ID, fmt.Sprintf("%s %q", ErrUnknownKind, job.Kind), nil)
+	}
+
+	result, err := m.call(ctx, k, job)
+	// Record the outcome even if ctx was cancelled as the job finished.
+	recordCtx := context.WithoutCancel(ctx)
+	if err == nil {
+		var data []byte
+		if data, err = json.Marshal(result); err == nil {
+			return true, m.store.FinishJob(recordCtx, job.ID, data)
+		}
+		err = Permanent(fmt.Errorf("encoding result: %w", err))
+	}
+	if ctx.Err() != nil {
+		// The server is shutting down. Leave the job running, so that the
+		// next Run queues it again if it has attempts left.
+		slog.InfoContext(ctx, "job interrupted by shutdown", "kind", job.Kind)
+		return true, nil
+	}
+
+	var retryAt *time.Time
+	var permanent *permanentError
+	if job.Attempts < job.MaxAttempts && !errors.As(err, &permanent) {
+		backoff := k.Backoff
+		if backoff <= 0 {
+			backoff = defaultBackoff
+		}
+		at := m.now().Add(backoff << (job.Attempts - 1))
+		retryAt = &at
+	}
+	slog.WarnContext(ctx, "job failed", "kind", job.Kind, "attempt", job.Attempts,
+		"retrying", retryAt != nil, "error", err)
+
+	return true, m.store.FailJob(recordCtx, job.ID, err.Error(), retryAt)
+}
+
+// Drain runs due jobs one at a time until none are left.
+func (m *Manager) Drain(ctx context.Context) error {
+	for {
+		ran, err := m.RunNext(ctx)
+		if err != nil || !ran {
+			return err
+		}
+	}
+}
+
+// call runs one attempt at job, within the kind's timeout, turning a panic
+// into an error so that one broken job doesn't take the server down.
+func (m *Manager) call(ctx context.Context, k Kind, job *models.Job) (result any, err error) {
+	if k.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, k.Timeout)
+		defer cancel()
+	}
+	defer func() {
+		if p := recover(); p != nil {
+			err = fmt.Errorf("job panicked: %v", p)
+		}
+	}()
+	return k.Handler(ctx, job.Payload)
+}
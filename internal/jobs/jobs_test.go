@@ -0,0 +1,224 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/hoanghai1803/apricot/internal/models"
+	"github.com/hoanghai1803/apricot/internal/storage"
+)
+
+func newTestManager(t *testing.T) *Manager {
+	t.Helper()
+
+	db, err := storage.OpenDatabase(":memory:")
+	if err != nil {
+		t.Fatalf("opening test db: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	if err := storage.RunMigrations(db); err != nil {
+		t.Fatalf("running migrations: %v", err)
+	}
+	return NewManager(storage.NewSQLiteStore(db), 1)
+}
+
+func TestEnqueue(t *testing.T) {
+	m := newTestManager(t)
+	ctx := context.Background()
+
+	m.Register(Kind{Name: "echo", Handler: func(ctx context.Context, payload json.RawMessage) (any, error) {
+		var p struct{ N int }
+		if err := json.Unmarshal(payload, &p); err != nil {
+			return nil, err
+		}
+		return map[string]int{"answer": p.N * 2}, nil
+	}})
+
+	job, err := m.Enqueue(ctx, "echo", map[string]int{"n": 21})
+	if err != nil {
+		t.Fatalf("Enqueue() error: %v", err)
+	}
+	if job.ID == 0 || job.Kind != "echo" || job.Status != models.JobQueued {
+		t.Errorf("Enqueue() = %+v, want a queued echo job", job)
+	}
+	if _, err := m.Enqueue(ctx, "nope", nil); !errors.Is(err, ErrUnknownKind) {
+		t.Errorf("Enqueue() of an unregistered kind error = %v, want ErrUnknownKind", err)
+	}
+
+	if err := m.Drain(ctx); err != nil {
+		t.Fatalf("Drain() error: %v", err)
+	}
+	got, err := m.Get(ctx, job.ID)
+	if err != nil {
+		t.Fatalf("Get() error: %v", err)
+	}
+	if got.Status != models.JobSucceeded || string(got.Result) != `{"answer":42}` {
+		t.Errorf("job = %+v, want succeeded with the result", got)
+	}
+	if got.StartedAt == nil || got.FinishedAt == nil {
+		t.Errorf("job = %+v, want start and finish times", got)
+	}
+}
+
+func TestRunNext_Retries(t *testing.T) {
+	m := newTestManager(t)
+	ctx := context.Background()
+	now := time.Now()
+	m.now = func() time.Time { return now }
+
+	calls := 0
+	m.Register(Kind{Name: "flaky", MaxAttempts: 3, Backoff: time.Minute,
+		Handler: func(ctx context.Context, payload json.RawMessage) (any, error) {
+			calls++
+			if calls < 3 {
+				return nil, errors.New("feed timeout")
+			}
+			return "ok", nil
+		}})
+
+	job, err := m.Enqueue(ctx, "flaky", nil)
+	if err != nil {
+		t.Fatalf("Enqueue() error: %v", err)
+	}
+
+	if err := m.Drain(ctx); err != nil {
+		t.Fatalf("Drain() error: %v", err)
+	}
+	got, _ := m.Get(ctx, job.ID)
+	if got.Status != models.JobQueued || got.Attempts != 1 || got.Error != "feed timeout" {
+		t.Fatalf("job after a failed attempt = %+v, want it queued for a retry", got)
+	}
+
+	// The first retry waits a minute, the second two.
+	now = now.Add(time.Minute + time.Second)
+	m.Drain(ctx) //nolint:errcheck // checked through the job
+	if got, _ = m.Get(ctx, job.ID); got.Attempts != 2 || got.Status != models.JobQueued {
+		t.Fatalf("job after a second failure = %+v, want it queued again", got)
+	}
+	now = now.Add(time.Minute + time.Second)
+	m.Drain(ctx) //nolint:errcheck // checked through the job
+	if got, _ = m.Get(ctx, job.ID); got.Attempts != 2 {
+		t.Fatalf("job retried after %d attempts before its backoff", got.Attempts)
+	}
+	now = now.Add(time.Minute)
+	m.Drain(ctx) //nolint:errcheck // checked through the job
+	if got, _ = m.Get(ctx, job.ID); got.Status != models.JobSucceeded || got.Attempts != 3 {
+		t.Errorf("job = %+v, want it succeeded on the third attempt", got)
+	}
+}
+
+func TestRunNext_Failure(t *testing.T) {
+	m := newTestManager(t)
+	ctx := context.Background()
+
+	m.Register(Kind{Name: "fail", MaxAttempts: 3, Handler: func(ctx context.Context, payload json.RawMessage) (any, error) {
+		return nil, Permanent(errors.New("no feeds"))
+	}})
+	m.Register(Kind{Name: "panic", Handler: func(ctx context.Context, payload json.RawMessage) (any, error) {
+		panic("boom")
+	}})
+	m.Register(Kind{Name: "slow", Timeout: 10 * time.Millisecond, Handler: func(ctx context.Context, payload json.RawMessage) (any, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}})
+
+	var ids []int64
+	for _, kind := range []string{"fail", "panic", "slow"} {
+		job, err := m.Enqueue(ctx, kind, nil)
+		if err != nil {
+			t.Fatalf("Enqueue(%q) error: %v", kind, err)
+		}
+		ids = append(ids, job.ID)
+	}
+	if err := m.Drain(ctx); err != nil {
+		t.Fatalf("Drain() error: %v", err)
+	}
+
+	for i, wantError := range []string{"no feeds", "job panicked: boom", context.DeadlineExceeded.Error()} {
+		got, err := m.Get(ctx, ids[i])
+		if err != nil {
+			t.Fatalf("Get(%d) error: %v", ids[i], err)
+		}
+		if got.Status != models.JobFailed || got.Error != wantError || got.Attempts != 1 {
+			t.Errorf("job %d = %+v, want failed once with %q", ids[i], got, wantError)
+		}
+	}
+}
+
+func TestRun(t *testing.T) {
+	m := newTestManager(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	done := make(chan struct{})
+	m.Register(Kind{Name: "signal", Handler: func(ctx context.Context, payload json.RawMessage) (any, error) {
+		close(done)
+		return nil, nil
+	}})
+
+	stopped := make(chan struct{})
+	go func() {
+		m.Run(ctx)
+		close(stopped)
+	}()
+	if _, err := m.Enqueue(ctx, "signal", nil); err != nil {
+		t.Fatalf("Enqueue() error: %v", err)
+	}
+
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("enqueued job did not run")
+	}
+	cancel()
+	select {
+	case <-stopped:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Run() did not return after ctx was cancelled")
+	}
+}
+
+func TestRun_Shutdown(t *testing.T) {
+	m := newTestManager(t)
+	ctx, cancel := context.WithCancel(context.Background())
+
+	started := make(chan struct{})
+	m.Register(Kind{Name: "block", MaxAttempts: 2, Handler: func(ctx context.Context, payload json.RawMessage) (any, error) {
+		close(started)
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}})
+	job, err := m.Enqueue(ctx, "block", nil)
+	if err != nil {
+		t.Fatalf("Enqueue() error: %v", err)
+	}
+
+	stopped := make(chan struct{})
+	go func() {
+		m.Run(ctx)
+		close(stopped)
+	}()
+	select {
+	case <-started:
+	case <-time.After(2 * time.Second):
+		t.Fatal("enqueued job did not run")
+	}
+	cancel()
+	select {
+	case <-stopped:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Run() did not return after ctx was cancelled")
+	}
+
+	// The interrupted job is left for the next Run, not failed.
+	got, err := m.Get(context.Background(), job.ID)
+	if err != nil {
+		t.Fatalf("Get() error: %v", err)
+	}
+	if got.Status != models.JobRunning || got.Error != "" {
+		t.Errorf("interrupted job = %+v, want it left running", got)
+	}
+}
@@ -0,0 +1,101 @@
+// Package kindle renders articles as a single self-contained HTML document,
+// a format Amazon's Send to Kindle service converts into a Kindle book. A
+// document of several articles opens with a table of contents and starts
+// each article on a new page.
+package kindle
+
+import (
+	"bytes"
+	"html/template"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Article is a post to include in a document.
+type Article struct {
+	Title       string
+	Source      string
+	URL         string
+	PublishedAt *time.Time
+	Text        string // the extracted article, paragraphs on separate lines
+}
+
+// unsafeName matches runs of characters left out of file names.
+var unsafeName = regexp.MustCompile(`[^\p{L}\p{N} ._-]+`)
+
+// maxName is the most runes of a title used in a file name.
+const maxName = 80
+
+// Render returns articles as an HTML document titled title, which Kindle
+// shows as the book's title.
+func Render(title string, articles []Article) []byte {
+	data := struct {
+		Title    string
+		Contents bool
+		Articles []renderedArticle
+	}{Title: title, Contents: len(articles) > 1}
+	for _, a := range articles {
+		r := renderedArticle{Article: a}
+		if a.PublishedAt != nil {
+			r.Date = a.PublishedAt.Format("January 2, 2006")
+		}
+		for _, line := range strings.Split(a.Text, "\n") {
+			if line = strings.TrimSpace(line); line != "" {
+				r.Paragraphs = append(r.Paragraphs, line)
+			}
+		}
+		data.Articles = append(data.Articles, r)
+	}
+
+	var b bytes.Buffer
+	// Executing only fails on a broken template or writer, neither of which
+	// can happen here.
+	document.Execute(&b, data) //nolint:errcheck
+	return b.Bytes()
+}
+
+// FileName returns the name to attach a document titled title under; Send
+// to Kindle uses it when the document has no title of its own.
+func FileName(title string) string {
+	name := strings.Join(strings.Fields(unsafeName.ReplaceAllString(title, " ")), " ")
+	if r := []rune(name); len(r) > maxName {
+		name = strings.TrimSpace(string(r[:maxName]))
+	}
+	if name == "" {
+		name = "Apricot"
+	}
+	return name + ".html"
+}
+
+// renderedArticle is an article as the template shows it.
+type renderedArticle struct {
+	Article
+	Date       string
+	Paragraphs []string
+}
+
+var document = template.Must(template.New("kindle").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<style>
+.article { page-break-before: always; }
+.byline { font-style: italic; }
+</style>
+</head>
+<body>
+{{if .Contents}}<h1>{{.Title}}</h1>
+<ol>
+{{range $i, $a := .Articles}}<li><a href="#article-{{$i}}">{{$a.Title}}</a>{{if $a.Source}} ({{$a.Source}}){{end}}</li>
+{{end}}</ol>
+{{end}}{{range $i, $a := .Articles}}<div{{if $.Contents}} class="article"{{end}} id="article-{{$i}}">
+<h1>{{$a.Title}}</h1>
+{{if or $a.Source $a.Date}}<p class="byline">{{$a.Source}}{{if and $a.Source $a.Date}} · {{end}}{{$a.Date}}</p>
+{{end}}{{range $a.Paragraphs}}<p>{{.}}</p>
+{{end}}<p><a href="{{$a.URL}}">{{$a.URL}}</a></p>
+</div>
+{{end}}</body>
+</html>
+`))
@@ -0,0 +1,59 @@
+package kindle
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRender(t *testing.T) {
+	published := time.Date(2026, 10, 12, 0, 0, 0, 0, time.UTC)
+	one := string(Render("Caching", []Article{{
+		Title:       "Caching <done right>",
+		Source:      "A Blog",
+		URL:         "https://a.example/cache",
+		PublishedAt: &published,
+		Text:        "First paragraph.\n\n  Second & last.  \n",
+	}}))
+	for _, want := range []string{
+		"<title>Caching</title>",
+		"<h1>Caching &lt;done right&gt;</h1>",
+		`<p class="byline">A Blog · October 12, 2026</p>`,
+		"<p>First paragraph.</p>\n<p>Second &amp; last.</p>\n",
+		`<a href="https://a.example/cache">`,
+	} {
+		if !strings.Contains(one, want) {
+			t.Errorf("document is missing %q:\n%s", want, one)
+		}
+	}
+	if strings.Contains(one, "<ol>") || strings.Contains(one, `class="article"`) {
+		t.Error("a single article has a table of contents or a page break")
+	}
+
+	many := string(Render("Week of October 12", []Article{
+		{Title: "One", URL: "https://a.example/1"},
+		{Title: "Two", Source: "B", URL: "https://b.example/2"},
+	}))
+	for _, want := range []string{
+		"<h1>Week of October 12</h1>",
+		`<li><a href="#article-0">One</a></li>`,
+		`<li><a href="#article-1">Two</a> (B)</li>`,
+		`<div class="article" id="article-1">`,
+	} {
+		if !strings.Contains(many, want) {
+			t.Errorf("compilation is missing %q:\n%s", want, many)
+		}
+	}
+}
+
+func TestFileName(t *testing.T) {
+	for title, want := range map[string]string{
+		"Why Postgres? (Part 2/3)": "Why Postgres Part 2 3.html",
+		"Café":                     "Café.html",
+		"???":                      "Apricot.html",
+	} {
+		if got := FileName(title); got != want {
+			t.Errorf("FileName(%q) = %q, want %q", title, got, want)
+		}
+	}
+}
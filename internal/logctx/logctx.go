@@ -0,0 +1,61 @@
+// Package logctx carries log attributes, such as a request or job ID, in a
+// context, and adds them to every record logged with that context. This
+// ties together the log lines of one request, or of a job it started, even
+// when they come from different packages.
+package logctx
+
+import (
+	"context"
+	"log/slog"
+)
+
+// attrsKey is the context key for the attributes.
+type attrsKey struct{}
+
+// With returns a copy of ctx that adds key=value to log records.
+func With(ctx context.Context, key string, value any) context.Context {
+	attrs, _ := ctx.Value(attrsKey{}).([]slog.Attr)
+	attrs = append(attrs[:len(attrs):len(attrs)], slog.Any(key, value))
+	return context.WithValue(ctx, attrsKey{}, attrs)
+}
+
+// Value returns the value of the attribute key in ctx, if any.
+func Value(ctx context.Context, key string) (any, bool) {
+	attrs, _ := ctx.Value(attrsKey{}).([]slog.Attr)
+	for i := len(attrs) - 1; i >= 0; i-- {
+		if attrs[i].Key == key {
+			return attrs[i].Value.Any(), true
+		}
+	}
+	return nil, false
+}
+
+// Handler is a slog.Handler that adds the attributes of the context passed
+// to the logger's *Context methods to each record.
+type Handler struct {
+	slog.Handler
+}
+
+// NewHandler wraps h so that records carry their context's attributes.
+func NewHandler(h slog.Handler) *Handler {
+	return &Handler{h}
+}
+
+// Handle adds ctx's attributes to r and passes it on.
+func (h *Handler) Handle(ctx context.Context, r slog.Record) error {
+	if attrs, _ := ctx.Value(attrsKey{}).([]slog.Attr); len(attrs) > 0 {
+		r = r.Clone()
+		r.AddAttrs(attrs...)
+	}
+	return h.Handler.Handle(ctx, r)
+}
+
+// WithAttrs returns a Handler whose records also carry attrs.
+func (h *Handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &Handler{h.Handler.WithAttrs(attrs)}
+}
+
+// WithGroup returns a Handler that puts later attributes in a group.
+func (h *Handler) WithGroup(name string) slog.Handler {
+	return &Handler{h.Handler.WithGroup(name)}
+}
@@ -0,0 +1,41 @@
+package logctx
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestHandler(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(NewHandler(slog.NewTextHandler(&buf, nil)))
+
+	ctx := With(context.Background(), "request_id", "abc123")
+	jobCtx := With(ctx, "job", 7)
+	logger.InfoContext(jobCtx, "fetching feeds", "sources", 3)
+	logger.InfoContext(ctx, "queued job")
+	logger.Info("no context")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("got %d lines, want 3:\n%s", len(lines), buf.String())
+	}
+	if !strings.Contains(lines[0], "sources=3 request_id=abc123 job=7") {
+		t.Errorf("line 1 = %q, want the request and job IDs", lines[0])
+	}
+	if !strings.Contains(lines[1], "request_id=abc123") || strings.Contains(lines[1], "job=") {
+		t.Errorf("line 2 = %q, want only the request ID", lines[1])
+	}
+	if strings.Contains(lines[2], "request_id") {
+		t.Errorf("line 3 = %q, want no request ID", lines[2])
+	}
+
+	if v, ok := Value(jobCtx, "request_id"); !ok || v != "abc123" {
+		t.Errorf("Value(request_id) = %v, %v; want abc123", v, ok)
+	}
+	if _, ok := Value(ctx, "job"); ok {
+		t.Error("Value(job) found an attribute added to a derived context")
+	}
+}
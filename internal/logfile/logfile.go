@@ -0,0 +1,117 @@
+// Package logfile writes logs to a file that is rotated by size: once it
+// would grow past a limit it is renamed to path.1 (older copies shift to
+// path.2 and so on) and a new file is started, keeping a fixed number of
+// old files.
+package logfile
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// Writer is an io.WriteCloser appending to a size-rotated file. It is safe
+// for concurrent use.
+type Writer struct {
+	path     string
+	maxBytes int64
+	keep     int
+
+	mu   sync.Mutex
+	file *os.File
+	size int64
+}
+
+// Open opens path for appending, creating it and its directory if needed.
+// The file is rotated before a write would take it past maxBytes, and keep
+// rotated files are kept.
+func Open(path string, maxBytes int64, keep int) (*Writer, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("creating log directory: %w", err)
+	}
+	w := &Writer{path: path, maxBytes: maxBytes, keep: keep}
+	if err := w.open(); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+// open opens the current file and records its size.
+func (w *Writer) open() error {
+	f, err := os.OpenFile(w.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("opening log file: %w", err)
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return fmt.Errorf("reading log file size: %w", err)
+	}
+	w.file, w.size = f, info.Size()
+	return nil
+}
+
+// Write appends p, rotating first if p would take the file past its limit.
+// A single write larger than the limit still goes to one file.
+func (w *Writer) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.file == nil {
+		return 0, fs.ErrClosed
+	}
+	if w.size > 0 && w.size+int64(len(p)) > w.maxBytes {
+		if err := w.rotate(); err != nil {
+			return 0, err
+		}
+	}
+	n, err := w.file.Write(p)
+	w.size += int64(n)
+	return n, err
+}
+
+// rotate shifts the old files along, deleting the oldest, moves the current
+// file to path.1, and starts a new one. w.mu must be held.
+func (w *Writer) rotate() error {
+	if err := w.file.Close(); err != nil {
+		return fmt.Errorf("closing log file: %w", err)
+	}
+	w.file = nil
+
+	if err := os.Remove(w.rotated(w.keep)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("removing oldest log file: %w", err)
+	}
+	for i := w.keep - 1; i >= 1; i-- {
+		if err := os.Rename(w.rotated(i), w.rotated(i+1)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return fmt.Errorf("rotating log file: %w", err)
+		}
+	}
+	if w.keep > 0 {
+		if err := os.Rename(w.path, w.rotated(1)); err != nil {
+			return fmt.Errorf("rotating log file: %w", err)
+		}
+	} else if err := os.Remove(w.path); err != nil {
+		return fmt.Errorf("removing log file: %w", err)
+	}
+	return w.open()
+}
+
+// rotated returns the name of the i-th rotated file.
+func (w *Writer) rotated(i int) string {
+	return fmt.Sprintf("%s.%d", w.path, i)
+}
+
+// Close closes the file.
+func (w *Writer) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.file == nil {
+		return nil
+	}
+	err := w.file.Close()
+	w.file = nil
+	return err
+}
@@ -0,0 +1,60 @@
+package logfile
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWriter_Rotates(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "logs", "access.log")
+	w, err := Open(path, 10, 2)
+	if err != nil {
+		t.Fatalf("Open() error: %v", err)
+	}
+	defer w.Close()
+
+	for _, line := range []string{"first\n", "second\n", "third\n", "fourth\n"} {
+		if _, err := w.Write([]byte(line)); err != nil {
+			t.Fatalf("Write(%q) error: %v", line, err)
+		}
+	}
+
+	for name, want := range map[string]string{
+		path:        "fourth\n",
+		path + ".1": "third\n",
+		path + ".2": "second\n",
+	} {
+		got, err := os.ReadFile(name)
+		if err != nil {
+			t.Errorf("reading %s: %v", filepath.Base(name), err)
+			continue
+		}
+		if string(got) != want {
+			t.Errorf("%s = %q, want %q", filepath.Base(name), got, want)
+		}
+	}
+	if _, err := os.Stat(path + ".3"); !os.IsNotExist(err) {
+		t.Errorf("a third rotated file exists (err = %v), want only 2 kept", err)
+	}
+}
+
+func TestWriter_AppendsToExisting(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "access.log")
+	if err := os.WriteFile(path, []byte("old\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	w, err := Open(path, 1024, 1)
+	if err != nil {
+		t.Fatalf("Open() error: %v", err)
+	}
+	if _, err := w.Write([]byte("new\n")); err != nil {
+		t.Fatalf("Write() error: %v", err)
+	}
+	w.Close()
+
+	if got, _ := os.ReadFile(path); string(got) != "old\nnew\n" {
+		t.Errorf("file = %q, want the new line appended", got)
+	}
+}
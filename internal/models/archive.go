@@ -0,0 +1,129 @@
+package models
+
+import (
+	"encoding/json"
+	"iter"
+	"time"
+)
+
+// ArchiveData holds the contents of an export archive. Records are keyed by
+// natural keys (feed URLs, post URLs, tag names, preference keys) rather
+// than database IDs, so an archive can be restored into any database.
+// Blogs holds the fetched posts that are not on the reading list; posts on
+// it are part of their reading list item.
+type ArchiveData struct {
+	Sources     []ArchiveSource            `json:"sources"`
+	Blogs       []ArchivePost              `json:"blogs"`
+	ReadingList []ArchiveItem              `json:"reading_list"`
+	Tags        []ArchiveTag               `json:"tags"`
+	Preferences map[string]json.RawMessage `json:"preferences"`
+}
+
+// ArchiveStream is the contents of an export archive as it is read from the
+// database, so that an archive can be written while its records are
+// scanned instead of after all of them are loaded. Each iterator yields
+// the records of its section in archive order, or stops after yielding an
+// error. A nil iterator yields nothing.
+type ArchiveStream struct {
+	Sources     iter.Seq2[ArchiveSource, error]
+	Blogs       iter.Seq2[ArchivePost, error]
+	ReadingList iter.Seq2[ArchiveItem, error]
+	Tags        iter.Seq2[ArchiveTag, error]
+	Preferences map[string]json.RawMessage
+}
+
+// ArchiveSource is a blog source in an export archive.
+type ArchiveSource struct {
+	Name     string `json:"name"`
+	Company  string `json:"company"`
+	FeedURL  string `json:"feed_url"`
+	SiteURL  string `json:"site_url"`
+	IsActive bool   `json:"is_active"`
+}
+
+// ArchivePost is a blog post and its summary in an export archive.
+// SourceFeedURL is empty for user-added posts, whose display source is kept
+// in CustomSource.
+type ArchivePost struct {
+	URL           string     `json:"url"`
+	Title         string     `json:"title"`
+	Description   string     `json:"description,omitempty"`
+	Content       string     `json:"content,omitempty"`
+	SourceFeedURL string     `json:"source_feed_url,omitempty"`
+	CustomSource  string     `json:"custom_source,omitempty"`
+	PublishedAt   *time.Time `json:"published_at,omitempty"`
+
+	Summary      string `json:"summary,omitempty"`
+	SummaryModel string `json:"summary_model,omitempty"`
+	Difficulty   string `json:"difficulty,omitempty"`
+	Category     string `json:"category,omitempty"`
+}
+
+// ArchiveItem is a reading list item in an export archive, together with its
+// post and summary.
+type ArchiveItem struct {
+	ArchivePost
+
+	Status       string     `json:"status"`
+	Progress     int        `json:"progress"`
+	Notes        string     `json:"notes,omitempty"`
+	Tags         []string   `json:"tags"`
+	AddedAt      time.Time  `json:"added_at"`
+	ReadAt       *time.Time `json:"read_at,omitempty"`
+	ArchivedAt   *time.Time `json:"archived_at,omitempty"`
+	SnoozedUntil *time.Time `json:"snoozed_until,omitempty"`
+	Position     *int       `json:"position,omitempty"`
+}
+
+// ArchiveTag is a tag's display metadata in an export archive. Which items
+// carry a tag is recorded on the items.
+type ArchiveTag struct {
+	Name        string `json:"name"`
+	Color       string `json:"color,omitempty"`
+	Description string `json:"description,omitempty"`
+}
+
+// ArchiveImportResult counts what an import added, and what it overwrote or
+// skipped because a record with the same key already existed, as chosen by
+// OnConflict. Reading list items whose post was already in the database
+// (from discovery, say) are counted as merged. For dry runs, Changes lists
+// the action for every record.
+type ArchiveImportResult struct {
+	DryRun                 bool                  `json:"dry_run,omitempty"`
+	OnConflict             string                `json:"on_conflict"`
+	SourcesAdded           int                   `json:"sources_added"`
+	SourcesOverwritten     int                   `json:"sources_overwritten"`
+	SourcesSkipped         int                   `json:"sources_skipped"`
+	BlogsAdded             int                   `json:"blogs_added"`
+	BlogsOverwritten       int                   `json:"blogs_overwritten"`
+	BlogsSkipped           int                   `json:"blogs_skipped"`
+	ItemsAdded             int                   `json:"items_added"`
+	ItemsMerged            int                   `json:"items_merged"`
+	ItemsOverwritten       int                   `json:"items_overwritten"`
+	ItemsSkipped           int                   `json:"items_skipped"`
+	TagsAdded              int                   `json:"tags_added"`
+	TagsOverwritten        int                   `json:"tags_overwritten"`
+	TagsSkipped            int                   `json:"tags_skipped"`
+	PreferencesAdded       int                   `json:"preferences_added"`
+	PreferencesOverwritten int                   `json:"preferences_overwritten"`
+	PreferencesSkipped     int                   `json:"preferences_skipped"`
+	Changes                []ArchiveImportChange `json:"changes,omitempty"`
+}
+
+// Import actions.
+const (
+	ImportCreate    = "create"    // a new record is created
+	ImportMerge     = "merge"     // an existing post is added to the reading list
+	ImportOverwrite = "overwrite" // the record already exists and is replaced
+	ImportSkip      = "skip"      // the record already exists and is left unchanged
+)
+
+// ArchiveImportChange is the action an import takes for one record. Kind is
+// "source", "blog", "item", "tag", or "preference"; Key is its feed URL,
+// post URL, tag name, or preference key.
+type ArchiveImportChange struct {
+	Kind   string `json:"kind"`
+	Key    string `json:"key"`
+	Title  string `json:"title,omitempty"`
+	Action string `json:"action"`
+}
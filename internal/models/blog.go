@@ -14,6 +14,51 @@ type BlogSource struct {
 	LastFetchOK bool       `json:"last_fetch_ok"`
 	LastError   string     `json:"last_error,omitempty"`
 	CreatedAt   time.Time  `json:"created_at"`
+	// Version goes up whenever the source is toggled. It is the source's
+	// ETag, for If-Match on updates.
+	Version int64 `json:"version"`
+}
+
+// SourceScore rates a source by how the user treats its posts over a period:
+// how many recommended posts were saved, how many saved posts were read, and
+// thumbs feedback. Score combines the three into a value between 0 and 1,
+// smoothed so that a source with little activity stays near 0.5.
+type SourceScore struct {
+	SourceID       int64   `json:"source_id"`
+	Name           string  `json:"name"`
+	Recommended    int     `json:"recommended"`
+	Saved          int     `json:"saved"`
+	Read           int     `json:"read"`
+	ThumbsUp       int     `json:"thumbs_up"`
+	ThumbsDown     int     `json:"thumbs_down"`
+	SaveRate       float64 `json:"save_rate"`
+	CompletionRate float64 `json:"completion_rate"`
+	Score          float64 `json:"score"`
+}
+
+// SourceStats is what a source produced over a period: posts first fetched
+// from it, posts discovery selected, and posts the user read, with the
+// average relevance of the selected posts, from 1 for the top pick of a
+// session down toward 0 for its last. AverageRelevance is nil when nothing
+// was selected. Months breaks the totals down by calendar month, oldest
+// first, leaving out months with no activity.
+type SourceStats struct {
+	SourceID         int64              `json:"source_id"`
+	Name             string             `json:"name"`
+	Fetched          int                `json:"fetched"`
+	Selected         int                `json:"selected"`
+	Read             int                `json:"read"`
+	AverageRelevance *float64           `json:"average_relevance"`
+	Months           []SourceStatsMonth `json:"months"`
+}
+
+// SourceStatsMonth is a source's stats for one month, "2006-01".
+type SourceStatsMonth struct {
+	Month            string   `json:"month"`
+	Fetched          int      `json:"fetched"`
+	Selected         int      `json:"selected"`
+	Read             int      `json:"read"`
+	AverageRelevance *float64 `json:"average_relevance"`
 }
 
 // Blog represents an individual blog post discovered from an RSS feed.
@@ -29,16 +74,99 @@ type Blog struct {
 	FetchedAt   time.Time  `json:"fetched_at"`
 	ContentHash        string     `json:"content_hash,omitempty"`
 	ReadingTimeMinutes *int       `json:"reading_time_minutes,omitempty"`
+	Difficulty         string     `json:"difficulty,omitempty"`
+	RewrittenTitle     string     `json:"rewritten_title,omitempty"`
 	CreatedAt          time.Time  `json:"created_at"`
 }
 
-// BlogSummary holds a cached AI-generated summary for a blog post.
+// BlogRevision is an earlier version of a blog post's content, replaced when
+// a re-fetch found the post had been edited. FetchedAt is when this version
+// was fetched and ReplacedAt when a newer one superseded it.
+type BlogRevision struct {
+	ID          int64     `json:"id"`
+	Content     string    `json:"content"`
+	ContentHash string    `json:"content_hash"`
+	FetchedAt   time.Time `json:"fetched_at"`
+	ReplacedAt  time.Time `json:"replaced_at"`
+}
+
+// SearchResult is a blog post matching a search query. TitleHighlight is
+// the title with matched terms wrapped in <mark> tags, and Snippet a short
+// fragment of the best-matching field, marked up the same way.
+type SearchResult struct {
+	Blog
+	TitleHighlight string `json:"title_highlight"`
+	Snippet        string `json:"snippet"`
+}
+
+// SearchPage is one page of search results. Total counts every match.
+type SearchPage struct {
+	Results []SearchResult `json:"results"`
+	Total   int            `json:"total"`
+	Limit   int            `json:"limit"`
+	Offset  int            `json:"offset"`
+}
+
+// Difficulty levels estimated for a blog post.
+const (
+	DifficultyIntro        = "intro"
+	DifficultyIntermediate = "intermediate"
+	DifficultyDeepDive     = "deep-dive"
+)
+
+// Difficulties lists every valid difficulty level, from lightest to deepest.
+var Difficulties = []string{DifficultyIntro, DifficultyIntermediate, DifficultyDeepDive}
+
+// IsValidDifficulty reports whether d is one of Difficulties.
+func IsValidDifficulty(d string) bool {
+	for _, v := range Difficulties {
+		if d == v {
+			return true
+		}
+	}
+	return false
+}
+
+// Primary categories assigned to a blog post when it is summarized.
+var Categories = []string{
+	"architecture",
+	"databases",
+	"distributed-systems",
+	"infrastructure",
+	"devops",
+	"security",
+	"ai-ml",
+	"data-engineering",
+	"frontend",
+	"mobile",
+	"languages",
+	"testing",
+	"performance",
+	"culture",
+	"other",
+}
+
+// IsValidCategory reports whether c is one of Categories.
+func IsValidCategory(c string) bool {
+	for _, v := range Categories {
+		if c == v {
+			return true
+		}
+	}
+	return false
+}
+
+// BlogSummary holds a cached AI-generated summary for a blog post, with the
+// difficulty level and primary category produced alongside it.
 type BlogSummary struct {
-	ID        int64     `json:"id"`
-	BlogID    int64     `json:"blog_id"`
-	Summary   string    `json:"summary"`
-	ModelUsed string    `json:"model_used"`
-	CreatedAt time.Time `json:"created_at"`
+	ID         int64     `json:"id"`
+	BlogID     int64     `json:"blog_id"`
+	Summary    string    `json:"summary"`
+	Difficulty string    `json:"difficulty,omitempty"`
+	Category   string    `json:"category,omitempty"`
+	ModelUsed  string    `json:"model_used"`
+	Stale      bool      `json:"stale,omitempty"` // content changed since summarizing
+	CreatedAt  time.Time `json:"created_at"`
 }
 
 // DiscoverySession records an audit trail of each discovery run.
@@ -52,5 +180,6 @@ type DiscoverySession struct {
 	OutputTokens        *int      `json:"output_tokens,omitempty"`
 	ResultsJSON         string    `json:"results_json,omitempty"`
 	FailedFeedsJSON     string    `json:"failed_feeds_json,omitempty"`
+	Fallback            bool      `json:"fallback,omitempty"` // ranked without AI
 	CreatedAt           time.Time `json:"created_at"`
 }
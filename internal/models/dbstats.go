@@ -0,0 +1,32 @@
+package models
+
+import "time"
+
+// TableStats is the number of rows in one database table.
+type TableStats struct {
+	Name string `json:"name"`
+	Rows int64  `json:"rows"`
+}
+
+// DBStats describes the size and contents of the database.
+type DBStats struct {
+	Driver    string `json:"driver"`
+	SizeBytes int64  `json:"size_bytes"`
+	// FreeBytes is space inside the database file that holds no data and
+	// would be returned by a vacuum. SQLite only.
+	FreeBytes int64 `json:"free_bytes"`
+	// WALSizeBytes is the size of the write-ahead log. SQLite only.
+	WALSizeBytes      int64        `json:"wal_size_bytes"`
+	Tables            []TableStats `json:"tables"`
+	LastVacuumAt      *time.Time   `json:"last_vacuum_at,omitempty"`
+	LastMaintenanceAt *time.Time   `json:"last_maintenance_at,omitempty"`
+}
+
+// MaintenanceResult reports a database maintenance run.
+type MaintenanceResult struct {
+	// Steps lists the tasks run, in order.
+	Steps           []string `json:"steps"`
+	SizeBeforeBytes int64    `json:"size_before_bytes"`
+	SizeAfterBytes  int64    `json:"size_after_bytes"`
+	DurationMs      int64    `json:"duration_ms"`
+}
@@ -0,0 +1,23 @@
+package models
+
+import "time"
+
+// Migration is the status of one schema migration.
+type Migration struct {
+	Version   int        `json:"version"`
+	Name      string     `json:"name"`
+	Applied   bool       `json:"applied"`
+	AppliedAt *time.Time `json:"applied_at,omitempty"`
+	// Reversible reports whether the migration has down SQL, so that
+	// RollbackTo can revert it.
+	Reversible bool `json:"reversible"`
+}
+
+// MigrationStatus lists the schema migrations known to this build and which
+// of them the database has applied.
+type MigrationStatus struct {
+	// Version is the highest applied migration.
+	Version    int         `json:"version"`
+	Pending    int         `json:"pending"`
+	Migrations []Migration `json:"migrations"`
+}
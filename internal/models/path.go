@@ -0,0 +1,33 @@
+package models
+
+import "time"
+
+// LearningPath is an ordered collection of reading list items, such as a
+// curriculum on a single topic. Completion is derived from the status of
+// each item: an item counts as completed once it is marked "read" (or
+// archived after reading).
+type LearningPath struct {
+	ID             int64              `json:"id"`
+	Title          string             `json:"title"`
+	Description    string             `json:"description,omitempty"`
+	Topic          string             `json:"topic,omitempty"`
+	TotalItems     int                `json:"total_items"`
+	CompletedItems int                `json:"completed_items"`
+	Items          []LearningPathItem `json:"items,omitempty"`
+	CreatedAt      time.Time          `json:"created_at"`
+	UpdatedAt      time.Time          `json:"updated_at"`
+}
+
+// LearningPathItem is a reading list item at a position within a path. Note
+// explains why the item is in the path, e.g. the generator's reasoning.
+type LearningPathItem struct {
+	Position int             `json:"position"`
+	Note     string          `json:"note,omitempty"`
+	Item     ReadingListItem `json:"item"`
+}
+
+// LearningPathStep is an entry used when creating or reordering a path.
+type LearningPathStep struct {
+	ReadingListID int64  `json:"reading_list_id"`
+	Note          string `json:"note,omitempty"`
+}
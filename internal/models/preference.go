@@ -10,3 +10,18 @@ type Preference struct {
 	Value     string    `json:"value"`
 	UpdatedAt time.Time `json:"updated_at"`
 }
+
+// Topic importance bounds, from nice to have to must have.
+const (
+	MinTopicImportance     = 1
+	MaxTopicImportance     = 5
+	DefaultTopicImportance = 3
+)
+
+// Topic is an interest from the "topics" preference with its importance.
+// The preference holds either a list of topics or, for older settings, a
+// free-form string.
+type Topic struct {
+	Topic      string `json:"topic"`
+	Importance int    `json:"importance"`
+}
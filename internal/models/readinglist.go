@@ -8,10 +8,103 @@ type ReadingListItem struct {
 	BlogID  int64      `json:"blog_id"`
 	Blog    *Blog      `json:"blog,omitempty"`
 	Summary *string    `json:"summary,omitempty"`
+	Category string     `json:"category,omitempty"`
 	Status   string     `json:"status"`
 	Progress int        `json:"progress"`
 	Notes    *string    `json:"notes,omitempty"`
 	Tags    []string   `json:"tags"`
 	AddedAt time.Time  `json:"added_at"`
 	ReadAt  *time.Time `json:"read_at,omitempty"`
+	ArchivedAt *time.Time `json:"archived_at,omitempty"`
+	SnoozedUntil *time.Time `json:"snoozed_until,omitempty"`
+	Position     *int       `json:"position,omitempty"` // manual queue order; nil if never reordered
+	// ScrollPosition is where the reader left the item, as a fraction of
+	// the way down the page, and LastReadAt when; nil until it is read.
+	ScrollPosition *float64   `json:"scroll_position,omitempty"`
+	LastReadAt     *time.Time `json:"last_read_at,omitempty"`
+	// Version goes up whenever the status, notes, or snooze changes. It is
+	// the item's ETag, for If-Match on updates.
+	Version int64 `json:"version"`
+}
+
+// ReadingListPage is one page of reading list items. Total counts every
+// item matching the request's filters, across all pages.
+type ReadingListPage struct {
+	Items  []ReadingListItem `json:"items"`
+	Total  int               `json:"total"`
+	Limit  int               `json:"limit"`
+	Offset int               `json:"offset"`
+}
+
+// ReadingPlan is a set of unread items whose combined reading time fits a
+// time budget, in the order to read them.
+type ReadingPlan struct {
+	BudgetMinutes int               `json:"budget_minutes"`
+	TotalMinutes  int               `json:"total_minutes"`
+	Items         []ReadingListItem `json:"items"`
+	AIOrdered     bool              `json:"ai_ordered"` // ordered by the AI rather than queue order
+}
+
+// Tag is a reading list tag with its optional display metadata and the
+// number of items carrying it.
+type Tag struct {
+	Name        string `json:"name"`
+	Color       string `json:"color,omitempty"` // CSS hex color, such as "#f59e0b"
+	Description string `json:"description,omitempty"`
+	ItemCount   int    `json:"item_count"`
+}
+
+// NoteRevision is a saved version of a reading list item's Markdown notes.
+type NoteRevision struct {
+	ID        int64     `json:"id"`
+	Notes     string    `json:"notes"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// ShareLink is a public link to a reading list item's summary. Token is
+// the unguessable part of its URL, /s/{token}; URL is filled in by the
+// handlers. IncludeNotes shows the item's notes on the page too. A revoked
+// link no longer opens.
+type ShareLink struct {
+	ID            int64      `json:"id"`
+	Token         string     `json:"token"`
+	URL           string     `json:"url,omitempty"`
+	ReadingListID int64      `json:"reading_list_id"`
+	IncludeNotes  bool       `json:"include_notes"`
+	CreatedAt     time.Time  `json:"created_at"`
+	RevokedAt     *time.Time `json:"revoked_at,omitempty"`
+}
+
+// BookmarkLink ties a reading list item to the bookmark mirroring it in a
+// Raindrop.io or Pinboard account. LocalHash and RemoteHash fingerprint
+// both as they were after the last sync.
+type BookmarkLink struct {
+	ID            int64     `json:"id"`
+	Service       string    `json:"service"`
+	RemoteID      string    `json:"remote_id"`
+	ReadingListID int64     `json:"reading_list_id"`
+	URL           string    `json:"url"`
+	LocalHash     string    `json:"local_hash"`
+	RemoteHash    string    `json:"remote_hash"`
+	SyncedAt      time.Time `json:"synced_at"`
+}
+
+// NotionPage is the Notion page a reading list item was exported to, in
+// database DatabaseID. Hash fingerprints the property values last sent.
+type NotionPage struct {
+	ID            int64     `json:"id"`
+	DatabaseID    string    `json:"database_id"`
+	ReadingListID int64     `json:"reading_list_id"`
+	URL           string    `json:"url"`
+	PageID        string    `json:"page_id"`
+	Hash          string    `json:"hash"`
+	SyncedAt      time.Time `json:"synced_at"`
+}
+
+// ReviewItem is a read post due for spaced-repetition review. Review is the
+// 1-based number of the review that is due.
+type ReviewItem struct {
+	ReadingListItem
+	Review int       `json:"review"`
+	DueAt  time.Time `json:"due_at"`
 }
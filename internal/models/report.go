@@ -0,0 +1,35 @@
+package models
+
+import "time"
+
+// YearReport is an annual summary of the user's reading activity.
+type YearReport struct {
+	Year         int             `json:"year"`
+	ArticlesRead int             `json:"articles_read"`
+	TotalMinutes int             `json:"total_minutes"`
+	TopSources   []ReportCount   `json:"top_sources"`
+	TopTags      []ReportCount   `json:"top_tags"`
+	LongestRead  *ReportArticle  `json:"longest_read,omitempty"`
+	Notes        []ReportArticle `json:"notes"`
+	Articles     []ReportArticle `json:"articles"`
+	Narrative    string          `json:"narrative,omitempty"`
+}
+
+// ReportCount is a name with an occurrence count, used for ranked lists in
+// reports.
+type ReportCount struct {
+	Name  string `json:"name"`
+	Count int    `json:"count"`
+}
+
+// ReportArticle is a read article as it appears in a report.
+type ReportArticle struct {
+	BlogID             int64     `json:"blog_id"`
+	Title              string    `json:"title"`
+	URL                string    `json:"url"`
+	Source             string    `json:"source"`
+	Description        string    `json:"description,omitempty"`
+	ReadingTimeMinutes *int      `json:"reading_time_minutes,omitempty"`
+	ReadAt             time.Time `json:"read_at"`
+	Note               string    `json:"note,omitempty"`
+}
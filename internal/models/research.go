@@ -0,0 +1,33 @@
+package models
+
+import "time"
+
+// Origins of a research source.
+const (
+	ResearchOriginArchive = "archive"
+	ResearchOriginFresh   = "fresh"
+)
+
+// ResearchReport is an answer to a research question synthesized from a set
+// of articles. The answer cites sources as [n], where n is a source's Ref.
+type ResearchReport struct {
+	ID        int64            `json:"id"`
+	Question  string           `json:"question"`
+	Answer    string           `json:"answer"`
+	Sources   []ResearchSource `json:"sources"`
+	ModelUsed string           `json:"model_used,omitempty"`
+	CreatedAt time.Time        `json:"created_at"`
+}
+
+// ResearchSource is an article cited by a research report. Origin records
+// whether it came from the user's archive or was freshly fetched for the
+// report.
+type ResearchSource struct {
+	Ref     int    `json:"ref"`
+	BlogID  int64  `json:"blog_id"`
+	Title   string `json:"title"`
+	URL     string `json:"url"`
+	Source  string `json:"source"`
+	Summary string `json:"summary,omitempty"`
+	Origin  string `json:"origin"`
+}
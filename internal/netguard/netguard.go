@@ -0,0 +1,117 @@
+// Package netguard keeps outbound fetches of user-supplied URLs from
+// reaching the machine Apricot runs on or the network around it.
+//
+// The page proxy and article extraction fetch whatever URL they are given,
+// so without a guard anyone who can use the API could have the server read
+// its own admin endpoints, a router's web UI, or a cloud metadata service.
+// Guard checks the address of every connection as it is dialed, after DNS
+// resolution and on every redirect, so a public name that resolves to a
+// private address is caught too.
+package netguard
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/netip"
+	"strings"
+	"syscall"
+)
+
+// ErrBlocked is returned (wrapped) when a connection to a blocked address is
+// refused.
+var ErrBlocked = errors.New("address is on a private or local network")
+
+// reserved lists blocked ranges that netip.Addr has no predicate for.
+var reserved = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),     // "this network"
+	netip.MustParsePrefix("100.64.0.0/10"), // carrier-grade NAT
+	netip.MustParsePrefix("192.0.0.0/24"),  // IETF protocol assignments
+	netip.MustParsePrefix("198.18.0.0/15"), // benchmarking
+	netip.MustParsePrefix("240.0.0.0/4"),   // reserved, and broadcast
+}
+
+// Guard refuses connections to loopback, private, link-local (which holds
+// the cloud metadata address 169.254.169.254), multicast, and reserved
+// addresses, except those in its allow list. A nil *Guard allows
+// everything.
+type Guard struct {
+	allow []netip.Prefix
+}
+
+// New returns a Guard that lets through the networks in allow, each a CIDR
+// prefix such as "192.168.1.0/24" or a single address.
+func New(allow []string) (*Guard, error) {
+	g := &Guard{}
+	for _, s := range allow {
+		p, err := ParsePrefix(s)
+		if err != nil {
+			return nil, err
+		}
+		g.allow = append(g.allow, p)
+	}
+	return g, nil
+}
+
+// ParsePrefix parses a CIDR prefix or a single IP address, which is taken
+// as a prefix holding only that address.
+func ParsePrefix(s string) (netip.Prefix, error) {
+	if strings.Contains(s, "/") {
+		p, err := netip.ParsePrefix(s)
+		if err != nil {
+			return netip.Prefix{}, fmt.Errorf("invalid network %q: %w", s, err)
+		}
+		return p.Masked(), nil
+	}
+	addr, err := netip.ParseAddr(s)
+	if err != nil {
+		return netip.Prefix{}, fmt.Errorf("invalid network %q: must be an IP address or CIDR prefix", s)
+	}
+	return netip.PrefixFrom(addr.Unmap(), addr.Unmap().BitLen()), nil
+}
+
+// Allowed reports whether connections to addr are allowed.
+func (g *Guard) Allowed(addr netip.Addr) bool {
+	if g == nil {
+		return true
+	}
+	addr = addr.Unmap()
+	for _, p := range g.allow {
+		if p.Contains(addr) {
+			return true
+		}
+	}
+	return !blocked(addr)
+}
+
+// blocked reports whether addr is in a range Guard refuses by default.
+func blocked(addr netip.Addr) bool {
+	if addr.IsLoopback() || addr.IsPrivate() || addr.IsUnspecified() ||
+		addr.IsLinkLocalUnicast() || addr.IsMulticast() {
+		return true
+	}
+	for _, p := range reserved {
+		if p.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// Control is a net.Dialer Control function that refuses connections to
+// addresses the guard does not allow. It sees the resolved address, so set
+// it on the dialer of every transport that fetches user-supplied URLs.
+func (g *Guard) Control(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	addr, err := netip.ParseAddr(host)
+	if err != nil {
+		return err
+	}
+	if !g.Allowed(addr) {
+		return fmt.Errorf("%w: %s", ErrBlocked, addr)
+	}
+	return nil
+}
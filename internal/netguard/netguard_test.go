@@ -0,0 +1,78 @@
+package netguard
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+)
+
+func TestGuard_Allowed(t *testing.T) {
+	g, err := New([]string{"192.168.1.0/24", "10.0.0.5"})
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+
+	for addr, want := range map[string]bool{
+		"93.184.215.14":         true,
+		"2606:2800:21f:cb07::1": true,
+		"127.0.0.1":             false,
+		"::1":                   false,
+		"::ffff:127.0.0.1":      false,
+		"0.0.0.0":               false,
+		"10.1.2.3":              false,
+		"172.20.0.1":            false,
+		"192.168.2.1":           false,
+		"169.254.169.254":       false,
+		"100.100.100.200":       false,
+		"fd00:ec2::254":         false,
+		"fe80::1":               false,
+		"224.0.0.1":             false,
+		"255.255.255.255":       false,
+		"192.168.1.20":          true, // allowed network
+		"10.0.0.5":              true, // allowed address
+		"::ffff:192.168.1.20":   true,
+	} {
+		if got := g.Allowed(netip.MustParseAddr(addr)); got != want {
+			t.Errorf("Allowed(%s) = %v, want %v", addr, got, want)
+		}
+	}
+
+	var none *Guard
+	if !none.Allowed(netip.MustParseAddr("127.0.0.1")) {
+		t.Error("a nil Guard blocked 127.0.0.1")
+	}
+}
+
+func TestNew_InvalidNetwork(t *testing.T) {
+	for _, s := range []string{"192.168.1.0/33", "lan", ""} {
+		if _, err := New([]string{s}); err == nil {
+			t.Errorf("New(%q) expected an error, got nil", s)
+		}
+	}
+}
+
+func TestGuard_Control(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+
+	client := func(g *Guard) *http.Client {
+		dialer := &net.Dialer{Control: g.Control}
+		return &http.Client{Transport: &http.Transport{DialContext: dialer.DialContext}}
+	}
+
+	g, _ := New(nil)
+	_, err := client(g).Get(srv.URL)
+	if !errors.Is(err, ErrBlocked) {
+		t.Errorf("Get(%s) error = %v, want ErrBlocked", srv.URL, err)
+	}
+
+	g, _ = New([]string{"127.0.0.1"})
+	resp, err := client(g).Get(srv.URL)
+	if err != nil {
+		t.Fatalf("Get(%s) with loopback allowed: %v", srv.URL, err)
+	}
+	resp.Body.Close()
+}
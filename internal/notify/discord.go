@@ -0,0 +1,70 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/hoanghai1803/apricot/internal/jobs"
+)
+
+// maxDiscordEmbeds is the most embeds Discord accepts in one message.
+const maxDiscordEmbeds = 10
+
+// Discord is a target that posts the top discovery results to a channel
+// through a Discord webhook, one embed per post. It is only sent
+// discovery.completed.
+type Discord struct {
+	WebhookURL string
+
+	// Top is how many results are posted, at most 10; 0 means DefaultTop.
+	Top int
+}
+
+// Name implements Target.
+func (d Discord) Name() string { return TargetName("discord", d.WebhookURL) }
+
+// Wants implements Target.
+func (d Discord) Wants(event string) bool { return event == EventDiscoveryCompleted }
+
+type discordMessage struct {
+	Content string         `json:"content"`
+	Embeds  []discordEmbed `json:"embeds"`
+}
+
+type discordEmbed struct {
+	Title       string         `json:"title"`
+	URL         string         `json:"url"`
+	Description string         `json:"description,omitempty"`
+	Footer      *discordFooter `json:"footer,omitempty"`
+}
+
+type discordFooter struct {
+	Text string `json:"text"`
+}
+
+// Send implements Target. A run with no results posts nothing.
+func (d Discord) Send(ctx context.Context, client *http.Client, e Event) error {
+	results, err := decodeDiscovery(e, d.Top)
+	if err != nil || len(results) == 0 {
+		return err
+	}
+	results = results[:min(len(results), maxDiscordEmbeds)]
+
+	msg := discordMessage{Content: fmt.Sprintf("Apricot picked %d posts for you", len(results))}
+	for _, r := range results {
+		msg.Embeds = append(msg.Embeds, discordEmbed{
+			Title:       OneLine(r.DisplayTitle(), 256),
+			URL:         r.URL,
+			Description: OneLine(r.Summary, 200),
+			Footer:      &discordFooter{Text: r.Source},
+		})
+	}
+
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return jobs.Permanent(err)
+	}
+	return post(ctx, client, d.WebhookURL, "application/json", body, nil)
+}
@@ -0,0 +1,216 @@
+// Package notify tells people and other systems when something happens in
+// Apricot, so nobody has to poll the API.
+//
+// Handlers call Notifier.Emit with an event and its data. Emit queues a
+// "notify" job (see internal/jobs) for each target that wants the event,
+// so deliveries survive a restart and failed ones are retried with backoff.
+// A target is anything that can deliver an event: a webhook, or a chat
+// service such as Slack. Targets that are also Listeners take commands
+// back, such as a Telegram reply asking to save a post.
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/hoanghai1803/apricot/internal/jobs"
+	"github.com/hoanghai1803/apricot/internal/outbound"
+)
+
+// Events.
+const (
+	EventDiscoveryCompleted = "discovery.completed" // a discovery run saved its session
+	EventItemAdded          = "item.added"          // a post was added to the reading list
+	EventItemFinished       = "item.finished"       // a reading list item was marked read
+)
+
+// Events lists every event, for validating configuration.
+var Events = []string{EventDiscoveryCompleted, EventItemAdded, EventItemFinished}
+
+// Event is something that happened, with its data as JSON.
+type Event struct {
+	Type string          `json:"event"`
+	Time time.Time       `json:"time"`
+	Data json.RawMessage `json:"data"`
+}
+
+// Target delivers events somewhere.
+type Target interface {
+	// Name identifies the target in queued jobs, so it must be the same
+	// every time the server starts with the same configuration. It must
+	// not contain secrets, which jobs would store in the database.
+	Name() string
+
+	// Wants reports whether the target is sent event.
+	Wants(event string) bool
+
+	// Send delivers e. Errors that retrying won't fix should be wrapped
+	// with jobs.Permanent.
+	Send(ctx context.Context, client *http.Client, e Event) error
+}
+
+// SaveFunc adds the post at url, which Apricot already has, to the reading
+// list and returns its title. Its errors are shown to the person asking.
+type SaveFunc func(ctx context.Context, url string) (title string, err error)
+
+// Listener is a target that also takes commands from people, such as
+// replies to the messages it sent.
+type Listener interface {
+	Target
+
+	// Listen handles commands, saving posts with save, until ctx is done.
+	Listen(ctx context.Context, client *http.Client, save SaveFunc)
+}
+
+// TargetName returns a name for a target of kind configured with key, such
+// as its URL, that does not reveal key.
+func TargetName(kind, key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return kind + ":" + hex.EncodeToString(sum[:6])
+}
+
+// Notifier sends events to targets. A nil *Notifier sends nothing.
+type Notifier struct {
+	runner  *jobs.Manager
+	targets []Target
+	client  *http.Client
+}
+
+// New returns a Notifier that queues deliveries to targets on runner.
+// Register its Job with runner.
+func New(runner *jobs.Manager, targets ...Target) *Notifier {
+	return &Notifier{
+		runner:  runner,
+		targets: targets,
+		client: &http.Client{
+			Timeout:   30 * time.Second,
+			Transport: &outbound.Transport{Purpose: outbound.PurposeNotify},
+		},
+	}
+}
+
+// Wants reports whether any target is sent event, so callers can skip
+// loading data nobody will see.
+func (n *Notifier) Wants(event string) bool {
+	if n == nil {
+		return false
+	}
+	return slices.ContainsFunc(n.targets, func(t Target) bool { return t.Wants(event) })
+}
+
+// Listen runs every Listener target until ctx is done.
+func (n *Notifier) Listen(ctx context.Context, save SaveFunc) {
+	if n == nil {
+		return
+	}
+	// Long polls outlive the delivery client's timeout, so listeners
+	// bound their own requests.
+	client := &http.Client{Transport: n.client.Transport}
+	var wg sync.WaitGroup
+	for _, t := range n.targets {
+		if l, ok := t.(Listener); ok {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				l.Listen(ctx, client, save)
+			}()
+		}
+	}
+	wg.Wait()
+}
+
+// notifyPayload is the payload of a "notify" job.
+type notifyPayload struct {
+	Target string `json:"target"`
+	Event  Event  `json:"event"`
+}
+
+// Emit queues event, with data encoded as JSON, for every target that
+// wants it. Failures are logged, not returned: a notification never fails
+// the operation that caused it.
+func (n *Notifier) Emit(ctx context.Context, event string, data any) {
+	if !n.Wants(event) {
+		return
+	}
+	raw, err := json.Marshal(data)
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to encode event", "event", event, "error", err)
+		return
+	}
+	e := Event{Type: event, Time: time.Now().UTC(), Data: raw}
+	for _, t := range n.targets {
+		if !t.Wants(event) {
+			continue
+		}
+		if _, err := n.runner.Enqueue(ctx, "notify", notifyPayload{Target: t.Name(), Event: e}); err != nil {
+			slog.ErrorContext(ctx, "failed to queue notification", "event", event, "target", t.Name(), "error", err)
+		}
+	}
+}
+
+// Job returns the "notify" job kind, which sends an event to a target.
+// Delivery is retried up to five times, backing off from a minute, unless
+// it fails permanently or the target is no longer configured.
+func (n *Notifier) Job() jobs.Kind {
+	return jobs.Kind{
+		Name:        "notify",
+		Timeout:     time.Minute,
+		MaxAttempts: 5,
+		Backoff:     time.Minute,
+		Handler: func(ctx context.Context, payload json.RawMessage) (any, error) {
+			var p notifyPayload
+			if err := json.Unmarshal(payload, &p); err != nil {
+				return nil, jobs.Permanent(fmt.Errorf("decoding payload: %w", err))
+			}
+			i := slices.IndexFunc(n.targets, func(t Target) bool { return t.Name() == p.Target })
+			if i < 0 {
+				return nil, jobs.Permanent(errors.New("notification target is no longer configured"))
+			}
+			if err := n.targets[i].Send(ctx, n.client, p.Event); err != nil {
+				return nil, err
+			}
+			return map[string]string{"target": p.Target}, nil
+		},
+	}
+}
+
+// post sends body to url with the given headers. A 4xx answer other than
+// 408 or 429 is a permanent error.
+func post(ctx context.Context, client *http.Client, url, contentType string, body []byte, header http.Header) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return jobs.Permanent(err)
+	}
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	req.Header.Set("Content-Type", contentType)
+	req.Header.Set("User-Agent", "Apricot")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("sending notification: %w", err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10)) //nolint:errcheck
+
+	switch {
+	case resp.StatusCode < 300:
+		return nil
+	case resp.StatusCode < 500 && resp.StatusCode != http.StatusRequestTimeout && resp.StatusCode != http.StatusTooManyRequests:
+		return jobs.Permanent(fmt.Errorf("notification rejected with HTTP %d", resp.StatusCode))
+	default:
+		return fmt.Errorf("notification failed with HTTP %d", resp.StatusCode)
+	}
+}
@@ -0,0 +1,343 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/hoanghai1803/apricot/internal/jobs"
+	"github.com/hoanghai1803/apricot/internal/models"
+	"github.com/hoanghai1803/apricot/internal/storage"
+)
+
+func newTestRunner(t *testing.T) *jobs.Manager {
+	t.Helper()
+
+	db, err := storage.OpenDatabase(":memory:")
+	if err != nil {
+		t.Fatalf("opening test db: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	if err := storage.RunMigrations(db); err != nil {
+		t.Fatalf("running migrations: %v", err)
+	}
+	return jobs.NewManager(storage.NewSQLiteStore(db), 1)
+}
+
+func TestEmit(t *testing.T) {
+	type delivery struct {
+		event, signature string
+		body             []byte
+	}
+	var got []delivery
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		got = append(got, delivery{r.Header.Get("X-Apricot-Event"), r.Header.Get("X-Apricot-Signature"), body})
+	}))
+	defer srv.Close()
+
+	runner := newTestRunner(t)
+	n := New(runner,
+		Webhook{URL: srv.URL + "/all", Secret: "s3cret"},
+		Webhook{URL: srv.URL + "/items", Events: []string{EventItemAdded}},
+	)
+	runner.Register(n.Job())
+	ctx := context.Background()
+
+	if !n.Wants(EventDiscoveryCompleted) || !n.Wants(EventItemAdded) {
+		t.Error("Wants() = false for an event a webhook subscribes to")
+	}
+	var none *Notifier
+	if none.Wants(EventItemAdded) {
+		t.Error("a nil Notifier wants events")
+	}
+	none.Emit(ctx, EventItemAdded, nil) // must not panic
+
+	n.Emit(ctx, EventDiscoveryCompleted, map[string]int{"session_id": 7})
+	n.Emit(ctx, EventItemAdded, map[string]int{"item_id": 3})
+	if err := runner.Drain(ctx); err != nil {
+		t.Fatalf("Drain() error: %v", err)
+	}
+
+	if len(got) != 3 {
+		t.Fatalf("got %d deliveries, want 3 (two to /all, one to /items)", len(got))
+	}
+	first := got[0]
+	if first.event != EventDiscoveryCompleted {
+		t.Errorf("X-Apricot-Event = %q, want %q", first.event, EventDiscoveryCompleted)
+	}
+	if want := Sign("s3cret", first.body); first.signature != want {
+		t.Errorf("X-Apricot-Signature = %q, want %q", first.signature, want)
+	}
+	var e Event
+	if err := json.Unmarshal(first.body, &e); err != nil {
+		t.Fatalf("decoding delivery: %v", err)
+	}
+	if e.Type != EventDiscoveryCompleted || string(e.Data) != `{"session_id":7}` || e.Time.IsZero() {
+		t.Errorf("delivered %+v, want the event with its data", e)
+	}
+	for _, d := range got[1:] {
+		if d.event != EventItemAdded {
+			t.Errorf("X-Apricot-Event = %q, want %q", d.event, EventItemAdded)
+		}
+	}
+}
+
+func TestJob_ClientErrorIsPermanent(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusGone)
+	}))
+	defer srv.Close()
+
+	runner := newTestRunner(t)
+	n := New(runner, Webhook{URL: srv.URL})
+	runner.Register(n.Job())
+	ctx := context.Background()
+
+	n.Emit(ctx, EventItemFinished, map[string]int{"item_id": 1})
+	if err := runner.Drain(ctx); err != nil {
+		t.Fatalf("Drain() error: %v", err)
+	}
+	list, err := runner.List(ctx, storage.JobFilter{Kind: "notify"})
+	if err != nil {
+		t.Fatalf("List() error: %v", err)
+	}
+	if len(list) != 1 || list[0].Status != models.JobFailed || list[0].Attempts != 1 {
+		t.Errorf("jobs = %+v, want one failed after a single attempt", list)
+	}
+}
+
+func TestSlack(t *testing.T) {
+	var got []slackMessage
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var msg slackMessage
+		if err := json.NewDecoder(r.Body).Decode(&msg); err != nil {
+			t.Errorf("decoding message: %v", err)
+		}
+		got = append(got, msg)
+	}))
+	defer srv.Close()
+
+	runner := newTestRunner(t)
+	slack := Slack{WebhookURL: srv.URL, Top: 2}
+	n := New(runner, slack)
+	runner.Register(n.Job())
+	ctx := context.Background()
+
+	if n.Wants(EventItemAdded) {
+		t.Error("Slack wants item.added, want only discovery.completed")
+	}
+	if strings.Contains(slack.Name(), srv.URL) {
+		t.Errorf("Name() = %q reveals the webhook URL", slack.Name())
+	}
+
+	n.Emit(ctx, EventDiscoveryCompleted, Discovery{Results: []DiscoveryResult{
+		{Title: "Scaling <Postgres>", URL: "https://a.example/1", Source: "A Blog", Summary: "How we sharded.\nMore detail."},
+		{Title: "Old", RewrittenTitle: "Better", URL: "https://b.example/2", Source: "B Blog"},
+		{Title: "Third", URL: "https://c.example/3", Source: "C Blog"},
+	}})
+	n.Emit(ctx, EventDiscoveryCompleted, Discovery{}) // nothing to post
+	if err := runner.Drain(ctx); err != nil {
+		t.Fatalf("Drain() error: %v", err)
+	}
+
+	if len(got) != 1 {
+		t.Fatalf("got %d messages, want 1", len(got))
+	}
+	blocks := got[0].Blocks
+	if len(blocks) != 3 {
+		t.Fatalf("got %d blocks, want a header and the top 2 results", len(blocks))
+	}
+	if want := "*<https://a.example/1|Scaling &lt;Postgres&gt;>*\n_A Blog_\nHow we sharded."; blocks[1].Text.Text != want {
+		t.Errorf("first result = %q, want %q", blocks[1].Text.Text, want)
+	}
+	if !strings.Contains(blocks[2].Text.Text, "|Better>") {
+		t.Errorf("second result = %q, want the rewritten title", blocks[2].Text.Text)
+	}
+}
+
+func TestDiscord(t *testing.T) {
+	var got discordMessage
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&got) //nolint:errcheck
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	e := Event{Type: EventDiscoveryCompleted, Data: json.RawMessage(`{"results": [
+		{"title": "One", "url": "https://a.example/1", "source": "A Blog", "summary": "First."},
+		{"title": "Two", "url": "https://b.example/2", "source": "B Blog"}
+	]}`)}
+	if err := (Discord{WebhookURL: srv.URL, Top: 1}).Send(context.Background(), srv.Client(), e); err != nil {
+		t.Fatalf("Send() error: %v", err)
+	}
+	if len(got.Embeds) != 1 {
+		t.Fatalf("got %d embeds, want the top 1", len(got.Embeds))
+	}
+	if em := got.Embeds[0]; em.Title != "One" || em.URL != "https://a.example/1" || em.Description != "First." || em.Footer.Text != "A Blog" {
+		t.Errorf("embed = %+v, want the first result", em)
+	}
+}
+
+// fakeTelegram is a Bot API server that records sent messages and returns
+// updates once.
+type fakeTelegram struct {
+	*httptest.Server
+	sent    []map[string]any
+	updates string
+}
+
+func newFakeTelegram(t *testing.T, updates string) *fakeTelegram {
+	f := &fakeTelegram{updates: updates}
+	f.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/bot123:abc/sendMessage":
+			var msg map[string]any
+			json.NewDecoder(r.Body).Decode(&msg) //nolint:errcheck
+			f.sent = append(f.sent, msg)
+			io.WriteString(w, `{"ok": true}`) //nolint:errcheck
+		case "/bot123:abc/getUpdates":
+			io.WriteString(w, `{"ok": true, "result": `+f.updates+`}`) //nolint:errcheck
+			f.updates = "[]"
+		default:
+			t.Errorf("unexpected request to %s", r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(f.Close)
+	return f
+}
+
+func TestTelegram_Send(t *testing.T) {
+	api := newFakeTelegram(t, "[]")
+	tg := Telegram{Token: "123:abc", ChatID: 42, API: api.URL}
+
+	e := Event{Type: EventDiscoveryCompleted, Data: json.RawMessage(`{"results": [
+		{"title": "A & B", "url": "https://a.example/1?x=1&y=2", "source": "A Blog", "summary": "First."}
+	]}`)}
+	if err := tg.Send(context.Background(), api.Client(), e); err != nil {
+		t.Fatalf("Send() error: %v", err)
+	}
+	if len(api.sent) != 1 {
+		t.Fatalf("sent %d messages, want 1", len(api.sent))
+	}
+	msg := api.sent[0]
+	text, _ := msg["text"].(string)
+	if msg["chat_id"] != float64(42) || msg["parse_mode"] != "HTML" {
+		t.Errorf("message = %+v, want HTML to chat 42", msg)
+	}
+	if !strings.Contains(text, `1. <a href="https://a.example/1?x=1&amp;y=2">A &amp; B</a> · A Blog`) {
+		t.Errorf("text = %q, want the escaped, numbered result", text)
+	}
+}
+
+func TestTelegram_Replies(t *testing.T) {
+	reply := func(chat int64, text string) string {
+		return fmt.Sprintf(`{"update_id": %d, "message": {"message_id": 9, "text": %q, "chat": {"id": %d},
+			"reply_to_message": {"from": {"is_bot": true}, "entities": [
+				{"type": "bold"},
+				{"type": "text_link", "url": "https://a.example/1"},
+				{"type": "text_link", "url": "https://b.example/2"}
+			]}}}`, chat, text, chat)
+	}
+	api := newFakeTelegram(t, "["+reply(42, "2 and 7")+","+reply(99, "1")+"]")
+	tg := Telegram{Token: "123:abc", ChatID: 42, API: api.URL}
+
+	var saved []string
+	save := func(_ context.Context, url string) (string, error) {
+		saved = append(saved, url)
+		return "Post two", nil
+	}
+	offset, err := tg.poll(context.Background(), api.Client(), 0, save)
+	if err != nil {
+		t.Fatalf("poll() error: %v", err)
+	}
+	if offset != 100 {
+		t.Errorf("offset = %d, want 100", offset)
+	}
+	if len(saved) != 1 || saved[0] != "https://b.example/2" {
+		t.Errorf("saved %v, want only post 2, and nothing from another chat", saved)
+	}
+	if len(api.sent) != 1 {
+		t.Fatalf("sent %d answers, want 1", len(api.sent))
+	}
+	if text := api.sent[0]["text"]; text != "Added Post two\n7: there is no post with that number" {
+		t.Errorf("answer = %q", text)
+	}
+	if api.sent[0]["reply_to_message_id"] != float64(9) {
+		t.Errorf("answer replies to %v, want message 9", api.sent[0]["reply_to_message_id"])
+	}
+}
+
+func TestNtfy(t *testing.T) {
+	var got []*http.Request
+	var bodies []ntfyMessage
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var msg ntfyMessage
+		json.NewDecoder(r.Body).Decode(&msg) //nolint:errcheck
+		got, bodies = append(got, r), append(bodies, msg)
+	}))
+	defer srv.Close()
+
+	n := Ntfy{Server: srv.URL + "/", Topic: "apricot", Token: "tk", MustRead: []string{"Postgres"}, AppURL: "http://laptop.local:8080/"}
+	discovery := func(scheduled bool) Event {
+		data, _ := json.Marshal(Discovery{Scheduled: scheduled, Results: []DiscoveryResult{
+			{Title: "Sharding postgres", URL: "https://a.example/1", Source: "A Blog"},
+			{Title: "CSS tricks", URL: "https://b.example/2", Source: "B Blog"},
+			{Title: "Vacuum explained", Category: "PostgreSQL", URL: "https://c.example/3", Source: "C Blog"},
+		}})
+		return Event{Type: EventDiscoveryCompleted, Data: data}
+	}
+	ctx := context.Background()
+
+	if err := n.Send(ctx, srv.Client(), discovery(false)); err != nil {
+		t.Fatalf("Send() error: %v", err)
+	}
+	if len(got) != 0 {
+		t.Fatalf("pushed after a manual run, want only scheduled runs")
+	}
+	if err := n.Send(ctx, srv.Client(), discovery(true)); err != nil {
+		t.Fatalf("Send() error: %v", err)
+	}
+	if len(got) != 1 {
+		t.Fatalf("got %d pushes, want 1", len(got))
+	}
+	if auth := got[0].Header.Get("Authorization"); auth != "Bearer tk" {
+		t.Errorf("Authorization = %q, want the token", auth)
+	}
+	msg := bodies[0]
+	if msg.Topic != "apricot" || msg.Title != "2 must-read posts" || msg.Click != "http://laptop.local:8080/" {
+		t.Errorf("message = %+v, want 2 matches linking to the app", msg)
+	}
+	if msg.Message != "Sharding postgres · A Blog\nVacuum explained · C Blog" {
+		t.Errorf("Message = %q, want the two matching posts", msg.Message)
+	}
+	if len(msg.Actions) != 2 || msg.Actions[1].URL != "https://c.example/3" {
+		t.Errorf("Actions = %+v, want a button per post", msg.Actions)
+	}
+
+	n.MustRead = []string{"kubernetes"}
+	if err := n.Send(ctx, srv.Client(), discovery(true)); err != nil {
+		t.Fatalf("Send() error: %v", err)
+	}
+	if len(got) != 1 {
+		t.Errorf("pushed with no must-read match")
+	}
+}
+
+func TestOneLine(t *testing.T) {
+	for _, tt := range []struct{ in, want string }{
+		{"short", "short"},
+		{"  first line\nsecond", "first line"},
+		{"abcdefghij klm", "abcdefghi…"},
+	} {
+		if got := OneLine(tt.in, 10); got != tt.want {
+			t.Errorf("OneLine(%q, 10) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
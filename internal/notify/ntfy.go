@@ -0,0 +1,122 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/hoanghai1803/apricot/internal/jobs"
+)
+
+// maxNtfyActions is the most action buttons ntfy shows on a notification.
+const maxNtfyActions = 3
+
+// Ntfy is a target that pushes a notification to a phone through an ntfy
+// server (https://ntfy.sh or self-hosted) when a scheduled discovery run
+// picks posts matching the must-read topics. It is only sent
+// discovery.completed.
+type Ntfy struct {
+	Server string // e.g. "https://ntfy.sh"
+	Topic  string
+
+	// Token is an access token for a protected topic, if needed.
+	Token string
+
+	// MustRead lists topics, matched case-insensitively against each
+	// post's title, summary, and category. Empty means every post.
+	MustRead []string
+
+	// ManualRuns pushes after runs started by hand too, not just
+	// scheduled ones.
+	ManualRuns bool
+
+	// AppURL is where the app is reached from the phone. Tapping the
+	// notification opens it; without it, tapping opens the first post.
+	AppURL string
+}
+
+// Name implements Target.
+func (n Ntfy) Name() string { return TargetName("ntfy", n.Server+"/"+n.Topic) }
+
+// Wants implements Target.
+func (n Ntfy) Wants(event string) bool { return event == EventDiscoveryCompleted }
+
+// matches reports whether r mentions one of the must-read topics.
+func (n Ntfy) matches(r DiscoveryResult) bool {
+	if len(n.MustRead) == 0 {
+		return true
+	}
+	text := strings.ToLower(strings.Join([]string{r.Title, r.RewrittenTitle, r.Summary, r.Category}, "\n"))
+	for _, topic := range n.MustRead {
+		if strings.Contains(text, strings.ToLower(topic)) {
+			return true
+		}
+	}
+	return false
+}
+
+type ntfyMessage struct {
+	Topic   string       `json:"topic"`
+	Title   string       `json:"title"`
+	Message string       `json:"message"`
+	Click   string       `json:"click,omitempty"`
+	Tags    []string     `json:"tags,omitempty"`
+	Actions []ntfyAction `json:"actions,omitempty"`
+}
+
+type ntfyAction struct {
+	Action string `json:"action"`
+	Label  string `json:"label"`
+	URL    string `json:"url"`
+}
+
+// Send implements Target. Nothing is pushed for manual runs, unless
+// ManualRuns is set, or when no post matches.
+func (n Ntfy) Send(ctx context.Context, client *http.Client, e Event) error {
+	var d Discovery
+	if err := json.Unmarshal(e.Data, &d); err != nil {
+		return jobs.Permanent(fmt.Errorf("decoding discovery: %w", err))
+	}
+	if !d.Scheduled && !n.ManualRuns {
+		return nil
+	}
+	var picked []DiscoveryResult
+	for _, r := range d.Results {
+		if n.matches(r) {
+			picked = append(picked, r)
+		}
+	}
+	if len(picked) == 0 {
+		return nil
+	}
+
+	msg := ntfyMessage{Topic: n.Topic, Tags: []string{"books"}, Click: n.AppURL}
+	if len(picked) == 1 {
+		msg.Title = "Must read: " + picked[0].DisplayTitle()
+	} else {
+		msg.Title = fmt.Sprintf("%d must-read posts", len(picked))
+	}
+	var lines []string
+	for i, r := range picked {
+		lines = append(lines, r.DisplayTitle()+" · "+r.Source)
+		if i < maxNtfyActions {
+			msg.Actions = append(msg.Actions, ntfyAction{Action: "view", Label: OneLine(r.DisplayTitle(), 40), URL: r.URL})
+		}
+	}
+	msg.Message = strings.Join(lines, "\n")
+	if msg.Click == "" {
+		msg.Click = picked[0].URL
+	}
+
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return jobs.Permanent(err)
+	}
+	var header http.Header
+	if n.Token != "" {
+		header = http.Header{"Authorization": {"Bearer " + n.Token}}
+	}
+	return post(ctx, client, strings.TrimRight(n.Server, "/"), "application/json", body, header)
+}
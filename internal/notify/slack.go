@@ -0,0 +1,130 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/hoanghai1803/apricot/internal/jobs"
+)
+
+// DefaultTop is how many discovery results chat targets post when their
+// configuration doesn't say.
+const DefaultTop = 5
+
+// Discovery is the part of a discovery.completed event's data that chat
+// targets show: the ranked results of the run.
+type Discovery struct {
+	SessionID int64             `json:"session_id"`
+	Results   []DiscoveryResult `json:"results"`
+	Scheduled bool              `json:"scheduled"`
+}
+
+// DiscoveryResult is one ranked post in a Discovery.
+type DiscoveryResult struct {
+	Title          string `json:"title"`
+	RewrittenTitle string `json:"rewritten_title"`
+	URL            string `json:"url"`
+	Source         string `json:"source"`
+	Summary        string `json:"summary"`
+	Category       string `json:"category"`
+}
+
+// DisplayTitle returns the AI rewritten title if there is one.
+func (r DiscoveryResult) DisplayTitle() string {
+	if r.RewrittenTitle != "" {
+		return r.RewrittenTitle
+	}
+	return r.Title
+}
+
+// decodeDiscovery returns the first top results of a discovery.completed
+// event.
+func decodeDiscovery(e Event, top int) ([]DiscoveryResult, error) {
+	var d Discovery
+	if err := json.Unmarshal(e.Data, &d); err != nil {
+		return nil, jobs.Permanent(fmt.Errorf("decoding discovery: %w", err))
+	}
+	if top <= 0 {
+		top = DefaultTop
+	}
+	return d.Results[:min(top, len(d.Results))], nil
+}
+
+// OneLine returns the first line of s, cut to at most n runes with an
+// ellipsis, for showing a summary in a chat message.
+func OneLine(s string, n int) string {
+	s, _, _ = strings.Cut(strings.TrimSpace(s), "\n")
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+	runes := []rune(s)
+	return strings.TrimSpace(string(runes[:n-1])) + "…"
+}
+
+// Slack is a target that posts the top discovery results to a channel
+// through a Slack incoming webhook. It is only sent discovery.completed.
+type Slack struct {
+	WebhookURL string
+
+	// Top is how many results are posted; 0 means DefaultTop.
+	Top int
+}
+
+// Name implements Target.
+func (s Slack) Name() string { return TargetName("slack", s.WebhookURL) }
+
+// Wants implements Target.
+func (s Slack) Wants(event string) bool { return event == EventDiscoveryCompleted }
+
+// slackMessage is an incoming webhook message. Text is the fallback shown
+// in notifications; Blocks is what the channel shows.
+type slackMessage struct {
+	Text   string       `json:"text"`
+	Blocks []slackBlock `json:"blocks"`
+}
+
+type slackBlock struct {
+	Type string     `json:"type"`
+	Text *slackText `json:"text,omitempty"`
+}
+
+type slackText struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+// Send implements Target. A run with no results posts nothing.
+func (s Slack) Send(ctx context.Context, client *http.Client, e Event) error {
+	results, err := decodeDiscovery(e, s.Top)
+	if err != nil || len(results) == 0 {
+		return err
+	}
+
+	heading := fmt.Sprintf("Apricot picked %d posts for you", len(results))
+	msg := slackMessage{
+		Text:   heading,
+		Blocks: []slackBlock{{Type: "header", Text: &slackText{Type: "plain_text", Text: heading}}},
+	}
+	for _, r := range results {
+		text := fmt.Sprintf("*<%s|%s>*\n_%s_", r.URL, slackEscape(r.DisplayTitle()), slackEscape(r.Source))
+		if summary := OneLine(r.Summary, 200); summary != "" {
+			text += "\n" + slackEscape(summary)
+		}
+		msg.Blocks = append(msg.Blocks, slackBlock{Type: "section", Text: &slackText{Type: "mrkdwn", Text: text}})
+	}
+
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return jobs.Permanent(err)
+	}
+	return post(ctx, client, s.WebhookURL, "application/json", body, nil)
+}
+
+// slackEscape escapes the characters Slack's mrkdwn treats as markup.
+func slackEscape(s string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(s)
+}
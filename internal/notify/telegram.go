@@ -0,0 +1,226 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"html"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/hoanghai1803/apricot/internal/jobs"
+)
+
+// TelegramAPI is the Telegram Bot API's address.
+const TelegramAPI = "https://api.telegram.org"
+
+// telegramPoll is how long a getUpdates call waits for a message.
+const telegramPoll = 50 * time.Second
+
+// Telegram is a target that sends the top discovery results to a chat as a
+// Telegram bot. It is only sent discovery.completed. It also listens for
+// replies to its messages, so the chat can add posts to the reading list
+// by answering with their numbers.
+type Telegram struct {
+	Token  string
+	ChatID int64
+
+	// Top is how many results are sent; 0 means DefaultTop.
+	Top int
+
+	// API is the Bot API's address; empty means TelegramAPI.
+	API string
+}
+
+// Name implements Target.
+func (t Telegram) Name() string {
+	return TargetName("telegram", t.Token+"/"+strconv.FormatInt(t.ChatID, 10))
+}
+
+// Wants implements Target.
+func (t Telegram) Wants(event string) bool { return event == EventDiscoveryCompleted }
+
+// Send implements Target. A run with no results sends nothing.
+func (t Telegram) Send(ctx context.Context, client *http.Client, e Event) error {
+	results, err := decodeDiscovery(e, t.Top)
+	if err != nil || len(results) == 0 {
+		return err
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "<b>Apricot picked %d posts for you</b>\n", len(results))
+	for i, r := range results {
+		fmt.Fprintf(&b, "\n%d. <a href=\"%s\">%s</a> · %s", i+1,
+			html.EscapeString(r.URL), html.EscapeString(r.DisplayTitle()), html.EscapeString(r.Source))
+		if summary := OneLine(r.Summary, 200); summary != "" {
+			fmt.Fprintf(&b, "\n<i>%s</i>", html.EscapeString(summary))
+		}
+		b.WriteString("\n")
+	}
+	b.WriteString("\nReply with the numbers of the posts to add to your reading list.")
+
+	return t.send(ctx, client, b.String(), 0)
+}
+
+// send sends text, in Telegram's HTML, to the chat, as a reply to message
+// replyTo unless it is 0.
+func (t Telegram) send(ctx context.Context, client *http.Client, text string, replyTo int64) error {
+	msg := map[string]any{
+		"chat_id":                  t.ChatID,
+		"text":                     text,
+		"parse_mode":               "HTML",
+		"disable_web_page_preview": true,
+	}
+	if replyTo != 0 {
+		msg["reply_to_message_id"] = replyTo
+	}
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return jobs.Permanent(err)
+	}
+	return post(ctx, client, t.method("sendMessage"), "application/json", body, nil)
+}
+
+// method returns the URL of a Bot API method.
+func (t Telegram) method(name string) string {
+	api := t.API
+	if api == "" {
+		api = TelegramAPI
+	}
+	return api + "/bot" + t.Token + "/" + name
+}
+
+// telegramUpdate is the part of a Bot API update that Listen reads.
+type telegramUpdate struct {
+	ID      int64 `json:"update_id"`
+	Message *struct {
+		ID   int64  `json:"message_id"`
+		Text string `json:"text"`
+		Chat struct {
+			ID int64 `json:"id"`
+		} `json:"chat"`
+		ReplyTo *struct {
+			From struct {
+				IsBot bool `json:"is_bot"`
+			} `json:"from"`
+			Entities []struct {
+				Type string `json:"type"`
+				URL  string `json:"url"`
+			} `json:"entities"`
+		} `json:"reply_to_message"`
+	} `json:"message"`
+}
+
+// Listen implements Listener. It long-polls the bot for messages until ctx
+// is done, answering replies to its own messages in the configured chat;
+// messages from anywhere else are ignored.
+func (t Telegram) Listen(ctx context.Context, client *http.Client, save SaveFunc) {
+	var offset int64
+	for ctx.Err() == nil {
+		next, err := t.poll(ctx, client, offset, save)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			slog.WarnContext(ctx, "failed to read Telegram messages; retrying", "chat_id", t.ChatID, "error", err)
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(30 * time.Second):
+			}
+			continue
+		}
+		offset = next
+	}
+}
+
+// poll waits for updates after offset, handles them, and returns the offset
+// to ask for next.
+func (t Telegram) poll(ctx context.Context, client *http.Client, offset int64, save SaveFunc) (int64, error) {
+	q := url.Values{
+		"offset":          {strconv.FormatInt(offset, 10)},
+		"timeout":         {strconv.Itoa(int(telegramPoll.Seconds()))},
+		"allowed_updates": {`["message"]`},
+	}
+	ctx, cancel := context.WithTimeout(ctx, telegramPoll+10*time.Second)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, t.method("getUpdates")+"?"+q.Encode(), nil)
+	if err != nil {
+		return offset, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return offset, err
+	}
+	defer resp.Body.Close()
+
+	var page struct {
+		OK          bool             `json:"ok"`
+		Description string           `json:"description"`
+		Result      []telegramUpdate `json:"result"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&page); err != nil {
+		return offset, fmt.Errorf("decoding updates: HTTP %d: %w", resp.StatusCode, err)
+	}
+	if !page.OK {
+		return offset, fmt.Errorf("getUpdates: %s", page.Description)
+	}
+	for _, u := range page.Result {
+		offset = max(offset, u.ID+1)
+		t.handle(ctx, client, u, save)
+	}
+	return offset, nil
+}
+
+// handle answers a reply to one of the bot's messages by adding the posts
+// whose numbers it lists, or the only post if the message had one.
+func (t Telegram) handle(ctx context.Context, client *http.Client, u telegramUpdate, save SaveFunc) {
+	m := u.Message
+	if m == nil || m.Chat.ID != t.ChatID || m.ReplyTo == nil || !m.ReplyTo.From.IsBot {
+		return
+	}
+	var links []string
+	for _, e := range m.ReplyTo.Entities {
+		if e.Type == "text_link" {
+			links = append(links, e.URL)
+		}
+	}
+	if len(links) == 0 {
+		return
+	}
+
+	var picked []int
+	for _, f := range strings.FieldsFunc(m.Text, func(r rune) bool { return !unicode.IsDigit(r) }) {
+		if n, err := strconv.Atoi(f); err == nil {
+			picked = append(picked, n)
+		}
+	}
+	if len(picked) == 0 && len(links) == 1 {
+		picked = []int{1}
+	}
+
+	var lines []string
+	for _, n := range picked {
+		if n < 1 || n > len(links) {
+			lines = append(lines, fmt.Sprintf("%d: there is no post with that number", n))
+			continue
+		}
+		title, err := save(ctx, links[n-1])
+		if err != nil {
+			lines = append(lines, fmt.Sprintf("%d: %s", n, html.EscapeString(err.Error())))
+			continue
+		}
+		lines = append(lines, fmt.Sprintf("Added %s", html.EscapeString(title)))
+	}
+	if len(lines) == 0 {
+		lines = []string{fmt.Sprintf("Reply with numbers from 1 to %d to add posts to your reading list.", len(links))}
+	}
+	if err := t.send(ctx, client, strings.Join(lines, "\n"), m.ID); err != nil {
+		slog.WarnContext(ctx, "failed to answer Telegram reply", "chat_id", t.ChatID, "error", err)
+	}
+}
@@ -0,0 +1,54 @@
+package notify
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"slices"
+
+	"github.com/hoanghai1803/apricot/internal/jobs"
+)
+
+// Webhook is a target that POSTs each event as JSON to a URL, for custom
+// automations.
+type Webhook struct {
+	URL string
+
+	// Events lists the events sent; empty means all of them.
+	Events []string
+
+	// Secret, if set, signs each delivery: the X-Apricot-Signature header
+	// holds "sha256=" and the hex HMAC-SHA256 of the body keyed with it.
+	Secret string
+}
+
+// Name implements Target.
+func (w Webhook) Name() string { return TargetName("webhook", w.URL) }
+
+// Wants implements Target.
+func (w Webhook) Wants(event string) bool {
+	return len(w.Events) == 0 || slices.Contains(w.Events, event)
+}
+
+// Send implements Target.
+func (w Webhook) Send(ctx context.Context, client *http.Client, e Event) error {
+	body, err := json.Marshal(e)
+	if err != nil {
+		return jobs.Permanent(err)
+	}
+	header := http.Header{"X-Apricot-Event": {e.Type}}
+	if w.Secret != "" {
+		header.Set("X-Apricot-Signature", Sign(w.Secret, body))
+	}
+	return post(ctx, client, w.URL, "application/json", body, header)
+}
+
+// Sign returns the X-Apricot-Signature header for body under secret.
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
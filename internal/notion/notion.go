@@ -0,0 +1,212 @@
+// Package notion creates and updates pages in a Notion database through
+// the Notion API (https://developers.notion.com), and builds property
+// values of whatever type the database's columns have.
+package notion
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hoanghai1803/apricot/internal/outbound"
+)
+
+// DefaultAPI is the Notion API's base URL.
+const DefaultAPI = "https://api.notion.com/v1"
+
+// Version is the Notion API version the client speaks.
+const Version = "2022-06-28"
+
+// maxRetries is how many times a rate-limited request is retried.
+const maxRetries = 3
+
+var (
+	// ErrUnauthorized is returned when Notion refuses the token.
+	ErrUnauthorized = errors.New("notion rejected the token")
+
+	// ErrNotFound is returned for a database or page that does not exist
+	// or that the integration was not given access to.
+	ErrNotFound = errors.New("not found in notion; check that the database is shared with the integration")
+)
+
+// Client talks to the Notion API with an internal integration's secret,
+// from https://www.notion.so/my-integrations.
+type Client struct {
+	Token string
+
+	// API is the API's base URL; empty means DefaultAPI.
+	API string
+
+	// HTTP makes the requests; nil means a client with a one-minute
+	// timeout whose requests are logged as exports.
+	HTTP *http.Client
+}
+
+// Database is a Notion database's schema: the type of each property, by
+// name.
+type Database struct {
+	Title      string
+	Properties map[string]string
+}
+
+// TitleProperty returns the name of the database's title property; every
+// database has exactly one.
+func (d *Database) TitleProperty() string {
+	for name, typ := range d.Properties {
+		if typ == "title" {
+			return name
+		}
+	}
+	return ""
+}
+
+// Database returns the schema of database id.
+func (c *Client) Database(ctx context.Context, id string) (*Database, error) {
+	var resp struct {
+		Title []struct {
+			PlainText string `json:"plain_text"`
+		} `json:"title"`
+		Properties map[string]struct {
+			Type string `json:"type"`
+		} `json:"properties"`
+	}
+	if err := c.do(ctx, http.MethodGet, "/databases/"+id, nil, &resp); err != nil {
+		return nil, err
+	}
+	db := &Database{Properties: make(map[string]string, len(resp.Properties))}
+	for _, t := range resp.Title {
+		db.Title += t.PlainText
+	}
+	for name, p := range resp.Properties {
+		db.Properties[name] = p.Type
+	}
+	return db, nil
+}
+
+// Properties are page property values by property name, as built by Text,
+// List, and Date.
+type Properties map[string]any
+
+// CreatePage adds a page with props to database databaseID and returns its
+// ID.
+func (c *Client) CreatePage(ctx context.Context, databaseID string, props Properties) (string, error) {
+	body := map[string]any{
+		"parent":     map[string]string{"database_id": databaseID},
+		"properties": props,
+	}
+	var resp struct {
+		ID string `json:"id"`
+	}
+	if err := c.do(ctx, http.MethodPost, "/pages", body, &resp); err != nil {
+		return "", err
+	}
+	return resp.ID, nil
+}
+
+// UpdatePage sets props on page id, leaving its other properties as they
+// are. It returns ErrNotFound if the page was deleted.
+func (c *Client) UpdatePage(ctx context.Context, id string, props Properties) error {
+	return c.do(ctx, http.MethodPatch, "/pages/"+id, map[string]any{"properties": props}, nil)
+}
+
+// ArchivePage moves page id to Notion's trash.
+func (c *Client) ArchivePage(ctx context.Context, id string) error {
+	return c.do(ctx, http.MethodPatch, "/pages/"+id, map[string]any{"archived": true}, nil)
+}
+
+// do sends a request to the API with body, if any, as JSON, and decodes
+// the response into out, if not nil. A rate-limited request is retried
+// after the wait Notion asks for.
+func (c *Client) do(ctx context.Context, method, path string, body, out any) error {
+	api := c.API
+	if api == "" {
+		api = DefaultAPI
+	}
+	client := c.HTTP
+	if client == nil {
+		client = &http.Client{
+			Timeout:   time.Minute,
+			Transport: &outbound.Transport{Purpose: outbound.PurposeExport},
+		}
+	}
+	var payload []byte
+	if body != nil {
+		var err error
+		if payload, err = json.Marshal(body); err != nil {
+			return fmt.Errorf("encoding request: %w", err)
+		}
+	}
+
+	for attempt := 0; ; attempt++ {
+		req, err := http.NewRequestWithContext(ctx, method, strings.TrimRight(api, "/")+path, bytes.NewReader(payload))
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Authorization", "Bearer "+c.Token)
+		req.Header.Set("Notion-Version", Version)
+		req.Header.Set("User-Agent", "Apricot")
+		if body != nil {
+			req.Header.Set("Content-Type", "application/json")
+		}
+
+		resp, err := client.Do(req)
+		if err != nil {
+			return fmt.Errorf("calling Notion: %w", err)
+		}
+		data, err := io.ReadAll(io.LimitReader(resp.Body, 4<<20))
+		resp.Body.Close()
+		if err != nil {
+			return fmt.Errorf("reading Notion response: %w", err)
+		}
+
+		switch {
+		case resp.StatusCode < 300:
+			if out == nil {
+				return nil
+			}
+			if err := json.Unmarshal(data, out); err != nil {
+				return fmt.Errorf("decoding Notion response: %w", err)
+			}
+			return nil
+		case resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden:
+			return ErrUnauthorized
+		case resp.StatusCode == http.StatusNotFound:
+			return ErrNotFound
+		case resp.StatusCode == http.StatusTooManyRequests && attempt < maxRetries:
+			wait, _ := strconv.Atoi(resp.Header.Get("Retry-After"))
+			t := time.NewTimer(time.Duration(max(wait, 1)) * time.Second)
+			select {
+			case <-ctx.Done():
+				t.Stop()
+				return ctx.Err()
+			case <-t.C:
+			}
+		default:
+			var apiErr struct {
+				Message string `json:"message"`
+			}
+			json.Unmarshal(data, &apiErr)
+			return &StatusError{Code: resp.StatusCode, Message: apiErr.Message}
+		}
+	}
+}
+
+// StatusError is an unexpected HTTP status from Notion.
+type StatusError struct {
+	Code    int
+	Message string
+}
+
+func (e *StatusError) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("notion returned HTTP %d", e.Code)
+	}
+	return fmt.Sprintf("notion returned HTTP %d: %s", e.Code, e.Message)
+}
@@ -0,0 +1,119 @@
+package notion
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestProperties(t *testing.T) {
+	day := time.Date(2026, 10, 12, 9, 0, 0, 0, time.Local)
+	for _, tc := range []struct {
+		name string
+		got  func() (any, error)
+		want string
+	}{
+		{"title", func() (any, error) { return Text("title", "Hello") }, `{"title":[{"text":{"content":"Hello"}}]}`},
+		{"empty rich text", func() (any, error) { return Text("rich_text", "") }, `{"rich_text":[]}`},
+		{"select", func() (any, error) { return Text("select", "A, Blog") }, `{"select":{"name":"A Blog"}}`},
+		{"empty status", func() (any, error) { return Text("status", "") }, `{"status":null}`},
+		{"url", func() (any, error) { return Text("url", "https://a.example") }, `{"url":"https://a.example"}`},
+		{"multi-select", func() (any, error) { return List("multi_select", []string{"go", " ", "c,c++"}) },
+			`{"multi_select":[{"name":"go"},{"name":"cc++"}]}`},
+		{"list as text", func() (any, error) { return List("rich_text", []string{"go", "sql"}) },
+			`{"rich_text":[{"text":{"content":"go, sql"}}]}`},
+		{"date", func() (any, error) { return Date("date", &day) }, `{"date":{"start":"2026-10-12"}}`},
+		{"no date", func() (any, error) { return Date("date", nil) }, `{"date":null}`},
+	} {
+		v, err := tc.got()
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", tc.name, err)
+			continue
+		}
+		if got, _ := json.Marshal(v); string(got) != tc.want {
+			t.Errorf("%s = %s, want %s", tc.name, got, tc.want)
+		}
+	}
+
+	long, _ := Text("rich_text", strings.Repeat("é", maxText+1))
+	if parts := long.(map[string]any)["rich_text"].([]map[string]any); len(parts) != 2 {
+		t.Errorf("text of %d runes split into %d parts, want 2", maxText+1, len(parts))
+	}
+	if _, err := Text("checkbox", "yes"); err == nil {
+		t.Error("Text(checkbox) expected an error, got nil")
+	}
+	if _, err := List("select", nil); err == nil {
+		t.Error("List(select) expected an error, got nil")
+	}
+}
+
+func TestClient(t *testing.T) {
+	var calls int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if got := r.Header.Get("Authorization"); got != "Bearer secret" {
+			t.Errorf("Authorization = %q", got)
+		}
+		if got := r.Header.Get("Notion-Version"); got != Version {
+			t.Errorf("Notion-Version = %q, want %q", got, Version)
+		}
+		switch {
+		case r.URL.Path == "/databases/db1":
+			w.Write([]byte(`{"title":[{"plain_text":"Read"},{"plain_text":"ing"}],
+				"properties":{"Name":{"type":"title"},"Tags":{"type":"multi_select"}}}`))
+		case r.URL.Path == "/pages" && calls == 2:
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusTooManyRequests)
+		case r.URL.Path == "/pages":
+			var body struct {
+				Parent struct {
+					DatabaseID string `json:"database_id"`
+				} `json:"parent"`
+			}
+			json.NewDecoder(r.Body).Decode(&body)
+			if body.Parent.DatabaseID != "db1" {
+				t.Errorf("page parent = %q, want db1", body.Parent.DatabaseID)
+			}
+			w.Write([]byte(`{"id":"page1"}`))
+		case r.URL.Path == "/pages/gone":
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"message":"Could not find page"}`))
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"message":"body failed validation"}`))
+		}
+	}))
+	defer srv.Close()
+
+	c := &Client{Token: "secret", API: srv.URL, HTTP: srv.Client()}
+	ctx := context.Background()
+
+	db, err := c.Database(ctx, "db1")
+	if err != nil {
+		t.Fatalf("Database() error: %v", err)
+	}
+	if db.Title != "Reading" || db.TitleProperty() != "Name" || db.Properties["Tags"] != "multi_select" {
+		t.Errorf("Database() = %+v", db)
+	}
+
+	id, err := c.CreatePage(ctx, "db1", Properties{})
+	if err != nil || id != "page1" {
+		t.Errorf("CreatePage() = %q, %v; want page1 after a retry", id, err)
+	}
+	if calls != 3 {
+		t.Errorf("made %d calls, want 3", calls)
+	}
+
+	if err := c.UpdatePage(ctx, "gone", Properties{}); !errors.Is(err, ErrNotFound) {
+		t.Errorf("UpdatePage(gone) error = %v, want ErrNotFound", err)
+	}
+	var se *StatusError
+	if err := c.ArchivePage(ctx, "bad"); !errors.As(err, &se) || se.Message != "body failed validation" {
+		t.Errorf("ArchivePage(bad) error = %v, want a StatusError with Notion's message", err)
+	}
+}
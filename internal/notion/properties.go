@@ -0,0 +1,91 @@
+package notion
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// maxText is the most characters Notion takes in one rich text object.
+const maxText = 2000
+
+// Text returns s as a value for a property of type typ: title, rich_text,
+// select, status, or url. Select and status options cannot contain commas,
+// so those are dropped.
+func Text(typ, s string) (any, error) {
+	switch typ {
+	case "title", "rich_text":
+		return map[string]any{typ: richText(s)}, nil
+	case "select", "status":
+		name := strings.TrimSpace(strings.ReplaceAll(s, ",", ""))
+		if name == "" {
+			return map[string]any{typ: nil}, nil
+		}
+		return map[string]any{typ: map[string]string{"name": truncate(name, 100)}}, nil
+	case "url":
+		if s == "" {
+			return map[string]any{"url": nil}, nil
+		}
+		return map[string]any{"url": s}, nil
+	default:
+		return nil, fmt.Errorf("cannot store text in a %s property", typ)
+	}
+}
+
+// List returns items as a value for a property of type typ: multi_select,
+// or a rich_text of the items separated by commas.
+func List(typ string, items []string) (any, error) {
+	switch typ {
+	case "multi_select":
+		options := make([]map[string]string, 0, len(items))
+		for _, item := range items {
+			if name := strings.TrimSpace(strings.ReplaceAll(item, ",", "")); name != "" {
+				options = append(options, map[string]string{"name": truncate(name, 100)})
+			}
+		}
+		return map[string]any{"multi_select": options}, nil
+	case "rich_text":
+		return Text(typ, strings.Join(items, ", "))
+	default:
+		return nil, fmt.Errorf("cannot store a list in a %s property", typ)
+	}
+}
+
+// Date returns the local date of t, without a time of day, as a value for
+// a property of type typ: date, or rich_text. A nil t clears the property.
+func Date(typ string, t *time.Time) (any, error) {
+	switch typ {
+	case "date":
+		if t == nil {
+			return map[string]any{"date": nil}, nil
+		}
+		return map[string]any{"date": map[string]string{"start": t.Local().Format(time.DateOnly)}}, nil
+	case "rich_text":
+		if t == nil {
+			return Text(typ, "")
+		}
+		return Text(typ, t.Local().Format(time.DateOnly))
+	default:
+		return nil, fmt.Errorf("cannot store a date in a %s property", typ)
+	}
+}
+
+// richText returns s as rich text objects, split to fit Notion's limit
+// per object.
+func richText(s string) []map[string]any {
+	parts := []map[string]any{}
+	for r := []rune(s); len(r) > 0; {
+		n := min(len(r), maxText)
+		parts = append(parts, map[string]any{"text": map[string]string{"content": string(r[:n])}})
+		r = r[n:]
+	}
+	return parts
+}
+
+// truncate cuts s to at most n runes.
+func truncate(s string, n int) string {
+	if r := []rune(s); len(r) > n {
+		return string(r[:n])
+	}
+	return s
+}
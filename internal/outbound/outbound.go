@@ -0,0 +1,177 @@
+// Package outbound records the HTTP requests Apricot makes to other hosts.
+//
+// Every HTTP client in the server (feed fetching, article extraction, the
+// page proxy, and the AI providers) sends requests through Transport, which
+// adds an entry to a fixed-size in-memory log once the response body has been
+// read. The log is served at /api/admin/outbound so users can see exactly
+// what the app talks to. Only the host and path are kept, never query
+// strings, headers, or bodies.
+package outbound
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Purposes of outbound requests.
+const (
+	PurposeFeed    = "feed"    // fetching an RSS/Atom feed or scraped listing page
+	PurposeExtract = "extract" // fetching an article page to extract its text
+	PurposeProxy   = "proxy"   // fetching a page for the reader's iframe proxy
+	PurposeAI      = "ai"      // calling the AI provider's API
+	PurposeNotify  = "notify"  // delivering a notification (webhooks, chat)
+	PurposeExport  = "export"  // sending notes to another service (Readwise, Notion)
+	PurposeSync    = "sync"    // mirroring bookmarks with Raindrop.io or Pinboard
+)
+
+// DefaultSize is the number of requests kept by Default.
+const DefaultSize = 500
+
+// Default is the log that Transport records into.
+var Default = NewLog(DefaultSize)
+
+// Request describes one outbound HTTP request.
+type Request struct {
+	Time       time.Time `json:"time"`
+	Purpose    string    `json:"purpose"`
+	Method     string    `json:"method"`
+	Host       string    `json:"host"`
+	Path       string    `json:"path"`
+	Status     int       `json:"status,omitempty"` // 0 if no response was received
+	Bytes      int64     `json:"bytes"`            // response body bytes read
+	DurationMS int64     `json:"duration_ms"`
+	Error      string    `json:"error,omitempty"`
+}
+
+// Log is a ring buffer of the most recent outbound requests. It is safe for
+// concurrent use.
+type Log struct {
+	mu      sync.Mutex
+	entries []Request
+	next    int  // index of the slot to write next
+	full    bool // whether entries has wrapped around
+}
+
+// NewLog returns a Log that keeps the last size requests.
+func NewLog(size int) *Log {
+	return &Log{entries: make([]Request, max(size, 1))}
+}
+
+// Add records req, evicting the oldest request if the log is full.
+func (l *Log) Add(req Request) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.entries[l.next] = req
+	l.next = (l.next + 1) % len(l.entries)
+	if l.next == 0 {
+		l.full = true
+	}
+}
+
+// Requests returns the recorded requests, newest first.
+func (l *Log) Requests() []Request {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	n := l.next
+	if l.full {
+		n = len(l.entries)
+	}
+	out := make([]Request, 0, n)
+	for i := range n {
+		out = append(out, l.entries[(l.next-1-i+len(l.entries))%len(l.entries)])
+	}
+	return out
+}
+
+type purposeKey struct{}
+
+// WithPurpose returns a context whose outbound requests are recorded with
+// purpose, overriding the purpose of the Transport that sends them. It lets
+// one client serve several purposes, such as feed fetching and extraction.
+func WithPurpose(ctx context.Context, purpose string) context.Context {
+	return context.WithValue(ctx, purposeKey{}, purpose)
+}
+
+// Transport wraps an http.RoundTripper to record each request in Default.
+// Purpose is used for requests whose context carries none.
+type Transport struct {
+	Base    http.RoundTripper // http.DefaultTransport if nil
+	Purpose string
+}
+
+// RoundTrip sends req and records it once its response body is closed or
+// fully read.
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	purpose := t.Purpose
+	if p, ok := req.Context().Value(purposeKey{}).(string); ok {
+		purpose = p
+	}
+
+	entry := Request{
+		Time:    time.Now(),
+		Purpose: purpose,
+		Method:  req.Method,
+		Host:    req.URL.Host,
+		Path:    req.URL.Path,
+	}
+
+	resp, err := base.RoundTrip(req)
+	if err != nil {
+		entry.DurationMS = time.Since(entry.Time).Milliseconds()
+		entry.Error = err.Error()
+		Default.Add(entry)
+		return nil, err
+	}
+
+	entry.Status = resp.StatusCode
+	resp.Body = &countingBody{ReadCloser: resp.Body, entry: entry}
+	return resp, nil
+}
+
+// countingBody counts the bytes read from a response body and records its
+// request when the body is exhausted or closed, whichever comes first.
+type countingBody struct {
+	io.ReadCloser
+	entry    Request
+	n        int64
+	recorded atomic.Bool
+}
+
+func (b *countingBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.n += int64(n)
+	if err == io.EOF {
+		b.record(nil)
+	} else if err != nil {
+		b.record(err)
+	}
+	return n, err
+}
+
+func (b *countingBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.record(nil)
+	return err
+}
+
+func (b *countingBody) record(err error) {
+	if b.recorded.Swap(true) {
+		return
+	}
+	b.entry.Bytes = b.n
+	b.entry.DurationMS = time.Since(b.entry.Time).Milliseconds()
+	if err != nil {
+		b.entry.Error = err.Error()
+	}
+	Default.Add(b.entry)
+}
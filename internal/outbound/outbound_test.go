@@ -0,0 +1,77 @@
+package outbound
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestLog_KeepsMostRecent(t *testing.T) {
+	l := NewLog(3)
+	for _, host := range []string{"a", "b", "c", "d", "e"} {
+		l.Add(Request{Host: host})
+	}
+
+	got := l.Requests()
+	want := []string{"e", "d", "c"}
+	if len(got) != len(want) {
+		t.Fatalf("got %d requests, want %d", len(got), len(want))
+	}
+	for i, host := range want {
+		if got[i].Host != host {
+			t.Errorf("Requests()[%d].Host = %q, want %q", i, got[i].Host, host)
+		}
+	}
+}
+
+func TestTransport_RecordsRequest(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+		_, _ = io.WriteString(w, "hello")
+	}))
+	defer srv.Close()
+
+	orig := Default
+	Default = NewLog(10)
+	t.Cleanup(func() { Default = orig })
+
+	client := &http.Client{Transport: &Transport{Purpose: PurposeFeed}}
+	ctx := WithPurpose(context.Background(), PurposeExtract)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL+"/post?token=secret", nil)
+	if err != nil {
+		t.Fatalf("NewRequest: %v", err)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatalf("Do: %v", err)
+	}
+	_, _ = io.ReadAll(resp.Body)
+	resp.Body.Close()
+
+	got := Default.Requests()
+	if len(got) != 1 {
+		t.Fatalf("recorded %d requests, want 1", len(got))
+	}
+	r := got[0]
+	if r.Purpose != PurposeExtract || r.Path != "/post" || r.Status != http.StatusTeapot || r.Bytes != 5 {
+		t.Errorf("recorded %+v, want purpose extract, path /post, status 418, 5 bytes", r)
+	}
+}
+
+func TestTransport_RecordsFailure(t *testing.T) {
+	orig := Default
+	Default = NewLog(10)
+	t.Cleanup(func() { Default = orig })
+
+	client := &http.Client{Transport: &Transport{Purpose: PurposeAI}}
+	if _, err := client.Get("http://127.0.0.1:0/"); err == nil {
+		t.Fatal("expected a connection error")
+	}
+
+	got := Default.Requests()
+	if len(got) != 1 || got[0].Purpose != PurposeAI || got[0].Error == "" {
+		t.Errorf("recorded %+v, want one failed ai request", got)
+	}
+}
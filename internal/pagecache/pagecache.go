@@ -0,0 +1,241 @@
+// Package pagecache keeps fetched web pages on disk so the reader's page
+// proxy can serve an article again without downloading it again.
+//
+// Each page is one file, named by a hash of its URL, holding a JSON header
+// line followed by the body. Pages are kept for as long as the upstream
+// Cache-Control or Expires headers allow (see Expiry), and the least
+// recently used pages are deleted once the cache outgrows its size limit.
+package pagecache
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultTTL is how long a page whose response says nothing about caching
+// is kept.
+const DefaultTTL = time.Hour
+
+// Page is a cached response.
+type Page struct {
+	URL         string    `json:"url"`
+	Location    string    `json:"location,omitempty"` // where URL redirected to, if anywhere
+	ContentType string    `json:"content_type"`
+	Expires     time.Time `json:"expires"`
+	Body        []byte    `json:"-"`
+}
+
+// Cache is a size-bounded on-disk page cache. It is safe for concurrent use.
+type Cache struct {
+	dir      string
+	maxBytes int64
+	now      func() time.Time
+
+	mu      sync.Mutex
+	entries map[string]*entry // by file name
+	size    int64
+}
+
+// entry is what the cache remembers in memory about a file.
+type entry struct {
+	size int64
+	used time.Time
+}
+
+// Open opens the cache in dir, creating the directory if needed, and limits
+// it to maxBytes.
+func Open(dir string, maxBytes int64) (*Cache, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("creating page cache directory: %w", err)
+	}
+	c := &Cache{dir: dir, maxBytes: maxBytes, now: time.Now, entries: make(map[string]*entry)}
+
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("reading page cache directory: %w", err)
+	}
+	for _, f := range files {
+		if f.IsDir() || strings.HasPrefix(f.Name(), ".") {
+			continue
+		}
+		info, err := f.Info()
+		if err != nil {
+			continue
+		}
+		// A file's modification time records when it was last used.
+		c.entries[f.Name()] = &entry{size: info.Size(), used: info.ModTime()}
+		c.size += info.Size()
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.evict()
+	return c, nil
+}
+
+// Get returns the cached page for url, if there is one that has not
+// expired.
+func (c *Cache) Get(url string) (*Page, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	name := fileName(url)
+	if _, ok := c.entries[name]; !ok {
+		return nil, false
+	}
+	page, err := c.read(name)
+	if err != nil || page.URL != url || !c.now().Before(page.Expires) {
+		c.remove(name)
+		return nil, false
+	}
+
+	now := c.now()
+	c.entries[name].used = now
+	_ = os.Chtimes(filepath.Join(c.dir, name), now, now)
+	return page, true
+}
+
+// Put stores page under page.URL, replacing any earlier copy, and deletes
+// the least recently used pages if the cache is now over its limit. Pages
+// larger than the whole cache are not stored.
+func (c *Cache) Put(page *Page) error {
+	header, err := json.Marshal(page)
+	if err != nil {
+		return err
+	}
+	size := int64(len(header) + 1 + len(page.Body))
+	if size > c.maxBytes {
+		return nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	name := fileName(page.URL)
+	tmp, err := os.CreateTemp(c.dir, ".page-*")
+	if err != nil {
+		return fmt.Errorf("creating cached page: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	w := bufio.NewWriter(tmp)
+	w.Write(header)
+	w.WriteByte('\n')
+	w.Write(page.Body)
+	if err := w.Flush(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("writing cached page: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("writing cached page: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), filepath.Join(c.dir, name)); err != nil {
+		return fmt.Errorf("saving cached page: %w", err)
+	}
+
+	if old, ok := c.entries[name]; ok {
+		c.size -= old.size
+	}
+	c.entries[name] = &entry{size: size, used: c.now()}
+	c.size += size
+	c.evict()
+	return nil
+}
+
+// read loads the page in file name. c.mu must be held.
+func (c *Cache) read(name string) (*Page, error) {
+	data, err := os.ReadFile(filepath.Join(c.dir, name))
+	if err != nil {
+		return nil, err
+	}
+	header, body, ok := bytes.Cut(data, []byte("\n"))
+	if !ok {
+		return nil, errors.New("cached page has no header")
+	}
+	var page Page
+	if err := json.Unmarshal(header, &page); err != nil {
+		return nil, fmt.Errorf("reading cached page header: %w", err)
+	}
+	page.Body = body
+	return &page, nil
+}
+
+// remove deletes file name from the cache. c.mu must be held.
+func (c *Cache) remove(name string) {
+	if e, ok := c.entries[name]; ok {
+		c.size -= e.size
+		delete(c.entries, name)
+	}
+	_ = os.Remove(filepath.Join(c.dir, name))
+}
+
+// evict deletes the least recently used pages until the cache fits in its
+// limit. c.mu must be held.
+func (c *Cache) evict() {
+	if c.size <= c.maxBytes {
+		return
+	}
+	names := make([]string, 0, len(c.entries))
+	for name := range c.entries {
+		names = append(names, name)
+	}
+	slices.SortFunc(names, func(a, b string) int {
+		return c.entries[a].used.Compare(c.entries[b].used)
+	})
+	for _, name := range names {
+		if c.size <= c.maxBytes {
+			break
+		}
+		c.remove(name)
+	}
+}
+
+// fileName returns the name of the file caching url.
+func fileName(url string) string {
+	sum := sha256.Sum256([]byte(url))
+	return hex.EncodeToString(sum[:16])
+}
+
+// Expiry returns when a response with header h, received at now, stops
+// being fresh, following its Cache-Control max-age or else its Expires
+// header, and DefaultTTL if it has neither. It returns false if the
+// response must not be cached: no-store, no-cache, or already stale.
+func Expiry(h http.Header, now time.Time) (time.Time, bool) {
+	maxAge := ""
+	for _, directive := range strings.Split(h.Get("Cache-Control"), ",") {
+		name, value, _ := strings.Cut(strings.TrimSpace(directive), "=")
+		switch strings.ToLower(name) {
+		case "no-store", "no-cache":
+			return time.Time{}, false
+		case "max-age":
+			maxAge = strings.Trim(value, `"`)
+		}
+	}
+	if maxAge != "" {
+		secs, err := strconv.Atoi(maxAge)
+		if err != nil || secs <= 0 {
+			return time.Time{}, false
+		}
+		return now.Add(time.Duration(secs) * time.Second), true
+	}
+	if v := h.Get("Expires"); v != "" {
+		t, err := http.ParseTime(v)
+		if err != nil || !t.After(now) {
+			return time.Time{}, false
+		}
+		return t, true
+	}
+	return now.Add(DefaultTTL), true
+}
@@ -0,0 +1,109 @@
+package pagecache
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestCache_PutGet(t *testing.T) {
+	dir := t.TempDir()
+	c, err := Open(dir, 1<<20)
+	if err != nil {
+		t.Fatalf("Open() error: %v", err)
+	}
+	now := time.Now()
+	c.now = func() time.Time { return now }
+
+	page := &Page{URL: "https://example.com/post", ContentType: "text/html", Expires: now.Add(time.Minute), Body: []byte("<html>hi</html>")}
+	if err := c.Put(page); err != nil {
+		t.Fatalf("Put() error: %v", err)
+	}
+
+	got, ok := c.Get(page.URL)
+	if !ok {
+		t.Fatal("Get() missed a page just stored")
+	}
+	if string(got.Body) != "<html>hi</html>" || got.ContentType != "text/html" {
+		t.Errorf("Get() = %q (%s), want the stored page", got.Body, got.ContentType)
+	}
+	if _, ok := c.Get("https://example.com/other"); ok {
+		t.Error("Get() hit for a URL never stored")
+	}
+
+	// A reopened cache still has the page.
+	reopened, err := Open(dir, 1<<20)
+	if err != nil {
+		t.Fatalf("Open() again error: %v", err)
+	}
+	if _, ok := reopened.Get(page.URL); !ok {
+		t.Error("Get() after reopening missed the stored page")
+	}
+
+	now = now.Add(2 * time.Minute)
+	if _, ok := c.Get(page.URL); ok {
+		t.Error("Get() returned an expired page")
+	}
+}
+
+func TestCache_EvictsLeastRecentlyUsed(t *testing.T) {
+	c, err := Open(t.TempDir(), 1000)
+	if err != nil {
+		t.Fatalf("Open() error: %v", err)
+	}
+	now := time.Now()
+	c.now = func() time.Time { return now }
+
+	put := func(url string) {
+		t.Helper()
+		now = now.Add(time.Second)
+		err := c.Put(&Page{URL: url, Expires: now.Add(time.Hour), Body: make([]byte, 300)})
+		if err != nil {
+			t.Fatalf("Put(%s) error: %v", url, err)
+		}
+	}
+	put("https://example.com/a")
+	put("https://example.com/b")
+	now = now.Add(time.Second)
+	c.Get("https://example.com/a") // a is now used more recently than b
+	put("https://example.com/c")
+
+	if _, ok := c.Get("https://example.com/b"); ok {
+		t.Error("the least recently used page was kept")
+	}
+	for _, url := range []string{"https://example.com/a", "https://example.com/c"} {
+		if _, ok := c.Get(url); !ok {
+			t.Errorf("Get(%s) missed; only the least recently used page should go", url)
+		}
+	}
+	if c.size > c.maxBytes {
+		t.Errorf("cache holds %d bytes, over its %d limit", c.size, c.maxBytes)
+	}
+}
+
+func TestExpiry(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name   string
+		header http.Header
+		want   time.Time
+		wantOK bool
+	}{
+		{"no headers", http.Header{}, now.Add(DefaultTTL), true},
+		{"max-age", http.Header{"Cache-Control": {"public, max-age=600"}}, now.Add(10 * time.Minute), true},
+		{"max-age zero", http.Header{"Cache-Control": {"max-age=0"}}, time.Time{}, false},
+		{"no-store", http.Header{"Cache-Control": {"max-age=600, no-store"}}, time.Time{}, false},
+		{"no-cache", http.Header{"Cache-Control": {"no-cache"}}, time.Time{}, false},
+		{"expires", http.Header{"Expires": {now.Add(time.Hour * 3).Format(http.TimeFormat)}}, now.Add(3 * time.Hour), true},
+		{"expired", http.Header{"Expires": {now.Add(-time.Hour).Format(http.TimeFormat)}}, time.Time{}, false},
+		{"max-age beats expires", http.Header{"Cache-Control": {"max-age=60"}, "Expires": {"0"}}, now.Add(time.Minute), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := Expiry(tt.header, now)
+			if ok != tt.wantOK || !got.Equal(tt.want) {
+				t.Errorf("Expiry() = %v, %v; want %v, %v", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
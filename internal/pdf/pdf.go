@@ -0,0 +1,170 @@
+// Package pdf renders a reading list item's reader view, the article with
+// its summary and notes, as a PDF for archiving. It uses the PDF core fonts,
+// so text is limited to the Windows-1252 character set; other characters
+// print as "?".
+package pdf
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/go-pdf/fpdf"
+)
+
+// Document is the reader view of an article.
+type Document struct {
+	Title       string
+	Source      string
+	URL         string
+	PublishedAt *time.Time
+	Tags        []string
+
+	Summary string
+	Notes   string // Markdown
+	Text    string // the extracted article, paragraphs on separate lines
+}
+
+// Layout, in millimeters and points.
+const (
+	margin     = 20.0
+	lineHeight = 5.5
+	bodySize   = 11.0
+	indent     = 8.0
+)
+
+// Write writes doc to w as an A4 PDF. created is the document's creation
+// date, which document management systems file it under.
+func Write(w io.Writer, doc Document, created time.Time) error {
+	f := fpdf.New("P", "mm", "A4", "")
+	tr := translator(f)
+
+	f.SetMargins(margin, margin, margin)
+	f.SetAutoPageBreak(true, margin)
+	f.SetTitle(doc.Title, true)
+	f.SetAuthor(doc.Source, true)
+	f.SetSubject(doc.URL, true)
+	f.SetKeywords(strings.Join(doc.Tags, ", "), true)
+	f.SetCreator("Apricot", true)
+	f.SetCreationDate(created)
+	f.SetModificationDate(created)
+	f.AliasNbPages("")
+	f.SetFooterFunc(func() {
+		f.SetY(-margin + 5)
+		f.SetFont("Helvetica", "", 8)
+		f.SetTextColor(128, 128, 128)
+		f.CellFormat(0, 5, fmt.Sprintf("%d / {nb}", f.PageNo()), "", 0, "C", false, 0, "")
+	})
+	f.AddPage()
+
+	f.SetFont("Helvetica", "B", 18)
+	f.MultiCell(0, 8, tr(doc.Title), "", "L", false)
+	f.Ln(1)
+
+	var byline []string
+	if doc.Source != "" {
+		byline = append(byline, doc.Source)
+	}
+	if doc.PublishedAt != nil {
+		byline = append(byline, doc.PublishedAt.Format("January 2, 2006"))
+	}
+	if len(doc.Tags) > 0 {
+		byline = append(byline, strings.Join(doc.Tags, ", "))
+	}
+	f.SetFont("Helvetica", "I", 10)
+	f.SetTextColor(96, 96, 96)
+	if len(byline) > 0 {
+		f.MultiCell(0, lineHeight, tr(strings.Join(byline, " · ")), "", "L", false)
+	}
+	if doc.URL != "" {
+		f.SetFont("Helvetica", "", 9)
+		f.SetTextColor(37, 99, 235)
+		f.WriteLinkString(lineHeight, tr(doc.URL), doc.URL)
+		f.Ln(lineHeight)
+	}
+	f.SetTextColor(0, 0, 0)
+
+	if summary := strings.TrimSpace(doc.Summary); summary != "" {
+		heading(f, "Summary")
+		paragraphs(f, tr, summary, "")
+	}
+	if notes := strings.TrimSpace(doc.Notes); notes != "" {
+		heading(f, "My notes")
+		markdown(f, tr, notes)
+	}
+	if text := strings.TrimSpace(doc.Text); text != "" {
+		heading(f, "Article")
+		paragraphs(f, tr, text, "")
+	}
+
+	return f.Output(w)
+}
+
+// heading starts a section titled title.
+func heading(f *fpdf.Fpdf, title string) {
+	f.Ln(4)
+	f.SetFont("Helvetica", "B", 13)
+	f.CellFormat(0, 8, title, "B", 1, "L", false, 0, "")
+	f.Ln(2)
+}
+
+// paragraphs writes each non-blank line of text as a paragraph in style.
+func paragraphs(f *fpdf.Fpdf, tr func(string) string, text, style string) {
+	f.SetFont("Helvetica", style, bodySize)
+	for _, line := range strings.Split(text, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			f.MultiCell(0, lineHeight, tr(line), "", "L", false)
+			f.Ln(2)
+		}
+	}
+}
+
+// markdown writes notes, keeping the shape of their headings, quotes, and
+// list items; other Markdown is printed as written.
+func markdown(f *fpdf.Fpdf, tr func(string) string, notes string) {
+	left, _, _, _ := f.GetMargins()
+	for _, line := range strings.Split(notes, "\n") {
+		line = strings.TrimSpace(line)
+		x := left
+		switch {
+		case line == "":
+			f.Ln(2)
+			continue
+		case strings.HasPrefix(line, "#"):
+			f.SetFont("Helvetica", "B", bodySize)
+			line = strings.TrimSpace(strings.TrimLeft(line, "#"))
+		case strings.HasPrefix(line, ">"):
+			f.SetFont("Helvetica", "I", bodySize)
+			x = left + indent
+			line = strings.TrimSpace(strings.TrimLeft(line, ">"))
+		case strings.HasPrefix(line, "- "), strings.HasPrefix(line, "* "):
+			f.SetFont("Helvetica", "", bodySize)
+			line = "• " + strings.TrimSpace(line[2:])
+		default:
+			f.SetFont("Helvetica", "", bodySize)
+		}
+		// MultiCell wraps lines back to the left margin.
+		f.SetLeftMargin(x)
+		f.SetX(x)
+		f.MultiCell(0, lineHeight, tr(line), "", "L", false)
+		f.SetLeftMargin(left)
+	}
+}
+
+// translator returns a function converting UTF-8 text to the Windows-1252
+// encoding of the core fonts, with "?" for characters it lacks.
+func translator(f *fpdf.Fpdf) func(string) string {
+	cp1252 := f.UnicodeTranslatorFromDescriptor("")
+	return func(s string) string {
+		var b strings.Builder
+		for _, r := range s {
+			c := cp1252(string(r))
+			if c == "." && r != '.' {
+				c = "?"
+			}
+			b.WriteString(c)
+		}
+		return b.String()
+	}
+}
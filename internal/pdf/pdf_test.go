@@ -0,0 +1,68 @@
+package pdf
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-pdf/fpdf"
+)
+
+func TestWrite(t *testing.T) {
+	published := time.Date(2026, 10, 12, 0, 0, 0, 0, time.UTC)
+	doc := Document{
+		Title:       "Caching “in depth” – part 1",
+		Source:      "A Blog",
+		URL:         "https://a.example/cache",
+		PublishedAt: &published,
+		Tags:        []string{"go", "performance"},
+		Summary:     "Caches trade memory for time.",
+		Notes:       "# Takeaways\n\n- Measure first\n> Cache invalidation is hard\n\nPlain note.",
+		Text:        strings.Repeat("A paragraph long enough to wrap over a couple of lines on an A4 page.\n\n", 120),
+	}
+
+	var buf bytes.Buffer
+	if err := Write(&buf, doc, time.Date(2026, 10, 16, 9, 0, 0, 0, time.UTC)); err != nil {
+		t.Fatalf("Write() error: %v", err)
+	}
+	out := buf.String()
+	if !strings.HasPrefix(out, "%PDF-") || !strings.HasSuffix(strings.TrimSpace(out), "%%EOF") {
+		t.Fatalf("output is not a PDF: %q...", out[:min(len(out), 20)])
+	}
+	for _, want := range []string{"/Creator", "/CreationDate (D:20261016", "/URI (https://a.example/cache)"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("PDF is missing %q", want)
+		}
+	}
+	if m := pageCount.FindStringSubmatch(out); m == nil || m[1] == "1" {
+		t.Errorf("page count = %v, want a long article on several pages", m)
+	}
+}
+
+var pageCount = regexp.MustCompile(`/Count (\d+)\n`)
+
+func TestWrite_Empty(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Write(&buf, Document{Title: "Untitled"}, time.Now()); err != nil {
+		t.Fatalf("Write() error: %v", err)
+	}
+	if !strings.HasPrefix(buf.String(), "%PDF-") {
+		t.Error("output is not a PDF")
+	}
+}
+
+func TestTranslator(t *testing.T) {
+	tr := translator(fpdf.New("P", "mm", "A4", ""))
+	for in, want := range map[string]string{
+		"plain. text":     "plain. text",
+		"café":            "caf\xe9",
+		"“quoted” – dash": "\x93quoted\x94 \x96 dash",
+		"日本 ok":           "?? ok",
+	} {
+		if got := tr(in); got != want {
+			t.Errorf("tr(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
@@ -0,0 +1,227 @@
+// Package readwise sends highlights to Readwise
+// (https://readwise.io/api_deets), so notes taken in Apricot show up in
+// Readwise's daily review alongside highlights from other apps.
+package readwise
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/hoanghai1803/apricot/internal/outbound"
+)
+
+// DefaultAPI is the Readwise API's base URL.
+const DefaultAPI = "https://readwise.io/api/v2"
+
+// batchSize is how many highlights Push sends in one request.
+const batchSize = 100
+
+// Limits on field lengths, from the Readwise API documentation.
+const (
+	maxText  = 8191
+	maxTitle = 511
+	maxNote  = 8191
+)
+
+// ErrUnauthorized is returned when Readwise refuses the access token.
+var ErrUnauthorized = errors.New("readwise rejected the access token")
+
+// Highlight is a highlight to create. Readwise groups highlights into
+// books by Title, Author, and SourceURL, and skips one it already has with
+// the same text in the same book, so pushing a highlight twice is harmless.
+type Highlight struct {
+	Text          string     `json:"text"`
+	Title         string     `json:"title,omitempty"`
+	Author        string     `json:"author,omitempty"`
+	SourceURL     string     `json:"source_url,omitempty"`
+	SourceType    string     `json:"source_type,omitempty"`
+	Category      string     `json:"category,omitempty"`
+	Note          string     `json:"note,omitempty"`
+	HighlightedAt *time.Time `json:"highlighted_at,omitempty"`
+	HighlightURL  string     `json:"highlight_url,omitempty"`
+}
+
+// Client talks to the Readwise API with an access token from
+// https://readwise.io/access_token.
+type Client struct {
+	Token string
+
+	// API is the API's base URL; empty means DefaultAPI.
+	API string
+
+	// HTTP makes the requests; nil means a client with a one-minute
+	// timeout whose requests are logged as exports.
+	HTTP *http.Client
+}
+
+// Push creates highlights, in batches, and returns how many it sent.
+// Texts, titles, and notes over Readwise's limits are cut short.
+func (c *Client) Push(ctx context.Context, highlights []Highlight) (int, error) {
+	sent := 0
+	for start := 0; start < len(highlights); start += batchSize {
+		batch := make([]Highlight, 0, batchSize)
+		for _, h := range highlights[start:min(start+batchSize, len(highlights))] {
+			h.Text = truncate(h.Text, maxText)
+			h.Title = truncate(h.Title, maxTitle)
+			h.Note = truncate(h.Note, maxNote)
+			batch = append(batch, h)
+		}
+		body, err := json.Marshal(map[string]any{"highlights": batch})
+		if err != nil {
+			return sent, fmt.Errorf("encoding highlights: %w", err)
+		}
+		if err := c.do(ctx, http.MethodPost, "/highlights/", body); err != nil {
+			return sent, err
+		}
+		sent += len(batch)
+	}
+	return sent, nil
+}
+
+// CheckToken reports whether Readwise accepts the access token.
+func (c *Client) CheckToken(ctx context.Context) error {
+	return c.do(ctx, http.MethodGet, "/auth/", nil)
+}
+
+// do sends a request to the API and checks its status.
+func (c *Client) do(ctx context.Context, method, path string, body []byte) error {
+	api := c.API
+	if api == "" {
+		api = DefaultAPI
+	}
+	client := c.HTTP
+	if client == nil {
+		client = &http.Client{
+			Timeout:   time.Minute,
+			Transport: &outbound.Transport{Purpose: outbound.PurposeExport},
+		}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, strings.TrimRight(api, "/")+path, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Token "+c.Token)
+	req.Header.Set("User-Agent", "Apricot")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("calling Readwise: %w", err)
+	}
+	defer resp.Body.Close()
+	msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+
+	switch {
+	case resp.StatusCode < 300:
+		return nil
+	case resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden:
+		return ErrUnauthorized
+	case resp.StatusCode == http.StatusTooManyRequests:
+		return fmt.Errorf("readwise rate limit reached; retry after %s seconds", resp.Header.Get("Retry-After"))
+	default:
+		return &StatusError{Code: resp.StatusCode, Body: strings.TrimSpace(string(msg))}
+	}
+}
+
+// StatusError is an unexpected HTTP status from Readwise.
+type StatusError struct {
+	Code int
+	Body string
+}
+
+func (e *StatusError) Error() string {
+	if e.Body == "" {
+		return fmt.Sprintf("readwise returned HTTP %d", e.Code)
+	}
+	return fmt.Sprintf("readwise returned HTTP %d: %s", e.Code, e.Body)
+}
+
+// truncate cuts s to at most n runes, ending in an ellipsis if cut.
+func truncate(s string, n int) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+	return string(r[:n-1]) + "…"
+}
+
+// FromNotes splits Markdown notes into highlights, carrying only Text and
+// Note. Each block quote becomes a highlight, with the paragraph right
+// after it, if any, as its note; every other paragraph becomes a highlight
+// of its own. Notes without block quotes thus become one highlight per
+// paragraph.
+func FromNotes(notes string) []Highlight {
+	var (
+		highlights []Highlight
+		lastQuote  = -1 // index of a quote still waiting for its note
+	)
+	for _, para := range paragraphs(notes) {
+		if quote, ok := blockQuote(para); ok {
+			highlights = append(highlights, Highlight{Text: quote})
+			lastQuote = len(highlights) - 1
+			continue
+		}
+		if lastQuote >= 0 {
+			highlights[lastQuote].Note = para
+			lastQuote = -1
+			continue
+		}
+		highlights = append(highlights, Highlight{Text: para})
+	}
+	return highlights
+}
+
+// paragraphs splits text at blank lines, and where a block quote starts or
+// ends, dropping empty paragraphs.
+func paragraphs(text string) []string {
+	var (
+		paras   []string
+		cur     []string
+		inQuote bool
+	)
+	flush := func() {
+		if p := strings.TrimSpace(strings.Join(cur, "\n")); p != "" {
+			paras = append(paras, p)
+		}
+		cur = nil
+	}
+	for _, line := range strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n") {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" {
+			flush()
+			continue
+		}
+		quote := strings.HasPrefix(trimmed, ">")
+		if len(cur) > 0 && quote != inQuote {
+			flush()
+		}
+		inQuote = quote
+		cur = append(cur, line)
+	}
+	flush()
+	return paras
+}
+
+// blockQuote returns the text of para without its quote markers, if it is
+// a block quote.
+func blockQuote(para string) (string, bool) {
+	if !strings.HasPrefix(para, ">") {
+		return "", false
+	}
+	lines := strings.Split(para, "\n")
+	for i, line := range lines {
+		line = strings.TrimPrefix(strings.TrimSpace(line), ">")
+		lines[i] = strings.TrimPrefix(line, " ")
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n")), true
+}
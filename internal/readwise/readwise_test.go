@@ -0,0 +1,81 @@
+package readwise
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestFromNotes(t *testing.T) {
+	notes := "Worth rereading.\n\n> The cache is the database.\n> Everything else is a view.\n\nWhich is why invalidation hurts.\n\n> A quote with no comment.\n\n> Another one.\r\n\r\nA last thought.\nOver two lines."
+
+	got := FromNotes(notes)
+	want := []Highlight{
+		{Text: "Worth rereading."},
+		{Text: "The cache is the database.\nEverything else is a view.", Note: "Which is why invalidation hurts."},
+		{Text: "A quote with no comment."},
+		{Text: "Another one.", Note: "A last thought.\nOver two lines."},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %d highlights, want %d: %+v", len(got), len(want), got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("highlight %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+
+	if got := FromNotes("  \n\n "); len(got) != 0 {
+		t.Errorf("blank notes gave %d highlights, want none", len(got))
+	}
+}
+
+func TestPush(t *testing.T) {
+	var batches []int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Token secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if r.Method != http.MethodPost || r.URL.Path != "/highlights/" {
+			t.Errorf("request %s %s, want POST /highlights/", r.Method, r.URL.Path)
+		}
+		var body struct {
+			Highlights []Highlight `json:"highlights"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("decoding body: %v", err)
+		}
+		if n := len([]rune(body.Highlights[0].Title)); n > maxTitle {
+			t.Errorf("title of %d runes sent, want at most %d", n, maxTitle)
+		}
+		batches = append(batches, len(body.Highlights))
+	}))
+	defer srv.Close()
+
+	highlights := make([]Highlight, 250)
+	for i := range highlights {
+		highlights[i] = Highlight{Text: "quote", Title: strings.Repeat("t", maxTitle+10)}
+	}
+
+	c := &Client{Token: "secret", API: srv.URL, HTTP: srv.Client()}
+	sent, err := c.Push(context.Background(), highlights)
+	if err != nil {
+		t.Fatalf("Push() error: %v", err)
+	}
+	if sent != 250 || len(batches) != 3 || batches[0] != batchSize || batches[2] != 50 {
+		t.Errorf("sent %d in batches %v, want 250 in batches of %d", sent, batches, batchSize)
+	}
+
+	c.Token = "wrong"
+	if _, err := c.Push(context.Background(), highlights[:1]); !errors.Is(err, ErrUnauthorized) {
+		t.Errorf("Push() with a bad token error = %v, want ErrUnauthorized", err)
+	}
+	if err := c.CheckToken(context.Background()); !errors.Is(err, ErrUnauthorized) {
+		t.Errorf("CheckToken() with a bad token error = %v, want ErrUnauthorized", err)
+	}
+}
@@ -0,0 +1,54 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// GetAIResponse returns the cached AI response stored under the given key.
+// Returns "", ErrNotFound if no matching row exists.
+func (s *sqlStore) GetAIResponse(ctx context.Context, key string) (string, error) {
+	var response string
+	err := s.rdb.QueryRowContext(ctx,
+		`SELECT response FROM ai_response_cache WHERE cache_key = ?`, key,
+	).Scan(&response)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", ErrNotFound
+		}
+		return "", fmt.Errorf("getting cached ai response: %w", err)
+	}
+	return response, nil
+}
+
+// PutAIResponse stores an AI response under the given key. An existing entry
+// with the same key is overwritten.
+func (s *sqlStore) PutAIResponse(ctx context.Context, key, operation, model, response string) error {
+	_, err := s.db.ExecContext(ctx,
+		`INSERT INTO ai_response_cache (cache_key, operation, model, response)
+		 VALUES (?, ?, ?, ?)
+		 ON CONFLICT(cache_key) DO UPDATE SET
+			response   = excluded.response,
+			created_at = datetime('now')`,
+		key, operation, model, response,
+	)
+	if err != nil {
+		return fmt.Errorf("caching ai response: %w", err)
+	}
+	return nil
+}
+
+// PruneAIResponses deletes cached AI responses stored before cutoff and
+// returns how many were deleted.
+func (s *sqlStore) PruneAIResponses(ctx context.Context, cutoff time.Time) (int64, error) {
+	res, err := s.db.ExecContext(ctx,
+		`DELETE FROM ai_response_cache WHERE created_at < ?`,
+		cutoff.UTC().Format("2006-01-02 15:04:05"))
+	if err != nil {
+		return 0, fmt.Errorf("pruning cached ai responses: %w", err)
+	}
+	return res.RowsAffected()
+}
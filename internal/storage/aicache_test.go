@@ -0,0 +1,77 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestAIResponseCache_PutAndGet(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+
+	if err := store.PutAIResponse(ctx, "key-1", "summarize", "claude-haiku-4-5", "A summary."); err != nil {
+		t.Fatalf("PutAIResponse() error: %v", err)
+	}
+
+	got, err := store.GetAIResponse(ctx, "key-1")
+	if err != nil {
+		t.Fatalf("GetAIResponse() error: %v", err)
+	}
+	if got != "A summary." {
+		t.Errorf("GetAIResponse() = %q, want %q", got, "A summary.")
+	}
+}
+
+func TestAIResponseCache_Overwrites(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+
+	if err := store.PutAIResponse(ctx, "key-1", "summarize", "m", "old"); err != nil {
+		t.Fatalf("first PutAIResponse() error: %v", err)
+	}
+	if err := store.PutAIResponse(ctx, "key-1", "summarize", "m", "new"); err != nil {
+		t.Fatalf("second PutAIResponse() error: %v", err)
+	}
+
+	got, err := store.GetAIResponse(ctx, "key-1")
+	if err != nil {
+		t.Fatalf("GetAIResponse() error: %v", err)
+	}
+	if got != "new" {
+		t.Errorf("GetAIResponse() = %q, want %q", got, "new")
+	}
+}
+
+func TestAIResponseCache_NotFound(t *testing.T) {
+	store := newTestStore(t)
+
+	_, err := store.GetAIResponse(context.Background(), "missing")
+	if !errors.Is(err, ErrNotFound) {
+		t.Errorf("GetAIResponse() error = %v, want ErrNotFound", err)
+	}
+}
+
+func TestPruneAIResponses(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+
+	if err := store.PutAIResponse(ctx, "key-1", "summarize", "m", "A summary."); err != nil {
+		t.Fatalf("PutAIResponse() error: %v", err)
+	}
+
+	if n, err := store.PruneAIResponses(ctx, time.Now().Add(-time.Hour)); err != nil || n != 0 {
+		t.Fatalf("PruneAIResponses() before it was stored = %d, %v; want 0", n, err)
+	}
+	n, err := store.PruneAIResponses(ctx, time.Now().Add(time.Hour))
+	if err != nil {
+		t.Fatalf("PruneAIResponses() error: %v", err)
+	}
+	if n != 1 {
+		t.Errorf("PruneAIResponses() = %d, want 1", n)
+	}
+	if _, err := store.GetAIResponse(ctx, "key-1"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("GetAIResponse() after pruning error = %v, want ErrNotFound", err)
+	}
+}
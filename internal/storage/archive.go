@@ -0,0 +1,641 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"iter"
+	"strings"
+	"time"
+
+	"github.com/hoanghai1803/apricot/internal/models"
+)
+
+// customFeedURL is the feed URL of the sentinel source of user-added posts.
+const customFeedURL = "custom://user-added"
+
+// SchemaVersion returns the version of the most recent applied migration.
+func (s *sqlStore) SchemaVersion(ctx context.Context) (int, error) {
+	var v int
+	if err := s.rdb.QueryRowContext(ctx,
+		`SELECT COALESCE(MAX(version), 0) FROM schema_migrations`).Scan(&v); err != nil {
+		return 0, fmt.Errorf("getting schema version: %w", err)
+	}
+	return v, nil
+}
+
+// ExportAll reads everything an export archive holds: the sources, the
+// fetched posts with their summaries, the reading list (with notes and tags),
+// tag metadata, and preferences. It reads them in one read-only transaction,
+// so the archive is a consistent snapshot, and hands them to write as a
+// stream: posts and reading list items are scanned as write consumes them,
+// so memory use does not grow with the database. The stream is only valid
+// until write returns.
+func (s *sqlStore) ExportAll(ctx context.Context, write func(*models.ArchiveStream) error) error {
+	tx, err := s.rdb.BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		return fmt.Errorf("beginning transaction: %w", err)
+	}
+	defer tx.Rollback() //nolint:errcheck // read-only, nothing to commit
+
+	prefs, err := exportPreferences(ctx, tx)
+	if err != nil {
+		return err
+	}
+	return write(&models.ArchiveStream{
+		Sources:     exportSources(ctx, tx),
+		Blogs:       exportBlogs(ctx, tx),
+		ReadingList: exportReadingList(ctx, tx),
+		Tags:        exportTags(ctx, tx),
+		Preferences: prefs,
+	})
+}
+
+// exportRows returns an iterator over the rows query returns in tx, each
+// scanned into a record by scan. The query runs when the iterator is used,
+// and what names the records in errors.
+func exportRows[T any](ctx context.Context, tx *sql.Tx, what string, scan func(*sql.Rows) (T, error), query string) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		var zero T
+		rows, err := tx.QueryContext(ctx, query)
+		if err != nil {
+			yield(zero, fmt.Errorf("querying %s for export: %w", what, err))
+			return
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			rec, err := scan(rows)
+			if err != nil {
+				yield(zero, fmt.Errorf("scanning %s for export: %w", what, err))
+				return
+			}
+			if !yield(rec, nil) {
+				return
+			}
+		}
+		if err := rows.Err(); err != nil {
+			yield(zero, fmt.Errorf("iterating %s for export: %w", what, err))
+		}
+	}
+}
+
+// exportSources returns the sources, by name, without the custom source.
+func exportSources(ctx context.Context, tx *sql.Tx) iter.Seq2[models.ArchiveSource, error] {
+	return exportRows(ctx, tx, "sources", func(rows *sql.Rows) (models.ArchiveSource, error) {
+		var (
+			src      models.ArchiveSource
+			isActive int
+		)
+		err := rows.Scan(&src.Name, &src.Company, &src.FeedURL, &src.SiteURL, &isActive)
+		src.IsActive = isActive == 1
+		return src, err
+	}, `SELECT name, company, feed_url, site_url, is_active
+		FROM blog_sources WHERE feed_url != '`+customFeedURL+`' ORDER BY name`)
+}
+
+// exportBlogs returns the posts that are not on the reading list, with their
+// summaries and any cold content, oldest first.
+func exportBlogs(ctx context.Context, tx *sql.Tx) iter.Seq2[models.ArchivePost, error] {
+	return exportRows(ctx, tx, "blogs", func(rows *sql.Rows) (models.ArchivePost, error) {
+		var (
+			post                                            models.ArchivePost
+			content                                         []byte
+			description, feedURL, customSource, publishedAt sql.NullString
+			summary, model, difficulty, category            sql.NullString
+		)
+		if err := rows.Scan(&post.URL, &post.Title, &description, &content,
+			&feedURL, &customSource, &publishedAt,
+			&summary, &model, &difficulty, &category); err != nil {
+			return post, err
+		}
+		var err error
+		if post.Content, err = decodeContent(content); err != nil {
+			return post, fmt.Errorf("decoding content of %q: %w", post.URL, err)
+		}
+		if feedURL.String != customFeedURL {
+			post.SourceFeedURL = feedURL.String
+		}
+		post.Description = description.String
+		post.CustomSource = customSource.String
+		post.PublishedAt = parseTimePtr(nullStringToPtr(publishedAt))
+		post.Summary = summary.String
+		post.SummaryModel = model.String
+		post.Difficulty = difficulty.String
+		post.Category = category.String
+		return post, nil
+	}, `SELECT b.url, b.title, b.description, COALESCE(b.full_content, c.content),
+		       bs.feed_url, b.custom_source, b.published_at,
+		       s.summary, s.model_used, s.difficulty, s.category
+		FROM blogs b
+		LEFT JOIN blog_sources bs ON bs.id = b.source_id
+		LEFT JOIN blog_cold_content c ON c.blog_id = b.id
+		LEFT JOIN blog_summaries s ON s.blog_id = b.id
+		WHERE NOT EXISTS (SELECT 1 FROM reading_list rl WHERE rl.blog_id = b.id)
+		ORDER BY b.id`)
+}
+
+// exportTags returns every tag with its display metadata, by name.
+func exportTags(ctx context.Context, tx *sql.Tx) iter.Seq2[models.ArchiveTag, error] {
+	return exportRows(ctx, tx, "tags", func(rows *sql.Rows) (models.ArchiveTag, error) {
+		var (
+			tag                models.ArchiveTag
+			color, description sql.NullString
+		)
+		err := rows.Scan(&tag.Name, &color, &description)
+		tag.Color, tag.Description = color.String, description.String
+		return tag, err
+	}, `SELECT name, color, description FROM tags ORDER BY name`)
+}
+
+// exportReadingList returns every reading list item as an archive item, in
+// the order they were added. The items' tags are loaded when the iterator
+// starts, before the items themselves, since a transaction runs one query
+// at a time on Postgres.
+func exportReadingList(ctx context.Context, tx *sql.Tx) iter.Seq2[models.ArchiveItem, error] {
+	return func(yield func(models.ArchiveItem, error) bool) {
+		tags, err := exportItemTags(ctx, tx)
+		if err != nil {
+			yield(models.ArchiveItem{}, err)
+			return
+		}
+
+		items := exportRows(ctx, tx, "reading list", func(rows *sql.Rows) (models.ArchiveItem, error) {
+			var (
+				item                                            models.ArchiveItem
+				id                                              int64
+				content                                         []byte
+				description, feedURL, customSource, publishedAt sql.NullString
+				summary, model, difficulty, category            sql.NullString
+				notes, readAt, archivedAt, snoozedUntil         sql.NullString
+				addedAt                                         string
+				position                                        sql.NullInt64
+			)
+			if err := rows.Scan(&id, &item.URL, &item.Title, &description, &content,
+				&feedURL, &customSource, &publishedAt,
+				&summary, &model, &difficulty, &category,
+				&item.Status, &item.Progress, &notes, &addedAt, &readAt, &archivedAt, &snoozedUntil, &position); err != nil {
+				return item, err
+			}
+			var err error
+			if item.Content, err = decodeContent(content); err != nil {
+				return item, fmt.Errorf("decoding content of %q: %w", item.URL, err)
+			}
+			if feedURL.String != customFeedURL {
+				item.SourceFeedURL = feedURL.String
+			}
+			item.Description = description.String
+			item.CustomSource = customSource.String
+			item.PublishedAt = parseTimePtr(nullStringToPtr(publishedAt))
+			item.Summary = summary.String
+			item.SummaryModel = model.String
+			item.Difficulty = difficulty.String
+			item.Category = category.String
+			item.Notes = notes.String
+			item.Tags = tags[id]
+			if item.Tags == nil {
+				item.Tags = []string{}
+			}
+			item.AddedAt = parseTime(addedAt)
+			item.ReadAt = parseTimePtr(nullStringToPtr(readAt))
+			item.ArchivedAt = parseTimePtr(nullStringToPtr(archivedAt))
+			item.SnoozedUntil = parseTimePtr(nullStringToPtr(snoozedUntil))
+			if position.Valid {
+				p := int(position.Int64)
+				item.Position = &p
+			}
+			return item, nil
+		}, `SELECT rl.id, b.url, b.title, b.description, COALESCE(b.full_content, c.content),
+			       bs.feed_url, b.custom_source, b.published_at,
+			       s.summary, s.model_used, s.difficulty, s.category,
+			       rl.status, rl.progress, rl.notes, rl.added_at, rl.read_at, rl.archived_at, rl.snoozed_until, rl.position
+			FROM reading_list rl
+			JOIN blogs b ON b.id = rl.blog_id
+			LEFT JOIN blog_sources bs ON bs.id = b.source_id
+			LEFT JOIN blog_cold_content c ON c.blog_id = b.id
+			LEFT JOIN blog_summaries s ON s.blog_id = rl.blog_id
+			ORDER BY rl.added_at, rl.id`)
+		for item, err := range items {
+			if !yield(item, err) {
+				return
+			}
+		}
+	}
+}
+
+// exportItemTags returns the names of the tags on each reading list item,
+// keyed by item ID and sorted by name.
+func exportItemTags(ctx context.Context, tx *sql.Tx) (map[int64][]string, error) {
+	rows, err := tx.QueryContext(ctx,
+		`SELECT rlt.reading_list_id, t.name
+		 FROM reading_list_tags rlt
+		 JOIN tags t ON t.id = rlt.tag_id
+		 ORDER BY t.name`)
+	if err != nil {
+		return nil, fmt.Errorf("querying reading list tags for export: %w", err)
+	}
+	defer rows.Close()
+
+	tags := make(map[int64][]string)
+	for rows.Next() {
+		var (
+			id   int64
+			name string
+		)
+		if err := rows.Scan(&id, &name); err != nil {
+			return nil, fmt.Errorf("scanning reading list tag for export: %w", err)
+		}
+		tags[id] = append(tags[id], name)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating reading list tags for export: %w", err)
+	}
+	return tags, nil
+}
+
+// exportPreferences returns every preference by key.
+func exportPreferences(ctx context.Context, tx *sql.Tx) (map[string]json.RawMessage, error) {
+	rows, err := tx.QueryContext(ctx, `SELECT key, value FROM preferences`)
+	if err != nil {
+		return nil, fmt.Errorf("querying preferences for export: %w", err)
+	}
+	defer rows.Close()
+
+	prefs := make(map[string]json.RawMessage)
+	for rows.Next() {
+		var key, value string
+		if err := rows.Scan(&key, &value); err != nil {
+			return nil, fmt.Errorf("scanning preference for export: %w", err)
+		}
+		prefs[key] = json.RawMessage(value)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating preferences for export: %w", err)
+	}
+	return prefs, nil
+}
+
+// Conflict policies for ImportArchive, for records whose key already exists.
+const (
+	ConflictSkip      = "skip"      // keep the existing record
+	ConflictOverwrite = "overwrite" // replace it with the archived one
+)
+
+// ImportOptions controls how ImportArchive restores an archive.
+type ImportOptions struct {
+	// OnConflict is ConflictSkip or ConflictOverwrite. Empty means skip.
+	OnConflict string
+
+	// DryRun rolls the import back instead of committing it, and lists the
+	// action that would be taken for each record.
+	DryRun bool
+}
+
+// ImportArchive restores an export archive inside a single transaction,
+// merging it into the existing data: sources are matched by feed URL, posts
+// and reading list items by post URL, and tags and preferences by name.
+// Records that already exist are left unchanged, or replaced when
+// opts.OnConflict is ConflictOverwrite. Posts whose source is neither in the
+// archive nor in the database are attached to the custom source.
+func (s *sqlStore) ImportArchive(ctx context.Context, data *models.ArchiveData, opts ImportOptions) (*models.ArchiveImportResult, error) {
+	switch opts.OnConflict {
+	case "":
+		opts.OnConflict = ConflictSkip
+	case ConflictSkip, ConflictOverwrite:
+	default:
+		return nil, fmt.Errorf("invalid conflict policy %q", opts.OnConflict)
+	}
+	overwrite := opts.OnConflict == ConflictOverwrite
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("beginning transaction: %w", err)
+	}
+	defer tx.Rollback() //nolint:errcheck // rollback after commit is a no-op
+
+	result := models.ArchiveImportResult{DryRun: opts.DryRun, OnConflict: opts.OnConflict}
+	record := func(kind, key, title, action string) {
+		if opts.DryRun {
+			result.Changes = append(result.Changes, models.ArchiveImportChange{
+				Kind: kind, Key: key, Title: title, Action: action,
+			})
+		}
+	}
+
+	for _, src := range data.Sources {
+		if src.FeedURL == "" || src.FeedURL == customFeedURL {
+			continue
+		}
+		active := 0
+		if src.IsActive {
+			active = 1
+		}
+		action, err := insertOrOverwrite(ctx, tx, overwrite,
+			`INSERT INTO blog_sources (name, company, feed_url, site_url, is_active)
+			 VALUES (?, ?, ?, ?, ?)
+			 ON CONFLICT(feed_url) DO NOTHING`,
+			[]any{src.Name, src.Company, src.FeedURL, src.SiteURL, active},
+			`UPDATE blog_sources SET name = ?, company = ?, site_url = ?, is_active = ?
+			 WHERE feed_url = ?`,
+			[]any{src.Name, src.Company, src.SiteURL, active, src.FeedURL},
+		)
+		if err != nil {
+			return nil, fmt.Errorf("importing source %q: %w", src.FeedURL, err)
+		}
+		result.SourcesAdded, result.SourcesOverwritten, result.SourcesSkipped =
+			count(action, result.SourcesAdded, result.SourcesOverwritten, result.SourcesSkipped)
+		record("source", src.FeedURL, src.Name, action)
+	}
+
+	// Tags go first so that their metadata survives the bare rows created
+	// when tagging items.
+	for _, tag := range data.Tags {
+		name := strings.TrimSpace(strings.ToLower(tag.Name))
+		if name == "" {
+			continue
+		}
+		action, err := insertOrOverwrite(ctx, tx, overwrite,
+			`INSERT INTO tags (name, color, description) VALUES (?, ?, ?)
+			 ON CONFLICT(name) DO NOTHING`,
+			[]any{name, nullableString(tag.Color), nullableString(tag.Description)},
+			`UPDATE tags SET color = ?, description = ? WHERE name = ?`,
+			[]any{nullableString(tag.Color), nullableString(tag.Description), name},
+		)
+		if err != nil {
+			return nil, fmt.Errorf("importing tag %q: %w", name, err)
+		}
+		result.TagsAdded, result.TagsOverwritten, result.TagsSkipped =
+			count(action, result.TagsAdded, result.TagsOverwritten, result.TagsSkipped)
+		record("tag", name, "", action)
+	}
+
+	for i := range data.Blogs {
+		post := &data.Blogs[i]
+		_, action, err := s.importArchivePost(ctx, tx, post, overwrite)
+		if err != nil {
+			return nil, err
+		}
+		if action == models.ImportMerge {
+			action = models.ImportSkip
+		}
+		result.BlogsAdded, result.BlogsOverwritten, result.BlogsSkipped =
+			count(action, result.BlogsAdded, result.BlogsOverwritten, result.BlogsSkipped)
+		record("blog", post.URL, post.Title, action)
+	}
+
+	for i := range data.ReadingList {
+		item := &data.ReadingList[i]
+		action, err := s.importArchiveItem(ctx, tx, item, overwrite)
+		if err != nil {
+			return nil, err
+		}
+		if action == models.ImportMerge {
+			result.ItemsMerged++
+		} else {
+			result.ItemsAdded, result.ItemsOverwritten, result.ItemsSkipped =
+				count(action, result.ItemsAdded, result.ItemsOverwritten, result.ItemsSkipped)
+		}
+		record("item", item.URL, item.Title, action)
+	}
+
+	for key, value := range data.Preferences {
+		action, err := insertOrOverwrite(ctx, tx, overwrite,
+			`INSERT INTO preferences (key, value) VALUES (?, ?)
+			 ON CONFLICT(key) DO NOTHING`,
+			[]any{key, string(value)},
+			`UPDATE preferences SET value = ?, updated_at = datetime('now') WHERE key = ?`,
+			[]any{string(value), key},
+		)
+		if err != nil {
+			return nil, fmt.Errorf("importing preference %q: %w", key, err)
+		}
+		result.PreferencesAdded, result.PreferencesOverwritten, result.PreferencesSkipped =
+			count(action, result.PreferencesAdded, result.PreferencesOverwritten, result.PreferencesSkipped)
+		record("preference", key, "", action)
+	}
+
+	if opts.DryRun {
+		return &result, nil
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("committing transaction: %w", err)
+	}
+	return &result, nil
+}
+
+// insertOrOverwrite runs insert, which must do nothing on conflict, and, if
+// it inserted no row and overwrite is set, runs update in its place. It
+// returns the action taken.
+func insertOrOverwrite(ctx context.Context, tx *sql.Tx, overwrite bool, insert string, insertArgs []any, update string, updateArgs []any) (string, error) {
+	res, err := tx.ExecContext(ctx, insert, insertArgs...)
+	if err != nil {
+		return "", err
+	}
+	if n, _ := res.RowsAffected(); n > 0 {
+		return models.ImportCreate, nil
+	}
+	if !overwrite {
+		return models.ImportSkip, nil
+	}
+	if _, err := tx.ExecContext(ctx, update, updateArgs...); err != nil {
+		return "", err
+	}
+	return models.ImportOverwrite, nil
+}
+
+// count adds one to the counter of action among added, overwritten, and
+// skipped, and returns the three counters.
+func count(action string, added, overwritten, skipped int) (int, int, int) {
+	switch action {
+	case models.ImportCreate:
+		added++
+	case models.ImportOverwrite:
+		overwritten++
+	default:
+		skipped++
+	}
+	return added, overwritten, skipped
+}
+
+// importArchivePost adds an archived post and its summary unless a post
+// with the same URL exists, and returns the post's ID. The action is
+// ImportCreate for a new post, ImportOverwrite for an existing one that was
+// replaced because overwrite is set, and ImportMerge for one left as it was.
+func (s *sqlStore) importArchivePost(ctx context.Context, tx *sql.Tx, post *models.ArchivePost, overwrite bool) (int64, string, error) {
+	if post.URL == "" || post.Title == "" {
+		return 0, "", fmt.Errorf("importing post: url and title are required")
+	}
+
+	feedURL := post.SourceFeedURL
+	if feedURL == "" {
+		feedURL = customFeedURL
+	}
+	var sourceID int64
+	err := tx.QueryRowContext(ctx,
+		`SELECT id FROM blog_sources WHERE feed_url IN (?, ?)
+		 ORDER BY feed_url = ? DESC LIMIT 1`,
+		feedURL, customFeedURL, feedURL,
+	).Scan(&sourceID)
+	if err != nil {
+		return 0, "", fmt.Errorf("resolving source of %q: %w", post.URL, err)
+	}
+
+	res, err := tx.ExecContext(ctx,
+		`INSERT INTO blogs (source_id, title, url, description, full_content, published_at, fetched_at, custom_source)
+		 VALUES (?, ?, ?, ?, ?, ?, datetime('now'), ?)
+		 ON CONFLICT(url) DO NOTHING`,
+		sourceID, post.Title, post.URL, nullableString(post.Description),
+		s.encodeContent(post.Content), formatTimePtr(post.PublishedAt), nullableString(post.CustomSource),
+	)
+	if err != nil {
+		return 0, "", fmt.Errorf("importing post %q: %w", post.URL, err)
+	}
+	action := models.ImportMerge
+	if n, _ := res.RowsAffected(); n > 0 {
+		action = models.ImportCreate
+	}
+
+	var blogID int64
+	if err := tx.QueryRowContext(ctx,
+		`SELECT id FROM blogs WHERE url = ?`, post.URL).Scan(&blogID); err != nil {
+		return 0, "", fmt.Errorf("getting imported post id: %w", err)
+	}
+
+	if action == models.ImportMerge && overwrite {
+		action = models.ImportOverwrite
+		if _, err := tx.ExecContext(ctx,
+			`UPDATE blogs SET source_id = ?, title = ?, description = ?,
+				full_content = COALESCE(?, full_content),
+				published_at = COALESCE(?, published_at),
+				custom_source = ?
+			 WHERE id = ?`,
+			sourceID, post.Title, nullableString(post.Description),
+			s.encodeContent(post.Content), formatTimePtr(post.PublishedAt),
+			nullableString(post.CustomSource), blogID,
+		); err != nil {
+			return 0, "", fmt.Errorf("overwriting post %q: %w", post.URL, err)
+		}
+		// Content now in the blogs table supersedes any cold copy.
+		if post.Content != "" {
+			if _, err := tx.ExecContext(ctx,
+				`DELETE FROM blog_cold_content WHERE blog_id = ?`, blogID); err != nil {
+				return 0, "", fmt.Errorf("clearing cold content of %q: %w", post.URL, err)
+			}
+		}
+	}
+
+	if post.Summary != "" {
+		onConflict := `DO NOTHING`
+		if overwrite {
+			onConflict = `DO UPDATE SET
+				summary    = excluded.summary,
+				difficulty = excluded.difficulty,
+				category   = excluded.category,
+				model_used = excluded.model_used,
+				stale      = 0,
+				created_at = datetime('now')`
+		}
+		if _, err := tx.ExecContext(ctx,
+			`INSERT INTO blog_summaries (blog_id, summary, difficulty, category, model_used)
+			 VALUES (?, ?, ?, ?, ?)
+			 ON CONFLICT(blog_id) `+onConflict,
+			blogID, post.Summary, nullableString(post.Difficulty),
+			nullableString(post.Category), post.SummaryModel,
+		); err != nil {
+			return 0, "", fmt.Errorf("importing summary of %q: %w", post.URL, err)
+		}
+	}
+	return blogID, action, nil
+}
+
+// importArchiveItem adds an archived post and its summary if the post is
+// new, then adds it to the reading list with its tags. It returns
+// ImportCreate for a new post, ImportMerge for an existing post that was not
+// on the reading list, and, for one that already was, ImportOverwrite if
+// overwrite is set (replacing the item's state and tags) or ImportSkip.
+func (s *sqlStore) importArchiveItem(ctx context.Context, tx *sql.Tx, item *models.ArchiveItem, overwrite bool) (string, error) {
+	if item.URL == "" || item.Title == "" {
+		return "", fmt.Errorf("importing reading list item: url and title are required")
+	}
+	if item.Status == "" {
+		item.Status = "unread"
+	}
+	if !validStatuses[item.Status] {
+		return "", fmt.Errorf("importing %q: invalid status %q", item.URL, item.Status)
+	}
+
+	blogID, action, err := s.importArchivePost(ctx, tx, &item.ArchivePost, overwrite)
+	if err != nil {
+		return "", err
+	}
+
+	addedAt := item.AddedAt
+	if addedAt.IsZero() {
+		addedAt = time.Now()
+	}
+	args := []any{
+		item.Status, min(max(item.Progress, 0), 100), nullableString(item.Notes),
+		addedAt.UTC().Format("2006-01-02 15:04:05"), formatTimePtr(item.ReadAt),
+		formatTimePtr(item.ArchivedAt), formatTimePtr(item.SnoozedUntil), item.Position,
+		blogID,
+	}
+	var itemID int64
+	err = tx.QueryRowContext(ctx,
+		`INSERT INTO reading_list (status, progress, notes, added_at, read_at, archived_at, snoozed_until, position, blog_id)
+		 VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+		 ON CONFLICT(blog_id) DO NOTHING
+		 RETURNING id`, args...).Scan(&itemID)
+	switch {
+	case err == nil:
+		if action == models.ImportOverwrite {
+			action = models.ImportMerge
+		}
+	case errors.Is(err, sql.ErrNoRows):
+		if !overwrite {
+			return models.ImportSkip, nil
+		}
+		action = models.ImportOverwrite
+		if err := tx.QueryRowContext(ctx,
+			`UPDATE reading_list SET status = ?, progress = ?, notes = ?, added_at = ?,
+				read_at = ?, archived_at = ?, snoozed_until = ?, position = ?
+			 WHERE blog_id = ?
+			 RETURNING id`, args...).Scan(&itemID); err != nil {
+			return "", fmt.Errorf("overwriting reading list item %q: %w", item.URL, err)
+		}
+		if _, err := tx.ExecContext(ctx,
+			`DELETE FROM reading_list_tags WHERE reading_list_id = ?`, itemID); err != nil {
+			return "", fmt.Errorf("clearing tags of %q: %w", item.URL, err)
+		}
+	default:
+		return "", fmt.Errorf("importing reading list item %q: %w", item.URL, err)
+	}
+
+	for _, tag := range item.Tags {
+		tag = strings.TrimSpace(strings.ToLower(tag))
+		if tag == "" {
+			continue
+		}
+		if _, err := tx.ExecContext(ctx,
+			`INSERT INTO tags (name) VALUES (?) ON CONFLICT DO NOTHING`, tag); err != nil {
+			return "", fmt.Errorf("importing tag %q: %w", tag, err)
+		}
+		if _, err := tx.ExecContext(ctx,
+			`INSERT INTO reading_list_tags (reading_list_id, tag_id)
+			 SELECT CAST(? AS INTEGER), id FROM tags WHERE name = ?
+			 ON CONFLICT DO NOTHING`, itemID, tag); err != nil {
+			return "", fmt.Errorf("tagging %q: %w", item.URL, err)
+		}
+	}
+	return action, nil
+}
+
+// formatTimePtr formats t as a SQLite datetime, or returns nil if t is nil.
+func formatTimePtr(t *time.Time) *string {
+	if t == nil {
+		return nil
+	}
+	v := t.UTC().Format("2006-01-02 15:04:05")
+	return &v
+}
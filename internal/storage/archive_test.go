@@ -0,0 +1,350 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"iter"
+	"testing"
+	"time"
+
+	"github.com/hoanghai1803/apricot/internal/models"
+)
+
+// exportAll collects everything store.ExportAll streams.
+func exportAll(t *testing.T, store *SQLiteStore) *models.ArchiveData {
+	t.Helper()
+	var data models.ArchiveData
+	err := store.ExportAll(context.Background(), func(stream *models.ArchiveStream) error {
+		var err error
+		if data.Sources, err = collect(stream.Sources); err != nil {
+			return err
+		}
+		if data.Blogs, err = collect(stream.Blogs); err != nil {
+			return err
+		}
+		if data.ReadingList, err = collect(stream.ReadingList); err != nil {
+			return err
+		}
+		if data.Tags, err = collect(stream.Tags); err != nil {
+			return err
+		}
+		data.Preferences = stream.Preferences
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("ExportAll() error: %v", err)
+	}
+	return &data
+}
+
+// collect returns the records records yields, or the first error.
+func collect[T any](records iter.Seq2[T, error]) ([]T, error) {
+	list := []T{}
+	for rec, err := range records {
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, rec)
+	}
+	return list, nil
+}
+
+func TestExportImportArchive(t *testing.T) {
+	ctx := context.Background()
+
+	src := newTestStore(t)
+	blogID, err := src.UpsertBlog(ctx, &models.Blog{
+		SourceID:    seedTestSource(t, src),
+		Title:       "Archived Post",
+		URL:         "https://test.com/archived",
+		FullContent: "archived text",
+		FetchedAt:   time.Now(),
+	})
+	if err != nil {
+		t.Fatalf("UpsertBlog: %v", err)
+	}
+	if err := src.AddToReadingList(ctx, blogID); err != nil {
+		t.Fatalf("AddToReadingList: %v", err)
+	}
+	itemID, err := src.GetReadingListIDByBlogID(ctx, blogID)
+	if err != nil {
+		t.Fatalf("GetReadingListIDByBlogID: %v", err)
+	}
+	if err := src.UpdateReadingListStatus(ctx, itemID, "read"); err != nil {
+		t.Fatalf("UpdateReadingListStatus: %v", err)
+	}
+	if err := src.UpdateReadingListNotes(ctx, itemID, "worth a re-read"); err != nil {
+		t.Fatalf("UpdateReadingListNotes: %v", err)
+	}
+	if err := src.AddTagToItem(ctx, itemID, "databases"); err != nil {
+		t.Fatalf("AddTagToItem: %v", err)
+	}
+	if err := src.SetPreference(ctx, "topics", "storage engines"); err != nil {
+		t.Fatalf("SetPreference: %v", err)
+	}
+
+	data := exportAll(t, src)
+	if len(data.ReadingList) != 1 {
+		t.Fatalf("exported %d items, want 1", len(data.ReadingList))
+	}
+
+	dst := newTestStore(t)
+	result, err := dst.ImportArchive(ctx, data, ImportOptions{})
+	if err != nil {
+		t.Fatalf("ImportArchive() error: %v", err)
+	}
+	if result.ItemsAdded != 1 || result.PreferencesAdded != 1 {
+		t.Errorf("result = %+v, want 1 item and 1 preference added", result)
+	}
+
+	items, err := dst.GetReadingList(ctx, "")
+	if err != nil {
+		t.Fatalf("GetReadingList() error: %v", err)
+	}
+	if len(items) != 1 {
+		t.Fatalf("imported %d items, want 1", len(items))
+	}
+	got := items[0]
+	if got.Blog.URL != "https://test.com/archived" || got.Status != "read" {
+		t.Errorf("imported item = %s (%s), want https://test.com/archived (read)", got.Blog.URL, got.Status)
+	}
+	if got.Blog.FullContent != "archived text" {
+		t.Errorf("FullContent = %q, want %q", got.Blog.FullContent, "archived text")
+	}
+	if got.Blog.Source != data.Sources[0].Name {
+		t.Errorf("Source = %q, want %q", got.Blog.Source, data.Sources[0].Name)
+	}
+	if got.Notes == nil || *got.Notes != "worth a re-read" {
+		t.Errorf("Notes = %v, want %q", got.Notes, "worth a re-read")
+	}
+	if len(got.Tags) != 1 || got.Tags[0] != "databases" {
+		t.Errorf("Tags = %v, want [databases]", got.Tags)
+	}
+	var topics string
+	if err := dst.GetPreference(ctx, "topics", &topics); err != nil || topics != "storage engines" {
+		t.Errorf("topics = %q, %v; want %q", topics, err, "storage engines")
+	}
+
+	// Importing again skips everything that already exists.
+	result, err = dst.ImportArchive(ctx, data, ImportOptions{})
+	if err != nil {
+		t.Fatalf("second ImportArchive() error: %v", err)
+	}
+	if result.ItemsAdded != 0 || result.ItemsSkipped != 1 || result.PreferencesSkipped != 1 {
+		t.Errorf("second result = %+v, want everything skipped", result)
+	}
+}
+
+func TestImportArchive_DryRun(t *testing.T) {
+	ctx := context.Background()
+	store := newTestStore(t)
+
+	// The second post was already fetched by discovery, so importing it
+	// merges into the existing post.
+	if _, err := store.UpsertBlog(ctx, &models.Blog{
+		SourceID:  seedTestSource(t, store),
+		Title:     "Known Post",
+		URL:       "https://test.com/known",
+		FetchedAt: time.Now(),
+	}); err != nil {
+		t.Fatalf("UpsertBlog: %v", err)
+	}
+
+	data := &models.ArchiveData{
+		ReadingList: []models.ArchiveItem{
+			{ArchivePost: models.ArchivePost{URL: "https://test.com/new", Title: "New Post"}},
+			{ArchivePost: models.ArchivePost{URL: "https://test.com/known", Title: "Known Post"}},
+		},
+	}
+	result, err := store.ImportArchive(ctx, data, ImportOptions{DryRun: true})
+	if err != nil {
+		t.Fatalf("ImportArchive() error: %v", err)
+	}
+	if !result.DryRun || result.ItemsAdded != 1 || result.ItemsMerged != 1 {
+		t.Errorf("result = %+v, want a dry run with 1 item added and 1 merged", result)
+	}
+	want := []models.ArchiveImportChange{
+		{Kind: "item", Key: "https://test.com/new", Title: "New Post", Action: models.ImportCreate},
+		{Kind: "item", Key: "https://test.com/known", Title: "Known Post", Action: models.ImportMerge},
+	}
+	if len(result.Changes) != len(want) {
+		t.Fatalf("Changes = %+v, want %+v", result.Changes, want)
+	}
+	for i := range want {
+		if result.Changes[i] != want[i] {
+			t.Errorf("Changes[%d] = %+v, want %+v", i, result.Changes[i], want[i])
+		}
+	}
+
+	items, err := store.GetReadingList(ctx, "")
+	if err != nil {
+		t.Fatalf("GetReadingList() error: %v", err)
+	}
+	if len(items) != 0 {
+		t.Errorf("dry run wrote %d reading list items, want 0", len(items))
+	}
+	if _, err := store.GetBlogByURL(ctx, "https://test.com/new"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("GetBlogByURL() after dry run error = %v, want ErrNotFound", err)
+	}
+}
+
+func TestSchemaVersion(t *testing.T) {
+	store := newTestStore(t)
+
+	v, err := store.SchemaVersion(context.Background())
+	if err != nil {
+		t.Fatalf("SchemaVersion() error: %v", err)
+	}
+	if v != 37 {
+		t.Errorf("SchemaVersion() = %d, want 37", v)
+	}
+}
+
+func TestExportImportArchive_BlogsAndTags(t *testing.T) {
+	ctx := context.Background()
+
+	src := newTestStore(t)
+	blogID, err := src.UpsertBlog(ctx, &models.Blog{
+		SourceID:    seedTestSource(t, src),
+		Title:       "Unsaved Post",
+		URL:         "https://test.com/unsaved",
+		FullContent: "unsaved text",
+		FetchedAt:   time.Now(),
+	})
+	if err != nil {
+		t.Fatalf("UpsertBlog: %v", err)
+	}
+	if err := src.UpsertSummary(ctx, &models.BlogSummary{
+		BlogID: blogID, Summary: "A summary.", Difficulty: "beginner", ModelUsed: "test-model",
+	}); err != nil {
+		t.Fatalf("UpsertSummary: %v", err)
+	}
+	if err := src.UpdateTag(ctx, "databases", "#336699", "Storage and queries"); err != nil {
+		t.Fatalf("UpdateTag: %v", err)
+	}
+
+	data := exportAll(t, src)
+	if len(data.Blogs) != 1 || data.Blogs[0].Content != "unsaved text" || data.Blogs[0].Summary != "A summary." {
+		t.Fatalf("exported blogs = %+v, want the unsaved post with content and summary", data.Blogs)
+	}
+	if len(data.Tags) != 1 || data.Tags[0].Color != "#336699" {
+		t.Fatalf("exported tags = %+v, want databases with its color", data.Tags)
+	}
+
+	dst := newTestStore(t)
+	result, err := dst.ImportArchive(ctx, data, ImportOptions{})
+	if err != nil {
+		t.Fatalf("ImportArchive() error: %v", err)
+	}
+	if result.BlogsAdded != 1 || result.TagsAdded != 1 {
+		t.Errorf("result = %+v, want 1 blog and 1 tag added", result)
+	}
+
+	blog, err := dst.GetBlogByURL(ctx, "https://test.com/unsaved")
+	if err != nil {
+		t.Fatalf("GetBlogByURL() error: %v", err)
+	}
+	if blog.FullContent != "unsaved text" {
+		t.Errorf("FullContent = %q, want %q", blog.FullContent, "unsaved text")
+	}
+	summary, err := dst.GetSummaryByBlogID(ctx, blog.ID)
+	if err != nil || summary.Summary != "A summary." || summary.Difficulty != "beginner" {
+		t.Errorf("summary = %+v, %v; want the exported summary", summary, err)
+	}
+	tags, err := dst.GetAllTags(ctx)
+	if err != nil {
+		t.Fatalf("GetAllTags() error: %v", err)
+	}
+	if len(tags) != 1 || tags[0].Color != "#336699" || tags[0].Description != "Storage and queries" {
+		t.Errorf("tags = %+v, want databases with its metadata", tags)
+	}
+
+	// Importing again skips everything that already exists.
+	result, err = dst.ImportArchive(ctx, data, ImportOptions{})
+	if err != nil {
+		t.Fatalf("second ImportArchive() error: %v", err)
+	}
+	if result.BlogsAdded != 0 || result.BlogsSkipped != 1 || result.TagsSkipped != 1 {
+		t.Errorf("second result = %+v, want everything skipped", result)
+	}
+}
+
+func TestImportArchive_Overwrite(t *testing.T) {
+	ctx := context.Background()
+	store := newTestStore(t)
+
+	blogID, err := store.UpsertBlog(ctx, &models.Blog{
+		SourceID:  seedTestSource(t, store),
+		Title:     "Old Title",
+		URL:       "https://test.com/post",
+		FetchedAt: time.Now(),
+	})
+	if err != nil {
+		t.Fatalf("UpsertBlog: %v", err)
+	}
+	if err := store.AddToReadingList(ctx, blogID); err != nil {
+		t.Fatalf("AddToReadingList: %v", err)
+	}
+	itemID, err := store.GetReadingListIDByBlogID(ctx, blogID)
+	if err != nil {
+		t.Fatalf("GetReadingListIDByBlogID: %v", err)
+	}
+	if err := store.AddTagToItem(ctx, itemID, "stale-tag"); err != nil {
+		t.Fatalf("AddTagToItem: %v", err)
+	}
+	if err := store.SetPreference(ctx, "topics", "old topics"); err != nil {
+		t.Fatalf("SetPreference: %v", err)
+	}
+
+	data := &models.ArchiveData{
+		ReadingList: []models.ArchiveItem{
+			{ArchivePost: models.ArchivePost{URL: "https://test.com/post", Title: "New Title", Summary: "Fresh."},
+				Status: "read", Progress: 100, Tags: []string{"fresh-tag"}},
+		},
+		Preferences: map[string]json.RawMessage{"topics": json.RawMessage(`"new topics"`)},
+	}
+
+	// Skipping leaves everything as it was.
+	result, err := store.ImportArchive(ctx, data, ImportOptions{})
+	if err != nil {
+		t.Fatalf("ImportArchive(skip) error: %v", err)
+	}
+	if result.ItemsSkipped != 1 || result.PreferencesSkipped != 1 || result.OnConflict != ConflictSkip {
+		t.Errorf("skip result = %+v, want the item and preference skipped", result)
+	}
+
+	result, err = store.ImportArchive(ctx, data, ImportOptions{OnConflict: ConflictOverwrite})
+	if err != nil {
+		t.Fatalf("ImportArchive(overwrite) error: %v", err)
+	}
+	if result.ItemsOverwritten != 1 || result.PreferencesOverwritten != 1 {
+		t.Errorf("overwrite result = %+v, want the item and preference overwritten", result)
+	}
+
+	item, err := store.GetReadingListItemByID(ctx, itemID)
+	if err != nil {
+		t.Fatalf("GetReadingListItemByID() error: %v", err)
+	}
+	if item.Blog.Title != "New Title" || item.Status != "read" || item.Progress != 100 {
+		t.Errorf("item = %q (%s, %d%%), want New Title (read, 100%%)", item.Blog.Title, item.Status, item.Progress)
+	}
+	if item.Summary == nil || *item.Summary != "Fresh." {
+		t.Errorf("Summary = %v, want %q", item.Summary, "Fresh.")
+	}
+	if len(item.Tags) != 1 || item.Tags[0] != "fresh-tag" {
+		t.Errorf("Tags = %v, want [fresh-tag]", item.Tags)
+	}
+	var topics string
+	if err := store.GetPreference(ctx, "topics", &topics); err != nil || topics != "new topics" {
+		t.Errorf("topics = %q, %v; want %q", topics, err, "new topics")
+	}
+}
+
+func TestImportArchive_InvalidConflictPolicy(t *testing.T) {
+	store := newTestStore(t)
+
+	if _, err := store.ImportArchive(context.Background(), &models.ArchiveData{}, ImportOptions{OnConflict: "merge"}); err == nil {
+		t.Error("ImportArchive() with an invalid policy succeeded, want an error")
+	}
+}
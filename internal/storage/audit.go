@@ -0,0 +1,138 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/hoanghai1803/apricot/internal/models"
+)
+
+// Audited actions, as recorded in audit_log.action.
+const (
+	AuditSourceToggle      = "source.toggle"
+	AuditPreferenceSet     = "preference.set"
+	AuditTagUpdate         = "tag.update"
+	AuditReadingListDelete = "reading_list.delete"
+	AuditPathDelete        = "learning_path.delete"
+	AuditResearchDelete    = "research_report.delete"
+	AuditSecretDelete      = "secret.delete"
+	AuditBlogsPrune        = "blogs.prune"
+)
+
+// recordAudit adds an entry to the audit log as part of tx, so that it is
+// kept exactly when the change it describes is. before and after are stored
+// as JSON; nil stores nothing.
+func recordAudit(ctx context.Context, tx *sql.Tx, action, entity string, entityID any, before, after any) error {
+	beforeJSON, err := auditJSON(before)
+	if err != nil {
+		return fmt.Errorf("marshaling audit value: %w", err)
+	}
+	afterJSON, err := auditJSON(after)
+	if err != nil {
+		return fmt.Errorf("marshaling audit value: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx,
+		`INSERT INTO audit_log (action, entity, entity_id, before_json, after_json)
+		 VALUES (?, ?, ?, ?, ?)`,
+		action, entity, fmt.Sprint(entityID), beforeJSON, afterJSON); err != nil {
+		return fmt.Errorf("recording %s in audit log: %w", action, err)
+	}
+	return nil
+}
+
+// auditJSON marshals v for the audit log, or returns nil for a nil v.
+func auditJSON(v any) (*string, error) {
+	if v == nil {
+		return nil, nil
+	}
+	if raw, ok := v.(json.RawMessage); ok {
+		if raw == nil {
+			return nil, nil
+		}
+		s := string(raw)
+		return &s, nil
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	s := string(data)
+	return &s, nil
+}
+
+// AuditFilter selects audit log entries. Empty fields match everything.
+type AuditFilter struct {
+	Entity   string
+	EntityID string
+	Action   string
+
+	// Limit and Offset select a page of the entries, newest first. A Limit
+	// of 0 returns every entry from Offset on.
+	Limit  int
+	Offset int
+}
+
+// ListAuditLog returns the audit log entries matching filter, newest first.
+func (s *sqlStore) ListAuditLog(ctx context.Context, filter AuditFilter) ([]models.AuditEntry, error) {
+	var (
+		where []string
+		args  []any
+	)
+	if filter.Entity != "" {
+		where, args = append(where, "entity = ?"), append(args, filter.Entity)
+	}
+	if filter.EntityID != "" {
+		where, args = append(where, "entity_id = ?"), append(args, filter.EntityID)
+	}
+	if filter.Action != "" {
+		where, args = append(where, "action = ?"), append(args, filter.Action)
+	}
+
+	query := `SELECT id, action, entity, entity_id, before_json, after_json, created_at FROM audit_log`
+	if len(where) > 0 {
+		query += " WHERE " + strings.Join(where, " AND ")
+	}
+	query += " ORDER BY id DESC"
+	if filter.Limit > 0 || filter.Offset > 0 {
+		// SQLite requires a LIMIT before OFFSET; -1 means no limit.
+		limit := filter.Limit
+		if limit <= 0 {
+			limit = -1
+		}
+		query += " LIMIT ? OFFSET ?"
+		args = append(args, limit, max(filter.Offset, 0))
+	}
+
+	rows, err := s.rdb.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("querying audit log: %w", err)
+	}
+	defer rows.Close()
+
+	entries := []models.AuditEntry{}
+	for rows.Next() {
+		var (
+			e             models.AuditEntry
+			before, after sql.NullString
+			createdAt     string
+		)
+		if err := rows.Scan(&e.ID, &e.Action, &e.Entity, &e.EntityID, &before, &after, &createdAt); err != nil {
+			return nil, fmt.Errorf("scanning audit entry: %w", err)
+		}
+		if before.Valid {
+			e.Before = json.RawMessage(before.String)
+		}
+		if after.Valid {
+			e.After = json.RawMessage(after.String)
+		}
+		e.CreatedAt = parseTime(createdAt)
+		entries = append(entries, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating audit log: %w", err)
+	}
+	return entries, nil
+}
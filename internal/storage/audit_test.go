@@ -0,0 +1,97 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"testing"
+)
+
+func TestAuditLog(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+	sourceID := seedTestSource(t, store)
+
+	if _, err := store.ToggleSource(ctx, sourceID, false, 0); err != nil {
+		t.Fatalf("ToggleSource() error: %v", err)
+	}
+	// Unchanged values are not logged.
+	if _, err := store.ToggleSource(ctx, sourceID, false, 0); err != nil {
+		t.Fatalf("ToggleSource() error: %v", err)
+	}
+	if err := store.SetPreference(ctx, "max_results", 10); err != nil {
+		t.Fatalf("SetPreference() error: %v", err)
+	}
+	if err := store.SetPreference(ctx, "max_results", 10); err != nil {
+		t.Fatalf("SetPreference() error: %v", err)
+	}
+	if err := store.SetPreference(ctx, "max_results", 5); err != nil {
+		t.Fatalf("SetPreference() error: %v", err)
+	}
+	blogID := seedSearchBlog(t, store, "Consensus", "Raft", "https://test.com/raft")
+	if err := store.AddToReadingList(ctx, blogID); err != nil {
+		t.Fatalf("AddToReadingList() error: %v", err)
+	}
+	itemID, err := store.GetReadingListIDByBlogID(ctx, blogID)
+	if err != nil {
+		t.Fatalf("GetReadingListIDByBlogID() error: %v", err)
+	}
+	if err := store.RemoveFromReadingList(ctx, itemID); err != nil {
+		t.Fatalf("RemoveFromReadingList() error: %v", err)
+	}
+
+	entries, err := store.ListAuditLog(ctx, AuditFilter{})
+	if err != nil {
+		t.Fatalf("ListAuditLog() error: %v", err)
+	}
+	var actions []string
+	for _, e := range entries {
+		actions = append(actions, e.Action)
+	}
+	want := []string{AuditReadingListDelete, AuditPreferenceSet, AuditPreferenceSet, AuditSourceToggle}
+	if len(actions) != len(want) {
+		t.Fatalf("audit actions = %v, want %v", actions, want)
+	}
+	for i := range want {
+		if actions[i] != want[i] {
+			t.Fatalf("audit actions = %v, want %v", actions, want)
+		}
+	}
+
+	deleted := entries[0]
+	var before struct {
+		URL    string `json:"url"`
+		Status string `json:"status"`
+	}
+	if err := json.Unmarshal(deleted.Before, &before); err != nil {
+		t.Fatalf("decoding deleted item: %v", err)
+	}
+	if before.URL != "https://test.com/raft" || before.Status != "unread" || deleted.After != nil {
+		t.Errorf("deletion entry = %+v, want the deleted item before and nothing after", deleted)
+	}
+	if string(entries[1].Before) != "10" || string(entries[1].After) != "5" {
+		t.Errorf("preference change = %s -> %s, want 10 -> 5", entries[1].Before, entries[1].After)
+	}
+	if entries[2].Before != nil {
+		t.Errorf("first preference set has before value %s, want none", entries[2].Before)
+	}
+	if entries[3].CreatedAt.IsZero() {
+		t.Error("audit entry has no timestamp")
+	}
+
+	sources, err := store.ListAuditLog(ctx, AuditFilter{Entity: "source", EntityID: strconv.FormatInt(sourceID, 10)})
+	if err != nil {
+		t.Fatalf("ListAuditLog() filtered error: %v", err)
+	}
+	if len(sources) != 1 || string(sources[0].After) != `{"is_active":false,"name":"Test Blog"}` {
+		t.Errorf("source entries = %+v, want the toggle", sources)
+	}
+
+	page, err := store.ListAuditLog(ctx, AuditFilter{Limit: 1, Offset: 1})
+	if err != nil {
+		t.Fatalf("ListAuditLog() paged error: %v", err)
+	}
+	if len(page) != 1 || page[0].ID != entries[1].ID {
+		t.Errorf("second page = %+v, want entry %d", page, entries[1].ID)
+	}
+}
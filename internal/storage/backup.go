@@ -0,0 +1,16 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+)
+
+// BackupTo writes a consistent copy of the database to path with VACUUM
+// INTO. The copy is compacted, and writers are not blocked while it is
+// taken. path must not exist yet.
+func (s *SQLiteStore) BackupTo(ctx context.Context, path string) error {
+	if _, err := s.db.ExecContext(ctx, `VACUUM INTO ?`, path); err != nil {
+		return fmt.Errorf("backing up database to %q: %w", path, err)
+	}
+	return nil
+}
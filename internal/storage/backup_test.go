@@ -0,0 +1,34 @@
+package storage
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+)
+
+func TestBackupTo(t *testing.T) {
+	ctx := context.Background()
+	store := newTestStore(t)
+	if err := store.SetPreference(ctx, "topics", "backups"); err != nil {
+		t.Fatalf("SetPreference: %v", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "backup.db")
+	if err := store.BackupTo(ctx, path); err != nil {
+		t.Fatalf("BackupTo() error: %v", err)
+	}
+
+	db, err := OpenDatabase(path)
+	if err != nil {
+		t.Fatalf("OpenDatabase(backup) error: %v", err)
+	}
+	defer db.Close()
+	var topics string
+	if err := NewSQLiteStore(db).GetPreference(ctx, "topics", &topics); err != nil || topics != "backups" {
+		t.Errorf("topics in backup = %q, %v; want %q", topics, err, "backups")
+	}
+
+	if err := store.BackupTo(ctx, path); err == nil {
+		t.Error("BackupTo() over an existing file succeeded, want an error")
+	}
+}
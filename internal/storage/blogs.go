@@ -5,32 +5,35 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/hoanghai1803/apricot/internal/models"
 )
 
 // UpsertBlog inserts a blog post or updates it if a row with the same URL
 // already exists. On conflict the full_content, content_hash, and fetched_at
-// fields are updated. The row ID is returned.
-func (s *Store) UpsertBlog(ctx context.Context, blog *models.Blog) (int64, error) {
+// fields are updated. The content and its hash are kept when the update
+// carries none; a changed hash flags the blog's summary as stale and keeps
+// the replaced content as a revision. The row ID is returned.
+func (s *sqlStore) UpsertBlog(ctx context.Context, blog *models.Blog) (int64, error) {
 	var publishedAt *string
 	if blog.PublishedAt != nil {
 		v := blog.PublishedAt.Format("2006-01-02 15:04:05")
 		publishedAt = &v
 	}
 
-	fetchedAt := blog.FetchedAt.Format("2006-01-02 15:04:05")
+	fetchedAt := blog.FetchedAt.UTC().Format("2006-01-02 15:04:05")
 
 	_, err := s.db.ExecContext(ctx,
 		`INSERT INTO blogs (source_id, title, url, description, full_content, published_at, fetched_at, content_hash)
 		 VALUES (?, ?, ?, ?, ?, ?, ?, ?)
 		 ON CONFLICT(url) DO UPDATE SET
-			full_content = excluded.full_content,
-			content_hash = excluded.content_hash,
+			full_content = COALESCE(excluded.full_content, blogs.full_content),
+			content_hash = COALESCE(excluded.content_hash, blogs.content_hash),
 			fetched_at   = excluded.fetched_at`,
 		blog.SourceID, blog.Title, blog.URL, nullableString(blog.Description),
-		nullableString(blog.FullContent), publishedAt, fetchedAt,
-		nullableString(blog.ContentHash),
+		s.encodeContent(blog.FullContent), publishedAt, fetchedAt,
+		storedContentHash(blog),
 	)
 	if err != nil {
 		return 0, fmt.Errorf("upserting blog: %w", err)
@@ -39,19 +42,17 @@ func (s *Store) UpsertBlog(ctx context.Context, blog *models.Blog) (int64, error
 	// Retrieve the ID of the upserted row. SQLite's last_insert_rowid()
 	// may not reflect the correct ID on an UPDATE path, so we query by URL.
 	var id int64
-	if err := s.db.QueryRowContext(ctx, `SELECT id FROM blogs WHERE url = ?`, blog.URL).Scan(&id); err != nil {
+	if err := s.rdb.QueryRowContext(ctx, `SELECT id FROM blogs WHERE url = ?`, blog.URL).Scan(&id); err != nil {
 		return 0, fmt.Errorf("getting upserted blog id: %w", err)
 	}
 	return id, nil
 }
 
-// GetBlogByURL returns the blog post with the given URL.
-// Returns nil, ErrNotFound if no matching row exists.
-func (s *Store) GetBlogByURL(ctx context.Context, url string) (*models.Blog, error) {
-	row := s.db.QueryRowContext(ctx,
-		`SELECT b.id, b.source_id, COALESCE(b.custom_source, bs.name, '') AS source, b.title, b.url,
-				b.description, b.full_content, b.published_at, b.fetched_at,
-				b.content_hash, b.reading_time_minutes, b.created_at
+// GetBlogByURL returns the blog post with the given URL, with any cold
+// content rehydrated. Returns nil, ErrNotFound if no matching row exists.
+func (s *sqlStore) GetBlogByURL(ctx context.Context, url string) (*models.Blog, error) {
+	row := s.rdb.QueryRowContext(ctx,
+		`SELECT `+blogColumns+`
 		 FROM blogs b
 		 LEFT JOIN blog_sources bs ON bs.id = b.source_id
 		 WHERE b.url = ?`, url)
@@ -63,16 +64,17 @@ func (s *Store) GetBlogByURL(ctx context.Context, url string) (*models.Blog, err
 		}
 		return nil, fmt.Errorf("getting blog by url: %w", err)
 	}
+	if err := s.rehydrateContent(ctx, blog); err != nil {
+		return nil, err
+	}
 	return blog, nil
 }
 
-// GetBlogByID returns the blog post with the given ID.
-// Returns nil, ErrNotFound if no matching row exists.
-func (s *Store) GetBlogByID(ctx context.Context, id int64) (*models.Blog, error) {
-	row := s.db.QueryRowContext(ctx,
-		`SELECT b.id, b.source_id, COALESCE(b.custom_source, bs.name, '') AS source, b.title, b.url,
-				b.description, b.full_content, b.published_at, b.fetched_at,
-				b.content_hash, b.reading_time_minutes, b.created_at
+// GetBlogByID returns the blog post with the given ID, with any cold content
+// rehydrated. Returns nil, ErrNotFound if no matching row exists.
+func (s *sqlStore) GetBlogByID(ctx context.Context, id int64) (*models.Blog, error) {
+	row := s.rdb.QueryRowContext(ctx,
+		`SELECT `+blogColumns+`
 		 FROM blogs b
 		 LEFT JOIN blog_sources bs ON bs.id = b.source_id
 		 WHERE b.id = ?`, id)
@@ -84,13 +86,46 @@ func (s *Store) GetBlogByID(ctx context.Context, id int64) (*models.Blog, error)
 		}
 		return nil, fmt.Errorf("getting blog by id: %w", err)
 	}
+	if err := s.rehydrateContent(ctx, blog); err != nil {
+		return nil, err
+	}
 	return blog, nil
 }
 
+// GetBlogsFetchedSince returns the posts fetched from feeds at or after
+// since, newest first, without their content. User-added posts are left
+// out.
+func (s *sqlStore) GetBlogsFetchedSince(ctx context.Context, since time.Time) ([]models.Blog, error) {
+	rows, err := s.rdb.QueryContext(ctx,
+		`SELECT `+blogListColumns+`
+		 FROM blogs b
+		 LEFT JOIN blog_sources bs ON bs.id = b.source_id
+		 WHERE b.fetched_at >= ? AND COALESCE(bs.feed_url, '') != 'custom://user-added'
+		 ORDER BY b.fetched_at DESC, b.id DESC`,
+		since.UTC().Format("2006-01-02 15:04:05"))
+	if err != nil {
+		return nil, fmt.Errorf("querying blogs fetched since %s: %w", since.Format(time.RFC3339), err)
+	}
+	defer rows.Close()
+
+	var blogs []models.Blog
+	for rows.Next() {
+		blog, err := scanBlog(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scanning fetched blog: %w", err)
+		}
+		blogs = append(blogs, *blog)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating fetched blogs: %w", err)
+	}
+	return blogs, nil
+}
+
 // GetCustomSourceID returns the ID of the sentinel "custom://user-added" source.
-func (s *Store) GetCustomSourceID(ctx context.Context) (int64, error) {
+func (s *sqlStore) GetCustomSourceID(ctx context.Context) (int64, error) {
 	var id int64
-	err := s.db.QueryRowContext(ctx,
+	err := s.rdb.QueryRowContext(ctx,
 		`SELECT id FROM blog_sources WHERE feed_url = 'custom://user-added'`,
 	).Scan(&id)
 	if err != nil {
@@ -101,7 +136,7 @@ func (s *Store) GetCustomSourceID(ctx context.Context) (int64, error) {
 
 // CreateCustomBlog inserts a user-added blog post linked to the sentinel
 // "Custom" source. The customSource field overrides the display source name.
-func (s *Store) CreateCustomBlog(ctx context.Context, url, title, description, fullContent, customSource string) (int64, error) {
+func (s *sqlStore) CreateCustomBlog(ctx context.Context, url, title, description, fullContent, customSource string) (int64, error) {
 	sourceID, err := s.GetCustomSourceID(ctx)
 	if err != nil {
 		return 0, err
@@ -111,21 +146,48 @@ func (s *Store) CreateCustomBlog(ctx context.Context, url, title, description, f
 		`INSERT INTO blogs (source_id, title, url, description, full_content, fetched_at, custom_source)
 		 VALUES (?, ?, ?, ?, ?, datetime('now'), ?)`,
 		sourceID, title, url, nullableString(description),
-		nullableString(fullContent), nullableString(customSource),
+		s.encodeContent(fullContent), nullableString(customSource),
 	)
 	if err != nil {
 		return 0, fmt.Errorf("creating custom blog: %w", err)
 	}
 
 	var id int64
-	if err := s.db.QueryRowContext(ctx, `SELECT id FROM blogs WHERE url = ?`, url).Scan(&id); err != nil {
+	if err := s.rdb.QueryRowContext(ctx, `SELECT id FROM blogs WHERE url = ?`, url).Scan(&id); err != nil {
 		return 0, fmt.Errorf("getting custom blog id: %w", err)
 	}
 	return id, nil
 }
 
-// SaveBlogs batch-upserts multiple blog posts inside a single transaction.
-func (s *Store) SaveBlogs(ctx context.Context, blogs []models.Blog) error {
+// UpdateCustomBlog replaces the title, description, content, and source
+// name of a user-added blog post, once they have been fetched.
+func (s *sqlStore) UpdateCustomBlog(ctx context.Context, id int64, title, description, fullContent, customSource string) error {
+	res, err := s.db.ExecContext(ctx,
+		`UPDATE blogs SET title = ?, description = ?, full_content = ?, custom_source = ?, fetched_at = datetime('now')
+		 WHERE id = ?`,
+		title, nullableString(description), s.encodeContent(fullContent), nullableString(customSource), id,
+	)
+	if err != nil {
+		return fmt.Errorf("updating custom blog: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// refetchInterval is how often SaveBlogs bumps the fetched_at of a post
+// whose content has not changed.
+const refetchInterval = 24 * time.Hour
+
+// SaveBlogs batch-upserts multiple blog posts inside a single transaction,
+// with the same conflict handling as UpsertBlog. A post that is already
+// stored is only updated when it brings content with a different hash, or
+// content where none is stored, or when its fetched_at is more than
+// refetchInterval old; feeds list the same posts on every run, and
+// rewriting them all would only churn the database. A post whose content
+// is in cold storage keeps it there unless the content changed.
+func (s *sqlStore) SaveBlogs(ctx context.Context, blogs []models.Blog) error {
 	tx, err := s.db.BeginTx(ctx, nil)
 	if err != nil {
 		return fmt.Errorf("beginning transaction: %w", err)
@@ -136,9 +198,18 @@ func (s *Store) SaveBlogs(ctx context.Context, blogs []models.Blog) error {
 		`INSERT INTO blogs (source_id, title, url, description, full_content, published_at, fetched_at, content_hash)
 		 VALUES (?, ?, ?, ?, ?, ?, ?, ?)
 		 ON CONFLICT(url) DO UPDATE SET
-			full_content = excluded.full_content,
-			content_hash = excluded.content_hash,
-			fetched_at   = excluded.fetched_at`)
+			full_content = CASE
+				WHEN EXISTS (SELECT 1 FROM blog_cold_content c WHERE c.blog_id = blogs.id)
+				     AND COALESCE(excluded.content_hash, '') = COALESCE(blogs.content_hash, '')
+				THEN blogs.full_content
+				ELSE COALESCE(excluded.full_content, blogs.full_content)
+			END,
+			content_hash = COALESCE(excluded.content_hash, blogs.content_hash),
+			fetched_at   = excluded.fetched_at
+		 WHERE (excluded.full_content IS NOT NULL AND blogs.full_content IS NULL
+		        AND NOT EXISTS (SELECT 1 FROM blog_cold_content c WHERE c.blog_id = blogs.id))
+			OR (excluded.content_hash IS NOT NULL AND excluded.content_hash != COALESCE(blogs.content_hash, ''))
+			OR blogs.fetched_at < ?`)
 	if err != nil {
 		return fmt.Errorf("preparing statement: %w", err)
 	}
@@ -151,12 +222,13 @@ func (s *Store) SaveBlogs(ctx context.Context, blogs []models.Blog) error {
 			v := b.PublishedAt.Format("2006-01-02 15:04:05")
 			publishedAt = &v
 		}
-		fetchedAt := b.FetchedAt.Format("2006-01-02 15:04:05")
+		fetchedAt := b.FetchedAt.UTC().Format("2006-01-02 15:04:05")
+		staleBefore := b.FetchedAt.Add(-refetchInterval).UTC().Format("2006-01-02 15:04:05")
 
 		if _, err := stmt.ExecContext(ctx,
 			b.SourceID, b.Title, b.URL, nullableString(b.Description),
-			nullableString(b.FullContent), publishedAt, fetchedAt,
-			nullableString(b.ContentHash),
+			s.encodeContent(b.FullContent), publishedAt, fetchedAt,
+			storedContentHash(b), staleBefore,
 		); err != nil {
 			return fmt.Errorf("upserting blog %q: %w", b.URL, err)
 		}
@@ -173,41 +245,82 @@ type scanner interface {
 	Scan(dest ...any) error
 }
 
-// scanBlog scans a single blog row into a models.Blog.
-func scanBlog(row scanner) (*models.Blog, error) {
-	var (
-		blog             models.Blog
-		description      sql.NullString
-		fullContent      sql.NullString
-		publishedAt      sql.NullString
-		fetchedAt        string
-		contentHash      sql.NullString
-		readingTimeMin   sql.NullInt64
-		createdAt        string
-	)
+// blogColumns is the column list scanned by blogRow. Queries must alias the
+// blogs table as "b" and LEFT JOIN blog_sources as "bs".
+const blogColumns = `b.id, b.source_id, COALESCE(b.custom_source, bs.name, '') AS source, b.title, b.url,
+				b.description, content_text(b.full_content), b.published_at, b.fetched_at,
+				b.content_hash, b.reading_time_minutes, b.difficulty, b.rewritten_title, b.created_at`
+
+// blogListColumns is blogColumns for list views, which never show the post
+// content. It selects NULL in place of the content, so it is not decompressed
+// and Blog.FullContent is left empty.
+const blogListColumns = `b.id, b.source_id, COALESCE(b.custom_source, bs.name, '') AS source, b.title, b.url,
+				b.description, NULL, b.published_at, b.fetched_at,
+				b.content_hash, b.reading_time_minutes, b.difficulty, b.rewritten_title, b.created_at`
+
+// blogRow holds the intermediate scan targets for the columns in
+// blogColumns, so queries that join blogs with other tables can share the
+// same scanning logic.
+type blogRow struct {
+	description    sql.NullString
+	fullContent    sql.NullString
+	publishedAt    sql.NullString
+	fetchedAt      string
+	contentHash    sql.NullString
+	readingTimeMin sql.NullInt64
+	difficulty     sql.NullString
+	rewrittenTitle sql.NullString
+	createdAt      string
+}
 
-	if err := row.Scan(
+// dest returns the scan destinations for blogColumns, in order.
+func (r *blogRow) dest(blog *models.Blog) []any {
+	return []any{
 		&blog.ID, &blog.SourceID, &blog.Source, &blog.Title, &blog.URL,
-		&description, &fullContent, &publishedAt, &fetchedAt,
-		&contentHash, &readingTimeMin, &createdAt,
-	); err != nil {
-		return nil, err
+		&r.description, &r.fullContent, &r.publishedAt, &r.fetchedAt,
+		&r.contentHash, &r.readingTimeMin, &r.difficulty, &r.rewrittenTitle, &r.createdAt,
 	}
+}
 
-	blog.Description = description.String
-	blog.FullContent = fullContent.String
-	blog.ContentHash = contentHash.String
-	if readingTimeMin.Valid {
-		v := int(readingTimeMin.Int64)
+// apply copies the scanned intermediate values into blog.
+func (r *blogRow) apply(blog *models.Blog) {
+	blog.Description = r.description.String
+	blog.FullContent = r.fullContent.String
+	blog.ContentHash = r.contentHash.String
+	blog.Difficulty = r.difficulty.String
+	blog.RewrittenTitle = r.rewrittenTitle.String
+	if r.readingTimeMin.Valid {
+		v := int(r.readingTimeMin.Int64)
 		blog.ReadingTimeMinutes = &v
 	}
-	blog.PublishedAt = parseTimePtr(nullStringToPtr(publishedAt))
-	blog.FetchedAt = parseTime(fetchedAt)
-	blog.CreatedAt = parseTime(createdAt)
+	blog.PublishedAt = parseTimePtr(nullStringToPtr(r.publishedAt))
+	blog.FetchedAt = parseTime(r.fetchedAt)
+	blog.CreatedAt = parseTime(r.createdAt)
+}
 
+// scanBlog scans a single blog row into a models.Blog.
+func scanBlog(row scanner) (*models.Blog, error) {
+	var (
+		blog models.Blog
+		br   blogRow
+	)
+	if err := row.Scan(br.dest(&blog)...); err != nil {
+		return nil, err
+	}
+	br.apply(&blog)
 	return &blog, nil
 }
 
+// storedContentHash returns the content hash to store for blog: its
+// ContentHash when it has full content, otherwise nil so that the hash of
+// previously stored content is kept.
+func storedContentHash(blog *models.Blog) *string {
+	if blog.FullContent == "" {
+		return nil
+	}
+	return nullableString(blog.ContentHash)
+}
+
 // nullableString converts an empty string to nil for nullable TEXT columns.
 func nullableString(s string) *string {
 	if s == "" {
@@ -217,7 +330,7 @@ func nullableString(s string) *string {
 }
 
 // UpdateReadingTime sets the reading_time_minutes for a blog post.
-func (s *Store) UpdateReadingTime(ctx context.Context, blogID int64, minutes int) error {
+func (s *sqlStore) UpdateReadingTime(ctx context.Context, blogID int64, minutes int) error {
 	res, err := s.db.ExecContext(ctx,
 		`UPDATE blogs SET reading_time_minutes = ? WHERE id = ?`,
 		minutes, blogID,
@@ -232,6 +345,44 @@ func (s *Store) UpdateReadingTime(ctx context.Context, blogID int64, minutes int
 	return nil
 }
 
+// UpdateBlogDifficulty sets the estimated difficulty level of a blog post.
+// The difficulty must be one of models.Difficulties.
+func (s *sqlStore) UpdateBlogDifficulty(ctx context.Context, blogID int64, difficulty string) error {
+	if !models.IsValidDifficulty(difficulty) {
+		return fmt.Errorf("invalid difficulty %q: must be one of intro, intermediate, deep-dive", difficulty)
+	}
+
+	res, err := s.db.ExecContext(ctx,
+		`UPDATE blogs SET difficulty = ? WHERE id = ?`,
+		difficulty, blogID,
+	)
+	if err != nil {
+		return fmt.Errorf("updating blog difficulty: %w", err)
+	}
+	n, _ := res.RowsAffected()
+	if n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// UpdateBlogRewrittenTitle stores the AI-rewritten factual title of a blog
+// post alongside its original title.
+func (s *sqlStore) UpdateBlogRewrittenTitle(ctx context.Context, blogID int64, title string) error {
+	res, err := s.db.ExecContext(ctx,
+		`UPDATE blogs SET rewritten_title = ? WHERE id = ?`,
+		title, blogID,
+	)
+	if err != nil {
+		return fmt.Errorf("updating blog rewritten title: %w", err)
+	}
+	n, _ := res.RowsAffected()
+	if n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
 // nullStringToPtr converts a sql.NullString to a *string.
 func nullStringToPtr(ns sql.NullString) *string {
 	if !ns.Valid {
@@ -3,6 +3,7 @@ package storage
 import (
 	"context"
 	"errors"
+	"slices"
 	"testing"
 	"time"
 
@@ -10,7 +11,7 @@ import (
 )
 
 // seedTestSource inserts a blog source for use in blog tests and returns its ID.
-func seedTestSource(t *testing.T, store *Store) int64 {
+func seedTestSource(t *testing.T, store *SQLiteStore) int64 {
 	t.Helper()
 	res, err := store.db.Exec(
 		`INSERT INTO blog_sources (name, company, feed_url, site_url, is_active)
@@ -227,3 +228,201 @@ func TestSaveBlogs_Batch(t *testing.T) {
 		t.Errorf("FullContent = %q, want %q", got.FullContent, "updated content")
 	}
 }
+
+func TestUpdateBlogDifficulty(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+
+	blogID := seedReadingListBlog(t, store, "https://test.com/difficulty")
+
+	if err := store.UpdateBlogDifficulty(ctx, blogID, models.DifficultyIntermediate); err != nil {
+		t.Fatalf("UpdateBlogDifficulty() error: %v", err)
+	}
+
+	blog, err := store.GetBlogByID(ctx, blogID)
+	if err != nil {
+		t.Fatalf("GetBlogByID() error: %v", err)
+	}
+	if blog.Difficulty != models.DifficultyIntermediate {
+		t.Errorf("Difficulty = %q, want %q", blog.Difficulty, models.DifficultyIntermediate)
+	}
+
+	if err := store.UpdateBlogDifficulty(ctx, 99999, models.DifficultyIntro); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got: %v", err)
+	}
+}
+
+func TestUpdateBlogRewrittenTitle(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+
+	blogID := seedReadingListBlog(t, store, "https://test.com/clickbait")
+
+	if err := store.UpdateBlogRewrittenTitle(ctx, blogID, "Factual Title"); err != nil {
+		t.Fatalf("UpdateBlogRewrittenTitle() error: %v", err)
+	}
+
+	blog, err := store.GetBlogByID(ctx, blogID)
+	if err != nil {
+		t.Fatalf("GetBlogByID() error: %v", err)
+	}
+	if blog.RewrittenTitle != "Factual Title" {
+		t.Errorf("RewrittenTitle = %q, want %q", blog.RewrittenTitle, "Factual Title")
+	}
+	if blog.Title != "RL Post: https://test.com/clickbait" {
+		t.Errorf("original Title changed to %q", blog.Title)
+	}
+
+	if err := store.UpdateBlogRewrittenTitle(ctx, 99999, "x"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got: %v", err)
+	}
+}
+
+func TestUpdateCustomBlog(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+
+	id, err := store.CreateCustomBlog(ctx, "https://example.com/saved", "https://example.com/saved", "", "", "example.com")
+	if err != nil {
+		t.Fatalf("CreateCustomBlog() error: %v", err)
+	}
+	if err := store.UpdateCustomBlog(ctx, id, "Saved Post", "An excerpt", "Full text", "Example Blog"); err != nil {
+		t.Fatalf("UpdateCustomBlog() error: %v", err)
+	}
+
+	got, err := store.GetBlogByID(ctx, id)
+	if err != nil {
+		t.Fatalf("GetBlogByID() error: %v", err)
+	}
+	if got.Title != "Saved Post" || got.Description != "An excerpt" || got.FullContent != "Full text" || got.Source != "Example Blog" {
+		t.Errorf("blog = %+v, want the fetched metadata", got)
+	}
+
+	if err := store.UpdateCustomBlog(ctx, 9999, "x", "", "", ""); !errors.Is(err, ErrNotFound) {
+		t.Errorf("UpdateCustomBlog(missing) error = %v, want ErrNotFound", err)
+	}
+}
+
+func TestSaveBlogs_SkipsUnchanged(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+	sourceID := seedTestSource(t, store)
+
+	first := time.Date(2026, 3, 1, 8, 0, 0, 0, time.UTC)
+	blog := models.Blog{
+		SourceID: sourceID, Title: "Hashing", URL: "https://test.com/hashing",
+		FullContent: "original text", ContentHash: "h1", FetchedAt: first,
+	}
+	save := func(fetchedAt time.Time) *models.Blog {
+		t.Helper()
+		blog.FetchedAt = fetchedAt
+		if err := store.SaveBlogs(ctx, []models.Blog{blog}); err != nil {
+			t.Fatalf("SaveBlogs() error: %v", err)
+		}
+		got, err := store.GetBlogByURL(ctx, blog.URL)
+		if err != nil {
+			t.Fatalf("GetBlogByURL() error: %v", err)
+		}
+		return got
+	}
+	save(first)
+
+	// The same content an hour later leaves the row alone.
+	if got := save(first.Add(time.Hour)); !got.FetchedAt.Equal(first) {
+		t.Errorf("FetchedAt after an unchanged save = %v, want %v", got.FetchedAt, first)
+	}
+
+	// New content is written at once.
+	blog.FullContent, blog.ContentHash = "edited text", "h2"
+	if got := save(first.Add(2 * time.Hour)); got.FullContent != "edited text" || !got.FetchedAt.Equal(first.Add(2*time.Hour)) {
+		t.Errorf("after a changed save = %q fetched %v, want the edited text fetched %v", got.FullContent, got.FetchedAt, first.Add(2*time.Hour))
+	}
+
+	// Unchanged content still bumps fetched_at once a day.
+	if got := save(first.Add(27 * time.Hour)); !got.FetchedAt.Equal(first.Add(27 * time.Hour)) {
+		t.Errorf("FetchedAt after a day = %v, want %v", got.FetchedAt, first.Add(27*time.Hour))
+	}
+}
+
+func TestSaveBlogs_KeepsColdContent(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+	sourceID := seedTestSource(t, store)
+
+	published := time.Now().AddDate(-2, 0, 0)
+	blog := models.Blog{
+		SourceID: sourceID, Title: "Old", URL: "https://test.com/old",
+		FullContent: "old text", ContentHash: "h1", PublishedAt: &published, FetchedAt: time.Now(),
+	}
+	if err := store.SaveBlogs(ctx, []models.Blog{blog}); err != nil {
+		t.Fatalf("SaveBlogs() error: %v", err)
+	}
+	if n, err := store.ArchiveColdContent(ctx, time.Now().AddDate(-1, 0, 0)); err != nil || n != 1 {
+		t.Fatalf("ArchiveColdContent() = %d, %v; want 1", n, err)
+	}
+
+	coldRows := func() int {
+		t.Helper()
+		var n int
+		if err := store.db.QueryRow(`SELECT COUNT(*) FROM blog_cold_content`).Scan(&n); err != nil {
+			t.Fatalf("counting cold content: %v", err)
+		}
+		return n
+	}
+
+	// The feed lists the post again, the same day and a few days later.
+	for _, fetchedAt := range []time.Time{time.Now().Add(time.Minute), time.Now().AddDate(0, 0, 3)} {
+		blog.FetchedAt = fetchedAt
+		if err := store.SaveBlogs(ctx, []models.Blog{blog}); err != nil {
+			t.Fatalf("SaveBlogs() error: %v", err)
+		}
+		if n := coldRows(); n != 1 {
+			t.Fatalf("saving the unchanged post again left %d cold rows, want 1", n)
+		}
+	}
+	if got, err := store.GetBlogByURL(ctx, blog.URL); err != nil || got.FullContent != "old text" {
+		t.Errorf("GetBlogByURL() = %+v, %v; want the cold content rehydrated", got, err)
+	}
+
+	// Changed content supersedes the cold copy.
+	blog.FullContent, blog.ContentHash = "new text", "h2"
+	if err := store.SaveBlogs(ctx, []models.Blog{blog}); err != nil {
+		t.Fatalf("SaveBlogs() error: %v", err)
+	}
+	if n := coldRows(); n != 0 {
+		t.Errorf("saving changed content left %d cold rows, want 0", n)
+	}
+}
+
+func TestGetBlogsFetchedSince(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+	sourceID := seedTestSource(t, store)
+
+	since := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	if err := store.SaveBlogs(ctx, []models.Blog{
+		{SourceID: sourceID, Title: "Old", URL: "https://test.com/old", FetchedAt: since.Add(-time.Hour)},
+		{SourceID: sourceID, Title: "Earlier", URL: "https://test.com/earlier", FullContent: "text", FetchedAt: since},
+		{SourceID: sourceID, Title: "Later", URL: "https://test.com/later", FetchedAt: since.Add(time.Hour)},
+	}); err != nil {
+		t.Fatalf("SaveBlogs() error: %v", err)
+	}
+	if _, err := store.CreateCustomBlog(ctx, "https://example.com/saved", "Saved", "", "", ""); err != nil {
+		t.Fatalf("CreateCustomBlog() error: %v", err)
+	}
+
+	blogs, err := store.GetBlogsFetchedSince(ctx, since)
+	if err != nil {
+		t.Fatalf("GetBlogsFetchedSince() error: %v", err)
+	}
+	var titles []string
+	for _, b := range blogs {
+		titles = append(titles, b.Title)
+		if b.FullContent != "" {
+			t.Errorf("%q FullContent = %q, want it left out", b.Title, b.FullContent)
+		}
+	}
+	if want := []string{"Later", "Earlier"}; !slices.Equal(titles, want) {
+		t.Errorf("titles = %v, want %v", titles, want)
+	}
+}
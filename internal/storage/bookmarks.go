@@ -0,0 +1,73 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hoanghai1803/apricot/internal/models"
+)
+
+// ListBookmarkLinks returns the links between reading list items and
+// bookmarks in service, oldest first.
+func (s *sqlStore) ListBookmarkLinks(ctx context.Context, service string) ([]models.BookmarkLink, error) {
+	rows, err := s.rdb.QueryContext(ctx,
+		`SELECT id, service, remote_id, reading_list_id, url, local_hash, remote_hash, synced_at
+		 FROM bookmark_links
+		 WHERE service = ?
+		 ORDER BY id`, service)
+	if err != nil {
+		return nil, fmt.Errorf("listing bookmark links: %w", err)
+	}
+	defer rows.Close()
+
+	links := []models.BookmarkLink{}
+	for rows.Next() {
+		var (
+			link     models.BookmarkLink
+			syncedAt string
+		)
+		if err := rows.Scan(&link.ID, &link.Service, &link.RemoteID, &link.ReadingListID,
+			&link.URL, &link.LocalHash, &link.RemoteHash, &syncedAt); err != nil {
+			return nil, fmt.Errorf("scanning bookmark link: %w", err)
+		}
+		link.SyncedAt = parseTime(syncedAt)
+		links = append(links, link)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating bookmark links: %w", err)
+	}
+	return links, nil
+}
+
+// SaveBookmarkLink records link, replacing any link to the same bookmark,
+// and sets its ID and SyncedAt.
+func (s *sqlStore) SaveBookmarkLink(ctx context.Context, link *models.BookmarkLink) error {
+	var syncedAt string
+	err := s.db.QueryRowContext(ctx,
+		`INSERT INTO bookmark_links (service, remote_id, reading_list_id, url, local_hash, remote_hash)
+		 VALUES (?, ?, ?, ?, ?, ?)
+		 ON CONFLICT(service, remote_id) DO UPDATE SET
+		     reading_list_id = excluded.reading_list_id,
+		     url = excluded.url,
+		     local_hash = excluded.local_hash,
+		     remote_hash = excluded.remote_hash,
+		     synced_at = datetime('now')
+		 RETURNING id, synced_at`,
+		link.Service, link.RemoteID, link.ReadingListID, link.URL, link.LocalHash, link.RemoteHash,
+	).Scan(&link.ID, &syncedAt)
+	if err != nil {
+		return fmt.Errorf("saving bookmark link: %w", err)
+	}
+	link.SyncedAt = parseTime(syncedAt)
+	return nil
+}
+
+// DeleteBookmarkLink forgets the link to bookmark remoteID in service.
+// Deleting a missing link is a no-op.
+func (s *sqlStore) DeleteBookmarkLink(ctx context.Context, service, remoteID string) error {
+	if _, err := s.db.ExecContext(ctx,
+		`DELETE FROM bookmark_links WHERE service = ? AND remote_id = ?`, service, remoteID); err != nil {
+		return fmt.Errorf("deleting bookmark link: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,50 @@
+package storage
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hoanghai1803/apricot/internal/models"
+)
+
+func TestBookmarkLinks(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+
+	link := &models.BookmarkLink{Service: "pinboard", RemoteID: "https://test.com/a", ReadingListID: 1,
+		URL: "https://test.com/a", LocalHash: "l1", RemoteHash: "r1"}
+	if err := store.SaveBookmarkLink(ctx, link); err != nil {
+		t.Fatalf("SaveBookmarkLink() error: %v", err)
+	}
+	if link.ID == 0 || link.SyncedAt.IsZero() {
+		t.Errorf("SaveBookmarkLink() left %+v without an ID or sync time", link)
+	}
+	if err := store.SaveBookmarkLink(ctx, &models.BookmarkLink{Service: "raindrop", RemoteID: "42",
+		ReadingListID: 2, URL: "https://test.com/b", LocalHash: "l", RemoteHash: "r"}); err != nil {
+		t.Fatalf("SaveBookmarkLink() error: %v", err)
+	}
+
+	// Saving the same bookmark again replaces its link.
+	again := *link
+	again.LocalHash, again.RemoteHash = "l2", "r2"
+	if err := store.SaveBookmarkLink(ctx, &again); err != nil {
+		t.Fatalf("SaveBookmarkLink() error: %v", err)
+	}
+	links, err := store.ListBookmarkLinks(ctx, "pinboard")
+	if err != nil {
+		t.Fatalf("ListBookmarkLinks() error: %v", err)
+	}
+	if len(links) != 1 || links[0].ID != link.ID || links[0].LocalHash != "l2" || links[0].RemoteHash != "r2" {
+		t.Errorf("ListBookmarkLinks(pinboard) = %+v, want the one link, updated", links)
+	}
+
+	if err := store.DeleteBookmarkLink(ctx, "pinboard", link.RemoteID); err != nil {
+		t.Fatalf("DeleteBookmarkLink() error: %v", err)
+	}
+	if links, _ := store.ListBookmarkLinks(ctx, "pinboard"); len(links) != 0 {
+		t.Errorf("got %d pinboard links after deleting, want none", len(links))
+	}
+	if links, _ := store.ListBookmarkLinks(ctx, "raindrop"); len(links) != 1 {
+		t.Errorf("got %d raindrop links, want the other service's link kept", len(links))
+	}
+}
@@ -0,0 +1,139 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/hoanghai1803/apricot/internal/models"
+)
+
+// coldStorageBatch is the number of blogs moved to cold storage per
+// transaction, bounding memory use and lock time on large archives.
+const coldStorageBatch = 200
+
+// ArchiveColdContent moves the full content of blogs published (or, without
+// a publish date, fetched) before cutoff into cold storage,
+// skipping blogs on the reading list. Reads of a single blog rehydrate the
+// content transparently. Cold content is no longer matched by full-text
+// search; titles and descriptions still are. Returns the number of blogs
+// moved.
+func (s *sqlStore) ArchiveColdContent(ctx context.Context, cutoff time.Time) (int, error) {
+	cutoffStr := cutoff.UTC().Format("2006-01-02 15:04:05")
+
+	total := 0
+	for {
+		n, err := s.archiveColdBatch(ctx, cutoffStr)
+		if err != nil {
+			return total, err
+		}
+		total += n
+		if n < coldStorageBatch {
+			return total, nil
+		}
+	}
+}
+
+// archiveColdBatch moves up to coldStorageBatch blogs to cold storage in a
+// single transaction and returns how many were moved.
+func (s *sqlStore) archiveColdBatch(ctx context.Context, cutoff string) (int, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("beginning transaction: %w", err)
+	}
+	defer tx.Rollback() //nolint:errcheck // rollback after commit is a no-op
+
+	rows, err := tx.QueryContext(ctx,
+		`SELECT b.id, b.full_content FROM blogs b
+		 WHERE b.full_content IS NOT NULL AND b.full_content != ''
+		   AND COALESCE(b.published_at, b.fetched_at) < ?
+		   AND NOT EXISTS (SELECT 1 FROM reading_list rl WHERE rl.blog_id = b.id)
+		 LIMIT ?`, cutoff, coldStorageBatch)
+	if err != nil {
+		return 0, fmt.Errorf("querying cold content candidates: %w", err)
+	}
+
+	type candidate struct {
+		id      int64
+		content []byte // compressed, see content.go
+	}
+	var candidates []candidate
+	for rows.Next() {
+		var c candidate
+		if err := rows.Scan(&c.id, &c.content); err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("scanning cold content candidate: %w", err)
+		}
+		candidates = append(candidates, c)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("iterating cold content candidates: %w", err)
+	}
+
+	for _, c := range candidates {
+		if _, err := tx.ExecContext(ctx,
+			`INSERT INTO blog_cold_content (blog_id, content) VALUES (?, ?)
+			 ON CONFLICT(blog_id) DO UPDATE SET content = excluded.content, archived_at = excluded.archived_at`,
+			c.id, c.content); err != nil {
+			return 0, fmt.Errorf("storing cold content of blog %d: %w", c.id, err)
+		}
+		if _, err := tx.ExecContext(ctx,
+			`UPDATE blogs SET full_content = NULL WHERE id = ?`, c.id); err != nil {
+			return 0, fmt.Errorf("clearing content of blog %d: %w", c.id, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("committing transaction: %w", err)
+	}
+	return len(candidates), nil
+}
+
+// rehydrateContent fills in blog.FullContent from cold storage when the blog
+// has no content in the blogs table. Blogs that were never archived are left
+// unchanged.
+func (s *sqlStore) rehydrateContent(ctx context.Context, blog *models.Blog) error {
+	if blog.FullContent != "" {
+		return nil
+	}
+
+	var blob []byte
+	err := s.rdb.QueryRowContext(ctx,
+		`SELECT content FROM blog_cold_content WHERE blog_id = ?`, blog.ID).Scan(&blob)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("reading cold content: %w", err)
+	}
+
+	content, err := decodeContent(blob)
+	if err != nil {
+		return fmt.Errorf("reading cold content of blog %d: %w", blog.ID, err)
+	}
+	blog.FullContent = content
+	return nil
+}
+
+// restoreColdContent moves a blog's content from cold storage back into the
+// blogs table, e.g. when it is saved to the reading list. It is a no-op for
+// blogs without cold content.
+func (s *sqlStore) restoreColdContent(ctx context.Context, blogID int64) error {
+	blog := models.Blog{ID: blogID}
+	if err := s.rehydrateContent(ctx, &blog); err != nil {
+		return err
+	}
+	if blog.FullContent == "" {
+		return nil
+	}
+
+	// The supersede trigger drops the cold copy once content is written back.
+	if _, err := s.db.ExecContext(ctx,
+		`UPDATE blogs SET full_content = ? WHERE id = ?`, s.encodeContent(blog.FullContent), blogID); err != nil {
+		return fmt.Errorf("restoring cold content: %w", err)
+	}
+	return nil
+}
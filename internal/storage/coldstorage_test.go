@@ -0,0 +1,89 @@
+package storage
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/hoanghai1803/apricot/internal/models"
+)
+
+func TestArchiveColdContent(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+	sourceID := seedTestSource(t, store)
+
+	old := time.Now().AddDate(-2, 0, 0)
+	seed := func(url string, publishedAt time.Time) int64 {
+		t.Helper()
+		id, err := store.UpsertBlog(ctx, &models.Blog{
+			SourceID:    sourceID,
+			Title:       "Post",
+			URL:         url,
+			FullContent: "full text of " + url,
+			PublishedAt: &publishedAt,
+			FetchedAt:   time.Now(),
+		})
+		if err != nil {
+			t.Fatalf("UpsertBlog: %v", err)
+		}
+		return id
+	}
+	oldID := seed("https://test.com/old", old)
+	savedID := seed("https://test.com/old-saved", old)
+	newID := seed("https://test.com/new", time.Now())
+	if err := store.AddToReadingList(ctx, savedID); err != nil {
+		t.Fatalf("AddToReadingList: %v", err)
+	}
+
+	n, err := store.ArchiveColdContent(ctx, time.Now().AddDate(-1, 0, 0))
+	if err != nil {
+		t.Fatalf("ArchiveColdContent() error: %v", err)
+	}
+	if n != 1 {
+		t.Fatalf("archived %d blogs, want 1", n)
+	}
+
+	var hot int
+	if err := store.db.QueryRow(
+		`SELECT COUNT(*) FROM blogs WHERE full_content IS NOT NULL`).Scan(&hot); err != nil {
+		t.Fatalf("counting hot content: %v", err)
+	}
+	if hot != 2 {
+		t.Errorf("%d blogs keep hot content, want 2 (saved and new)", hot)
+	}
+
+	// Reads rehydrate transparently.
+	blog, err := store.GetBlogByID(ctx, oldID)
+	if err != nil {
+		t.Fatalf("GetBlogByID() error: %v", err)
+	}
+	if blog.FullContent != "full text of https://test.com/old" {
+		t.Errorf("got content %q", blog.FullContent)
+	}
+	if blog, _ := store.GetBlogByID(ctx, newID); blog.FullContent == "" {
+		t.Error("hot content missing")
+	}
+
+	// A second run has nothing left to move.
+	if n, err := store.ArchiveColdContent(ctx, time.Now().AddDate(-1, 0, 0)); err != nil || n != 0 {
+		t.Errorf("second ArchiveColdContent() = %d, %v; want 0, nil", n, err)
+	}
+
+	// Saving the blog moves its content back into the blogs table.
+	if err := store.AddToReadingList(ctx, oldID); err != nil {
+		t.Fatalf("AddToReadingList: %v", err)
+	}
+	var cold int
+	if err := store.db.QueryRow(`SELECT COUNT(*) FROM blog_cold_content`).Scan(&cold); err != nil {
+		t.Fatalf("counting cold content: %v", err)
+	}
+	var content string
+	if err := store.db.QueryRow(
+		`SELECT content_text(full_content) FROM blogs WHERE id = ?`, oldID).Scan(&content); err != nil {
+		t.Fatalf("reading restored content: %v", err)
+	}
+	if cold != 0 || content != "full text of https://test.com/old" {
+		t.Errorf("after saving: cold rows=%d, content=%q", cold, content)
+	}
+}
@@ -0,0 +1,91 @@
+package storage
+
+import (
+	"bytes"
+	"compress/gzip"
+	"database/sql/driver"
+	"fmt"
+	"io"
+
+	"github.com/klauspost/compress/zstd"
+	"modernc.org/sqlite"
+)
+
+// Article text is the bulk of the database, so blogs.full_content and cold
+// storage hold it zstd-compressed as BLOBs. Store methods compress on write
+// and decompress on read; SQL that needs the text (the FTS triggers and
+// blogColumns) goes through the content_text function registered below.
+// Postgres stores the text uncompressed; see sqlStore.encodeContent.
+
+var (
+	contentEncoder, _ = zstd.NewWriter(nil)
+	contentDecoder, _ = zstd.NewReader(nil)
+
+	zstdMagic = []byte{0x28, 0xb5, 0x2f, 0xfd}
+	gzipMagic = []byte{0x1f, 0x8b}
+)
+
+func init() {
+	// content_text(x) returns the text of a stored content value.
+	sqlite.MustRegisterDeterministicScalarFunction("content_text", 1,
+		func(_ *sqlite.FunctionContext, args []driver.Value) (driver.Value, error) {
+			switch v := args[0].(type) {
+			case []byte:
+				return decodeContent(v)
+			case nil, string:
+				return v, nil
+			}
+			return nil, fmt.Errorf("content_text: unsupported value of type %T", args[0])
+		})
+
+	// content_compress(x) returns the compressed form of a text value, for
+	// migrating rows written before compression. Other values are returned
+	// unchanged.
+	sqlite.MustRegisterDeterministicScalarFunction("content_compress", 1,
+		func(_ *sqlite.FunctionContext, args []driver.Value) (driver.Value, error) {
+			if v, ok := args[0].(string); ok && v != "" {
+				return compressContent(v), nil
+			}
+			return args[0], nil
+		})
+}
+
+// encodeContent returns the value to store for article text: compressed
+// bytes, or nil for empty text.
+func encodeContent(content string) any {
+	if content == "" {
+		return nil
+	}
+	return compressContent(content)
+}
+
+// compressContent zstd-compresses content.
+func compressContent(content string) []byte {
+	return contentEncoder.EncodeAll([]byte(content), nil)
+}
+
+// decodeContent returns the text of a stored content value. Besides zstd it
+// accepts gzip, used by cold storage before zstd, and plain text written
+// before compression.
+func decodeContent(raw []byte) (string, error) {
+	switch {
+	case bytes.HasPrefix(raw, zstdMagic):
+		data, err := contentDecoder.DecodeAll(raw, nil)
+		if err != nil {
+			return "", fmt.Errorf("decompressing content: %w", err)
+		}
+		return string(data), nil
+	case bytes.HasPrefix(raw, gzipMagic):
+		zr, err := gzip.NewReader(bytes.NewReader(raw))
+		if err != nil {
+			return "", fmt.Errorf("decompressing content: %w", err)
+		}
+		defer zr.Close()
+		data, err := io.ReadAll(zr)
+		if err != nil {
+			return "", fmt.Errorf("decompressing content: %w", err)
+		}
+		return string(data), nil
+	}
+	return string(raw), nil
+}
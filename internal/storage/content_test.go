@@ -0,0 +1,126 @@
+package storage
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hoanghai1803/apricot/internal/models"
+)
+
+func TestDecodeContent(t *testing.T) {
+	text := "some article text with unicode — ✓ " + strings.Repeat("repeated ", 100)
+
+	var gz bytes.Buffer
+	zw := gzip.NewWriter(&gz)
+	zw.Write([]byte(text)) //nolint:errcheck // writes to a buffer
+	zw.Close()
+
+	tests := []struct {
+		name string
+		raw  []byte
+	}{
+		{name: "zstd", raw: compressContent(text)},
+		{name: "legacy gzip", raw: gz.Bytes()},
+		{name: "legacy plain text", raw: []byte(text)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := decodeContent(tt.raw)
+			if err != nil {
+				t.Fatalf("decodeContent() error: %v", err)
+			}
+			if got != text {
+				t.Errorf("decodeContent() = %q, want %q", got, text)
+			}
+		})
+	}
+}
+
+func TestUpsertBlog_CompressesContent(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+	sourceID := seedTestSource(t, store)
+
+	content := strings.Repeat("Long-form posts compress well. ", 200)
+	id, err := store.UpsertBlog(ctx, &models.Blog{
+		SourceID:    sourceID,
+		Title:       "Compressed Post",
+		URL:         "https://test.com/compressed",
+		FullContent: content,
+		FetchedAt:   time.Now(),
+	})
+	if err != nil {
+		t.Fatalf("UpsertBlog() error: %v", err)
+	}
+
+	var (
+		kind string
+		size int
+	)
+	if err := store.db.QueryRow(
+		`SELECT typeof(full_content), length(full_content) FROM blogs WHERE id = ?`, id,
+	).Scan(&kind, &size); err != nil {
+		t.Fatalf("inspecting stored content: %v", err)
+	}
+	if kind != "blob" || size >= len(content) {
+		t.Errorf("stored %s of %d bytes, want a blob smaller than %d bytes", kind, size, len(content))
+	}
+
+	got, err := store.GetBlogByID(ctx, id)
+	if err != nil {
+		t.Fatalf("GetBlogByID() error: %v", err)
+	}
+	if got.FullContent != content {
+		t.Error("FullContent does not round-trip")
+	}
+
+	// Full-text search still matches the decompressed text.
+	results, err := store.SearchBlogs(ctx, "compress", 10, 0)
+	if err != nil {
+		t.Fatalf("SearchBlogs() error: %v", err)
+	}
+	if len(results) != 1 || results[0].ID != id {
+		t.Errorf("SearchBlogs() returned %d results, want the compressed post", len(results))
+	}
+}
+
+func TestMigration_CompressesExistingContent(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+	sourceID := seedTestSource(t, store)
+
+	// Simulate a row written before compression.
+	res, err := store.db.Exec(
+		`INSERT INTO blogs (source_id, title, url, full_content, fetched_at)
+		 VALUES (?, 'Legacy', 'https://test.com/legacy', 'legacy plain text', datetime('now'))`,
+		sourceID)
+	if err != nil {
+		t.Fatalf("inserting legacy row: %v", err)
+	}
+	id, _ := res.LastInsertId()
+
+	if _, err := store.db.Exec(
+		`UPDATE blogs SET full_content = content_compress(full_content)
+		 WHERE typeof(full_content) = 'text'`); err != nil {
+		t.Fatalf("compressing legacy rows: %v", err)
+	}
+
+	got, err := store.GetBlogByID(ctx, id)
+	if err != nil {
+		t.Fatalf("GetBlogByID() error: %v", err)
+	}
+	if got.FullContent != "legacy plain text" {
+		t.Errorf("FullContent = %q, want %q", got.FullContent, "legacy plain text")
+	}
+	results, err := store.SearchBlogs(ctx, "plain", 10, 0)
+	if err != nil {
+		t.Fatalf("SearchBlogs() error: %v", err)
+	}
+	if len(results) != 1 {
+		t.Errorf("SearchBlogs() returned %d results, want 1", len(results))
+	}
+}
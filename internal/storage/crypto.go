@@ -0,0 +1,74 @@
+package storage
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hkdf"
+	"crypto/rand"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+)
+
+// Secrets are encrypted with AES-256-GCM under a key derived with HKDF from
+// a secret the user provides in the environment, so that a copied database
+// file or backup does not give away the credentials in it. Sealed values
+// are a version byte, the 12-byte nonce, and the ciphertext with its tag.
+
+// secretBoxVersion is the format of sealed values. It changes if the key
+// derivation or cipher ever does.
+const secretBoxVersion = 1
+
+// minSecretKeyLen is the shortest secret accepted, in bytes.
+const minSecretKeyLen = 16
+
+// secretBox encrypts and decrypts secrets with a key derived from a
+// user-provided secret.
+type secretBox struct {
+	aead cipher.AEAD
+}
+
+// newSecretBox derives an encryption key from secret.
+func newSecretBox(secret string) (*secretBox, error) {
+	if len(secret) < minSecretKeyLen {
+		return nil, fmt.Errorf("secret key must be at least %d bytes", minSecretKeyLen)
+	}
+	key, err := hkdf.Key(sha256.New, []byte(secret), nil, "apricot secrets v1", 32)
+	if err != nil {
+		return nil, fmt.Errorf("deriving encryption key: %w", err)
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("creating cipher: %w", err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("creating cipher: %w", err)
+	}
+	return &secretBox{aead: aead}, nil
+}
+
+// seal encrypts plaintext. additional is authenticated but not encrypted;
+// passing the secret's name binds the value to it, so that values cannot be
+// swapped between rows.
+func (b *secretBox) seal(plaintext, additional []byte) ([]byte, error) {
+	out := make([]byte, 1+b.aead.NonceSize(), 1+b.aead.NonceSize()+len(plaintext)+b.aead.Overhead())
+	out[0] = secretBoxVersion
+	if _, err := rand.Read(out[1:]); err != nil {
+		return nil, fmt.Errorf("generating nonce: %w", err)
+	}
+	return b.aead.Seal(out, out[1:], plaintext, additional), nil
+}
+
+// open decrypts a value sealed with the same key and additional data.
+func (b *secretBox) open(sealed, additional []byte) ([]byte, error) {
+	n := b.aead.NonceSize()
+	if len(sealed) < 1+n || sealed[0] != secretBoxVersion {
+		return nil, errors.New("unrecognized encrypted value")
+	}
+	plaintext, err := b.aead.Open(nil, sealed[1:1+n], sealed[1+n:], additional)
+	if err != nil {
+		return nil, errors.New("decrypting value: wrong secret key or corrupted data")
+	}
+	return plaintext, nil
+}
@@ -4,3 +4,15 @@ import "errors"
 
 // ErrNotFound is returned when a requested record does not exist.
 var ErrNotFound = errors.New("not found")
+
+// ErrNoSecretKey is returned when storing or reading a secret without a key
+// to encrypt it with (see UseSecretKey).
+var ErrNoSecretKey = errors.New("no secret key configured")
+
+// ErrAlreadyOnList is returned when adding a blog that is already on the
+// reading list.
+var ErrAlreadyOnList = errors.New("already on the reading list")
+
+// ErrVersionConflict is returned when an update names a version of a record
+// that is no longer current, because someone else changed it in between.
+var ErrVersionConflict = errors.New("version conflict")
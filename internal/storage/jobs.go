@@ -0,0 +1,255 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hoanghai1803/apricot/internal/models"
+)
+
+// jobColumns are the columns scanned by scanJob.
+const jobColumns = `id, kind, payload, status, attempts, max_attempts, result, error,
+		run_after, created_at, started_at, finished_at`
+
+// CreateJob queues a job of the given kind with a JSON payload, to be tried
+// up to maxAttempts times, and returns it.
+func (s *sqlStore) CreateJob(ctx context.Context, kind string, payload json.RawMessage, maxAttempts int) (*models.Job, error) {
+	if len(payload) == 0 {
+		payload = json.RawMessage(`{}`)
+	}
+	var id int64
+	if err := s.db.QueryRowContext(ctx,
+		`INSERT INTO jobs (kind, payload, max_attempts) VALUES (?, ?, ?) RETURNING id`,
+		kind, string(payload), max(maxAttempts, 1)).Scan(&id); err != nil {
+		return nil, fmt.Errorf("creating %s job: %w", kind, err)
+	}
+	return s.GetJob(ctx, id)
+}
+
+// GetJob returns the job with the given ID, or ErrNotFound.
+func (s *sqlStore) GetJob(ctx context.Context, id int64) (*models.Job, error) {
+	job, err := scanJob(s.rdb.QueryRowContext(ctx,
+		`SELECT `+jobColumns+` FROM jobs WHERE id = ?`, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("getting job %d: %w", id, err)
+	}
+	return job, nil
+}
+
+// JobFilter selects jobs. Empty fields match everything.
+type JobFilter struct {
+	Kind   string
+	Status string
+
+	// Limit and Offset select a page of the jobs, newest first. A Limit of
+	// 0 returns every job from Offset on.
+	Limit  int
+	Offset int
+}
+
+// ListJobs returns the jobs matching filter, newest first.
+func (s *sqlStore) ListJobs(ctx context.Context, filter JobFilter) ([]models.Job, error) {
+	var (
+		where []string
+		args  []any
+	)
+	if filter.Kind != "" {
+		where, args = append(where, "kind = ?"), append(args, filter.Kind)
+	}
+	if filter.Status != "" {
+		where, args = append(where, "status = ?"), append(args, filter.Status)
+	}
+
+	query := `SELECT ` + jobColumns + ` FROM jobs`
+	if len(where) > 0 {
+		query += " WHERE " + strings.Join(where, " AND ")
+	}
+	query += " ORDER BY id DESC"
+	if filter.Limit > 0 || filter.Offset > 0 {
+		// SQLite requires a LIMIT before OFFSET; -1 means no limit.
+		limit := filter.Limit
+		if limit <= 0 {
+			limit = -1
+		}
+		query += " LIMIT ? OFFSET ?"
+		args = append(args, limit, max(filter.Offset, 0))
+	}
+
+	rows, err := s.rdb.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("querying jobs: %w", err)
+	}
+	defer rows.Close()
+
+	jobs := []models.Job{}
+	for rows.Next() {
+		job, err := scanJob(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scanning job: %w", err)
+		}
+		jobs = append(jobs, *job)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating jobs: %w", err)
+	}
+	return jobs, nil
+}
+
+// ClaimJob marks the oldest queued job that is due by now as running,
+// counts the attempt, and returns it. It returns ErrNotFound if no job is
+// due. Claims are made in a transaction, so two workers never get the same
+// job.
+func (s *sqlStore) ClaimJob(ctx context.Context, now time.Time) (*models.Job, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("beginning transaction: %w", err)
+	}
+	defer tx.Rollback() //nolint:errcheck // rollback after commit is a no-op
+
+	var id int64
+	err = tx.QueryRowContext(ctx,
+		`SELECT id FROM jobs WHERE status = 'queued' AND run_after <= ?
+		 ORDER BY run_after, id LIMIT 1`,
+		now.UTC().Format("2006-01-02 15:04:05")).Scan(&id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("finding a due job: %w", err)
+	}
+
+	res, err := tx.ExecContext(ctx,
+		`UPDATE jobs SET status = 'running', attempts = attempts + 1, started_at = datetime('now')
+		 WHERE id = ? AND status = 'queued'`, id)
+	if err != nil {
+		return nil, fmt.Errorf("claiming job %d: %w", id, err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		// Another worker got there first.
+		return nil, ErrNotFound
+	}
+
+	job, err := scanJob(tx.QueryRowContext(ctx, `SELECT `+jobColumns+` FROM jobs WHERE id = ?`, id))
+	if err != nil {
+		return nil, fmt.Errorf("reading claimed job %d: %w", id, err)
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("committing transaction: %w", err)
+	}
+	return job, nil
+}
+
+// FinishJob records that a running job succeeded with the given JSON result.
+func (s *sqlStore) FinishJob(ctx context.Context, id int64, result json.RawMessage) error {
+	res, err := s.db.ExecContext(ctx,
+		`UPDATE jobs SET status = 'succeeded', result = ?, error = NULL, finished_at = datetime('now')
+		 WHERE id = ?`, nullableString(string(result)), id)
+	if err != nil {
+		return fmt.Errorf("finishing job %d: %w", id, err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// FailJob records that an attempt at a running job failed with errMsg. If
+// retryAt is set the job is queued again to run then; otherwise it has
+// failed for good.
+func (s *sqlStore) FailJob(ctx context.Context, id int64, errMsg string, retryAt *time.Time) error {
+	var (
+		res sql.Result
+		err error
+	)
+	if retryAt != nil {
+		res, err = s.db.ExecContext(ctx,
+			`UPDATE jobs SET status = 'queued', error = ?, run_after = ? WHERE id = ?`,
+			errMsg, retryAt.UTC().Format("2006-01-02 15:04:05"), id)
+	} else {
+		res, err = s.db.ExecContext(ctx,
+			`UPDATE jobs SET status = 'failed', error = ?, finished_at = datetime('now') WHERE id = ?`,
+			errMsg, id)
+	}
+	if err != nil {
+		return fmt.Errorf("failing job %d: %w", id, err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// RequeueInterruptedJobs deals with jobs left running by a process that
+// stopped before finishing them: those with attempts left are queued again,
+// the rest fail. It returns the number queued again.
+func (s *sqlStore) RequeueInterruptedJobs(ctx context.Context) (int, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("beginning transaction: %w", err)
+	}
+	defer tx.Rollback() //nolint:errcheck // rollback after commit is a no-op
+
+	res, err := tx.ExecContext(ctx,
+		`UPDATE jobs SET status = 'queued', run_after = datetime('now')
+		 WHERE status = 'running' AND attempts < max_attempts`)
+	if err != nil {
+		return 0, fmt.Errorf("requeueing interrupted jobs: %w", err)
+	}
+	requeued, _ := res.RowsAffected()
+
+	if _, err := tx.ExecContext(ctx,
+		`UPDATE jobs SET status = 'failed', error = 'interrupted by a server restart', finished_at = datetime('now')
+		 WHERE status = 'running'`); err != nil {
+		return 0, fmt.Errorf("failing interrupted jobs: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("committing transaction: %w", err)
+	}
+	return int(requeued), nil
+}
+
+// PruneJobs deletes jobs that finished before cutoff and returns how many
+// were deleted.
+func (s *sqlStore) PruneJobs(ctx context.Context, cutoff time.Time) (int64, error) {
+	res, err := s.db.ExecContext(ctx,
+		`DELETE FROM jobs WHERE finished_at IS NOT NULL AND finished_at < ?`,
+		cutoff.UTC().Format("2006-01-02 15:04:05"))
+	if err != nil {
+		return 0, fmt.Errorf("pruning jobs: %w", err)
+	}
+	return res.RowsAffected()
+}
+
+// scanJob scans a row of jobColumns.
+func scanJob(row scanner) (*models.Job, error) {
+	var (
+		job                   models.Job
+		payload               string
+		result, errMsg        sql.NullString
+		runAfter, createdAt   string
+		startedAt, finishedAt *string
+	)
+	if err := row.Scan(&job.ID, &job.Kind, &payload, &job.Status, &job.Attempts, &job.MaxAttempts,
+		&result, &errMsg, &runAfter, &createdAt, &startedAt, &finishedAt); err != nil {
+		return nil, err
+	}
+	job.Payload = json.RawMessage(payload)
+	if result.Valid {
+		job.Result = json.RawMessage(result.String)
+	}
+	job.Error = errMsg.String
+	job.RunAfter = parseTime(runAfter)
+	job.CreatedAt = parseTime(createdAt)
+	job.StartedAt = parseTimePtr(startedAt)
+	job.FinishedAt = parseTimePtr(finishedAt)
+	return &job, nil
+}
@@ -0,0 +1,114 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/hoanghai1803/apricot/internal/models"
+)
+
+func TestJobLifecycle(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+	now := time.Now().Add(time.Second)
+
+	if _, err := store.ClaimJob(ctx, now); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("ClaimJob() on an empty queue error = %v, want ErrNotFound", err)
+	}
+
+	job, err := store.CreateJob(ctx, "discover", json.RawMessage(`{"dry_run":true}`), 2)
+	if err != nil {
+		t.Fatalf("CreateJob() error: %v", err)
+	}
+	if job.Status != models.JobQueued || job.MaxAttempts != 2 || string(job.Payload) != `{"dry_run":true}` {
+		t.Errorf("created job = %+v", job)
+	}
+
+	claimed, err := store.ClaimJob(ctx, now)
+	if err != nil {
+		t.Fatalf("ClaimJob() error: %v", err)
+	}
+	if claimed.ID != job.ID || claimed.Status != models.JobRunning || claimed.Attempts != 1 || claimed.StartedAt == nil {
+		t.Errorf("claimed job = %+v, want job %d running, attempt 1", claimed, job.ID)
+	}
+	if _, err := store.ClaimJob(ctx, now); !errors.Is(err, ErrNotFound) {
+		t.Errorf("ClaimJob() of a running job error = %v, want ErrNotFound", err)
+	}
+
+	// A retry waits until it is due.
+	retryAt := now.Add(time.Minute)
+	if err := store.FailJob(ctx, job.ID, "feed timeout", &retryAt); err != nil {
+		t.Fatalf("FailJob() error: %v", err)
+	}
+	if _, err := store.ClaimJob(ctx, now); !errors.Is(err, ErrNotFound) {
+		t.Errorf("ClaimJob() before the retry is due error = %v, want ErrNotFound", err)
+	}
+	if claimed, err = store.ClaimJob(ctx, retryAt); err != nil || claimed.Attempts != 2 {
+		t.Fatalf("ClaimJob() at retry time = %+v, %v; want attempt 2", claimed, err)
+	}
+
+	if err := store.FinishJob(ctx, job.ID, json.RawMessage(`{"results":[]}`)); err != nil {
+		t.Fatalf("FinishJob() error: %v", err)
+	}
+	got, err := store.GetJob(ctx, job.ID)
+	if err != nil {
+		t.Fatalf("GetJob() error: %v", err)
+	}
+	if got.Status != models.JobSucceeded || string(got.Result) != `{"results":[]}` || got.Error != "" || got.FinishedAt == nil {
+		t.Errorf("finished job = %+v", got)
+	}
+
+	jobs, err := store.ListJobs(ctx, JobFilter{Kind: "discover", Status: models.JobSucceeded})
+	if err != nil {
+		t.Fatalf("ListJobs() error: %v", err)
+	}
+	if len(jobs) != 1 || jobs[0].ID != job.ID {
+		t.Errorf("ListJobs() = %+v, want the finished job", jobs)
+	}
+
+	n, err := store.PruneJobs(ctx, time.Now().Add(time.Hour))
+	if err != nil {
+		t.Fatalf("PruneJobs() error: %v", err)
+	}
+	if n != 1 {
+		t.Errorf("PruneJobs() = %d, want 1", n)
+	}
+}
+
+func TestRequeueInterruptedJobs(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+	now := time.Now().Add(time.Second)
+
+	retryable, err := store.CreateJob(ctx, "backup", nil, 3)
+	if err != nil {
+		t.Fatalf("CreateJob() error: %v", err)
+	}
+	lastTry, err := store.CreateJob(ctx, "discover", nil, 1)
+	if err != nil {
+		t.Fatalf("CreateJob() error: %v", err)
+	}
+	for range 2 {
+		if _, err := store.ClaimJob(ctx, now); err != nil {
+			t.Fatalf("ClaimJob() error: %v", err)
+		}
+	}
+
+	n, err := store.RequeueInterruptedJobs(ctx)
+	if err != nil {
+		t.Fatalf("RequeueInterruptedJobs() error: %v", err)
+	}
+	if n != 1 {
+		t.Errorf("RequeueInterruptedJobs() = %d, want 1", n)
+	}
+
+	if got, _ := store.GetJob(ctx, retryable.ID); got.Status != models.JobQueued {
+		t.Errorf("job with attempts left = %+v, want it queued again", got)
+	}
+	if got, _ := store.GetJob(ctx, lastTry.ID); got.Status != models.JobFailed || got.Error == "" {
+		t.Errorf("job on its last attempt = %+v, want it failed", got)
+	}
+}
@@ -0,0 +1,235 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/hoanghai1803/apricot/internal/models"
+)
+
+// Tasks recorded in maintenance_runs.
+const (
+	taskVacuum      = "vacuum"
+	taskMaintenance = "maintenance"
+)
+
+// Vacuum rebuilds the database to return the space freed by deleted rows to
+// the operating system (SQLite) or to the tables for reuse (Postgres). It
+// must not run inside a transaction.
+func (s *sqlStore) Vacuum(ctx context.Context) error {
+	if _, err := s.db.ExecContext(ctx, `VACUUM`); err != nil {
+		return fmt.Errorf("vacuuming database: %w", err)
+	}
+	return s.recordRun(ctx, taskVacuum)
+}
+
+// recordRun notes that a maintenance task has just finished.
+func (s *sqlStore) recordRun(ctx context.Context, task string) error {
+	if _, err := s.db.ExecContext(ctx,
+		`INSERT INTO maintenance_runs (task, ran_at) VALUES (?, datetime('now'))
+		 ON CONFLICT(task) DO UPDATE SET ran_at = excluded.ran_at`, task); err != nil {
+		return fmt.Errorf("recording %s run: %w", task, err)
+	}
+	return nil
+}
+
+// lastRun returns when a maintenance task last finished, or nil if it never
+// has.
+func (s *sqlStore) lastRun(ctx context.Context, task string) (*time.Time, error) {
+	var ranAt string
+	err := s.db.QueryRowContext(ctx,
+		`SELECT ran_at FROM maintenance_runs WHERE task = ?`, task).Scan(&ranAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading last %s run: %w", task, err)
+	}
+	return parseTimePtr(&ranAt), nil
+}
+
+// tableStats counts the rows of each table named by query, which selects
+// table names from the database catalog. It also fills in the last vacuum
+// and maintenance times.
+func (s *sqlStore) tableStats(ctx context.Context, stats *models.DBStats, query string) error {
+	rows, err := s.db.QueryContext(ctx, query)
+	if err != nil {
+		return fmt.Errorf("listing tables: %w", err)
+	}
+	var names []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			rows.Close()
+			return fmt.Errorf("scanning table name: %w", err)
+		}
+		names = append(names, name)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("iterating tables: %w", err)
+	}
+
+	stats.Tables = make([]models.TableStats, 0, len(names))
+	for _, name := range names {
+		t := models.TableStats{Name: name}
+		// The name comes from the catalog, not the request.
+		if err := s.db.QueryRowContext(ctx,
+			`SELECT COUNT(*) FROM "`+name+`"`).Scan(&t.Rows); err != nil {
+			return fmt.Errorf("counting rows of %s: %w", name, err)
+		}
+		stats.Tables = append(stats.Tables, t)
+	}
+
+	if stats.LastVacuumAt, err = s.lastRun(ctx, taskVacuum); err != nil {
+		return err
+	}
+	if stats.LastMaintenanceAt, err = s.lastRun(ctx, taskMaintenance); err != nil {
+		return err
+	}
+	return nil
+}
+
+// DBStats reports the size of the database file and its write-ahead log,
+// the free space a vacuum would reclaim, and the rows in each table. FTS5
+// index tables are left out.
+func (s *SQLiteStore) DBStats(ctx context.Context) (*models.DBStats, error) {
+	stats := &models.DBStats{Driver: "sqlite"}
+	var err error
+	if stats.SizeBytes, stats.FreeBytes, err = s.pageUsage(ctx); err != nil {
+		return nil, err
+	}
+
+	path, err := s.dbPath(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if path != "" {
+		if info, err := os.Stat(path + "-wal"); err == nil {
+			stats.WALSizeBytes = info.Size()
+		}
+	}
+
+	if err := s.tableStats(ctx, stats,
+		`SELECT name FROM sqlite_master
+		 WHERE type = 'table' AND name NOT LIKE 'sqlite\_%' ESCAPE '\'
+		   AND name NOT LIKE 'blogs\_fts%' ESCAPE '\'
+		 ORDER BY name`); err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
+
+// RunMaintenance rebuilds the full-text index, reclaims free pages,
+// refreshes query planner statistics, and truncates the write-ahead log.
+// The first run on a database created before incremental auto-vacuum was
+// enabled switches it on, which takes a full VACUUM; later runs only
+// release the free pages.
+func (s *SQLiteStore) RunMaintenance(ctx context.Context) (*models.MaintenanceResult, error) {
+	start := time.Now()
+	result := &models.MaintenanceResult{}
+	var err error
+	if result.SizeBeforeBytes, _, err = s.pageUsage(ctx); err != nil {
+		return nil, err
+	}
+
+	if _, err := s.db.ExecContext(ctx, `INSERT INTO blogs_fts(blogs_fts) VALUES ('rebuild')`); err != nil {
+		return nil, fmt.Errorf("rebuilding search index: %w", err)
+	}
+	result.Steps = append(result.Steps, "rebuild search index")
+
+	var autoVacuum int
+	if err := s.db.QueryRowContext(ctx, `PRAGMA auto_vacuum`).Scan(&autoVacuum); err != nil {
+		return nil, fmt.Errorf("reading auto_vacuum mode: %w", err)
+	}
+	const incremental = 2
+	if autoVacuum != incremental {
+		if _, err := s.db.ExecContext(ctx, `PRAGMA auto_vacuum = INCREMENTAL`); err != nil {
+			return nil, fmt.Errorf("enabling incremental vacuum: %w", err)
+		}
+		if err := s.Vacuum(ctx); err != nil {
+			return nil, err
+		}
+		result.Steps = append(result.Steps, "vacuum")
+	} else {
+		if _, err := s.db.ExecContext(ctx, `PRAGMA incremental_vacuum`); err != nil {
+			return nil, fmt.Errorf("running incremental vacuum: %w", err)
+		}
+		result.Steps = append(result.Steps, "incremental vacuum")
+	}
+
+	if _, err := s.db.ExecContext(ctx, `PRAGMA optimize`); err != nil {
+		return nil, fmt.Errorf("optimizing database: %w", err)
+	}
+	result.Steps = append(result.Steps, "optimize")
+
+	if _, err := s.db.ExecContext(ctx, `PRAGMA wal_checkpoint(TRUNCATE)`); err != nil {
+		return nil, fmt.Errorf("checkpointing write-ahead log: %w", err)
+	}
+	result.Steps = append(result.Steps, "checkpoint")
+
+	if err := s.recordRun(ctx, taskMaintenance); err != nil {
+		return nil, err
+	}
+	if result.SizeAfterBytes, _, err = s.pageUsage(ctx); err != nil {
+		return nil, err
+	}
+	result.DurationMs = time.Since(start).Milliseconds()
+	return result, nil
+}
+
+// IntegrityCheck runs SQLite's integrity check over the whole database and
+// returns the problems it finds, or none if the database is sound.
+func (s *SQLiteStore) IntegrityCheck(ctx context.Context) ([]string, error) {
+	rows, err := s.db.QueryContext(ctx, `PRAGMA integrity_check`)
+	if err != nil {
+		return nil, fmt.Errorf("checking database integrity: %w", err)
+	}
+	defer rows.Close()
+	var problems []string
+	for rows.Next() {
+		var line string
+		if err := rows.Scan(&line); err != nil {
+			return nil, fmt.Errorf("scanning integrity check: %w", err)
+		}
+		if line != "ok" {
+			problems = append(problems, line)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating integrity check: %w", err)
+	}
+	return problems, nil
+}
+
+// pageUsage returns the size of the database and of its free pages, in
+// bytes.
+func (s *SQLiteStore) pageUsage(ctx context.Context) (size, free int64, err error) {
+	var pageSize, pages, freePages int64
+	if err := s.db.QueryRowContext(ctx, `PRAGMA page_size`).Scan(&pageSize); err != nil {
+		return 0, 0, fmt.Errorf("reading page size: %w", err)
+	}
+	if err := s.db.QueryRowContext(ctx, `PRAGMA page_count`).Scan(&pages); err != nil {
+		return 0, 0, fmt.Errorf("reading page count: %w", err)
+	}
+	if err := s.db.QueryRowContext(ctx, `PRAGMA freelist_count`).Scan(&freePages); err != nil {
+		return 0, 0, fmt.Errorf("reading free page count: %w", err)
+	}
+	return pages * pageSize, freePages * pageSize, nil
+}
+
+// dbPath returns the file of the main database, or "" for an in-memory one.
+func (s *SQLiteStore) dbPath(ctx context.Context) (string, error) {
+	var (
+		seq        int
+		name, path string
+	)
+	if err := s.db.QueryRowContext(ctx, `PRAGMA database_list`).Scan(&seq, &name, &path); err != nil {
+		return "", fmt.Errorf("reading database path: %w", err)
+	}
+	return path, nil
+}
@@ -0,0 +1,71 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/hoanghai1803/apricot/internal/models"
+)
+
+// DBStats reports the size of the database and the rows in each table.
+// Postgres manages its write-ahead log and free space itself, so those are
+// left at zero.
+func (s *PostgresStore) DBStats(ctx context.Context) (*models.DBStats, error) {
+	stats := &models.DBStats{Driver: "postgres"}
+	if err := s.db.QueryRowContext(ctx,
+		`SELECT pg_database_size(current_database())`).Scan(&stats.SizeBytes); err != nil {
+		return nil, fmt.Errorf("reading database size: %w", err)
+	}
+	if err := s.tableStats(ctx, stats,
+		`SELECT table_name FROM information_schema.tables
+		 WHERE table_schema = current_schema() AND table_type = 'BASE TABLE'
+		 ORDER BY table_name`); err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
+
+// RunMaintenance rebuilds the full-text search documents, then vacuums and
+// analyzes the database.
+func (s *PostgresStore) RunMaintenance(ctx context.Context) (*models.MaintenanceResult, error) {
+	start := time.Now()
+	result := &models.MaintenanceResult{}
+	if err := s.db.QueryRowContext(ctx,
+		`SELECT pg_database_size(current_database())`).Scan(&result.SizeBeforeBytes); err != nil {
+		return nil, fmt.Errorf("reading database size: %w", err)
+	}
+
+	if _, err := s.db.ExecContext(ctx, `SELECT refresh_blog_search(id) FROM blogs`); err != nil {
+		return nil, fmt.Errorf("rebuilding search index: %w", err)
+	}
+	result.Steps = append(result.Steps, "rebuild search index")
+
+	if _, err := s.db.ExecContext(ctx, `VACUUM ANALYZE`); err != nil {
+		return nil, fmt.Errorf("vacuuming database: %w", err)
+	}
+	if err := s.recordRun(ctx, taskVacuum); err != nil {
+		return nil, err
+	}
+	result.Steps = append(result.Steps, "vacuum analyze")
+
+	if err := s.recordRun(ctx, taskMaintenance); err != nil {
+		return nil, err
+	}
+	if err := s.db.QueryRowContext(ctx,
+		`SELECT pg_database_size(current_database())`).Scan(&result.SizeAfterBytes); err != nil {
+		return nil, fmt.Errorf("reading database size: %w", err)
+	}
+	result.DurationMs = time.Since(start).Milliseconds()
+	return result, nil
+}
+
+// IntegrityCheck only verifies that the database answers queries. Postgres
+// has no built-in equivalent of SQLite's integrity check; its page checksums
+// catch corruption as pages are read.
+func (s *PostgresStore) IntegrityCheck(ctx context.Context) ([]string, error) {
+	if err := s.db.PingContext(ctx); err != nil {
+		return nil, fmt.Errorf("checking database integrity: %w", err)
+	}
+	return nil, nil
+}
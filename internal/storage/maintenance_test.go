@@ -0,0 +1,90 @@
+package storage
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+)
+
+func TestDBStats(t *testing.T) {
+	db, err := OpenDatabase(filepath.Join(t.TempDir(), "app.db"))
+	if err != nil {
+		t.Fatalf("opening database: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	if err := RunMigrations(db); err != nil {
+		t.Fatalf("running migrations: %v", err)
+	}
+	store := NewSQLiteStore(db)
+	ctx := context.Background()
+	seedSearchBlog(t, store, "Consensus", "Raft elects a leader", "https://test.com/raft")
+
+	stats, err := store.DBStats(ctx)
+	if err != nil {
+		t.Fatalf("DBStats() error: %v", err)
+	}
+	if stats.Driver != "sqlite" || stats.SizeBytes <= 0 || stats.WALSizeBytes <= 0 {
+		t.Errorf("stats = %+v, want sqlite with a database and WAL size", stats)
+	}
+	if stats.LastVacuumAt != nil || stats.LastMaintenanceAt != nil {
+		t.Errorf("last runs = %v, %v before any maintenance, want nil", stats.LastVacuumAt, stats.LastMaintenanceAt)
+	}
+	rows := make(map[string]int64)
+	for _, table := range stats.Tables {
+		rows[table.Name] = table.Rows
+	}
+	if rows["blogs"] != 1 || rows["blog_sources"] == 0 {
+		t.Errorf("row counts = %v, want 1 blog and a source", rows)
+	}
+	if _, ok := rows["blogs_fts"]; ok {
+		t.Error("row counts include the FTS index")
+	}
+}
+
+func TestRunMaintenance(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+	seedSearchBlog(t, store, "Consensus", "Raft elects a leader", "https://test.com/raft")
+
+	result, err := store.RunMaintenance(ctx)
+	if err != nil {
+		t.Fatalf("RunMaintenance() error: %v", err)
+	}
+	if len(result.Steps) == 0 || result.Steps[1] != "vacuum" {
+		t.Errorf("first run steps = %v, want a full vacuum", result.Steps)
+	}
+
+	// Later runs reclaim free pages incrementally.
+	result, err = store.RunMaintenance(ctx)
+	if err != nil {
+		t.Fatalf("second RunMaintenance() error: %v", err)
+	}
+	if result.Steps[1] != "incremental vacuum" {
+		t.Errorf("second run steps = %v, want an incremental vacuum", result.Steps)
+	}
+
+	stats, err := store.DBStats(ctx)
+	if err != nil {
+		t.Fatalf("DBStats() error: %v", err)
+	}
+	if stats.LastVacuumAt == nil || stats.LastMaintenanceAt == nil {
+		t.Errorf("last runs = %v, %v after maintenance, want both set", stats.LastVacuumAt, stats.LastMaintenanceAt)
+	}
+	results, err := store.SearchBlogs(ctx, "raft", 10, 0)
+	if err != nil || len(results) != 1 {
+		t.Errorf("SearchBlogs() after rebuild = %d results, %v; want 1", len(results), err)
+	}
+}
+
+func TestIntegrityCheck(t *testing.T) {
+	store := newTestStore(t)
+	seedSearchBlog(t, store, "Consensus", "Raft elects a leader", "https://test.com/raft")
+
+	problems, err := store.IntegrityCheck(context.Background())
+	if err != nil {
+		t.Fatalf("IntegrityCheck() error: %v", err)
+	}
+	if len(problems) != 0 {
+		t.Errorf("problems = %q, want none", problems)
+	}
+}
@@ -0,0 +1,192 @@
+package storage
+
+import (
+	"context"
+	"embed"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+
+	"github.com/hoanghai1803/apricot/internal/models"
+)
+
+// downSuffix ends the name of the file that reverts a migration: the down
+// SQL of 007_reading_progress.sql is in 007_reading_progress.down.sql.
+const downSuffix = ".down.sql"
+
+// migration is a numbered schema change and the files that apply and
+// revert it.
+type migration struct {
+	version int
+	name    string // file with the up SQL
+	down    string // file with the down SQL; empty if irreversible
+}
+
+// loadMigrations returns the migrations in dir of fsys, ordered by version.
+func loadMigrations(fsys embed.FS, dir string) ([]migration, error) {
+	entries, err := fsys.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("reading migrations directory: %w", err)
+	}
+
+	byVersion := make(map[int]*migration)
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, ".sql") {
+			continue
+		}
+		version := parseVersion(name)
+		if version <= 0 {
+			continue
+		}
+		m := byVersion[version]
+		if m == nil {
+			m = &migration{version: version}
+			byVersion[version] = m
+		}
+		if strings.HasSuffix(name, downSuffix) {
+			m.down = name
+		} else {
+			m.name = name
+		}
+	}
+
+	migrations := make([]migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.name == "" {
+			return nil, fmt.Errorf("down migration %s has no up migration", m.down)
+		}
+		migrations = append(migrations, *m)
+	}
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].version < migrations[j].version
+	})
+	return migrations, nil
+}
+
+// migrationSource returns the embedded migrations for the store's database.
+func (s *sqlStore) migrationSource() (embed.FS, string) {
+	if s.dialect == dialectPostgres {
+		return postgresMigrationsFS, "migrations/postgres"
+	}
+	return migrationsFS, "migrations"
+}
+
+// MigrationStatus returns every migration known to this build, whether it
+// has been applied and when, and whether it can be rolled back.
+func (s *sqlStore) MigrationStatus(ctx context.Context) (*models.MigrationStatus, error) {
+	fsys, dir := s.migrationSource()
+	migrations, err := loadMigrations(fsys, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := s.rdb.QueryContext(ctx, `SELECT version, applied_at FROM schema_migrations`)
+	if err != nil {
+		return nil, fmt.Errorf("querying schema_migrations: %w", err)
+	}
+	defer rows.Close()
+
+	appliedAt := make(map[int]string)
+	for rows.Next() {
+		var (
+			v  int
+			at string
+		)
+		if err := rows.Scan(&v, &at); err != nil {
+			return nil, fmt.Errorf("scanning migration version: %w", err)
+		}
+		appliedAt[v] = at
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating migration versions: %w", err)
+	}
+
+	status := &models.MigrationStatus{Migrations: make([]models.Migration, 0, len(migrations))}
+	for _, m := range migrations {
+		mig := models.Migration{
+			Version:    m.version,
+			Name:       strings.TrimSuffix(m.name, ".sql"),
+			Reversible: m.down != "",
+		}
+		if at, ok := appliedAt[m.version]; ok {
+			mig.Applied = true
+			mig.AppliedAt = parseTimePtr(&at)
+			status.Version = max(status.Version, m.version)
+		} else {
+			status.Pending++
+		}
+		status.Migrations = append(status.Migrations, mig)
+	}
+	return status, nil
+}
+
+// RollbackTo reverts applied migrations newer than version, newest first,
+// by running their down SQL. Each runs in its own transaction, like
+// RunMigrations; if one fails, the ones before it stay reverted. Nothing is
+// reverted if any of the migrations to revert has no down SQL.
+//
+// Rolling back loses the data held by the dropped tables and columns. It is
+// meant for development, to retry a migration after fixing it.
+func (s *sqlStore) RollbackTo(ctx context.Context, version int) error {
+	if version < 0 {
+		return fmt.Errorf("invalid version %d", version)
+	}
+	fsys, dir := s.migrationSource()
+	migrations, err := loadMigrations(fsys, dir)
+	if err != nil {
+		return err
+	}
+	applied, err := appliedVersions(s.db)
+	if err != nil {
+		return fmt.Errorf("reading applied migrations: %w", err)
+	}
+
+	var revert []migration
+	for i := len(migrations) - 1; i >= 0; i-- {
+		m := migrations[i]
+		if m.version <= version || !applied[m.version] {
+			continue
+		}
+		if m.down == "" {
+			return fmt.Errorf("migration %s cannot be rolled back: it has no down migration", m.name)
+		}
+		revert = append(revert, m)
+	}
+
+	for _, m := range revert {
+		sqlBytes, err := fsys.ReadFile(dir + "/" + m.down)
+		if err != nil {
+			return fmt.Errorf("reading migration file %q: %w", m.down, err)
+		}
+		if err := s.revertMigration(ctx, m.version, string(sqlBytes)); err != nil {
+			return fmt.Errorf("reverting migration %s: %w", m.name, err)
+		}
+		slog.Info("reverted migration", "version", m.version, "file", m.down)
+	}
+	return nil
+}
+
+// revertMigration executes a migration's down SQL and forgets its version,
+// all within a single transaction.
+func (s *sqlStore) revertMigration(ctx context.Context, version int, sql string) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("beginning transaction: %w", err)
+	}
+	defer tx.Rollback() //nolint:errcheck // rollback after commit is a no-op
+
+	if _, err := tx.ExecContext(ctx, sql); err != nil {
+		return fmt.Errorf("executing migration SQL: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx,
+		`DELETE FROM schema_migrations WHERE version = ?`, version); err != nil {
+		return fmt.Errorf("forgetting migration version: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("committing migration: %w", err)
+	}
+	return nil
+}
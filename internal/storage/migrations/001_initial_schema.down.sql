@@ -0,0 +1,6 @@
+DROP TABLE IF EXISTS discovery_sessions;
+DROP TABLE IF EXISTS reading_list;
+DROP TABLE IF EXISTS preferences;
+DROP TABLE IF EXISTS blog_summaries;
+DROP TABLE IF EXISTS blogs;
+DROP TABLE IF EXISTS blog_sources;
@@ -0,0 +1,3 @@
+-- The removed sources were broken feeds, so they are not restored. There is
+-- nothing else to revert.
+SELECT 1;
@@ -0,0 +1,2 @@
+ALTER TABLE discovery_sessions DROP COLUMN failed_feeds_json;
+ALTER TABLE discovery_sessions DROP COLUMN results_json;
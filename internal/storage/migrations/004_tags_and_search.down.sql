@@ -0,0 +1,7 @@
+DROP TRIGGER IF EXISTS blogs_fts_insert;
+DROP TRIGGER IF EXISTS blogs_fts_update;
+DROP TRIGGER IF EXISTS blogs_fts_delete;
+DROP TABLE IF EXISTS blogs_fts;
+
+DROP TABLE IF EXISTS reading_list_tags;
+DROP TABLE IF EXISTS tags;
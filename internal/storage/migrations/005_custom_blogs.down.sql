@@ -0,0 +1,6 @@
+ALTER TABLE blogs DROP COLUMN custom_source;
+
+-- The sentinel source stays while user-added blogs still reference it.
+DELETE FROM blog_sources
+WHERE feed_url = 'custom://user-added'
+  AND NOT EXISTS (SELECT 1 FROM blogs WHERE blogs.source_id = blog_sources.id);
@@ -0,0 +1,3 @@
+ALTER TABLE blog_sources DROP COLUMN last_error;
+ALTER TABLE blog_sources DROP COLUMN last_fetch_ok;
+ALTER TABLE blog_sources DROP COLUMN last_fetch_at;
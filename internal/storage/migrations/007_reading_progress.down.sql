@@ -0,0 +1,2 @@
+ALTER TABLE reading_list DROP COLUMN progress;
+ALTER TABLE blogs DROP COLUMN reading_time_minutes;
@@ -0,0 +1,9 @@
+-- Cached raw AI responses, keyed by a hash of the operation, prompt template
+-- version, model, and prompt content.
+CREATE TABLE ai_response_cache (
+    cache_key   TEXT PRIMARY KEY,
+    operation   TEXT NOT NULL,
+    model       TEXT NOT NULL,
+    response    TEXT NOT NULL,
+    created_at  TEXT NOT NULL DEFAULT (datetime('now'))
+);
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_blogs_difficulty;
+ALTER TABLE blogs DROP COLUMN difficulty;
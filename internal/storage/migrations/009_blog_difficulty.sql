@@ -0,0 +1,4 @@
+-- AI-estimated difficulty level per blog: 'intro', 'intermediate', or 'deep-dive'.
+ALTER TABLE blogs ADD COLUMN difficulty TEXT;
+
+CREATE INDEX idx_blogs_difficulty ON blogs(difficulty);
@@ -0,0 +1,3 @@
+-- AI-rewritten factual title, stored alongside the original. NULL until the
+-- title has been processed; equal to the original when no rewrite was needed.
+ALTER TABLE blogs ADD COLUMN rewritten_title TEXT;
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS learning_path_items;
+DROP TABLE IF EXISTS learning_paths;
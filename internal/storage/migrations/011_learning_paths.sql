@@ -0,0 +1,20 @@
+-- Learning paths: ordered collections of reading list items. Completion is
+-- tracked through each item's reading list status.
+CREATE TABLE IF NOT EXISTS learning_paths (
+    id          INTEGER PRIMARY KEY AUTOINCREMENT,
+    title       TEXT    NOT NULL,
+    description TEXT,
+    topic       TEXT,
+    created_at  TEXT    NOT NULL DEFAULT (datetime('now')),
+    updated_at  TEXT    NOT NULL DEFAULT (datetime('now'))
+);
+
+CREATE TABLE IF NOT EXISTS learning_path_items (
+    path_id         INTEGER NOT NULL REFERENCES learning_paths(id) ON DELETE CASCADE,
+    reading_list_id INTEGER NOT NULL REFERENCES reading_list(id) ON DELETE CASCADE,
+    position        INTEGER NOT NULL,
+    note            TEXT,
+    PRIMARY KEY (path_id, reading_list_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_learning_path_items_order ON learning_path_items(path_id, position);
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_blog_summaries_category;
+ALTER TABLE blog_summaries DROP COLUMN category;
+ALTER TABLE blog_summaries DROP COLUMN difficulty;
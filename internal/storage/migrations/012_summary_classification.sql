@@ -0,0 +1,5 @@
+-- Difficulty level and primary category produced alongside each summary.
+ALTER TABLE blog_summaries ADD COLUMN difficulty TEXT;
+ALTER TABLE blog_summaries ADD COLUMN category TEXT;
+
+CREATE INDEX idx_blog_summaries_category ON blog_summaries(category);
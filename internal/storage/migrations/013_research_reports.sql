@@ -0,0 +1,13 @@
+-- Research reports: answers to user questions synthesized from archived and
+-- freshly fetched articles. Cited sources are stored as a JSON array so that
+-- a report stays readable after its blogs are pruned.
+CREATE TABLE IF NOT EXISTS research_reports (
+    id           INTEGER PRIMARY KEY AUTOINCREMENT,
+    question     TEXT    NOT NULL,
+    answer       TEXT    NOT NULL,
+    sources_json TEXT    NOT NULL,
+    model_used   TEXT,
+    created_at   TEXT    NOT NULL DEFAULT (datetime('now'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_research_reports_created ON research_reports(created_at);
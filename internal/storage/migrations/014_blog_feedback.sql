@@ -0,0 +1,7 @@
+-- Thumbs up/down feedback on individual posts, one rating per post. Used
+-- together with reading list activity to score sources.
+CREATE TABLE IF NOT EXISTS blog_feedback (
+    blog_id    INTEGER PRIMARY KEY REFERENCES blogs(id) ON DELETE CASCADE,
+    rating     INTEGER NOT NULL CHECK (rating IN (-1, 1)),
+    created_at TEXT    NOT NULL DEFAULT (datetime('now'))
+);
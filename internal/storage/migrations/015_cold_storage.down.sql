@@ -0,0 +1,5 @@
+-- Cold content goes back into the blogs table before its table is dropped.
+DROP TRIGGER IF EXISTS blog_cold_content_supersede;
+UPDATE blogs SET full_content = (SELECT content_text(content) FROM blog_cold_content c WHERE c.blog_id = blogs.id)
+WHERE id IN (SELECT blog_id FROM blog_cold_content);
+DROP TABLE IF EXISTS blog_cold_content;
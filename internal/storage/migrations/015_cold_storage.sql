@@ -0,0 +1,15 @@
+-- Cold storage: zstd-compressed full content of old, unsaved blogs (see
+-- content.go), moved out of the blogs table to keep it small.
+-- blogs.full_content is NULL while a blog's content is cold.
+CREATE TABLE IF NOT EXISTS blog_cold_content (
+    blog_id     INTEGER PRIMARY KEY REFERENCES blogs(id) ON DELETE CASCADE,
+    content     BLOB    NOT NULL,
+    archived_at TEXT    NOT NULL DEFAULT (datetime('now'))
+);
+
+-- Fresh content written to a blog (e.g. a re-extraction) supersedes the
+-- cold copy.
+CREATE TRIGGER IF NOT EXISTS blog_cold_content_supersede AFTER UPDATE OF full_content ON blogs
+WHEN new.full_content IS NOT NULL AND new.full_content != '' BEGIN
+    DELETE FROM blog_cold_content WHERE blog_id = new.id;
+END;
@@ -0,0 +1,3 @@
+-- The old URL hashes in content_hash were cleared and are not restored.
+DROP TRIGGER IF EXISTS blog_summaries_stale;
+ALTER TABLE blog_summaries DROP COLUMN stale;
@@ -0,0 +1,13 @@
+-- Summaries are flagged stale when the content they were generated from
+-- changes, so they are regenerated on next use.
+ALTER TABLE blog_summaries ADD COLUMN stale INTEGER NOT NULL DEFAULT 0;
+
+-- content_hash used to hold the feed parser's hash of the post URL. It is
+-- now only stored alongside full_content and identifies that content, so
+-- clear the old values.
+UPDATE blogs SET content_hash = NULL;
+
+CREATE TRIGGER IF NOT EXISTS blog_summaries_stale AFTER UPDATE OF content_hash ON blogs
+WHEN old.content_hash IS NOT NULL AND new.content_hash IS NOT old.content_hash BEGIN
+    UPDATE blog_summaries SET stale = 1 WHERE blog_id = new.id;
+END;
@@ -0,0 +1,27 @@
+-- Decompress article text and restore the FTS triggers of 004, which index
+-- blogs.full_content as stored. Cold and revision copies are not touched.
+DROP TRIGGER IF EXISTS blogs_fts_insert;
+DROP TRIGGER IF EXISTS blogs_fts_update;
+DROP TRIGGER IF EXISTS blogs_fts_delete;
+
+UPDATE blogs SET full_content = content_text(full_content)
+WHERE typeof(full_content) = 'blob';
+
+CREATE TRIGGER blogs_fts_insert AFTER INSERT ON blogs BEGIN
+    INSERT INTO blogs_fts(rowid, title, description, full_content)
+    VALUES (new.id, new.title, COALESCE(new.description, ''), COALESCE(new.full_content, ''));
+END;
+
+CREATE TRIGGER blogs_fts_update AFTER UPDATE ON blogs BEGIN
+    INSERT INTO blogs_fts(blogs_fts, rowid, title, description, full_content)
+    VALUES ('delete', old.id, old.title, COALESCE(old.description, ''), COALESCE(old.full_content, ''));
+    INSERT INTO blogs_fts(rowid, title, description, full_content)
+    VALUES (new.id, new.title, COALESCE(new.description, ''), COALESCE(new.full_content, ''));
+END;
+
+CREATE TRIGGER blogs_fts_delete AFTER DELETE ON blogs BEGIN
+    INSERT INTO blogs_fts(blogs_fts, rowid, title, description, full_content)
+    VALUES ('delete', old.id, old.title, COALESCE(old.description, ''), COALESCE(old.full_content, ''));
+END;
+
+INSERT INTO blogs_fts(blogs_fts) VALUES ('rebuild');
@@ -0,0 +1,27 @@
+-- Article text is stored zstd-compressed. content_text() and
+-- content_compress() are registered by the storage package, so the FTS
+-- triggers below only work through Apricot's own connection.
+DROP TRIGGER IF EXISTS blogs_fts_insert;
+DROP TRIGGER IF EXISTS blogs_fts_update;
+DROP TRIGGER IF EXISTS blogs_fts_delete;
+
+CREATE TRIGGER blogs_fts_insert AFTER INSERT ON blogs BEGIN
+    INSERT INTO blogs_fts(rowid, title, description, full_content)
+    VALUES (new.id, new.title, COALESCE(new.description, ''), COALESCE(content_text(new.full_content), ''));
+END;
+
+CREATE TRIGGER blogs_fts_update AFTER UPDATE ON blogs BEGIN
+    INSERT INTO blogs_fts(blogs_fts, rowid, title, description, full_content)
+    VALUES ('delete', old.id, old.title, COALESCE(old.description, ''), COALESCE(content_text(old.full_content), ''));
+    INSERT INTO blogs_fts(rowid, title, description, full_content)
+    VALUES (new.id, new.title, COALESCE(new.description, ''), COALESCE(content_text(new.full_content), ''));
+END;
+
+CREATE TRIGGER blogs_fts_delete AFTER DELETE ON blogs BEGIN
+    INSERT INTO blogs_fts(blogs_fts, rowid, title, description, full_content)
+    VALUES ('delete', old.id, old.title, COALESCE(old.description, ''), COALESCE(content_text(old.full_content), ''));
+END;
+
+-- Compress content written before this migration.
+UPDATE blogs SET full_content = content_compress(full_content)
+WHERE typeof(full_content) = 'text';
@@ -0,0 +1,16 @@
+-- Back to the unstemmed index of 004, over the blogs table itself. It is
+-- filled from decompressed text, as the 017 triggers keep it.
+DROP TABLE IF EXISTS blogs_fts;
+DROP VIEW IF EXISTS blogs_fts_content;
+
+CREATE VIRTUAL TABLE blogs_fts USING fts5(
+    title,
+    description,
+    full_content,
+    content='blogs',
+    content_rowid='id'
+);
+
+INSERT INTO blogs_fts(rowid, title, description, full_content)
+SELECT id, title, COALESCE(description, ''), COALESCE(content_text(full_content), '')
+FROM blogs;
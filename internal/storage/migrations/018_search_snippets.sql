@@ -0,0 +1,22 @@
+-- Rebuild the search index with porter stemming, so "caching" matches
+-- "cache" and "cached". Its external content is now a view that
+-- decompresses article text, because snippet() and highlight() read
+-- column values from the content table and blogs.full_content is
+-- compressed. The blogs_fts_* triggers are unchanged.
+DROP TABLE IF EXISTS blogs_fts;
+
+CREATE VIEW blogs_fts_content AS
+SELECT id, title, COALESCE(description, '') AS description,
+       COALESCE(content_text(full_content), '') AS full_content
+FROM blogs;
+
+CREATE VIRTUAL TABLE blogs_fts USING fts5(
+    title,
+    description,
+    full_content,
+    content='blogs_fts_content',
+    content_rowid='id',
+    tokenize='porter unicode61'
+);
+
+INSERT INTO blogs_fts(blogs_fts) VALUES ('rebuild');
@@ -0,0 +1,53 @@
+-- Back to the index of 018, without notes and summaries, and the 017
+-- triggers on blogs.
+DROP TRIGGER IF EXISTS blogs_fts_insert;
+DROP TRIGGER IF EXISTS blogs_fts_before_update;
+DROP TRIGGER IF EXISTS blogs_fts_update;
+DROP TRIGGER IF EXISTS blogs_fts_delete;
+DROP TRIGGER IF EXISTS reading_list_fts_before_insert;
+DROP TRIGGER IF EXISTS reading_list_fts_insert;
+DROP TRIGGER IF EXISTS reading_list_fts_before_update;
+DROP TRIGGER IF EXISTS reading_list_fts_update;
+DROP TRIGGER IF EXISTS reading_list_fts_before_delete;
+DROP TRIGGER IF EXISTS reading_list_fts_delete;
+DROP TRIGGER IF EXISTS blog_summaries_fts_before_insert;
+DROP TRIGGER IF EXISTS blog_summaries_fts_insert;
+DROP TRIGGER IF EXISTS blog_summaries_fts_before_update;
+DROP TRIGGER IF EXISTS blog_summaries_fts_update;
+DROP TRIGGER IF EXISTS blog_summaries_fts_before_delete;
+DROP TRIGGER IF EXISTS blog_summaries_fts_delete;
+DROP TABLE IF EXISTS blogs_fts;
+DROP VIEW IF EXISTS blogs_fts_content;
+
+CREATE VIEW blogs_fts_content AS
+SELECT id, title, COALESCE(description, '') AS description,
+       COALESCE(content_text(full_content), '') AS full_content
+FROM blogs;
+
+CREATE VIRTUAL TABLE blogs_fts USING fts5(
+    title,
+    description,
+    full_content,
+    content='blogs_fts_content',
+    content_rowid='id',
+    tokenize='porter unicode61'
+);
+
+CREATE TRIGGER blogs_fts_insert AFTER INSERT ON blogs BEGIN
+    INSERT INTO blogs_fts(rowid, title, description, full_content)
+    VALUES (new.id, new.title, COALESCE(new.description, ''), COALESCE(content_text(new.full_content), ''));
+END;
+
+CREATE TRIGGER blogs_fts_update AFTER UPDATE ON blogs BEGIN
+    INSERT INTO blogs_fts(blogs_fts, rowid, title, description, full_content)
+    VALUES ('delete', old.id, old.title, COALESCE(old.description, ''), COALESCE(content_text(old.full_content), ''));
+    INSERT INTO blogs_fts(rowid, title, description, full_content)
+    VALUES (new.id, new.title, COALESCE(new.description, ''), COALESCE(content_text(new.full_content), ''));
+END;
+
+CREATE TRIGGER blogs_fts_delete AFTER DELETE ON blogs BEGIN
+    INSERT INTO blogs_fts(blogs_fts, rowid, title, description, full_content)
+    VALUES ('delete', old.id, old.title, COALESCE(old.description, ''), COALESCE(content_text(old.full_content), ''));
+END;
+
+INSERT INTO blogs_fts(blogs_fts) VALUES ('rebuild');
@@ -0,0 +1,129 @@
+-- Index reading list notes and AI summaries alongside each post, so a search
+-- finds posts by what the user wrote about them or by the summary's wording.
+-- The index's external content view joins them onto blogs; highlights can
+-- join in the same way when they exist.
+--
+-- A row of the view now depends on three tables, so every trigger follows
+-- the same pattern: before a change, remove the post's current view row from
+-- the index; after it, add the new one. The BEFORE INSERT triggers skip
+-- inserts that will conflict, since an upsert runs the UPDATE triggers
+-- instead and ON CONFLICT DO NOTHING runs no AFTER trigger at all.
+DROP TRIGGER IF EXISTS blogs_fts_insert;
+DROP TRIGGER IF EXISTS blogs_fts_update;
+DROP TRIGGER IF EXISTS blogs_fts_delete;
+DROP TABLE IF EXISTS blogs_fts;
+DROP VIEW IF EXISTS blogs_fts_content;
+
+CREATE VIEW blogs_fts_content AS
+SELECT b.id, b.title, COALESCE(b.description, '') AS description,
+       COALESCE(content_text(b.full_content), '') AS full_content,
+       COALESCE(rl.notes, '') AS notes,
+       COALESCE(s.summary, '') AS summary
+FROM blogs b
+LEFT JOIN reading_list rl ON rl.blog_id = b.id
+LEFT JOIN blog_summaries s ON s.blog_id = b.id;
+
+CREATE VIRTUAL TABLE blogs_fts USING fts5(
+    title,
+    description,
+    full_content,
+    notes,
+    summary,
+    content='blogs_fts_content',
+    content_rowid='id',
+    tokenize='porter unicode61'
+);
+
+-- blogs
+
+CREATE TRIGGER blogs_fts_insert AFTER INSERT ON blogs BEGIN
+    INSERT INTO blogs_fts(rowid, title, description, full_content, notes, summary)
+    SELECT id, title, description, full_content, notes, summary FROM blogs_fts_content WHERE id = new.id;
+END;
+
+CREATE TRIGGER blogs_fts_before_update BEFORE UPDATE ON blogs BEGIN
+    INSERT INTO blogs_fts(blogs_fts, rowid, title, description, full_content, notes, summary)
+    SELECT 'delete', id, title, description, full_content, notes, summary FROM blogs_fts_content WHERE id = old.id;
+END;
+
+CREATE TRIGGER blogs_fts_update AFTER UPDATE ON blogs BEGIN
+    INSERT INTO blogs_fts(rowid, title, description, full_content, notes, summary)
+    SELECT id, title, description, full_content, notes, summary FROM blogs_fts_content WHERE id = new.id;
+END;
+
+CREATE TRIGGER blogs_fts_delete BEFORE DELETE ON blogs BEGIN
+    INSERT INTO blogs_fts(blogs_fts, rowid, title, description, full_content, notes, summary)
+    SELECT 'delete', id, title, description, full_content, notes, summary FROM blogs_fts_content WHERE id = old.id;
+END;
+
+-- reading_list notes
+
+CREATE TRIGGER reading_list_fts_before_insert BEFORE INSERT ON reading_list
+WHEN COALESCE(new.notes, '') != ''
+ AND NOT EXISTS (SELECT 1 FROM reading_list WHERE blog_id = new.blog_id) BEGIN
+    INSERT INTO blogs_fts(blogs_fts, rowid, title, description, full_content, notes, summary)
+    SELECT 'delete', id, title, description, full_content, notes, summary FROM blogs_fts_content WHERE id = new.blog_id;
+END;
+
+CREATE TRIGGER reading_list_fts_insert AFTER INSERT ON reading_list
+WHEN COALESCE(new.notes, '') != '' BEGIN
+    INSERT INTO blogs_fts(rowid, title, description, full_content, notes, summary)
+    SELECT id, title, description, full_content, notes, summary FROM blogs_fts_content WHERE id = new.blog_id;
+END;
+
+CREATE TRIGGER reading_list_fts_before_update BEFORE UPDATE OF notes ON reading_list BEGIN
+    INSERT INTO blogs_fts(blogs_fts, rowid, title, description, full_content, notes, summary)
+    SELECT 'delete', id, title, description, full_content, notes, summary FROM blogs_fts_content WHERE id = old.blog_id;
+END;
+
+CREATE TRIGGER reading_list_fts_update AFTER UPDATE OF notes ON reading_list BEGIN
+    INSERT INTO blogs_fts(rowid, title, description, full_content, notes, summary)
+    SELECT id, title, description, full_content, notes, summary FROM blogs_fts_content WHERE id = new.blog_id;
+END;
+
+CREATE TRIGGER reading_list_fts_before_delete BEFORE DELETE ON reading_list
+WHEN COALESCE(old.notes, '') != '' BEGIN
+    INSERT INTO blogs_fts(blogs_fts, rowid, title, description, full_content, notes, summary)
+    SELECT 'delete', id, title, description, full_content, notes, summary FROM blogs_fts_content WHERE id = old.blog_id;
+END;
+
+CREATE TRIGGER reading_list_fts_delete AFTER DELETE ON reading_list
+WHEN COALESCE(old.notes, '') != '' BEGIN
+    INSERT INTO blogs_fts(rowid, title, description, full_content, notes, summary)
+    SELECT id, title, description, full_content, notes, summary FROM blogs_fts_content WHERE id = old.blog_id;
+END;
+
+-- blog_summaries
+
+CREATE TRIGGER blog_summaries_fts_before_insert BEFORE INSERT ON blog_summaries
+WHEN NOT EXISTS (SELECT 1 FROM blog_summaries WHERE blog_id = new.blog_id) BEGIN
+    INSERT INTO blogs_fts(blogs_fts, rowid, title, description, full_content, notes, summary)
+    SELECT 'delete', id, title, description, full_content, notes, summary FROM blogs_fts_content WHERE id = new.blog_id;
+END;
+
+CREATE TRIGGER blog_summaries_fts_insert AFTER INSERT ON blog_summaries BEGIN
+    INSERT INTO blogs_fts(rowid, title, description, full_content, notes, summary)
+    SELECT id, title, description, full_content, notes, summary FROM blogs_fts_content WHERE id = new.blog_id;
+END;
+
+CREATE TRIGGER blog_summaries_fts_before_update BEFORE UPDATE OF summary ON blog_summaries BEGIN
+    INSERT INTO blogs_fts(blogs_fts, rowid, title, description, full_content, notes, summary)
+    SELECT 'delete', id, title, description, full_content, notes, summary FROM blogs_fts_content WHERE id = old.blog_id;
+END;
+
+CREATE TRIGGER blog_summaries_fts_update AFTER UPDATE OF summary ON blog_summaries BEGIN
+    INSERT INTO blogs_fts(rowid, title, description, full_content, notes, summary)
+    SELECT id, title, description, full_content, notes, summary FROM blogs_fts_content WHERE id = new.blog_id;
+END;
+
+CREATE TRIGGER blog_summaries_fts_before_delete BEFORE DELETE ON blog_summaries BEGIN
+    INSERT INTO blogs_fts(blogs_fts, rowid, title, description, full_content, notes, summary)
+    SELECT 'delete', id, title, description, full_content, notes, summary FROM blogs_fts_content WHERE id = old.blog_id;
+END;
+
+CREATE TRIGGER blog_summaries_fts_delete AFTER DELETE ON blog_summaries BEGIN
+    INSERT INTO blogs_fts(rowid, title, description, full_content, notes, summary)
+    SELECT id, title, description, full_content, notes, summary FROM blogs_fts_content WHERE id = old.blog_id;
+END;
+
+INSERT INTO blogs_fts(blogs_fts) VALUES ('rebuild');
@@ -0,0 +1,2 @@
+UPDATE reading_list SET status = 'read' WHERE status = 'archived';
+ALTER TABLE reading_list DROP COLUMN archived_at;
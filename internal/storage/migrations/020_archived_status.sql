@@ -0,0 +1,3 @@
+-- Finished reading list items can be archived: status 'archived' keeps them,
+-- with their read_at, out of the active list. archived_at records when.
+ALTER TABLE reading_list ADD COLUMN archived_at TEXT;
@@ -0,0 +1,2 @@
+ALTER TABLE tags DROP COLUMN description;
+ALTER TABLE tags DROP COLUMN color;
@@ -0,0 +1,4 @@
+-- Optional display metadata for tags, edited on the tag management screen.
+-- Tags with metadata are kept when their last item is untagged.
+ALTER TABLE tags ADD COLUMN color TEXT;
+ALTER TABLE tags ADD COLUMN description TEXT;
@@ -0,0 +1,3 @@
+DROP TRIGGER IF EXISTS reading_list_notes_update;
+DROP TRIGGER IF EXISTS reading_list_notes_insert;
+DROP TABLE IF EXISTS note_revisions;
@@ -0,0 +1,30 @@
+-- Reading list notes (Markdown) keep a history of saved versions, so an
+-- accidental overwrite can be recovered. Each distinct non-empty value
+-- written to reading_list.notes is recorded; the newest revision is the
+-- current text. Only the last 50 revisions per item are kept.
+CREATE TABLE IF NOT EXISTS note_revisions (
+    id              INTEGER PRIMARY KEY AUTOINCREMENT,
+    reading_list_id INTEGER NOT NULL REFERENCES reading_list(id) ON DELETE CASCADE,
+    notes           TEXT    NOT NULL,
+    created_at      TEXT    NOT NULL DEFAULT (datetime('now'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_note_revisions_item ON note_revisions(reading_list_id, id);
+
+-- Existing notes become each item's first revision.
+INSERT INTO note_revisions (reading_list_id, notes)
+SELECT id, notes FROM reading_list WHERE notes IS NOT NULL AND notes != '';
+
+CREATE TRIGGER IF NOT EXISTS reading_list_notes_insert AFTER INSERT ON reading_list
+WHEN new.notes IS NOT NULL AND new.notes != '' BEGIN
+    INSERT INTO note_revisions (reading_list_id, notes) VALUES (new.id, new.notes);
+END;
+
+CREATE TRIGGER IF NOT EXISTS reading_list_notes_update AFTER UPDATE OF notes ON reading_list
+WHEN new.notes IS NOT NULL AND new.notes != '' AND new.notes IS NOT old.notes BEGIN
+    INSERT INTO note_revisions (reading_list_id, notes) VALUES (new.id, new.notes);
+    DELETE FROM note_revisions
+    WHERE reading_list_id = new.id AND id NOT IN (
+        SELECT id FROM note_revisions WHERE reading_list_id = new.id ORDER BY id DESC LIMIT 50
+    );
+END;
@@ -0,0 +1,2 @@
+ALTER TABLE reading_list DROP COLUMN last_reviewed_at;
+ALTER TABLE reading_list DROP COLUMN reviews_done;
@@ -0,0 +1,5 @@
+-- Spaced repetition: read posts rated thumbs-up resurface for review one
+-- week, one month, and three months after reading. reviews_done counts the
+-- reviews completed so far.
+ALTER TABLE reading_list ADD COLUMN reviews_done INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE reading_list ADD COLUMN last_reviewed_at TEXT;
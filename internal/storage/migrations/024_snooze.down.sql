@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_reading_list_snoozed_until;
+ALTER TABLE reading_list DROP COLUMN snoozed_until;
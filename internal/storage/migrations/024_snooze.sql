@@ -0,0 +1,4 @@
+-- Snoozed reading list items are hidden from the default list until
+-- snoozed_until, when a background job moves them back to unread.
+ALTER TABLE reading_list ADD COLUMN snoozed_until TEXT;
+CREATE INDEX IF NOT EXISTS idx_reading_list_snoozed_until ON reading_list(snoozed_until);
@@ -0,0 +1 @@
+ALTER TABLE reading_list DROP COLUMN position;
@@ -0,0 +1,3 @@
+-- Manual "up next" order of the reading list. Items with a position come
+-- first, in ascending order; items without one follow, newest first.
+ALTER TABLE reading_list ADD COLUMN position INTEGER;
@@ -0,0 +1,2 @@
+DROP TRIGGER IF EXISTS blog_revisions_keep;
+DROP TABLE IF EXISTS blog_revisions;
@@ -0,0 +1,29 @@
+-- Posts are sometimes edited after publication. When a re-fetch changes a
+-- blog's content hash, the content it replaces is kept here (compressed, as
+-- in blogs.full_content), so the version that was read can be recovered.
+-- Only the last 20 revisions per blog are kept.
+CREATE TABLE IF NOT EXISTS blog_revisions (
+    id           INTEGER PRIMARY KEY AUTOINCREMENT,
+    blog_id      INTEGER NOT NULL REFERENCES blogs(id) ON DELETE CASCADE,
+    content      BLOB    NOT NULL,
+    content_hash TEXT    NOT NULL,
+    fetched_at   TEXT    NOT NULL,
+    replaced_at  TEXT    NOT NULL DEFAULT (datetime('now'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_blog_revisions_blog ON blog_revisions(blog_id, id);
+
+-- BEFORE, so that content moved to cold storage is still there to copy;
+-- blog_cold_content_supersede deletes it once the new content is written.
+CREATE TRIGGER IF NOT EXISTS blog_revisions_keep BEFORE UPDATE OF content_hash ON blogs
+WHEN old.content_hash IS NOT NULL AND new.content_hash IS NOT old.content_hash BEGIN
+    INSERT INTO blog_revisions (blog_id, content, content_hash, fetched_at)
+    SELECT old.id, c.content, old.content_hash, old.fetched_at
+    FROM (SELECT COALESCE(old.full_content,
+                 (SELECT content FROM blog_cold_content WHERE blog_id = old.id)) AS content) c
+    WHERE c.content IS NOT NULL AND c.content != '';
+    DELETE FROM blog_revisions
+    WHERE blog_id = old.id AND id NOT IN (
+        SELECT id FROM blog_revisions WHERE blog_id = old.id ORDER BY id DESC LIMIT 20
+    );
+END;
@@ -0,0 +1,7 @@
+-- Credentials and API keys for integrations, by name. Values are encrypted
+-- by the store (see crypto.go) and never stored in plain text.
+CREATE TABLE IF NOT EXISTS secrets (
+    name       TEXT PRIMARY KEY,
+    value      BLOB NOT NULL,
+    updated_at TEXT NOT NULL DEFAULT (datetime('now'))
+);
@@ -0,0 +1,4 @@
+DROP TRIGGER IF EXISTS blog_sources_version;
+DROP TRIGGER IF EXISTS reading_list_version;
+ALTER TABLE blog_sources DROP COLUMN version;
+ALTER TABLE reading_list DROP COLUMN version;
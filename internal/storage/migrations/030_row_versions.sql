@@ -0,0 +1,18 @@
+-- Version counters for optimistic concurrency: a PATCH may name the version
+-- it was based on, and is rejected if the row changed since. The counters
+-- go up when the fields people edit change, not on background updates such
+-- as reading progress or fetch health.
+ALTER TABLE reading_list ADD COLUMN version INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE blog_sources ADD COLUMN version INTEGER NOT NULL DEFAULT 1;
+
+CREATE TRIGGER reading_list_version AFTER UPDATE OF status, notes, snoozed_until ON reading_list
+WHEN old.status IS NOT new.status OR old.notes IS NOT new.notes OR old.snoozed_until IS NOT new.snoozed_until
+BEGIN
+    UPDATE reading_list SET version = version + 1 WHERE id = new.id;
+END;
+
+CREATE TRIGGER blog_sources_version AFTER UPDATE OF is_active ON blog_sources
+WHEN old.is_active IS NOT new.is_active
+BEGIN
+    UPDATE blog_sources SET version = version + 1 WHERE id = new.id;
+END;
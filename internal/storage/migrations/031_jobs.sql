@@ -0,0 +1,19 @@
+-- Background jobs, kept in the database so that queued work survives a
+-- restart and finished work can be looked up afterwards. payload and result
+-- are JSON; run_after delays a retry.
+CREATE TABLE IF NOT EXISTS jobs (
+    id           INTEGER PRIMARY KEY AUTOINCREMENT,
+    kind         TEXT NOT NULL,
+    payload      TEXT NOT NULL DEFAULT '{}',
+    status       TEXT NOT NULL DEFAULT 'queued',
+    attempts     INTEGER NOT NULL DEFAULT 0,
+    max_attempts INTEGER NOT NULL DEFAULT 1,
+    result       TEXT,
+    error        TEXT,
+    run_after    TEXT NOT NULL DEFAULT (datetime('now')),
+    created_at   TEXT NOT NULL DEFAULT (datetime('now')),
+    started_at   TEXT,
+    finished_at  TEXT
+);
+
+CREATE INDEX IF NOT EXISTS idx_jobs_status ON jobs(status, run_after);
@@ -0,0 +1,14 @@
+-- Public links to a reading list item's summary, for people who don't run
+-- Apricot. token is the unguessable part of the URL; a revoked link keeps
+-- its row (revoked_at set) so the owner can see what was shared. Links go
+-- with their item.
+CREATE TABLE IF NOT EXISTS share_links (
+    id              INTEGER PRIMARY KEY AUTOINCREMENT,
+    token           TEXT    NOT NULL UNIQUE,
+    reading_list_id INTEGER NOT NULL REFERENCES reading_list(id) ON DELETE CASCADE,
+    include_notes   INTEGER NOT NULL DEFAULT 1,
+    created_at      TEXT    NOT NULL DEFAULT (datetime('now')),
+    revoked_at      TEXT
+);
+
+CREATE INDEX IF NOT EXISTS idx_share_links_item ON share_links(reading_list_id);
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_bookmark_links_item;
+DROP TABLE IF EXISTS bookmark_links;
@@ -0,0 +1,19 @@
+-- Which reading list item each bookmark in a Raindrop.io or Pinboard
+-- account mirrors, and what both looked like after the last sync, as
+-- fingerprints of their notes, tags, and read state, so the next sync can
+-- tell which side changed. There is no foreign key: a link outlives its
+-- item, so the sync can tell that the item was deleted; url guards
+-- against the id being reused.
+CREATE TABLE IF NOT EXISTS bookmark_links (
+    id              INTEGER PRIMARY KEY AUTOINCREMENT,
+    service         TEXT    NOT NULL,
+    remote_id       TEXT    NOT NULL,
+    reading_list_id INTEGER NOT NULL,
+    url             TEXT    NOT NULL,
+    local_hash      TEXT    NOT NULL,
+    remote_hash     TEXT    NOT NULL,
+    synced_at       TEXT    NOT NULL DEFAULT (datetime('now')),
+    UNIQUE (service, remote_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_bookmark_links_item ON bookmark_links(reading_list_id);
@@ -0,0 +1 @@
+ALTER TABLE discovery_sessions DROP COLUMN fallback;
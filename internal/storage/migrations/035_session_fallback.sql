@@ -0,0 +1,3 @@
+-- Discovery sessions whose posts were ranked by recency and topic keywords
+-- because AI ranking failed.
+ALTER TABLE discovery_sessions ADD COLUMN fallback INTEGER NOT NULL DEFAULT 0;
@@ -0,0 +1,9 @@
+-- The cleaned article HTML the reader view shows, extracted on first view.
+-- content_hash is the blog's when the page was extracted, so a post whose
+-- content has changed since is extracted again.
+CREATE TABLE IF NOT EXISTS reader_content (
+    blog_id      INTEGER PRIMARY KEY REFERENCES blogs(id) ON DELETE CASCADE,
+    html         BLOB    NOT NULL,
+    content_hash TEXT,
+    extracted_at TEXT    NOT NULL DEFAULT (datetime('now'))
+);
@@ -0,0 +1,2 @@
+ALTER TABLE reading_list DROP COLUMN last_read_at;
+ALTER TABLE reading_list DROP COLUMN scroll_position;
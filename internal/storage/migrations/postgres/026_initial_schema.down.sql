@@ -0,0 +1,9 @@
+-- Drops the whole schema; dropping the tables drops their triggers.
+DROP TABLE IF EXISTS blog_search, blog_revisions, note_revisions, blog_cold_content,
+    blog_feedback, research_reports, learning_path_items, learning_paths,
+    ai_response_cache, reading_list_tags, tags, discovery_sessions, reading_list,
+    preferences, blog_summaries, blogs, blog_sources;
+
+DROP FUNCTION IF EXISTS blog_search_sync(), refresh_blog_search(bigint),
+    reading_list_notes_keep(), blog_revisions_keep(), blog_summaries_stale(),
+    blog_cold_content_supersede(), json_each(text), content_text(bytea), datetime(text);
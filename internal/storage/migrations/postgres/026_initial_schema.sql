@@ -0,0 +1,322 @@
+-- The Postgres schema as of SQLite migration 026, which it is numbered after
+-- so that schema versions (and archives) match across backends.
+--
+-- It mirrors the SQLite tables closely so the store can run the same
+-- queries on both: timestamps are TEXT in 'YYYY-MM-DD HH24:MI:SS' UTC form,
+-- flags are 0/1 INTEGERs, and the functions below stand in for the SQLite
+-- built-ins and content.go functions the queries call. Article text is
+-- stored as plain UTF-8 in BYTEA columns; Postgres compresses large values
+-- itself.
+
+-- datetime('now') returns the current UTC time in the stored text form.
+CREATE FUNCTION datetime(t text) RETURNS text
+LANGUAGE sql STABLE AS $$
+    SELECT to_char(t::timestamptz AT TIME ZONE 'UTC', 'YYYY-MM-DD HH24:MI:SS')
+$$;
+
+-- content_text(x) returns the text of a stored content value.
+CREATE FUNCTION content_text(x bytea) RETURNS text
+LANGUAGE sql IMMUTABLE AS $$
+    SELECT convert_from(x, 'UTF8')
+$$;
+
+-- json_each(x) returns the numbers in a JSON array stored as text, like
+-- SQLite's json_each over a list of blog IDs.
+CREATE FUNCTION json_each(x text) RETURNS TABLE (value bigint)
+LANGUAGE sql IMMUTABLE AS $$
+    SELECT e::bigint FROM json_array_elements_text(x::json) AS e
+$$;
+
+CREATE TABLE blog_sources (
+    id            BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    name          TEXT    NOT NULL,
+    company       TEXT    NOT NULL,
+    feed_url      TEXT    NOT NULL UNIQUE,
+    site_url      TEXT    NOT NULL,
+    is_active     INTEGER NOT NULL DEFAULT 1,
+    created_at    TEXT    NOT NULL DEFAULT datetime('now'),
+    last_fetch_at TEXT,
+    last_fetch_ok INTEGER NOT NULL DEFAULT 1,
+    last_error    TEXT
+);
+
+-- Sentinel source for user-added blogs.
+INSERT INTO blog_sources (name, company, feed_url, site_url, is_active)
+VALUES ('Other Blog', 'Custom', 'custom://user-added', 'https://custom', 0);
+
+CREATE TABLE blogs (
+    id                   BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    source_id            BIGINT  NOT NULL REFERENCES blog_sources(id),
+    title                TEXT    NOT NULL,
+    url                  TEXT    NOT NULL UNIQUE,
+    description          TEXT,
+    full_content         BYTEA,
+    published_at         TEXT,
+    fetched_at           TEXT    NOT NULL DEFAULT datetime('now'),
+    content_hash         TEXT,
+    created_at           TEXT    NOT NULL DEFAULT datetime('now'),
+    custom_source        TEXT,
+    reading_time_minutes INTEGER,
+    difficulty           TEXT,
+    rewritten_title      TEXT
+);
+
+CREATE INDEX idx_blogs_published ON blogs(published_at DESC);
+CREATE INDEX idx_blogs_source ON blogs(source_id);
+CREATE INDEX idx_blogs_difficulty ON blogs(difficulty);
+
+CREATE TABLE blog_summaries (
+    id         BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    blog_id    BIGINT  NOT NULL UNIQUE REFERENCES blogs(id),
+    summary    TEXT    NOT NULL,
+    model_used TEXT    NOT NULL,
+    created_at TEXT    NOT NULL DEFAULT datetime('now'),
+    difficulty TEXT,
+    category   TEXT,
+    stale      INTEGER NOT NULL DEFAULT 0
+);
+
+CREATE INDEX idx_blog_summaries_category ON blog_summaries(category);
+
+CREATE TABLE preferences (
+    id         BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    key        TEXT NOT NULL UNIQUE,
+    value      TEXT NOT NULL,
+    updated_at TEXT NOT NULL DEFAULT datetime('now')
+);
+
+CREATE TABLE reading_list (
+    id               BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    blog_id          BIGINT  NOT NULL UNIQUE REFERENCES blogs(id),
+    status           TEXT    NOT NULL DEFAULT 'unread',
+    notes            TEXT,
+    added_at         TEXT    NOT NULL DEFAULT datetime('now'),
+    read_at          TEXT,
+    progress         INTEGER NOT NULL DEFAULT 0,
+    archived_at      TEXT,
+    reviews_done     INTEGER NOT NULL DEFAULT 0,
+    last_reviewed_at TEXT,
+    snoozed_until    TEXT,
+    position         INTEGER
+);
+
+CREATE INDEX idx_reading_status ON reading_list(status);
+CREATE INDEX idx_reading_list_snoozed_until ON reading_list(snoozed_until);
+
+CREATE TABLE discovery_sessions (
+    id                   BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    preferences_snapshot TEXT    NOT NULL,
+    blogs_considered     INTEGER NOT NULL,
+    blogs_selected       TEXT    NOT NULL,
+    model_used           TEXT    NOT NULL,
+    input_tokens         INTEGER,
+    output_tokens        INTEGER,
+    created_at           TEXT    NOT NULL DEFAULT datetime('now'),
+    results_json         TEXT,
+    failed_feeds_json    TEXT
+);
+
+CREATE TABLE tags (
+    id          BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    name        TEXT NOT NULL UNIQUE,
+    created_at  TEXT NOT NULL DEFAULT datetime('now'),
+    color       TEXT,
+    description TEXT
+);
+
+CREATE TABLE reading_list_tags (
+    reading_list_id BIGINT NOT NULL REFERENCES reading_list(id) ON DELETE CASCADE,
+    tag_id          BIGINT NOT NULL REFERENCES tags(id) ON DELETE CASCADE,
+    PRIMARY KEY (reading_list_id, tag_id)
+);
+
+CREATE INDEX idx_reading_list_tags_tag ON reading_list_tags(tag_id);
+
+CREATE TABLE ai_response_cache (
+    cache_key  TEXT PRIMARY KEY,
+    operation  TEXT NOT NULL,
+    model      TEXT NOT NULL,
+    response   TEXT NOT NULL,
+    created_at TEXT NOT NULL DEFAULT datetime('now')
+);
+
+CREATE TABLE learning_paths (
+    id          BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    title       TEXT NOT NULL,
+    description TEXT,
+    topic       TEXT,
+    created_at  TEXT NOT NULL DEFAULT datetime('now'),
+    updated_at  TEXT NOT NULL DEFAULT datetime('now')
+);
+
+CREATE TABLE learning_path_items (
+    path_id         BIGINT  NOT NULL REFERENCES learning_paths(id) ON DELETE CASCADE,
+    reading_list_id BIGINT  NOT NULL REFERENCES reading_list(id) ON DELETE CASCADE,
+    position        INTEGER NOT NULL,
+    note            TEXT,
+    PRIMARY KEY (path_id, reading_list_id)
+);
+
+CREATE INDEX idx_learning_path_items_order ON learning_path_items(path_id, position);
+
+CREATE TABLE research_reports (
+    id           BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    question     TEXT NOT NULL,
+    answer       TEXT NOT NULL,
+    sources_json TEXT NOT NULL,
+    model_used   TEXT,
+    created_at   TEXT NOT NULL DEFAULT datetime('now')
+);
+
+CREATE INDEX idx_research_reports_created ON research_reports(created_at);
+
+CREATE TABLE blog_feedback (
+    blog_id    BIGINT  PRIMARY KEY REFERENCES blogs(id) ON DELETE CASCADE,
+    rating     INTEGER NOT NULL CHECK (rating IN (-1, 1)),
+    created_at TEXT    NOT NULL DEFAULT datetime('now')
+);
+
+CREATE TABLE blog_cold_content (
+    blog_id     BIGINT PRIMARY KEY REFERENCES blogs(id) ON DELETE CASCADE,
+    content     BYTEA  NOT NULL,
+    archived_at TEXT   NOT NULL DEFAULT datetime('now')
+);
+
+CREATE TABLE note_revisions (
+    id              BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    reading_list_id BIGINT NOT NULL REFERENCES reading_list(id) ON DELETE CASCADE,
+    notes           TEXT   NOT NULL,
+    created_at      TEXT   NOT NULL DEFAULT datetime('now')
+);
+
+CREATE INDEX idx_note_revisions_item ON note_revisions(reading_list_id, id);
+
+CREATE TABLE blog_revisions (
+    id           BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    blog_id      BIGINT NOT NULL REFERENCES blogs(id) ON DELETE CASCADE,
+    content      BYTEA  NOT NULL,
+    content_hash TEXT   NOT NULL,
+    fetched_at   TEXT   NOT NULL,
+    replaced_at  TEXT   NOT NULL DEFAULT datetime('now')
+);
+
+CREATE INDEX idx_blog_revisions_blog ON blog_revisions(blog_id, id);
+
+-- Content written back to blogs supersedes its cold copy.
+CREATE FUNCTION blog_cold_content_supersede() RETURNS trigger
+LANGUAGE plpgsql AS $$
+BEGIN
+    DELETE FROM blog_cold_content WHERE blog_id = new.id;
+    RETURN NULL;
+END
+$$;
+
+CREATE TRIGGER blog_cold_content_supersede AFTER UPDATE OF full_content ON blogs
+FOR EACH ROW WHEN (new.full_content IS NOT NULL AND new.full_content <> ''::bytea)
+EXECUTE FUNCTION blog_cold_content_supersede();
+
+-- A changed content hash flags the blog's summary as stale.
+CREATE FUNCTION blog_summaries_stale() RETURNS trigger
+LANGUAGE plpgsql AS $$
+BEGIN
+    UPDATE blog_summaries SET stale = 1 WHERE blog_id = new.id;
+    RETURN NULL;
+END
+$$;
+
+CREATE TRIGGER blog_summaries_stale AFTER UPDATE OF content_hash ON blogs
+FOR EACH ROW WHEN (old.content_hash IS NOT NULL AND new.content_hash IS DISTINCT FROM old.content_hash)
+EXECUTE FUNCTION blog_summaries_stale();
+
+-- A changed content hash keeps the replaced content as a revision, from
+-- cold storage if it was moved there. Only the last 20 are kept.
+CREATE FUNCTION blog_revisions_keep() RETURNS trigger
+LANGUAGE plpgsql AS $$
+BEGIN
+    INSERT INTO blog_revisions (blog_id, content, content_hash, fetched_at)
+    SELECT old.id, c.content, old.content_hash, old.fetched_at
+    FROM (SELECT COALESCE(old.full_content,
+                 (SELECT content FROM blog_cold_content WHERE blog_id = old.id)) AS content) c
+    WHERE c.content IS NOT NULL AND c.content <> ''::bytea;
+    DELETE FROM blog_revisions
+    WHERE blog_id = old.id AND id NOT IN (
+        SELECT id FROM blog_revisions WHERE blog_id = old.id ORDER BY id DESC LIMIT 20
+    );
+    RETURN new;
+END
+$$;
+
+CREATE TRIGGER blog_revisions_keep BEFORE UPDATE OF content_hash ON blogs
+FOR EACH ROW WHEN (old.content_hash IS NOT NULL AND new.content_hash IS DISTINCT FROM old.content_hash)
+EXECUTE FUNCTION blog_revisions_keep();
+
+-- Every saved version of a note is kept in note_revisions, up to the last
+-- 50 per item.
+CREATE FUNCTION reading_list_notes_keep() RETURNS trigger
+LANGUAGE plpgsql AS $$
+BEGIN
+    INSERT INTO note_revisions (reading_list_id, notes) VALUES (new.id, new.notes);
+    DELETE FROM note_revisions
+    WHERE reading_list_id = new.id AND id NOT IN (
+        SELECT id FROM note_revisions WHERE reading_list_id = new.id ORDER BY id DESC LIMIT 50
+    );
+    RETURN NULL;
+END
+$$;
+
+CREATE TRIGGER reading_list_notes_insert AFTER INSERT ON reading_list
+FOR EACH ROW WHEN (new.notes IS NOT NULL AND new.notes <> '')
+EXECUTE FUNCTION reading_list_notes_keep();
+
+CREATE TRIGGER reading_list_notes_update AFTER UPDATE OF notes ON reading_list
+FOR EACH ROW WHEN (new.notes IS NOT NULL AND new.notes <> '' AND new.notes IS DISTINCT FROM old.notes)
+EXECUTE FUNCTION reading_list_notes_keep();
+
+-- Full-text search. blog_search holds one weighted document per blog over
+-- its title (A), description (B), reading list notes and summary (C), and
+-- article text (D), kept current by the triggers below. Like the SQLite FTS
+-- index, it does not cover content moved to cold storage.
+CREATE TABLE blog_search (
+    blog_id  BIGINT   PRIMARY KEY REFERENCES blogs(id) ON DELETE CASCADE,
+    document tsvector NOT NULL
+);
+
+CREATE INDEX idx_blog_search_document ON blog_search USING GIN (document);
+
+CREATE FUNCTION refresh_blog_search(blog bigint) RETURNS void
+LANGUAGE sql AS $$
+    INSERT INTO blog_search (blog_id, document)
+    SELECT b.id,
+           setweight(to_tsvector('english', b.title), 'A') ||
+           setweight(to_tsvector('english', COALESCE(b.description, '')), 'B') ||
+           setweight(to_tsvector('english', COALESCE(rl.notes, '') || ' ' || COALESCE(s.summary, '')), 'C') ||
+           setweight(to_tsvector('english', COALESCE(content_text(b.full_content), '')), 'D')
+    FROM blogs b
+    LEFT JOIN reading_list rl ON rl.blog_id = b.id
+    LEFT JOIN blog_summaries s ON s.blog_id = b.id
+    WHERE b.id = blog
+    ON CONFLICT (blog_id) DO UPDATE SET document = excluded.document
+$$;
+
+CREATE FUNCTION blog_search_sync() RETURNS trigger
+LANGUAGE plpgsql AS $$
+BEGIN
+    IF TG_TABLE_NAME = 'blogs' THEN
+        PERFORM refresh_blog_search(new.id);
+    ELSIF TG_OP = 'DELETE' THEN
+        PERFORM refresh_blog_search(old.blog_id);
+    ELSE
+        PERFORM refresh_blog_search(new.blog_id);
+    END IF;
+    RETURN NULL;
+END
+$$;
+
+CREATE TRIGGER blog_search_blogs AFTER INSERT OR UPDATE ON blogs
+FOR EACH ROW EXECUTE FUNCTION blog_search_sync();
+
+CREATE TRIGGER blog_search_notes AFTER INSERT OR UPDATE OF notes OR DELETE ON reading_list
+FOR EACH ROW EXECUTE FUNCTION blog_search_sync();
+
+CREATE TRIGGER blog_search_summaries AFTER INSERT OR UPDATE OF summary OR DELETE ON blog_summaries
+FOR EACH ROW EXECUTE FUNCTION blog_search_sync();
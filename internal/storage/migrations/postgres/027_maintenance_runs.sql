@@ -0,0 +1,6 @@
+-- When each database maintenance task (vacuum, maintenance) last ran, for
+-- the admin stats.
+CREATE TABLE IF NOT EXISTS maintenance_runs (
+    task   TEXT PRIMARY KEY,
+    ran_at TEXT NOT NULL DEFAULT datetime('now')
+);
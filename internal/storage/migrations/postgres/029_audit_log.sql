@@ -0,0 +1,13 @@
+-- Changes to configuration and deletions of content, with the values before
+-- and after as JSON, to answer "what happened to this" long afterwards.
+CREATE TABLE IF NOT EXISTS audit_log (
+    id          BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    action      TEXT NOT NULL,
+    entity      TEXT NOT NULL,
+    entity_id   TEXT NOT NULL,
+    before_json TEXT,
+    after_json  TEXT,
+    created_at  TEXT NOT NULL DEFAULT datetime('now')
+);
+
+CREATE INDEX IF NOT EXISTS idx_audit_log_entity ON audit_log(entity, entity_id, id);
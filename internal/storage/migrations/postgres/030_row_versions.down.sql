@@ -0,0 +1,5 @@
+DROP TRIGGER IF EXISTS blog_sources_version ON blog_sources;
+DROP TRIGGER IF EXISTS reading_list_version ON reading_list;
+DROP FUNCTION IF EXISTS bump_version();
+ALTER TABLE blog_sources DROP COLUMN version;
+ALTER TABLE reading_list DROP COLUMN version;
@@ -0,0 +1,23 @@
+-- Version counters for optimistic concurrency: a PATCH may name the version
+-- it was based on, and is rejected if the row changed since. The counters
+-- go up when the fields people edit change, not on background updates such
+-- as reading progress or fetch health.
+ALTER TABLE reading_list ADD COLUMN version INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE blog_sources ADD COLUMN version INTEGER NOT NULL DEFAULT 1;
+
+CREATE FUNCTION bump_version() RETURNS trigger
+LANGUAGE plpgsql AS $$
+BEGIN
+    new.version := old.version + 1;
+    RETURN new;
+END
+$$;
+
+CREATE TRIGGER reading_list_version BEFORE UPDATE OF status, notes, snoozed_until ON reading_list
+FOR EACH ROW WHEN (old.status IS DISTINCT FROM new.status OR old.notes IS DISTINCT FROM new.notes
+                   OR old.snoozed_until IS DISTINCT FROM new.snoozed_until)
+EXECUTE FUNCTION bump_version();
+
+CREATE TRIGGER blog_sources_version BEFORE UPDATE OF is_active ON blog_sources
+FOR EACH ROW WHEN (old.is_active IS DISTINCT FROM new.is_active)
+EXECUTE FUNCTION bump_version();
@@ -0,0 +1,15 @@
+-- The Notion page each reading list item was exported to, per database,
+-- with a hash of the property values last sent, so an export only updates
+-- pages whose item changed. There is no foreign key: a row outlives its
+-- item, so the export can archive the page; url guards against the id
+-- being reused.
+CREATE TABLE IF NOT EXISTS notion_pages (
+    id              BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    database_id     TEXT    NOT NULL,
+    reading_list_id BIGINT  NOT NULL,
+    url             TEXT    NOT NULL,
+    page_id         TEXT    NOT NULL,
+    hash            TEXT    NOT NULL,
+    synced_at       TEXT    NOT NULL DEFAULT datetime('now'),
+    UNIQUE (database_id, reading_list_id)
+);
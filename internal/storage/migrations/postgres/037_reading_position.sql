@@ -0,0 +1,5 @@
+-- Where the reader left each item, as a fraction of the way down the page
+-- (finer than progress, a whole percentage), and when it was last read, so
+-- another device can resume there.
+ALTER TABLE reading_list ADD COLUMN scroll_position DOUBLE PRECISION;
+ALTER TABLE reading_list ADD COLUMN last_read_at TEXT;
@@ -0,0 +1,166 @@
+package storage
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hoanghai1803/apricot/internal/models"
+)
+
+// seedMigrationData fills every kind of record the migrations touch: a saved
+// post with notes, a summary, tags, and feedback, and an unsaved post moved
+// to cold storage.
+func seedMigrationData(t *testing.T, store *SQLiteStore) {
+	t.Helper()
+	ctx := context.Background()
+	sourceID := seedTestSource(t, store)
+
+	saved, err := store.UpsertBlog(ctx, &models.Blog{
+		SourceID: sourceID, Title: "Consensus in practice", URL: "https://test.com/raft",
+		FullContent: "Raft elects a leader.", ContentHash: "h1", FetchedAt: time.Now(),
+	})
+	if err != nil {
+		t.Fatalf("seeding blog: %v", err)
+	}
+	old := time.Now().AddDate(-2, 0, 0)
+	if _, err := store.UpsertBlog(ctx, &models.Blog{
+		SourceID: sourceID, Title: "Old post", URL: "https://test.com/old",
+		FullContent: "Cold text.", PublishedAt: &old, FetchedAt: old,
+	}); err != nil {
+		t.Fatalf("seeding old blog: %v", err)
+	}
+	if err := store.AddToReadingList(ctx, saved); err != nil {
+		t.Fatalf("adding to reading list: %v", err)
+	}
+	id, err := store.GetReadingListIDByBlogID(ctx, saved)
+	if err != nil {
+		t.Fatalf("getting reading list id: %v", err)
+	}
+	if err := store.UpdateReadingListNotes(ctx, id, "leader election"); err != nil {
+		t.Fatalf("setting notes: %v", err)
+	}
+	if err := store.UpdateReadingListStatus(ctx, id, "archived"); err != nil {
+		t.Fatalf("archiving: %v", err)
+	}
+	if err := store.AddTagToItem(ctx, id, "distributed"); err != nil {
+		t.Fatalf("tagging: %v", err)
+	}
+	if err := store.UpsertSummary(ctx, &models.BlogSummary{BlogID: saved, Summary: "About Raft.", ModelUsed: "mock"}); err != nil {
+		t.Fatalf("summarizing: %v", err)
+	}
+	if err := store.SetBlogFeedback(ctx, saved, 1); err != nil {
+		t.Fatalf("rating: %v", err)
+	}
+	if _, err := store.ArchiveColdContent(ctx, time.Now().AddDate(-1, 0, 0)); err != nil {
+		t.Fatalf("archiving cold content: %v", err)
+	}
+}
+
+func TestMigrationStatus(t *testing.T) {
+	store := newTestStore(t)
+
+	status, err := store.MigrationStatus(context.Background())
+	if err != nil {
+		t.Fatalf("MigrationStatus() error: %v", err)
+	}
+	if status.Version != 37 || status.Pending != 0 || len(status.Migrations) != 37 {
+		t.Fatalf("status = version %d, %d pending, %d migrations; want 37, 0, 37",
+			status.Version, status.Pending, len(status.Migrations))
+	}
+	first := status.Migrations[0]
+	if first.Version != 1 || first.Name != "001_initial_schema" || !first.Applied || first.AppliedAt == nil {
+		t.Errorf("first migration = %+v", first)
+	}
+	for _, m := range status.Migrations {
+		if !m.Reversible {
+			t.Errorf("migration %s has no down migration", m.Name)
+		}
+	}
+}
+
+func TestRollbackTo_Partial(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+	seedMigrationData(t, store)
+
+	if err := store.RollbackTo(ctx, 23); err != nil {
+		t.Fatalf("RollbackTo(23) error: %v", err)
+	}
+	status, err := store.MigrationStatus(ctx)
+	if err != nil {
+		t.Fatalf("MigrationStatus() error: %v", err)
+	}
+	if status.Version != 23 || status.Pending != 14 {
+		t.Errorf("after rollback: version %d, %d pending; want 23, 14", status.Version, status.Pending)
+	}
+	if _, err := store.db.Exec(`SELECT position FROM reading_list`); err == nil {
+		t.Error("reading_list.position still exists after rolling back 025")
+	}
+
+	// Reapplying brings the schema back, keeping the data.
+	if err := RunMigrations(store.db); err != nil {
+		t.Fatalf("RunMigrations() after rollback error: %v", err)
+	}
+	items, err := store.GetReadingList(ctx, "archived")
+	if err != nil {
+		t.Fatalf("GetReadingList() error: %v", err)
+	}
+	if len(items) != 1 || items[0].Notes == nil || *items[0].Notes != "leader election" {
+		t.Errorf("reading list after reapplying = %+v, want the archived item with notes", items)
+	}
+}
+
+func TestRollbackTo_AllTheWay(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+	seedMigrationData(t, store)
+
+	// Every down migration runs against real data, in order.
+	for v := 30; v >= 0; v-- {
+		if err := store.RollbackTo(ctx, v); err != nil {
+			t.Fatalf("RollbackTo(%d) error: %v", v, err)
+		}
+	}
+
+	var tables []string
+	rows, err := store.db.Query(`SELECT name FROM sqlite_master WHERE type IN ('table', 'view') ORDER BY name`)
+	if err != nil {
+		t.Fatalf("listing tables: %v", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			t.Fatalf("scanning table name: %v", err)
+		}
+		if name != "schema_migrations" && name != "sqlite_sequence" {
+			tables = append(tables, name)
+		}
+	}
+	if len(tables) > 0 {
+		t.Errorf("tables left after rolling back everything: %s", strings.Join(tables, ", "))
+	}
+
+	// The schema can be rebuilt from scratch.
+	if err := RunMigrations(store.db); err != nil {
+		t.Fatalf("RunMigrations() after full rollback error: %v", err)
+	}
+	seedMigrationData(t, store)
+	results, err := store.SearchBlogs(ctx, "leader", 10, 0)
+	if err != nil {
+		t.Fatalf("SearchBlogs() error: %v", err)
+	}
+	if len(results) != 1 {
+		t.Errorf("SearchBlogs() = %d results, want 1", len(results))
+	}
+}
+
+func TestRollbackTo_InvalidVersion(t *testing.T) {
+	store := newTestStore(t)
+
+	if err := store.RollbackTo(context.Background(), -1); err == nil {
+		t.Fatal("RollbackTo(-1) error = nil, want error")
+	}
+}
@@ -0,0 +1,73 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hoanghai1803/apricot/internal/models"
+)
+
+// ListNotionPages returns the pages reading list items were exported to in
+// Notion database databaseID, oldest first.
+func (s *sqlStore) ListNotionPages(ctx context.Context, databaseID string) ([]models.NotionPage, error) {
+	rows, err := s.rdb.QueryContext(ctx,
+		`SELECT id, database_id, reading_list_id, url, page_id, hash, synced_at
+		 FROM notion_pages
+		 WHERE database_id = ?
+		 ORDER BY id`, databaseID)
+	if err != nil {
+		return nil, fmt.Errorf("listing notion pages: %w", err)
+	}
+	defer rows.Close()
+
+	pages := []models.NotionPage{}
+	for rows.Next() {
+		var (
+			page     models.NotionPage
+			syncedAt string
+		)
+		if err := rows.Scan(&page.ID, &page.DatabaseID, &page.ReadingListID, &page.URL,
+			&page.PageID, &page.Hash, &syncedAt); err != nil {
+			return nil, fmt.Errorf("scanning notion page: %w", err)
+		}
+		page.SyncedAt = parseTime(syncedAt)
+		pages = append(pages, page)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating notion pages: %w", err)
+	}
+	return pages, nil
+}
+
+// SaveNotionPage records page, replacing the item's previous page in the
+// same database, and sets its ID and SyncedAt.
+func (s *sqlStore) SaveNotionPage(ctx context.Context, page *models.NotionPage) error {
+	var syncedAt string
+	err := s.db.QueryRowContext(ctx,
+		`INSERT INTO notion_pages (database_id, reading_list_id, url, page_id, hash)
+		 VALUES (?, ?, ?, ?, ?)
+		 ON CONFLICT(database_id, reading_list_id) DO UPDATE SET
+		     url = excluded.url,
+		     page_id = excluded.page_id,
+		     hash = excluded.hash,
+		     synced_at = datetime('now')
+		 RETURNING id, synced_at`,
+		page.DatabaseID, page.ReadingListID, page.URL, page.PageID, page.Hash,
+	).Scan(&page.ID, &syncedAt)
+	if err != nil {
+		return fmt.Errorf("saving notion page: %w", err)
+	}
+	page.SyncedAt = parseTime(syncedAt)
+	return nil
+}
+
+// DeleteNotionPage forgets the page of reading list item readingListID in
+// database databaseID. Deleting a missing record is a no-op.
+func (s *sqlStore) DeleteNotionPage(ctx context.Context, databaseID string, readingListID int64) error {
+	if _, err := s.db.ExecContext(ctx,
+		`DELETE FROM notion_pages WHERE database_id = ? AND reading_list_id = ?`,
+		databaseID, readingListID); err != nil {
+		return fmt.Errorf("deleting notion page: %w", err)
+	}
+	return nil
+}
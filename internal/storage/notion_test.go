@@ -0,0 +1,49 @@
+package storage
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hoanghai1803/apricot/internal/models"
+)
+
+func TestNotionPages(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+
+	page := &models.NotionPage{DatabaseID: "db1", ReadingListID: 1, URL: "https://test.com/a", PageID: "p1", Hash: "h1"}
+	if err := store.SaveNotionPage(ctx, page); err != nil {
+		t.Fatalf("SaveNotionPage() error: %v", err)
+	}
+	if page.ID == 0 || page.SyncedAt.IsZero() {
+		t.Errorf("SaveNotionPage() left %+v without an ID or sync time", page)
+	}
+	if err := store.SaveNotionPage(ctx, &models.NotionPage{DatabaseID: "db2", ReadingListID: 1,
+		URL: "https://test.com/a", PageID: "other", Hash: "h"}); err != nil {
+		t.Fatalf("SaveNotionPage() error: %v", err)
+	}
+
+	// Saving the item's page again replaces it.
+	again := *page
+	again.PageID, again.Hash = "p2", "h2"
+	if err := store.SaveNotionPage(ctx, &again); err != nil {
+		t.Fatalf("SaveNotionPage() error: %v", err)
+	}
+	pages, err := store.ListNotionPages(ctx, "db1")
+	if err != nil {
+		t.Fatalf("ListNotionPages() error: %v", err)
+	}
+	if len(pages) != 1 || pages[0].ID != page.ID || pages[0].PageID != "p2" || pages[0].Hash != "h2" {
+		t.Errorf("ListNotionPages(db1) = %+v, want the one page, updated", pages)
+	}
+
+	if err := store.DeleteNotionPage(ctx, "db1", 1); err != nil {
+		t.Fatalf("DeleteNotionPage() error: %v", err)
+	}
+	if pages, _ := store.ListNotionPages(ctx, "db1"); len(pages) != 0 {
+		t.Errorf("got %d db1 pages after deleting, want none", len(pages))
+	}
+	if pages, _ := store.ListNotionPages(ctx, "db2"); len(pages) != 1 {
+		t.Errorf("got %d db2 pages, want the other database's page kept", len(pages))
+	}
+}
@@ -0,0 +1,286 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/hoanghai1803/apricot/internal/models"
+)
+
+// CreateLearningPath inserts a learning path with the given steps, in order,
+// inside a single transaction. Returns the new path ID.
+func (s *sqlStore) CreateLearningPath(ctx context.Context, path *models.LearningPath, steps []models.LearningPathStep) (int64, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("beginning transaction: %w", err)
+	}
+	defer tx.Rollback() //nolint:errcheck // rollback after commit is a no-op
+
+	var id int64
+	if err := tx.QueryRowContext(ctx,
+		`INSERT INTO learning_paths (title, description, topic) VALUES (?, ?, ?) RETURNING id`,
+		path.Title, nullableString(path.Description), nullableString(path.Topic),
+	).Scan(&id); err != nil {
+		return 0, fmt.Errorf("creating learning path: %w", err)
+	}
+
+	if err := insertPathSteps(ctx, tx, id, steps); err != nil {
+		return 0, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("committing transaction: %w", err)
+	}
+	return id, nil
+}
+
+// insertPathSteps inserts steps as the items of path id, numbering positions
+// from zero.
+func insertPathSteps(ctx context.Context, tx *sql.Tx, id int64, steps []models.LearningPathStep) error {
+	for i, step := range steps {
+		_, err := tx.ExecContext(ctx,
+			`INSERT INTO learning_path_items (path_id, reading_list_id, position, note)
+			 VALUES (?, ?, ?, ?)`,
+			id, step.ReadingListID, i, nullableString(step.Note),
+		)
+		if err != nil {
+			if isUniqueViolation(err) {
+				return fmt.Errorf("reading list item %d appears more than once in the path", step.ReadingListID)
+			}
+			if isForeignKeyViolation(err) {
+				return fmt.Errorf("reading list item %d does not exist", step.ReadingListID)
+			}
+			return fmt.Errorf("adding learning path item: %w", err)
+		}
+	}
+	return nil
+}
+
+// learningPathSelect selects path columns plus item and completion counts.
+// Rows are scanned with scanLearningPath.
+const learningPathSelect = `
+		SELECT p.id, p.title, p.description, p.topic, p.created_at, p.updated_at,
+			   COUNT(i.reading_list_id),
+			   COALESCE(SUM(CASE WHEN rl.status IN ('read', 'archived') THEN 1 ELSE 0 END), 0)
+		FROM learning_paths p
+		LEFT JOIN learning_path_items i ON i.path_id = p.id
+		LEFT JOIN reading_list rl ON rl.id = i.reading_list_id`
+
+// scanLearningPath scans a row produced by learningPathSelect.
+func scanLearningPath(row scanner) (*models.LearningPath, error) {
+	var (
+		path        models.LearningPath
+		description sql.NullString
+		topic       sql.NullString
+		createdAt   string
+		updatedAt   string
+	)
+	if err := row.Scan(&path.ID, &path.Title, &description, &topic, &createdAt, &updatedAt,
+		&path.TotalItems, &path.CompletedItems); err != nil {
+		return nil, err
+	}
+	path.Description = description.String
+	path.Topic = topic.String
+	path.CreatedAt = parseTime(createdAt)
+	path.UpdatedAt = parseTime(updatedAt)
+	return &path, nil
+}
+
+// ListLearningPaths returns all learning paths with their item and
+// completion counts but without items, most recently updated first.
+func (s *sqlStore) ListLearningPaths(ctx context.Context) ([]models.LearningPath, error) {
+	rows, err := s.rdb.QueryContext(ctx, learningPathSelect+`
+		GROUP BY p.id
+		ORDER BY p.updated_at DESC, p.id DESC`)
+	if err != nil {
+		return nil, fmt.Errorf("listing learning paths: %w", err)
+	}
+	defer rows.Close()
+
+	paths := []models.LearningPath{}
+	for rows.Next() {
+		path, err := scanLearningPath(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scanning learning path: %w", err)
+		}
+		paths = append(paths, *path)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating learning paths: %w", err)
+	}
+	return paths, nil
+}
+
+// GetLearningPath returns a learning path with its items in order.
+// Returns ErrNotFound if the path does not exist.
+func (s *sqlStore) GetLearningPath(ctx context.Context, id int64) (*models.LearningPath, error) {
+	path, err := scanLearningPath(s.rdb.QueryRowContext(ctx, learningPathSelect+`
+		WHERE p.id = ?
+		GROUP BY p.id`, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("getting learning path: %w", err)
+	}
+
+	rows, err := s.rdb.QueryContext(ctx, readingListSelect+`
+		JOIN learning_path_items lpi ON lpi.reading_list_id = rl.id
+		WHERE lpi.path_id = ?
+		ORDER BY lpi.position`, id)
+	if err != nil {
+		return nil, fmt.Errorf("querying learning path items: %w", err)
+	}
+	defer rows.Close()
+
+	var items []models.ReadingListItem
+	for rows.Next() {
+		item, err := scanReadingListItem(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scanning learning path item: %w", err)
+		}
+		item.Tags = []string{}
+		items = append(items, *item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating learning path items: %w", err)
+	}
+	if err := s.loadTagsForItems(ctx, items); err != nil {
+		return nil, fmt.Errorf("loading tags: %w", err)
+	}
+
+	notes, err := s.learningPathNotes(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	path.Items = make([]models.LearningPathItem, len(items))
+	for i, item := range items {
+		path.Items[i] = models.LearningPathItem{
+			Position: i,
+			Note:     notes[item.ID],
+			Item:     item,
+		}
+	}
+	return path, nil
+}
+
+// learningPathNotes returns the notes of a path's items keyed by reading
+// list item ID.
+func (s *sqlStore) learningPathNotes(ctx context.Context, id int64) (map[int64]string, error) {
+	rows, err := s.rdb.QueryContext(ctx,
+		`SELECT reading_list_id, note FROM learning_path_items
+		 WHERE path_id = ? AND note IS NOT NULL`, id)
+	if err != nil {
+		return nil, fmt.Errorf("querying learning path notes: %w", err)
+	}
+	defer rows.Close()
+
+	notes := make(map[int64]string)
+	for rows.Next() {
+		var (
+			itemID int64
+			note   string
+		)
+		if err := rows.Scan(&itemID, &note); err != nil {
+			return nil, fmt.Errorf("scanning learning path note: %w", err)
+		}
+		notes[itemID] = note
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating learning path notes: %w", err)
+	}
+	return notes, nil
+}
+
+// UpdateLearningPath updates the title and description of a learning path.
+// Nil fields are left unchanged.
+func (s *sqlStore) UpdateLearningPath(ctx context.Context, id int64, title, description *string) error {
+	res, err := s.db.ExecContext(ctx,
+		`UPDATE learning_paths
+		 SET title = COALESCE(?, title),
+		     description = COALESCE(?, description),
+		     updated_at = datetime('now')
+		 WHERE id = ?`,
+		title, description, id,
+	)
+	if err != nil {
+		return fmt.Errorf("updating learning path: %w", err)
+	}
+	n, _ := res.RowsAffected()
+	if n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// SetLearningPathItems replaces the items of a learning path with steps, in
+// order. Returns ErrNotFound if the path does not exist.
+func (s *sqlStore) SetLearningPathItems(ctx context.Context, id int64, steps []models.LearningPathStep) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("beginning transaction: %w", err)
+	}
+	defer tx.Rollback() //nolint:errcheck // rollback after commit is a no-op
+
+	res, err := tx.ExecContext(ctx,
+		`UPDATE learning_paths SET updated_at = datetime('now') WHERE id = ?`, id)
+	if err != nil {
+		return fmt.Errorf("touching learning path: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrNotFound
+	}
+
+	if _, err := tx.ExecContext(ctx,
+		`DELETE FROM learning_path_items WHERE path_id = ?`, id); err != nil {
+		return fmt.Errorf("clearing learning path items: %w", err)
+	}
+	if err := insertPathSteps(ctx, tx, id, steps); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("committing transaction: %w", err)
+	}
+	return nil
+}
+
+// DeleteLearningPath deletes a learning path, recording it in the audit log.
+// The reading list items it referenced are not affected.
+func (s *sqlStore) DeleteLearningPath(ctx context.Context, id int64) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("beginning transaction: %w", err)
+	}
+	defer tx.Rollback() //nolint:errcheck // rollback after commit is a no-op
+
+	var (
+		title              string
+		description, topic sql.NullString
+	)
+	err = tx.QueryRowContext(ctx,
+		`SELECT title, description, topic FROM learning_paths WHERE id = ?`, id).Scan(&title, &description, &topic)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("reading learning path: %w", err)
+	}
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM learning_paths WHERE id = ?`, id); err != nil {
+		return fmt.Errorf("deleting learning path: %w", err)
+	}
+	if err := recordAudit(ctx, tx, AuditPathDelete, "learning_path", id, map[string]any{
+		"title": title, "description": description.String, "topic": topic.String,
+	}, nil); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("committing transaction: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,187 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/hoanghai1803/apricot/internal/models"
+)
+
+// seedPathItems adds n blogs to the reading list and returns their reading
+// list item IDs.
+func seedPathItems(t *testing.T, store *SQLiteStore, n int) []int64 {
+	t.Helper()
+	ctx := context.Background()
+
+	ids := make([]int64, n)
+	for i := range n {
+		blogID := seedReadingListBlog(t, store, "https://test.com/path-"+string(rune('a'+i)))
+		if err := store.AddToReadingList(ctx, blogID); err != nil {
+			t.Fatalf("AddToReadingList: %v", err)
+		}
+		id, err := store.GetReadingListIDByBlogID(ctx, blogID)
+		if err != nil {
+			t.Fatalf("GetReadingListIDByBlogID: %v", err)
+		}
+		ids[i] = id
+	}
+	return ids
+}
+
+func TestCreateAndGetLearningPath(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+	items := seedPathItems(t, store, 3)
+
+	id, err := store.CreateLearningPath(ctx, &models.LearningPath{Title: "Consensus", Topic: "raft"}, []models.LearningPathStep{
+		{ReadingListID: items[2], Note: "start here"},
+		{ReadingListID: items[0]},
+	})
+	if err != nil {
+		t.Fatalf("CreateLearningPath() error: %v", err)
+	}
+
+	if err := store.UpdateReadingListStatus(ctx, items[2], "read"); err != nil {
+		t.Fatalf("UpdateReadingListStatus() error: %v", err)
+	}
+
+	path, err := store.GetLearningPath(ctx, id)
+	if err != nil {
+		t.Fatalf("GetLearningPath() error: %v", err)
+	}
+	if path.Title != "Consensus" || path.Topic != "raft" {
+		t.Errorf("got title=%q topic=%q", path.Title, path.Topic)
+	}
+	if path.TotalItems != 2 || path.CompletedItems != 1 {
+		t.Errorf("got total=%d completed=%d, want 2 and 1", path.TotalItems, path.CompletedItems)
+	}
+	if len(path.Items) != 2 {
+		t.Fatalf("got %d items, want 2", len(path.Items))
+	}
+	if path.Items[0].Item.ID != items[2] || path.Items[1].Item.ID != items[0] {
+		t.Errorf("items out of order: %d, %d", path.Items[0].Item.ID, path.Items[1].Item.ID)
+	}
+	if path.Items[0].Note != "start here" || path.Items[1].Note != "" {
+		t.Errorf("got notes %q, %q", path.Items[0].Note, path.Items[1].Note)
+	}
+	if path.Items[0].Item.Blog == nil {
+		t.Error("expected item blog to be populated")
+	}
+}
+
+func TestCreateLearningPath_InvalidItems(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+	items := seedPathItems(t, store, 1)
+
+	_, err := store.CreateLearningPath(ctx, &models.LearningPath{Title: "Dup"}, []models.LearningPathStep{
+		{ReadingListID: items[0]}, {ReadingListID: items[0]},
+	})
+	if err == nil || !strings.Contains(err.Error(), "more than once") {
+		t.Errorf("expected duplicate item error, got: %v", err)
+	}
+
+	_, err = store.CreateLearningPath(ctx, &models.LearningPath{Title: "Missing"}, []models.LearningPathStep{
+		{ReadingListID: 99999},
+	})
+	if err == nil || !strings.Contains(err.Error(), "does not exist") {
+		t.Errorf("expected missing item error, got: %v", err)
+	}
+
+	paths, err := store.ListLearningPaths(ctx)
+	if err != nil {
+		t.Fatalf("ListLearningPaths() error: %v", err)
+	}
+	if len(paths) != 0 {
+		t.Errorf("got %d paths after failed creates, want 0", len(paths))
+	}
+}
+
+func TestSetLearningPathItems(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+	items := seedPathItems(t, store, 3)
+
+	id, err := store.CreateLearningPath(ctx, &models.LearningPath{Title: "Path"}, []models.LearningPathStep{
+		{ReadingListID: items[0]}, {ReadingListID: items[1]},
+	})
+	if err != nil {
+		t.Fatalf("CreateLearningPath() error: %v", err)
+	}
+
+	if err := store.SetLearningPathItems(ctx, id, []models.LearningPathStep{
+		{ReadingListID: items[1]}, {ReadingListID: items[2]}, {ReadingListID: items[0]},
+	}); err != nil {
+		t.Fatalf("SetLearningPathItems() error: %v", err)
+	}
+
+	path, err := store.GetLearningPath(ctx, id)
+	if err != nil {
+		t.Fatalf("GetLearningPath() error: %v", err)
+	}
+	want := []int64{items[1], items[2], items[0]}
+	for i, item := range path.Items {
+		if item.Item.ID != want[i] || item.Position != i {
+			t.Errorf("item %d = id %d position %d, want id %d", i, item.Item.ID, item.Position, want[i])
+		}
+	}
+
+	if err := store.SetLearningPathItems(ctx, 99999, nil); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got: %v", err)
+	}
+}
+
+func TestDeleteLearningPath_KeepsReadingList(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+	items := seedPathItems(t, store, 1)
+
+	id, err := store.CreateLearningPath(ctx, &models.LearningPath{Title: "Path"}, []models.LearningPathStep{
+		{ReadingListID: items[0]},
+	})
+	if err != nil {
+		t.Fatalf("CreateLearningPath() error: %v", err)
+	}
+
+	if err := store.DeleteLearningPath(ctx, id); err != nil {
+		t.Fatalf("DeleteLearningPath() error: %v", err)
+	}
+	if _, err := store.GetLearningPath(ctx, id); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound after delete, got: %v", err)
+	}
+	if _, err := store.GetReadingListItemByID(ctx, items[0]); err != nil {
+		t.Errorf("reading list item should survive path deletion: %v", err)
+	}
+	if err := store.DeleteLearningPath(ctx, id); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound on second delete, got: %v", err)
+	}
+}
+
+func TestUpdateLearningPath(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+
+	id, err := store.CreateLearningPath(ctx, &models.LearningPath{Title: "Old", Description: "keep"}, nil)
+	if err != nil {
+		t.Fatalf("CreateLearningPath() error: %v", err)
+	}
+
+	title := "New"
+	if err := store.UpdateLearningPath(ctx, id, &title, nil); err != nil {
+		t.Fatalf("UpdateLearningPath() error: %v", err)
+	}
+
+	path, err := store.GetLearningPath(ctx, id)
+	if err != nil {
+		t.Fatalf("GetLearningPath() error: %v", err)
+	}
+	if path.Title != "New" || path.Description != "keep" {
+		t.Errorf("got title=%q description=%q", path.Title, path.Description)
+	}
+
+	if err := store.UpdateLearningPath(ctx, 99999, &title, nil); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got: %v", err)
+	}
+}
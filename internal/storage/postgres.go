@@ -0,0 +1,213 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"embed"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/stdlib"
+)
+
+// PostgresStore implements Store on a Postgres database, for running
+// Apricot next to other services on a shared server. It runs the same
+// queries as SQLiteStore apart from full-text search, which uses Postgres
+// text search instead of FTS5 (see search_postgres.go).
+type PostgresStore struct {
+	*sqlStore
+}
+
+// Compile-time interface check.
+var _ Store = (*PostgresStore)(nil)
+
+// NewPostgresStore creates a PostgresStore backed by a connection from
+// OpenPostgres.
+func NewPostgresStore(db *sql.DB) *PostgresStore {
+	return &PostgresStore{&sqlStore{db: db, rdb: db, dialect: dialectPostgres}}
+}
+
+// PoolOptions configures the connection pool of a Postgres database. Zero
+// values keep the database/sql defaults.
+type PoolOptions struct {
+	MaxOpenConns    int
+	MaxIdleConns    int
+	ConnMaxLifetime time.Duration
+}
+
+// OpenPostgres connects to the Postgres database described by dsn, a
+// "postgres://" URL or key=value connection string, and configures its
+// connection pool. Queries on the returned *sql.DB may use "?" placeholders,
+// which are rewritten to Postgres' "$1" form.
+func OpenPostgres(dsn string, pool PoolOptions) (*sql.DB, error) {
+	cfg, err := pgx.ParseConfig(dsn)
+	if err != nil {
+		return nil, fmt.Errorf("parsing postgres dsn: %w", err)
+	}
+
+	db := sql.OpenDB(rebindConnector{stdlib.GetConnector(*cfg)})
+	if pool.MaxOpenConns > 0 {
+		db.SetMaxOpenConns(pool.MaxOpenConns)
+	}
+	if pool.MaxIdleConns > 0 {
+		db.SetMaxIdleConns(pool.MaxIdleConns)
+	}
+	if pool.ConnMaxLifetime > 0 {
+		db.SetConnMaxLifetime(pool.ConnMaxLifetime)
+	}
+
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("pinging postgres database %q on %s: %w", cfg.Database, cfg.Host, err)
+	}
+
+	slog.Info("opened postgres database", "host", cfg.Host, "database", cfg.Database)
+	return db, nil
+}
+
+//go:embed migrations/postgres/*.sql
+var postgresMigrationsFS embed.FS
+
+// RunPostgresMigrations applies any unapplied schema migrations to a
+// Postgres database, from the embedded migrations/postgres/ directory.
+// Version numbers are shared with the SQLite migrations: the Postgres schema
+// starts at the SQLite schema version it was written from, and later
+// migrations are added to both directories under the same number.
+func RunPostgresMigrations(db *sql.DB) error {
+	return runMigrations(db, postgresMigrationsFS, "migrations/postgres", `
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+			version    INTEGER PRIMARY KEY,
+			applied_at TEXT NOT NULL DEFAULT (to_char(now() AT TIME ZONE 'UTC', 'YYYY-MM-DD HH24:MI:SS'))
+		);
+	`)
+}
+
+// rebindConnector wraps the pgx connector so that every connection rewrites
+// "?" placeholders before sending a query.
+type rebindConnector struct {
+	driver.Connector
+}
+
+// Connect returns a connection that rebinds placeholders.
+func (c rebindConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	conn, err := c.Connector.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return rebindConn{conn.(*stdlib.Conn)}, nil
+}
+
+// rebindConn is a pgx connection whose query methods accept "?"
+// placeholders. Transactions, pings, and argument conversion are pgx's.
+type rebindConn struct {
+	*stdlib.Conn
+}
+
+func (c rebindConn) Prepare(query string) (driver.Stmt, error) {
+	return c.Conn.Prepare(rebind(query))
+}
+
+func (c rebindConn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	return c.Conn.PrepareContext(ctx, rebind(query))
+}
+
+func (c rebindConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	return c.Conn.ExecContext(ctx, rebind(query), args)
+}
+
+func (c rebindConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	return c.Conn.QueryContext(ctx, rebind(query), args)
+}
+
+// rebind rewrites the "?" placeholders in query to Postgres' numbered "$N"
+// form. Question marks inside string literals, quoted identifiers, comments,
+// and dollar-quoted bodies are left alone.
+func rebind(query string) string {
+	if !strings.Contains(query, "?") {
+		return query
+	}
+
+	var (
+		b strings.Builder
+		n int
+	)
+	b.Grow(len(query) + 8)
+	for i := 0; i < len(query); i++ {
+		c := query[i]
+		var end int // index just past a quoted span starting at i, if any
+		switch {
+		case c == '?':
+			n++
+			b.WriteByte('$')
+			b.WriteString(strconv.Itoa(n))
+			continue
+		case c == '\'' || c == '"':
+			end = closingQuote(query, i+1, c)
+		case c == '-' && strings.HasPrefix(query[i:], "--"):
+			end = indexFrom(query, i, "\n")
+		case c == '/' && strings.HasPrefix(query[i:], "/*"):
+			end = indexFrom(query, i+2, "*/")
+		case c == '$':
+			if tag, ok := dollarTag(query[i:]); ok {
+				end = indexFrom(query, i+len(tag), tag)
+			}
+		}
+		if end > i {
+			b.WriteString(query[i:end])
+			i = end - 1
+			continue
+		}
+		b.WriteByte(c)
+	}
+	return b.String()
+}
+
+// closingQuote returns the index just past the quote character q that closes
+// a span opened before start. A doubled quote is an escaped one.
+func closingQuote(s string, start int, q byte) int {
+	for i := start; i < len(s); i++ {
+		if s[i] != q {
+			continue
+		}
+		if i+1 < len(s) && s[i+1] == q {
+			i++
+			continue
+		}
+		return i + 1
+	}
+	return len(s)
+}
+
+// indexFrom returns the index just past the first occurrence of sub in s at
+// or after start, or len(s) if there is none.
+func indexFrom(s string, start int, sub string) int {
+	if start > len(s) {
+		return len(s)
+	}
+	j := strings.Index(s[start:], sub)
+	if j < 0 {
+		return len(s)
+	}
+	return start + j + len(sub)
+}
+
+// dollarTag returns the "$tag$" opening a dollar-quoted string at the start
+// of s, if s starts with one.
+func dollarTag(s string) (string, bool) {
+	for i := 1; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == '$':
+			return s[:i+1], true
+		case c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || i > 1 && c >= '0' && c <= '9':
+		default:
+			return "", false
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,74 @@
+package storage
+
+import (
+	"embed"
+	"testing"
+)
+
+func TestRebind(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		want  string
+	}{
+		{"no placeholders", "SELECT 1", "SELECT 1"},
+		{"placeholders", "SELECT id FROM blogs WHERE url = ? AND id != ? LIMIT ?",
+			"SELECT id FROM blogs WHERE url = $1 AND id != $2 LIMIT $3"},
+		{"string literal", "SELECT '?', 'it''s ?' WHERE a = ?", "SELECT '?', 'it''s ?' WHERE a = $1"},
+		{"quoted identifier", `SELECT "a?b" FROM t WHERE x = ?`, `SELECT "a?b" FROM t WHERE x = $1`},
+		{"line comment", "SELECT ? -- why?\nFROM t WHERE y = ?", "SELECT $1 -- why?\nFROM t WHERE y = $2"},
+		{"block comment", "SELECT /* ? */ ?", "SELECT /* ? */ $1"},
+		{"dollar quoted", "SELECT $$ ? $$, $tag$ ? $tag$, ?", "SELECT $$ ? $$, $tag$ ? $tag$, $1"},
+		{"unterminated literal", "SELECT 'oops ?", "SELECT 'oops ?"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := rebind(tt.query); got != tt.want {
+				t.Errorf("rebind(%q) = %q, want %q", tt.query, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPgAnyTerm(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"", ""},
+		{"  go  ", "go"},
+		{`kafka "streams" -ops`, "kafka or streams or ops"},
+		{"sqlite OR postgres", "sqlite or postgres"},
+		{`" -`, ""},
+	}
+	for _, tt := range tests {
+		if got := pgAnyTerm(tt.in); got != tt.want {
+			t.Errorf("pgAnyTerm(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+// The Postgres schema shares version numbers with the SQLite migrations, so
+// archives and schema checks agree across backends.
+func TestPostgresMigrationsMatchSQLite(t *testing.T) {
+	latest := func(fsys embed.FS, dir string) int {
+		t.Helper()
+		entries, err := fsys.ReadDir(dir)
+		if err != nil {
+			t.Fatalf("reading %s: %v", dir, err)
+		}
+		v := 0
+		for _, e := range entries {
+			if !e.IsDir() {
+				v = max(v, parseVersion(e.Name()))
+			}
+		}
+		return v
+	}
+
+	sqlite := latest(migrationsFS, "migrations")
+	postgres := latest(postgresMigrationsFS, "migrations/postgres")
+	if postgres != sqlite {
+		t.Errorf("latest postgres migration is %d, latest sqlite migration is %d", postgres, sqlite)
+	}
+}
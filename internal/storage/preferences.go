@@ -10,9 +10,9 @@ import (
 
 // GetPreference retrieves a preference by key and JSON-unmarshals it into dest.
 // Returns ErrNotFound if the key does not exist.
-func (s *Store) GetPreference(ctx context.Context, key string, dest any) error {
+func (s *sqlStore) GetPreference(ctx context.Context, key string, dest any) error {
 	var raw string
-	err := s.db.QueryRowContext(ctx,
+	err := s.rdb.QueryRowContext(ctx,
 		`SELECT value FROM preferences WHERE key = ?`, key,
 	).Scan(&raw)
 	if err != nil {
@@ -29,14 +29,35 @@ func (s *Store) GetPreference(ctx context.Context, key string, dest any) error {
 }
 
 // SetPreference JSON-marshals value and stores it under the given key. If the
-// key already exists, its value and updated_at are overwritten.
-func (s *Store) SetPreference(ctx context.Context, key string, value any) error {
+// key already exists, its value and updated_at are overwritten. Changed
+// values are recorded in the audit log; writing the same value again is a
+// no-op.
+func (s *sqlStore) SetPreference(ctx context.Context, key string, value any) error {
 	data, err := json.Marshal(value)
 	if err != nil {
 		return fmt.Errorf("marshaling preference %q: %w", key, err)
 	}
 
-	_, err = s.db.ExecContext(ctx,
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("beginning transaction: %w", err)
+	}
+	defer tx.Rollback() //nolint:errcheck // rollback after commit is a no-op
+
+	var before json.RawMessage
+	var old string
+	err = tx.QueryRowContext(ctx, `SELECT value FROM preferences WHERE key = ?`, key).Scan(&old)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+	case err != nil:
+		return fmt.Errorf("reading preference %q: %w", key, err)
+	case old == string(data):
+		return nil
+	default:
+		before = json.RawMessage(old)
+	}
+
+	_, err = tx.ExecContext(ctx,
 		`INSERT INTO preferences (key, value, updated_at)
 		 VALUES (?, ?, datetime('now'))
 		 ON CONFLICT(key) DO UPDATE SET
@@ -47,12 +68,19 @@ func (s *Store) SetPreference(ctx context.Context, key string, value any) error
 	if err != nil {
 		return fmt.Errorf("setting preference %q: %w", key, err)
 	}
+	if err := recordAudit(ctx, tx, AuditPreferenceSet, "preference", key, before, json.RawMessage(data)); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("committing transaction: %w", err)
+	}
 	return nil
 }
 
 // GetAllPreferences returns every preference as a map of key to raw JSON value.
-func (s *Store) GetAllPreferences(ctx context.Context) (map[string]json.RawMessage, error) {
-	rows, err := s.db.QueryContext(ctx, `SELECT key, value FROM preferences`)
+func (s *sqlStore) GetAllPreferences(ctx context.Context) (map[string]json.RawMessage, error) {
+	rows, err := s.rdb.QueryContext(ctx, `SELECT key, value FROM preferences`)
 	if err != nil {
 		return nil, fmt.Errorf("querying all preferences: %w", err)
 	}
@@ -0,0 +1,55 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+)
+
+// GetReaderContent returns the reader view HTML stored for a blog by
+// SaveReaderContent. Returns ErrNotFound if there is none, or if the blog's
+// content has changed since it was stored.
+func (s *sqlStore) GetReaderContent(ctx context.Context, blogID int64) (string, error) {
+	var raw []byte
+	err := s.rdb.QueryRowContext(ctx,
+		`SELECT r.html FROM reader_content r
+		 JOIN blogs b ON b.id = r.blog_id
+		 WHERE r.blog_id = ? AND COALESCE(r.content_hash, '') = COALESCE(b.content_hash, '')`,
+		blogID,
+	).Scan(&raw)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", ErrNotFound
+	}
+	if err != nil {
+		return "", fmt.Errorf("getting reader content: %w", err)
+	}
+
+	html, err := decodeContent(raw)
+	if err != nil {
+		return "", fmt.Errorf("reading reader content of blog %d: %w", blogID, err)
+	}
+	return html, nil
+}
+
+// SaveReaderContent stores the reader view HTML of a blog, extracted from
+// its current content, replacing any stored before. Returns ErrNotFound if
+// the blog does not exist.
+func (s *sqlStore) SaveReaderContent(ctx context.Context, blogID int64, html string) error {
+	_, err := s.db.ExecContext(ctx,
+		`INSERT INTO reader_content (blog_id, html, content_hash)
+		 VALUES (?, ?, (SELECT content_hash FROM blogs WHERE id = ?))
+		 ON CONFLICT(blog_id) DO UPDATE SET
+			html         = excluded.html,
+			content_hash = excluded.content_hash,
+			extracted_at = datetime('now')`,
+		blogID, s.encodeContent(html), blogID,
+	)
+	if err != nil {
+		if isForeignKeyViolation(err) {
+			return ErrNotFound
+		}
+		return fmt.Errorf("saving reader content: %w", err)
+	}
+	return nil
+}
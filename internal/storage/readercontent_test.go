@@ -0,0 +1,60 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/hoanghai1803/apricot/internal/models"
+)
+
+func TestReaderContent(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+	sourceID := seedTestSource(t, store)
+
+	upsert := func(content, hash string) int64 {
+		t.Helper()
+		id, err := store.UpsertBlog(ctx, &models.Blog{
+			SourceID:    sourceID,
+			Title:       "Reader Post",
+			URL:         "https://test.com/reader",
+			FullContent: content,
+			ContentHash: hash,
+			FetchedAt:   time.Now(),
+		})
+		if err != nil {
+			t.Fatalf("UpsertBlog: %v", err)
+		}
+		return id
+	}
+
+	id := upsert("first version", "h1")
+	if _, err := store.GetReaderContent(ctx, id); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("GetReaderContent() before saving: error = %v, want ErrNotFound", err)
+	}
+
+	if err := store.SaveReaderContent(ctx, id, "<p>first version</p>"); err != nil {
+		t.Fatalf("SaveReaderContent: %v", err)
+	}
+	if got, err := store.GetReaderContent(ctx, id); err != nil || got != "<p>first version</p>" {
+		t.Errorf("GetReaderContent() = %q, %v; want the saved HTML", got, err)
+	}
+
+	// An edited post is extracted again.
+	upsert("second version", "h2")
+	if _, err := store.GetReaderContent(ctx, id); !errors.Is(err, ErrNotFound) {
+		t.Errorf("GetReaderContent() after an edit: error = %v, want ErrNotFound", err)
+	}
+	if err := store.SaveReaderContent(ctx, id, "<p>second version</p>"); err != nil {
+		t.Fatalf("SaveReaderContent again: %v", err)
+	}
+	if got, err := store.GetReaderContent(ctx, id); err != nil || got != "<p>second version</p>" {
+		t.Errorf("GetReaderContent() = %q, %v; want the replaced HTML", got, err)
+	}
+
+	if err := store.SaveReaderContent(ctx, 99999, "<p>x</p>"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("SaveReaderContent(missing) error = %v, want ErrNotFound", err)
+	}
+}
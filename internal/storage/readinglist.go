@@ -5,62 +5,230 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"log/slog"
+	"slices"
 	"strings"
+	"time"
 
 	"github.com/hoanghai1803/apricot/internal/models"
 )
 
 // validStatuses is the set of allowed reading list statuses.
 var validStatuses = map[string]bool{
-	"unread":  true,
-	"reading": true,
-	"read":    true,
+	"unread":   true,
+	"reading":  true,
+	"read":     true,
+	"archived": true,
 }
 
 // AddToReadingList adds a blog post to the reading list with status "unread".
 // Returns a descriptive error if the blog_id does not exist (foreign key) or
-// the blog is already on the list (unique constraint).
-func (s *Store) AddToReadingList(ctx context.Context, blogID int64) error {
+// the blog is already on the list (unique constraint). Content moved to cold
+// storage is restored.
+func (s *sqlStore) AddToReadingList(ctx context.Context, blogID int64) error {
 	_, err := s.db.ExecContext(ctx,
 		`INSERT INTO reading_list (blog_id, status) VALUES (?, 'unread')`,
 		blogID,
 	)
 	if err != nil {
-		errMsg := err.Error()
-		if strings.Contains(errMsg, "UNIQUE constraint failed") {
-			return fmt.Errorf("blog %d is already on the reading list", blogID)
+		if isUniqueViolation(err) {
+			return fmt.Errorf("blog %d is %w", blogID, ErrAlreadyOnList)
 		}
-		if strings.Contains(errMsg, "FOREIGN KEY constraint failed") {
+		if isForeignKeyViolation(err) {
 			return fmt.Errorf("blog %d does not exist", blogID)
 		}
 		return fmt.Errorf("adding to reading list: %w", err)
 	}
+
+	// Saved blogs are never kept cold, so bring archived content back.
+	// Reads rehydrate it anyway, so a failure here is not fatal.
+	if err := s.restoreColdContent(ctx, blogID); err != nil {
+		slog.Warn("failed to restore cold content", "blog_id", blogID, "error", err)
+	}
 	return nil
 }
 
-// GetReadingList returns reading list items with associated blog data and
-// summaries. If status is empty, all items are returned. Results are ordered
-// by added_at DESC.
-func (s *Store) GetReadingList(ctx context.Context, status string) ([]models.ReadingListItem, error) {
-	query := `
-		SELECT rl.id, rl.blog_id, rl.status, rl.progress, rl.notes, rl.added_at, rl.read_at,
-			   b.id, b.source_id, COALESCE(b.custom_source, bs.name, '') AS source, b.title, b.url,
-			   b.description, b.full_content, b.published_at, b.fetched_at,
-			   b.content_hash, b.reading_time_minutes, b.created_at,
-			   s.summary
+// AddManyToReadingList adds the given blogs to the reading list as unread
+// in a single transaction, at the head of the queue in the given order, and
+// returns the IDs of those it added, in order. Once the queue has been
+// reordered they are placed ahead of the pinned items, as by
+// ReorderReadingList. Blogs already on the list are left alone. If any blog
+// does not exist, nothing is added and an error wrapping ErrNotFound is
+// returned.
+func (s *sqlStore) AddManyToReadingList(ctx context.Context, blogIDs []int64) ([]int64, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("beginning transaction: %w", err)
+	}
+	defer tx.Rollback() //nolint:errcheck // rollback after commit is a no-op
+
+	stmt, err := tx.PrepareContext(ctx,
+		`INSERT INTO reading_list (blog_id, status) VALUES (?, 'unread')
+		 ON CONFLICT(blog_id) DO NOTHING`)
+	if err != nil {
+		return nil, fmt.Errorf("preparing statement: %w", err)
+	}
+	defer stmt.Close()
+
+	// Unplaced items list newest first, so the first blog is added last.
+	var added []int64
+	for _, blogID := range slices.Backward(blogIDs) {
+		res, err := stmt.ExecContext(ctx, blogID)
+		if err != nil {
+			if isForeignKeyViolation(err) {
+				return nil, fmt.Errorf("blog %d: %w", blogID, ErrNotFound)
+			}
+			return nil, fmt.Errorf("adding blog %d to reading list: %w", blogID, err)
+		}
+		if n, _ := res.RowsAffected(); n > 0 {
+			added = append(added, blogID)
+		}
+	}
+	slices.Reverse(added)
+
+	if len(added) > 0 {
+		res, err := tx.ExecContext(ctx,
+			`UPDATE reading_list SET position = position + ? WHERE position IS NOT NULL`, len(added))
+		if err != nil {
+			return nil, fmt.Errorf("shifting reading list positions: %w", err)
+		}
+		if n, _ := res.RowsAffected(); n > 0 {
+			for i, blogID := range added {
+				if _, err := tx.ExecContext(ctx,
+					`UPDATE reading_list SET position = ? WHERE blog_id = ?`, i+1, blogID,
+				); err != nil {
+					return nil, fmt.Errorf("positioning blog %d: %w", blogID, err)
+				}
+			}
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("committing transaction: %w", err)
+	}
+
+	// As in AddToReadingList.
+	for _, blogID := range added {
+		if err := s.restoreColdContent(ctx, blogID); err != nil {
+			slog.Warn("failed to restore cold content", "blog_id", blogID, "error", err)
+		}
+	}
+	return added, nil
+}
+
+// ReadingListFilter narrows the items returned by GetReadingListFiltered.
+// Zero-valued fields do not filter.
+type ReadingListFilter struct {
+	Status     string
+	Difficulty string
+	Category   string
+
+	// Limit and Offset select a page of the matching items. A Limit of 0
+	// returns every item from Offset on.
+	Limit  int
+	Offset int
+
+	// Snoozed selects items by snooze state: SnoozeHide drops items whose
+	// snoozed_until is still in the future, SnoozeOnly keeps only those.
+	// The empty value ignores snoozing.
+	Snoozed string
+
+	// WithoutContent leaves Blog.FullContent empty, for list views.
+	WithoutContent bool
+}
+
+// Values of ReadingListFilter.Snoozed.
+const (
+	SnoozeHide = "hide"
+	SnoozeOnly = "only"
+)
+
+// where returns the WHERE clause and arguments for the filter's conditions,
+// or an empty clause if it has none.
+func (f ReadingListFilter) where() (string, []any) {
+	var (
+		conds []string
+		args  []any
+	)
+	if f.Status != "" {
+		conds = append(conds, "rl.status = ?")
+		args = append(args, f.Status)
+	}
+	if f.Difficulty != "" {
+		// Prefer the standalone classification, falling back to the level
+		// produced with the summary.
+		conds = append(conds, "COALESCE(b.difficulty, s.difficulty) = ?")
+		args = append(args, f.Difficulty)
+	}
+	if f.Category != "" {
+		conds = append(conds, "s.category = ?")
+		args = append(args, f.Category)
+	}
+	switch f.Snoozed {
+	case SnoozeHide:
+		conds = append(conds, "(rl.snoozed_until IS NULL OR rl.snoozed_until <= datetime('now'))")
+	case SnoozeOnly:
+		conds = append(conds, "rl.snoozed_until > datetime('now')")
+	}
+	if len(conds) == 0 {
+		return "", nil
+	}
+	return " WHERE " + strings.Join(conds, " AND "), args
+}
+
+// readingListFrom is the FROM clause shared by reading list queries.
+const readingListFrom = `
 		FROM reading_list rl
 		JOIN blogs b ON b.id = rl.blog_id
 		LEFT JOIN blog_sources bs ON bs.id = b.source_id
 		LEFT JOIN blog_summaries s ON s.blog_id = rl.blog_id`
 
-	var args []any
-	if status != "" {
-		query += " WHERE rl.status = ?"
-		args = append(args, status)
+// readingListSelect is the SELECT ... FROM clause shared by reading list
+// queries. Rows are scanned with scanReadingListItem.
+const readingListSelect = `
+		SELECT rl.id, rl.blog_id, rl.status, rl.progress, rl.notes, rl.added_at, rl.read_at, rl.archived_at, rl.snoozed_until, rl.position, rl.version,
+			   rl.scroll_position, rl.last_read_at,
+			   ` + blogColumns + `,
+			   s.summary, s.category` + readingListFrom
+
+// readingListSelectWithoutContent is readingListSelect without the post
+// content, using blogListColumns.
+const readingListSelectWithoutContent = `
+		SELECT rl.id, rl.blog_id, rl.status, rl.progress, rl.notes, rl.added_at, rl.read_at, rl.archived_at, rl.snoozed_until, rl.position, rl.version,
+			   rl.scroll_position, rl.last_read_at,
+			   ` + blogListColumns + `,
+			   s.summary, s.category` + readingListFrom
+
+// GetReadingList returns reading list items with associated blog data and
+// summaries. If status is empty, all items are returned. Results are in
+// queue order, as described on GetReadingListFiltered.
+func (s *sqlStore) GetReadingList(ctx context.Context, status string) ([]models.ReadingListItem, error) {
+	return s.GetReadingListFiltered(ctx, ReadingListFilter{Status: status})
+}
+
+// GetReadingListFiltered returns reading list items matching every non-empty
+// field of the filter, with associated blog data, summaries, and tags.
+// Results are in queue order: items placed by ReorderReadingList first, by
+// position, then the rest by added_at DESC, then by ID for a stable page
+// order.
+func (s *sqlStore) GetReadingListFiltered(ctx context.Context, filter ReadingListFilter) ([]models.ReadingListItem, error) {
+	query := readingListSelect
+	if filter.WithoutContent {
+		query = readingListSelectWithoutContent
+	}
+	where, args := filter.where()
+	query += where + " ORDER BY rl.position IS NULL, rl.position, rl.added_at DESC, rl.id DESC"
+	if filter.Limit > 0 || filter.Offset > 0 {
+		// SQLite requires a LIMIT before OFFSET; -1 means no limit.
+		limit := filter.Limit
+		if limit <= 0 {
+			limit = -1
+		}
+		query += " LIMIT ? OFFSET ?"
+		args = append(args, limit, max(filter.Offset, 0))
 	}
-	query += " ORDER BY rl.added_at DESC"
 
-	rows, err := s.db.QueryContext(ctx, query, args...)
+	rows, err := s.rdb.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("querying reading list: %w", err)
 	}
@@ -68,55 +236,11 @@ func (s *Store) GetReadingList(ctx context.Context, status string) ([]models.Rea
 
 	var items []models.ReadingListItem
 	for rows.Next() {
-		var (
-			item           models.ReadingListItem
-			notes          sql.NullString
-			addedAt        string
-			readAt         sql.NullString
-			blog           models.Blog
-			description    sql.NullString
-			fullContent    sql.NullString
-			publishedAt    sql.NullString
-			fetchedAt      string
-			contentHash    sql.NullString
-			readingTimeMin sql.NullInt64
-			blogCreated    string
-			summary        sql.NullString
-		)
-
-		if err := rows.Scan(
-			&item.ID, &item.BlogID, &item.Status, &item.Progress, &notes, &addedAt, &readAt,
-			&blog.ID, &blog.SourceID, &blog.Source, &blog.Title, &blog.URL,
-			&description, &fullContent, &publishedAt, &fetchedAt,
-			&contentHash, &readingTimeMin, &blogCreated,
-			&summary,
-		); err != nil {
+		item, err := scanReadingListItem(rows)
+		if err != nil {
 			return nil, fmt.Errorf("scanning reading list row: %w", err)
 		}
-
-		if notes.Valid {
-			item.Notes = &notes.String
-		}
-		item.AddedAt = parseTime(addedAt)
-		item.ReadAt = parseTimePtr(nullStringToPtr(readAt))
-
-		blog.Description = description.String
-		blog.FullContent = fullContent.String
-		blog.ContentHash = contentHash.String
-		if readingTimeMin.Valid {
-			v := int(readingTimeMin.Int64)
-			blog.ReadingTimeMinutes = &v
-		}
-		blog.PublishedAt = parseTimePtr(nullStringToPtr(publishedAt))
-		blog.FetchedAt = parseTime(fetchedAt)
-		blog.CreatedAt = parseTime(blogCreated)
-		item.Blog = &blog
-
-		if summary.Valid {
-			item.Summary = &summary.String
-		}
-
-		items = append(items, item)
+		items = append(items, *item)
 	}
 	if err := rows.Err(); err != nil {
 		return nil, fmt.Errorf("iterating reading list rows: %w", err)
@@ -133,24 +257,240 @@ func (s *Store) GetReadingList(ctx context.Context, status string) ([]models.Rea
 	return items, nil
 }
 
+// CountReadingList returns the number of reading list items matching the
+// filter, ignoring its Limit and Offset.
+func (s *sqlStore) CountReadingList(ctx context.Context, filter ReadingListFilter) (int, error) {
+	where, args := filter.where()
+	var n int
+	if err := s.rdb.QueryRowContext(ctx,
+		`SELECT COUNT(*)`+readingListFrom+where, args...).Scan(&n); err != nil {
+		return 0, fmt.Errorf("counting reading list: %w", err)
+	}
+	return n, nil
+}
+
+// scanReadingListItem scans a row produced by readingListSelect into a
+// models.ReadingListItem with its Blog and Summary populated.
+func scanReadingListItem(row scanner) (*models.ReadingListItem, error) {
+	var (
+		item     models.ReadingListItem
+		notes    sql.NullString
+		addedAt  string
+		readAt   sql.NullString
+		archived sql.NullString
+		snoozed  sql.NullString
+		position sql.NullInt64
+		scroll   sql.NullFloat64
+		lastRead sql.NullString
+		blog     models.Blog
+		br       blogRow
+		summary  sql.NullString
+		category sql.NullString
+	)
+
+	dest := []any{&item.ID, &item.BlogID, &item.Status, &item.Progress, &notes, &addedAt, &readAt, &archived, &snoozed, &position, &item.Version,
+		&scroll, &lastRead}
+	dest = append(dest, br.dest(&blog)...)
+	dest = append(dest, &summary, &category)
+	if err := row.Scan(dest...); err != nil {
+		return nil, err
+	}
+
+	if notes.Valid {
+		item.Notes = &notes.String
+	}
+	item.AddedAt = parseTime(addedAt)
+	item.ReadAt = parseTimePtr(nullStringToPtr(readAt))
+	item.ArchivedAt = parseTimePtr(nullStringToPtr(archived))
+	item.SnoozedUntil = parseTimePtr(nullStringToPtr(snoozed))
+	if position.Valid {
+		p := int(position.Int64)
+		item.Position = &p
+	}
+	if scroll.Valid {
+		item.ScrollPosition = &scroll.Float64
+	}
+	item.LastReadAt = parseTimePtr(nullStringToPtr(lastRead))
+
+	br.apply(&blog)
+	item.Blog = &blog
+
+	if summary.Valid {
+		item.Summary = &summary.String
+	}
+	item.Category = category.String
+
+	return &item, nil
+}
+
+// IsValidStatus reports whether status is an allowed reading list status.
+func IsValidStatus(status string) bool {
+	return validStatuses[status]
+}
+
 // UpdateReadingListStatus updates the status of a reading list item. The
-// status must be one of "unread", "reading", or "read". When the status
-// becomes "read", read_at is set to the current time; otherwise it is cleared.
-func (s *Store) UpdateReadingListStatus(ctx context.Context, id int64, status string) error {
+// status must be one of "unread", "reading", "read", or "archived". When the
+// status becomes "read", read_at is set to the current time (or kept, for an
+// item coming out of the archive); when it becomes "archived", archived_at is
+// set and read_at is kept; otherwise both are cleared.
+func (s *sqlStore) UpdateReadingListStatus(ctx context.Context, id int64, status string) error {
 	if !validStatuses[status] {
-		return fmt.Errorf("invalid reading list status %q: must be one of unread, reading, read", status)
+		return fmt.Errorf("invalid reading list status %q: must be one of unread, reading, read, archived", status)
 	}
 
-	var query string
-	if status == "read" {
-		query = `UPDATE reading_list SET status = ?, read_at = datetime('now') WHERE id = ?`
-	} else {
-		query = `UPDATE reading_list SET status = ?, read_at = NULL WHERE id = ?`
+	res, err := s.db.ExecContext(ctx, statusUpdateQuery(status), status, id)
+	if err != nil {
+		return fmt.Errorf("updating reading list status: %w", err)
 	}
+	n, _ := res.RowsAffected()
+	if n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// statusUpdateQuery returns the UPDATE statement that sets an item's status,
+// taking the status and the item ID, with read_at and archived_at updated as
+// described on UpdateReadingListStatus.
+func statusUpdateQuery(status string) string {
+	switch status {
+	case "read":
+		return `UPDATE reading_list SET status = ?,
+			read_at = CASE WHEN status = 'archived' THEN COALESCE(read_at, datetime('now')) ELSE datetime('now') END,
+			archived_at = NULL
+			WHERE id = ?`
+	case "archived":
+		return `UPDATE reading_list SET status = ?, archived_at = datetime('now') WHERE id = ?`
+	default:
+		return `UPDATE reading_list SET status = ?, read_at = NULL, archived_at = NULL WHERE id = ?`
+	}
+}
+
+// ReadingListUpdate is a set of changes to a reading list item, applied
+// together by UpdateReadingListItem. Nil fields are left unchanged.
+type ReadingListUpdate struct {
+	Status *string
+	Notes  *string
 
-	res, err := s.db.ExecContext(ctx, query, status, id)
+	// Snooze sets snoozed_until to SnoozedUntil, or clears it when
+	// SnoozedUntil is nil.
+	Snooze       bool
+	SnoozedUntil *time.Time
+
+	// IfVersion, if non-zero, is the version the changes were based on. The
+	// update fails with ErrVersionConflict if the item's version differs.
+	IfVersion int64
+}
+
+// UpdateReadingListItem applies update to an item in a single transaction,
+// with the effects of UpdateReadingListStatus, UpdateReadingListNotes, and
+// SnoozeReadingListItem, and returns the item's new version. Returns
+// ErrNotFound if the item does not exist.
+func (s *sqlStore) UpdateReadingListItem(ctx context.Context, id int64, update ReadingListUpdate) (int64, error) {
+	if update.Status != nil && !validStatuses[*update.Status] {
+		return 0, fmt.Errorf("invalid reading list status %q: must be one of unread, reading, read, archived", *update.Status)
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
 	if err != nil {
-		return fmt.Errorf("updating reading list status: %w", err)
+		return 0, fmt.Errorf("beginning transaction: %w", err)
+	}
+	defer tx.Rollback() //nolint:errcheck // rollback after commit is a no-op
+
+	if err := lockVersion(ctx, tx, "reading_list", id, update.IfVersion); err != nil {
+		return 0, err
+	}
+	if update.Status != nil {
+		if _, err := tx.ExecContext(ctx, statusUpdateQuery(*update.Status), *update.Status, id); err != nil {
+			return 0, fmt.Errorf("updating reading list status: %w", err)
+		}
+	}
+	if update.Notes != nil {
+		if _, err := tx.ExecContext(ctx,
+			`UPDATE reading_list SET notes = ? WHERE id = ?`, nullableString(*update.Notes), id); err != nil {
+			return 0, fmt.Errorf("updating reading list notes: %w", err)
+		}
+	}
+	if update.Snooze {
+		var v *string
+		if update.SnoozedUntil != nil {
+			t := update.SnoozedUntil.UTC().Format("2006-01-02 15:04:05")
+			v = &t
+		}
+		if _, err := tx.ExecContext(ctx,
+			`UPDATE reading_list SET snoozed_until = ? WHERE id = ?`, v, id); err != nil {
+			return 0, fmt.Errorf("snoozing reading list item: %w", err)
+		}
+	}
+
+	var version int64
+	if err := tx.QueryRowContext(ctx,
+		`SELECT version FROM reading_list WHERE id = ?`, id).Scan(&version); err != nil {
+		return 0, fmt.Errorf("reading reading list item version: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("committing transaction: %w", err)
+	}
+	return version, nil
+}
+
+// lockVersion checks, as the first statement of tx, that the row of table
+// with the given id exists and, if ifVersion is non-zero, is at that
+// version. The check is a no-op UPDATE so that it also locks the row until
+// tx ends, keeping a concurrent update from slipping in between. Returns
+// ErrNotFound or ErrVersionConflict.
+func lockVersion(ctx context.Context, tx *sql.Tx, table string, id, ifVersion int64) error {
+	query := `UPDATE ` + table + ` SET version = version WHERE id = ?`
+	args := []any{id}
+	if ifVersion != 0 {
+		query += ` AND version = ?`
+		args = append(args, ifVersion)
+	}
+	res, err := tx.ExecContext(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("checking %s version: %w", table, err)
+	}
+	if n, _ := res.RowsAffected(); n > 0 {
+		return nil
+	}
+
+	var exists bool
+	if err := tx.QueryRowContext(ctx,
+		`SELECT EXISTS(SELECT 1 FROM `+table+` WHERE id = ?)`, id).Scan(&exists); err != nil {
+		return fmt.Errorf("checking %s: %w", table, err)
+	}
+	if !exists {
+		return ErrNotFound
+	}
+	return ErrVersionConflict
+}
+
+// ArchiveReadItems moves every reading list item with status "read" to
+// "archived", keeping its read_at, and returns how many were archived.
+func (s *sqlStore) ArchiveReadItems(ctx context.Context) (int64, error) {
+	res, err := s.db.ExecContext(ctx,
+		`UPDATE reading_list SET status = 'archived', archived_at = datetime('now')
+		 WHERE status = 'read'`)
+	if err != nil {
+		return 0, fmt.Errorf("archiving read items: %w", err)
+	}
+	n, _ := res.RowsAffected()
+	return n, nil
+}
+
+// SnoozeReadingListItem hides a reading list item from the default list
+// until the given time, when WakeSnoozedItems moves it back to unread. A nil
+// until clears the snooze.
+func (s *sqlStore) SnoozeReadingListItem(ctx context.Context, id int64, until *time.Time) error {
+	var v *string
+	if until != nil {
+		t := until.UTC().Format("2006-01-02 15:04:05")
+		v = &t
+	}
+	res, err := s.db.ExecContext(ctx,
+		`UPDATE reading_list SET snoozed_until = ? WHERE id = ?`, v, id)
+	if err != nil {
+		return fmt.Errorf("snoozing reading list item: %w", err)
 	}
 	n, _ := res.RowsAffected()
 	if n == 0 {
@@ -159,8 +499,24 @@ func (s *Store) UpdateReadingListStatus(ctx context.Context, id int64, status st
 	return nil
 }
 
-// UpdateReadingListNotes updates the notes field of a reading list item.
-func (s *Store) UpdateReadingListNotes(ctx context.Context, id int64, notes string) error {
+// WakeSnoozedItems moves every item whose snooze ended at or before now back
+// to "unread", clearing its snooze, and returns how many were woken.
+func (s *sqlStore) WakeSnoozedItems(ctx context.Context, now time.Time) (int64, error) {
+	res, err := s.db.ExecContext(ctx,
+		`UPDATE reading_list SET status = 'unread', read_at = NULL, archived_at = NULL, snoozed_until = NULL
+		 WHERE snoozed_until IS NOT NULL AND snoozed_until <= ?`,
+		now.UTC().Format("2006-01-02 15:04:05"))
+	if err != nil {
+		return 0, fmt.Errorf("waking snoozed items: %w", err)
+	}
+	n, _ := res.RowsAffected()
+	return n, nil
+}
+
+// UpdateReadingListNotes updates the Markdown notes of a reading list item.
+// Every distinct non-empty value is also kept as a revision; see
+// GetNoteHistory.
+func (s *sqlStore) UpdateReadingListNotes(ctx context.Context, id int64, notes string) error {
 	res, err := s.db.ExecContext(ctx,
 		`UPDATE reading_list SET notes = ? WHERE id = ?`,
 		nullableString(notes), id,
@@ -175,74 +531,69 @@ func (s *Store) UpdateReadingListNotes(ctx context.Context, id int64, notes stri
 	return nil
 }
 
+// GetNoteHistory returns the saved revisions of a reading list item's notes,
+// newest (the current notes) first. Returns ErrNotFound if the item does not
+// exist.
+func (s *sqlStore) GetNoteHistory(ctx context.Context, id int64) ([]models.NoteRevision, error) {
+	var exists bool
+	if err := s.rdb.QueryRowContext(ctx,
+		`SELECT EXISTS(SELECT 1 FROM reading_list WHERE id = ?)`, id,
+	).Scan(&exists); err != nil {
+		return nil, fmt.Errorf("checking reading list item: %w", err)
+	}
+	if !exists {
+		return nil, ErrNotFound
+	}
+
+	rows, err := s.rdb.QueryContext(ctx,
+		`SELECT id, notes, created_at FROM note_revisions
+		 WHERE reading_list_id = ?
+		 ORDER BY id DESC`, id)
+	if err != nil {
+		return nil, fmt.Errorf("querying note history: %w", err)
+	}
+	defer rows.Close()
+
+	revisions := []models.NoteRevision{}
+	for rows.Next() {
+		var (
+			rev       models.NoteRevision
+			createdAt string
+		)
+		if err := rows.Scan(&rev.ID, &rev.Notes, &createdAt); err != nil {
+			return nil, fmt.Errorf("scanning note revision: %w", err)
+		}
+		rev.CreatedAt = parseTime(createdAt)
+		revisions = append(revisions, rev)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating note history: %w", err)
+	}
+	return revisions, nil
+}
+
 // GetReadingListItemByID returns a single reading list item with its blog and
 // summary data. Returns ErrNotFound if the item does not exist.
-func (s *Store) GetReadingListItemByID(ctx context.Context, id int64) (*models.ReadingListItem, error) {
-	row := s.db.QueryRowContext(ctx, `
-		SELECT rl.id, rl.blog_id, rl.status, rl.progress, rl.notes, rl.added_at, rl.read_at,
-			   b.id, b.source_id, COALESCE(b.custom_source, bs.name, '') AS source, b.title, b.url,
-			   b.description, b.full_content, b.published_at, b.fetched_at,
-			   b.content_hash, b.reading_time_minutes, b.created_at,
-			   s.summary
-		FROM reading_list rl
-		JOIN blogs b ON b.id = rl.blog_id
-		LEFT JOIN blog_sources bs ON bs.id = b.source_id
-		LEFT JOIN blog_summaries s ON s.blog_id = rl.blog_id
+func (s *sqlStore) GetReadingListItemByID(ctx context.Context, id int64) (*models.ReadingListItem, error) {
+	row := s.rdb.QueryRowContext(ctx, readingListSelect+`
 		WHERE rl.id = ?`, id)
 
-	var (
-		item           models.ReadingListItem
-		notes          sql.NullString
-		addedAt        string
-		readAt         sql.NullString
-		blog           models.Blog
-		description    sql.NullString
-		fullContent    sql.NullString
-		publishedAt    sql.NullString
-		fetchedAt      string
-		contentHash    sql.NullString
-		readingTimeMin sql.NullInt64
-		blogCreated    string
-		summary        sql.NullString
-	)
-
-	if err := row.Scan(
-		&item.ID, &item.BlogID, &item.Status, &item.Progress, &notes, &addedAt, &readAt,
-		&blog.ID, &blog.SourceID, &blog.Source, &blog.Title, &blog.URL,
-		&description, &fullContent, &publishedAt, &fetchedAt,
-		&contentHash, &readingTimeMin, &blogCreated,
-		&summary,
-	); err != nil {
+	item, err := scanReadingListItem(row)
+	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, ErrNotFound
 		}
 		return nil, fmt.Errorf("getting reading list item: %w", err)
 	}
 
-	if notes.Valid {
-		item.Notes = &notes.String
-	}
-	item.AddedAt = parseTime(addedAt)
-	item.ReadAt = parseTimePtr(nullStringToPtr(readAt))
-
-	blog.Description = description.String
-	blog.FullContent = fullContent.String
-	blog.ContentHash = contentHash.String
-	if readingTimeMin.Valid {
-		v := int(readingTimeMin.Int64)
-		blog.ReadingTimeMinutes = &v
-	}
-	blog.PublishedAt = parseTimePtr(nullStringToPtr(publishedAt))
-	blog.FetchedAt = parseTime(fetchedAt)
-	blog.CreatedAt = parseTime(blogCreated)
-	item.Blog = &blog
-
-	if summary.Valid {
-		item.Summary = &summary.String
+	if item.Blog != nil {
+		if err := s.rehydrateContent(ctx, item.Blog); err != nil {
+			return nil, err
+		}
 	}
 
 	item.Tags = []string{}
-	items := []models.ReadingListItem{item}
+	items := []models.ReadingListItem{*item}
 	if err := s.loadTagsForItems(ctx, items); err != nil {
 		return nil, fmt.Errorf("loading tags: %w", err)
 	}
@@ -250,16 +601,38 @@ func (s *Store) GetReadingListItemByID(ctx context.Context, id int64) (*models.R
 	return &items[0], nil
 }
 
+// GetReadingListIDByBlogID returns the ID of the reading list item for the
+// given blog. Returns ErrNotFound if the blog is not on the reading list.
+func (s *sqlStore) GetReadingListIDByBlogID(ctx context.Context, blogID int64) (int64, error) {
+	var id int64
+	err := s.rdb.QueryRowContext(ctx,
+		`SELECT id FROM reading_list WHERE blog_id = ?`, blogID,
+	).Scan(&id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, ErrNotFound
+		}
+		return 0, fmt.Errorf("getting reading list id: %w", err)
+	}
+	return id, nil
+}
+
 // UpdateReadingListProgress updates the scroll progress (0-100) of a reading
-// list item.
-func (s *Store) UpdateReadingListProgress(ctx context.Context, id int64, progress int) error {
+// list item and records that it was read now. A non-nil scrollPosition, a
+// fraction from 0 to 1, replaces where the reader left the item; a nil one
+// keeps it.
+func (s *sqlStore) UpdateReadingListProgress(ctx context.Context, id int64, progress int, scrollPosition *float64) error {
 	if progress < 0 || progress > 100 {
 		return fmt.Errorf("progress must be between 0 and 100, got %d", progress)
 	}
+	if scrollPosition != nil && (*scrollPosition < 0 || *scrollPosition > 1) {
+		return fmt.Errorf("scroll position must be between 0 and 1, got %g", *scrollPosition)
+	}
 
 	res, err := s.db.ExecContext(ctx,
-		`UPDATE reading_list SET progress = ? WHERE id = ?`,
-		progress, id,
+		`UPDATE reading_list SET progress = ?, scroll_position = COALESCE(?, scroll_position), last_read_at = datetime('now')
+		 WHERE id = ?`,
+		progress, scrollPosition, id,
 	)
 	if err != nil {
 		return fmt.Errorf("updating reading list progress: %w", err)
@@ -271,17 +644,193 @@ func (s *Store) UpdateReadingListProgress(ctx context.Context, id int64, progres
 	return nil
 }
 
-// RemoveFromReadingList deletes a reading list item by ID.
-func (s *Store) RemoveFromReadingList(ctx context.Context, id int64) error {
-	res, err := s.db.ExecContext(ctx,
-		`DELETE FROM reading_list WHERE id = ?`, id,
+// RemoveFromReadingList deletes a reading list item by ID, recording what
+// was deleted in the audit log.
+func (s *sqlStore) RemoveFromReadingList(ctx context.Context, id int64) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("beginning transaction: %w", err)
+	}
+	defer tx.Rollback() //nolint:errcheck // rollback after commit is a no-op
+
+	if err := deleteReadingListItem(ctx, tx, id); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("committing transaction: %w", err)
+	}
+	return nil
+}
+
+// deleteReadingListItem deletes a reading list item as part of tx and
+// records it in the audit log with the post it referred to.
+func deleteReadingListItem(ctx context.Context, tx *sql.Tx, id int64) error {
+	var (
+		blogID             int64
+		title, url, status string
+		notes              sql.NullString
 	)
+	err := tx.QueryRowContext(ctx,
+		`SELECT rl.blog_id, b.title, b.url, rl.status, rl.notes
+		 FROM reading_list rl JOIN blogs b ON b.id = rl.blog_id
+		 WHERE rl.id = ?`, id).Scan(&blogID, &title, &url, &status, &notes)
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("reading list item %d: %w", id, ErrNotFound)
+	}
 	if err != nil {
+		return fmt.Errorf("reading reading list item %d: %w", id, err)
+	}
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM reading_list WHERE id = ?`, id); err != nil {
 		return fmt.Errorf("removing from reading list: %w", err)
 	}
-	n, _ := res.RowsAffected()
-	if n == 0 {
-		return ErrNotFound
+	return recordAudit(ctx, tx, AuditReadingListDelete, "reading_list_item", id, map[string]any{
+		"blog_id": blogID, "title": title, "url": url, "status": status, "notes": notes.String,
+	}, nil)
+}
+
+// Bulk actions on reading list items, as named in a BulkAction.
+const (
+	BulkSetStatus = "set_status"
+	BulkAddTag    = "add_tag"
+	BulkDelete    = "delete"
+	BulkArchive   = "archive"
+)
+
+// ReorderReadingList moves the given items to the front of the queue, in
+// the given order. Items placed earlier keep their relative order after
+// them; items never placed stay last, newest first. Returns an error
+// wrapping ErrNotFound if any ID does not exist, in which case nothing
+// changes.
+func (s *sqlStore) ReorderReadingList(ctx context.Context, ids []int64) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("beginning transaction: %w", err)
+	}
+	defer tx.Rollback() //nolint:errcheck // rollback after commit is a no-op
+
+	if _, err := tx.ExecContext(ctx,
+		`UPDATE reading_list SET position = position + ? WHERE position IS NOT NULL`, len(ids),
+	); err != nil {
+		return fmt.Errorf("shifting reading list positions: %w", err)
+	}
+
+	for i, id := range ids {
+		res, err := tx.ExecContext(ctx,
+			`UPDATE reading_list SET position = ? WHERE id = ?`, i+1, id)
+		if err != nil {
+			return fmt.Errorf("positioning item %d: %w", id, err)
+		}
+		if n, _ := res.RowsAffected(); n == 0 {
+			return fmt.Errorf("reading list item %d: %w", id, ErrNotFound)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("committing transaction: %w", err)
+	}
+	return nil
+}
+
+// BulkAction is an action applied to many reading list items at once by
+// BulkUpdateReadingList.
+type BulkAction struct {
+	Action string // one of the Bulk* constants
+	Status string // the new status, for BulkSetStatus
+	Tag    string // the tag to add, for BulkAddTag
+}
+
+// BulkUpdateReadingList applies action to every item in ids inside a single
+// transaction, once per distinct ID. If any item does not exist, nothing is
+// changed and an error wrapping ErrNotFound is returned. Archiving sets the status to "archived" whatever
+// the current status, like UpdateReadingListStatus.
+func (s *sqlStore) BulkUpdateReadingList(ctx context.Context, ids []int64, action BulkAction) error {
+	var (
+		query string
+		args  []any
+	)
+	switch action.Action {
+	case BulkSetStatus:
+		if !validStatuses[action.Status] {
+			return fmt.Errorf("invalid reading list status %q: must be one of unread, reading, read, archived", action.Status)
+		}
+		query, args = statusUpdateQuery(action.Status), []any{action.Status}
+	case BulkArchive:
+		query, args = statusUpdateQuery("archived"), []any{"archived"}
+	case BulkDelete:
+		// Deleted one by one with deleteReadingListItem, to audit them.
+	case BulkAddTag:
+		action.Tag = strings.TrimSpace(strings.ToLower(action.Tag))
+		if action.Tag == "" {
+			return fmt.Errorf("tag name cannot be empty")
+		}
+		query = `INSERT INTO reading_list_tags (reading_list_id, tag_id)
+			SELECT rl.id, t.id FROM reading_list rl, tags t WHERE t.name = ? AND rl.id = ?
+			ON CONFLICT DO NOTHING`
+		args = []any{action.Tag}
+	default:
+		return fmt.Errorf("unknown bulk action %q", action.Action)
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("beginning transaction: %w", err)
+	}
+	defer tx.Rollback() //nolint:errcheck // rollback after commit is a no-op
+
+	if action.Action == BulkAddTag {
+		if _, err := tx.ExecContext(ctx,
+			`INSERT INTO tags (name) VALUES (?) ON CONFLICT DO NOTHING`, action.Tag); err != nil {
+			return fmt.Errorf("creating tag: %w", err)
+		}
+	}
+
+	seen := make(map[int64]bool, len(ids))
+	if action.Action == BulkDelete {
+		for _, id := range ids {
+			if seen[id] {
+				continue
+			}
+			seen[id] = true
+			if err := deleteReadingListItem(ctx, tx, id); err != nil {
+				return err
+			}
+		}
+		if err := tx.Commit(); err != nil {
+			return fmt.Errorf("committing transaction: %w", err)
+		}
+		return nil
+	}
+
+	stmt, err := tx.PrepareContext(ctx, query)
+	if err != nil {
+		return fmt.Errorf("preparing bulk %s: %w", action.Action, err)
+	}
+	defer stmt.Close()
+
+	for _, id := range ids {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+
+		var exists bool
+		if err := tx.QueryRowContext(ctx,
+			`SELECT EXISTS(SELECT 1 FROM reading_list WHERE id = ?)`, id,
+		).Scan(&exists); err != nil {
+			return fmt.Errorf("checking reading list item: %w", err)
+		}
+		if !exists {
+			return fmt.Errorf("reading list item %d: %w", id, ErrNotFound)
+		}
+		if _, err := stmt.ExecContext(ctx, append(args, id)...); err != nil {
+			return fmt.Errorf("bulk %s of item %d: %w", action.Action, id, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("committing transaction: %w", err)
 	}
 	return nil
 }
@@ -3,6 +3,7 @@ package storage
 import (
 	"context"
 	"errors"
+	"slices"
 	"strings"
 	"testing"
 	"time"
@@ -11,7 +12,7 @@ import (
 )
 
 // seedReadingListBlog creates a source and blog suitable for reading list tests.
-func seedReadingListBlog(t *testing.T, store *Store, url string) int64 {
+func seedReadingListBlog(t *testing.T, store *SQLiteStore, url string) int64 {
 	t.Helper()
 	ctx := context.Background()
 
@@ -78,8 +79,8 @@ func TestAddToReadingList_DuplicateError(t *testing.T) {
 	if err == nil {
 		t.Fatal("expected error for duplicate, got nil")
 	}
-	if !strings.Contains(err.Error(), "already on the reading list") {
-		t.Errorf("expected 'already on the reading list' error, got: %v", err)
+	if !errors.Is(err, ErrAlreadyOnList) {
+		t.Errorf("expected ErrAlreadyOnList, got: %v", err)
 	}
 }
 
@@ -96,6 +97,61 @@ func TestAddToReadingList_NonExistentBlog(t *testing.T) {
 	}
 }
 
+func TestAddManyToReadingList(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+	a := seedReadingListBlog(t, store, "https://example.com/many-a")
+	b := seedReadingListBlog(t, store, "https://example.com/many-b")
+	c := seedReadingListBlog(t, store, "https://example.com/many-c")
+	if err := store.AddToReadingList(ctx, b); err != nil {
+		t.Fatalf("AddToReadingList() error: %v", err)
+	}
+
+	// A missing blog adds nothing.
+	if _, err := store.AddManyToReadingList(ctx, []int64{a, 99999}); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("AddManyToReadingList(missing) error = %v, want ErrNotFound", err)
+	}
+	if _, err := store.GetReadingListIDByBlogID(ctx, a); err == nil {
+		t.Error("blog a was added despite the failure")
+	}
+
+	added, err := store.AddManyToReadingList(ctx, []int64{a, b, c})
+	if err != nil {
+		t.Fatalf("AddManyToReadingList() error: %v", err)
+	}
+	if want := []int64{a, c}; !slices.Equal(added, want) {
+		t.Errorf("added = %v, want %v", added, want)
+	}
+	items, err := store.GetReadingList(ctx, "")
+	if err != nil {
+		t.Fatalf("GetReadingList() error: %v", err)
+	}
+	if len(items) != 3 || items[0].BlogID != a || items[1].BlogID != c || items[0].Status != "unread" {
+		t.Errorf("items = %+v, want 3 with the added ones first, in order", items)
+	}
+
+	// Once the queue is reordered, added blogs go ahead of the pinned items.
+	if err := store.ReorderReadingList(ctx, []int64{items[2].ID}); err != nil {
+		t.Fatalf("ReorderReadingList() error: %v", err)
+	}
+	d := seedReadingListBlog(t, store, "https://example.com/many-d")
+	e := seedReadingListBlog(t, store, "https://example.com/many-e")
+	if _, err := store.AddManyToReadingList(ctx, []int64{d, e}); err != nil {
+		t.Fatalf("AddManyToReadingList() error: %v", err)
+	}
+	items, err = store.GetReadingList(ctx, "")
+	if err != nil {
+		t.Fatalf("GetReadingList() error: %v", err)
+	}
+	var order []int64
+	for _, item := range items {
+		order = append(order, item.BlogID)
+	}
+	if want := []int64{d, e, b, a, c}; !slices.Equal(order, want) {
+		t.Errorf("order = %v, want %v", order, want)
+	}
+}
+
 func TestGetReadingList_FilterByStatus(t *testing.T) {
 	store := newTestStore(t)
 	ctx := context.Background()
@@ -201,6 +257,149 @@ func TestUpdateReadingListStatus_Transitions(t *testing.T) {
 	}
 }
 
+func TestArchiveReadItems(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+
+	read := seedReadingListBlog(t, store, "https://test.com/rl-a1")
+	unread := seedReadingListBlog(t, store, "https://test.com/rl-a2")
+	for _, id := range []int64{read, unread} {
+		if err := store.AddToReadingList(ctx, id); err != nil {
+			t.Fatalf("AddToReadingList(%d) error: %v", id, err)
+		}
+	}
+	readItem, err := store.GetReadingListIDByBlogID(ctx, read)
+	if err != nil {
+		t.Fatalf("GetReadingListIDByBlogID() error: %v", err)
+	}
+	if err := store.UpdateReadingListStatus(ctx, readItem, "read"); err != nil {
+		t.Fatalf("UpdateReadingListStatus(read) error: %v", err)
+	}
+
+	n, err := store.ArchiveReadItems(ctx)
+	if err != nil {
+		t.Fatalf("ArchiveReadItems() error: %v", err)
+	}
+	if n != 1 {
+		t.Errorf("ArchiveReadItems() = %d, want 1", n)
+	}
+
+	archived, err := store.GetReadingList(ctx, "archived")
+	if err != nil {
+		t.Fatalf("GetReadingList(archived) error: %v", err)
+	}
+	if len(archived) != 1 || archived[0].BlogID != read {
+		t.Fatalf("got %d archived items, want only blog %d", len(archived), read)
+	}
+	if archived[0].ArchivedAt == nil {
+		t.Error("ArchivedAt should be set for archived items")
+	}
+	if archived[0].ReadAt == nil {
+		t.Error("ReadAt should be kept when archiving")
+	}
+
+	if active, _ := store.GetReadingList(ctx, "unread"); len(active) != 1 {
+		t.Errorf("got %d unread items, want 1 (left unarchived)", len(active))
+	}
+
+	// Archived items still count as read in reading history.
+	year := archived[0].ReadAt.Year()
+	report, err := store.GetYearReport(ctx, year)
+	if err != nil {
+		t.Fatalf("GetYearReport() error: %v", err)
+	}
+	if report.ArticlesRead != 1 {
+		t.Errorf("ArticlesRead = %d, want 1 including the archived item", report.ArticlesRead)
+	}
+
+	// Unarchiving keeps the original read time.
+	if _, err := store.db.Exec(`UPDATE reading_list SET read_at = '2020-01-02 03:04:05' WHERE id = ?`, readItem); err != nil {
+		t.Fatalf("backdating read_at: %v", err)
+	}
+	if err := store.UpdateReadingListStatus(ctx, readItem, "read"); err != nil {
+		t.Fatalf("UpdateReadingListStatus(read) error: %v", err)
+	}
+	items, _ := store.GetReadingList(ctx, "read")
+	if len(items) != 1 || items[0].ReadAt == nil || items[0].ReadAt.Year() != 2020 {
+		t.Errorf("unarchived item lost its read_at: %+v", items)
+	}
+	if len(items) == 1 && items[0].ArchivedAt != nil {
+		t.Error("ArchivedAt should be cleared when unarchiving")
+	}
+}
+
+func TestSnoozeReadingListItem(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+
+	snoozed := seedReadingListBlog(t, store, "https://test.com/rl-s1")
+	awake := seedReadingListBlog(t, store, "https://test.com/rl-s2")
+	for _, id := range []int64{snoozed, awake} {
+		if err := store.AddToReadingList(ctx, id); err != nil {
+			t.Fatalf("AddToReadingList(%d) error: %v", id, err)
+		}
+	}
+	itemID, err := store.GetReadingListIDByBlogID(ctx, snoozed)
+	if err != nil {
+		t.Fatalf("GetReadingListIDByBlogID() error: %v", err)
+	}
+	if err := store.UpdateReadingListStatus(ctx, itemID, "reading"); err != nil {
+		t.Fatalf("UpdateReadingListStatus(reading) error: %v", err)
+	}
+	until := time.Now().Add(24 * time.Hour)
+	if err := store.SnoozeReadingListItem(ctx, itemID, &until); err != nil {
+		t.Fatalf("SnoozeReadingListItem() error: %v", err)
+	}
+
+	hidden, err := store.GetReadingListFiltered(ctx, ReadingListFilter{Snoozed: SnoozeHide})
+	if err != nil {
+		t.Fatalf("GetReadingListFiltered(hide) error: %v", err)
+	}
+	if len(hidden) != 1 || hidden[0].BlogID != awake {
+		t.Errorf("got %d items with snoozed hidden, want only blog %d", len(hidden), awake)
+	}
+	if n, _ := store.CountReadingList(ctx, ReadingListFilter{Snoozed: SnoozeHide}); n != 1 {
+		t.Errorf("CountReadingList(hide) = %d, want 1", n)
+	}
+
+	only, err := store.GetReadingListFiltered(ctx, ReadingListFilter{Snoozed: SnoozeOnly})
+	if err != nil {
+		t.Fatalf("GetReadingListFiltered(only) error: %v", err)
+	}
+	if len(only) != 1 || only[0].SnoozedUntil == nil {
+		t.Fatalf("got %+v, want the snoozed item with SnoozedUntil set", only)
+	}
+	if all, _ := store.GetReadingList(ctx, ""); len(all) != 2 {
+		t.Errorf("GetReadingList() = %d items, want 2 including the snoozed one", len(all))
+	}
+
+	// Nothing is due yet.
+	if n, err := store.WakeSnoozedItems(ctx, time.Now()); err != nil || n != 0 {
+		t.Fatalf("WakeSnoozedItems(now) = %d, %v; want 0, nil", n, err)
+	}
+	n, err := store.WakeSnoozedItems(ctx, until.Add(time.Minute))
+	if err != nil {
+		t.Fatalf("WakeSnoozedItems() error: %v", err)
+	}
+	if n != 1 {
+		t.Errorf("WakeSnoozedItems() = %d, want 1", n)
+	}
+	item, err := store.GetReadingListItemByID(ctx, itemID)
+	if err != nil {
+		t.Fatalf("GetReadingListItemByID() error: %v", err)
+	}
+	if item.Status != "unread" {
+		t.Errorf("Status = %q after waking, want unread", item.Status)
+	}
+	if item.SnoozedUntil != nil {
+		t.Error("SnoozedUntil should be cleared after waking")
+	}
+
+	if err := store.SnoozeReadingListItem(ctx, 99999, &until); !errors.Is(err, ErrNotFound) {
+		t.Errorf("SnoozeReadingListItem(missing) error = %v, want ErrNotFound", err)
+	}
+}
+
 func TestUpdateReadingListStatus_InvalidStatus(t *testing.T) {
 	store := newTestStore(t)
 	ctx := context.Background()
@@ -246,6 +445,48 @@ func TestUpdateReadingListNotes(t *testing.T) {
 	}
 }
 
+func TestGetNoteHistory(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+	blogID := seedReadingListBlog(t, store, "https://test.com/rl-notes-history")
+
+	if err := store.AddToReadingList(ctx, blogID); err != nil {
+		t.Fatalf("AddToReadingList() error: %v", err)
+	}
+	itemID, err := store.GetReadingListIDByBlogID(ctx, blogID)
+	if err != nil {
+		t.Fatalf("GetReadingListIDByBlogID() error: %v", err)
+	}
+
+	// Saving the same text twice or clearing the notes adds no revision.
+	for _, notes := range []string{"# Draft", "# Draft\n\n- point one", "# Draft\n\n- point one", "", "oops"} {
+		if err := store.UpdateReadingListNotes(ctx, itemID, notes); err != nil {
+			t.Fatalf("UpdateReadingListNotes(%q) error: %v", notes, err)
+		}
+	}
+
+	history, err := store.GetNoteHistory(ctx, itemID)
+	if err != nil {
+		t.Fatalf("GetNoteHistory() error: %v", err)
+	}
+	want := []string{"oops", "# Draft\n\n- point one", "# Draft"}
+	if len(history) != len(want) {
+		t.Fatalf("got %d revisions, want %d", len(history), len(want))
+	}
+	for i, rev := range history {
+		if rev.Notes != want[i] {
+			t.Errorf("history[%d] = %q, want %q", i, rev.Notes, want[i])
+		}
+		if rev.CreatedAt.IsZero() {
+			t.Errorf("history[%d] has no CreatedAt", i)
+		}
+	}
+
+	if _, err := store.GetNoteHistory(ctx, 99999); !errors.Is(err, ErrNotFound) {
+		t.Errorf("GetNoteHistory(unknown) error = %v, want ErrNotFound", err)
+	}
+}
+
 func TestUpdateReadingListNotes_NotFound(t *testing.T) {
 	store := newTestStore(t)
 	ctx := context.Background()
@@ -324,3 +565,333 @@ func TestGetReadingList_WithSummary(t *testing.T) {
 		t.Errorf("Summary = %q, want %q", *items[0].Summary, "Test summary for reading list.")
 	}
 }
+
+func TestGetReadingListFiltered_ByDifficulty(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+
+	blog1 := seedReadingListBlog(t, store, "https://test.com/rl-d1")
+	blog2 := seedReadingListBlog(t, store, "https://test.com/rl-d2")
+
+	for _, id := range []int64{blog1, blog2} {
+		if err := store.AddToReadingList(ctx, id); err != nil {
+			t.Fatalf("AddToReadingList(%d) error: %v", id, err)
+		}
+	}
+	if err := store.UpdateBlogDifficulty(ctx, blog1, models.DifficultyDeepDive); err != nil {
+		t.Fatalf("UpdateBlogDifficulty() error: %v", err)
+	}
+
+	items, err := store.GetReadingListFiltered(ctx, ReadingListFilter{Difficulty: models.DifficultyDeepDive})
+	if err != nil {
+		t.Fatalf("GetReadingListFiltered() error: %v", err)
+	}
+	if len(items) != 1 {
+		t.Fatalf("got %d items, want 1", len(items))
+	}
+	if items[0].BlogID != blog1 {
+		t.Errorf("BlogID = %d, want %d", items[0].BlogID, blog1)
+	}
+	if items[0].Blog.Difficulty != models.DifficultyDeepDive {
+		t.Errorf("Blog.Difficulty = %q, want %q", items[0].Blog.Difficulty, models.DifficultyDeepDive)
+	}
+
+	none, err := store.GetReadingListFiltered(ctx, ReadingListFilter{Status: "read", Difficulty: models.DifficultyDeepDive})
+	if err != nil {
+		t.Fatalf("GetReadingListFiltered(read) error: %v", err)
+	}
+	if len(none) != 0 {
+		t.Errorf("got %d read items, want 0", len(none))
+	}
+}
+
+func TestGetReadingListFiltered_BySummaryClassification(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+
+	deep := seedReadingListBlog(t, store, "https://test.com/rl-c1")
+	intro := seedReadingListBlog(t, store, "https://test.com/rl-c2")
+
+	for _, s := range []struct {
+		blogID     int64
+		difficulty string
+		category   string
+	}{
+		{deep, models.DifficultyDeepDive, "databases"},
+		{intro, models.DifficultyIntro, "security"},
+	} {
+		if err := store.AddToReadingList(ctx, s.blogID); err != nil {
+			t.Fatalf("AddToReadingList(%d) error: %v", s.blogID, err)
+		}
+		if err := store.UpsertSummary(ctx, &models.BlogSummary{
+			BlogID: s.blogID, Summary: "s", Difficulty: s.difficulty, Category: s.category, ModelUsed: "m",
+		}); err != nil {
+			t.Fatalf("UpsertSummary(%d) error: %v", s.blogID, err)
+		}
+	}
+
+	// Blogs without a standalone classification fall back to the summary's.
+	deepOnly, err := store.GetReadingListFiltered(ctx, ReadingListFilter{Difficulty: models.DifficultyDeepDive})
+	if err != nil {
+		t.Fatalf("GetReadingListFiltered(difficulty) error: %v", err)
+	}
+	if len(deepOnly) != 1 || deepOnly[0].BlogID != deep {
+		t.Errorf("deep-dive filter returned %d items, want only blog %d", len(deepOnly), deep)
+	}
+
+	security, err := store.GetReadingListFiltered(ctx, ReadingListFilter{Category: "security"})
+	if err != nil {
+		t.Fatalf("GetReadingListFiltered(category) error: %v", err)
+	}
+	if len(security) != 1 || security[0].BlogID != intro {
+		t.Fatalf("category filter returned %d items, want only blog %d", len(security), intro)
+	}
+	if security[0].Category != "security" {
+		t.Errorf("Category = %q, want %q", security[0].Category, "security")
+	}
+}
+
+func TestGetReadingListFiltered_Pagination(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+
+	for i := range 5 {
+		id := seedReadingListBlog(t, store, "https://test.com/rl-p"+string(rune('a'+i)))
+		if err := store.AddToReadingList(ctx, id); err != nil {
+			t.Fatalf("AddToReadingList(%d) error: %v", id, err)
+		}
+	}
+
+	all, err := store.GetReadingList(ctx, "")
+	if err != nil {
+		t.Fatalf("GetReadingList() error: %v", err)
+	}
+
+	page, err := store.GetReadingListFiltered(ctx, ReadingListFilter{Limit: 2, Offset: 2})
+	if err != nil {
+		t.Fatalf("GetReadingListFiltered() error: %v", err)
+	}
+	if len(page) != 2 || page[0].ID != all[2].ID || page[1].ID != all[3].ID {
+		t.Errorf("page with offset 2 does not match items 2 and 3 of the full list")
+	}
+
+	tail, err := store.GetReadingListFiltered(ctx, ReadingListFilter{Offset: 4})
+	if err != nil {
+		t.Fatalf("GetReadingListFiltered(offset only) error: %v", err)
+	}
+	if len(tail) != 1 || tail[0].ID != all[4].ID {
+		t.Errorf("offset without limit returned %d items, want the last item", len(tail))
+	}
+
+	total, err := store.CountReadingList(ctx, ReadingListFilter{Limit: 2, Offset: 2})
+	if err != nil {
+		t.Fatalf("CountReadingList() error: %v", err)
+	}
+	if total != 5 {
+		t.Errorf("CountReadingList() = %d, want 5", total)
+	}
+	read, err := store.CountReadingList(ctx, ReadingListFilter{Status: "read"})
+	if err != nil {
+		t.Fatalf("CountReadingList(read) error: %v", err)
+	}
+	if read != 0 {
+		t.Errorf("CountReadingList(read) = %d, want 0", read)
+	}
+}
+
+func TestGetReadingListFiltered_WithoutContent(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+
+	id, err := store.UpsertBlog(ctx, &models.Blog{
+		SourceID:    seedTestSource(t, store),
+		Title:       "Long Post",
+		URL:         "https://test.com/rl-long",
+		FullContent: strings.Repeat("word ", 1000),
+		FetchedAt:   time.Now(),
+	})
+	if err != nil {
+		t.Fatalf("UpsertBlog() error: %v", err)
+	}
+	if err := store.AddToReadingList(ctx, id); err != nil {
+		t.Fatalf("AddToReadingList() error: %v", err)
+	}
+
+	items, err := store.GetReadingListFiltered(ctx, ReadingListFilter{WithoutContent: true})
+	if err != nil {
+		t.Fatalf("GetReadingListFiltered() error: %v", err)
+	}
+	if len(items) != 1 {
+		t.Fatalf("got %d items, want 1", len(items))
+	}
+	if items[0].Blog.FullContent != "" {
+		t.Error("FullContent is set, want it omitted")
+	}
+	if items[0].Blog.Title != "Long Post" {
+		t.Errorf("Title = %q, want %q", items[0].Blog.Title, "Long Post")
+	}
+}
+
+func TestReorderReadingList(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+
+	var ids []int64
+	for _, url := range []string{"https://test.com/rl-o1", "https://test.com/rl-o2", "https://test.com/rl-o3", "https://test.com/rl-o4"} {
+		blogID := seedReadingListBlog(t, store, url)
+		if err := store.AddToReadingList(ctx, blogID); err != nil {
+			t.Fatalf("AddToReadingList(%d) error: %v", blogID, err)
+		}
+		id, err := store.GetReadingListIDByBlogID(ctx, blogID)
+		if err != nil {
+			t.Fatalf("GetReadingListIDByBlogID() error: %v", err)
+		}
+		ids = append(ids, id)
+	}
+	order := func() []int64 {
+		t.Helper()
+		items, err := store.GetReadingList(ctx, "")
+		if err != nil {
+			t.Fatalf("GetReadingList() error: %v", err)
+		}
+		got := make([]int64, len(items))
+		for i, item := range items {
+			got[i] = item.ID
+		}
+		return got
+	}
+	assertOrder := func(want ...int64) {
+		t.Helper()
+		if got := order(); !slices.Equal(got, want) {
+			t.Errorf("order = %v, want %v", got, want)
+		}
+	}
+
+	// Unplaced items are newest first.
+	assertOrder(ids[3], ids[2], ids[1], ids[0])
+
+	if err := store.ReorderReadingList(ctx, []int64{ids[0], ids[2]}); err != nil {
+		t.Fatalf("ReorderReadingList() error: %v", err)
+	}
+	assertOrder(ids[0], ids[2], ids[3], ids[1])
+
+	// Newly placed items go ahead of earlier ones, which keep their order.
+	if err := store.ReorderReadingList(ctx, []int64{ids[1]}); err != nil {
+		t.Fatalf("ReorderReadingList() error: %v", err)
+	}
+	assertOrder(ids[1], ids[0], ids[2], ids[3])
+
+	err := store.ReorderReadingList(ctx, []int64{ids[3], 99999})
+	if !errors.Is(err, ErrNotFound) {
+		t.Fatalf("ReorderReadingList(unknown id) error = %v, want ErrNotFound", err)
+	}
+	assertOrder(ids[1], ids[0], ids[2], ids[3])
+}
+
+func TestBulkUpdateReadingList(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+
+	var ids []int64
+	for i := range 3 {
+		blogID := seedReadingListBlog(t, store, "https://test.com/rl-bulk"+string(rune('a'+i)))
+		if err := store.AddToReadingList(ctx, blogID); err != nil {
+			t.Fatalf("AddToReadingList(%d) error: %v", blogID, err)
+		}
+		id, err := store.GetReadingListIDByBlogID(ctx, blogID)
+		if err != nil {
+			t.Fatalf("GetReadingListIDByBlogID() error: %v", err)
+		}
+		ids = append(ids, id)
+	}
+
+	if err := store.BulkUpdateReadingList(ctx, ids[:2], BulkAction{Action: BulkSetStatus, Status: "read"}); err != nil {
+		t.Fatalf("BulkUpdateReadingList(set_status) error: %v", err)
+	}
+	if read, _ := store.GetReadingList(ctx, "read"); len(read) != 2 {
+		t.Errorf("got %d read items, want 2", len(read))
+	}
+
+	if err := store.BulkUpdateReadingList(ctx, ids, BulkAction{Action: BulkAddTag, Tag: " Go "}); err != nil {
+		t.Fatalf("BulkUpdateReadingList(add_tag) error: %v", err)
+	}
+	if tagged, _ := store.GetReadingListByTag(ctx, "go"); len(tagged) != 3 {
+		t.Errorf("got %d items tagged go, want 3", len(tagged))
+	}
+
+	if err := store.BulkUpdateReadingList(ctx, ids[:1], BulkAction{Action: BulkArchive}); err != nil {
+		t.Fatalf("BulkUpdateReadingList(archive) error: %v", err)
+	}
+	if archived, _ := store.GetReadingList(ctx, "archived"); len(archived) != 1 || archived[0].ID != ids[0] {
+		t.Errorf("archive did not move item %d to archived", ids[0])
+	}
+
+	// An unknown ID rolls back the whole batch.
+	err := store.BulkUpdateReadingList(ctx, []int64{ids[1], 99999}, BulkAction{Action: BulkDelete})
+	if !errors.Is(err, ErrNotFound) {
+		t.Fatalf("BulkUpdateReadingList(unknown id) error = %v, want ErrNotFound", err)
+	}
+	if all, _ := store.GetReadingList(ctx, ""); len(all) != 3 {
+		t.Errorf("got %d items after failed delete, want 3", len(all))
+	}
+
+	// Duplicate IDs are applied once.
+	if err := store.BulkUpdateReadingList(ctx, []int64{ids[1], ids[2], ids[1]}, BulkAction{Action: BulkDelete}); err != nil {
+		t.Fatalf("BulkUpdateReadingList(delete) error: %v", err)
+	}
+	if all, _ := store.GetReadingList(ctx, ""); len(all) != 1 {
+		t.Errorf("got %d items after delete, want 1", len(all))
+	}
+
+	if err := store.BulkUpdateReadingList(ctx, ids[:1], BulkAction{Action: "explode"}); err == nil {
+		t.Error("expected error for unknown action")
+	}
+}
+
+func TestUpdateReadingListItem(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+	blogID := seedReadingListBlog(t, store, "https://example.com/versioned")
+	if err := store.AddToReadingList(ctx, blogID); err != nil {
+		t.Fatalf("AddToReadingList: %v", err)
+	}
+	id, err := store.GetReadingListIDByBlogID(ctx, blogID)
+	if err != nil {
+		t.Fatalf("GetReadingListIDByBlogID: %v", err)
+	}
+
+	status, notes := "reading", "first pass"
+	v, err := store.UpdateReadingListItem(ctx, id, ReadingListUpdate{Status: &status, Notes: &notes, IfVersion: 1})
+	if err != nil {
+		t.Fatalf("UpdateReadingListItem() error: %v", err)
+	}
+	if v <= 1 {
+		t.Errorf("version after update = %d, want > 1", v)
+	}
+
+	// Progress is not an edit that conflicts.
+	if err := store.UpdateReadingListProgress(ctx, id, 40, nil); err != nil {
+		t.Fatalf("UpdateReadingListProgress: %v", err)
+	}
+
+	// An edit based on the old version is rejected and changes nothing.
+	stale := "read"
+	if _, err := store.UpdateReadingListItem(ctx, id, ReadingListUpdate{Status: &stale, IfVersion: 1}); !errors.Is(err, ErrVersionConflict) {
+		t.Fatalf("UpdateReadingListItem() at stale version error = %v, want ErrVersionConflict", err)
+	}
+	item, err := store.GetReadingListItemByID(ctx, id)
+	if err != nil {
+		t.Fatalf("GetReadingListItemByID: %v", err)
+	}
+	if item.Status != "reading" || item.Version != v {
+		t.Errorf("item = status %q, version %d; want reading at version %d", item.Status, item.Version, v)
+	}
+
+	// Without IfVersion the update goes through.
+	if _, err := store.UpdateReadingListItem(ctx, id, ReadingListUpdate{Status: &stale}); err != nil {
+		t.Fatalf("UpdateReadingListItem() without version error: %v", err)
+	}
+	if _, err := store.UpdateReadingListItem(ctx, 99999, ReadingListUpdate{Status: &stale}); !errors.Is(err, ErrNotFound) {
+		t.Errorf("UpdateReadingListItem() of missing item error = %v, want ErrNotFound", err)
+	}
+}
@@ -0,0 +1,141 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+
+	"github.com/hoanghai1803/apricot/internal/models"
+)
+
+// reportTopN is the number of entries kept in ranked report lists.
+const reportTopN = 5
+
+// GetYearReport aggregates the reading list items marked as read during the
+// given calendar year into a YearReport. The Narrative field is left empty
+// for the caller to fill in.
+func (s *sqlStore) GetYearReport(ctx context.Context, year int) (*models.YearReport, error) {
+	start := fmt.Sprintf("%04d-01-01 00:00:00", year)
+	end := fmt.Sprintf("%04d-01-01 00:00:00", year+1)
+
+	rows, err := s.rdb.QueryContext(ctx,
+		`SELECT b.id, b.title, b.url, COALESCE(b.custom_source, bs.name, '') AS source,
+				b.description, b.reading_time_minutes, rl.read_at, rl.notes
+		 FROM reading_list rl
+		 JOIN blogs b ON b.id = rl.blog_id
+		 LEFT JOIN blog_sources bs ON bs.id = b.source_id
+		 WHERE rl.status IN ('read', 'archived') AND rl.read_at >= ? AND rl.read_at < ?
+		 ORDER BY rl.read_at`, start, end)
+	if err != nil {
+		return nil, fmt.Errorf("querying read items for %d: %w", year, err)
+	}
+	defer rows.Close()
+
+	report := &models.YearReport{
+		Year:     year,
+		Articles: []models.ReportArticle{},
+		Notes:    []models.ReportArticle{},
+	}
+	sourceCounts := make(map[string]int)
+
+	for rows.Next() {
+		var (
+			article        models.ReportArticle
+			description    sql.NullString
+			readingTimeMin sql.NullInt64
+			readAt         string
+			notes          sql.NullString
+		)
+		if err := rows.Scan(
+			&article.BlogID, &article.Title, &article.URL, &article.Source,
+			&description, &readingTimeMin, &readAt, &notes,
+		); err != nil {
+			return nil, fmt.Errorf("scanning report row: %w", err)
+		}
+
+		article.Description = description.String
+		article.ReadAt = parseTime(readAt)
+		if readingTimeMin.Valid {
+			v := int(readingTimeMin.Int64)
+			article.ReadingTimeMinutes = &v
+			report.TotalMinutes += v
+
+			if report.LongestRead == nil || v > *report.LongestRead.ReadingTimeMinutes {
+				longest := article
+				report.LongestRead = &longest
+			}
+		}
+		if notes.String != "" {
+			withNote := article
+			withNote.Note = notes.String
+			report.Notes = append(report.Notes, withNote)
+		}
+
+		sourceCounts[article.Source]++
+		report.Articles = append(report.Articles, article)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating report rows: %w", err)
+	}
+
+	report.ArticlesRead = len(report.Articles)
+	report.TopSources = topCounts(sourceCounts, reportTopN)
+
+	report.TopTags, err = s.topTagsReadBetween(ctx, start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	return report, nil
+}
+
+// topTagsReadBetween returns the most used tags on items read within the
+// given [start, end) range.
+func (s *sqlStore) topTagsReadBetween(ctx context.Context, start, end string) ([]models.ReportCount, error) {
+	rows, err := s.rdb.QueryContext(ctx,
+		`SELECT t.name, COUNT(*) AS n
+		 FROM reading_list_tags rlt
+		 JOIN tags t ON t.id = rlt.tag_id
+		 JOIN reading_list rl ON rl.id = rlt.reading_list_id
+		 WHERE rl.status IN ('read', 'archived') AND rl.read_at >= ? AND rl.read_at < ?
+		 GROUP BY t.name
+		 ORDER BY n DESC, t.name
+		 LIMIT ?`, start, end, reportTopN)
+	if err != nil {
+		return nil, fmt.Errorf("querying top tags: %w", err)
+	}
+	defer rows.Close()
+
+	tags := []models.ReportCount{}
+	for rows.Next() {
+		var c models.ReportCount
+		if err := rows.Scan(&c.Name, &c.Count); err != nil {
+			return nil, fmt.Errorf("scanning top tag: %w", err)
+		}
+		tags = append(tags, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating top tags: %w", err)
+	}
+	return tags, nil
+}
+
+// topCounts converts a name->count map into a slice sorted by count DESC,
+// then name ASC, truncated to n entries.
+func topCounts(counts map[string]int, n int) []models.ReportCount {
+	result := make([]models.ReportCount, 0, len(counts))
+	for name, count := range counts {
+		result = append(result, models.ReportCount{Name: name, Count: count})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Count != result[j].Count {
+			return result[i].Count > result[j].Count
+		}
+		return result[i].Name < result[j].Name
+	})
+	if len(result) > n {
+		result = result[:n]
+	}
+	return result
+}
@@ -0,0 +1,93 @@
+package storage
+
+import (
+	"context"
+	"testing"
+)
+
+func TestGetYearReport(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+
+	// Two items read in 2025, one read in 2024, one unread.
+	var itemIDs []int64
+	for i, url := range []string{
+		"https://test.com/report-a",
+		"https://test.com/report-b",
+		"https://test.com/report-c",
+		"https://test.com/report-d",
+	} {
+		blogID := seedReadingListBlog(t, store, url)
+		if err := store.AddToReadingList(ctx, blogID); err != nil {
+			t.Fatalf("AddToReadingList() error: %v", err)
+		}
+		if err := store.UpdateReadingTime(ctx, blogID, (i+1)*5); err != nil {
+			t.Fatalf("UpdateReadingTime() error: %v", err)
+		}
+		var id int64
+		if err := store.db.QueryRow(`SELECT id FROM reading_list WHERE blog_id = ?`, blogID).Scan(&id); err != nil {
+			t.Fatalf("getting reading list id: %v", err)
+		}
+		itemIDs = append(itemIDs, id)
+	}
+
+	readAt := []string{"2025-02-01 10:00:00", "2025-11-30 23:59:59", "2024-12-31 23:59:59"}
+	for i, ts := range readAt {
+		if _, err := store.db.Exec(
+			`UPDATE reading_list SET status = 'read', read_at = ? WHERE id = ?`, ts, itemIDs[i],
+		); err != nil {
+			t.Fatalf("marking item read: %v", err)
+		}
+	}
+	if err := store.UpdateReadingListNotes(ctx, itemIDs[0], "great read"); err != nil {
+		t.Fatalf("UpdateReadingListNotes() error: %v", err)
+	}
+	if err := store.AddTagToItem(ctx, itemIDs[0], "go"); err != nil {
+		t.Fatalf("AddTagToItem() error: %v", err)
+	}
+	if err := store.AddTagToItem(ctx, itemIDs[1], "go"); err != nil {
+		t.Fatalf("AddTagToItem() error: %v", err)
+	}
+	if err := store.AddTagToItem(ctx, itemIDs[2], "rust"); err != nil {
+		t.Fatalf("AddTagToItem() error: %v", err)
+	}
+
+	report, err := store.GetYearReport(ctx, 2025)
+	if err != nil {
+		t.Fatalf("GetYearReport() error: %v", err)
+	}
+
+	if report.ArticlesRead != 2 {
+		t.Errorf("ArticlesRead = %d, want 2", report.ArticlesRead)
+	}
+	if report.TotalMinutes != 15 {
+		t.Errorf("TotalMinutes = %d, want 15", report.TotalMinutes)
+	}
+	if report.LongestRead == nil || report.LongestRead.URL != "https://test.com/report-b" {
+		t.Errorf("LongestRead = %+v, want report-b", report.LongestRead)
+	}
+	if len(report.TopTags) != 1 || report.TopTags[0].Name != "go" || report.TopTags[0].Count != 2 {
+		t.Errorf("TopTags = %+v, want [{go 2}]", report.TopTags)
+	}
+	if len(report.TopSources) != 1 || report.TopSources[0].Count != 2 {
+		t.Errorf("TopSources = %+v, want one source with count 2", report.TopSources)
+	}
+	if len(report.Notes) != 1 || report.Notes[0].Note != "great read" {
+		t.Errorf("Notes = %+v, want one note", report.Notes)
+	}
+}
+
+func TestGetYearReport_Empty(t *testing.T) {
+	store := newTestStore(t)
+
+	report, err := store.GetYearReport(context.Background(), 2030)
+	if err != nil {
+		t.Fatalf("GetYearReport() error: %v", err)
+	}
+	if report.ArticlesRead != 0 || report.LongestRead != nil {
+		t.Errorf("got %+v, want empty report", report)
+	}
+	if report.Articles == nil || report.TopTags == nil || report.TopSources == nil {
+		t.Error("slices should be non-nil for JSON serialization")
+	}
+}
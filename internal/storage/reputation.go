@@ -0,0 +1,253 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/hoanghai1803/apricot/internal/models"
+)
+
+// SetBlogFeedback records a thumbs up (1) or down (-1) rating for a blog,
+// replacing any earlier rating. A rating of 0 clears it. Returns ErrNotFound
+// if the blog does not exist.
+func (s *sqlStore) SetBlogFeedback(ctx context.Context, blogID int64, rating int) error {
+	if rating == 0 {
+		_, err := s.db.ExecContext(ctx, `DELETE FROM blog_feedback WHERE blog_id = ?`, blogID)
+		if err != nil {
+			return fmt.Errorf("clearing blog feedback: %w", err)
+		}
+		return nil
+	}
+
+	_, err := s.db.ExecContext(ctx,
+		`INSERT INTO blog_feedback (blog_id, rating) VALUES (?, ?)
+		 ON CONFLICT(blog_id) DO UPDATE SET rating = excluded.rating, created_at = datetime('now')`,
+		blogID, rating,
+	)
+	if err != nil {
+		if isForeignKeyViolation(err) {
+			return ErrNotFound
+		}
+		return fmt.Errorf("setting blog feedback: %w", err)
+	}
+	return nil
+}
+
+// GetSourceScores returns a score for every source based on activity since
+// the given time: posts recommended by discovery sessions, posts added to the
+// reading list and how many of those were read, and thumbs feedback. Pass
+// the zero time to score all activity. The sentinel "custom://user-added"
+// source is excluded.
+func (s *sqlStore) GetSourceScores(ctx context.Context, since time.Time) ([]models.SourceScore, error) {
+	sinceStr := since.UTC().Format("2006-01-02 15:04:05")
+
+	rows, err := s.rdb.QueryContext(ctx,
+		`SELECT bs.id, bs.name,
+				(SELECT COUNT(DISTINCT j.value)
+				 FROM discovery_sessions ds, json_each(ds.blogs_selected) j
+				 JOIN blogs b ON b.id = j.value
+				 WHERE b.source_id = bs.id AND ds.created_at >= ?),
+				(SELECT COUNT(*) FROM reading_list rl JOIN blogs b ON b.id = rl.blog_id
+				 WHERE b.source_id = bs.id AND rl.added_at >= ?),
+				(SELECT COUNT(*) FROM reading_list rl JOIN blogs b ON b.id = rl.blog_id
+				 WHERE b.source_id = bs.id AND rl.added_at >= ? AND rl.status IN ('read', 'archived')),
+				(SELECT COUNT(*) FROM blog_feedback f JOIN blogs b ON b.id = f.blog_id
+				 WHERE b.source_id = bs.id AND f.created_at >= ? AND f.rating > 0),
+				(SELECT COUNT(*) FROM blog_feedback f JOIN blogs b ON b.id = f.blog_id
+				 WHERE b.source_id = bs.id AND f.created_at >= ? AND f.rating < 0)
+		 FROM blog_sources bs
+		 WHERE bs.feed_url != 'custom://user-added'
+		 ORDER BY bs.name`,
+		sinceStr, sinceStr, sinceStr, sinceStr, sinceStr,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("querying source scores: %w", err)
+	}
+	defer rows.Close()
+
+	scores := []models.SourceScore{}
+	for rows.Next() {
+		var sc models.SourceScore
+		if err := rows.Scan(&sc.SourceID, &sc.Name, &sc.Recommended, &sc.Saved, &sc.Read,
+			&sc.ThumbsUp, &sc.ThumbsDown); err != nil {
+			return nil, fmt.Errorf("scanning source score: %w", err)
+		}
+		computeSourceScore(&sc)
+		scores = append(scores, sc)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating source scores: %w", err)
+	}
+	return scores, nil
+}
+
+// computeSourceScore fills in the rates and overall score of sc from its
+// counts. Each signal is Laplace-smoothed toward 0.5 and the three are
+// averaged. Posts saved without being recommended (e.g. found via search)
+// count toward the save rate's denominator so it never exceeds 1.
+func computeSourceScore(sc *models.SourceScore) {
+	shown := max(sc.Recommended, sc.Saved)
+	if shown > 0 {
+		sc.SaveRate = float64(sc.Saved) / float64(shown)
+	}
+	if sc.Saved > 0 {
+		sc.CompletionRate = float64(sc.Read) / float64(sc.Saved)
+	}
+
+	save := float64(sc.Saved+1) / float64(shown+2)
+	completion := float64(sc.Read+1) / float64(sc.Saved+2)
+	feedback := float64(sc.ThumbsUp+1) / float64(sc.ThumbsUp+sc.ThumbsDown+2)
+	sc.Score = (save + completion + feedback) / 3
+}
+
+// GetSourceStats returns what source id produced since the given time; pass
+// the zero time for all time. A post's relevance in a session is its place
+// in the session's ranking, from 1 for the first of n posts down to 1/n for
+// the last, and it counts once for every session that selected it. Returns
+// ErrNotFound if the source does not exist.
+func (s *sqlStore) GetSourceStats(ctx context.Context, id int64, since time.Time) (*models.SourceStats, error) {
+	stats := &models.SourceStats{SourceID: id, Months: []models.SourceStatsMonth{}}
+	err := s.rdb.QueryRowContext(ctx, `SELECT name FROM blog_sources WHERE id = ?`, id).Scan(&stats.Name)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("getting source %d: %w", id, err)
+	}
+	sinceStr := since.UTC().Format("2006-01-02 15:04:05")
+
+	type month struct {
+		models.SourceStatsMonth
+		selected     map[int64]bool
+		relevanceSum float64
+		selections   int
+	}
+	months := make(map[string]*month)
+	monthOf := func(at string) *month {
+		key := parseTime(at).Format("2006-01")
+		m, ok := months[key]
+		if !ok {
+			m = &month{SourceStatsMonth: models.SourceStatsMonth{Month: key}, selected: make(map[int64]bool)}
+			months[key] = m
+		}
+		return m
+	}
+
+	fetched, err := queryColumn[string](ctx, s.rdb,
+		`SELECT created_at FROM blogs WHERE source_id = ? AND created_at >= ?`, id, sinceStr)
+	if err != nil {
+		return nil, fmt.Errorf("querying fetched posts: %w", err)
+	}
+	for _, at := range fetched {
+		monthOf(at).Fetched++
+	}
+	stats.Fetched = len(fetched)
+
+	read, err := queryColumn[string](ctx, s.rdb,
+		`SELECT rl.read_at FROM reading_list rl JOIN blogs b ON b.id = rl.blog_id
+		 WHERE b.source_id = ? AND rl.status IN ('read', 'archived') AND rl.read_at >= ?`, id, sinceStr)
+	if err != nil {
+		return nil, fmt.Errorf("querying read posts: %w", err)
+	}
+	for _, at := range read {
+		monthOf(at).Read++
+	}
+	stats.Read = len(read)
+
+	own, err := queryColumn[int64](ctx, s.rdb,
+		`SELECT DISTINCT j.value FROM discovery_sessions ds, json_each(ds.blogs_selected) j
+		 JOIN blogs b ON b.id = j.value
+		 WHERE b.source_id = ? AND ds.created_at >= ?`, id, sinceStr)
+	if err != nil {
+		return nil, fmt.Errorf("querying selected posts: %w", err)
+	}
+	ownPosts := make(map[int64]bool, len(own))
+	for _, blogID := range own {
+		ownPosts[blogID] = true
+	}
+
+	rows, err := s.rdb.QueryContext(ctx,
+		`SELECT ds.created_at, ds.blogs_selected FROM discovery_sessions ds
+		 WHERE ds.created_at >= ? AND EXISTS (
+			SELECT 1 FROM json_each(ds.blogs_selected) j JOIN blogs b ON b.id = j.value
+			WHERE b.source_id = ?)`, sinceStr, id)
+	if err != nil {
+		return nil, fmt.Errorf("querying sessions selecting source %d: %w", id, err)
+	}
+	defer rows.Close()
+
+	var relevanceSum float64
+	var selections int
+	for rows.Next() {
+		var createdAt, selectedJSON string
+		if err := rows.Scan(&createdAt, &selectedJSON); err != nil {
+			return nil, fmt.Errorf("scanning session: %w", err)
+		}
+		var selected []int64
+		if err := json.Unmarshal([]byte(selectedJSON), &selected); err != nil {
+			return nil, fmt.Errorf("decoding selected posts: %w", err)
+		}
+		m := monthOf(createdAt)
+		for i, blogID := range selected {
+			if !ownPosts[blogID] {
+				continue
+			}
+			relevance := 1 - float64(i)/float64(len(selected))
+			m.selected[blogID] = true
+			m.relevanceSum += relevance
+			m.selections++
+			relevanceSum += relevance
+			selections++
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating sessions: %w", err)
+	}
+	stats.Selected = len(ownPosts)
+	stats.AverageRelevance = averageOf(relevanceSum, selections)
+
+	for _, m := range months {
+		m.Selected = len(m.selected)
+		m.AverageRelevance = averageOf(m.relevanceSum, m.selections)
+		stats.Months = append(stats.Months, m.SourceStatsMonth)
+	}
+	slices.SortFunc(stats.Months, func(a, b models.SourceStatsMonth) int {
+		return strings.Compare(a.Month, b.Month)
+	})
+	return stats, nil
+}
+
+// queryColumn runs query, which selects a single column, and returns its
+// values.
+func queryColumn[T any](ctx context.Context, db *sql.DB, query string, args ...any) ([]T, error) {
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var values []T
+	for rows.Next() {
+		var v T
+		if err := rows.Scan(&v); err != nil {
+			return nil, err
+		}
+		values = append(values, v)
+	}
+	return values, rows.Err()
+}
+
+// averageOf returns sum/n, or nil when n is 0.
+func averageOf(sum float64, n int) *float64 {
+	if n == 0 {
+		return nil
+	}
+	avg := sum / float64(n)
+	return &avg
+}
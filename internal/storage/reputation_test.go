@@ -0,0 +1,205 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/hoanghai1803/apricot/internal/models"
+)
+
+// seedSourceBlogs inserts a feed source with n blogs and returns the blog
+// IDs. The source is needed because the sentinel custom source, which
+// seedReadingListBlog may pick, is excluded from scores.
+func seedSourceBlogs(t *testing.T, store *SQLiteStore, n int) []int64 {
+	t.Helper()
+	sourceID := seedTestSource(t, store)
+
+	ids := make([]int64, n)
+	for i := range n {
+		id, err := store.UpsertBlog(context.Background(), &models.Blog{
+			SourceID:  sourceID,
+			Title:     "Post",
+			URL:       "https://test.com/score-" + string(rune('a'+i)),
+			FetchedAt: time.Now(),
+		})
+		if err != nil {
+			t.Fatalf("UpsertBlog: %v", err)
+		}
+		ids[i] = id
+	}
+	return ids
+}
+
+func TestGetSourceScores(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+	blogIDs := seedSourceBlogs(t, store, 4)
+	selected, _ := json.Marshal(blogIDs)
+	if _, err := store.CreateSession(ctx, &models.DiscoverySession{
+		PreferencesSnapshot: "go",
+		BlogsConsidered:     4,
+		BlogsSelected:       string(selected),
+		ModelUsed:           "test",
+	}); err != nil {
+		t.Fatalf("CreateSession: %v", err)
+	}
+
+	for _, id := range blogIDs[:2] {
+		if err := store.AddToReadingList(ctx, id); err != nil {
+			t.Fatalf("AddToReadingList: %v", err)
+		}
+	}
+	itemID, err := store.GetReadingListIDByBlogID(ctx, blogIDs[0])
+	if err != nil {
+		t.Fatalf("GetReadingListIDByBlogID: %v", err)
+	}
+	if err := store.UpdateReadingListStatus(ctx, itemID, "read"); err != nil {
+		t.Fatalf("UpdateReadingListStatus: %v", err)
+	}
+	for _, id := range blogIDs[:2] {
+		if err := store.SetBlogFeedback(ctx, id, 1); err != nil {
+			t.Fatalf("SetBlogFeedback: %v", err)
+		}
+	}
+
+	scores, err := store.GetSourceScores(ctx, time.Time{})
+	if err != nil {
+		t.Fatalf("GetSourceScores() error: %v", err)
+	}
+	if len(scores) != 1 {
+		t.Fatalf("expected 1 source, got %d", len(scores))
+	}
+	sc := scores[0]
+	if sc.Recommended != 4 || sc.Saved != 2 || sc.Read != 1 || sc.ThumbsUp != 2 || sc.ThumbsDown != 0 {
+		t.Errorf("got counts %+v", sc)
+	}
+	if sc.SaveRate != 0.5 || sc.CompletionRate != 0.5 {
+		t.Errorf("got save rate %v, completion rate %v, want 0.5 and 0.5", sc.SaveRate, sc.CompletionRate)
+	}
+	// (3/6 + 2/4 + 3/4) / 3
+	if want := (0.5 + 0.5 + 0.75) / 3; math.Abs(sc.Score-want) > 1e-9 {
+		t.Errorf("Score = %v, want %v", sc.Score, want)
+	}
+
+	// Activity before the window is ignored.
+	scores, err = store.GetSourceScores(ctx, time.Now().Add(time.Hour))
+	if err != nil {
+		t.Fatalf("GetSourceScores() error: %v", err)
+	}
+	if sc := scores[0]; sc.Recommended != 0 || sc.Saved != 0 || sc.Score != 0.5 {
+		t.Errorf("expected a neutral score with no activity, got %+v", sc)
+	}
+}
+
+func TestSetBlogFeedback(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+	blogID := seedSourceBlogs(t, store, 1)[0]
+
+	if err := store.SetBlogFeedback(ctx, blogID, 1); err != nil {
+		t.Fatalf("SetBlogFeedback(1) error: %v", err)
+	}
+	if err := store.SetBlogFeedback(ctx, blogID, -1); err != nil {
+		t.Fatalf("SetBlogFeedback(-1) error: %v", err)
+	}
+
+	scores, err := store.GetSourceScores(ctx, time.Time{})
+	if err != nil {
+		t.Fatalf("GetSourceScores() error: %v", err)
+	}
+	if scores[0].ThumbsUp != 0 || scores[0].ThumbsDown != 1 {
+		t.Errorf("expected the rating to be replaced, got %+v", scores[0])
+	}
+
+	if err := store.SetBlogFeedback(ctx, blogID, 0); err != nil {
+		t.Fatalf("SetBlogFeedback(0) error: %v", err)
+	}
+	scores, _ = store.GetSourceScores(ctx, time.Time{})
+	if scores[0].ThumbsDown != 0 {
+		t.Errorf("expected the rating to be cleared, got %+v", scores[0])
+	}
+
+	if err := store.SetBlogFeedback(ctx, 9999, 1); !errors.Is(err, ErrNotFound) {
+		t.Errorf("SetBlogFeedback(missing) error = %v, want ErrNotFound", err)
+	}
+}
+
+func TestGetSourceStats(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+	blogIDs := seedSourceBlogs(t, store, 3)
+	blog, err := store.GetBlogByID(ctx, blogIDs[0])
+	if err != nil {
+		t.Fatalf("GetBlogByID: %v", err)
+	}
+	sourceID := blog.SourceID
+
+	// A post from another source, ranked first.
+	res, err := store.db.Exec(
+		`INSERT INTO blog_sources (name, company, feed_url, site_url, is_active)
+		 VALUES ('Other Blog', 'OtherCo', 'https://other.com/feed', 'https://other.com', 1)`)
+	if err != nil {
+		t.Fatalf("seeding other source: %v", err)
+	}
+	otherSourceID, _ := res.LastInsertId()
+	otherID, err := store.UpsertBlog(ctx, &models.Blog{
+		SourceID: otherSourceID, Title: "Other", URL: "https://other.com/post", FetchedAt: time.Now(),
+	})
+	if err != nil {
+		t.Fatalf("UpsertBlog: %v", err)
+	}
+
+	for _, selected := range [][]int64{{otherID, blogIDs[0], blogIDs[1]}, {blogIDs[0]}, {otherID}} {
+		data, _ := json.Marshal(selected)
+		if _, err := store.CreateSession(ctx, &models.DiscoverySession{
+			PreferencesSnapshot: "go",
+			BlogsSelected:       string(data),
+			ModelUsed:           "test",
+		}); err != nil {
+			t.Fatalf("CreateSession: %v", err)
+		}
+	}
+	if err := store.AddToReadingList(ctx, blogIDs[0]); err != nil {
+		t.Fatalf("AddToReadingList: %v", err)
+	}
+	itemID, err := store.GetReadingListIDByBlogID(ctx, blogIDs[0])
+	if err != nil {
+		t.Fatalf("GetReadingListIDByBlogID: %v", err)
+	}
+	if err := store.UpdateReadingListStatus(ctx, itemID, "read"); err != nil {
+		t.Fatalf("UpdateReadingListStatus: %v", err)
+	}
+
+	stats, err := store.GetSourceStats(ctx, sourceID, time.Time{})
+	if err != nil {
+		t.Fatalf("GetSourceStats() error: %v", err)
+	}
+	if stats.Name != "Test Blog" || stats.Fetched != 3 || stats.Selected != 2 || stats.Read != 1 {
+		t.Errorf("got %+v, want Test Blog with 3 fetched, 2 selected, 1 read", stats)
+	}
+	// Second and third of three, then first of one: (2/3 + 1/3 + 1) / 3.
+	if stats.AverageRelevance == nil || math.Abs(*stats.AverageRelevance-2.0/3) > 1e-9 {
+		t.Errorf("AverageRelevance = %v, want 2/3", stats.AverageRelevance)
+	}
+	if len(stats.Months) != 1 || stats.Months[0].Month != time.Now().UTC().Format("2006-01") ||
+		stats.Months[0].Fetched != 3 || stats.Months[0].Selected != 2 || stats.Months[0].Read != 1 {
+		t.Errorf("Months = %+v, want this month's totals", stats.Months)
+	}
+
+	// Activity before the window is ignored.
+	stats, err = store.GetSourceStats(ctx, sourceID, time.Now().Add(time.Hour))
+	if err != nil {
+		t.Fatalf("GetSourceStats() error: %v", err)
+	}
+	if stats.Fetched != 0 || stats.Selected != 0 || stats.AverageRelevance != nil || len(stats.Months) != 0 {
+		t.Errorf("got %+v, want no activity", stats)
+	}
+
+	if _, err := store.GetSourceStats(ctx, 9999, time.Time{}); !errors.Is(err, ErrNotFound) {
+		t.Errorf("GetSourceStats(missing) error = %v, want ErrNotFound", err)
+	}
+}
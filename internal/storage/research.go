@@ -0,0 +1,131 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/hoanghai1803/apricot/internal/models"
+)
+
+// CreateResearchReport inserts a research report with its cited sources and
+// returns its ID.
+func (s *sqlStore) CreateResearchReport(ctx context.Context, report *models.ResearchReport) (int64, error) {
+	sources := report.Sources
+	if sources == nil {
+		sources = []models.ResearchSource{}
+	}
+	sourcesJSON, err := json.Marshal(sources)
+	if err != nil {
+		return 0, fmt.Errorf("encoding research sources: %w", err)
+	}
+
+	var id int64
+	if err := s.db.QueryRowContext(ctx,
+		`INSERT INTO research_reports (question, answer, sources_json, model_used)
+		 VALUES (?, ?, ?, ?)
+		 RETURNING id`,
+		report.Question, report.Answer, string(sourcesJSON), nullableString(report.ModelUsed),
+	).Scan(&id); err != nil {
+		return 0, fmt.Errorf("creating research report: %w", err)
+	}
+	return id, nil
+}
+
+// scanResearchReport scans a research report row from either *sql.Row or
+// *sql.Rows.
+func scanResearchReport(row scanner) (*models.ResearchReport, error) {
+	var (
+		report      models.ResearchReport
+		sourcesJSON string
+		modelUsed   sql.NullString
+		createdAt   string
+	)
+	if err := row.Scan(&report.ID, &report.Question, &report.Answer,
+		&sourcesJSON, &modelUsed, &createdAt); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(sourcesJSON), &report.Sources); err != nil {
+		return nil, fmt.Errorf("decoding research sources: %w", err)
+	}
+	report.ModelUsed = modelUsed.String
+	report.CreatedAt = parseTime(createdAt)
+	return &report, nil
+}
+
+// ListResearchReports returns the most recent research reports, newest
+// first, limited to the specified count.
+func (s *sqlStore) ListResearchReports(ctx context.Context, limit int) ([]models.ResearchReport, error) {
+	rows, err := s.rdb.QueryContext(ctx,
+		`SELECT id, question, answer, sources_json, model_used, created_at
+		 FROM research_reports
+		 ORDER BY created_at DESC, id DESC
+		 LIMIT ?`, limit)
+	if err != nil {
+		return nil, fmt.Errorf("listing research reports: %w", err)
+	}
+	defer rows.Close()
+
+	reports := []models.ResearchReport{}
+	for rows.Next() {
+		report, err := scanResearchReport(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scanning research report: %w", err)
+		}
+		reports = append(reports, *report)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating research reports: %w", err)
+	}
+	return reports, nil
+}
+
+// GetResearchReport returns the research report with the given ID, or
+// ErrNotFound if it does not exist.
+func (s *sqlStore) GetResearchReport(ctx context.Context, id int64) (*models.ResearchReport, error) {
+	report, err := scanResearchReport(s.rdb.QueryRowContext(ctx,
+		`SELECT id, question, answer, sources_json, model_used, created_at
+		 FROM research_reports WHERE id = ?`, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("getting research report: %w", err)
+	}
+	return report, nil
+}
+
+// DeleteResearchReport deletes a research report, recording its question in
+// the audit log. The cited blogs are not affected.
+func (s *sqlStore) DeleteResearchReport(ctx context.Context, id int64) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("beginning transaction: %w", err)
+	}
+	defer tx.Rollback() //nolint:errcheck // rollback after commit is a no-op
+
+	var question string
+	err = tx.QueryRowContext(ctx,
+		`SELECT question FROM research_reports WHERE id = ?`, id).Scan(&question)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("reading research report: %w", err)
+	}
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM research_reports WHERE id = ?`, id); err != nil {
+		return fmt.Errorf("deleting research report: %w", err)
+	}
+	if err := recordAudit(ctx, tx, AuditResearchDelete, "research_report", id,
+		map[string]any{"question": question}, nil); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("committing transaction: %w", err)
+	}
+	return nil
+}
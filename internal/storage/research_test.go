@@ -0,0 +1,84 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/hoanghai1803/apricot/internal/models"
+)
+
+func TestCreateAndGetResearchReport(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+
+	id, err := store.CreateResearchReport(ctx, &models.ResearchReport{
+		Question:  "How do LSM trees compact?",
+		Answer:    "Leveled compaction merges runs [1].",
+		ModelUsed: "test-model",
+		Sources: []models.ResearchSource{
+			{Ref: 1, BlogID: 7, Title: "Compaction", URL: "https://test.com/c", Origin: models.ResearchOriginFresh},
+		},
+	})
+	if err != nil {
+		t.Fatalf("CreateResearchReport() error: %v", err)
+	}
+
+	report, err := store.GetResearchReport(ctx, id)
+	if err != nil {
+		t.Fatalf("GetResearchReport() error: %v", err)
+	}
+	if report.Question != "How do LSM trees compact?" || report.ModelUsed != "test-model" {
+		t.Errorf("got question=%q model=%q", report.Question, report.ModelUsed)
+	}
+	if len(report.Sources) != 1 || report.Sources[0].BlogID != 7 || report.Sources[0].Origin != "fresh" {
+		t.Errorf("got sources %+v", report.Sources)
+	}
+	if report.CreatedAt.IsZero() {
+		t.Error("CreatedAt is zero")
+	}
+
+	if _, err := store.GetResearchReport(ctx, id+1); !errors.Is(err, ErrNotFound) {
+		t.Errorf("GetResearchReport(missing) error = %v, want ErrNotFound", err)
+	}
+}
+
+func TestListAndDeleteResearchReports(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+
+	reports, err := store.ListResearchReports(ctx, 10)
+	if err != nil {
+		t.Fatalf("ListResearchReports() error: %v", err)
+	}
+	if len(reports) != 0 {
+		t.Fatalf("expected no reports, got %d", len(reports))
+	}
+
+	var ids []int64
+	for _, q := range []string{"first", "second"} {
+		id, err := store.CreateResearchReport(ctx, &models.ResearchReport{Question: q, Answer: "a"})
+		if err != nil {
+			t.Fatalf("CreateResearchReport() error: %v", err)
+		}
+		ids = append(ids, id)
+	}
+
+	reports, err = store.ListResearchReports(ctx, 10)
+	if err != nil {
+		t.Fatalf("ListResearchReports() error: %v", err)
+	}
+	if len(reports) != 2 || reports[0].Question != "second" {
+		t.Fatalf("expected newest first, got %+v", reports)
+	}
+	if reports[1].Sources == nil {
+		t.Error("expected empty, non-nil sources")
+	}
+
+	if err := store.DeleteResearchReport(ctx, ids[0]); err != nil {
+		t.Fatalf("DeleteResearchReport() error: %v", err)
+	}
+	if err := store.DeleteResearchReport(ctx, ids[0]); !errors.Is(err, ErrNotFound) {
+		t.Errorf("second DeleteResearchReport() error = %v, want ErrNotFound", err)
+	}
+}
@@ -0,0 +1,127 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/hoanghai1803/apricot/internal/models"
+)
+
+// pruneBatch is the number of blogs deleted per transaction by PruneBlogs.
+const pruneBatch = 200
+
+// prunable selects the blogs b that PruneBlogs deletes: those published, or
+// fetched, before the cutoff parameter and not kept.
+const prunable = `COALESCE(b.published_at, b.fetched_at) < ?
+	AND NOT EXISTS (SELECT 1 FROM reading_list rl WHERE rl.blog_id = b.id)
+	AND NOT (EXISTS (SELECT 1 FROM blog_summaries sm WHERE sm.blog_id = b.id)
+	         AND EXISTS (SELECT 1 FROM blog_feedback f WHERE f.blog_id = b.id AND f.rating = 1))
+	AND b.id NOT IN (SELECT j.value FROM discovery_sessions ds, json_each(ds.blogs_selected) j)`
+
+// PruneBlogs deletes blogs published (or, without a publish date, fetched)
+// before cutoff that nobody kept: blogs on the reading list, blogs that were
+// both summarized and given a thumbs up, and blogs picked by a discovery
+// session are left alone. Their summaries, feedback, cold content, and
+// revisions go with them. Each batch deleted is recorded in the audit log.
+// Returns the number of blogs deleted.
+func (s *sqlStore) PruneBlogs(ctx context.Context, cutoff time.Time) (int, error) {
+	cutoffStr := cutoff.UTC().Format("2006-01-02 15:04:05")
+
+	total := 0
+	for {
+		n, err := s.pruneBatch(ctx, cutoffStr)
+		if err != nil {
+			return total, err
+		}
+		total += n
+		if n < pruneBatch {
+			return total, nil
+		}
+	}
+}
+
+// PrunableBlogs returns the blogs PruneBlogs would delete for cutoff, oldest
+// first, without their content.
+func (s *sqlStore) PrunableBlogs(ctx context.Context, cutoff time.Time) ([]models.Blog, error) {
+	rows, err := s.rdb.QueryContext(ctx,
+		`SELECT `+blogColumns+`
+		 FROM blogs b
+		 LEFT JOIN blog_sources bs ON bs.id = b.source_id
+		 WHERE `+prunable+`
+		 ORDER BY COALESCE(b.published_at, b.fetched_at), b.id`,
+		cutoff.UTC().Format("2006-01-02 15:04:05"))
+	if err != nil {
+		return nil, fmt.Errorf("querying prunable blogs: %w", err)
+	}
+	defer rows.Close()
+
+	var blogs []models.Blog
+	for rows.Next() {
+		blog, err := scanBlog(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scanning prunable blog: %w", err)
+		}
+		blog.FullContent = ""
+		blogs = append(blogs, *blog)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating prunable blogs: %w", err)
+	}
+	return blogs, nil
+}
+
+// pruneBatch deletes up to pruneBatch prunable blogs in a single transaction
+// and returns how many were deleted.
+func (s *sqlStore) pruneBatch(ctx context.Context, cutoff string) (int, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("beginning transaction: %w", err)
+	}
+	defer tx.Rollback() //nolint:errcheck // rollback after commit is a no-op
+
+	rows, err := tx.QueryContext(ctx,
+		`SELECT b.id FROM blogs b WHERE `+prunable+` LIMIT ?`, cutoff, pruneBatch)
+	if err != nil {
+		return 0, fmt.Errorf("querying prunable blogs: %w", err)
+	}
+
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("scanning prunable blog: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("iterating prunable blogs: %w", err)
+	}
+
+	// Summaries reference blogs without ON DELETE CASCADE; feedback, cold
+	// content, and revisions cascade.
+	for _, id := range ids {
+		if _, err := tx.ExecContext(ctx,
+			`DELETE FROM blog_summaries WHERE blog_id = ?`, id); err != nil {
+			return 0, fmt.Errorf("deleting summary of blog %d: %w", id, err)
+		}
+		if _, err := tx.ExecContext(ctx,
+			`DELETE FROM blogs WHERE id = ?`, id); err != nil {
+			return 0, fmt.Errorf("deleting blog %d: %w", id, err)
+		}
+	}
+
+	if len(ids) > 0 {
+		if err := recordAudit(ctx, tx, AuditBlogsPrune, "blogs", cutoff,
+			map[string]any{"published_before": cutoff, "blog_ids": ids}, nil); err != nil {
+			return 0, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("committing transaction: %w", err)
+	}
+	return len(ids), nil
+}
@@ -0,0 +1,104 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/hoanghai1803/apricot/internal/models"
+)
+
+func TestPruneBlogs(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+	sourceID := seedTestSource(t, store)
+
+	old := time.Now().AddDate(0, 0, -100)
+	seed := func(url string, publishedAt time.Time) int64 {
+		t.Helper()
+		id, err := store.UpsertBlog(ctx, &models.Blog{
+			SourceID:    sourceID,
+			Title:       "Post",
+			URL:         url,
+			FullContent: "full text of " + url,
+			PublishedAt: &publishedAt,
+			FetchedAt:   time.Now(),
+		})
+		if err != nil {
+			t.Fatalf("UpsertBlog: %v", err)
+		}
+		return id
+	}
+	summarize := func(id int64) {
+		t.Helper()
+		if err := store.UpsertSummary(ctx, &models.BlogSummary{BlogID: id, Summary: "A summary.", ModelUsed: "mock"}); err != nil {
+			t.Fatalf("UpsertSummary: %v", err)
+		}
+	}
+
+	prunedID := seed("https://test.com/old", old)
+	summarizedID := seed("https://test.com/old-summarized", old)
+	summarize(summarizedID)
+	dislikedID := seed("https://test.com/old-disliked", old)
+	summarize(dislikedID)
+	if err := store.SetBlogFeedback(ctx, dislikedID, -1); err != nil {
+		t.Fatalf("SetBlogFeedback: %v", err)
+	}
+
+	savedID := seed("https://test.com/old-saved", old)
+	if err := store.AddToReadingList(ctx, savedID); err != nil {
+		t.Fatalf("AddToReadingList: %v", err)
+	}
+	likedID := seed("https://test.com/old-liked", old)
+	summarize(likedID)
+	if err := store.SetBlogFeedback(ctx, likedID, 1); err != nil {
+		t.Fatalf("SetBlogFeedback: %v", err)
+	}
+	selectedID := seed("https://test.com/old-selected", old)
+	if _, err := store.CreateSession(ctx, &models.DiscoverySession{
+		PreferencesSnapshot: "go",
+		BlogsSelected:       fmt.Sprintf("[%d]", selectedID),
+	}); err != nil {
+		t.Fatalf("CreateSession: %v", err)
+	}
+	newID := seed("https://test.com/new", time.Now())
+
+	cutoff := time.Now().AddDate(0, 0, -90)
+	prunable, err := store.PrunableBlogs(ctx, cutoff)
+	if err != nil {
+		t.Fatalf("PrunableBlogs() error: %v", err)
+	}
+	var prunableIDs []int64
+	for _, b := range prunable {
+		prunableIDs = append(prunableIDs, b.ID)
+	}
+	if want := []int64{prunedID, summarizedID, dislikedID}; !slices.Equal(prunableIDs, want) {
+		t.Errorf("PrunableBlogs() = %v, want %v", prunableIDs, want)
+	}
+
+	n, err := store.PruneBlogs(ctx, cutoff)
+	if err != nil {
+		t.Fatalf("PruneBlogs() error: %v", err)
+	}
+	if n != 3 {
+		t.Errorf("pruned %d blogs, want 3", n)
+	}
+
+	for _, id := range []int64{prunedID, summarizedID, dislikedID} {
+		if _, err := store.GetBlogByID(ctx, id); !errors.Is(err, ErrNotFound) {
+			t.Errorf("GetBlogByID(%d) error = %v, want ErrNotFound", id, err)
+		}
+	}
+	for _, id := range []int64{savedID, likedID, selectedID, newID} {
+		if _, err := store.GetBlogByID(ctx, id); err != nil {
+			t.Errorf("GetBlogByID(%d) error: %v, want the blog kept", id, err)
+		}
+	}
+
+	if err := store.Vacuum(ctx); err != nil {
+		t.Fatalf("Vacuum() error: %v", err)
+	}
+}
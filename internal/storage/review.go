@@ -0,0 +1,107 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/hoanghai1803/apricot/internal/models"
+)
+
+// ReviewIntervals are the delays after reading at which a post is due for
+// each spaced-repetition review: one week, one month, and three months.
+var ReviewIntervals = []time.Duration{
+	7 * 24 * time.Hour,
+	30 * 24 * time.Hour,
+	90 * 24 * time.Hour,
+}
+
+// GetReviewQueue returns the posts due for review at now: items read (or
+// read and archived) whose post has thumbs-up feedback and whose next review
+// interval has elapsed since read_at. Items are ordered by due date, oldest
+// first, and leave out post content.
+func (s *sqlStore) GetReviewQueue(ctx context.Context, now time.Time) ([]models.ReviewItem, error) {
+	// The cutoff for each review is the latest read_at that makes it due.
+	cutoffs := make([]any, len(ReviewIntervals))
+	due := "CASE rl.reviews_done"
+	for i, interval := range ReviewIntervals {
+		cutoffs[i] = now.Add(-interval).UTC().Format("2006-01-02 15:04:05")
+		due += fmt.Sprintf(" WHEN %d THEN ?", i)
+	}
+	due += " END"
+
+	args := append(cutoffs, len(ReviewIntervals))
+	rows, err := s.rdb.QueryContext(ctx, readingListSelectWithoutContent+`
+		JOIN blog_feedback f ON f.blog_id = rl.blog_id AND f.rating > 0
+		WHERE rl.status IN ('read', 'archived') AND rl.read_at IS NOT NULL
+		  AND rl.read_at <= `+due+`
+		  AND rl.reviews_done < ?
+		ORDER BY rl.read_at`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("querying review queue: %w", err)
+	}
+	defer rows.Close()
+
+	var items []models.ReadingListItem
+	for rows.Next() {
+		item, err := scanReadingListItem(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scanning review item: %w", err)
+		}
+		items = append(items, *item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating review queue: %w", err)
+	}
+	if err := s.loadTagsForItems(ctx, items); err != nil {
+		return nil, fmt.Errorf("loading tags: %w", err)
+	}
+
+	// scanReadingListItem doesn't read reviews_done, so look it up apart.
+	done := make(map[int64]int, len(items))
+	rows, err = s.rdb.QueryContext(ctx,
+		`SELECT id, reviews_done FROM reading_list WHERE reviews_done < ?`, len(ReviewIntervals))
+	if err != nil {
+		return nil, fmt.Errorf("querying reviews done: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var id int64
+		var n int
+		if err := rows.Scan(&id, &n); err != nil {
+			return nil, fmt.Errorf("scanning reviews done: %w", err)
+		}
+		done[id] = n
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating reviews done: %w", err)
+	}
+
+	queue := make([]models.ReviewItem, 0, len(items))
+	for _, item := range items {
+		n := done[item.ID]
+		queue = append(queue, models.ReviewItem{
+			ReadingListItem: item,
+			Review:          n + 1,
+			DueAt:           item.ReadAt.Add(ReviewIntervals[n]),
+		})
+	}
+	return queue, nil
+}
+
+// MarkReviewed records that the due review of a reading list item is done,
+// so it leaves the queue until its next interval. Returns ErrNotFound if the
+// item does not exist or has no reviews left.
+func (s *sqlStore) MarkReviewed(ctx context.Context, id int64) error {
+	res, err := s.db.ExecContext(ctx,
+		`UPDATE reading_list SET reviews_done = reviews_done + 1, last_reviewed_at = datetime('now')
+		 WHERE id = ? AND reviews_done < ?`, id, len(ReviewIntervals))
+	if err != nil {
+		return fmt.Errorf("marking reviewed: %w", err)
+	}
+	n, _ := res.RowsAffected()
+	if n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
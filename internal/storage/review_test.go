@@ -0,0 +1,85 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestGetReviewQueue(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+
+	liked := seedReadingListBlog(t, store, "https://test.com/review-liked")
+	unrated := seedReadingListBlog(t, store, "https://test.com/review-unrated")
+	for _, blogID := range []int64{liked, unrated} {
+		if err := store.AddToReadingList(ctx, blogID); err != nil {
+			t.Fatalf("AddToReadingList(%d) error: %v", blogID, err)
+		}
+		id, err := store.GetReadingListIDByBlogID(ctx, blogID)
+		if err != nil {
+			t.Fatalf("GetReadingListIDByBlogID() error: %v", err)
+		}
+		if err := store.UpdateReadingListStatus(ctx, id, "read"); err != nil {
+			t.Fatalf("UpdateReadingListStatus() error: %v", err)
+		}
+	}
+	if err := store.SetBlogFeedback(ctx, liked, 1); err != nil {
+		t.Fatalf("SetBlogFeedback() error: %v", err)
+	}
+	itemID, _ := store.GetReadingListIDByBlogID(ctx, liked)
+
+	now := time.Now()
+	queueAt := func(at time.Time) []int64 {
+		t.Helper()
+		queue, err := store.GetReviewQueue(ctx, at)
+		if err != nil {
+			t.Fatalf("GetReviewQueue() error: %v", err)
+		}
+		var ids []int64
+		for _, item := range queue {
+			ids = append(ids, item.ID)
+		}
+		return ids
+	}
+
+	if ids := queueAt(now.AddDate(0, 0, 6)); len(ids) != 0 {
+		t.Errorf("queue after 6 days = %v, want empty", ids)
+	}
+
+	queue, err := store.GetReviewQueue(ctx, now.AddDate(0, 0, 8))
+	if err != nil {
+		t.Fatalf("GetReviewQueue() error: %v", err)
+	}
+	if len(queue) != 1 || queue[0].ID != itemID {
+		t.Fatalf("queue after 8 days has %d items, want only the liked post", len(queue))
+	}
+	if queue[0].Review != 1 {
+		t.Errorf("Review = %d, want 1", queue[0].Review)
+	}
+
+	// Each review moves the item to the next interval.
+	if err := store.MarkReviewed(ctx, itemID); err != nil {
+		t.Fatalf("MarkReviewed() error: %v", err)
+	}
+	if ids := queueAt(now.AddDate(0, 0, 8)); len(ids) != 0 {
+		t.Errorf("queue after first review = %v, want empty until one month", ids)
+	}
+	queue, _ = store.GetReviewQueue(ctx, now.AddDate(0, 0, 31))
+	if len(queue) != 1 || queue[0].Review != 2 {
+		t.Fatalf("queue after 31 days = %+v, want the second review", queue)
+	}
+
+	for range 2 {
+		if err := store.MarkReviewed(ctx, itemID); err != nil {
+			t.Fatalf("MarkReviewed() error: %v", err)
+		}
+	}
+	if ids := queueAt(now.AddDate(1, 0, 0)); len(ids) != 0 {
+		t.Errorf("queue after all reviews = %v, want empty", ids)
+	}
+	if err := store.MarkReviewed(ctx, itemID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("MarkReviewed() after all reviews error = %v, want ErrNotFound", err)
+	}
+}
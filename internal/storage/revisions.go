@@ -0,0 +1,54 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hoanghai1803/apricot/internal/models"
+)
+
+// GetBlogRevisions returns the earlier versions of a blog's content, kept
+// when a re-fetch changed its content hash, newest first. The current
+// content is not included. Returns ErrNotFound if the blog does not exist.
+func (s *sqlStore) GetBlogRevisions(ctx context.Context, blogID int64) ([]models.BlogRevision, error) {
+	var exists bool
+	if err := s.rdb.QueryRowContext(ctx,
+		`SELECT EXISTS(SELECT 1 FROM blogs WHERE id = ?)`, blogID,
+	).Scan(&exists); err != nil {
+		return nil, fmt.Errorf("checking blog: %w", err)
+	}
+	if !exists {
+		return nil, ErrNotFound
+	}
+
+	rows, err := s.rdb.QueryContext(ctx,
+		`SELECT id, content, content_hash, fetched_at, replaced_at FROM blog_revisions
+		 WHERE blog_id = ?
+		 ORDER BY id DESC`, blogID)
+	if err != nil {
+		return nil, fmt.Errorf("querying blog revisions: %w", err)
+	}
+	defer rows.Close()
+
+	revisions := []models.BlogRevision{}
+	for rows.Next() {
+		var (
+			rev                   models.BlogRevision
+			content               []byte
+			fetchedAt, replacedAt string
+		)
+		if err := rows.Scan(&rev.ID, &content, &rev.ContentHash, &fetchedAt, &replacedAt); err != nil {
+			return nil, fmt.Errorf("scanning blog revision: %w", err)
+		}
+		if rev.Content, err = decodeContent(content); err != nil {
+			return nil, fmt.Errorf("decoding revision %d: %w", rev.ID, err)
+		}
+		rev.FetchedAt = parseTime(fetchedAt)
+		rev.ReplacedAt = parseTime(replacedAt)
+		revisions = append(revisions, rev)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating blog revisions: %w", err)
+	}
+	return revisions, nil
+}
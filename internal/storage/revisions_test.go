@@ -0,0 +1,98 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/hoanghai1803/apricot/internal/models"
+)
+
+func TestGetBlogRevisions(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+	sourceID := seedTestSource(t, store)
+
+	upsert := func(content, hash string) int64 {
+		t.Helper()
+		id, err := store.UpsertBlog(ctx, &models.Blog{
+			SourceID:    sourceID,
+			Title:       "Edited Post",
+			URL:         "https://test.com/edited",
+			FullContent: content,
+			ContentHash: hash,
+			FetchedAt:   time.Now(),
+		})
+		if err != nil {
+			t.Fatalf("UpsertBlog: %v", err)
+		}
+		return id
+	}
+
+	id := upsert("first version", "h1")
+	upsert("first version", "h1")
+	if revs, err := store.GetBlogRevisions(ctx, id); err != nil || len(revs) != 0 {
+		t.Fatalf("GetBlogRevisions() = %d revisions, %v; want none before an edit", len(revs), err)
+	}
+
+	upsert("second version", "h2")
+	// A fetch without content keeps the hash and makes no revision.
+	upsert("", "")
+	upsert("third version", "h3")
+
+	revs, err := store.GetBlogRevisions(ctx, id)
+	if err != nil {
+		t.Fatalf("GetBlogRevisions() error: %v", err)
+	}
+	if len(revs) != 2 {
+		t.Fatalf("got %d revisions, want 2", len(revs))
+	}
+	if revs[0].Content != "second version" || revs[0].ContentHash != "h2" {
+		t.Errorf("newest revision = %q (%s), want the second version", revs[0].Content, revs[0].ContentHash)
+	}
+	if revs[1].Content != "first version" || revs[1].ContentHash != "h1" {
+		t.Errorf("oldest revision = %q (%s), want the first version", revs[1].Content, revs[1].ContentHash)
+	}
+
+	if _, err := store.GetBlogRevisions(ctx, 99999); !errors.Is(err, ErrNotFound) {
+		t.Errorf("GetBlogRevisions(missing) error = %v, want ErrNotFound", err)
+	}
+}
+
+func TestGetBlogRevisions_ColdContent(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+	sourceID := seedTestSource(t, store)
+
+	published := time.Now().AddDate(-2, 0, 0)
+	blog := &models.Blog{
+		SourceID:    sourceID,
+		Title:       "Old Post",
+		URL:         "https://test.com/old-edited",
+		FullContent: "original text",
+		ContentHash: "h1",
+		PublishedAt: &published,
+		FetchedAt:   time.Now(),
+	}
+	id, err := store.UpsertBlog(ctx, blog)
+	if err != nil {
+		t.Fatalf("UpsertBlog: %v", err)
+	}
+	if n, err := store.ArchiveColdContent(ctx, time.Now().AddDate(-1, 0, 0)); err != nil || n != 1 {
+		t.Fatalf("ArchiveColdContent() = %d, %v; want 1", n, err)
+	}
+
+	blog.FullContent, blog.ContentHash = "edited text", "h2"
+	if _, err := store.UpsertBlog(ctx, blog); err != nil {
+		t.Fatalf("UpsertBlog: %v", err)
+	}
+
+	revs, err := store.GetBlogRevisions(ctx, id)
+	if err != nil {
+		t.Fatalf("GetBlogRevisions() error: %v", err)
+	}
+	if len(revs) != 1 || revs[0].Content != "original text" {
+		t.Errorf("revisions = %+v, want the cold original text", revs)
+	}
+}
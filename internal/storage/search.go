@@ -2,76 +2,148 @@ package storage
 
 import (
 	"context"
-	"database/sql"
 	"fmt"
 	"strings"
 
 	"github.com/hoanghai1803/apricot/internal/models"
 )
 
-// SearchBlogs performs a full-text search on blogs using FTS5.
-// Returns matching blogs with source names joined, limited to the given count.
-func (s *Store) SearchBlogs(ctx context.Context, query string, limit int) ([]models.Blog, error) {
+// Markers around matched terms in search highlights and snippets.
+const (
+	highlightOpen  = "<mark>"
+	highlightClose = "</mark>"
+)
+
+// SearchBlogs performs a full-text search on blogs using FTS5, over titles,
+// descriptions, and article text with porter stemming. Returns matching
+// blogs with source names joined and highlighted match fragments, best match
+// first, skipping offset results and limited to the given count. The post
+// content itself is not returned.
+func (s *SQLiteStore) SearchBlogs(ctx context.Context, query string, limit, offset int) ([]models.SearchResult, error) {
 	query = strings.TrimSpace(query)
 	if query == "" {
-		return []models.Blog{}, nil
+		return []models.SearchResult{}, nil
 	}
 	if limit <= 0 {
 		limit = 20
 	}
 
-	rows, err := s.db.QueryContext(ctx,
-		`SELECT b.id, b.source_id, COALESCE(b.custom_source, bs.name, '') AS source,
-				b.title, b.url, b.description, b.full_content,
-				b.published_at, b.fetched_at, b.content_hash, b.reading_time_minutes, b.created_at
+	rows, err := s.rdb.QueryContext(ctx,
+		`SELECT `+blogListColumns+`,
+		        highlight(blogs_fts, 0, ?, ?),
+		        snippet(blogs_fts, -1, ?, ?, '…', 16)
 		 FROM blogs_fts fts
 		 JOIN blogs b ON b.id = fts.rowid
 		 LEFT JOIN blog_sources bs ON bs.id = b.source_id
 		 WHERE blogs_fts MATCH ?
 		 ORDER BY rank
-		 LIMIT ?`,
-		query, limit,
+		 LIMIT ? OFFSET ?`,
+		highlightOpen, highlightClose, highlightOpen, highlightClose, query, limit, max(offset, 0),
 	)
 	if err != nil {
 		return nil, fmt.Errorf("searching blogs: %w", err)
 	}
 	defer rows.Close()
 
-	var blogs []models.Blog
+	results := []models.SearchResult{}
 	for rows.Next() {
 		var (
-			blog           models.Blog
-			description    sql.NullString
-			fullContent    sql.NullString
-			publishedAt    sql.NullString
-			fetchedAt      string
-			contentHash    sql.NullString
-			readingTimeMin sql.NullInt64
-			createdAt      string
+			r  models.SearchResult
+			br blogRow
 		)
-
-		if err := rows.Scan(
-			&blog.ID, &blog.SourceID, &blog.Source,
-			&blog.Title, &blog.URL,
-			&description, &fullContent,
-			&publishedAt, &fetchedAt,
-			&contentHash, &readingTimeMin, &createdAt,
-		); err != nil {
+		dest := append(br.dest(&r.Blog), &r.TitleHighlight, &r.Snippet)
+		if err := rows.Scan(dest...); err != nil {
 			return nil, fmt.Errorf("scanning search result: %w", err)
 		}
+		br.apply(&r.Blog)
+		results = append(results, r)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating search results: %w", err)
+	}
+	return results, nil
+}
 
-		blog.Description = description.String
-		blog.FullContent = fullContent.String
-		blog.ContentHash = contentHash.String
-		if readingTimeMin.Valid {
-			v := int(readingTimeMin.Int64)
-			blog.ReadingTimeMinutes = &v
-		}
-		blog.PublishedAt = parseTimePtr(nullStringToPtr(publishedAt))
-		blog.FetchedAt = parseTime(fetchedAt)
-		blog.CreatedAt = parseTime(createdAt)
+// CountSearchResults returns the number of blogs matching a SearchBlogs
+// query.
+func (s *SQLiteStore) CountSearchResults(ctx context.Context, query string) (int, error) {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return 0, nil
+	}
+	var n int
+	if err := s.rdb.QueryRowContext(ctx,
+		`SELECT COUNT(*) FROM blogs_fts WHERE blogs_fts MATCH ?`, query).Scan(&n); err != nil {
+		return 0, fmt.Errorf("counting search results: %w", err)
+	}
+	return n, nil
+}
 
-		blogs = append(blogs, blog)
+// SearchSavedBlogs performs a full-text search restricted to blogs on the
+// reading list, matching any of the whitespace-separated keywords. Keywords
+// are quoted, so FTS5 syntax in them is matched literally. The blog with
+// excludeID is omitted from the results.
+func (s *SQLiteStore) SearchSavedBlogs(ctx context.Context, keywords string, excludeID int64, limit int) ([]models.Blog, error) {
+	match := ftsAnyTerm(keywords)
+	if match == "" {
+		return []models.Blog{}, nil
+	}
+	if limit <= 0 {
+		limit = 20
+	}
+
+	return s.queryBlogs(ctx,
+		`SELECT `+blogColumns+`
+		 FROM blogs_fts fts
+		 JOIN blogs b ON b.id = fts.rowid
+		 JOIN reading_list rl ON rl.blog_id = b.id
+		 LEFT JOIN blog_sources bs ON bs.id = b.source_id
+		 WHERE blogs_fts MATCH ? AND b.id != ?
+		 ORDER BY rank
+		 LIMIT ?`,
+		match, excludeID, limit,
+	)
+}
+
+// SearchBlogsAnyTerm performs a full-text search over all stored blogs,
+// matching any of the whitespace-separated keywords. Like SearchSavedBlogs,
+// keywords are matched literally.
+func (s *SQLiteStore) SearchBlogsAnyTerm(ctx context.Context, keywords string, limit int) ([]models.Blog, error) {
+	match := ftsAnyTerm(keywords)
+	if match == "" {
+		return []models.Blog{}, nil
+	}
+	if limit <= 0 {
+		limit = 20
+	}
+
+	return s.queryBlogs(ctx,
+		`SELECT `+blogColumns+`
+		 FROM blogs_fts fts
+		 JOIN blogs b ON b.id = fts.rowid
+		 LEFT JOIN blog_sources bs ON bs.id = b.source_id
+		 WHERE blogs_fts MATCH ?
+		 ORDER BY rank
+		 LIMIT ?`,
+		match, limit,
+	)
+}
+
+// queryBlogs runs a query selecting blogColumns and scans every row.
+func (s *sqlStore) queryBlogs(ctx context.Context, query string, args ...any) ([]models.Blog, error) {
+	rows, err := s.rdb.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("searching blogs: %w", err)
+	}
+	defer rows.Close()
+
+	var blogs []models.Blog
+	for rows.Next() {
+		blog, err := scanBlog(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scanning search result: %w", err)
+		}
+		blogs = append(blogs, *blog)
 	}
 	if err := rows.Err(); err != nil {
 		return nil, fmt.Errorf("iterating search results: %w", err)
@@ -82,3 +154,16 @@ func (s *Store) SearchBlogs(ctx context.Context, query string, limit int) ([]mod
 	}
 	return blogs, nil
 }
+
+// ftsAnyTerm builds an FTS5 query matching any of the words in s, each
+// quoted as a string literal.
+func ftsAnyTerm(s string) string {
+	var terms []string
+	for _, word := range strings.Fields(s) {
+		word = strings.ReplaceAll(word, `"`, "")
+		if word != "" {
+			terms = append(terms, `"`+word+`"`)
+		}
+	}
+	return strings.Join(terms, " OR ")
+}
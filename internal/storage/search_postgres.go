@@ -0,0 +1,153 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/hoanghai1803/apricot/internal/models"
+)
+
+// The Postgres search methods mirror those in search.go, using the
+// blog_search table of weighted tsvectors in place of blogs_fts. Queries are
+// parsed with websearch_to_tsquery, which accepts the quoted phrases, OR, and
+// -exclusions people type into search boxes, and never fails on bad syntax.
+
+// pgHeadline is the ts_headline option list for snippets; pgTitleHeadline
+// highlights every match in a title.
+var (
+	pgHeadline      = fmt.Sprintf("StartSel=%s, StopSel=%s, MaxWords=16, MinWords=8, MaxFragments=1, FragmentDelimiter=…", highlightOpen, highlightClose)
+	pgTitleHeadline = fmt.Sprintf("StartSel=%s, StopSel=%s, HighlightAll=true", highlightOpen, highlightClose)
+)
+
+// SearchBlogs performs a full-text search on blogs with Postgres text search
+// and English stemming. Results match SQLiteStore.SearchBlogs: best match
+// first, with a highlighted title and snippet, and without the post content.
+func (s *PostgresStore) SearchBlogs(ctx context.Context, query string, limit, offset int) ([]models.SearchResult, error) {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return []models.SearchResult{}, nil
+	}
+	if limit <= 0 {
+		limit = 20
+	}
+
+	rows, err := s.rdb.QueryContext(ctx,
+		`SELECT `+blogListColumns+`,
+		        ts_headline('english', b.title, q, ?),
+		        ts_headline('english',
+		            concat_ws(' ', b.description, rl.notes, sm.summary, content_text(b.full_content)), q, ?)
+		 FROM blog_search bsr
+		 CROSS JOIN websearch_to_tsquery('english', ?) q
+		 JOIN blogs b ON b.id = bsr.blog_id
+		 LEFT JOIN blog_sources bs ON bs.id = b.source_id
+		 LEFT JOIN reading_list rl ON rl.blog_id = b.id
+		 LEFT JOIN blog_summaries sm ON sm.blog_id = b.id
+		 WHERE bsr.document @@ q
+		 ORDER BY ts_rank(bsr.document, q) DESC, b.id
+		 LIMIT ? OFFSET ?`,
+		pgTitleHeadline, pgHeadline, query, limit, max(offset, 0),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("searching blogs: %w", err)
+	}
+	defer rows.Close()
+
+	results := []models.SearchResult{}
+	for rows.Next() {
+		var (
+			r  models.SearchResult
+			br blogRow
+		)
+		dest := append(br.dest(&r.Blog), &r.TitleHighlight, &r.Snippet)
+		if err := rows.Scan(dest...); err != nil {
+			return nil, fmt.Errorf("scanning search result: %w", err)
+		}
+		br.apply(&r.Blog)
+		results = append(results, r)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating search results: %w", err)
+	}
+	return results, nil
+}
+
+// CountSearchResults returns the number of blogs matching a SearchBlogs
+// query.
+func (s *PostgresStore) CountSearchResults(ctx context.Context, query string) (int, error) {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return 0, nil
+	}
+	var n int
+	if err := s.rdb.QueryRowContext(ctx,
+		`SELECT COUNT(*) FROM blog_search
+		 WHERE document @@ websearch_to_tsquery('english', ?)`, query).Scan(&n); err != nil {
+		return 0, fmt.Errorf("counting search results: %w", err)
+	}
+	return n, nil
+}
+
+// SearchSavedBlogs performs a full-text search restricted to blogs on the
+// reading list, matching any of the whitespace-separated keywords. The blog
+// with excludeID is omitted from the results.
+func (s *PostgresStore) SearchSavedBlogs(ctx context.Context, keywords string, excludeID int64, limit int) ([]models.Blog, error) {
+	match := pgAnyTerm(keywords)
+	if match == "" {
+		return []models.Blog{}, nil
+	}
+	if limit <= 0 {
+		limit = 20
+	}
+
+	return s.queryBlogs(ctx,
+		`SELECT `+blogColumns+`
+		 FROM blog_search bsr
+		 CROSS JOIN websearch_to_tsquery('english', ?) q
+		 JOIN blogs b ON b.id = bsr.blog_id
+		 JOIN reading_list rl ON rl.blog_id = b.id
+		 LEFT JOIN blog_sources bs ON bs.id = b.source_id
+		 WHERE bsr.document @@ q AND b.id != ?
+		 ORDER BY ts_rank(bsr.document, q) DESC, b.id
+		 LIMIT ?`,
+		match, excludeID, limit,
+	)
+}
+
+// SearchBlogsAnyTerm performs a full-text search over all stored blogs,
+// matching any of the whitespace-separated keywords.
+func (s *PostgresStore) SearchBlogsAnyTerm(ctx context.Context, keywords string, limit int) ([]models.Blog, error) {
+	match := pgAnyTerm(keywords)
+	if match == "" {
+		return []models.Blog{}, nil
+	}
+	if limit <= 0 {
+		limit = 20
+	}
+
+	return s.queryBlogs(ctx,
+		`SELECT `+blogColumns+`
+		 FROM blog_search bsr
+		 CROSS JOIN websearch_to_tsquery('english', ?) q
+		 JOIN blogs b ON b.id = bsr.blog_id
+		 LEFT JOIN blog_sources bs ON bs.id = b.source_id
+		 WHERE bsr.document @@ q
+		 ORDER BY ts_rank(bsr.document, q) DESC, b.id
+		 LIMIT ?`,
+		match, limit,
+	)
+}
+
+// pgAnyTerm builds a websearch_to_tsquery query matching any of the words
+// in s. Quotes and leading minus signs are dropped so that words are never
+// read as phrases or exclusions.
+func pgAnyTerm(s string) string {
+	var terms []string
+	for _, word := range strings.Fields(s) {
+		word = strings.TrimLeft(strings.ReplaceAll(word, `"`, ""), "-")
+		if word != "" && !strings.EqualFold(word, "or") {
+			terms = append(terms, word)
+		}
+	}
+	return strings.Join(terms, " or ")
+}
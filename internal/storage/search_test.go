@@ -2,13 +2,14 @@ package storage
 
 import (
 	"context"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/hoanghai1803/apricot/internal/models"
 )
 
-func seedSearchBlog(t *testing.T, store *Store, title, description, url string) int64 {
+func seedSearchBlog(t *testing.T, store *SQLiteStore, title, description, url string) int64 {
 	t.Helper()
 	ctx := context.Background()
 
@@ -40,7 +41,7 @@ func TestSearchBlogs_ByTitle(t *testing.T) {
 	seedSearchBlog(t, store, "Introduction to Machine Learning", "ML basics for engineers", "https://test.com/ml")
 	seedSearchBlog(t, store, "Go Concurrency Patterns", "Advanced Go patterns", "https://test.com/go")
 
-	results, err := store.SearchBlogs(ctx, "distributed", 10)
+	results, err := store.SearchBlogs(ctx, "distributed", 10, 0)
 	if err != nil {
 		t.Fatalf("SearchBlogs() error: %v", err)
 	}
@@ -60,7 +61,7 @@ func TestSearchBlogs_ByDescription(t *testing.T) {
 	seedSearchBlog(t, store, "Some Title", "Kubernetes orchestration patterns", "https://test.com/k8s")
 	seedSearchBlog(t, store, "Another Title", "React component testing", "https://test.com/react")
 
-	results, err := store.SearchBlogs(ctx, "kubernetes", 10)
+	results, err := store.SearchBlogs(ctx, "kubernetes", 10, 0)
 	if err != nil {
 		t.Fatalf("SearchBlogs() error: %v", err)
 	}
@@ -74,7 +75,7 @@ func TestSearchBlogs_EmptyQuery(t *testing.T) {
 	store := newTestStore(t)
 	ctx := context.Background()
 
-	results, err := store.SearchBlogs(ctx, "", 10)
+	results, err := store.SearchBlogs(ctx, "", 10, 0)
 	if err != nil {
 		t.Fatalf("SearchBlogs() error: %v", err)
 	}
@@ -89,7 +90,7 @@ func TestSearchBlogs_NoResults(t *testing.T) {
 
 	seedSearchBlog(t, store, "Go Patterns", "Concurrency in Go", "https://test.com/go2")
 
-	results, err := store.SearchBlogs(ctx, "python", 10)
+	results, err := store.SearchBlogs(ctx, "python", 10, 0)
 	if err != nil {
 		t.Fatalf("SearchBlogs() error: %v", err)
 	}
@@ -104,7 +105,7 @@ func TestSearchBlogs_IncludesSourceName(t *testing.T) {
 
 	seedSearchBlog(t, store, "Unique Test Blog", "Unique description", "https://test.com/unique")
 
-	results, err := store.SearchBlogs(ctx, "unique", 10)
+	results, err := store.SearchBlogs(ctx, "unique", 10, 0)
 	if err != nil {
 		t.Fatalf("SearchBlogs() error: %v", err)
 	}
@@ -126,7 +127,7 @@ func TestSearchBlogs_Limit(t *testing.T) {
 			"https://test.com/micro-"+string(rune('a'+i)))
 	}
 
-	results, err := store.SearchBlogs(ctx, "microservices", 2)
+	results, err := store.SearchBlogs(ctx, "microservices", 2, 0)
 	if err != nil {
 		t.Fatalf("SearchBlogs() error: %v", err)
 	}
@@ -134,3 +135,169 @@ func TestSearchBlogs_Limit(t *testing.T) {
 		t.Errorf("got %d results, want 2 (limited)", len(results))
 	}
 }
+
+func TestSearchBlogs_OffsetAndCount(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+
+	for i := 0; i < 5; i++ {
+		seedSearchBlog(t, store, "Microservices Architecture Part", "Microservices patterns",
+			"https://test.com/micro-"+string(rune('a'+i)))
+	}
+
+	first, err := store.SearchBlogs(ctx, "microservices", 3, 0)
+	if err != nil {
+		t.Fatalf("SearchBlogs() error: %v", err)
+	}
+	rest, err := store.SearchBlogs(ctx, "microservices", 3, 3)
+	if err != nil {
+		t.Fatalf("SearchBlogs() error: %v", err)
+	}
+	if len(first) != 3 || len(rest) != 2 {
+		t.Fatalf("got pages of %d and %d results, want 3 and 2", len(first), len(rest))
+	}
+	seen := make(map[int64]bool)
+	for _, r := range append(first, rest...) {
+		if seen[r.ID] {
+			t.Errorf("blog %d appears on both pages", r.ID)
+		}
+		seen[r.ID] = true
+	}
+
+	total, err := store.CountSearchResults(ctx, "microservices")
+	if err != nil {
+		t.Fatalf("CountSearchResults() error: %v", err)
+	}
+	if total != 5 {
+		t.Errorf("CountSearchResults() = %d, want 5", total)
+	}
+}
+
+func TestSearchSavedBlogs(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+
+	saved := seedSearchBlog(t, store, "Consistent Hashing Explained", "Rings and virtual nodes", "https://test.com/hashing")
+	unsaved := seedSearchBlog(t, store, "Hashing in Databases", "Hash indexes", "https://test.com/hash-db")
+	self := seedSearchBlog(t, store, "Dynamo-style Hashing", "Partitioning with hashing", "https://test.com/dynamo")
+
+	for _, id := range []int64{saved, self} {
+		if err := store.AddToReadingList(ctx, id); err != nil {
+			t.Fatalf("AddToReadingList(%d) error: %v", id, err)
+		}
+	}
+
+	// The hyphen and quote would be FTS5 syntax errors if not quoted.
+	results, err := store.SearchSavedBlogs(ctx, `consistent "hashing" B-tree`, self, 10)
+	if err != nil {
+		t.Fatalf("SearchSavedBlogs() error: %v", err)
+	}
+	if len(results) != 1 {
+		t.Fatalf("got %d results, want 1", len(results))
+	}
+	if results[0].ID != saved {
+		t.Errorf("ID = %d, want %d (unsaved %d and self %d must be excluded)", results[0].ID, saved, unsaved, self)
+	}
+
+	empty, err := store.SearchSavedBlogs(ctx, `  "" `, 0, 10)
+	if err != nil {
+		t.Fatalf("SearchSavedBlogs(empty) error: %v", err)
+	}
+	if len(empty) != 0 {
+		t.Errorf("got %d results for empty keywords, want 0", len(empty))
+	}
+}
+
+func TestSearchBlogs_ContentSnippetWithStemming(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+
+	if _, err := store.UpsertBlog(ctx, &models.Blog{
+		SourceID:    seedTestSource(t, store),
+		Title:       "Lessons From Production",
+		URL:         "https://test.com/lessons",
+		FullContent: "We spent a quarter tuning the query planner. Our caches were cold after every deploy, so we started warming them from a snapshot.",
+		FetchedAt:   time.Now(),
+	}); err != nil {
+		t.Fatalf("UpsertBlog: %v", err)
+	}
+
+	// "caching" only appears as "caches" in the article text.
+	results, err := store.SearchBlogs(ctx, "caching", 10, 0)
+	if err != nil {
+		t.Fatalf("SearchBlogs() error: %v", err)
+	}
+	if len(results) != 1 {
+		t.Fatalf("got %d results, want 1", len(results))
+	}
+	if !strings.Contains(results[0].Snippet, "<mark>caches</mark>") {
+		t.Errorf("Snippet = %q, want the matched term marked", results[0].Snippet)
+	}
+	if results[0].TitleHighlight != "Lessons From Production" {
+		t.Errorf("TitleHighlight = %q, want the unmarked title", results[0].TitleHighlight)
+	}
+
+	results, err = store.SearchBlogs(ctx, "production", 10, 0)
+	if err != nil {
+		t.Fatalf("SearchBlogs() error: %v", err)
+	}
+	if len(results) != 1 || results[0].TitleHighlight != "Lessons From <mark>Production</mark>" {
+		t.Errorf("results = %+v, want the title highlighted", results)
+	}
+}
+
+func TestSearchBlogs_NotesAndSummaries(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+
+	blogID := seedSearchBlog(t, store, "Flow Control in Streaming Systems", "Rate limiting consumers", "https://test.com/flow")
+	if err := store.AddToReadingList(ctx, blogID); err != nil {
+		t.Fatalf("AddToReadingList: %v", err)
+	}
+	itemID, err := store.GetReadingListIDByBlogID(ctx, blogID)
+	if err != nil {
+		t.Fatalf("GetReadingListIDByBlogID: %v", err)
+	}
+
+	search := func(query string) []models.SearchResult {
+		t.Helper()
+		results, err := store.SearchBlogs(ctx, query, 10, 0)
+		if err != nil {
+			t.Fatalf("SearchBlogs(%q) error: %v", query, err)
+		}
+		return results
+	}
+
+	if err := store.UpdateReadingListNotes(ctx, itemID, "This is really about backpressure."); err != nil {
+		t.Fatalf("UpdateReadingListNotes: %v", err)
+	}
+	results := search("backpressure")
+	if len(results) != 1 || !strings.Contains(results[0].Snippet, "<mark>backpressure</mark>") {
+		t.Errorf("search by note = %+v, want the post with the note as snippet", results)
+	}
+
+	for _, text := range []string{"Explains load shedding.", "Explains admission control."} {
+		if err := store.UpsertSummary(ctx, &models.BlogSummary{BlogID: blogID, Summary: text, ModelUsed: "test"}); err != nil {
+			t.Fatalf("UpsertSummary: %v", err)
+		}
+	}
+	if got := search("admission"); len(got) != 1 {
+		t.Errorf("search by summary returned %d results, want 1", len(got))
+	}
+	if got := search("shedding"); len(got) != 0 {
+		t.Errorf("search by replaced summary returned %d results, want 0", len(got))
+	}
+
+	if err := store.RemoveFromReadingList(ctx, itemID); err != nil {
+		t.Fatalf("RemoveFromReadingList: %v", err)
+	}
+	if got := search("backpressure"); len(got) != 0 {
+		t.Errorf("search by note of removed item returned %d results, want 0", len(got))
+	}
+
+	// The index must still agree with its content after all the triggers.
+	if _, err := store.db.ExecContext(ctx,
+		`INSERT INTO blogs_fts(blogs_fts, rank) VALUES ('integrity-check', 1)`); err != nil {
+		t.Errorf("FTS integrity check: %v", err)
+	}
+}
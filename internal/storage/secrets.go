@@ -0,0 +1,89 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+)
+
+// UseSecretKey sets the secret from which the key that encrypts stored
+// secrets is derived. It must be at least 16 bytes, and the same secret
+// must be used every time the database is opened: secrets stored under one
+// cannot be read with another.
+func (s *sqlStore) UseSecretKey(secret string) error {
+	box, err := newSecretBox(secret)
+	if err != nil {
+		return err
+	}
+	s.secrets = box
+	return nil
+}
+
+// SetSecret stores value encrypted under name, replacing any earlier value.
+// It returns ErrNoSecretKey if no secret key is configured, rather than
+// store the value in plain text.
+func (s *sqlStore) SetSecret(ctx context.Context, name, value string) error {
+	if s.secrets == nil {
+		return ErrNoSecretKey
+	}
+	sealed, err := s.secrets.seal([]byte(value), []byte(name))
+	if err != nil {
+		return fmt.Errorf("encrypting secret %q: %w", name, err)
+	}
+	if _, err := s.db.ExecContext(ctx,
+		`INSERT INTO secrets (name, value, updated_at) VALUES (?, ?, datetime('now'))
+		 ON CONFLICT(name) DO UPDATE SET value = excluded.value, updated_at = excluded.updated_at`,
+		name, sealed); err != nil {
+		return fmt.Errorf("storing secret %q: %w", name, err)
+	}
+	return nil
+}
+
+// GetSecret returns the decrypted value stored under name.
+func (s *sqlStore) GetSecret(ctx context.Context, name string) (string, error) {
+	if s.secrets == nil {
+		return "", ErrNoSecretKey
+	}
+	var sealed []byte
+	err := s.rdb.QueryRowContext(ctx,
+		`SELECT value FROM secrets WHERE name = ?`, name).Scan(&sealed)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", fmt.Errorf("secret %q: %w", name, ErrNotFound)
+	}
+	if err != nil {
+		return "", fmt.Errorf("reading secret %q: %w", name, err)
+	}
+	value, err := s.secrets.open(sealed, []byte(name))
+	if err != nil {
+		return "", fmt.Errorf("reading secret %q: %w", name, err)
+	}
+	return string(value), nil
+}
+
+// DeleteSecret removes the secret stored under name. The audit log records
+// the deletion, but never the value.
+func (s *sqlStore) DeleteSecret(ctx context.Context, name string) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("beginning transaction: %w", err)
+	}
+	defer tx.Rollback() //nolint:errcheck // rollback after commit is a no-op
+
+	res, err := tx.ExecContext(ctx, `DELETE FROM secrets WHERE name = ?`, name)
+	if err != nil {
+		return fmt.Errorf("deleting secret %q: %w", name, err)
+	}
+	n, _ := res.RowsAffected()
+	if n == 0 {
+		return fmt.Errorf("secret %q: %w", name, ErrNotFound)
+	}
+	if err := recordAudit(ctx, tx, AuditSecretDelete, "secret", name, nil, nil); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("committing transaction: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,106 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+)
+
+const testSecretKey = "correct horse battery staple"
+
+func TestSecrets(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+	if err := store.UseSecretKey(testSecretKey); err != nil {
+		t.Fatalf("UseSecretKey() error: %v", err)
+	}
+
+	if err := store.SetSecret(ctx, "readwise_token", "tok-123"); err != nil {
+		t.Fatalf("SetSecret() error: %v", err)
+	}
+	if err := store.SetSecret(ctx, "readwise_token", "tok-456"); err != nil {
+		t.Fatalf("SetSecret() replacing error: %v", err)
+	}
+	got, err := store.GetSecret(ctx, "readwise_token")
+	if err != nil {
+		t.Fatalf("GetSecret() error: %v", err)
+	}
+	if got != "tok-456" {
+		t.Errorf("GetSecret() = %q, want %q", got, "tok-456")
+	}
+
+	// The stored value is not the plain text.
+	var stored []byte
+	if err := store.db.QueryRow(`SELECT value FROM secrets WHERE name = 'readwise_token'`).Scan(&stored); err != nil {
+		t.Fatalf("reading stored value: %v", err)
+	}
+	if bytes.Contains(stored, []byte("tok-456")) {
+		t.Error("secret is stored in plain text")
+	}
+
+	if err := store.DeleteSecret(ctx, "readwise_token"); err != nil {
+		t.Fatalf("DeleteSecret() error: %v", err)
+	}
+	if _, err := store.GetSecret(ctx, "readwise_token"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("GetSecret() after delete error = %v, want ErrNotFound", err)
+	}
+	if err := store.DeleteSecret(ctx, "readwise_token"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("DeleteSecret() of missing secret error = %v, want ErrNotFound", err)
+	}
+}
+
+func TestSecrets_NoKey(t *testing.T) {
+	store := newTestStore(t)
+
+	if err := store.SetSecret(context.Background(), "token", "x"); !errors.Is(err, ErrNoSecretKey) {
+		t.Errorf("SetSecret() without key error = %v, want ErrNoSecretKey", err)
+	}
+	if _, err := store.GetSecret(context.Background(), "token"); !errors.Is(err, ErrNoSecretKey) {
+		t.Errorf("GetSecret() without key error = %v, want ErrNoSecretKey", err)
+	}
+}
+
+func TestSecrets_WrongKey(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+	if err := store.UseSecretKey(testSecretKey); err != nil {
+		t.Fatalf("UseSecretKey() error: %v", err)
+	}
+	if err := store.SetSecret(ctx, "token", "x"); err != nil {
+		t.Fatalf("SetSecret() error: %v", err)
+	}
+
+	if err := store.UseSecretKey("a different secret key"); err != nil {
+		t.Fatalf("UseSecretKey() error: %v", err)
+	}
+	if _, err := store.GetSecret(ctx, "token"); err == nil {
+		t.Error("GetSecret() with the wrong key succeeded")
+	}
+}
+
+func TestSecretBox(t *testing.T) {
+	if _, err := newSecretBox("short"); err == nil {
+		t.Error("newSecretBox() accepted a short secret")
+	}
+
+	box, err := newSecretBox(testSecretKey)
+	if err != nil {
+		t.Fatalf("newSecretBox() error: %v", err)
+	}
+	a, err := box.seal([]byte("value"), []byte("name"))
+	if err != nil {
+		t.Fatalf("seal() error: %v", err)
+	}
+	b, _ := box.seal([]byte("value"), []byte("name"))
+	if bytes.Equal(a, b) {
+		t.Error("sealing twice gave the same bytes; nonces are reused")
+	}
+	if _, err := box.open(a, []byte("other name")); err == nil {
+		t.Error("open() with other additional data succeeded")
+	}
+	a[len(a)-1] ^= 1
+	if _, err := box.open(a, []byte("name")); err == nil {
+		t.Error("open() of tampered value succeeded")
+	}
+}
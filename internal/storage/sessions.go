@@ -9,33 +9,36 @@ import (
 )
 
 // CreateSession inserts a new discovery session and returns its ID.
-func (s *Store) CreateSession(ctx context.Context, session *models.DiscoverySession) (int64, error) {
-	res, err := s.db.ExecContext(ctx,
+func (s *sqlStore) CreateSession(ctx context.Context, session *models.DiscoverySession) (int64, error) {
+	fallback := 0
+	if session.Fallback {
+		fallback = 1
+	}
+	var id int64
+	err := s.db.QueryRowContext(ctx,
 		`INSERT INTO discovery_sessions
 			(preferences_snapshot, blogs_considered, blogs_selected, model_used,
-			 input_tokens, output_tokens, results_json, failed_feeds_json)
-		 VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+			 input_tokens, output_tokens, results_json, failed_feeds_json, fallback)
+		 VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+		 RETURNING id`,
 		session.PreferencesSnapshot, session.BlogsConsidered, session.BlogsSelected,
 		session.ModelUsed, session.InputTokens, session.OutputTokens,
 		nullableString(session.ResultsJSON), nullableString(session.FailedFeedsJSON),
-	)
+		fallback,
+	).Scan(&id)
 	if err != nil {
 		return 0, fmt.Errorf("creating session: %w", err)
 	}
-	id, err := res.LastInsertId()
-	if err != nil {
-		return 0, fmt.Errorf("getting session id: %w", err)
-	}
 	return id, nil
 }
 
 // GetLatestSession returns the most recent discovery session, or
 // ErrNotFound if no sessions exist.
-func (s *Store) GetLatestSession(ctx context.Context) (*models.DiscoverySession, error) {
-	row := s.db.QueryRowContext(ctx,
+func (s *sqlStore) GetLatestSession(ctx context.Context) (*models.DiscoverySession, error) {
+	row := s.rdb.QueryRowContext(ctx,
 		`SELECT id, preferences_snapshot, blogs_considered, blogs_selected,
 				model_used, input_tokens, output_tokens, results_json,
-				failed_feeds_json, created_at
+				failed_feeds_json, fallback, created_at
 		 FROM discovery_sessions
 		 ORDER BY created_at DESC, id DESC
 		 LIMIT 1`)
@@ -52,11 +55,11 @@ func (s *Store) GetLatestSession(ctx context.Context) (*models.DiscoverySession,
 
 // GetRecentSessions returns the most recent discovery sessions, ordered by
 // created_at DESC and limited to the specified count.
-func (s *Store) GetRecentSessions(ctx context.Context, limit int) ([]models.DiscoverySession, error) {
-	rows, err := s.db.QueryContext(ctx,
+func (s *sqlStore) GetRecentSessions(ctx context.Context, limit int) ([]models.DiscoverySession, error) {
+	rows, err := s.rdb.QueryContext(ctx,
 		`SELECT id, preferences_snapshot, blogs_considered, blogs_selected,
 				model_used, input_tokens, output_tokens, results_json,
-				failed_feeds_json, created_at
+				failed_feeds_json, fallback, created_at
 		 FROM discovery_sessions
 		 ORDER BY created_at DESC, id DESC
 		 LIMIT ?`, limit)
@@ -79,6 +82,68 @@ func (s *Store) GetRecentSessions(ctx context.Context, limit int) ([]models.Disc
 	return sessions, nil
 }
 
+// GetSession returns the discovery session with the given ID, or
+// ErrNotFound if there is none.
+func (s *sqlStore) GetSession(ctx context.Context, id int64) (*models.DiscoverySession, error) {
+	row := s.rdb.QueryRowContext(ctx,
+		`SELECT id, preferences_snapshot, blogs_considered, blogs_selected,
+				model_used, input_tokens, output_tokens, results_json,
+				failed_feeds_json, fallback, created_at
+		 FROM discovery_sessions
+		 WHERE id = ?`, id)
+
+	sess, err := scanSession(row)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("querying session %d: %w", id, err)
+	}
+	return sess, nil
+}
+
+// ListSessions returns a page of discovery sessions, newest first. A limit
+// of 0 returns every session from offset on.
+func (s *sqlStore) ListSessions(ctx context.Context, limit, offset int) ([]models.DiscoverySession, error) {
+	if limit <= 0 {
+		limit = -1 // SQLite requires a LIMIT before OFFSET; -1 means no limit.
+	}
+	rows, err := s.rdb.QueryContext(ctx,
+		`SELECT id, preferences_snapshot, blogs_considered, blogs_selected,
+				model_used, input_tokens, output_tokens, results_json,
+				failed_feeds_json, fallback, created_at
+		 FROM discovery_sessions
+		 ORDER BY created_at DESC, id DESC
+		 LIMIT ? OFFSET ?`, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("querying sessions: %w", err)
+	}
+	defer rows.Close()
+
+	sessions := []models.DiscoverySession{}
+	for rows.Next() {
+		sess, err := scanSession(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scanning session row: %w", err)
+		}
+		sessions = append(sessions, *sess)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating session rows: %w", err)
+	}
+	return sessions, nil
+}
+
+// CountSessions returns the number of stored discovery sessions.
+func (s *sqlStore) CountSessions(ctx context.Context) (int, error) {
+	var n int
+	if err := s.rdb.QueryRowContext(ctx,
+		`SELECT COUNT(*) FROM discovery_sessions`).Scan(&n); err != nil {
+		return 0, fmt.Errorf("counting sessions: %w", err)
+	}
+	return n, nil
+}
+
 // scanSession scans a single discovery session row from either *sql.Row or *sql.Rows.
 func scanSession(row scanner) (*models.DiscoverySession, error) {
 	var (
@@ -87,12 +152,13 @@ func scanSession(row scanner) (*models.DiscoverySession, error) {
 		outputTokens    sql.NullInt64
 		resultsJSON     sql.NullString
 		failedFeedsJSON sql.NullString
+		fallback        int
 		createdAt       string
 	)
 	if err := row.Scan(
 		&sess.ID, &sess.PreferencesSnapshot, &sess.BlogsConsidered,
 		&sess.BlogsSelected, &sess.ModelUsed, &inputTokens, &outputTokens,
-		&resultsJSON, &failedFeedsJSON, &createdAt,
+		&resultsJSON, &failedFeedsJSON, &fallback, &createdAt,
 	); err != nil {
 		return nil, err
 	}
@@ -106,6 +172,7 @@ func scanSession(row scanner) (*models.DiscoverySession, error) {
 	}
 	sess.ResultsJSON = resultsJSON.String
 	sess.FailedFeedsJSON = failedFeedsJSON.String
+	sess.Fallback = fallback != 0
 	sess.CreatedAt = parseTime(createdAt)
 	return &sess, nil
 }
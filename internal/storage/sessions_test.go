@@ -23,6 +23,7 @@ func TestCreateSession(t *testing.T) {
 		OutputTokens:        &outputTokens,
 		ResultsJSON:         `[{"id":1,"title":"test"}]`,
 		FailedFeedsJSON:     `[{"source":"Bad Blog","error":"timeout"}]`,
+		Fallback:            true,
 	}
 
 	id, err := store.CreateSession(ctx, session)
@@ -68,6 +69,9 @@ func TestCreateSession(t *testing.T) {
 	if got.FailedFeedsJSON != `[{"source":"Bad Blog","error":"timeout"}]` {
 		t.Errorf("FailedFeedsJSON = %q, want %q", got.FailedFeedsJSON, `[{"source":"Bad Blog","error":"timeout"}]`)
 	}
+	if !got.Fallback {
+		t.Error("Fallback = false, want true")
+	}
 	if got.CreatedAt.IsZero() {
 		t.Error("CreatedAt is zero")
 	}
@@ -220,3 +224,72 @@ func TestGetLatestSession(t *testing.T) {
 		t.Errorf("latest ID = %d, want %d", got2.ID, id2)
 	}
 }
+
+func TestListSessions(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+
+	var ids []int64
+	for i := 0; i < 5; i++ {
+		id, err := store.CreateSession(ctx, &models.DiscoverySession{
+			PreferencesSnapshot: "topics",
+			BlogsSelected:       "[]",
+			ModelUsed:           "test-model",
+		})
+		if err != nil {
+			t.Fatalf("CreateSession() error: %v", err)
+		}
+		ids = append(ids, id)
+	}
+
+	page, err := store.ListSessions(ctx, 2, 1)
+	if err != nil {
+		t.Fatalf("ListSessions() error: %v", err)
+	}
+	if len(page) != 2 || page[0].ID != ids[3] || page[1].ID != ids[2] {
+		t.Errorf("ListSessions(2, 1) = %+v, want sessions %d and %d", page, ids[3], ids[2])
+	}
+
+	all, err := store.ListSessions(ctx, 0, 0)
+	if err != nil {
+		t.Fatalf("ListSessions() error: %v", err)
+	}
+	if len(all) != 5 {
+		t.Errorf("ListSessions(0, 0) returned %d sessions, want 5", len(all))
+	}
+
+	n, err := store.CountSessions(ctx)
+	if err != nil {
+		t.Fatalf("CountSessions() error: %v", err)
+	}
+	if n != 5 {
+		t.Errorf("CountSessions() = %d, want 5", n)
+	}
+}
+
+func TestGetSession(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+
+	if _, err := store.GetSession(ctx, 1); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("GetSession() on empty store error = %v, want ErrNotFound", err)
+	}
+
+	id, err := store.CreateSession(ctx, &models.DiscoverySession{
+		PreferencesSnapshot: "topics",
+		BlogsSelected:       "[1]",
+		ModelUsed:           "test-model",
+		ResultsJSON:         `[{"id":1}]`,
+	})
+	if err != nil {
+		t.Fatalf("CreateSession() error: %v", err)
+	}
+
+	got, err := store.GetSession(ctx, id)
+	if err != nil {
+		t.Fatalf("GetSession() error: %v", err)
+	}
+	if got.ID != id || got.ModelUsed != "test-model" || got.ResultsJSON != `[{"id":1}]` {
+		t.Errorf("GetSession() = %+v", got)
+	}
+}
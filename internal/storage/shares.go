@@ -0,0 +1,108 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/hoanghai1803/apricot/internal/models"
+)
+
+// CreateShareLink records a public link with token to reading list item
+// itemID and returns it.
+func (s *sqlStore) CreateShareLink(ctx context.Context, itemID int64, token string, includeNotes bool) (*models.ShareLink, error) {
+	notesInt := 0
+	if includeNotes {
+		notesInt = 1
+	}
+	row := s.db.QueryRowContext(ctx,
+		`INSERT INTO share_links (token, reading_list_id, include_notes)
+		 SELECT ?, id, ? FROM reading_list WHERE id = ?
+		 RETURNING id, token, reading_list_id, include_notes, created_at, revoked_at`,
+		token, notesInt, itemID)
+	link, err := scanShareLink(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("creating share link: %w", err)
+	}
+	return link, nil
+}
+
+// scanShareLink scans a share link row from either *sql.Row or *sql.Rows.
+func scanShareLink(row scanner) (*models.ShareLink, error) {
+	var (
+		link      models.ShareLink
+		notes     int
+		createdAt string
+		revokedAt *string
+	)
+	if err := row.Scan(&link.ID, &link.Token, &link.ReadingListID, &notes,
+		&createdAt, &revokedAt); err != nil {
+		return nil, err
+	}
+	link.IncludeNotes = notes == 1
+	link.CreatedAt = parseTime(createdAt)
+	link.RevokedAt = parseTimePtr(revokedAt)
+	return &link, nil
+}
+
+// ListShareLinks returns the share links of reading list item itemID,
+// revoked ones included, newest first.
+func (s *sqlStore) ListShareLinks(ctx context.Context, itemID int64) ([]models.ShareLink, error) {
+	rows, err := s.rdb.QueryContext(ctx,
+		`SELECT id, token, reading_list_id, include_notes, created_at, revoked_at
+		 FROM share_links
+		 WHERE reading_list_id = ?
+		 ORDER BY id DESC`, itemID)
+	if err != nil {
+		return nil, fmt.Errorf("listing share links: %w", err)
+	}
+	defer rows.Close()
+
+	links := []models.ShareLink{}
+	for rows.Next() {
+		link, err := scanShareLink(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scanning share link: %w", err)
+		}
+		links = append(links, *link)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating share links: %w", err)
+	}
+	return links, nil
+}
+
+// GetShareLink returns the share link with token, or ErrNotFound if there
+// is none or it was revoked.
+func (s *sqlStore) GetShareLink(ctx context.Context, token string) (*models.ShareLink, error) {
+	link, err := scanShareLink(s.rdb.QueryRowContext(ctx,
+		`SELECT id, token, reading_list_id, include_notes, created_at, revoked_at
+		 FROM share_links
+		 WHERE token = ? AND revoked_at IS NULL`, token))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("getting share link: %w", err)
+	}
+	return link, nil
+}
+
+// RevokeShareLink revokes share link id of reading list item itemID.
+// Revoking a revoked link is a no-op.
+func (s *sqlStore) RevokeShareLink(ctx context.Context, itemID, id int64) error {
+	res, err := s.db.ExecContext(ctx,
+		`UPDATE share_links SET revoked_at = COALESCE(revoked_at, datetime('now'))
+		 WHERE id = ? AND reading_list_id = ?`, id, itemID)
+	if err != nil {
+		return fmt.Errorf("revoking share link: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
@@ -0,0 +1,63 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestShareLinks(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+	blogID := seedReadingListBlog(t, store, "https://test.com/shared")
+	if err := store.AddToReadingList(ctx, blogID); err != nil {
+		t.Fatalf("AddToReadingList() error: %v", err)
+	}
+	itemID, err := store.GetReadingListIDByBlogID(ctx, blogID)
+	if err != nil {
+		t.Fatalf("GetReadingListIDByBlogID() error: %v", err)
+	}
+
+	if _, err := store.CreateShareLink(ctx, itemID+100, "missing", true); !errors.Is(err, ErrNotFound) {
+		t.Errorf("CreateShareLink(missing item) error = %v, want ErrNotFound", err)
+	}
+	first, err := store.CreateShareLink(ctx, itemID, "first-token", false)
+	if err != nil {
+		t.Fatalf("CreateShareLink() error: %v", err)
+	}
+	if first.ReadingListID != itemID || first.IncludeNotes || first.CreatedAt.IsZero() {
+		t.Errorf("CreateShareLink() = %+v", first)
+	}
+	if _, err := store.CreateShareLink(ctx, itemID, "second-token", true); err != nil {
+		t.Fatalf("CreateShareLink() error: %v", err)
+	}
+
+	if got, err := store.GetShareLink(ctx, "first-token"); err != nil || got.ID != first.ID {
+		t.Errorf("GetShareLink() = %+v, %v; want the first link", got, err)
+	}
+	if err := store.RevokeShareLink(ctx, itemID, first.ID); err != nil {
+		t.Fatalf("RevokeShareLink() error: %v", err)
+	}
+	if _, err := store.GetShareLink(ctx, "first-token"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("GetShareLink(revoked) error = %v, want ErrNotFound", err)
+	}
+	if err := store.RevokeShareLink(ctx, itemID+1, first.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("RevokeShareLink(other item) error = %v, want ErrNotFound", err)
+	}
+
+	links, err := store.ListShareLinks(ctx, itemID)
+	if err != nil {
+		t.Fatalf("ListShareLinks() error: %v", err)
+	}
+	if len(links) != 2 || links[0].Token != "second-token" || !links[0].IncludeNotes || links[1].RevokedAt == nil {
+		t.Errorf("ListShareLinks() = %+v, want both links newest first, the first revoked", links)
+	}
+
+	// Links go with their item.
+	if err := store.RemoveFromReadingList(ctx, itemID); err != nil {
+		t.Fatalf("RemoveFromReadingList() error: %v", err)
+	}
+	if _, err := store.GetShareLink(ctx, "second-token"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("GetShareLink(deleted item) error = %v, want ErrNotFound", err)
+	}
+}
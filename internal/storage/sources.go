@@ -36,9 +36,9 @@ var defaultSources = []models.BlogSource{
 
 // GetAllSources returns all blog sources regardless of active status,
 // ordered by name. The sentinel "custom://user-added" source is excluded.
-func (s *Store) GetAllSources(ctx context.Context) ([]models.BlogSource, error) {
-	rows, err := s.db.QueryContext(ctx,
-		`SELECT id, name, company, feed_url, site_url, is_active, last_fetch_at, last_fetch_ok, last_error, created_at
+func (s *sqlStore) GetAllSources(ctx context.Context) ([]models.BlogSource, error) {
+	rows, err := s.rdb.QueryContext(ctx,
+		`SELECT id, name, company, feed_url, site_url, is_active, last_fetch_at, last_fetch_ok, last_error, created_at, version
 		 FROM blog_sources WHERE feed_url != 'custom://user-added' ORDER BY name`)
 	if err != nil {
 		return nil, fmt.Errorf("querying all sources: %w", err)
@@ -50,9 +50,9 @@ func (s *Store) GetAllSources(ctx context.Context) ([]models.BlogSource, error)
 
 // GetActiveSources returns all blog sources where is_active = 1,
 // ordered by name.
-func (s *Store) GetActiveSources(ctx context.Context) ([]models.BlogSource, error) {
-	rows, err := s.db.QueryContext(ctx,
-		`SELECT id, name, company, feed_url, site_url, is_active, last_fetch_at, last_fetch_ok, last_error, created_at
+func (s *sqlStore) GetActiveSources(ctx context.Context) ([]models.BlogSource, error) {
+	rows, err := s.rdb.QueryContext(ctx,
+		`SELECT id, name, company, feed_url, site_url, is_active, last_fetch_at, last_fetch_ok, last_error, created_at, version
 		 FROM blog_sources WHERE is_active = 1 ORDER BY name`)
 	if err != nil {
 		return nil, fmt.Errorf("querying active sources: %w", err)
@@ -62,33 +62,61 @@ func (s *Store) GetActiveSources(ctx context.Context) ([]models.BlogSource, erro
 	return scanSources(rows)
 }
 
-// ToggleSource sets the is_active flag for the given source ID.
-// It returns ErrNotFound if no source matches the given ID.
-func (s *Store) ToggleSource(ctx context.Context, id int64, active bool) error {
+// ToggleSource sets the is_active flag for the given source ID, recording
+// the change in the audit log, and returns the source's new version. If
+// ifVersion is non-zero and the source is at another version, nothing
+// changes and ErrVersionConflict is returned. It returns ErrNotFound if no
+// source matches the given ID.
+func (s *sqlStore) ToggleSource(ctx context.Context, id int64, active bool, ifVersion int64) (int64, error) {
 	activeInt := 0
 	if active {
 		activeInt = 1
 	}
 
-	res, err := s.db.ExecContext(ctx,
-		`UPDATE blog_sources SET is_active = ? WHERE id = ?`, activeInt, id)
+	tx, err := s.db.BeginTx(ctx, nil)
 	if err != nil {
-		return fmt.Errorf("toggling source %d: %w", id, err)
+		return 0, fmt.Errorf("beginning transaction: %w", err)
 	}
+	defer tx.Rollback() //nolint:errcheck // rollback after commit is a no-op
 
-	n, err := res.RowsAffected()
-	if err != nil {
-		return fmt.Errorf("checking rows affected for source %d: %w", id, err)
+	if err := lockVersion(ctx, tx, "blog_sources", id, ifVersion); err != nil {
+		return 0, err
 	}
-	if n == 0 {
-		return ErrNotFound
+	var (
+		name      string
+		wasActive int
+		version   int64
+	)
+	if err := tx.QueryRowContext(ctx,
+		`SELECT name, is_active, version FROM blog_sources WHERE id = ?`, id).Scan(&name, &wasActive, &version); err != nil {
+		return 0, fmt.Errorf("reading source %d: %w", id, err)
+	}
+	if wasActive == activeInt {
+		return version, nil
 	}
 
-	return nil
+	if _, err := tx.ExecContext(ctx,
+		`UPDATE blog_sources SET is_active = ? WHERE id = ?`, activeInt, id); err != nil {
+		return 0, fmt.Errorf("toggling source %d: %w", id, err)
+	}
+	if err := recordAudit(ctx, tx, AuditSourceToggle, "source", id,
+		map[string]any{"name": name, "is_active": wasActive == 1},
+		map[string]any{"name": name, "is_active": active}); err != nil {
+		return 0, err
+	}
+	if err := tx.QueryRowContext(ctx,
+		`SELECT version FROM blog_sources WHERE id = ?`, id).Scan(&version); err != nil {
+		return 0, fmt.Errorf("reading source %d version: %w", id, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("committing transaction: %w", err)
+	}
+	return version, nil
 }
 
 // UpdateSourceHealth records the last fetch result for a source.
-func (s *Store) UpdateSourceHealth(ctx context.Context, name string, ok bool, fetchErr string) error {
+func (s *sqlStore) UpdateSourceHealth(ctx context.Context, name string, ok bool, fetchErr string) error {
 	okInt := 0
 	if ok {
 		okInt = 1
@@ -107,10 +135,10 @@ func (s *Store) UpdateSourceHealth(ctx context.Context, name string, ok bool, fe
 	return nil
 }
 
-// SeedDefaults inserts any missing default blog sources. Uses INSERT OR IGNORE
-// so existing sources (matched by feed_url UNIQUE constraint) are not modified.
+// SeedDefaults inserts any missing default blog sources. Uses ON CONFLICT DO
+// NOTHING so existing sources (matched by feed_url UNIQUE constraint) are not modified.
 // This is idempotent and safe to call on every startup.
-func (s *Store) SeedDefaults(ctx context.Context) error {
+func (s *sqlStore) SeedDefaults(ctx context.Context) error {
 	tx, err := s.db.BeginTx(ctx, nil)
 	if err != nil {
 		return fmt.Errorf("beginning seed transaction: %w", err)
@@ -118,8 +146,9 @@ func (s *Store) SeedDefaults(ctx context.Context) error {
 	defer tx.Rollback() //nolint:errcheck // rollback after commit is a no-op
 
 	stmt, err := tx.PrepareContext(ctx,
-		`INSERT OR IGNORE INTO blog_sources (name, company, feed_url, site_url, is_active)
-		 VALUES (?, ?, ?, ?, ?)`)
+		`INSERT INTO blog_sources (name, company, feed_url, site_url, is_active)
+		 VALUES (?, ?, ?, ?, ?)
+		 ON CONFLICT(feed_url) DO NOTHING`)
 	if err != nil {
 		return fmt.Errorf("preparing seed statement: %w", err)
 	}
@@ -162,7 +191,7 @@ func scanSources(rows interface {
 		)
 		if err := rows.Scan(
 			&src.ID, &src.Name, &src.Company, &src.FeedURL,
-			&src.SiteURL, &isActive, &lastFetchAt, &lastFetchOK, &lastError, &createdAt,
+			&src.SiteURL, &isActive, &lastFetchAt, &lastFetchOK, &lastError, &createdAt, &src.Version,
 		); err != nil {
 			return nil, fmt.Errorf("scanning source row: %w", err)
 		}
@@ -130,7 +130,7 @@ func TestGetActiveSources(t *testing.T) {
 	}
 
 	// Deactivate the first source.
-	if err := store.ToggleSource(ctx, active[0].ID, false); err != nil {
+	if _, err := store.ToggleSource(ctx, active[0].ID, false, 0); err != nil {
 		t.Fatalf("ToggleSource error: %v", err)
 	}
 
@@ -167,7 +167,7 @@ func TestToggleSource(t *testing.T) {
 	targetID := all[0].ID
 
 	// Deactivate.
-	if err := store.ToggleSource(ctx, targetID, false); err != nil {
+	if _, err := store.ToggleSource(ctx, targetID, false, 0); err != nil {
 		t.Fatalf("ToggleSource(false) error: %v", err)
 	}
 
@@ -183,7 +183,7 @@ func TestToggleSource(t *testing.T) {
 	}
 
 	// Reactivate.
-	if err := store.ToggleSource(ctx, targetID, true); err != nil {
+	if _, err := store.ToggleSource(ctx, targetID, true, 0); err != nil {
 		t.Fatalf("ToggleSource(true) error: %v", err)
 	}
 
@@ -208,7 +208,7 @@ func TestToggleSource_NotFound(t *testing.T) {
 	store := newTestStore(t)
 	ctx := context.Background()
 
-	err := store.ToggleSource(ctx, 99999, false)
+	_, err := store.ToggleSource(ctx, 99999, false, 0)
 	if err == nil {
 		t.Fatal("expected error for non-existent source, got nil")
 	}
@@ -223,3 +223,29 @@ func TestDefaultSourceCount(t *testing.T) {
 		t.Fatalf("DefaultSourceCount() = %d, want 21", got)
 	}
 }
+
+func TestToggleSource_IfVersion(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+	sourceID := seedTestSource(t, store)
+
+	v, err := store.ToggleSource(ctx, sourceID, false, 1)
+	if err != nil {
+		t.Fatalf("ToggleSource() at current version error: %v", err)
+	}
+	if v != 2 {
+		t.Errorf("version after toggle = %d, want 2", v)
+	}
+
+	// A second tab still holding version 1 is turned away.
+	if _, err := store.ToggleSource(ctx, sourceID, true, 1); !errors.Is(err, ErrVersionConflict) {
+		t.Fatalf("ToggleSource() at stale version error = %v, want ErrVersionConflict", err)
+	}
+	sources, err := store.GetAllSources(ctx)
+	if err != nil {
+		t.Fatalf("GetAllSources() error: %v", err)
+	}
+	if len(sources) != 1 || sources[0].IsActive || sources[0].Version != 2 {
+		t.Errorf("sources = %+v, want the source inactive at version 2", sources)
+	}
+}
@@ -1,8 +1,9 @@
-// Package storage provides the SQLite persistence layer for Apricot.
+// Package storage provides the persistence layer for Apricot.
 //
 // It manages database connections, schema migrations, and CRUD operations
-// for all domain entities. The database uses WAL journal mode for concurrent
-// reads and a single-writer model.
+// for all domain entities. SQLite is the default backend; it uses WAL
+// journal mode for concurrent reads and a single-writer model. Postgres is
+// supported for servers that already run one (see postgres.go).
 package storage
 
 import (
@@ -12,7 +13,7 @@ import (
 	"log/slog"
 	"os"
 	"path/filepath"
-	"sort"
+	"runtime"
 	"strconv"
 	"strings"
 	"time"
@@ -20,25 +21,23 @@ import (
 	_ "modernc.org/sqlite" // Pure Go SQLite driver.
 )
 
-// Store wraps a SQL database connection and provides typed query methods
-// for all Apricot domain entities.
-type Store struct {
-	db *sql.DB
+// SQLiteStore implements Store on a SQLite database.
+type SQLiteStore struct {
+	*sqlStore
 }
 
-// NewStore creates a Store backed by the given database connection.
-func NewStore(db *sql.DB) *Store {
-	return &Store{db: db}
+// NewSQLiteStore creates a SQLiteStore backed by the given database
+// connection. Reads share it until UseReadPool is called.
+func NewSQLiteStore(db *sql.DB) *SQLiteStore {
+	return &SQLiteStore{&sqlStore{db: db, rdb: db, dialect: dialectSQLite}}
 }
 
-// Close closes the underlying database connection.
-func (s *Store) Close() error {
-	return s.db.Close()
-}
-
-// DB returns the underlying *sql.DB for advanced use cases.
-func (s *Store) DB() *sql.DB {
-	return s.db
+// UseReadPool sends the store's reads outside transactions to rdb, a pool
+// opened with OpenReadPool on the same database file, so that they no
+// longer wait behind writes on the single write connection. The store
+// closes rdb when it is closed.
+func (s *SQLiteStore) UseReadPool(rdb *sql.DB) {
+	s.rdb = rdb
 }
 
 // OpenDatabase opens (or creates) a SQLite database at the given path.
@@ -46,7 +45,8 @@ func (s *Store) DB() *sql.DB {
 // and foreign key enforcement. Parent directories are created if missing.
 //
 // The returned *sql.DB is limited to a single connection because SQLite
-// supports only one concurrent writer.
+// supports only one concurrent writer. Reads can be moved off it with
+// OpenReadPool and SQLiteStore.UseReadPool.
 func OpenDatabase(path string) (*sql.DB, error) {
 	// For in-memory databases, skip directory creation.
 	if path != ":memory:" {
@@ -76,21 +76,58 @@ func OpenDatabase(path string) (*sql.DB, error) {
 	return db, nil
 }
 
+// OpenReadPool opens a pool of read-only connections to the SQLite database
+// at path, which OpenDatabase must already have opened in WAL mode. WAL lets
+// readers run alongside the single writer, each seeing the last committed
+// state. The connections are opened with query_only set, so a write sent to
+// the pool by mistake fails instead of competing with the writer.
+//
+// An in-memory database is private to its connection and cannot be shared,
+// so ":memory:" is rejected.
+func OpenReadPool(path string) (*sql.DB, error) {
+	if path == ":memory:" {
+		return nil, fmt.Errorf("opening read pool: in-memory databases cannot be shared")
+	}
+
+	dsn := path + "?_pragma=busy_timeout(5000)&_pragma=foreign_keys(ON)&_pragma=query_only(1)"
+
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("opening read pool for %q: %w", path, err)
+	}
+
+	conns := max(runtime.NumCPU(), 4)
+	db.SetMaxOpenConns(conns)
+	db.SetMaxIdleConns(conns)
+
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("pinging read pool for %q: %w", path, err)
+	}
+	return db, nil
+}
+
 //go:embed migrations/*.sql
 var migrationsFS embed.FS
 
 // RunMigrations applies any unapplied schema migrations to the database.
 // Migration SQL files are read from the embedded migrations/ directory.
-// Each file must be named NNN_description.sql where NNN is the version number.
+// Each file must be named NNN_description.sql where NNN is the version number,
+// with an optional NNN_description.down.sql that reverts it (see RollbackTo).
 // Each migration runs inside its own transaction for atomicity.
 func RunMigrations(db *sql.DB) error {
-	// Ensure the tracking table exists.
-	const createTracker = `
+	return runMigrations(db, migrationsFS, "migrations", `
 		CREATE TABLE IF NOT EXISTS schema_migrations (
 			version    INTEGER PRIMARY KEY,
 			applied_at TEXT NOT NULL DEFAULT (datetime('now'))
 		);
-	`
+	`)
+}
+
+// runMigrations applies the unapplied migrations in dir of fsys, after
+// creating the schema_migrations tracking table with createTracker.
+func runMigrations(db *sql.DB, fsys embed.FS, dir, createTracker string) error {
+	// Ensure the tracking table exists.
 	if _, err := db.Exec(createTracker); err != nil {
 		return fmt.Errorf("creating schema_migrations table: %w", err)
 	}
@@ -101,48 +138,27 @@ func RunMigrations(db *sql.DB) error {
 		return fmt.Errorf("reading applied migrations: %w", err)
 	}
 
-	// Read migration files from the embedded filesystem.
-	entries, err := migrationsFS.ReadDir("migrations")
+	migrations, err := loadMigrations(fsys, dir)
 	if err != nil {
-		return fmt.Errorf("reading migrations directory: %w", err)
-	}
-
-	// Parse and sort migration files by version number.
-	type migrationFile struct {
-		version  int
-		filename string
-	}
-	var files []migrationFile
-	for _, entry := range entries {
-		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".sql") {
-			continue
-		}
-		version := parseVersion(entry.Name())
-		if version <= 0 {
-			continue
-		}
-		files = append(files, migrationFile{version: version, filename: entry.Name()})
+		return err
 	}
-	sort.Slice(files, func(i, j int) bool {
-		return files[i].version < files[j].version
-	})
 
 	// Apply each unapplied migration.
-	for _, mf := range files {
-		if applied[mf.version] {
+	for _, m := range migrations {
+		if applied[m.version] {
 			continue
 		}
 
-		sqlBytes, err := migrationsFS.ReadFile("migrations/" + mf.filename)
+		sqlBytes, err := fsys.ReadFile(dir + "/" + m.name)
 		if err != nil {
-			return fmt.Errorf("reading migration file %q: %w", mf.filename, err)
+			return fmt.Errorf("reading migration file %q: %w", m.name, err)
 		}
 
-		if err := applyMigration(db, mf.version, string(sqlBytes)); err != nil {
-			return fmt.Errorf("applying migration %s: %w", mf.filename, err)
+		if err := applyMigration(db, m.version, string(sqlBytes)); err != nil {
+			return fmt.Errorf("applying migration %s: %w", m.name, err)
 		}
 
-		slog.Info("applied migration", "version", mf.version, "file", mf.filename)
+		slog.Info("applied migration", "version", m.version, "file", m.name)
 	}
 
 	return nil
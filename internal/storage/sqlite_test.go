@@ -1,10 +1,12 @@
 package storage
 
 import (
+	"context"
 	"database/sql"
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 )
 
 // newTestDB creates an in-memory SQLite database with migrations applied.
@@ -27,11 +29,11 @@ func newTestDB(t *testing.T) *sql.DB {
 
 // newTestStore creates an in-memory Store with migrations applied.
 // The store is automatically closed when the test completes.
-func newTestStore(t *testing.T) *Store {
+func newTestStore(t *testing.T) *SQLiteStore {
 	t.Helper()
 
 	db := newTestDB(t)
-	return NewStore(db)
+	return NewSQLiteStore(db)
 }
 
 func TestOpenDatabase_InMemory(t *testing.T) {
@@ -145,20 +147,72 @@ func TestRunMigrations_Idempotent(t *testing.T) {
 	if err := db.QueryRow("SELECT COUNT(*) FROM schema_migrations").Scan(&count); err != nil {
 		t.Fatalf("counting migrations: %v", err)
 	}
-	if count != 7 {
-		t.Fatalf("expected 7 migration records, got %d", count)
+	if count != 37 {
+		t.Fatalf("expected 37 migration records, got %d", count)
 	}
 }
 
 func TestNewStore(t *testing.T) {
 	db := newTestDB(t)
-	store := NewStore(db)
+	store := NewSQLiteStore(db)
 
 	if store.DB() != db {
 		t.Fatal("NewStore did not store the provided *sql.DB")
 	}
 }
 
+func TestReadPool(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.db")
+	db, err := OpenDatabase(path)
+	if err != nil {
+		t.Fatalf("OpenDatabase() error: %v", err)
+	}
+	if err := RunMigrations(db); err != nil {
+		t.Fatalf("RunMigrations() error: %v", err)
+	}
+	store := NewSQLiteStore(db)
+	rdb, err := OpenReadPool(path)
+	if err != nil {
+		t.Fatalf("OpenReadPool() error: %v", err)
+	}
+	store.UseReadPool(rdb)
+	t.Cleanup(func() { store.Close() })
+
+	ctx := context.Background()
+	if err := store.SetPreference(ctx, "feed_mode", "time_range"); err != nil {
+		t.Fatalf("SetPreference() error: %v", err)
+	}
+
+	// Hold the write connection in an open transaction: reads must not
+	// wait for it, and see the last committed state.
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		t.Fatalf("BeginTx() error: %v", err)
+	}
+	defer tx.Rollback()
+	if _, err := tx.ExecContext(ctx,
+		`UPDATE preferences SET value = '"recent_posts"' WHERE key = 'feed_mode'`); err != nil {
+		t.Fatalf("updating in transaction: %v", err)
+	}
+
+	readCtx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	defer cancel()
+	var mode string
+	if err := store.GetPreference(readCtx, "feed_mode", &mode); err != nil {
+		t.Fatalf("GetPreference() during a write transaction error: %v", err)
+	}
+	if mode != "time_range" {
+		t.Errorf("feed_mode = %q, want the committed %q", mode, "time_range")
+	}
+
+	if _, err := rdb.ExecContext(ctx, `DELETE FROM preferences`); err == nil {
+		t.Error("write through the read pool succeeded, want it rejected")
+	}
+	if _, err := OpenReadPool(":memory:"); err == nil {
+		t.Error("OpenReadPool(\":memory:\") succeeded, want an error")
+	}
+}
+
 func TestParseTime(t *testing.T) {
 	tests := []struct {
 		name  string
@@ -0,0 +1,76 @@
+package storage
+
+import (
+	"database/sql"
+	"errors"
+	"strings"
+)
+
+// dialect identifies the SQL database behind a sqlStore.
+type dialect int
+
+const (
+	dialectSQLite dialect = iota
+	dialectPostgres
+)
+
+// sqlStore holds the queries shared by SQLiteStore and PostgresStore.
+//
+// The queries are written for SQLite and kept portable: placeholders are
+// "?" (rewritten for Postgres by its connector), timestamps are TEXT in
+// "2006-01-02 15:04:05" form, and flags are 0/1 integers. The Postgres
+// schema defines the datetime, content_text, and json_each functions the
+// queries use, so only full-text search needs separate SQL.
+//
+// Writes and transactions go to db. Reads outside a transaction go to rdb,
+// which is db itself unless a SQLite store was given a read pool (see
+// UseReadPool).
+type sqlStore struct {
+	db      *sql.DB
+	rdb     *sql.DB
+	dialect dialect
+	secrets *secretBox // encrypts secrets; nil until UseSecretKey
+}
+
+// Close closes the underlying database connections.
+func (s *sqlStore) Close() error {
+	err := s.db.Close()
+	if s.rdb != s.db {
+		err = errors.Join(err, s.rdb.Close())
+	}
+	return err
+}
+
+// DB returns the underlying *sql.DB for advanced use cases.
+func (s *sqlStore) DB() *sql.DB {
+	return s.db
+}
+
+// encodeContent returns the value to store for article text. SQLite gets
+// zstd-compressed bytes (see content.go); Postgres gets the plain UTF-8
+// text, which it compresses itself when storing large values, so that its
+// content_text function and search index can read it.
+func (s *sqlStore) encodeContent(content string) any {
+	if s.dialect == dialectPostgres {
+		if content == "" {
+			return nil
+		}
+		return []byte(content)
+	}
+	return encodeContent(content)
+}
+
+// isUniqueViolation reports whether err is a unique constraint failure.
+func isUniqueViolation(err error) bool {
+	msg := err.Error()
+	return strings.Contains(msg, "UNIQUE constraint failed") || // SQLite
+		strings.Contains(msg, "SQLSTATE 23505") // Postgres
+}
+
+// isForeignKeyViolation reports whether err is a foreign key constraint
+// failure.
+func isForeignKeyViolation(err error) bool {
+	msg := err.Error()
+	return strings.Contains(msg, "FOREIGN KEY constraint failed") || // SQLite
+		strings.Contains(msg, "SQLSTATE 23503") // Postgres
+}
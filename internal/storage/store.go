@@ -0,0 +1,251 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/hoanghai1803/apricot/internal/models"
+)
+
+// Compile-time interface check.
+var _ Store = (*SQLiteStore)(nil)
+
+// Store is the persistence layer used by the HTTP handlers and background
+// jobs. It is split by domain so that code needing only part of it can
+// accept the smaller interface. SQLiteStore and PostgresStore implement it;
+// tests can embed Store in a struct and override only the methods they
+// exercise.
+//
+// Methods return an error wrapping ErrNotFound when the record they address
+// does not exist.
+type Store interface {
+	BlogStore
+	SummaryStore
+	ReadingListStore
+	TagStore
+	SourceStore
+	PreferenceStore
+	SessionStore
+	SearchStore
+	LearningPathStore
+	ResearchStore
+	ReviewStore
+	ReportStore
+	ArchiveStore
+	AICacheStore
+	MigrationStore
+	MaintenanceStore
+	SecretStore
+	AuditStore
+	JobStore
+	ShareStore
+	BookmarkStore
+	NotionStore
+
+	// Close releases the underlying connection.
+	Close() error
+}
+
+// BlogStore stores fetched and user-added blog posts.
+type BlogStore interface {
+	UpsertBlog(ctx context.Context, blog *models.Blog) (int64, error)
+	SaveBlogs(ctx context.Context, blogs []models.Blog) error
+	GetBlogByURL(ctx context.Context, url string) (*models.Blog, error)
+	GetBlogByID(ctx context.Context, id int64) (*models.Blog, error)
+	GetBlogsFetchedSince(ctx context.Context, since time.Time) ([]models.Blog, error)
+	GetCustomSourceID(ctx context.Context) (int64, error)
+	CreateCustomBlog(ctx context.Context, url, title, description, fullContent, customSource string) (int64, error)
+	UpdateCustomBlog(ctx context.Context, id int64, title, description, fullContent, customSource string) error
+	UpdateReadingTime(ctx context.Context, blogID int64, minutes int) error
+	UpdateBlogDifficulty(ctx context.Context, blogID int64, difficulty string) error
+	UpdateBlogRewrittenTitle(ctx context.Context, blogID int64, title string) error
+	GetBlogRevisions(ctx context.Context, blogID int64) ([]models.BlogRevision, error)
+	GetReaderContent(ctx context.Context, blogID int64) (string, error)
+	SaveReaderContent(ctx context.Context, blogID int64, html string) error
+	ArchiveColdContent(ctx context.Context, cutoff time.Time) (int, error)
+	PrunableBlogs(ctx context.Context, cutoff time.Time) ([]models.Blog, error)
+	PruneBlogs(ctx context.Context, cutoff time.Time) (int, error)
+}
+
+// SummaryStore stores AI summaries of blog posts.
+type SummaryStore interface {
+	UpsertSummary(ctx context.Context, summary *models.BlogSummary) error
+	GetSummaryByBlogID(ctx context.Context, blogID int64) (*models.BlogSummary, error)
+	HasSummary(ctx context.Context, blogID int64) (bool, error)
+}
+
+// ReadingListStore stores the reading list and its items' state.
+type ReadingListStore interface {
+	AddToReadingList(ctx context.Context, blogID int64) error
+	AddManyToReadingList(ctx context.Context, blogIDs []int64) ([]int64, error)
+	GetReadingList(ctx context.Context, status string) ([]models.ReadingListItem, error)
+	GetReadingListFiltered(ctx context.Context, filter ReadingListFilter) ([]models.ReadingListItem, error)
+	CountReadingList(ctx context.Context, filter ReadingListFilter) (int, error)
+	GetReadingListItemByID(ctx context.Context, id int64) (*models.ReadingListItem, error)
+	GetReadingListIDByBlogID(ctx context.Context, blogID int64) (int64, error)
+	UpdateReadingListItem(ctx context.Context, id int64, update ReadingListUpdate) (int64, error)
+	UpdateReadingListStatus(ctx context.Context, id int64, status string) error
+	UpdateReadingListProgress(ctx context.Context, id int64, progress int, scrollPosition *float64) error
+	UpdateReadingListNotes(ctx context.Context, id int64, notes string) error
+	GetNoteHistory(ctx context.Context, id int64) ([]models.NoteRevision, error)
+	SnoozeReadingListItem(ctx context.Context, id int64, until *time.Time) error
+	WakeSnoozedItems(ctx context.Context, now time.Time) (int64, error)
+	ArchiveReadItems(ctx context.Context) (int64, error)
+	ReorderReadingList(ctx context.Context, ids []int64) error
+	BulkUpdateReadingList(ctx context.Context, ids []int64, action BulkAction) error
+	RemoveFromReadingList(ctx context.Context, id int64) error
+}
+
+// TagStore stores tags and which reading list items carry them.
+type TagStore interface {
+	AddTagToItem(ctx context.Context, readingListID int64, tagName string) error
+	RemoveTagFromItem(ctx context.Context, readingListID int64, tagName string) error
+	GetAllTags(ctx context.Context) ([]models.Tag, error)
+	UpdateTag(ctx context.Context, name, color, description string) error
+	GetReadingListByTag(ctx context.Context, tag string) ([]models.ReadingListItem, error)
+}
+
+// SourceStore stores blog sources, their fetch health, and the feedback
+// that scores them.
+type SourceStore interface {
+	GetAllSources(ctx context.Context) ([]models.BlogSource, error)
+	GetActiveSources(ctx context.Context) ([]models.BlogSource, error)
+	ToggleSource(ctx context.Context, id int64, active bool, ifVersion int64) (int64, error)
+	UpdateSourceHealth(ctx context.Context, name string, ok bool, fetchErr string) error
+	SetBlogFeedback(ctx context.Context, blogID int64, rating int) error
+	GetSourceScores(ctx context.Context, since time.Time) ([]models.SourceScore, error)
+	GetSourceStats(ctx context.Context, id int64, since time.Time) (*models.SourceStats, error)
+	SeedDefaults(ctx context.Context) error
+}
+
+// PreferenceStore stores user preferences as JSON values.
+type PreferenceStore interface {
+	GetPreference(ctx context.Context, key string, dest any) error
+	SetPreference(ctx context.Context, key string, value any) error
+	GetAllPreferences(ctx context.Context) (map[string]json.RawMessage, error)
+}
+
+// SessionStore stores discovery sessions.
+type SessionStore interface {
+	CreateSession(ctx context.Context, session *models.DiscoverySession) (int64, error)
+	GetLatestSession(ctx context.Context) (*models.DiscoverySession, error)
+	GetRecentSessions(ctx context.Context, limit int) ([]models.DiscoverySession, error)
+	GetSession(ctx context.Context, id int64) (*models.DiscoverySession, error)
+	ListSessions(ctx context.Context, limit, offset int) ([]models.DiscoverySession, error)
+	CountSessions(ctx context.Context) (int, error)
+}
+
+// SearchStore searches stored blog posts.
+type SearchStore interface {
+	SearchBlogs(ctx context.Context, query string, limit, offset int) ([]models.SearchResult, error)
+	CountSearchResults(ctx context.Context, query string) (int, error)
+	SearchSavedBlogs(ctx context.Context, keywords string, excludeID int64, limit int) ([]models.Blog, error)
+	SearchBlogsAnyTerm(ctx context.Context, keywords string, limit int) ([]models.Blog, error)
+}
+
+// LearningPathStore stores learning paths and their steps.
+type LearningPathStore interface {
+	CreateLearningPath(ctx context.Context, path *models.LearningPath, steps []models.LearningPathStep) (int64, error)
+	ListLearningPaths(ctx context.Context) ([]models.LearningPath, error)
+	GetLearningPath(ctx context.Context, id int64) (*models.LearningPath, error)
+	UpdateLearningPath(ctx context.Context, id int64, title, description *string) error
+	SetLearningPathItems(ctx context.Context, id int64, steps []models.LearningPathStep) error
+	DeleteLearningPath(ctx context.Context, id int64) error
+}
+
+// ResearchStore stores research reports.
+type ResearchStore interface {
+	CreateResearchReport(ctx context.Context, report *models.ResearchReport) (int64, error)
+	ListResearchReports(ctx context.Context, limit int) ([]models.ResearchReport, error)
+	GetResearchReport(ctx context.Context, id int64) (*models.ResearchReport, error)
+	DeleteResearchReport(ctx context.Context, id int64) error
+}
+
+// ReviewStore schedules spaced-repetition reviews of read posts.
+type ReviewStore interface {
+	GetReviewQueue(ctx context.Context, now time.Time) ([]models.ReviewItem, error)
+	MarkReviewed(ctx context.Context, id int64) error
+}
+
+// ReportStore computes reading reports.
+type ReportStore interface {
+	GetYearReport(ctx context.Context, year int) (*models.YearReport, error)
+}
+
+// ArchiveStore exports and imports everything as an archive.
+type ArchiveStore interface {
+	SchemaVersion(ctx context.Context) (int, error)
+	ExportAll(ctx context.Context, write func(*models.ArchiveStream) error) error
+	ImportArchive(ctx context.Context, data *models.ArchiveData, opts ImportOptions) (*models.ArchiveImportResult, error)
+}
+
+// AICacheStore persists raw AI responses. It satisfies ai.ResponseCache.
+type AICacheStore interface {
+	GetAIResponse(ctx context.Context, key string) (string, error)
+	PutAIResponse(ctx context.Context, key, operation, model, response string) error
+	PruneAIResponses(ctx context.Context, cutoff time.Time) (int64, error)
+}
+
+// MigrationStore reports and reverts schema migrations.
+type MigrationStore interface {
+	MigrationStatus(ctx context.Context) (*models.MigrationStatus, error)
+	RollbackTo(ctx context.Context, version int) error
+}
+
+// MaintenanceStore reports on the database's size and keeps it compact.
+type MaintenanceStore interface {
+	DBStats(ctx context.Context) (*models.DBStats, error)
+	RunMaintenance(ctx context.Context) (*models.MaintenanceResult, error)
+	Vacuum(ctx context.Context) error
+	IntegrityCheck(ctx context.Context) ([]string, error)
+}
+
+// SecretStore stores credentials and API keys encrypted at rest.
+type SecretStore interface {
+	SetSecret(ctx context.Context, name, value string) error
+	GetSecret(ctx context.Context, name string) (string, error)
+	DeleteSecret(ctx context.Context, name string) error
+}
+
+// AuditStore reads the log of configuration changes and deletions, which
+// the other stores write as they make them.
+type AuditStore interface {
+	ListAuditLog(ctx context.Context, filter AuditFilter) ([]models.AuditEntry, error)
+}
+
+// JobStore keeps the background job queue run by the jobs package.
+type JobStore interface {
+	CreateJob(ctx context.Context, kind string, payload json.RawMessage, maxAttempts int) (*models.Job, error)
+	GetJob(ctx context.Context, id int64) (*models.Job, error)
+	ListJobs(ctx context.Context, filter JobFilter) ([]models.Job, error)
+	ClaimJob(ctx context.Context, now time.Time) (*models.Job, error)
+	FinishJob(ctx context.Context, id int64, result json.RawMessage) error
+	FailJob(ctx context.Context, id int64, errMsg string, retryAt *time.Time) error
+	RequeueInterruptedJobs(ctx context.Context) (int, error)
+	PruneJobs(ctx context.Context, cutoff time.Time) (int64, error)
+}
+
+// ShareStore stores public links to reading list items.
+type ShareStore interface {
+	CreateShareLink(ctx context.Context, itemID int64, token string, includeNotes bool) (*models.ShareLink, error)
+	ListShareLinks(ctx context.Context, itemID int64) ([]models.ShareLink, error)
+	GetShareLink(ctx context.Context, token string) (*models.ShareLink, error)
+	RevokeShareLink(ctx context.Context, itemID, id int64) error
+}
+
+// BookmarkStore stores the links between reading list items and the
+// bookmarks mirroring them in a bookmarking service.
+type BookmarkStore interface {
+	ListBookmarkLinks(ctx context.Context, service string) ([]models.BookmarkLink, error)
+	SaveBookmarkLink(ctx context.Context, link *models.BookmarkLink) error
+	DeleteBookmarkLink(ctx context.Context, service, remoteID string) error
+}
+
+// NotionStore stores which Notion page each reading list item was
+// exported to.
+type NotionStore interface {
+	ListNotionPages(ctx context.Context, databaseID string) ([]models.NotionPage, error)
+	SaveNotionPage(ctx context.Context, page *models.NotionPage) error
+	DeleteNotionPage(ctx context.Context, databaseID string, readingListID int64) error
+}
@@ -10,16 +10,20 @@ import (
 )
 
 // UpsertSummary inserts a blog summary or updates it if a row with the same
-// blog_id already exists.
-func (s *Store) UpsertSummary(ctx context.Context, summary *models.BlogSummary) error {
+// blog_id already exists. The stored summary is no longer stale.
+func (s *sqlStore) UpsertSummary(ctx context.Context, summary *models.BlogSummary) error {
 	_, err := s.db.ExecContext(ctx,
-		`INSERT INTO blog_summaries (blog_id, summary, model_used)
-		 VALUES (?, ?, ?)
+		`INSERT INTO blog_summaries (blog_id, summary, difficulty, category, model_used)
+		 VALUES (?, ?, ?, ?, ?)
 		 ON CONFLICT(blog_id) DO UPDATE SET
 			summary    = excluded.summary,
+			difficulty = excluded.difficulty,
+			category   = excluded.category,
 			model_used = excluded.model_used,
+			stale      = 0,
 			created_at = datetime('now')`,
-		summary.BlogID, summary.Summary, summary.ModelUsed,
+		summary.BlogID, summary.Summary, nullableString(summary.Difficulty),
+		nullableString(summary.Category), summary.ModelUsed,
 	)
 	if err != nil {
 		return fmt.Errorf("upserting summary: %w", err)
@@ -27,31 +31,37 @@ func (s *Store) UpsertSummary(ctx context.Context, summary *models.BlogSummary)
 	return nil
 }
 
-// GetSummaryByBlogID returns the summary for the given blog ID.
+// GetSummaryByBlogID returns the summary for the given blog ID, flagged
+// stale if the blog's content changed since it was generated.
 // Returns nil, ErrNotFound if no matching row exists.
-func (s *Store) GetSummaryByBlogID(ctx context.Context, blogID int64) (*models.BlogSummary, error) {
+func (s *sqlStore) GetSummaryByBlogID(ctx context.Context, blogID int64) (*models.BlogSummary, error) {
 	var (
-		summary   models.BlogSummary
-		createdAt string
+		summary    models.BlogSummary
+		difficulty sql.NullString
+		category   sql.NullString
+		createdAt  string
 	)
-	err := s.db.QueryRowContext(ctx,
-		`SELECT id, blog_id, summary, model_used, created_at
+	err := s.rdb.QueryRowContext(ctx,
+		`SELECT id, blog_id, summary, difficulty, category, model_used, stale, created_at
 		 FROM blog_summaries WHERE blog_id = ?`, blogID,
-	).Scan(&summary.ID, &summary.BlogID, &summary.Summary, &summary.ModelUsed, &createdAt)
+	).Scan(&summary.ID, &summary.BlogID, &summary.Summary, &difficulty, &category, &summary.ModelUsed,
+		&summary.Stale, &createdAt)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, ErrNotFound
 		}
 		return nil, fmt.Errorf("getting summary by blog id: %w", err)
 	}
+	summary.Difficulty = difficulty.String
+	summary.Category = category.String
 	summary.CreatedAt = parseTime(createdAt)
 	return &summary, nil
 }
 
 // HasSummary returns true if a summary exists for the given blog ID.
-func (s *Store) HasSummary(ctx context.Context, blogID int64) (bool, error) {
+func (s *sqlStore) HasSummary(ctx context.Context, blogID int64) (bool, error) {
 	var exists bool
-	err := s.db.QueryRowContext(ctx,
+	err := s.rdb.QueryRowContext(ctx,
 		`SELECT EXISTS(SELECT 1 FROM blog_summaries WHERE blog_id = ?)`, blogID,
 	).Scan(&exists)
 	if err != nil {
@@ -10,7 +10,7 @@ import (
 )
 
 // seedTestBlog creates a source and a blog for use in summary tests, returning the blog ID.
-func seedTestBlog(t *testing.T, store *Store) int64 {
+func seedTestBlog(t *testing.T, store *SQLiteStore) int64 {
 	t.Helper()
 	sourceID := seedTestSource(t, store)
 	blog := &models.Blog{
@@ -146,3 +146,97 @@ func TestHasSummary_NonExistentBlog(t *testing.T) {
 		t.Error("HasSummary() = true for non-existent blog, want false")
 	}
 }
+
+func TestUpsertSummary_DifficultyAndCategory(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+	blogID := seedTestBlog(t, store)
+
+	if err := store.UpsertSummary(ctx, &models.BlogSummary{
+		BlogID:     blogID,
+		Summary:    "Summary.",
+		Difficulty: models.DifficultyDeepDive,
+		Category:   "databases",
+		ModelUsed:  "claude-haiku-4-5",
+	}); err != nil {
+		t.Fatalf("UpsertSummary() error: %v", err)
+	}
+
+	got, err := store.GetSummaryByBlogID(ctx, blogID)
+	if err != nil {
+		t.Fatalf("GetSummaryByBlogID() error: %v", err)
+	}
+	if got.Difficulty != models.DifficultyDeepDive || got.Category != "databases" {
+		t.Errorf("got difficulty=%q category=%q, want deep-dive and databases", got.Difficulty, got.Category)
+	}
+}
+
+func TestUpsertBlog_ContentChangeMarksSummaryStale(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+	sourceID := seedTestSource(t, store)
+
+	blog := &models.Blog{
+		SourceID:    sourceID,
+		Title:       "Changing Post",
+		URL:         "https://test.com/changing",
+		FullContent: "first version",
+		ContentHash: "hash-1",
+		FetchedAt:   time.Now(),
+	}
+	blogID, err := store.UpsertBlog(ctx, blog)
+	if err != nil {
+		t.Fatalf("UpsertBlog() error: %v", err)
+	}
+	if err := store.UpsertSummary(ctx, &models.BlogSummary{
+		BlogID: blogID, Summary: "About the first version.", ModelUsed: "test-model",
+	}); err != nil {
+		t.Fatalf("UpsertSummary() error: %v", err)
+	}
+
+	stale := func() bool {
+		t.Helper()
+		got, err := store.GetSummaryByBlogID(ctx, blogID)
+		if err != nil {
+			t.Fatalf("GetSummaryByBlogID() error: %v", err)
+		}
+		return got.Stale
+	}
+
+	// A feed refresh carries no content and keeps the stored hash.
+	if _, err := store.UpsertBlog(ctx, &models.Blog{
+		SourceID: sourceID, Title: blog.Title, URL: blog.URL, FetchedAt: time.Now(),
+	}); err != nil {
+		t.Fatalf("UpsertBlog() error: %v", err)
+	}
+	if stale() {
+		t.Fatal("summary stale after a refresh without content")
+	}
+
+	// Re-extracting identical content is not a change.
+	if _, err := store.UpsertBlog(ctx, blog); err != nil {
+		t.Fatalf("UpsertBlog() error: %v", err)
+	}
+	if stale() {
+		t.Fatal("summary stale after unchanged content")
+	}
+
+	blog.FullContent = "second version"
+	blog.ContentHash = "hash-2"
+	if _, err := store.UpsertBlog(ctx, blog); err != nil {
+		t.Fatalf("UpsertBlog() error: %v", err)
+	}
+	if !stale() {
+		t.Fatal("summary not stale after content changed")
+	}
+
+	// Regenerating the summary clears the flag.
+	if err := store.UpsertSummary(ctx, &models.BlogSummary{
+		BlogID: blogID, Summary: "About the second version.", ModelUsed: "test-model",
+	}); err != nil {
+		t.Fatalf("UpsertSummary() error: %v", err)
+	}
+	if stale() {
+		t.Error("summary still stale after regenerating")
+	}
+}
@@ -2,6 +2,8 @@ package storage
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 	"fmt"
 	"strings"
 
@@ -11,7 +13,7 @@ import (
 // AddTagToItem adds a tag to a reading list item. The tag is created if it
 // doesn't exist yet. Returns an error if the reading list item doesn't exist
 // or the tag is already attached.
-func (s *Store) AddTagToItem(ctx context.Context, readingListID int64, tagName string) error {
+func (s *sqlStore) AddTagToItem(ctx context.Context, readingListID int64, tagName string) error {
 	tagName = strings.TrimSpace(strings.ToLower(tagName))
 	if tagName == "" {
 		return fmt.Errorf("tag name cannot be empty")
@@ -19,7 +21,7 @@ func (s *Store) AddTagToItem(ctx context.Context, readingListID int64, tagName s
 
 	// Verify the reading list item exists.
 	var exists bool
-	if err := s.db.QueryRowContext(ctx,
+	if err := s.rdb.QueryRowContext(ctx,
 		`SELECT EXISTS(SELECT 1 FROM reading_list WHERE id = ?)`, readingListID,
 	).Scan(&exists); err != nil {
 		return fmt.Errorf("checking reading list item: %w", err)
@@ -30,14 +32,14 @@ func (s *Store) AddTagToItem(ctx context.Context, readingListID int64, tagName s
 
 	// Create tag if it doesn't exist.
 	if _, err := s.db.ExecContext(ctx,
-		`INSERT OR IGNORE INTO tags (name) VALUES (?)`, tagName,
+		`INSERT INTO tags (name) VALUES (?) ON CONFLICT DO NOTHING`, tagName,
 	); err != nil {
 		return fmt.Errorf("creating tag: %w", err)
 	}
 
 	// Get tag ID.
 	var tagID int64
-	if err := s.db.QueryRowContext(ctx,
+	if err := s.rdb.QueryRowContext(ctx,
 		`SELECT id FROM tags WHERE name = ?`, tagName,
 	).Scan(&tagID); err != nil {
 		return fmt.Errorf("getting tag id: %w", err)
@@ -45,7 +47,8 @@ func (s *Store) AddTagToItem(ctx context.Context, readingListID int64, tagName s
 
 	// Link tag to reading list item.
 	if _, err := s.db.ExecContext(ctx,
-		`INSERT OR IGNORE INTO reading_list_tags (reading_list_id, tag_id) VALUES (?, ?)`,
+		`INSERT INTO reading_list_tags (reading_list_id, tag_id) VALUES (?, ?)
+		 ON CONFLICT DO NOTHING`,
 		readingListID, tagID,
 	); err != nil {
 		return fmt.Errorf("linking tag to item: %w", err)
@@ -55,13 +58,14 @@ func (s *Store) AddTagToItem(ctx context.Context, readingListID int64, tagName s
 }
 
 // RemoveTagFromItem removes a tag from a reading list item. If the tag is no
-// longer used by any item, it is deleted from the tags table.
-func (s *Store) RemoveTagFromItem(ctx context.Context, readingListID int64, tagName string) error {
+// longer used by any item and has no color or description, it is deleted
+// from the tags table.
+func (s *sqlStore) RemoveTagFromItem(ctx context.Context, readingListID int64, tagName string) error {
 	tagName = strings.TrimSpace(strings.ToLower(tagName))
 
 	// Get tag ID.
 	var tagID int64
-	if err := s.db.QueryRowContext(ctx,
+	if err := s.rdb.QueryRowContext(ctx,
 		`SELECT id FROM tags WHERE name = ?`, tagName,
 	).Scan(&tagID); err != nil {
 		return ErrNotFound
@@ -80,11 +84,10 @@ func (s *Store) RemoveTagFromItem(ctx context.Context, readingListID int64, tagN
 		return ErrNotFound
 	}
 
-	// Clean up unused tags.
+	// Clean up unused tags, unless the user has described them.
 	if _, err := s.db.ExecContext(ctx,
-		`DELETE FROM tags WHERE id = ? AND NOT EXISTS (
-			SELECT 1 FROM reading_list_tags WHERE tag_id = ?
-		)`, tagID, tagID,
+		`DELETE FROM tags WHERE id = ? AND color IS NULL AND description IS NULL
+		 AND NOT EXISTS (SELECT 1 FROM reading_list_tags WHERE tag_id = ?)`, tagID, tagID,
 	); err != nil {
 		return fmt.Errorf("cleaning up unused tag: %w", err)
 	}
@@ -92,34 +95,90 @@ func (s *Store) RemoveTagFromItem(ctx context.Context, readingListID int64, tagN
 	return nil
 }
 
-// GetAllTags returns all distinct tag names, ordered alphabetically.
-func (s *Store) GetAllTags(ctx context.Context) ([]string, error) {
-	rows, err := s.db.QueryContext(ctx, `SELECT name FROM tags ORDER BY name`)
+// GetAllTags returns all tags with their metadata and item counts, ordered
+// alphabetically.
+func (s *sqlStore) GetAllTags(ctx context.Context) ([]models.Tag, error) {
+	rows, err := s.rdb.QueryContext(ctx,
+		`SELECT t.name, t.color, t.description, COUNT(rlt.reading_list_id)
+		 FROM tags t
+		 LEFT JOIN reading_list_tags rlt ON rlt.tag_id = t.id
+		 GROUP BY t.id
+		 ORDER BY t.name`)
 	if err != nil {
 		return nil, fmt.Errorf("querying tags: %w", err)
 	}
 	defer rows.Close()
 
-	var tags []string
+	tags := []models.Tag{}
 	for rows.Next() {
-		var name string
-		if err := rows.Scan(&name); err != nil {
+		var (
+			tag                models.Tag
+			color, description sql.NullString
+		)
+		if err := rows.Scan(&tag.Name, &color, &description, &tag.ItemCount); err != nil {
 			return nil, fmt.Errorf("scanning tag: %w", err)
 		}
-		tags = append(tags, name)
+		tag.Color = color.String
+		tag.Description = description.String
+		tags = append(tags, tag)
 	}
 	if err := rows.Err(); err != nil {
 		return nil, fmt.Errorf("iterating tags: %w", err)
 	}
+	return tags, nil
+}
 
-	if tags == nil {
-		tags = []string{}
+// UpdateTag sets the color and description of a tag, creating the tag if it
+// doesn't exist yet, and records changes in the audit log. Empty values
+// clear the field.
+func (s *sqlStore) UpdateTag(ctx context.Context, name, color, description string) error {
+	name = strings.TrimSpace(strings.ToLower(name))
+	if name == "" {
+		return fmt.Errorf("tag name cannot be empty")
 	}
-	return tags, nil
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("beginning transaction: %w", err)
+	}
+	defer tx.Rollback() //nolint:errcheck // rollback after commit is a no-op
+
+	var (
+		before                   map[string]any
+		oldColor, oldDescription sql.NullString
+	)
+	err = tx.QueryRowContext(ctx,
+		`SELECT color, description FROM tags WHERE name = ?`, name).Scan(&oldColor, &oldDescription)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+	case err != nil:
+		return fmt.Errorf("reading tag: %w", err)
+	case oldColor.String == color && oldDescription.String == description:
+		return nil
+	default:
+		before = map[string]any{"color": oldColor.String, "description": oldDescription.String}
+	}
+
+	if _, err := tx.ExecContext(ctx,
+		`INSERT INTO tags (name, color, description) VALUES (?, ?, ?)
+		 ON CONFLICT(name) DO UPDATE SET color = excluded.color, description = excluded.description`,
+		name, nullableString(color), nullableString(description),
+	); err != nil {
+		return fmt.Errorf("updating tag: %w", err)
+	}
+	if err := recordAudit(ctx, tx, AuditTagUpdate, "tag", name, before,
+		map[string]any{"color": color, "description": description}); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("committing transaction: %w", err)
+	}
+	return nil
 }
 
 // GetReadingListByTag returns reading list items that have the given tag.
-func (s *Store) GetReadingListByTag(ctx context.Context, tag string) ([]models.ReadingListItem, error) {
+func (s *sqlStore) GetReadingListByTag(ctx context.Context, tag string) ([]models.ReadingListItem, error) {
 	tag = strings.TrimSpace(strings.ToLower(tag))
 
 	items, err := s.GetReadingList(ctx, "")
@@ -146,7 +205,7 @@ func (s *Store) GetReadingListByTag(ctx context.Context, tag string) ([]models.R
 // loadTagsForItems loads tags for the given reading list items and attaches
 // them to each item's Tags field. This is called by GetReadingList after the
 // main query to avoid complicating the already-complex JOIN.
-func (s *Store) loadTagsForItems(ctx context.Context, items []models.ReadingListItem) error {
+func (s *sqlStore) loadTagsForItems(ctx context.Context, items []models.ReadingListItem) error {
 	if len(items) == 0 {
 		return nil
 	}
@@ -168,7 +227,7 @@ func (s *Store) loadTagsForItems(ctx context.Context, items []models.ReadingList
 		strings.Join(ids, ","),
 	)
 
-	rows, err := s.db.QueryContext(ctx, query, args...)
+	rows, err := s.rdb.QueryContext(ctx, query, args...)
 	if err != nil {
 		return fmt.Errorf("loading tags for items: %w", err)
 	}
@@ -4,6 +4,8 @@ import (
 	"context"
 	"errors"
 	"testing"
+
+	"github.com/hoanghai1803/apricot/internal/models"
 )
 
 func TestAddTagToItem(t *testing.T) {
@@ -177,9 +179,61 @@ func TestGetAllTags(t *testing.T) {
 		t.Fatalf("got %d tags, want 2", len(tags))
 	}
 	// Should be alphabetically ordered.
-	if tags[0] != "golang" || tags[1] != "rust" {
+	if tags[0].Name != "golang" || tags[1].Name != "rust" {
 		t.Errorf("tags = %v, want [golang, rust]", tags)
 	}
+	if tags[0].ItemCount != 2 || tags[1].ItemCount != 1 {
+		t.Errorf("item counts = %d, %d; want 2, 1", tags[0].ItemCount, tags[1].ItemCount)
+	}
+}
+
+func TestUpdateTag(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+	blogID := seedReadingListBlog(t, store, "https://test.com/tag-meta")
+
+	if err := store.AddToReadingList(ctx, blogID); err != nil {
+		t.Fatalf("AddToReadingList() error: %v", err)
+	}
+	items, _ := store.GetReadingList(ctx, "")
+	itemID := items[0].ID
+
+	if err := store.AddTagToItem(ctx, itemID, "databases"); err != nil {
+		t.Fatalf("AddTagToItem() error: %v", err)
+	}
+	if err := store.UpdateTag(ctx, "Databases", "#f59e0b", "Storage engines and query planners"); err != nil {
+		t.Fatalf("UpdateTag() error: %v", err)
+	}
+	// Tags can be described before they are used.
+	if err := store.UpdateTag(ctx, "later", "", "Posts to revisit"); err != nil {
+		t.Fatalf("UpdateTag(new) error: %v", err)
+	}
+
+	tags, err := store.GetAllTags(ctx)
+	if err != nil {
+		t.Fatalf("GetAllTags() error: %v", err)
+	}
+	want := []models.Tag{
+		{Name: "databases", Color: "#f59e0b", Description: "Storage engines and query planners", ItemCount: 1},
+		{Name: "later", Description: "Posts to revisit"},
+	}
+	if len(tags) != len(want) {
+		t.Fatalf("got %d tags, want %d", len(tags), len(want))
+	}
+	for i := range want {
+		if tags[i] != want[i] {
+			t.Errorf("tags[%d] = %+v, want %+v", i, tags[i], want[i])
+		}
+	}
+
+	// Described tags survive losing their last item.
+	if err := store.RemoveTagFromItem(ctx, itemID, "databases"); err != nil {
+		t.Fatalf("RemoveTagFromItem() error: %v", err)
+	}
+	tags, _ = store.GetAllTags(ctx)
+	if len(tags) != 2 || tags[0].ItemCount != 0 {
+		t.Errorf("tags after untagging = %+v, want databases kept with 0 items", tags)
+	}
 }
 
 func TestGetAllTags_Empty(t *testing.T) {
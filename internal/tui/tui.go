@@ -0,0 +1,402 @@
+// Package tui is Apricot's terminal UI. It shows the latest discovery
+// results and the reading list, with each post's summary, and lets the
+// reader save discovered posts and mark saved ones read, working on the
+// store directly like the other commands.
+package tui
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/hoanghai1803/apricot/internal/api/handlers"
+	"github.com/hoanghai1803/apricot/internal/models"
+	"github.com/hoanghai1803/apricot/internal/storage"
+)
+
+// Run shows the terminal UI until the reader quits.
+func Run(ctx context.Context, store storage.Store) error {
+	_, err := tea.NewProgram(newModel(ctx, store), tea.WithAltScreen(), tea.WithContext(ctx)).Run()
+	return err
+}
+
+// tab is one of the UI's two lists.
+type tab int
+
+const (
+	tabDiscover tab = iota
+	tabReadingList
+)
+
+// Messages carrying the results of commands.
+type (
+	discoveryLoaded   struct{ resp handlers.DiscoverResponse }
+	readingListLoaded struct{ items []models.ReadingListItem }
+	// changed reports a change to the reading list, which is then reloaded.
+	changed struct{ status string }
+	failed  struct{ err error }
+)
+
+// model is the state of the UI.
+type model struct {
+	ctx   context.Context
+	store storage.Store
+
+	tab        tab
+	results    []handlers.DiscoverResult
+	discovered string // when the results were discovered
+	items      []models.ReadingListItem
+	cursor     [2]int // the selected row of each tab
+	offset     [2]int // the first row shown of each tab
+	detail     bool   // showing the selected post instead of the list
+	status     string // the outcome of the last action
+
+	width, height int
+}
+
+func newModel(ctx context.Context, store storage.Store) model {
+	return model{ctx: ctx, store: store, width: 80, height: 24}
+}
+
+func (m model) Init() tea.Cmd {
+	return tea.Batch(m.loadDiscovery, m.loadReadingList)
+}
+
+func (m model) loadDiscovery() tea.Msg {
+	resp, err := handlers.LatestDiscovery(m.ctx, m.store)
+	if err != nil {
+		return failed{err}
+	}
+	return discoveryLoaded{resp}
+}
+
+func (m model) loadReadingList() tea.Msg {
+	items, err := m.store.GetReadingListFiltered(m.ctx, storage.ReadingListFilter{
+		Snoozed:        storage.SnoozeHide,
+		WithoutContent: true,
+	})
+	if err != nil {
+		return failed{fmt.Errorf("loading reading list: %w", err)}
+	}
+	return readingListLoaded{items}
+}
+
+// save adds the selected discovery result to the reading list.
+func (m model) save() tea.Cmd {
+	r, ok := m.selectedResult()
+	if !ok {
+		return nil
+	}
+	return func() tea.Msg {
+		if err := m.store.AddToReadingList(m.ctx, r.ID); err != nil {
+			return failed{err}
+		}
+		return changed{fmt.Sprintf("Saved %q", r.Title)}
+	}
+}
+
+// setStatus sets the status of the selected reading list item.
+func (m model) setStatus(status string) tea.Cmd {
+	item, ok := m.selectedItem()
+	if !ok || item.Status == status {
+		return nil
+	}
+	return func() tea.Msg {
+		if err := m.store.UpdateReadingListStatus(m.ctx, item.ID, status); err != nil {
+			return failed{err}
+		}
+		return changed{fmt.Sprintf("Marked %q %s", item.Blog.Title, status)}
+	}
+}
+
+func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width, m.height = msg.Width, msg.Height
+		m.scroll()
+	case discoveryLoaded:
+		m.results = msg.resp.Results
+		m.discovered = msg.resp.CreatedAt
+		m.scroll()
+	case readingListLoaded:
+		m.items = msg.items
+		m.scroll()
+	case changed:
+		m.status = msg.status
+		return m, m.loadReadingList
+	case failed:
+		m.status = "Error: " + msg.err.Error()
+	case tea.KeyMsg:
+		return m.key(msg)
+	}
+	return m, nil
+}
+
+// key handles a key press.
+func (m model) key(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if m.detail {
+		switch msg.String() {
+		case "ctrl+c":
+			return m, tea.Quit
+		case "esc", "q", "enter", "backspace":
+			m.detail = false
+			return m, nil
+		}
+	}
+
+	switch msg.String() {
+	case "ctrl+c", "q":
+		return m, tea.Quit
+	case "tab", "shift+tab":
+		m.tab, m.detail = 1-m.tab, false
+	case "1":
+		m.tab, m.detail = tabDiscover, false
+	case "2":
+		m.tab, m.detail = tabReadingList, false
+	case "up", "k":
+		m.cursor[m.tab]--
+	case "down", "j":
+		m.cursor[m.tab]++
+	case "pgup":
+		m.cursor[m.tab] -= m.rows()
+	case "pgdown":
+		m.cursor[m.tab] += m.rows()
+	case "home", "g":
+		m.cursor[m.tab] = 0
+	case "end", "G":
+		m.cursor[m.tab] = m.count() - 1
+	case "enter":
+		m.detail = m.count() > 0
+	case "R":
+		m.status = ""
+		return m, tea.Batch(m.loadDiscovery, m.loadReadingList)
+	case "s":
+		if m.tab == tabDiscover {
+			return m, m.save()
+		}
+	case "r":
+		if m.tab == tabReadingList {
+			return m, m.setStatus("read")
+		}
+	case "u":
+		if m.tab == tabReadingList {
+			return m, m.setStatus("unread")
+		}
+	case "a":
+		if m.tab == tabReadingList {
+			return m, m.setStatus("archived")
+		}
+	default:
+		return m, nil
+	}
+	m.scroll()
+	return m, nil
+}
+
+// count returns the number of rows in the current tab.
+func (m model) count() int {
+	if m.tab == tabDiscover {
+		return len(m.results)
+	}
+	return len(m.items)
+}
+
+// rows returns how many list rows fit between the header and footer.
+func (m model) rows() int {
+	return max(m.height-4, 1)
+}
+
+// scroll keeps the cursor of the current tab on a row, and that row on
+// screen.
+func (m *model) scroll() {
+	t := m.tab
+	m.cursor[t] = max(min(m.cursor[t], m.count()-1), 0)
+	if m.cursor[t] < m.offset[t] {
+		m.offset[t] = m.cursor[t]
+	}
+	if m.cursor[t] >= m.offset[t]+m.rows() {
+		m.offset[t] = m.cursor[t] - m.rows() + 1
+	}
+}
+
+func (m model) selectedResult() (handlers.DiscoverResult, bool) {
+	if m.tab != tabDiscover || len(m.results) == 0 {
+		return handlers.DiscoverResult{}, false
+	}
+	return m.results[m.cursor[tabDiscover]], true
+}
+
+func (m model) selectedItem() (models.ReadingListItem, bool) {
+	if m.tab != tabReadingList || len(m.items) == 0 || m.items[m.cursor[tabReadingList]].Blog == nil {
+		return models.ReadingListItem{}, false
+	}
+	return m.items[m.cursor[tabReadingList]], true
+}
+
+var (
+	accent        = lipgloss.Color("208") // apricot orange
+	activeTab     = lipgloss.NewStyle().Bold(true).Foreground(accent)
+	inactiveTab   = lipgloss.NewStyle().Faint(true)
+	selectedStyle = lipgloss.NewStyle().Bold(true).Foreground(accent)
+	faint         = lipgloss.NewStyle().Faint(true)
+	heading       = lipgloss.NewStyle().Bold(true)
+)
+
+func (m model) View() string {
+	var b strings.Builder
+	b.WriteString(m.header())
+	b.WriteString("\n\n")
+	if m.detail {
+		b.WriteString(m.detailView())
+	} else {
+		b.WriteString(m.listView())
+	}
+	b.WriteString("\n")
+	b.WriteString(m.footer())
+	return b.String()
+}
+
+func (m model) header() string {
+	names := []string{
+		fmt.Sprintf("1 Discover (%d)", len(m.results)),
+		fmt.Sprintf("2 Reading list (%d)", len(m.items)),
+	}
+	for i, name := range names {
+		if tab(i) == m.tab {
+			names[i] = activeTab.Render(name)
+		} else {
+			names[i] = inactiveTab.Render(name)
+		}
+	}
+	return strings.Join(names, "   ")
+}
+
+func (m model) listView() string {
+	var lines []string
+	if m.count() == 0 {
+		if m.tab == tabDiscover {
+			lines = append(lines, faint.Render(`No discovery results yet. Run "apricot discover" or use the web app.`))
+		} else {
+			lines = append(lines, faint.Render("The reading list is empty. Save a result with s."))
+		}
+	}
+	end := min(m.offset[m.tab]+m.rows(), m.count())
+	for i := m.offset[m.tab]; i < end; i++ {
+		title, meta := m.row(i)
+		line := "  " + title
+		if i == m.cursor[m.tab] {
+			line = selectedStyle.Render("› " + title)
+		}
+		lines = append(lines, truncate(line+"  "+faint.Render(meta), m.width))
+	}
+	for len(lines) < m.rows() {
+		lines = append(lines, "")
+	}
+	return strings.Join(lines, "\n")
+}
+
+// row returns the title and details of row i of the current tab.
+func (m model) row(i int) (title, meta string) {
+	if m.tab == tabDiscover {
+		r := m.results[i]
+		return resultTitle(r), r.Source
+	}
+	item := m.items[i]
+	if item.Blog == nil {
+		return "(deleted post)", item.Status
+	}
+	return itemTitle(item), item.Blog.Source + " · " + item.Status
+}
+
+func (m model) detailView() string {
+	width := max(min(m.width, 100), 20)
+	wrap := lipgloss.NewStyle().Width(width)
+
+	var title, summary, reason, url string
+	var meta []string
+	if r, ok := m.selectedResult(); ok {
+		title, summary, reason, url = resultTitle(r), r.Summary, r.Reason, r.URL
+		meta = append(meta, r.Source)
+		if r.ReadingTimeMinutes != nil {
+			meta = append(meta, fmt.Sprintf("%d min", *r.ReadingTimeMinutes))
+		}
+		if r.Difficulty != "" {
+			meta = append(meta, r.Difficulty)
+		}
+	} else if item, ok := m.selectedItem(); ok {
+		title, url = itemTitle(item), item.Blog.URL
+		if item.Summary != nil {
+			summary = *item.Summary
+		}
+		meta = append(meta, item.Blog.Source, item.Status)
+		if item.Blog.ReadingTimeMinutes != nil {
+			meta = append(meta, fmt.Sprintf("%d min", *item.Blog.ReadingTimeMinutes))
+		}
+		if len(item.Tags) > 0 {
+			meta = append(meta, strings.Join(item.Tags, ", "))
+		}
+	}
+
+	parts := []string{
+		wrap.Inherit(heading).Render(title),
+		faint.Render(strings.Join(meta, " · ")),
+		faint.Render(url),
+		"",
+	}
+	if summary == "" {
+		summary = faint.Render("No summary.")
+	}
+	parts = append(parts, wrap.Render(summary))
+	if reason != "" {
+		parts = append(parts, "", wrap.Render(heading.Render("Why: ")+reason))
+	}
+	lines := strings.Split(strings.Join(parts, "\n"), "\n")
+	if len(lines) > m.rows() {
+		lines = lines[:m.rows()]
+	}
+	for len(lines) < m.rows() {
+		lines = append(lines, "")
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (m model) footer() string {
+	keys := "↑/↓ move · enter summary · tab switch · R reload · q quit"
+	switch {
+	case m.detail:
+		keys = "esc back · q back · ctrl+c quit"
+	case m.tab == tabDiscover:
+		keys = "s save · " + keys
+	default:
+		keys = "r read · u unread · a archive · " + keys
+	}
+	if m.status != "" {
+		return truncate(m.status, m.width) + "\n" + faint.Render(keys)
+	}
+	if m.tab == tabDiscover && m.discovered != "" && !m.detail {
+		return faint.Render("Discovered "+m.discovered) + "\n" + faint.Render(keys)
+	}
+	return "\n" + faint.Render(keys)
+}
+
+func resultTitle(r handlers.DiscoverResult) string {
+	if r.RewrittenTitle != "" {
+		return r.RewrittenTitle
+	}
+	return r.Title
+}
+
+func itemTitle(item models.ReadingListItem) string {
+	if item.Blog.RewrittenTitle != "" {
+		return item.Blog.RewrittenTitle
+	}
+	return item.Blog.Title
+}
+
+// truncate cuts s, which may be styled, to width cells.
+func truncate(s string, width int) string {
+	return lipgloss.NewStyle().MaxWidth(width).Render(s)
+}
@@ -0,0 +1,174 @@
+package tui
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/hoanghai1803/apricot/internal/api/handlers"
+	"github.com/hoanghai1803/apricot/internal/models"
+	"github.com/hoanghai1803/apricot/internal/storage"
+)
+
+func newTestStore(t *testing.T) storage.Store {
+	t.Helper()
+	db, err := storage.OpenDatabase(":memory:")
+	if err != nil {
+		t.Fatalf("opening test db: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	if err := storage.RunMigrations(db); err != nil {
+		t.Fatalf("running migrations: %v", err)
+	}
+	return storage.NewSQLiteStore(db)
+}
+
+// press sends m a key press and runs the command it returns, feeding the
+// resulting messages back to it.
+func press(t *testing.T, m model, key string) model {
+	t.Helper()
+	var msg tea.KeyMsg
+	switch key {
+	case "enter":
+		msg = tea.KeyMsg{Type: tea.KeyEnter}
+	case "esc":
+		msg = tea.KeyMsg{Type: tea.KeyEsc}
+	case "tab":
+		msg = tea.KeyMsg{Type: tea.KeyTab}
+	default:
+		msg = tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(key)}
+	}
+	next, cmd := m.Update(msg)
+	return run(t, next.(model), cmd)
+}
+
+// run runs cmd and the commands that follow from it.
+func run(t *testing.T, m model, cmd tea.Cmd) model {
+	t.Helper()
+	for cmd != nil {
+		msg := cmd()
+		if batch, ok := msg.(tea.BatchMsg); ok {
+			for _, c := range batch {
+				m = run(t, m, c)
+			}
+			return m
+		}
+		var next tea.Model
+		next, cmd = m.Update(msg)
+		m = next.(model)
+	}
+	return m
+}
+
+func TestModel(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+	if err := store.SeedDefaults(ctx); err != nil {
+		t.Fatalf("SeedDefaults: %v", err)
+	}
+	sources, err := store.GetActiveSources(ctx)
+	if err != nil || len(sources) == 0 {
+		t.Fatalf("GetActiveSources() = %v, %v", sources, err)
+	}
+	blogID, err := store.UpsertBlog(ctx, &models.Blog{
+		SourceID:  sources[0].ID,
+		Title:     "Consensus in practice",
+		URL:       "https://test.com/raft",
+		FetchedAt: time.Now(),
+	})
+	if err != nil {
+		t.Fatalf("UpsertBlog: %v", err)
+	}
+	results, _ := json.Marshal([]handlers.DiscoverResult{{
+		ID:      blogID,
+		Title:   "Consensus in practice",
+		URL:     "https://test.com/raft",
+		Summary: "Raft elects a leader.",
+		Reason:  "You follow distributed systems.",
+	}})
+	if _, err := store.CreateSession(ctx, &models.DiscoverySession{ResultsJSON: string(results)}); err != nil {
+		t.Fatalf("CreateSession: %v", err)
+	}
+
+	m := run(t, newModel(ctx, store), newModel(ctx, store).Init())
+	if len(m.results) != 1 || len(m.items) != 0 {
+		t.Fatalf("loaded %d results and %d items, want 1 and 0", len(m.results), len(m.items))
+	}
+	if view := m.View(); !strings.Contains(view, "Consensus in practice") {
+		t.Errorf("list view is missing the result:\n%s", view)
+	}
+
+	m = press(t, m, "enter")
+	if view := m.View(); !strings.Contains(view, "Raft elects a leader.") || !strings.Contains(view, "You follow distributed systems.") {
+		t.Errorf("detail view is missing the summary and reason:\n%s", view)
+	}
+	m = press(t, m, "esc")
+
+	// Saving a result puts it on the reading list.
+	m = press(t, m, "s")
+	if len(m.items) != 1 || m.items[0].BlogID != blogID {
+		t.Fatalf("reading list after save = %+v, want the result", m.items)
+	}
+	m = press(t, m, "s")
+	if !strings.HasPrefix(m.status, "Error:") {
+		t.Errorf("status after saving twice = %q, want an error", m.status)
+	}
+
+	// Marking read and unread works on the reading list tab.
+	if err := store.UpsertSummary(ctx, &models.BlogSummary{BlogID: blogID, Summary: "A stored summary.", ModelUsed: "mock"}); err != nil {
+		t.Fatalf("UpsertSummary: %v", err)
+	}
+	m = press(t, m, "tab")
+	m = press(t, m, "R")
+	m = press(t, m, "enter")
+	if view := m.View(); !strings.Contains(view, "A stored summary.") {
+		t.Errorf("detail view is missing the item's summary:\n%s", view)
+	}
+	m = press(t, m, "esc")
+	m = press(t, m, "r")
+	item, err := store.GetReadingListItemByID(ctx, m.items[0].ID)
+	if err != nil || item.Status != "read" {
+		t.Fatalf("status after r = %v, %v; want read", item, err)
+	}
+	m = press(t, m, "u")
+	if m.items[0].Status != "unread" {
+		t.Errorf("status after u = %q, want unread", m.items[0].Status)
+	}
+
+	if _, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("q")}); cmd == nil || cmd() != tea.Quit() {
+		t.Error("q did not quit")
+	}
+}
+
+func TestScroll(t *testing.T) {
+	m := newModel(context.Background(), nil)
+	m.height = 8 // four rows
+	for range 10 {
+		m.results = append(m.results, handlers.DiscoverResult{Title: "Post"})
+	}
+
+	for range 6 {
+		next, _ := m.Update(tea.KeyMsg{Type: tea.KeyDown})
+		m = next.(model)
+	}
+	if m.cursor[tabDiscover] != 6 || m.offset[tabDiscover] != 3 {
+		t.Errorf("after 6 downs: cursor %d, offset %d; want 6, 3", m.cursor[tabDiscover], m.offset[tabDiscover])
+	}
+	next, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("G")})
+	m = next.(model)
+	if m.cursor[tabDiscover] != 9 {
+		t.Errorf("after G: cursor %d, want 9", m.cursor[tabDiscover])
+	}
+	next, _ = m.Update(tea.KeyMsg{Type: tea.KeyDown})
+	m = next.(model)
+	if m.cursor[tabDiscover] != 9 {
+		t.Errorf("down at the end: cursor %d, want 9", m.cursor[tabDiscover])
+	}
+	if lines := strings.Count(m.View(), "\n") + 1; lines != m.height {
+		t.Errorf("view is %d lines, want the height %d", lines, m.height)
+	}
+}
@@ -0,0 +1,283 @@
+// Package vault writes read posts as Markdown notes into a directory, such
+// as a folder in an Obsidian vault: one file per post, with YAML
+// frontmatter (title, source, URL, tags, dates) followed by its summary
+// and the reader's notes.
+//
+// Each note records the ID of its reading list item and a hash of what was
+// written, so exporting again updates the notes Apricot wrote but leaves
+// alone any that were edited by hand since.
+package vault
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Note is a read post to write as a note.
+type Note struct {
+	ID       int64 // reading list item ID
+	Title    string
+	URL      string
+	Source   string
+	Category string
+	Tags     []string
+	AddedAt  time.Time
+	ReadAt   *time.Time
+	Summary  string
+	Notes    string // Markdown
+}
+
+// Result counts what Export did.
+type Result struct {
+	Written   int `json:"written"`
+	Unchanged int `json:"unchanged"`
+
+	// Edited lists the notes left alone because they were changed by
+	// hand since Apricot wrote them, by file name.
+	Edited []string `json:"edited,omitempty"`
+}
+
+// maxName is the most runes of a title used in a file name.
+const maxName = 100
+
+var (
+	hashLine = regexp.MustCompile(`(?m)^apricot_hash: ([0-9a-f]+)\n`)
+
+	// unsafeName matches characters that file systems or Obsidian links
+	// don't allow in a note's name.
+	unsafeName = regexp.MustCompile(`[\\/:*?"<>|#^\[\]\x00-\x1f]+`)
+
+	// plainTag matches tags that need no quoting in YAML.
+	plainTag = regexp.MustCompile(`^[\p{L}\p{N}_/-]+$`)
+)
+
+// Export writes notes into dir, creating it if needed. A note already
+// exported, found by its item ID, is rewritten in place if it changed,
+// unless it was edited since; a new one is named after its title.
+func Export(dir string, notes []Note) (Result, error) {
+	var result Result
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return result, fmt.Errorf("creating vault directory: %w", err)
+	}
+	existing, err := scan(dir)
+	if err != nil {
+		return result, err
+	}
+	taken := make(map[string]bool)
+	for _, name := range existing {
+		taken[strings.ToLower(name)] = true
+	}
+
+	for _, n := range notes {
+		content := Render(n)
+		name, ok := existing[n.ID]
+		if ok {
+			old, err := os.ReadFile(filepath.Join(dir, name))
+			if err != nil {
+				return result, fmt.Errorf("reading %s: %w", name, err)
+			}
+			if bytes.Equal(old, content) {
+				result.Unchanged++
+				continue
+			}
+			if !untouched(old) {
+				result.Edited = append(result.Edited, name)
+				continue
+			}
+		} else {
+			name = fileName(n, taken)
+			taken[strings.ToLower(name)] = true
+		}
+		if err := write(dir, name, content); err != nil {
+			return result, err
+		}
+		result.Written++
+	}
+	return result, nil
+}
+
+// scan returns the notes in dir that Apricot wrote, by item ID.
+func scan(dir string) (map[int64]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("listing vault directory: %w", err)
+	}
+	notes := make(map[int64]string)
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), ".md") {
+			continue
+		}
+		id, err := noteID(filepath.Join(dir, e.Name()))
+		if err != nil {
+			return nil, err
+		}
+		if id != 0 {
+			notes[id] = e.Name()
+		}
+	}
+	return notes, nil
+}
+
+// noteID returns the item ID in the frontmatter of the note at path, or 0
+// if it has none.
+func noteID(path string) (int64, error) {
+	f, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("reading %s: %w", filepath.Base(path), err)
+	}
+	defer f.Close()
+
+	sc := bufio.NewScanner(f)
+	if !sc.Scan() || sc.Text() != "---" {
+		return 0, nil
+	}
+	for sc.Scan() && sc.Text() != "---" {
+		if v, ok := strings.CutPrefix(sc.Text(), "apricot_id: "); ok {
+			id, _ := strconv.ParseInt(v, 10, 64)
+			return id, nil
+		}
+	}
+	return 0, nil
+}
+
+// untouched reports whether content is as Apricot wrote it, by checking it
+// against its own hash.
+func untouched(content []byte) bool {
+	m := hashLine.FindSubmatchIndex(content)
+	if m == nil {
+		return false
+	}
+	want := string(content[m[2]:m[3]])
+	rest := append(bytes.Clone(content[:m[0]]), content[m[1]:]...)
+	return hash(rest) == want
+}
+
+// hash returns the short hash of content recorded in a note's frontmatter.
+func hash(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:8])
+}
+
+// fileName returns a file name for n that is not in taken (lower-cased,
+// since some file systems ignore case): its title, made safe, or with its
+// ID added if another note has the same title.
+func fileName(n Note, taken map[string]bool) string {
+	base := strings.TrimSpace(unsafeName.ReplaceAllString(n.Title, " "))
+	base = strings.Join(strings.Fields(strings.Trim(base, ".")), " ")
+	if r := []rune(base); len(r) > maxName {
+		base = strings.TrimSpace(string(r[:maxName]))
+	}
+	if base == "" {
+		base = fmt.Sprintf("Post %d", n.ID)
+	}
+	name := base + ".md"
+	if taken[strings.ToLower(name)] {
+		name = fmt.Sprintf("%s (%d).md", base, n.ID)
+	}
+	return name
+}
+
+// write writes content to dir/name through a hidden temporary file, so a
+// vault being synced never sees half a note.
+func write(dir, name string, content []byte) error {
+	tmp, err := os.CreateTemp(dir, ".apricot-*")
+	if err != nil {
+		return fmt.Errorf("creating %s: %w", name, err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(content); err != nil {
+		tmp.Close()
+		return fmt.Errorf("writing %s: %w", name, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("writing %s: %w", name, err)
+	}
+	if err := os.Chmod(tmp.Name(), 0o644); err != nil {
+		return fmt.Errorf("writing %s: %w", name, err)
+	}
+	if err := os.Rename(tmp.Name(), filepath.Join(dir, name)); err != nil {
+		return fmt.Errorf("saving %s: %w", name, err)
+	}
+	return nil
+}
+
+// Render returns n as a Markdown note with YAML frontmatter, ending with
+// the hash that Export checks for hand edits.
+func Render(n Note) []byte {
+	var b bytes.Buffer
+	b.WriteString("---\n")
+	fmt.Fprintf(&b, "title: %s\n", quote(n.Title))
+	if n.Source != "" {
+		fmt.Fprintf(&b, "source: %s\n", quote(n.Source))
+	}
+	fmt.Fprintf(&b, "url: %s\n", quote(n.URL))
+	if n.Category != "" {
+		fmt.Fprintf(&b, "category: %s\n", quote(n.Category))
+	}
+	if len(n.Tags) > 0 {
+		b.WriteString("tags:\n")
+		for _, tag := range n.Tags {
+			// Obsidian tags cannot contain spaces.
+			tag = strings.Join(strings.Fields(tag), "-")
+			if !plainTag.MatchString(tag) {
+				tag = quote(tag)
+			}
+			fmt.Fprintf(&b, "  - %s\n", tag)
+		}
+	}
+	fmt.Fprintf(&b, "added: %s\n", n.AddedAt.Local().Format(time.DateOnly))
+	if n.ReadAt != nil {
+		fmt.Fprintf(&b, "read: %s\n", n.ReadAt.Local().Format(time.DateOnly))
+	}
+	fmt.Fprintf(&b, "apricot_id: %d\n", n.ID)
+	b.WriteString("---\n\n")
+
+	fmt.Fprintf(&b, "# %s\n\n", strings.TrimSpace(n.Title))
+	if n.Source != "" {
+		fmt.Fprintf(&b, "[%s](<%s>)\n", escapeLinkText(n.Source), n.URL)
+	} else {
+		fmt.Fprintf(&b, "<%s>\n", n.URL)
+	}
+	if s := strings.TrimSpace(n.Summary); s != "" {
+		fmt.Fprintf(&b, "\n## Summary\n\n%s\n", s)
+	}
+	if s := strings.TrimSpace(n.Notes); s != "" {
+		fmt.Fprintf(&b, "\n## Notes\n\n%s\n", s)
+	}
+
+	// The hash goes last in the frontmatter, covering everything else.
+	content := b.Bytes()
+	end := bytes.Index(content, []byte("---\n\n"))
+	out := make([]byte, 0, len(content)+40)
+	out = append(out, content[:end]...)
+	out = append(out, "apricot_hash: "+hash(content)+"\n"...)
+	return append(out, content[end:]...)
+}
+
+// quote returns s as a YAML double-quoted string; JSON's escaping is valid
+// YAML.
+func quote(s string) string {
+	b, _ := json.Marshal(s)
+	return string(b)
+}
+
+// escapeLinkText escapes the characters that would end a Markdown link's
+// text.
+func escapeLinkText(s string) string {
+	return strings.NewReplacer(`[`, `\[`, `]`, `\]`).Replace(s)
+}
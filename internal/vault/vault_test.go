@@ -0,0 +1,91 @@
+package vault
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRender(t *testing.T) {
+	readAt := time.Date(2026, 10, 12, 9, 0, 0, 0, time.Local)
+	got := string(Render(Note{
+		ID:      42,
+		Title:   `Why "Postgres" wins`,
+		URL:     "https://a.example/pg",
+		Source:  "A Blog",
+		Tags:    []string{"databases", "machine learning", "c++"},
+		AddedAt: readAt.Add(-48 * time.Hour),
+		ReadAt:  &readAt,
+		Summary: "It is boring.",
+		Notes:   "> Boring is good.\n\nAgreed.",
+	}))
+
+	for _, want := range []string{
+		"---\ntitle: \"Why \\\"Postgres\\\" wins\"\nsource: \"A Blog\"\nurl: \"https://a.example/pg\"\n",
+		"tags:\n  - databases\n  - machine-learning\n  - \"c++\"\n",
+		"added: 2026-10-10\nread: 2026-10-12\napricot_id: 42\napricot_hash: ",
+		"---\n\n# Why \"Postgres\" wins\n\n[A Blog](<https://a.example/pg>)\n",
+		"\n## Summary\n\nIt is boring.\n\n## Notes\n\n> Boring is good.\n\nAgreed.\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("note is missing %q:\n%s", want, got)
+		}
+	}
+	if !untouched([]byte(got)) {
+		t.Error("untouched() = false for a freshly rendered note")
+	}
+	if untouched([]byte(strings.Replace(got, "Agreed.", "Agreed!", 1))) {
+		t.Error("untouched() = true for an edited note")
+	}
+}
+
+func TestExport(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "Reading")
+	added := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	notes := []Note{
+		{ID: 1, Title: "Go: the good parts?", URL: "https://a.example/1", AddedAt: added},
+		{ID: 2, Title: "Go  the good parts", URL: "https://a.example/2", AddedAt: added},
+		{ID: 3, Title: "Untouched", URL: "https://a.example/3", AddedAt: added},
+	}
+
+	res, err := Export(dir, notes)
+	if err != nil {
+		t.Fatalf("Export() error: %v", err)
+	}
+	if res.Written != 3 {
+		t.Errorf("first Export() = %+v, want 3 written", res)
+	}
+	for _, name := range []string{"Go the good parts.md", "Go the good parts (2).md", "Untouched.md"} {
+		if _, err := os.Stat(filepath.Join(dir, name)); err != nil {
+			t.Errorf("note %q: %v", name, err)
+		}
+	}
+
+	// Edit one note by hand and change the others' posts.
+	edited := filepath.Join(dir, "Go the good parts.md")
+	content, _ := os.ReadFile(edited)
+	os.WriteFile(edited, append(content, "\nMy own thoughts.\n"...), 0o644)
+	notes[0].Notes = "New notes."
+	notes[1].Notes = "New notes."
+	notes[1].Title = "Renamed"
+
+	res, err = Export(dir, notes)
+	if err != nil {
+		t.Fatalf("Export() error: %v", err)
+	}
+	if res.Written != 1 || res.Unchanged != 1 || len(res.Edited) != 1 || res.Edited[0] != "Go the good parts.md" {
+		t.Errorf("second Export() = %+v, want 1 written, 1 unchanged, and the edited note left alone", res)
+	}
+	if got, _ := os.ReadFile(edited); !strings.HasSuffix(string(got), "My own thoughts.\n") {
+		t.Error("the hand-edited note was overwritten")
+	}
+	got, _ := os.ReadFile(filepath.Join(dir, "Go the good parts (2).md"))
+	if !strings.Contains(string(got), "# Renamed") || !strings.Contains(string(got), "New notes.") {
+		t.Errorf("renamed post's note = %s, want it updated in place", got)
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 3 {
+		t.Errorf("vault has %d files, want 3 (no temporary files left)", len(entries))
+	}
+}
@@ -1,6 +1,7 @@
 import { useState } from 'react'
-import { ExternalLink, BookmarkPlus, BookmarkCheck, Clock } from 'lucide-react'
+import { ExternalLink, BookmarkPlus, BookmarkCheck, Clock, ThumbsUp, ThumbsDown } from 'lucide-react'
 import type { DiscoverResult } from '@/lib/types'
+import { api } from '@/lib/api'
 import { formatReadingTime } from '@/lib/reading'
 import { Card, CardHeader, CardTitle, CardDescription, CardContent, CardFooter } from '@/components/ui/card'
 import { Badge } from '@/components/ui/badge'
@@ -25,7 +26,19 @@ function formatDate(dateStr?: string): string {
 
 export function BlogCard({ blog, onAddToReadingList, isAdded = false }: BlogCardProps) {
   const [confirmOpen, setConfirmOpen] = useState(false)
+  const [rating, setRating] = useState(0)
   const readingTime = formatReadingTime(blog.reading_time_minutes)
+  const displayTitle = blog.rewritten_title || blog.title
+
+  async function handleRate(value: number) {
+    const next = rating === value ? 0 : value
+    try {
+      await api.put(`/api/blogs/${blog.id}/feedback`, { rating: next })
+      setRating(next)
+    } catch {
+      // Feedback is best-effort; leave the current rating in place.
+    }
+  }
 
   return (
     <>
@@ -47,7 +60,9 @@ export function BlogCard({ blog, onAddToReadingList, isAdded = false }: BlogCard
               </span>
             )}
           </div>
-          <CardTitle>{blog.title}</CardTitle>
+          <CardTitle title={displayTitle !== blog.title ? `Original: ${blog.title}` : undefined}>
+            {displayTitle}
+          </CardTitle>
           <CardDescription className="sr-only">
             From {blog.source}
           </CardDescription>
@@ -87,6 +102,26 @@ export function BlogCard({ blog, onAddToReadingList, isAdded = false }: BlogCard
               </>
             )}
           </Button>
+          <div className="ml-auto flex gap-1">
+            <Button
+              variant={rating === 1 ? 'secondary' : 'ghost'}
+              size="sm"
+              onClick={() => void handleRate(1)}
+              aria-label="More like this"
+              title="More like this"
+            >
+              <ThumbsUp className="size-4" />
+            </Button>
+            <Button
+              variant={rating === -1 ? 'secondary' : 'ghost'}
+              size="sm"
+              onClick={() => void handleRate(-1)}
+              aria-label="Less like this"
+              title="Less like this"
+            >
+              <ThumbsDown className="size-4" />
+            </Button>
+          </div>
         </CardFooter>
       </Card>
 
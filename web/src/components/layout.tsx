@@ -10,7 +10,7 @@ import { Separator } from '@/components/ui/separator'
 import { Badge } from '@/components/ui/badge'
 import { type Theme, getStoredTheme, setStoredTheme, applyTheme } from '@/lib/theme'
 import { api } from '@/lib/api'
-import type { Blog } from '@/lib/types'
+import type { SearchPage, SearchResult } from '@/lib/types'
 import { ConfirmDialog } from '@/components/confirm-dialog'
 
 const navItems = [
@@ -58,22 +58,41 @@ function removeFromHistory(query: string) {
 
 // --- Search result overlay item ---
 
+// Highlighted renders search text whose matched terms the server wrapped in
+// <mark> tags. The text is split on the tags rather than parsed as HTML, so
+// markup in article text is shown literally.
+function Highlighted({ text }: { text: string }) {
+  return (
+    <>
+      {text.split(/<\/?mark>/).map((part, i) =>
+        i % 2 === 1 ? (
+          <mark key={i} className="rounded-sm bg-primary/20 px-0.5 text-foreground">
+            {part}
+          </mark>
+        ) : (
+          part
+        )
+      )}
+    </>
+  )
+}
+
 function SearchResultItem({
   blog,
   onAdd,
   isAdded,
 }: {
-  blog: Blog
+  blog: SearchResult
   onAdd: (blogId: number) => void
   isAdded: boolean
 }) {
   const [confirmOpen, setConfirmOpen] = useState(false)
 
-  const snippet = blog.description
-    ? blog.description.length > 200
-      ? blog.description.slice(0, 200) + '...'
-      : blog.description
-    : null
+  // The snippet comes from whichever field matched best; when that is the
+  // title, show the description instead of repeating it.
+  const snippet = blog.snippet && blog.snippet !== blog.title_highlight
+    ? blog.snippet
+    : blog.description
 
   return (
     <>
@@ -95,11 +114,11 @@ function SearchResultItem({
             rel="noopener noreferrer"
             className="mt-1 block text-sm font-medium hover:text-primary hover:underline underline-offset-2 transition-colors"
           >
-            {blog.title}
+            <Highlighted text={blog.title_highlight || blog.title} />
           </a>
           {snippet && (
             <p className="mt-1 text-xs leading-relaxed text-muted-foreground line-clamp-2">
-              {snippet}
+              <Highlighted text={snippet} />
             </p>
           )}
         </div>
@@ -156,7 +175,7 @@ export function Layout() {
   // Results overlay state (separate from input dropdown)
   const [resultsOpen, setResultsOpen] = useState(false)
   const [resultsQuery, setResultsQuery] = useState('')
-  const [searchResults, setSearchResults] = useState<Blog[]>([])
+  const [searchResults, setSearchResults] = useState<SearchResult[]>([])
   const [searchLoading, setSearchLoading] = useState(false)
   const [addedIds, setAddedIds] = useState<Set<number>>(new Set())
 
@@ -226,8 +245,8 @@ export function Layout() {
     setSearchLoading(true)
 
     try {
-      const data = await api.get<Blog[]>(`/api/search?q=${encodeURIComponent(trimmed)}&limit=20`)
-      setSearchResults(data)
+      const data = await api.get<SearchPage>(`/api/search?q=${encodeURIComponent(trimmed)}&limit=20`)
+      setSearchResults(data.results)
     } catch {
       setSearchResults([])
     } finally {
@@ -1,7 +1,7 @@
 import { useState, useRef } from 'react'
 import { useNavigate } from 'react-router-dom'
-import { ExternalLink, BookOpen, CheckCircle, RotateCcw, Trash2, Plus, X, Tag, Clock } from 'lucide-react'
-import type { ReadingListItem } from '@/lib/types'
+import { AlarmClock, Archive, ArrowUpToLine, ExternalLink, BookOpen, CheckCircle, RotateCcw, Trash2, Plus, X, Tag, Clock, Share2, Link2Off } from 'lucide-react'
+import type { ReadingListItem, ShareLink } from '@/lib/types'
 import { cn } from '@/lib/utils'
 import { formatReadingTime } from '@/lib/reading'
 import { Card, CardHeader, CardTitle, CardContent, CardFooter } from '@/components/ui/card'
@@ -14,6 +14,8 @@ interface ReadingItemProps {
   item: ReadingListItem
   onStatusChange: (id: number, status: string) => void
   onRemove: (id: number) => void
+  onSnooze: (id: number, until: Date) => void
+  onMoveToTop: (id: number) => void
   onTagsChange: () => void
   allTags: string[]
 }
@@ -40,18 +42,40 @@ const statusLabels: Record<string, { action: string; description: string }> = {
     action: 'Back to Unread',
     description: 'Move this post back to your "Unread" list?',
   },
+  archived: {
+    action: 'Archive',
+    description: 'Move this post to your archive? It stays in your reading history.',
+  },
 }
 
-export function ReadingItem({ item, onStatusChange, onRemove, onTagsChange, allTags }: ReadingItemProps) {
+// snoozeOptions are the choices offered for hiding an item until later.
+const snoozeOptions = [
+  { label: 'Tomorrow', days: 1 },
+  { label: 'Next week', days: 7 },
+  { label: 'Next month', days: 30 },
+]
+
+function snoozeUntil(days: number): Date {
+  const until = new Date()
+  until.setDate(until.getDate() + days)
+  until.setHours(8, 0, 0, 0)
+  return until
+}
+
+export function ReadingItem({ item, onStatusChange, onRemove, onSnooze, onMoveToTop, onTagsChange, allTags }: ReadingItemProps) {
   const navigate = useNavigate()
   const [statusConfirm, setStatusConfirm] = useState<string | null>(null)
   const [removeConfirm, setRemoveConfirm] = useState(false)
+  const [showSnooze, setShowSnooze] = useState(false)
   const [showTagInput, setShowTagInput] = useState(false)
   const [tagInput, setTagInput] = useState('')
   const [suggestionIndex, setSuggestionIndex] = useState(-1)
+  const [shareLinks, setShareLinks] = useState<ShareLink[] | null>(null)
+  const [copied, setCopied] = useState(false)
   const inputRef = useRef<HTMLInputElement>(null)
 
   const title = item.blog?.title ?? `Blog #${item.blog_id}`
+  const displayTitle = item.blog?.rewritten_title || title
   const url = item.blog?.url
   const source = item.blog?.source
   const readingTime = formatReadingTime(item.blog?.reading_time_minutes)
@@ -83,6 +107,33 @@ export function ReadingItem({ item, onStatusChange, onRemove, onTagsChange, allT
     }
   }
 
+  // Shares the item: copies a new public link to the clipboard and lists
+  // the item's live links so they can be revoked.
+  async function handleShare() {
+    try {
+      const link = await api.post<ShareLink>(`/api/reading-list/${item.id}/share`)
+      if (link.url) {
+        await navigator.clipboard?.writeText(link.url).catch(() => {})
+        setCopied(true)
+        setTimeout(() => setCopied(false), 2000)
+      }
+      const links = await api.get<ShareLink[]>(`/api/reading-list/${item.id}/share`)
+      setShareLinks(links.filter((l) => !l.revoked_at))
+    } catch {
+      // Silently fail
+    }
+  }
+
+  async function handleRevokeShares() {
+    if (!shareLinks) return
+    try {
+      await Promise.all(shareLinks.map((l) => api.del(`/api/reading-list/${item.id}/share/${l.id}`)))
+      setShareLinks(null)
+    } catch {
+      // Silently fail
+    }
+  }
+
   async function handleRemoveTag(tagName: string) {
     try {
       await api.del(`/api/reading-list/${item.id}/tags/${encodeURIComponent(tagName)}`)
@@ -137,7 +188,9 @@ export function ReadingItem({ item, onStatusChange, onRemove, onTagsChange, allT
               Added {formatDate(item.added_at)}
             </span>
           </div>
-          <CardTitle>{title}</CardTitle>
+          <CardTitle title={displayTitle !== title ? `Original: ${title}` : undefined}>
+            {displayTitle}
+          </CardTitle>
         </CardHeader>
 
         {item.summary && (
@@ -247,11 +300,51 @@ export function ReadingItem({ item, onStatusChange, onRemove, onTagsChange, allT
             </>
           )}
           {item.status === 'read' && (
-            <Button variant="outline" size="sm" onClick={() => setStatusConfirm('reading')}>
+            <>
+              <Button variant="outline" size="sm" onClick={() => setStatusConfirm('reading')}>
+                <RotateCcw className="size-4" />
+                Back to Reading
+              </Button>
+              <Button variant="outline" size="sm" onClick={() => setStatusConfirm('archived')}>
+                <Archive className="size-4" />
+                Archive
+              </Button>
+            </>
+          )}
+          {item.status === 'archived' && (
+            <Button variant="outline" size="sm" onClick={() => setStatusConfirm('read')}>
               <RotateCcw className="size-4" />
-              Back to Reading
+              Unarchive
+            </Button>
+          )}
+
+          {item.status === 'unread' && (
+            <Button variant="outline" size="sm" onClick={() => onMoveToTop(item.id)}>
+              <ArrowUpToLine className="size-4" />
+              Up Next
             </Button>
           )}
+          {(item.status === 'unread' || item.status === 'reading') &&
+            (showSnooze ? (
+              snoozeOptions.map((option) => (
+                <Button
+                  key={option.days}
+                  variant="outline"
+                  size="sm"
+                  onClick={() => {
+                    setShowSnooze(false)
+                    onSnooze(item.id, snoozeUntil(option.days))
+                  }}
+                >
+                  {option.label}
+                </Button>
+              ))
+            ) : (
+              <Button variant="outline" size="sm" onClick={() => setShowSnooze(true)}>
+                <AlarmClock className="size-4" />
+                Snooze
+              </Button>
+            ))}
 
           {url && (
             <Button variant="outline" size="sm" asChild>
@@ -262,6 +355,17 @@ export function ReadingItem({ item, onStatusChange, onRemove, onTagsChange, allT
             </Button>
           )}
 
+          <Button variant="outline" size="sm" onClick={() => void handleShare()}>
+            <Share2 className="size-4" />
+            {copied ? 'Link Copied' : 'Share'}
+          </Button>
+          {shareLinks && shareLinks.length > 0 && (
+            <Button variant="outline" size="sm" onClick={() => void handleRevokeShares()}>
+              <Link2Off className="size-4" />
+              Stop Sharing ({shareLinks.length})
+            </Button>
+          )}
+
           <Button
             variant="destructive"
             size="sm"
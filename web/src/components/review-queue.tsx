@@ -0,0 +1,69 @@
+import { useState, useEffect } from 'react'
+import { useNavigate } from 'react-router-dom'
+import { BookOpen, Check, RefreshCw } from 'lucide-react'
+import type { ReviewItem } from '@/lib/types'
+import { api } from '@/lib/api'
+import { Button } from '@/components/ui/button'
+
+const reviewLabels = ['', 'one week', 'one month', 'three months']
+
+// ReviewQueue lists read posts due for spaced-repetition review. It renders
+// nothing when the queue is empty or resurfacing is turned off.
+export function ReviewQueue() {
+  const navigate = useNavigate()
+  const [queue, setQueue] = useState<ReviewItem[]>([])
+
+  useEffect(() => {
+    api
+      .get<ReviewItem[]>('/api/review')
+      .then(setQueue)
+      .catch(() => setQueue([]))
+  }, [])
+
+  async function handleDone(id: number) {
+    setQueue((prev) => prev.filter((item) => item.id !== id))
+    try {
+      await api.post(`/api/review/${id}`)
+    } catch {
+      // Leave it off the list; it comes back on the next visit.
+    }
+  }
+
+  if (queue.length === 0) return null
+
+  return (
+    <div className="space-y-3 rounded-lg border border-primary/30 bg-primary/5 p-4">
+      <div className="flex items-center gap-2">
+        <RefreshCw className="size-4 text-primary" />
+        <h2 className="text-sm font-semibold">Due for review</h2>
+        <span className="text-xs text-muted-foreground">
+          Favorite posts worth revisiting
+        </span>
+      </div>
+      <ul className="space-y-2">
+        {queue.map((item) => (
+          <li key={item.id} className="flex items-center justify-between gap-4">
+            <div className="min-w-0">
+              <p className="truncate text-sm font-medium">
+                {item.blog?.rewritten_title || item.blog?.title || `Blog #${item.blog_id}`}
+              </p>
+              <p className="text-xs text-muted-foreground">
+                Read {reviewLabels[item.review] ?? ''} ago
+              </p>
+            </div>
+            <div className="flex shrink-0 gap-2">
+              <Button variant="outline" size="sm" onClick={() => navigate(`/read/${item.id}`)}>
+                <BookOpen className="size-4" />
+                Revisit
+              </Button>
+              <Button variant="ghost" size="sm" onClick={() => void handleDone(item.id)}>
+                <Check className="size-4" />
+                Done
+              </Button>
+            </div>
+          </li>
+        ))}
+      </ul>
+    </div>
+  )
+}
@@ -1,3 +1,5 @@
+import type { Job } from './types'
+
 const BASE_URL = ''
 
 async function request<T>(path: string, options?: RequestInit): Promise<T> {
@@ -16,6 +18,10 @@ async function request<T>(path: string, options?: RequestInit): Promise<T> {
   return res.json()
 }
 
+function ifMatch(version: number): HeadersInit {
+  return { 'Content-Type': 'application/json', 'If-Match': `"${version}"` }
+}
+
 export const api = {
   get: <T>(path: string) => request<T>(path),
 
@@ -31,12 +37,35 @@ export const api = {
       body: JSON.stringify(body),
     }),
 
-  patch: <T>(path: string, body: unknown) =>
+  // Passing the version a record was loaded at makes the server reject the
+  // edit if the record has changed since, e.g. in another tab.
+  patch: <T>(path: string, body: unknown, version?: number) =>
     request<T>(path, {
       method: 'PATCH',
       body: JSON.stringify(body),
+      ...(version ? { headers: ifMatch(version) } : {}),
+    }),
+
+  upload: <T>(path: string, body: Blob) =>
+    request<T>(path, {
+      method: 'POST',
+      body,
     }),
 
   del: (path: string) =>
     request<void>(path, { method: 'DELETE' }),
 }
+
+// runJob starts a background job with a POST to path, then polls it until it
+// finishes and returns its result, or throws its error.
+export async function runJob<T>(path: string, body?: unknown, intervalMs = 1500): Promise<T> {
+  let job = await api.post<Job<T>>(path, body)
+  while (job.status === 'queued' || job.status === 'running') {
+    await new Promise((resolve) => setTimeout(resolve, intervalMs))
+    job = await api.get<Job<T>>(`/api/jobs/${job.id}`)
+  }
+  if (job.status === 'failed') {
+    throw new Error(job.error || 'Job failed')
+  }
+  return job.result as T
+}
@@ -9,6 +9,20 @@ export interface BlogSource {
   last_fetch_ok: boolean
   last_error?: string
   created_at: string
+  version: number
+}
+
+export interface SourceScore {
+  source_id: number
+  name: string
+  recommended: number
+  saved: number
+  read: number
+  thumbs_up: number
+  thumbs_down: number
+  save_rate: number
+  completion_rate: number
+  score: number
 }
 
 export interface Blog {
@@ -23,20 +37,91 @@ export interface Blog {
   fetched_at: string
   content_hash?: string
   reading_time_minutes?: number
+  difficulty?: string
+  rewritten_title?: string
   created_at: string
 }
 
+// A post's article as sanitized HTML, from GET /api/blogs/{id}/content.
+export interface BlogContent {
+  blog_id: number
+  title: string
+  url: string
+  html: string
+}
+
+export interface SearchResult extends Blog {
+  title_highlight: string
+  snippet: string
+}
+
 export interface ReadingListItem {
   id: number
   blog_id: number
   blog?: Blog
   summary?: string
-  status: 'unread' | 'reading' | 'read'
+  category?: string
+  status: 'unread' | 'reading' | 'read' | 'archived'
   progress: number
   notes?: string
   tags: string[]
   added_at: string
   read_at?: string
+  archived_at?: string
+  snoozed_until?: string
+  position?: number
+  scroll_position?: number // fraction of the way down the reader, 0-1
+  last_read_at?: string
+  version: number
+}
+
+export interface ShareLink {
+  id: number
+  token: string
+  url?: string
+  reading_list_id: number
+  include_notes: boolean
+  created_at: string
+  revoked_at?: string
+}
+
+export interface ReviewItem extends ReadingListItem {
+  review: number
+  due_at: string
+}
+
+export interface Tag {
+  name: string
+  color?: string
+  description?: string
+  item_count: number
+}
+
+export interface ReadingListPage {
+  items: ReadingListItem[]
+  total: number
+  limit: number
+  offset: number
+}
+
+export interface BatchAddResult {
+  url: string
+  status: 'added' | 'exists' | 'failed'
+  blog_id?: number
+  error?: string
+}
+
+export interface BatchAddResponse {
+  results: BatchAddResult[]
+  added: number
+  failed: number
+}
+
+export interface SearchPage {
+  results: SearchResult[]
+  total: number
+  limit: number
+  offset: number
 }
 
 export interface DiscoverResult {
@@ -48,6 +133,9 @@ export interface DiscoverResult {
   reading_time_minutes?: number
   summary: string
   reason: string
+  difficulty?: string
+  category?: string
+  rewritten_title?: string
 }
 
 export interface FailedFeed {
@@ -60,15 +148,68 @@ export interface DiscoverResponse {
   failed_feeds: FailedFeed[]
   session_id: number
   created_at: string
+  partial?: boolean
+  fallback?: boolean
+}
+
+export interface Topic {
+  topic: string
+  importance: number
 }
 
 export interface Preferences {
-  topics?: string
+  topics?: string | Topic[]
   selected_sources?: number[]
-  feed_mode?: string
-  max_articles_per_feed?: number
-  lookback_days?: number
-  max_results?: number
-  timezone?: string
   [key: string]: unknown
 }
+
+export interface Settings {
+  feed_mode: 'recent_posts' | 'time_range'
+  max_articles_per_feed: number
+  lookback_days: number
+  max_results: number
+  max_reading_minutes: number
+  timezone: string
+  rewrite_titles: boolean
+  weight_by_source_score: boolean
+  resurface: boolean
+  discover_schedule: string
+  // Configured notification targets by kind; read-only.
+  notification_targets: Record<string, number>
+}
+
+export interface ArchiveImportResult {
+  dry_run?: boolean
+  on_conflict: 'skip' | 'overwrite'
+  sources_added: number
+  sources_overwritten: number
+  sources_skipped: number
+  blogs_added: number
+  blogs_overwritten: number
+  blogs_skipped: number
+  items_added: number
+  items_merged: number
+  items_overwritten: number
+  items_skipped: number
+  tags_added: number
+  tags_overwritten: number
+  tags_skipped: number
+  preferences_added: number
+  preferences_overwritten: number
+  preferences_skipped: number
+}
+
+export interface Job<T = unknown> {
+  id: number
+  kind: string
+  payload?: unknown
+  status: 'queued' | 'running' | 'succeeded' | 'failed'
+  attempts: number
+  max_attempts: number
+  result?: T
+  error?: string
+  run_after: string
+  created_at: string
+  started_at?: string
+  finished_at?: string
+}
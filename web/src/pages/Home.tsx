@@ -1,8 +1,8 @@
 import { useState, useEffect, useMemo } from 'react'
 import { useBlocker } from 'react-router-dom'
-import { Sparkles, Shuffle, AlertCircle, ChevronDown, ChevronUp, AlertTriangle } from 'lucide-react'
-import type { DiscoverResult, DiscoverResponse, FailedFeed, ReadingListItem, Preferences } from '@/lib/types'
-import { api } from '@/lib/api'
+import { Sparkles, Shuffle, AlertCircle, ChevronDown, ChevronUp, AlertTriangle, RefreshCw, BookmarkPlus } from 'lucide-react'
+import type { DiscoverResult, DiscoverResponse, FailedFeed, ReadingListPage, Settings } from '@/lib/types'
+import { api, runJob } from '@/lib/api'
 import { Button } from '@/components/ui/button'
 import { Skeleton } from '@/components/ui/skeleton'
 import { Tabs, TabsList, TabsTrigger, TabsContent } from '@/components/ui/tabs'
@@ -23,15 +23,34 @@ function formatLastDiscovered(dateStr: string, timezone: string): string {
   })
 }
 
+// readingListBlogIds returns the posts on the reading list, snoozed ones
+// included, paging through the list until it has them all.
+async function readingListBlogIds(): Promise<Set<number>> {
+  const ids = new Set<number>()
+  let offset = 0
+  for (;;) {
+    const page = await api.get<ReadingListPage>(
+      `/api/reading-list?snoozed=include&limit=200&offset=${offset}`
+    )
+    page.items.forEach((item) => ids.add(item.blog_id))
+    offset += page.items.length
+    if (page.items.length === 0 || offset >= page.total) return ids
+  }
+}
+
 export function Home() {
   const [results, setResults] = useState<DiscoverResult[]>([])
   const [failedFeeds, setFailedFeeds] = useState<FailedFeed[]>([])
+  const [partial, setPartial] = useState(false)
+  const [fallback, setFallback] = useState(false)
+  const [sessionId, setSessionId] = useState(0)
   const [filter, setFilter] = useState<'all' | 'new' | 'added'>('all')
   const [failedExpanded, setFailedExpanded] = useState(false)
   const [loading, setLoading] = useState(false)
   const [loadingLatest, setLoadingLatest] = useState(true)
   const [error, setError] = useState<string | null>(null)
   const [addedIds, setAddedIds] = useState<Set<number>>(new Set())
+  const [savingAll, setSavingAll] = useState(false)
   const [hasSearched, setHasSearched] = useState(false)
   const [lastDiscoveredAt, setLastDiscoveredAt] = useState('')
   const [timezone, setTimezone] = useState('UTC')
@@ -47,25 +66,27 @@ export function Home() {
   useEffect(() => {
     async function loadLatest() {
       try {
-        const [discoverData, readingList, prefs] = await Promise.all([
+        const [discoverData, readingList, settings] = await Promise.all([
           api.get<DiscoverResponse>('/api/discover/latest').catch(() => null),
-          api.get<ReadingListItem[]>('/api/reading-list').catch((): ReadingListItem[] => []),
-          api.get<Preferences>('/api/preferences').catch(() => null),
+          readingListBlogIds().catch(() => null),
+          api.get<Settings>('/api/settings').catch(() => null),
         ])
 
-        if (prefs?.timezone) {
-          setTimezone(prefs.timezone)
+        if (settings?.timezone) {
+          setTimezone(settings.timezone)
         }
 
         if (discoverData?.results && discoverData.results.length > 0) {
           setResults(discoverData.results)
           setFailedFeeds(discoverData.failed_feeds ?? [])
+          setFallback(discoverData.fallback ?? false)
+          setSessionId(discoverData.session_id)
           setLastDiscoveredAt(discoverData.created_at)
           setHasSearched(true)
         }
 
-        if (readingList.length > 0) {
-          setAddedIds(new Set(readingList.map((item) => item.blog_id)))
+        if (readingList && readingList.size > 0) {
+          setAddedIds(readingList)
         }
       } finally {
         setLoadingLatest(false)
@@ -76,17 +97,32 @@ export function Home() {
   }, [])
 
   async function handleDiscover(mode: 'normal' | 'serendipity' = 'normal') {
+    setAddedIds(new Set())
+    await runDiscovery('/api/discover', { mode })
+  }
+
+  // Retrying the failed feeds merges what they find into the current results,
+  // so the reading list marks still apply.
+  async function handleRetryFailed() {
+    await runDiscovery(`/api/discover/sessions/${sessionId}/retry-failed`)
+  }
+
+  async function runDiscovery(path: string, body?: unknown) {
     setLoading(true)
     setError(null)
     setResults([])
     setFailedFeeds([])
-    setAddedIds(new Set())
+    setPartial(false)
+    setFallback(false)
     setFailedExpanded(false)
 
     try {
-      const data = await api.post<DiscoverResponse>('/api/discover', { mode })
+      const data = await runJob<DiscoverResponse>(path, body)
       setResults(data.results)
       setFailedFeeds(data.failed_feeds ?? [])
+      setPartial(data.partial ?? false)
+      setFallback(data.fallback ?? false)
+      setSessionId(data.session_id)
       setLastDiscoveredAt(new Date().toISOString())
       setHasSearched(true)
     } catch (err) {
@@ -107,6 +143,20 @@ export function Home() {
     }
   }
 
+  // Saves every result of the session in one go; the server skips those
+  // already on the reading list.
+  async function handleSaveAll() {
+    setSavingAll(true)
+    try {
+      await api.post(`/api/discover/sessions/${sessionId}/save-all`)
+      setAddedIds((prev) => new Set([...prev, ...results.map((b) => b.id)]))
+    } catch (err) {
+      setError(err instanceof Error ? err.message : 'Failed to save all results')
+    } finally {
+      setSavingAll(false)
+    }
+  }
+
   if (loadingLatest) {
     return (
       <div className="space-y-8">
@@ -195,20 +245,28 @@ export function Home() {
 
       {!loading && results.length > 0 && (
         <Tabs value={filter} onValueChange={(v) => setFilter(v as typeof filter)}>
-          <TabsList>
-            {([
-              ['all', 'All'],
-              ['new', 'New'],
-              ['added', 'Added'],
-            ] as const).map(([value, label]) => (
-              <TabsTrigger key={value} value={value} className="gap-2">
-                {label}
-                <Badge variant="secondary" className="ml-1 px-1.5 py-0 text-[10px]">
-                  {filterCounts[value]}
-                </Badge>
-              </TabsTrigger>
-            ))}
-          </TabsList>
+          <div className="flex flex-wrap items-center justify-between gap-3">
+            <TabsList>
+              {([
+                ['all', 'All'],
+                ['new', 'New'],
+                ['added', 'Added'],
+              ] as const).map(([value, label]) => (
+                <TabsTrigger key={value} value={value} className="gap-2">
+                  {label}
+                  <Badge variant="secondary" className="ml-1 px-1.5 py-0 text-[10px]">
+                    {filterCounts[value]}
+                  </Badge>
+                </TabsTrigger>
+              ))}
+            </TabsList>
+            {sessionId > 0 && filterCounts.new > 0 && (
+              <Button variant="outline" size="sm" disabled={savingAll} onClick={() => void handleSaveAll()}>
+                <BookmarkPlus className="size-4" />
+                {savingAll ? 'Saving...' : `Save all ${filterCounts.new}`}
+              </Button>
+            )}
+          </div>
 
           {(['all', 'new', 'added'] as const).map((tab) => {
             const filtered =
@@ -242,6 +300,20 @@ export function Home() {
         </Tabs>
       )}
 
+      {!loading && partial && (
+        <div className="mx-auto flex max-w-2xl items-center gap-2 rounded-lg border border-yellow-500/50 bg-yellow-500/10 p-4 text-sm font-medium text-yellow-700 dark:text-yellow-400">
+          <AlertTriangle className="size-4" />
+          Discovery ran out of time, so these are the results it had so far
+        </div>
+      )}
+
+      {!loading && fallback && (
+        <div className="mx-auto flex max-w-2xl items-center gap-2 rounded-lg border border-yellow-500/50 bg-yellow-500/10 p-4 text-sm font-medium text-yellow-700 dark:text-yellow-400">
+          <AlertTriangle className="size-4" />
+          The AI could not rank these posts, so they are ranked by your topics and recency
+        </div>
+      )}
+
       {!loading && failedFeeds.length > 0 && (
         <div className="mx-auto max-w-2xl rounded-lg border border-yellow-500/50 bg-yellow-500/10 p-4">
           <button
@@ -261,11 +333,19 @@ export function Home() {
           </button>
 
           {failedExpanded && (
-            <ul className="mt-3 space-y-1 pl-6 text-sm text-yellow-700 dark:text-yellow-400 list-disc">
-              {failedFeeds.map((feed) => (
-                <li key={feed.source}>{feed.source}</li>
-              ))}
-            </ul>
+            <>
+              <ul className="mt-3 space-y-1 pl-6 text-sm text-yellow-700 dark:text-yellow-400 list-disc">
+                {failedFeeds.map((feed) => (
+                  <li key={feed.source}>{feed.source}</li>
+                ))}
+              </ul>
+              {sessionId > 0 && (
+                <Button variant="outline" size="sm" className="mt-3" onClick={() => void handleRetryFailed()}>
+                  <RefreshCw className="size-4" />
+                  Retry these sources
+                </Button>
+              )}
+            </>
           )}
         </div>
       )}
@@ -1,9 +1,8 @@
-import { useState, useEffect } from 'react'
-import { Save, Loader2, AlertCircle, Info, Heart, HeartCrack } from 'lucide-react'
-import type { BlogSource, Preferences as PreferencesType } from '@/lib/types'
+import { useState, useEffect, useRef } from 'react'
+import { Save, Loader2, AlertCircle, Info, Heart, HeartCrack, Plus, X, Download, Upload } from 'lucide-react'
+import type { ArchiveImportResult, BlogSource, SourceScore, Topic, Preferences as PreferencesType, Settings } from '@/lib/types'
 import { api } from '@/lib/api'
 import { Button } from '@/components/ui/button'
-import { Textarea } from '@/components/ui/textarea'
 import { Switch } from '@/components/ui/switch'
 import { Skeleton } from '@/components/ui/skeleton'
 import { Separator } from '@/components/ui/separator'
@@ -11,6 +10,27 @@ import { Toast } from '@/components/toast'
 
 type FeedMode = 'recent_posts' | 'time_range'
 
+const IMPORTANCE_LABELS: Record<number, string> = {
+  1: 'Nice to have',
+  2: 'Somewhat interested',
+  3: 'Interested',
+  4: 'Very interested',
+  5: 'Must have',
+}
+
+const DEFAULT_IMPORTANCE = 3
+
+// Topics saved before weighting are a free-form string; split it into
+// topics of default importance.
+function toTopics(topics: string | Topic[]): Topic[] {
+  if (Array.isArray(topics)) return topics
+  return topics
+    .split(/[,\n]/)
+    .map((t) => t.trim())
+    .filter(Boolean)
+    .map((topic) => ({ topic, importance: DEFAULT_IMPORTANCE }))
+}
+
 function sourceHealthTooltip(source: BlogSource): string {
   if (!source.last_fetch_at) return 'Healthy — not yet fetched'
   const date = new Date(source.last_fetch_at).toLocaleString('en-US', {
@@ -21,19 +41,51 @@ function sourceHealthTooltip(source: BlogSource): string {
   return `Failed — last attempt ${date}\nLikely due to network issues`
 }
 
+function sourceScoreTooltip(score: SourceScore): string {
+  const pct = (n: number) => `${Math.round(n * 100)}%`
+  return [
+    `Saved ${score.saved} of ${score.recommended} recommended (${pct(score.save_rate)})`,
+    `Finished ${score.read} of ${score.saved} saved (${pct(score.completion_rate)})`,
+    `Thumbs: ${score.thumbs_up} up, ${score.thumbs_down} down`,
+    'Last 90 days',
+  ].join('\n')
+}
+
+function importSummary(r: ArchiveImportResult): string {
+  const items = r.items_added + r.items_merged
+  const overwritten = r.sources_overwritten + r.blogs_overwritten + r.items_overwritten +
+    r.tags_overwritten + r.preferences_overwritten
+  const skipped = r.sources_skipped + r.blogs_skipped + r.items_skipped +
+    r.tags_skipped + r.preferences_skipped
+  const parts = [`Imported ${items} reading list items and ${r.blogs_added} posts`]
+  if (overwritten > 0) parts.push(`${overwritten} records overwritten`)
+  if (skipped > 0) parts.push(`${skipped} already present`)
+  return parts.join(', ') + '.'
+}
+
 export function Preferences() {
-  const [topics, setTopics] = useState('')
+  const [topics, setTopics] = useState<Topic[]>([])
   const [sources, setSources] = useState<BlogSource[]>([])
   const [selectedSources, setSelectedSources] = useState<Set<number>>(new Set())
   const [feedMode, setFeedMode] = useState<FeedMode>('recent_posts')
   const [maxArticles, setMaxArticles] = useState(10)
   const [lookbackDays, setLookbackDays] = useState(7)
   const [maxResults, setMaxResults] = useState(10)
+  const [maxReadingMinutes, setMaxReadingMinutes] = useState(0)
   const [timezone, setTimezone] = useState('UTC')
+  const [rewriteTitles, setRewriteTitles] = useState(false)
+  const [weightBySourceScore, setWeightBySourceScore] = useState(false)
+  const [resurface, setResurface] = useState(false)
+  const [discoverSchedule, setDiscoverSchedule] = useState('')
+  const [scores, setScores] = useState<Map<number, SourceScore>>(new Map())
   const [loading, setLoading] = useState(true)
   const [saving, setSaving] = useState(false)
   const [error, setError] = useState<string | null>(null)
   const [success, setSuccess] = useState(false)
+  const [overwriteOnImport, setOverwriteOnImport] = useState(false)
+  const [importing, setImporting] = useState(false)
+  const [importMessage, setImportMessage] = useState<string | null>(null)
+  const importInput = useRef<HTMLInputElement>(null)
 
   useEffect(() => {
     async function fetchData() {
@@ -41,34 +93,32 @@ export function Preferences() {
       setError(null)
 
       try {
-        const [sourcesData, prefsData] = await Promise.all([
+        const [sourcesData, prefsData, settings, scoresData] = await Promise.all([
           api.get<BlogSource[]>('/api/sources'),
           api.get<PreferencesType>('/api/preferences'),
+          api.get<Settings>('/api/settings'),
+          api.get<SourceScore[]>('/api/sources/scores'),
         ])
 
         setSources(sourcesData)
+        setScores(new Map(scoresData.map((s) => [s.source_id, s])))
 
         if (prefsData.topics) {
-          setTopics(prefsData.topics)
+          setTopics(toTopics(prefsData.topics))
         }
         if (prefsData.selected_sources) {
           setSelectedSources(new Set(prefsData.selected_sources))
         }
-        if (prefsData.feed_mode === 'recent_posts' || prefsData.feed_mode === 'time_range') {
-          setFeedMode(prefsData.feed_mode)
-        }
-        if (typeof prefsData.max_articles_per_feed === 'number') {
-          setMaxArticles(prefsData.max_articles_per_feed)
-        }
-        if (typeof prefsData.lookback_days === 'number') {
-          setLookbackDays(prefsData.lookback_days)
-        }
-        if (typeof prefsData.max_results === 'number') {
-          setMaxResults(prefsData.max_results)
-        }
-        if (prefsData.timezone) {
-          setTimezone(prefsData.timezone)
-        }
+        setFeedMode(settings.feed_mode)
+        setMaxArticles(settings.max_articles_per_feed)
+        setLookbackDays(settings.lookback_days)
+        setMaxResults(settings.max_results)
+        setMaxReadingMinutes(settings.max_reading_minutes)
+        setTimezone(settings.timezone)
+        setRewriteTitles(settings.rewrite_titles)
+        setWeightBySourceScore(settings.weight_by_source_score)
+        setResurface(settings.resurface)
+        setDiscoverSchedule(settings.discover_schedule)
       } catch (err) {
         setError(err instanceof Error ? err.message : 'Failed to load preferences')
       } finally {
@@ -91,21 +141,42 @@ export function Preferences() {
     })
   }
 
+  function handleTopicChange(index: number, change: Partial<Topic>) {
+    setTopics((prev) => prev.map((t, i) => (i === index ? { ...t, ...change } : t)))
+  }
+
+  function handleTopicRemove(index: number) {
+    setTopics((prev) => prev.filter((_, i) => i !== index))
+  }
+
+  function handleTopicAdd() {
+    setTopics((prev) => [...prev, { topic: '', importance: DEFAULT_IMPORTANCE }])
+  }
+
   async function handleSave() {
     setSaving(true)
     setError(null)
     setSuccess(false)
 
     try {
-      await api.put('/api/preferences', {
-        topics,
-        selected_sources: Array.from(selectedSources),
-        feed_mode: feedMode,
-        max_articles_per_feed: maxArticles,
-        lookback_days: lookbackDays,
-        max_results: maxResults,
-        timezone,
-      })
+      await Promise.all([
+        api.put('/api/preferences', {
+          topics: topics.filter((t) => t.topic.trim() !== ''),
+          selected_sources: Array.from(selectedSources),
+        }),
+        api.put('/api/settings', {
+          feed_mode: feedMode,
+          max_articles_per_feed: maxArticles,
+          lookback_days: lookbackDays,
+          max_results: maxResults,
+          max_reading_minutes: maxReadingMinutes,
+          timezone,
+          rewrite_titles: rewriteTitles,
+          weight_by_source_score: weightBySourceScore,
+          resurface,
+          discover_schedule: discoverSchedule,
+        }),
+      ])
       setSuccess(true)
     } catch (err) {
       setError(err instanceof Error ? err.message : 'Failed to save preferences')
@@ -114,6 +185,22 @@ export function Preferences() {
     }
   }
 
+  async function handleImport(file: File) {
+    setImporting(true)
+    setError(null)
+
+    try {
+      const onConflict = overwriteOnImport ? 'overwrite' : 'skip'
+      const result = await api.upload<ArchiveImportResult>(`/api/import?on_conflict=${onConflict}`, file)
+      setImportMessage(importSummary(result))
+    } catch (err) {
+      setError(err instanceof Error ? err.message : 'Failed to import archive')
+    } finally {
+      setImporting(false)
+      if (importInput.current) importInput.current.value = ''
+    }
+  }
+
   if (loading) {
     return (
       <div className="space-y-8">
@@ -150,19 +237,47 @@ export function Preferences() {
       )}
 
       <div className="space-y-2">
-        <label htmlFor="topics" className="text-sm font-medium">
-          Topics
-        </label>
-        <Textarea
-          id="topics"
-          value={topics}
-          onChange={(e) => setTopics(e.target.value)}
-          placeholder="e.g., system design, distributed databases, data pipelines, AI/ML infrastructure..."
-          rows={4}
-          className="resize-y"
-        />
+        <span className="text-sm font-medium">Topics</span>
+        <div className="space-y-2">
+          {topics.map((t, i) => (
+            <div key={i} className="flex items-center gap-2">
+              <input
+                type="text"
+                value={t.topic}
+                onChange={(e) => handleTopicChange(i, { topic: e.target.value })}
+                placeholder="e.g., distributed databases"
+                aria-label={`Topic ${i + 1}`}
+                className="min-w-0 flex-1 rounded-md border border-input bg-background px-3 py-2 text-sm focus:outline-none focus:ring-1 focus:ring-primary"
+              />
+              <select
+                value={t.importance}
+                onChange={(e) => handleTopicChange(i, { importance: Number(e.target.value) })}
+                aria-label={`Importance of topic ${i + 1}`}
+                className="rounded-md border border-input bg-background px-3 py-2 text-sm focus:outline-none focus:ring-1 focus:ring-primary"
+              >
+                {[5, 4, 3, 2, 1].map((n) => (
+                  <option key={n} value={n}>
+                    {n} · {IMPORTANCE_LABELS[n]}
+                  </option>
+                ))}
+              </select>
+              <Button
+                variant="ghost"
+                size="icon"
+                onClick={() => handleTopicRemove(i)}
+                aria-label={`Remove topic ${i + 1}`}
+              >
+                <X className="size-4" />
+              </Button>
+            </div>
+          ))}
+        </div>
+        <Button variant="outline" size="sm" onClick={handleTopicAdd} className="gap-2">
+          <Plus className="size-4" />
+          Add topic
+        </Button>
         <p className="text-xs text-muted-foreground">
-          Enter your interests as a comma-separated list or free-form description.
+          Rate each interest from nice to have to must have. Must-have topics win when posts compete for a spot.
         </p>
       </div>
 
@@ -199,6 +314,29 @@ export function Preferences() {
           </div>
         </div>
 
+        <div className="rounded-lg border bg-muted/30 p-4">
+          <div className="flex items-baseline justify-between">
+            <label htmlFor="max-reading-minutes" className="text-sm font-medium">
+              Maximum reading time
+            </label>
+          </div>
+          <select
+            id="max-reading-minutes"
+            value={maxReadingMinutes}
+            onChange={(e) => setMaxReadingMinutes(Number(e.target.value))}
+            className="mt-2 w-full rounded-md border border-input bg-background px-3 py-2 text-sm focus:outline-none focus:ring-1 focus:ring-primary"
+          >
+            <option value={0}>No limit</option>
+            <option value={5}>Under 5 minutes</option>
+            <option value={10}>Under 10 minutes</option>
+            <option value={20}>Under 20 minutes</option>
+            <option value={30}>Under 30 minutes</option>
+          </select>
+          <p className="mt-1 text-xs text-muted-foreground">
+            Longer posts are skipped during discovery so they don't take up result slots.
+          </p>
+        </div>
+
         <div className="rounded-lg border bg-muted/30 p-4">
           <div className="flex items-baseline justify-between">
             <label htmlFor="timezone" className="text-sm font-medium">
@@ -245,6 +383,54 @@ export function Preferences() {
             ))}
           </select>
         </div>
+
+        <div className="flex items-center justify-between gap-4 rounded-lg border bg-muted/30 p-4">
+          <div>
+            <label htmlFor="rewrite-titles" className="text-sm font-medium">
+              Rewrite clickbait titles
+            </label>
+            <p className="mt-1 text-xs text-muted-foreground">
+              Show an AI-written factual title instead of marketing-style headlines. The original is kept on hover.
+            </p>
+          </div>
+          <Switch
+            id="rewrite-titles"
+            checked={rewriteTitles}
+            onCheckedChange={(checked: boolean) => setRewriteTitles(checked)}
+          />
+        </div>
+
+        <div className="flex items-center justify-between gap-4 rounded-lg border bg-muted/30 p-4">
+          <div>
+            <label htmlFor="weight-by-source-score" className="text-sm font-medium">
+              Favor sources I engage with
+            </label>
+            <p className="mt-1 text-xs text-muted-foreground">
+              Nudge discovery rankings toward sources whose posts you save, finish, and rate up.
+            </p>
+          </div>
+          <Switch
+            id="weight-by-source-score"
+            checked={weightBySourceScore}
+            onCheckedChange={(checked: boolean) => setWeightBySourceScore(checked)}
+          />
+        </div>
+
+        <div className="flex items-center justify-between gap-4 rounded-lg border bg-muted/30 p-4">
+          <div>
+            <label htmlFor="resurface" className="text-sm font-medium">
+              Resurface favorite reads
+            </label>
+            <p className="mt-1 text-xs text-muted-foreground">
+              Bring back posts you rated up for review one week, one month, and three months after reading them.
+            </p>
+          </div>
+          <Switch
+            id="resurface"
+            checked={resurface}
+            onCheckedChange={(checked: boolean) => setResurface(checked)}
+          />
+        </div>
       </div>
 
       <Separator />
@@ -340,6 +526,23 @@ export function Preferences() {
             </div>
           </div>
         )}
+
+        <div className="rounded-lg border bg-muted/30 p-4">
+          <label htmlFor="discover-schedule" className="text-sm font-medium">
+            Automatic discovery
+          </label>
+          <input
+            id="discover-schedule"
+            type="text"
+            value={discoverSchedule}
+            onChange={(e) => setDiscoverSchedule(e.target.value)}
+            placeholder="e.g., 0 7 * * 1-5"
+            className="mt-2 w-full rounded-md border border-input bg-background px-3 py-2 font-mono text-sm focus:outline-none focus:ring-1 focus:ring-primary"
+          />
+          <p className="mt-1 text-xs text-muted-foreground">
+            Run discovery on a cron schedule, such as 0 7 * * 1-5 for 07:00 on weekdays, so results are waiting. Leave empty to run it only by hand.
+          </p>
+        </div>
       </div>
 
       <Separator />
@@ -406,6 +609,14 @@ export function Preferences() {
                           <Heart className="size-3.5 fill-red-500 text-red-500" />
                         )}
                       </span>
+                      {scores.has(source.id) && (
+                        <span
+                          title={sourceScoreTooltip(scores.get(source.id)!)}
+                          className="cursor-help text-xs tabular-nums text-muted-foreground"
+                        >
+                          {Math.round(scores.get(source.id)!.score * 100)}
+                        </span>
+                      )}
                     </div>
                     <p className="mt-1 truncate text-xs text-muted-foreground">
                       {source.feed_url}
@@ -436,11 +647,66 @@ export function Preferences() {
         </Button>
       </div>
 
+      <Separator />
+
+      <div className="space-y-4">
+        <div>
+          <h2 className="text-lg font-semibold">Backup</h2>
+          <p className="text-sm text-muted-foreground">
+            Download everything as an archive, or restore one into this instance. Exports from
+            Instapaper (CSV) and Omnivore (zip) can be imported too.
+          </p>
+        </div>
+
+        <div className="flex flex-wrap items-center gap-3">
+          <Button variant="outline" className="gap-2" onClick={() => { window.location.href = '/api/export' }}>
+            <Download className="size-4" />
+            Export archive
+          </Button>
+          <Button
+            variant="outline"
+            className="gap-2"
+            disabled={importing}
+            onClick={() => importInput.current?.click()}
+          >
+            {importing ? <Loader2 className="size-4 animate-spin" /> : <Upload className="size-4" />}
+            {importing ? 'Importing...' : 'Import archive'}
+          </Button>
+          <input
+            ref={importInput}
+            type="file"
+            accept="application/json,.json,text/csv,.csv,application/zip,.zip"
+            className="hidden"
+            onChange={(e) => {
+              const file = e.target.files?.[0]
+              if (file) void handleImport(file)
+            }}
+          />
+        </div>
+
+        <div className="flex items-center gap-3">
+          <Switch
+            id="overwrite-on-import"
+            checked={overwriteOnImport}
+            onCheckedChange={(checked: boolean) => setOverwriteOnImport(checked)}
+          />
+          <label htmlFor="overwrite-on-import" className="text-sm">
+            Overwrite posts, items, and settings that already exist
+          </label>
+        </div>
+      </div>
+
       <Toast
         message="Preferences saved successfully."
         visible={success}
         onClose={() => setSuccess(false)}
       />
+      <Toast
+        message={importMessage ?? ''}
+        visible={importMessage !== null}
+        onClose={() => setImportMessage(null)}
+        duration={5000}
+      />
     </div>
   )
 }
@@ -1,7 +1,7 @@
-import { useState, useEffect } from 'react'
+import { useState, useEffect, useRef } from 'react'
 import { useParams, useNavigate } from 'react-router-dom'
-import { ArrowLeft, ExternalLink, Clock, Loader2, CheckCircle } from 'lucide-react'
-import type { ReadingListItem } from '@/lib/types'
+import { ArrowLeft, ExternalLink, Clock, Loader2, CheckCircle, BookOpen, Globe } from 'lucide-react'
+import type { BlogContent, ReadingListItem } from '@/lib/types'
 import { api } from '@/lib/api'
 import { formatReadingTime } from '@/lib/reading'
 import { Button } from '@/components/ui/button'
@@ -16,6 +16,12 @@ export function ReaderView() {
   const [loading, setLoading] = useState(true)
   const [error, setError] = useState<string | null>(null)
   const [iframeLoading, setIframeLoading] = useState(true)
+  // The extracted article, shown instead of the proxied page when there is
+  // one; null while loading, and '' if extraction failed.
+  const [articleHtml, setArticleHtml] = useState<string | null>(null)
+  const [showOriginal, setShowOriginal] = useState(false)
+  const articleRef = useRef<HTMLDivElement>(null)
+  const saveTimer = useRef<ReturnType<typeof setTimeout>>(undefined)
   const [status, setStatus] = useState<string>('unread')
   const [toastVisible, setToastVisible] = useState(false)
   const [toastMessage, setToastMessage] = useState('')
@@ -40,7 +46,12 @@ export function ReaderView() {
         // Auto-set status to "reading" if currently "unread".
         if (data.status === 'unread') {
           try {
-            await api.patch(`/api/reading-list/${id}`, { status: 'reading' })
+            const res = await api.patch<{ version: number }>(
+              `/api/reading-list/${id}`,
+              { status: 'reading' },
+              data.version
+            )
+            setItem({ ...data, status: 'reading', version: res.version })
             setStatus('reading')
           } catch {
             // Non-critical
@@ -56,10 +67,53 @@ export function ReaderView() {
     void load()
   }, [id])
 
+  // Fetch the extracted article, falling back to the proxied page.
+  const blogId = item?.blog?.id
+  useEffect(() => {
+    if (!blogId) return
+    api
+      .get<BlogContent>(`/api/blogs/${blogId}/content`)
+      .then((data) => setArticleHtml(data.html))
+      .catch(() => setArticleHtml(''))
+  }, [blogId])
+
+  // Resume where the item was left, on this device or another, once the
+  // article is shown. Only the first showing restores it.
+  const restored = useRef(false)
+  useEffect(() => {
+    const el = articleRef.current
+    const position = item?.scroll_position
+    if (!el || restored.current || !articleHtml || showOriginal) return
+    restored.current = true
+    if (position && position > 0 && position < 1) {
+      el.scrollTop = position * (el.scrollHeight - el.clientHeight)
+      showToast('Resumed where you left off')
+    }
+  }, [articleHtml, showOriginal, item?.scroll_position])
+
+  // Save the scroll position a moment after scrolling stops.
+  function handleArticleScroll() {
+    const el = articleRef.current
+    if (!el || !id) return
+    clearTimeout(saveTimer.current)
+    saveTimer.current = setTimeout(() => {
+      const scrollable = el.scrollHeight - el.clientHeight
+      const position = scrollable > 0 ? Math.min(1, el.scrollTop / scrollable) : 1
+      const progress = Math.round(position * 100)
+      api
+        .patch<{ auto_read: boolean }>(`/api/reading-list/${id}/progress`, { progress, scroll_position: position })
+        .then((res) => {
+          if (res.auto_read) setStatus('read')
+        })
+        .catch(() => {})
+    }, 1000)
+  }
+  useEffect(() => () => clearTimeout(saveTimer.current), [])
+
   async function handleMarkAsRead() {
     if (!id) return
     try {
-      await api.patch(`/api/reading-list/${id}`, { status: 'read' })
+      await api.patch(`/api/reading-list/${id}`, { status: 'read' }, item?.version)
       // Also set progress to 100.
       await api.patch(`/api/reading-list/${id}/progress`, { progress: 100 }).catch(() => {})
       setStatus('read')
@@ -131,6 +185,12 @@ export function ReaderView() {
             {status === 'read' && (
               <Badge variant="default" className="bg-green-600">Read</Badge>
             )}
+            {articleHtml && (
+              <Button variant="ghost" size="sm" onClick={() => setShowOriginal((prev) => !prev)}>
+                {showOriginal ? <BookOpen className="size-4" /> : <Globe className="size-4" />}
+                <span className="hidden sm:inline">{showOriginal ? 'Reader' : 'Original'}</span>
+              </Button>
+            )}
             {blogUrl && (
               <Button variant="ghost" size="sm" asChild>
                 <a href={blogUrl} target="_blank" rel="noopener noreferrer">
@@ -143,8 +203,23 @@ export function ReaderView() {
         </div>
       </header>
 
-      {/* Embedded original page via proxy */}
-      {blogUrl ? (
+      {/* The extracted article, sanitized by the server, or else the
+          original page via the proxy */}
+      {blogUrl && articleHtml === null ? (
+        <div className="flex flex-1 items-center justify-center">
+          <Loader2 className="size-6 animate-spin text-muted-foreground" />
+        </div>
+      ) : blogUrl && articleHtml && !showOriginal ? (
+        <div ref={articleRef} onScroll={handleArticleScroll} className="flex-1 overflow-y-auto">
+          <article className="mx-auto max-w-2xl px-4 py-8">
+            <h1 className="mb-6 text-3xl font-bold leading-tight">{blog?.title}</h1>
+            <div
+              className="space-y-4 leading-relaxed [&_a]:text-primary [&_a]:underline [&_a]:underline-offset-4 [&_blockquote]:border-l-4 [&_blockquote]:border-border [&_blockquote]:pl-4 [&_blockquote]:italic [&_code]:rounded [&_code]:bg-muted [&_code]:px-1 [&_code]:text-sm [&_figcaption]:text-center [&_figcaption]:text-sm [&_figcaption]:text-muted-foreground [&_h2]:mt-8 [&_h2]:text-2xl [&_h2]:font-semibold [&_h3]:mt-6 [&_h3]:text-xl [&_h3]:font-semibold [&_img]:mx-auto [&_img]:h-auto [&_img]:max-w-full [&_img]:rounded-md [&_li]:my-1 [&_ol]:list-decimal [&_ol]:pl-6 [&_pre]:overflow-x-auto [&_pre]:rounded-md [&_pre]:bg-muted [&_pre]:p-4 [&_pre_code]:bg-transparent [&_pre_code]:p-0 [&_table]:w-full [&_table]:text-sm [&_td]:border [&_td]:border-border [&_td]:p-2 [&_th]:border [&_th]:border-border [&_th]:p-2 [&_ul]:list-disc [&_ul]:pl-6"
+              dangerouslySetInnerHTML={{ __html: articleHtml }}
+            />
+          </article>
+        </div>
+      ) : blogUrl ? (
         <div className="relative flex-1">
           {iframeLoading && (
             <div className="absolute inset-0 z-10 flex items-center justify-center bg-background">
@@ -156,7 +231,7 @@ export function ReaderView() {
             title={blog?.title ?? 'Article'}
             className="size-full border-0"
             onLoad={() => setIframeLoading(false)}
-            sandbox="allow-scripts allow-same-origin allow-popups allow-popups-to-escape-sandbox"
+            sandbox="allow-same-origin allow-popups allow-popups-to-escape-sandbox"
           />
         </div>
       ) : (
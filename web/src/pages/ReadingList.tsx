@@ -1,6 +1,6 @@
 import { useState, useEffect, useCallback } from 'react'
-import { AlertCircle, X, Plus, Loader2, Link } from 'lucide-react'
-import type { ReadingListItem } from '@/lib/types'
+import { AlertCircle, Archive, X, Plus, Loader2, Link } from 'lucide-react'
+import type { BatchAddResponse, ReadingListItem, ReadingListPage, Tag } from '@/lib/types'
 import { api } from '@/lib/api'
 import { Tabs, TabsList, TabsTrigger, TabsContent } from '@/components/ui/tabs'
 import { Badge } from '@/components/ui/badge'
@@ -16,14 +16,19 @@ import {
   AlertDialogCancel,
 } from '@/components/ui/alert-dialog'
 import { ReadingItem } from '@/components/reading-item'
+import { ReviewQueue } from '@/components/review-queue'
 import { Toast } from '@/components/toast'
 
-type TabStatus = 'unread' | 'reading' | 'read'
+type TabStatus = 'unread' | 'reading' | 'read' | 'archived'
+
+// pageSize is how many items a tab loads at a time.
+const pageSize = 50
 
 const tabConfig: { value: TabStatus; label: string; emptyMessage: string }[] = [
   { value: 'unread', label: 'Unread', emptyMessage: 'No unread posts' },
   { value: 'reading', label: 'Reading', emptyMessage: 'No posts in progress' },
   { value: 'read', label: 'Read', emptyMessage: 'No completed posts' },
+  { value: 'archived', label: 'Archived', emptyMessage: 'No archived posts' },
 ]
 
 export function ReadingList() {
@@ -32,20 +37,26 @@ export function ReadingList() {
     unread: [],
     reading: [],
     read: [],
+    archived: [],
   })
   const [counts, setCounts] = useState<Record<TabStatus, number>>({
     unread: 0,
     reading: 0,
     read: 0,
+    archived: 0,
   })
   const [loading, setLoading] = useState<Record<TabStatus, boolean>>({
     unread: true,
     reading: true,
     read: true,
+    archived: true,
   })
+  const [loadingMore, setLoadingMore] = useState<TabStatus | null>(null)
   const [error, setError] = useState<string | null>(null)
   const [allTags, setAllTags] = useState<string[]>([])
   const [selectedTag, setSelectedTag] = useState<string | null>(null)
+  const [deepDivesOnly, setDeepDivesOnly] = useState(false)
+  const [archiving, setArchiving] = useState(false)
 
   // Add Blog dialog state
   const [addDialogOpen, setAddDialogOpen] = useState(false)
@@ -57,8 +68,8 @@ export function ReadingList() {
 
   const fetchTags = useCallback(async () => {
     try {
-      const data = await api.get<string[]>('/api/tags')
-      setAllTags(data)
+      const data = await api.get<Tag[]>('/api/tags')
+      setAllTags(data.map((tag) => tag.name))
     } catch {
       // Non-critical — autocomplete just won't work
     }
@@ -68,9 +79,9 @@ export function ReadingList() {
     setLoading((prev) => ({ ...prev, [status]: true }))
 
     try {
-      const data = await api.get<ReadingListItem[]>(`/api/reading-list?status=${status}`)
-      setItems((prev) => ({ ...prev, [status]: data }))
-      setCounts((prev) => ({ ...prev, [status]: data.length }))
+      const data = await api.get<ReadingListPage>(`/api/reading-list?status=${status}&limit=${pageSize}`)
+      setItems((prev) => ({ ...prev, [status]: data.items }))
+      setCounts((prev) => ({ ...prev, [status]: data.total }))
     } catch (err) {
       setError(err instanceof Error ? err.message : 'Failed to load reading list')
     } finally {
@@ -78,6 +89,25 @@ export function ReadingList() {
     }
   }, [])
 
+  async function loadMore(status: TabStatus) {
+    setLoadingMore(status)
+    try {
+      const data = await api.get<ReadingListPage>(
+        `/api/reading-list?status=${status}&limit=${pageSize}&offset=${items[status].length}`
+      )
+      // Items moved since the last page can shift the offsets; skip repeats.
+      setItems((prev) => {
+        const seen = new Set(prev[status].map((item) => item.id))
+        return { ...prev, [status]: [...prev[status], ...data.items.filter((item) => !seen.has(item.id))] }
+      })
+      setCounts((prev) => ({ ...prev, [status]: data.total }))
+    } catch (err) {
+      setError(err instanceof Error ? err.message : 'Failed to load reading list')
+    } finally {
+      setLoadingMore(null)
+    }
+  }
+
   const fetchAll = useCallback(async () => {
     await Promise.all([
       ...tabConfig.map((tab) => fetchTab(tab.value)),
@@ -115,7 +145,7 @@ export function ReadingList() {
     setActiveTab(newStatus as TabStatus)
 
     try {
-      await api.patch(`/api/reading-list/${id}`, { status: newStatus })
+      await api.patch(`/api/reading-list/${id}`, { status: newStatus }, oldItem.version)
     } catch {
       setItems((prev) => ({
         ...prev,
@@ -163,24 +193,110 @@ export function ReadingList() {
     }
   }
 
+  async function handleSnooze(id: number, until: Date) {
+    const oldItem = Object.values(items)
+      .flat()
+      .find((item) => item.id === id)
+    if (!oldItem) return
+
+    const status = oldItem.status
+
+    setItems((prev) => ({
+      ...prev,
+      [status]: prev[status].filter((item) => item.id !== id),
+    }))
+    setCounts((prev) => ({
+      ...prev,
+      [status]: prev[status] - 1,
+    }))
+
+    try {
+      await api.patch(`/api/reading-list/${id}`, { snoozed_until: until.toISOString() }, oldItem.version)
+    } catch {
+      setItems((prev) => ({
+        ...prev,
+        [status]: [...prev[status], oldItem],
+      }))
+      setCounts((prev) => ({
+        ...prev,
+        [status]: prev[status] + 1,
+      }))
+    }
+  }
+
+  async function handleMoveToTop(id: number) {
+    const oldItem = Object.values(items)
+      .flat()
+      .find((item) => item.id === id)
+    if (!oldItem) return
+
+    const status = oldItem.status
+    const oldOrder = items[status]
+
+    setItems((prev) => ({
+      ...prev,
+      [status]: [oldItem, ...prev[status].filter((item) => item.id !== id)],
+    }))
+
+    try {
+      await api.patch('/api/reading-list/reorder', { ids: [id] })
+    } catch {
+      setItems((prev) => ({ ...prev, [status]: oldOrder }))
+    }
+  }
+
+  async function handleArchiveRead() {
+    setArchiving(true)
+    try {
+      await api.post('/api/reading-list/archive-read')
+      await Promise.all([fetchTab('read'), fetchTab('archived')])
+    } catch (err) {
+      setError(err instanceof Error ? err.message : 'Failed to archive read posts')
+    } finally {
+      setArchiving(false)
+    }
+  }
+
   function handleTagsChange() {
     void fetchAll()
   }
 
   function getFilteredItems(tabItems: ReadingListItem[]) {
-    if (!selectedTag) return tabItems
-    return tabItems.filter((item) => item.tags.includes(selectedTag))
+    return tabItems.filter(
+      (item) =>
+        (!selectedTag || item.tags.includes(selectedTag)) &&
+        (!deepDivesOnly || item.blog?.difficulty === 'deep-dive')
+    )
   }
 
   async function handleAddCustomBlog() {
     setAddError(null)
     setAddLoading(true)
 
+    // Several pasted links go to the batch endpoint in one request.
+    const urls = addUrl.split(/\s+/).filter(Boolean)
     try {
-      await api.post('/api/reading-list/custom', {
-        url: addUrl.trim(),
-        source: addSource.trim() || undefined,
-      })
+      if (urls.length > 1) {
+        const res = await api.post<BatchAddResponse>('/api/reading-list/custom/batch', {
+          urls,
+          source: addSource.trim() || undefined,
+        })
+        const failed = res.results.filter((r) => r.status === 'failed')
+        if (failed.length > 0) {
+          setAddUrl(failed.map((r) => r.url).join('\n'))
+          setAddError(
+            `Added ${res.added} of ${res.results.length}. These failed:\n` +
+              failed.map((r) => `${r.url}: ${r.error}`).join('\n')
+          )
+          void fetchAll()
+          return
+        }
+      } else {
+        await api.post('/api/reading-list/custom', {
+          url: urls[0] ?? '',
+          source: addSource.trim() || undefined,
+        })
+      }
       setAddDialogOpen(false)
       setAddUrl('')
       setAddSource('')
@@ -227,6 +343,24 @@ export function ReadingList() {
         </div>
       )}
 
+      <ReviewQueue />
+
+      {/* Difficulty filter */}
+      <div className="flex items-center gap-2">
+        <Badge
+          variant={deepDivesOnly ? 'default' : 'outline'}
+          className={`cursor-pointer transition-colors ${
+            deepDivesOnly
+              ? 'bg-primary text-primary-foreground'
+              : 'border-primary/30 text-primary hover:bg-primary/10'
+          }`}
+          onClick={() => setDeepDivesOnly(!deepDivesOnly)}
+        >
+          Deep dives only
+          {deepDivesOnly && <X className="ml-1 size-3" />}
+        </Badge>
+      </div>
+
       {/* Tag filter bar */}
       {usedTags.length > 0 && (
         <div className="flex flex-wrap items-center gap-2">
@@ -276,6 +410,20 @@ export function ReadingList() {
           const filtered = getFilteredItems(items[tab.value])
           return (
             <TabsContent key={tab.value} value={tab.value} className="mt-6">
+              {tab.value === 'read' && counts.read > 0 && (
+                <div className="mb-4 flex justify-end">
+                  <Button
+                    variant="outline"
+                    size="sm"
+                    onClick={() => void handleArchiveRead()}
+                    disabled={archiving}
+                    className="gap-2"
+                  >
+                    {archiving ? <Loader2 className="size-4 animate-spin" /> : <Archive className="size-4" />}
+                    Archive all read
+                  </Button>
+                </div>
+              )}
               {loading[tab.value] ? (
                 <div className="space-y-4">
                   {Array.from({ length: 3 }).map((_, i) => (
@@ -312,12 +460,28 @@ export function ReadingList() {
                       item={item}
                       onStatusChange={handleStatusChange}
                       onRemove={handleRemove}
+                      onSnooze={handleSnooze}
+                      onMoveToTop={handleMoveToTop}
                       onTagsChange={handleTagsChange}
                       allTags={allTags}
                     />
                   ))}
                 </div>
               )}
+              {!loading[tab.value] && items[tab.value].length < counts[tab.value] && (
+                <div className="mt-6 flex justify-center">
+                  <Button
+                    variant="outline"
+                    size="sm"
+                    onClick={() => void loadMore(tab.value)}
+                    disabled={loadingMore === tab.value}
+                    className="gap-2"
+                  >
+                    {loadingMore === tab.value && <Loader2 className="size-4 animate-spin" />}
+                    Load more ({counts[tab.value] - items[tab.value].length} left)
+                  </Button>
+                </div>
+              )}
             </TabsContent>
           )
         })}
@@ -329,7 +493,7 @@ export function ReadingList() {
           <AlertDialogHeader>
             <AlertDialogTitle>Add Blog to Reading List</AlertDialogTitle>
             <AlertDialogDescription>
-              Paste any blog post URL and we'll fetch the article details automatically.
+              Paste any blog post URL, or several, one per line, and we'll fetch the article details automatically.
             </AlertDialogDescription>
           </AlertDialogHeader>
 
@@ -339,20 +503,21 @@ export function ReadingList() {
                 URL <span className="text-destructive">*</span>
               </label>
               <div className="relative">
-                <Link className="absolute left-3 top-1/2 size-4 -translate-y-1/2 text-muted-foreground" />
-                <input
+                <Link className="absolute left-3 top-2.5 size-4 text-muted-foreground" />
+                <textarea
                   id="add-url"
-                  type="url"
+                  rows={Math.min(6, Math.max(1, addUrl.split('\n').length))}
                   value={addUrl}
                   onChange={(e) => setAddUrl(e.target.value)}
                   onKeyDown={(e) => {
-                    if (e.key === 'Enter' && addUrl.trim() && !addLoading) {
+                    if (e.key === 'Enter' && !e.shiftKey && addUrl.trim() && !addLoading) {
+                      e.preventDefault()
                       void handleAddCustomBlog()
                     }
                   }}
                   placeholder="https://example.com/blog/post"
                   disabled={addLoading}
-                  className="flex h-9 w-full rounded-md border border-input bg-transparent pl-9 pr-3 py-1 text-sm shadow-xs transition-colors placeholder:text-muted-foreground focus-visible:outline-none focus-visible:ring-1 focus-visible:ring-ring disabled:cursor-not-allowed disabled:opacity-50"
+                  className="flex min-h-9 w-full resize-none rounded-md border border-input bg-transparent pl-9 pr-3 py-2 text-sm shadow-xs transition-colors placeholder:text-muted-foreground focus-visible:outline-none focus-visible:ring-1 focus-visible:ring-ring disabled:cursor-not-allowed disabled:opacity-50"
                   autoFocus
                 />
               </div>
@@ -381,7 +546,7 @@ export function ReadingList() {
             {addError && (
               <div className="flex items-start gap-2 rounded-md border border-destructive/50 bg-destructive/10 px-3 py-2 text-sm">
                 <AlertCircle className="mt-0.5 size-3.5 shrink-0 text-destructive" />
-                <p className="text-destructive">{addError}</p>
+                <p className="whitespace-pre-line break-all text-destructive">{addError}</p>
               </div>
             )}
           </div>